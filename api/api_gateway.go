@@ -19,16 +19,40 @@ type SaoApi interface {
 	// MethodGroup: Order Job
 	OrderStatus(ctx context.Context, id string) (types.OrderInfo, error) //perm:read
 	OrderList(ctx context.Context) ([]types.OrderInfo, error)            //perm:read
-	// OrderFix(ctx context.Context, id string) error                       //perm:write
+	// OrderHistory list state transitions of the orders of the data and their shards on this node, oldest first,
+	// orderId 0 lists all orders of the data
+	OrderHistory(ctx context.Context, dataId string, orderId uint64) ([]types.OrderEvent, error) //perm:read
+	// OrderFix reset the retries of an order which is still in progress on chain and process it again
+	OrderFix(ctx context.Context, dataId string) error //perm:admin
+	// OrderCancel stop processing an in-flight order and release its staging files
+	OrderCancel(ctx context.Context, dataId string) error //perm:admin
+	// OrderReassign hand the shard of provider from over to provider to, which holds a waiting shard of the order on chain
+	OrderReassign(ctx context.Context, dataId string, from string, to string) error //perm:admin
 
 	// MethodGroup: Shard Job
 	ShardStatus(ctx context.Context, orderId uint64, cid cid.Cid) (types.ShardInfo, error) //perm:read
 	ShardList(ctx context.Context) ([]types.ShardInfo, error)                              //perm:read
-	// ShardFix(ctx context.Context, orderId uint64, cid cid.Cid) error
+	// ShardFix reset the retries of a shard which is still waiting on chain and process it again
+	ShardFix(ctx context.Context, orderId uint64, cid cid.Cid) error //perm:admin
 
 	// MethodGroup: Migration Job
 	MigrateJobList(ctx context.Context) ([]types.MigrateInfo, error) //perm:read
 
+	// MethodGroup: Indexer Job
+
+	// IndexJobAdd register a metadata job of platform ids or a shard job of provider addresses, it runs every interval seconds unless interval is 0
+	IndexJobAdd(ctx context.Context, jobType string, param string, interval uint64) (string, error) //perm:admin
+	IndexJobList(ctx context.Context) ([]types.IndexJobInfo, error)                                 //perm:read
+	// IndexJobKill stop the job and delete it
+	IndexJobKill(ctx context.Context, jobId string) error //perm:admin
+	// IndexJobReindex drop the indexed height of the job and rebuild its rows from the current chain state
+	IndexJobReindex(ctx context.Context, jobId string) error //perm:admin
+
+	// MethodGroup: Gc
+
+	// GcStaging remove stale staging, upload and cache files, only report them if dryRun is set
+	GcStaging(ctx context.Context, dryRun bool) (types.GcReport, error) //perm:admin
+
 	// MethodGroup: Model
 	// The Model method group contains methods for manipulating data models.
 
@@ -50,6 +74,11 @@ type SaoApi interface {
 	ModelUpdatePermission(ctx context.Context, req *types.PermissionProposal, isPublish bool) (apitypes.UpdatePermissionResp, error) //perm:write
 	ModelMigrate(ctx context.Context, dataIds []string) (apitypes.MigrateResp, error)                                                // perm:write
 
+	// MethodGroup: Fisherman
+
+	// AuditHistory list scheduled shard audits of the provider, or of all providers if provider is empty
+	AuditHistory(ctx context.Context, provider string) ([]types.AuditRecord, error) //perm:read
+
 	// Raise Storage Faults
 	FaultsCheck(ctx context.Context, dataIds []string) (*apitypes.FileFaultsReportResp, error)
 	// Requst Check for Recoverable Storage Faults
@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/SaoNetwork/sao-node/api"
+	"github.com/SaoNetwork/sao-node/tracing"
 
 	"github.com/filecoin-project/go-jsonrpc"
 )
@@ -18,6 +19,8 @@ func NewNodeApi(ctx context.Context, address string, token string) (api.SaoApi,
 
 	headers := http.Header{}
 	headers.Add("Authorization", "Bearer "+string(token))
+	// calls made through the client join the trace of ctx
+	tracing.InjectHeader(ctx, headers)
 
 	closer, err := jsonrpc.NewMergeClient(ctx, address, namespace, api.GetInternalStructs(&res), headers)
 	return &res, closer, err
@@ -16,12 +16,16 @@ var ErrNotSupported = xerrors.New("method not supported")
 
 type SaoApiStruct struct {
 	Internal struct {
+		AuditHistory func(p0 context.Context, p1 string) ([]types.AuditRecord, error) `perm:"read"`
+
 		AuthNew func(p0 context.Context, p1 []auth.Permission) ([]byte, error) `perm:"admin"`
 
 		AuthVerify func(p0 context.Context, p1 string) ([]auth.Permission, error) `perm:"none"`
 
 		FaultsCheck func(p0 context.Context, p1 []string) (*apitypes.FileFaultsReportResp, error) ``
 
+		GcStaging func(p0 context.Context, p1 bool) (types.GcReport, error) `perm:"admin"`
+
 		GenerateToken func(p0 context.Context, p1 string) (apitypes.GenerateTokenResp, error) `perm:"read"`
 
 		GetHttpUrl func(p0 context.Context, p1 string) (apitypes.GetUrlResp, error) `perm:"read"`
@@ -34,7 +38,15 @@ type SaoApiStruct struct {
 
 		GetPeerInfo func(p0 context.Context) (apitypes.GetPeerInfoResp, error) `perm:"read"`
 
-		MigrateJobList func(p0 context.Context) ([]types.MigrateInfo, error) ``
+		IndexJobAdd func(p0 context.Context, p1 string, p2 string, p3 uint64) (string, error) `perm:"admin"`
+
+		IndexJobKill func(p0 context.Context, p1 string) error `perm:"admin"`
+
+		IndexJobList func(p0 context.Context) ([]types.IndexJobInfo, error) `perm:"read"`
+
+		IndexJobReindex func(p0 context.Context, p1 string) error `perm:"admin"`
+
+		MigrateJobList func(p0 context.Context) ([]types.MigrateInfo, error) `perm:"read"`
 
 		ModelCreate func(p0 context.Context, p1 *types.MetadataProposal, p2 *types.OrderStoreProposal, p3 uint64, p4 []byte) (apitypes.CreateResp, error) `perm:"write"`
 
@@ -54,13 +66,23 @@ type SaoApiStruct struct {
 
 		ModelUpdatePermission func(p0 context.Context, p1 *types.PermissionProposal, p2 bool) (apitypes.UpdatePermissionResp, error) `perm:"write"`
 
-		OrderList func(p0 context.Context) ([]types.OrderInfo, error) ``
+		OrderCancel func(p0 context.Context, p1 string) error `perm:"admin"`
+
+		OrderFix func(p0 context.Context, p1 string) error `perm:"admin"`
+
+		OrderHistory func(p0 context.Context, p1 string, p2 uint64) ([]types.OrderEvent, error) `perm:"read"`
+
+		OrderList func(p0 context.Context) ([]types.OrderInfo, error) `perm:"read"`
+
+		OrderReassign func(p0 context.Context, p1 string, p2 string, p3 string) error `perm:"admin"`
 
 		OrderStatus func(p0 context.Context, p1 string) (types.OrderInfo, error) `perm:"read"`
 
 		RecoverCheck func(p0 context.Context, p1 string, p2 []string) (*apitypes.FileRecoverReportResp, error) ``
 
-		ShardList func(p0 context.Context) ([]types.ShardInfo, error) ``
+		ShardFix func(p0 context.Context, p1 uint64, p2 cid.Cid) error `perm:"admin"`
+
+		ShardList func(p0 context.Context) ([]types.ShardInfo, error) `perm:"read"`
 
 		ShardStatus func(p0 context.Context, p1 uint64, p2 cid.Cid) (types.ShardInfo, error) `perm:"read"`
 	}
@@ -69,6 +91,17 @@ type SaoApiStruct struct {
 type SaoApiStub struct {
 }
 
+func (s *SaoApiStruct) AuditHistory(p0 context.Context, p1 string) ([]types.AuditRecord, error) {
+	if s.Internal.AuditHistory == nil {
+		return *new([]types.AuditRecord), ErrNotSupported
+	}
+	return s.Internal.AuditHistory(p0, p1)
+}
+
+func (s *SaoApiStub) AuditHistory(p0 context.Context, p1 string) ([]types.AuditRecord, error) {
+	return *new([]types.AuditRecord), ErrNotSupported
+}
+
 func (s *SaoApiStruct) AuthNew(p0 context.Context, p1 []auth.Permission) ([]byte, error) {
 	if s.Internal.AuthNew == nil {
 		return *new([]byte), ErrNotSupported
@@ -102,6 +135,17 @@ func (s *SaoApiStub) FaultsCheck(p0 context.Context, p1 []string) (*apitypes.Fil
 	return nil, ErrNotSupported
 }
 
+func (s *SaoApiStruct) GcStaging(p0 context.Context, p1 bool) (types.GcReport, error) {
+	if s.Internal.GcStaging == nil {
+		return *new(types.GcReport), ErrNotSupported
+	}
+	return s.Internal.GcStaging(p0, p1)
+}
+
+func (s *SaoApiStub) GcStaging(p0 context.Context, p1 bool) (types.GcReport, error) {
+	return *new(types.GcReport), ErrNotSupported
+}
+
 func (s *SaoApiStruct) GenerateToken(p0 context.Context, p1 string) (apitypes.GenerateTokenResp, error) {
 	if s.Internal.GenerateToken == nil {
 		return *new(apitypes.GenerateTokenResp), ErrNotSupported
@@ -168,6 +212,50 @@ func (s *SaoApiStub) GetPeerInfo(p0 context.Context) (apitypes.GetPeerInfoResp,
 	return *new(apitypes.GetPeerInfoResp), ErrNotSupported
 }
 
+func (s *SaoApiStruct) IndexJobAdd(p0 context.Context, p1 string, p2 string, p3 uint64) (string, error) {
+	if s.Internal.IndexJobAdd == nil {
+		return "", ErrNotSupported
+	}
+	return s.Internal.IndexJobAdd(p0, p1, p2, p3)
+}
+
+func (s *SaoApiStub) IndexJobAdd(p0 context.Context, p1 string, p2 string, p3 uint64) (string, error) {
+	return "", ErrNotSupported
+}
+
+func (s *SaoApiStruct) IndexJobKill(p0 context.Context, p1 string) error {
+	if s.Internal.IndexJobKill == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.IndexJobKill(p0, p1)
+}
+
+func (s *SaoApiStub) IndexJobKill(p0 context.Context, p1 string) error {
+	return ErrNotSupported
+}
+
+func (s *SaoApiStruct) IndexJobList(p0 context.Context) ([]types.IndexJobInfo, error) {
+	if s.Internal.IndexJobList == nil {
+		return *new([]types.IndexJobInfo), ErrNotSupported
+	}
+	return s.Internal.IndexJobList(p0)
+}
+
+func (s *SaoApiStub) IndexJobList(p0 context.Context) ([]types.IndexJobInfo, error) {
+	return *new([]types.IndexJobInfo), ErrNotSupported
+}
+
+func (s *SaoApiStruct) IndexJobReindex(p0 context.Context, p1 string) error {
+	if s.Internal.IndexJobReindex == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.IndexJobReindex(p0, p1)
+}
+
+func (s *SaoApiStub) IndexJobReindex(p0 context.Context, p1 string) error {
+	return ErrNotSupported
+}
+
 func (s *SaoApiStruct) MigrateJobList(p0 context.Context) ([]types.MigrateInfo, error) {
 	if s.Internal.MigrateJobList == nil {
 		return *new([]types.MigrateInfo), ErrNotSupported
@@ -278,6 +366,39 @@ func (s *SaoApiStub) ModelUpdatePermission(p0 context.Context, p1 *types.Permiss
 	return *new(apitypes.UpdatePermissionResp), ErrNotSupported
 }
 
+func (s *SaoApiStruct) OrderCancel(p0 context.Context, p1 string) error {
+	if s.Internal.OrderCancel == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.OrderCancel(p0, p1)
+}
+
+func (s *SaoApiStub) OrderCancel(p0 context.Context, p1 string) error {
+	return ErrNotSupported
+}
+
+func (s *SaoApiStruct) OrderFix(p0 context.Context, p1 string) error {
+	if s.Internal.OrderFix == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.OrderFix(p0, p1)
+}
+
+func (s *SaoApiStub) OrderFix(p0 context.Context, p1 string) error {
+	return ErrNotSupported
+}
+
+func (s *SaoApiStruct) OrderHistory(p0 context.Context, p1 string, p2 uint64) ([]types.OrderEvent, error) {
+	if s.Internal.OrderHistory == nil {
+		return *new([]types.OrderEvent), ErrNotSupported
+	}
+	return s.Internal.OrderHistory(p0, p1, p2)
+}
+
+func (s *SaoApiStub) OrderHistory(p0 context.Context, p1 string, p2 uint64) ([]types.OrderEvent, error) {
+	return *new([]types.OrderEvent), ErrNotSupported
+}
+
 func (s *SaoApiStruct) OrderList(p0 context.Context) ([]types.OrderInfo, error) {
 	if s.Internal.OrderList == nil {
 		return *new([]types.OrderInfo), ErrNotSupported
@@ -289,6 +410,17 @@ func (s *SaoApiStub) OrderList(p0 context.Context) ([]types.OrderInfo, error) {
 	return *new([]types.OrderInfo), ErrNotSupported
 }
 
+func (s *SaoApiStruct) OrderReassign(p0 context.Context, p1 string, p2 string, p3 string) error {
+	if s.Internal.OrderReassign == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.OrderReassign(p0, p1, p2, p3)
+}
+
+func (s *SaoApiStub) OrderReassign(p0 context.Context, p1 string, p2 string, p3 string) error {
+	return ErrNotSupported
+}
+
 func (s *SaoApiStruct) OrderStatus(p0 context.Context, p1 string) (types.OrderInfo, error) {
 	if s.Internal.OrderStatus == nil {
 		return *new(types.OrderInfo), ErrNotSupported
@@ -311,6 +443,17 @@ func (s *SaoApiStub) RecoverCheck(p0 context.Context, p1 string, p2 []string) (*
 	return nil, ErrNotSupported
 }
 
+func (s *SaoApiStruct) ShardFix(p0 context.Context, p1 uint64, p2 cid.Cid) error {
+	if s.Internal.ShardFix == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.ShardFix(p0, p1, p2)
+}
+
+func (s *SaoApiStub) ShardFix(p0 context.Context, p1 uint64, p2 cid.Cid) error {
+	return ErrNotSupported
+}
+
 func (s *SaoApiStruct) ShardList(p0 context.Context) ([]types.ShardInfo, error) {
 	if s.Internal.ShardList == nil {
 		return *new([]types.ShardInfo), ErrNotSupported
@@ -4,8 +4,11 @@ import (
 	"context"
 	"encoding/hex"
 	"fmt"
+	"sync"
 	"time"
 
+	"github.com/SaoNetwork/sao-node/metrics"
+	"github.com/SaoNetwork/sao-node/tracing"
 	"github.com/SaoNetwork/sao-node/types"
 
 	sdktypes "github.com/cosmos/cosmos-sdk/types"
@@ -24,6 +27,8 @@ import (
 	"github.com/ipfs/go-cid"
 	logging "github.com/ipfs/go-log/v2"
 	"github.com/tendermint/tendermint/rpc/client/http"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var log = logging.Logger("chain")
@@ -42,6 +47,8 @@ type ChainSvc struct {
 	didClient        didtypes.QueryClient
 	modelClient      modeltypes.QueryClient
 	listener         *http.HTTP
+	listenerLk       sync.Mutex
+	subscriptions    txSubscriptions
 	accountRetriever authtypes.AccountRetriever
 	ap               *AddressPool
 	broadcastChanMap map[string]chan BroadcastTxJob
@@ -49,6 +56,7 @@ type ChainSvc struct {
 }
 
 type BroadcastTxJob struct {
+	ctx        context.Context
 	signer     string
 	msg        sdktypes.Msg
 	resultChan chan BroadcastTxJobResult
@@ -61,6 +69,7 @@ type BroadcastTxJobResult struct {
 
 type ChainSvcApi interface {
 	Stop(ctx context.Context) error
+	SetAddressPool(ctx context.Context, ap *AddressPool)
 	GetLastHeight(ctx context.Context) (int64, error)
 	GetAccount(ctx context.Context, address string) (client.Account, error)
 	GetBalance(ctx context.Context, address string) (sdktypes.Coins, error)
@@ -79,6 +88,11 @@ type ChainSvcApi interface {
 	GetNodePeer(ctx context.Context, creator string) (string, error)
 	GetNodeStatus(ctx context.Context, creator string) (uint32, error)
 	ListNodes(ctx context.Context) ([]nodetypes.Node, error)
+	GetPledge(ctx context.Context, creator string) (*nodetypes.Pledge, error)
+	GetPledgeInfo(ctx context.Context, creator string) (*sdktypes.Coin, error)
+	ClaimReward(ctx context.Context, creator string) (string, error)
+	AddVstorage(ctx context.Context, creator string, size uint64) (string, error)
+	RemoveVstorage(ctx context.Context, creator string, size uint64) (string, error)
 	StartStatusReporter(ctx context.Context, creator string, status uint32)
 	OrderReady(ctx context.Context, provider string, orderId uint64) (saotypes.MsgReadyResponse, string, int64, error)
 	StoreOrder(ctx context.Context, signer string, clientProposal *types.OrderStoreProposal) (saotypes.MsgStoreResponse, string, int64, error)
@@ -87,19 +101,25 @@ type ChainSvcApi interface {
 	MigrateOrder(ctx context.Context, creator string, dataIds []string) (string, map[string]string, int64, error)
 	GetOrder(ctx context.Context, orderId uint64) (*ordertypes.FullOrder, error)
 	GetShard(ctx context.Context, shardId uint64) (*ordertypes.Shard, error)
-	//SubscribeOrderComplete(ctx context.Context, orderId uint64, doneChan chan OrderCompleteResult) error
-	//UnsubscribeOrderComplete(ctx context.Context, orderId uint64) error
-	//SubscribeShardTask(ctx context.Context, nodeAddr string, shardTaskChan chan *ShardTask) error
-	//UnsubscribeShardTask(ctx context.Context, nodeAddr string) error
+	ListShards(ctx context.Context, offset uint64, limit uint64) ([]ordertypes.Shard, uint64, error)
+	SubscribeShardTask(ctx context.Context, nodeAddr string, fromHeight int64, shardTaskChan chan *ShardTask) error
+	UnsubscribeShardTask(ctx context.Context, nodeAddr string) error
+	SubscribeShardComplete(ctx context.Context, gateway string, fromHeight int64, shardCompleteChan chan *ShardComplete) error
+	UnsubscribeShardComplete(ctx context.Context, gateway string) error
 	TerminateOrder(ctx context.Context, creator string, terminateProposal types.OrderTerminateProposal) (string, error)
 	GetTx(ctx context.Context, hash string, heigth int64) (*coretypes.ResultTx, error)
 	ReportFaults(ctx context.Context, creator string, provider string, faults []*saotypes.Fault) ([]string, error)
 	RecoverFaults(ctx context.Context, creator string, provider string, faults []*saotypes.Fault) ([]string, error)
+	GetFault(ctx context.Context, faultId string) (*nodetypes.Fault, error)
+	GetMyFaults(ctx context.Context, provider string) ([]string, error)
 	ListMeta(ctx context.Context, offset uint64, limit uint64) ([]modeltypes.Metadata, uint64, error)
 	ListMetaByDid(ctx context.Context, did string) ([]modeltypes.Metadata, error)
 	GetBlock(ctx context.Context, height int64) (*coretypes.ResultBlock, error)
+	GetBlockResults(ctx context.Context, height int64) (*coretypes.ResultBlockResults, error)
 }
 
+var _ ChainSvcApi = (*ChainSvc)(nil)
+
 func NewChainSvc(
 	ctx context.Context,
 	chainAddress string,
@@ -160,7 +180,7 @@ func NewChainSvc(
  *
  * @param respChan is to notify broadcast result
  */
-func (c *ChainSvc) broadcastMsg(signer string, msg sdktypes.Msg, respChan chan BroadcastTxJobResult) {
+func (c *ChainSvc) broadcastMsg(ctx context.Context, signer string, msg sdktypes.Msg, respChan chan BroadcastTxJobResult) {
 	if _, exists := c.broadcastChanMap[signer]; !exists {
 		log.Debugf("broadcast chan for signer %s doesn't exist, create.", signer)
 		c.broadcastChanMap[signer] = make(chan BroadcastTxJob, 1)
@@ -168,7 +188,14 @@ func (c *ChainSvc) broadcastMsg(signer string, msg sdktypes.Msg, respChan chan B
 		time.Sleep(time.Second)
 	}
 
+	// the span covers the time waiting in the queue, it's ended once the tx is broadcast
+	ctx, _ = tracing.StartSpan(ctx, "ChainSvc.broadcast", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("sao.signer", signer),
+		attribute.String("sao.msg", sdktypes.MsgTypeURL(msg)),
+	))
+	metrics.BroadcastQueueDepth.WithLabelValues(signer).Inc()
 	c.broadcastChanMap[signer] <- BroadcastTxJob{
+		ctx:        ctx,
 		signer:     signer,
 		msg:        msg,
 		resultChan: respChan,
@@ -184,23 +211,33 @@ func (c *ChainSvc) broadcastLoop(ctx context.Context, ch chan BroadcastTxJob) {
 	for {
 		select {
 		case job := <-ch:
+			metrics.BroadcastQueueDepth.WithLabelValues(job.signer).Dec()
+			span := trace.SpanFromContext(job.ctx)
+			span.AddEvent("dequeued")
 			signerAcc, err := c.cosmos.Account(job.signer)
 			if err != nil {
+				tracing.RecordError(span, err)
 				job.resultChan <- BroadcastTxJobResult{
 					err: types.Wrap(types.ErrAccountNotFound, err),
 				}
 			} else {
 				txResp, err := c.cosmos.BroadcastTx(ctx, signerAcc, job.msg)
 				if err != nil {
+					tracing.RecordError(span, err)
 					job.resultChan <- BroadcastTxJobResult{
 						err: types.Wrap(types.ErrTxProcessFailed, err),
 					}
 				} else {
+					span.SetAttributes(
+						attribute.String("sao.tx.hash", txResp.TxResponse.TxHash),
+						attribute.Int64("sao.tx.code", int64(txResp.TxResponse.Code)),
+					)
 					job.resultChan <- BroadcastTxJobResult{
 						resp: txResp,
 					}
 				}
 			}
+			span.End()
 		case <-c.stopChan:
 			log.Info("tx broadcast loop stopped.")
 			return
@@ -215,7 +252,8 @@ func (c *ChainSvc) SetAddressPool(ctx context.Context, ap *AddressPool) {
 }
 
 func (c *ChainSvc) Stop(ctx context.Context) error {
-	if c.listener != nil {
+	c.subscriptions.removeAll()
+	if c.listener != nil && c.listener.IsRunning() {
 		log.Infof("Stop chain listener.")
 		err := c.listener.Stop()
 		if err != nil {
@@ -268,6 +306,10 @@ func (c *ChainSvc) GetBlock(ctx context.Context, height int64) (*coretypes.Resul
 	return c.cosmos.RPC.Block(ctx, &height)
 }
 
+func (c *ChainSvc) GetBlockResults(ctx context.Context, height int64) (*coretypes.ResultBlockResults, error) {
+	return c.cosmos.RPC.BlockResults(ctx, &height)
+}
+
 func (c *ChainSvc) GetFishmen(ctx context.Context) (string, error) {
 	resp, err := c.nodeClient.Fishmen(ctx, &nodetypes.QueryFishmenRequest{})
 	if err != nil {
@@ -52,7 +52,7 @@ func (c *ChainSvc) UpdateDidBinding(ctx context.Context, creator string, did str
 		AccountId: accountId,
 	}
 	resultChan := make(chan BroadcastTxJobResult)
-	c.broadcastMsg(creator, msg, resultChan)
+	c.broadcastMsg(ctx, creator, msg, resultChan)
 	result := <-resultChan
 	if result.err != nil {
 		return "", types.Wrap(types.ErrTxProcessFailed, result.err)
@@ -0,0 +1,394 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/SaoNetwork/sao-node/types"
+
+	ordertypes "github.com/SaoNetwork/sao/x/order/types"
+	"github.com/ipfs/go-cid"
+	abcitypes "github.com/tendermint/tendermint/abci/types"
+	coretypes "github.com/tendermint/tendermint/rpc/core/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+const (
+	// tx events missed by the websocket, e.g. while it reconnects, are searched by height at this interval
+	eventBackfillInterval = 10 * Blocktime
+	eventBackfillPageSize = 100
+	eventChanCapacity     = 100
+	// the longest wait between two attempts to subscribe
+	maxSubscribeBackoff = time.Minute
+)
+
+// a shard assigned to a storage provider, parsed from the new-shard event of MsgStore, MsgReady and MsgMigrate.
+type ShardTask struct {
+	OrderId   uint64
+	Gateway   string
+	Provider  string
+	Cid       cid.Cid
+	Operation string
+	TxHash    string
+	Height    int64
+}
+
+// a shard completed by a storage provider, parsed from the shard-completed event of MsgComplete.
+type ShardComplete struct {
+	OrderId  uint64
+	Provider string
+	TxHash   string
+	Height   int64
+}
+
+// handles events of a tx, the txs of a subscription are delivered at most once. txs are mostly delivered in height
+// order, but a tx found by the backfill may be older than the txs pushed by the websocket before it.
+type txEventHandler func(ctx context.Context, height int64, txHash string, events []abcitypes.Event)
+
+type txSubscription struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+type txSubscriptions struct {
+	lk   sync.Mutex
+	subs map[string]*txSubscription
+}
+
+func (s *txSubscriptions) add(ctx context.Context, query string, run func(ctx context.Context)) error {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+
+	if s.subs == nil {
+		s.subs = make(map[string]*txSubscription)
+	}
+	if _, exists := s.subs[query]; exists {
+		return types.Wrapf(types.ErrSubscribeFailed, "%s is subscribed already", query)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	sub := &txSubscription{cancel: cancel, done: make(chan struct{})}
+	s.subs[query] = sub
+	go func() {
+		defer close(sub.done)
+		run(ctx)
+	}()
+	return nil
+}
+
+/**
+ * cancel the subscription and wait for its loop to exit, no event is handled after remove returns.
+ */
+func (s *txSubscriptions) remove(query string) error {
+	s.lk.Lock()
+	sub, exists := s.subs[query]
+	delete(s.subs, query)
+	s.lk.Unlock()
+
+	if !exists {
+		return types.Wrapf(types.ErrUnsubscribeFailed, "%s is not subscribed", query)
+	}
+	sub.cancel()
+	<-sub.done
+	return nil
+}
+
+func (s *txSubscriptions) removeAll() {
+	s.lk.Lock()
+	queries := make([]string, 0, len(s.subs))
+	for query := range s.subs {
+		queries = append(queries, query)
+	}
+	s.lk.Unlock()
+
+	for _, query := range queries {
+		_ = s.remove(query)
+	}
+}
+
+func QueryOrderShard(addr string) string {
+	return fmt.Sprintf("%s.%s='%s'", ordertypes.NewShardEventType, ordertypes.ShardEventProvider, addr)
+}
+
+func QueryShardComplete() string {
+	return fmt.Sprintf("%s.%s EXISTS", ordertypes.ShardCompletedEventType, ordertypes.EventOrderId)
+}
+
+/**
+ * subscribe shard tasks assigned to nodeAddr, tasks of txs after fromHeight are sent to shardTaskChan.
+ * the subscription starts from the latest block if fromHeight is not positive.
+ */
+func (c *ChainSvc) SubscribeShardTask(ctx context.Context, nodeAddr string, fromHeight int64, shardTaskChan chan *ShardTask) error {
+	return c.subscribeTx(ctx, QueryOrderShard(nodeAddr), fromHeight, shardTaskHandler(nodeAddr, shardTaskChan))
+}
+
+func (c *ChainSvc) UnsubscribeShardTask(ctx context.Context, nodeAddr string) error {
+	return c.subscriptions.remove(QueryOrderShard(nodeAddr))
+}
+
+/**
+ * subscribe shards completed on chain for the gateway, shard completions of txs after fromHeight are sent to shardCompleteChan.
+ * the event doesn't tell the order provider, completions of all orders are sent and the gateway picks its own ones.
+ * the subscription starts from the latest block if fromHeight is not positive.
+ */
+func (c *ChainSvc) SubscribeShardComplete(ctx context.Context, gateway string, fromHeight int64, shardCompleteChan chan *ShardComplete) error {
+	return c.subscribeTx(ctx, QueryShardComplete(), fromHeight, shardCompleteHandler(shardCompleteChan))
+}
+
+func (c *ChainSvc) UnsubscribeShardComplete(ctx context.Context, gateway string) error {
+	return c.subscriptions.remove(QueryShardComplete())
+}
+
+/**
+ * call subscribe until it succeeds or ctx is done, failures are logged and retried with backoff.
+ */
+func RetrySubscribe(ctx context.Context, name string, subscribe func(ctx context.Context) error) error {
+	for tries := 1; ; tries++ {
+		err := subscribe(ctx)
+		if err == nil {
+			return nil
+		}
+		backoff := subscribeBackoff(tries)
+		log.Warnf("subscribe %s failed, retry in %v: %v", name, backoff, err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func subscribeBackoff(tries int) time.Duration {
+	backoff := time.Duration(1<<uint(tries)) * time.Second
+	if backoff <= 0 || backoff > maxSubscribeBackoff {
+		backoff = maxSubscribeBackoff
+	}
+	return backoff
+}
+
+func (c *ChainSvc) subscribeTx(ctx context.Context, query string, fromHeight int64, handle txEventHandler) error {
+	if fromHeight <= 0 {
+		latest, err := c.GetLastHeight(ctx)
+		if err != nil {
+			return types.Wrap(types.ErrQueryHeightFailed, err)
+		}
+		// txs of the latest block may be committed before the websocket subscription
+		fromHeight = latest - 1
+	}
+	return c.subscriptions.add(ctx, query, func(ctx context.Context) {
+		c.runTxSubscription(ctx, query, fromHeight, handle)
+	})
+}
+
+/**
+ * deliver txs matching the query after height. txs are pushed by the websocket listener, and searched by height
+ * periodically to backfill the ones missed while the websocket is down. the subscription is retried with backoff.
+ */
+func (c *ChainSvc) runTxSubscription(ctx context.Context, query string, height int64, handle txEventHandler) {
+	// txs above height delivered by the websocket, they are skipped by the backfill
+	delivered := make(map[string]int64)
+	deliver := func(ctx context.Context, txHeight int64, txHash string, events []abcitypes.Event) {
+		if txHeight <= height {
+			return
+		}
+		if _, exists := delivered[txHash]; exists {
+			return
+		}
+		delivered[txHash] = txHeight
+		handle(ctx, txHeight, txHash, events)
+	}
+	backfill := func() {
+		latest, err := c.backfillTx(ctx, query, height, deliver)
+		if err != nil {
+			log.Warnf("backfill %s after height %d failed: %v", query, height, err)
+			return
+		}
+		height = latest
+		for txHash, txHeight := range delivered {
+			if txHeight <= height {
+				delete(delivered, txHash)
+			}
+		}
+	}
+
+	var events <-chan coretypes.ResultEvent
+	tries := 0
+	retry := time.After(0)
+	ticker := time.NewTicker(eventBackfillInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if events != nil {
+				err := c.listener.Unsubscribe(context.Background(), subscriber, query)
+				if err != nil {
+					log.Warnf("unsubscribe %s failed: %v", query, err)
+				}
+			}
+			return
+		case <-retry:
+			ch, err := c.listen(ctx, query)
+			if err != nil {
+				tries++
+				backoff := subscribeBackoff(tries)
+				retry = time.After(backoff)
+				log.Warnf("subscribe %s failed, retry in %v: %v", query, backoff, err)
+			} else {
+				log.Infof("subscribed %s from height %d", query, height)
+				events = ch
+				retry = nil
+			}
+			// catch up with txs committed before the subscription
+			backfill()
+		case event := <-events:
+			data, ok := event.Data.(tmtypes.EventDataTx)
+			if !ok {
+				continue
+			}
+			deliver(ctx, data.Height, fmt.Sprintf("%X", tmtypes.Tx(data.Tx).Hash()), data.Result.Events)
+		case <-ticker.C:
+			backfill()
+		}
+	}
+}
+
+func (c *ChainSvc) listen(ctx context.Context, query string) (<-chan coretypes.ResultEvent, error) {
+	c.listenerLk.Lock()
+	if !c.listener.IsRunning() {
+		err := c.listener.Start()
+		if err != nil {
+			c.listenerLk.Unlock()
+			return nil, types.Wrap(types.ErrSubscribeFailed, err)
+		}
+	}
+	c.listenerLk.Unlock()
+
+	ch, err := c.listener.Subscribe(ctx, subscriber, query, eventChanCapacity)
+	if err != nil {
+		return nil, types.Wrap(types.ErrSubscribeFailed, err)
+	}
+	return ch, nil
+}
+
+/**
+ * search txs matching the query in (from, latest], returns the latest height searched.
+ */
+func (c *ChainSvc) backfillTx(ctx context.Context, query string, from int64, deliver txEventHandler) (int64, error) {
+	latest, err := c.GetLastHeight(ctx)
+	if err != nil {
+		return from, types.Wrap(types.ErrQueryHeightFailed, err)
+	}
+	if latest <= from {
+		return from, nil
+	}
+
+	q := fmt.Sprintf("%s AND tx.height>%d AND tx.height<=%d", query, from, latest)
+	page, perPage := 1, eventBackfillPageSize
+	for {
+		res, err := c.listener.TxSearch(ctx, q, false, &page, &perPage, "asc")
+		if err != nil {
+			return from, types.Wrap(types.ErrTxQueryFailed, err)
+		}
+		for _, tx := range res.Txs {
+			deliver(ctx, tx.Height, fmt.Sprintf("%X", []byte(tx.Hash)), tx.TxResult.Events)
+		}
+		if page*perPage >= res.TotalCount {
+			break
+		}
+		page++
+	}
+	return latest, nil
+}
+
+func shardTaskHandler(nodeAddr string, shardTaskChan chan *ShardTask) txEventHandler {
+	return func(ctx context.Context, height int64, txHash string, events []abcitypes.Event) {
+		for _, task := range parseShardTasks(events, nodeAddr, txHash, height) {
+			select {
+			case shardTaskChan <- task:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+func shardCompleteHandler(shardCompleteChan chan *ShardComplete) txEventHandler {
+	return func(ctx context.Context, height int64, txHash string, events []abcitypes.Event) {
+		for _, complete := range parseShardCompletes(events, txHash, height) {
+			select {
+			case shardCompleteChan <- complete:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+func eventAttributes(event abcitypes.Event) map[string]string {
+	attrs := make(map[string]string)
+	for _, attr := range event.Attributes {
+		// the order id of new-shard is emitted twice, the first one is kept
+		if _, exists := attrs[string(attr.Key)]; !exists {
+			attrs[string(attr.Key)] = string(attr.Value)
+		}
+	}
+	return attrs
+}
+
+func parseShardTasks(events []abcitypes.Event, nodeAddr string, txHash string, height int64) []*ShardTask {
+	tasks := make([]*ShardTask, 0)
+	for _, event := range events {
+		if event.Type != ordertypes.NewShardEventType {
+			continue
+		}
+		attrs := eventAttributes(event)
+		if attrs[ordertypes.ShardEventProvider] != nodeAddr {
+			continue
+		}
+		orderId, err := strconv.ParseUint(attrs[ordertypes.EventOrderId], 10, 64)
+		if err != nil {
+			log.Warnf("invalid order id of new-shard event in tx %s: %v", txHash, err)
+			continue
+		}
+		shardCid, err := cid.Decode(attrs[ordertypes.EventCid])
+		if err != nil {
+			log.Warnf("invalid cid of new-shard event in tx %s: %v", txHash, err)
+			continue
+		}
+		tasks = append(tasks, &ShardTask{
+			OrderId:   orderId,
+			Gateway:   attrs[ordertypes.OrderEventProvider],
+			Provider:  nodeAddr,
+			Cid:       shardCid,
+			Operation: attrs[ordertypes.OrderEventOperation],
+			TxHash:    txHash,
+			Height:    height,
+		})
+	}
+	return tasks
+}
+
+func parseShardCompletes(events []abcitypes.Event, txHash string, height int64) []*ShardComplete {
+	completes := make([]*ShardComplete, 0)
+	for _, event := range events {
+		if event.Type != ordertypes.ShardCompletedEventType {
+			continue
+		}
+		attrs := eventAttributes(event)
+		orderId, err := strconv.ParseUint(attrs[ordertypes.EventOrderId], 10, 64)
+		if err != nil {
+			log.Warnf("invalid order id of shard-completed event in tx %s: %v", txHash, err)
+			continue
+		}
+		completes = append(completes, &ShardComplete{
+			OrderId:  orderId,
+			Provider: attrs[ordertypes.ShardEventProvider],
+			TxHash:   txHash,
+			Height:   height,
+		})
+	}
+	return completes
+}
@@ -0,0 +1,853 @@
+package chain
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SaoNetwork/sao-node/types"
+
+	saodid "github.com/SaoNetwork/sao-did"
+	"github.com/SaoNetwork/sao-did/parser"
+	"github.com/SaoNetwork/sao-did/sid"
+	saodidtypes "github.com/SaoNetwork/sao-did/types"
+	didtypes "github.com/SaoNetwork/sao/x/did/types"
+	modeltypes "github.com/SaoNetwork/sao/x/model/types"
+	nodetypes "github.com/SaoNetwork/sao/x/node/types"
+	ordertypes "github.com/SaoNetwork/sao/x/order/types"
+	saotypes "github.com/SaoNetwork/sao/x/sao/types"
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdktypes "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	"github.com/google/uuid"
+	abcitypes "github.com/tendermint/tendermint/abci/types"
+	coretypes "github.com/tendermint/tendermint/rpc/core/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+	"golang.org/x/xerrors"
+)
+
+const MOCK_CHAIN_PREFIX = "mock://"
+
+const (
+	// every account of the mock chain starts with this balance in DENOM
+	mockDefaultBalance = 1_000_000_000_000
+	// every node of the mock chain starts with this pledge in DENOM and vstorage in bytes
+	mockDefaultPledge     = 1_000_000
+	mockDefaultVstorage   = 1 << 40
+	mockDefaultReputation = 10000.0
+)
+
+var (
+	mockChainsLk sync.Mutex
+	mockChains   = make(map[string]*MockChainSvc)
+)
+
+// in-memory chain simulating the sao chain modules, all nodes attached with the same mock://<name> remote share one instance.
+// signatures are not verified and no fees are charged, the state lives as long as one node is attached.
+type MockChainSvc struct {
+	name      string
+	blocktime time.Duration
+	genesis   time.Time
+	refs      int
+	stopChan  chan struct{}
+
+	lk               sync.Mutex
+	rand             *rand.Rand
+	height           int64
+	txCount          uint64
+	txs              map[string]*coretypes.ResultTx
+	txList           []*coretypes.ResultTx
+	endBlockEvents   map[int64][]abcitypes.Event
+	txNotify         chan struct{}
+	subscriptions    txSubscriptions
+	balances         map[string]sdktypes.Coins
+	pubKeys          map[string]cryptotypes.PubKey
+	accountNums      map[string]uint64
+	nodes            map[string]*nodetypes.Node
+	pledges          map[string]*nodetypes.Pledge
+	fishmen          []string
+	faults           map[string]*nodetypes.Fault
+	sidDocs          map[string]*sid.SidDocument
+	sidVersions      map[string][]string
+	paymentAddresses map[string]string
+	builtinDids      string
+	lastOrderId      uint64
+	lastShardId      uint64
+	orders           map[uint64]*ordertypes.Order
+	shards           map[uint64]*ordertypes.Shard
+	metadata         map[string]*modeltypes.Metadata
+	models           map[string]string
+}
+
+var _ ChainSvcApi = (*MockChainSvc)(nil)
+
+/**
+ * connect to the chain at chainAddress, or attach to the in-memory mock chain if chainAddress is mock://<name>.
+ */
+func NewChainSvcApi(ctx context.Context, chainAddress string, wsEndpoint string, keyringHome string) (ChainSvcApi, error) {
+	if strings.HasPrefix(chainAddress, MOCK_CHAIN_PREFIX) {
+		return NewMockChainSvc(ctx, chainAddress, keyringHome)
+	}
+	return NewChainSvc(ctx, chainAddress, wsEndpoint, keyringHome)
+}
+
+/**
+ * attach to the mock chain named by remote, mock://<name>[?blocktime=<duration>], the chain is created on the first attach.
+ * public keys of the accounts in keyringHome are registered so that their signatures can be verified by other nodes.
+ */
+func NewMockChainSvc(ctx context.Context, remote string, keyringHome string) (*MockChainSvc, error) {
+	u, err := url.Parse(remote)
+	if err != nil || u.Scheme+"://" != MOCK_CHAIN_PREFIX {
+		return nil, types.Wrapf(types.ErrCreateChainServiceFailed, "invalid mock chain remote %s", remote)
+	}
+	name := u.Host + u.Path
+	blocktime := Blocktime
+	if bt := u.Query().Get("blocktime"); bt != "" {
+		blocktime, err = time.ParseDuration(bt)
+		if err != nil || blocktime <= 0 {
+			return nil, types.Wrapf(types.ErrCreateChainServiceFailed, "invalid mock chain blocktime %s", bt)
+		}
+	}
+
+	mockChainsLk.Lock()
+	mc, exists := mockChains[name]
+	if !exists {
+		mc = &MockChainSvc{
+			name:             name,
+			blocktime:        blocktime,
+			genesis:          time.Now(),
+			stopChan:         make(chan struct{}),
+			rand:             rand.New(rand.NewSource(time.Now().UnixNano())),
+			height:           1,
+			txs:              make(map[string]*coretypes.ResultTx),
+			endBlockEvents:   make(map[int64][]abcitypes.Event),
+			txNotify:         make(chan struct{}),
+			balances:         make(map[string]sdktypes.Coins),
+			pubKeys:          make(map[string]cryptotypes.PubKey),
+			accountNums:      make(map[string]uint64),
+			nodes:            make(map[string]*nodetypes.Node),
+			pledges:          make(map[string]*nodetypes.Pledge),
+			faults:           make(map[string]*nodetypes.Fault),
+			sidDocs:          make(map[string]*sid.SidDocument),
+			sidVersions:      make(map[string][]string),
+			paymentAddresses: make(map[string]string),
+			orders:           make(map[uint64]*ordertypes.Order),
+			shards:           make(map[uint64]*ordertypes.Shard),
+			metadata:         make(map[string]*modeltypes.Metadata),
+			models:           make(map[string]string),
+		}
+		mockChains[name] = mc
+
+		// the cosmos client sets the global address prefix on broadcasting, keyring signing by address relies on it.
+		sdktypes.GetConfig().SetBech32PrefixForAccount(ADDRESS_PREFIX, ADDRESS_PREFIX+"pub")
+
+		go mc.produceBlocks()
+		log.Infof("mock chain %s started, blocktime %v", name, blocktime)
+	}
+	mc.refs++
+	mockChainsLk.Unlock()
+
+	if keyringHome != "" {
+		mc.importKeyring(ctx, keyringHome)
+	}
+	return mc, nil
+}
+
+func (mc *MockChainSvc) importKeyring(ctx context.Context, keyringHome string) {
+	registry, err := newAccountRegistry(ctx, keyringHome)
+	if err != nil {
+		log.Warnf("mock chain: failed to open keyring %s: %v", keyringHome, err)
+		return
+	}
+	accounts, err := registry.List()
+	if err != nil {
+		log.Warnf("mock chain: failed to list keyring %s: %v", keyringHome, err)
+		return
+	}
+	for _, account := range accounts {
+		pk, err := account.Record.GetPubKey()
+		if err != nil {
+			continue
+		}
+		address, err := account.Address(ADDRESS_PREFIX)
+		if err != nil {
+			continue
+		}
+		mc.RegisterAccount(address, pk)
+	}
+}
+
+func (mc *MockChainSvc) produceBlocks() {
+	ticker := time.NewTicker(mc.blocktime)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			mc.lk.Lock()
+			mc.height++
+			mc.endBlock()
+			mc.lk.Unlock()
+		case <-mc.stopChan:
+			log.Infof("mock chain %s stopped at height %d", mc.name, mc.height)
+			return
+		}
+	}
+}
+
+/**
+ * advance the chain by n blocks immediately, it's useful for tests waiting on timeouts and expirations.
+ */
+func (mc *MockChainSvc) AdvanceBlocks(n int64) int64 {
+	mc.lk.Lock()
+	defer mc.lk.Unlock()
+
+	for i := int64(0); i < n; i++ {
+		mc.height++
+		mc.endBlock()
+	}
+	return mc.height
+}
+
+/**
+ * register the public key of an account, GetAccount fails for accounts without public key.
+ */
+func (mc *MockChainSvc) RegisterAccount(address string, pk cryptotypes.PubKey) {
+	mc.lk.Lock()
+	defer mc.lk.Unlock()
+
+	mc.pubKeys[address] = pk
+	mc.accountNumber(address)
+}
+
+func (mc *MockChainSvc) Fund(address string, amount int64) {
+	mc.lk.Lock()
+	defer mc.lk.Unlock()
+
+	mc.balances[address] = mc.balance(address).Add(sdktypes.NewInt64Coin(DENOM, amount))
+}
+
+func (mc *MockChainSvc) SetFishmen(addresses ...string) {
+	mc.lk.Lock()
+	defer mc.lk.Unlock()
+
+	mc.fishmen = addresses
+}
+
+func (mc *MockChainSvc) SetBuiltinDids(dids string) {
+	mc.lk.Lock()
+	defer mc.lk.Unlock()
+
+	mc.builtinDids = dids
+}
+
+/**
+ * add a new version of the sid document, the version id is the document's VersionId.
+ */
+func (mc *MockChainSvc) AddSidDocument(docId string, doc *sid.SidDocument) {
+	mc.lk.Lock()
+	defer mc.lk.Unlock()
+
+	mc.sidDocs[doc.VersionId] = doc
+	mc.sidVersions[docId] = append(mc.sidVersions[docId], doc.VersionId)
+}
+
+func (mc *MockChainSvc) accountNumber(address string) uint64 {
+	num, exists := mc.accountNums[address]
+	if !exists {
+		num = uint64(len(mc.accountNums))
+		mc.accountNums[address] = num
+	}
+	return num
+}
+
+func (mc *MockChainSvc) balance(address string) sdktypes.Coins {
+	coins, exists := mc.balances[address]
+	if !exists {
+		coins = sdktypes.NewCoins(sdktypes.NewInt64Coin(DENOM, mockDefaultBalance))
+		mc.balances[address] = coins
+	}
+	return coins
+}
+
+func (mc *MockChainSvc) pledge(creator string) *nodetypes.Pledge {
+	pledge, exists := mc.pledges[creator]
+	if !exists {
+		pledge = &nodetypes.Pledge{
+			Creator:             creator,
+			TotalStoragePledged: sdktypes.NewInt64Coin(DENOM, mockDefaultPledge),
+			TotalShardPledged:   sdktypes.NewInt64Coin(DENOM, 0),
+			Reward:              sdktypes.NewInt64DecCoin(DENOM, 0),
+			RewardDebt:          sdktypes.NewInt64DecCoin(DENOM, 0),
+			TotalStorage:        mockDefaultVstorage,
+		}
+		mc.pledges[creator] = pledge
+	}
+	return pledge
+}
+
+/**
+ * record a successful tx with msg in the current block, the tx can be queried and decoded by GetTx like a real one.
+ * the events are delivered to the tx subscriptions.
+ */
+func (mc *MockChainSvc) commitTx(msg sdktypes.Msg, resp codec.ProtoMarshaler, events ...abcitypes.Event) (string, int64, error) {
+	msgAny, err := codectypes.NewAnyWithValue(msg)
+	if err != nil {
+		return "", -1, types.Wrap(types.ErrTxProcessFailed, err)
+	}
+	mc.txCount++
+	txb := txtypes.Tx{
+		Body: &txtypes.TxBody{
+			Messages: []*codectypes.Any{msgAny},
+			Memo:     fmt.Sprintf("%s-%d", mc.name, mc.txCount),
+		},
+	}
+	txBytes, err := txb.Marshal()
+	if err != nil {
+		return "", -1, types.Wrap(types.ErrTxProcessFailed, err)
+	}
+
+	var txMsgData sdktypes.TxMsgData
+	if resp != nil {
+		respAny, err := codectypes.NewAnyWithValue(resp)
+		if err != nil {
+			return "", -1, types.Wrap(types.ErrTxProcessFailed, err)
+		}
+		txMsgData.MsgResponses = []*codectypes.Any{respAny}
+	}
+	data, err := txMsgData.Marshal()
+	if err != nil {
+		return "", -1, types.Wrap(types.ErrTxProcessFailed, err)
+	}
+
+	tx := tmtypes.Tx(txBytes)
+	hash := fmt.Sprintf("%X", tx.Hash())
+	resultTx := &coretypes.ResultTx{
+		Hash:     tx.Hash(),
+		Height:   mc.height,
+		TxResult: abcitypes.ResponseDeliverTx{Code: 0, Data: data, Events: events},
+		Tx:       tx,
+	}
+	mc.txs[hash] = resultTx
+	mc.txList = append(mc.txList, resultTx)
+	close(mc.txNotify)
+	mc.txNotify = make(chan struct{})
+	return hash, mc.height, nil
+}
+
+func (mc *MockChainSvc) SetAddressPool(ctx context.Context, ap *AddressPool) {
+	// txs of the mock chain are not signed, there is no need to spread them over pool addresses.
+}
+
+func (mc *MockChainSvc) Stop(ctx context.Context) error {
+	mockChainsLk.Lock()
+	defer mockChainsLk.Unlock()
+
+	mc.refs--
+	if mc.refs == 0 {
+		mc.subscriptions.removeAll()
+		close(mc.stopChan)
+		delete(mockChains, mc.name)
+	}
+	return nil
+}
+
+func (mc *MockChainSvc) GetLastHeight(ctx context.Context) (int64, error) {
+	mc.lk.Lock()
+	defer mc.lk.Unlock()
+
+	return mc.height, nil
+}
+
+func (mc *MockChainSvc) GetAccount(ctx context.Context, address string) (client.Account, error) {
+	bz, err := sdktypes.GetFromBech32(address, ADDRESS_PREFIX)
+	if err != nil {
+		return nil, types.Wrap(types.ErrSignedFailed, err)
+	}
+
+	mc.lk.Lock()
+	defer mc.lk.Unlock()
+
+	pk, exists := mc.pubKeys[address]
+	if !exists {
+		return nil, types.Wrapf(types.ErrAccountNotFound, "account %s not found", address)
+	}
+	return authtypes.NewBaseAccount(sdktypes.AccAddress(bz), pk, mc.accountNumber(address), 0), nil
+}
+
+func (mc *MockChainSvc) GetBalance(ctx context.Context, address string) (sdktypes.Coins, error) {
+	mc.lk.Lock()
+	defer mc.lk.Unlock()
+
+	return mc.balance(address), nil
+}
+
+func (mc *MockChainSvc) GetTx(ctx context.Context, hash string, height int64) (*coretypes.ResultTx, error) {
+	if _, err := hex.DecodeString(hash); err != nil {
+		return nil, types.Wrap(types.ErrTxQueryFailed, err)
+	}
+
+	mc.lk.Lock()
+	defer mc.lk.Unlock()
+
+	tx, exists := mc.txs[strings.ToUpper(hash)]
+	if !exists {
+		return nil, types.Wrapf(types.ErrTxQueryFailed, "tx %s not found", hash)
+	}
+	return tx, nil
+}
+
+func (mc *MockChainSvc) GetBlock(ctx context.Context, height int64) (*coretypes.ResultBlock, error) {
+	mc.lk.Lock()
+	defer mc.lk.Unlock()
+
+	if height <= 0 || height > mc.height {
+		return nil, xerrors.Errorf("height %d must be less than or equal to the current blockchain height %d", height, mc.height)
+	}
+	txs := make(tmtypes.Txs, 0)
+	for _, tx := range mc.txList {
+		if tx.Height == height {
+			txs = append(txs, tx.Tx)
+		}
+	}
+	return &coretypes.ResultBlock{
+		Block: &tmtypes.Block{
+			Header: tmtypes.Header{
+				ChainID: "mock-" + mc.name,
+				Height:  height,
+				Time:    mc.genesis.Add(time.Duration(height) * mc.blocktime),
+			},
+			Data: tmtypes.Data{Txs: txs},
+		},
+	}, nil
+}
+
+/**
+ * results of the block at height, the end-block events are the order timeouts of the block.
+ */
+func (mc *MockChainSvc) GetBlockResults(ctx context.Context, height int64) (*coretypes.ResultBlockResults, error) {
+	mc.lk.Lock()
+	defer mc.lk.Unlock()
+
+	if height <= 0 || height > mc.height {
+		return nil, xerrors.Errorf("height %d must be less than or equal to the current blockchain height %d", height, mc.height)
+	}
+	results := &coretypes.ResultBlockResults{
+		Height:         height,
+		EndBlockEvents: mc.endBlockEvents[height],
+	}
+	for _, tx := range mc.txList {
+		if tx.Height == height {
+			txResult := tx.TxResult
+			results.TxsResults = append(results.TxsResults, &txResult)
+		}
+	}
+	return results, nil
+}
+
+func (mc *MockChainSvc) GetFishmen(ctx context.Context) (string, error) {
+	mc.lk.Lock()
+	defer mc.lk.Unlock()
+
+	return strings.Join(mc.fishmen, ","), nil
+}
+
+// ----------------- node -----------------
+
+func (mc *MockChainSvc) Create(ctx context.Context, creator string) (string, error) {
+	mc.lk.Lock()
+	defer mc.lk.Unlock()
+
+	if _, exists := mc.nodes[creator]; exists {
+		return "", types.Wrapf(types.ErrTxProcessFailed, "MsgCreate: node %s exists", creator)
+	}
+	mc.createNode(creator)
+	hash, _, err := mc.commitTx(&nodetypes.MsgCreate{Creator: creator}, &nodetypes.MsgCreateResponse{})
+	return hash, err
+}
+
+func (mc *MockChainSvc) createNode(creator string) *nodetypes.Node {
+	node := &nodetypes.Node{
+		Creator:         creator,
+		Reputation:      mockDefaultReputation,
+		Status:          nodetypes.NODE_STATUS_ONLINE,
+		LastAliveHeight: mc.height,
+		Role:            uint32(nodetypes.NODE_NORMAL),
+	}
+	mc.nodes[creator] = node
+	mc.pledge(creator)
+	return node
+}
+
+/**
+ * unlike the real chain, nodes not created by `saonode init` are registered on the first reset.
+ */
+func (mc *MockChainSvc) Reset(ctx context.Context, creator string, peerInfo string, status uint32, txAddresses []string, description *nodetypes.Description) (string, error) {
+	mc.lk.Lock()
+	defer mc.lk.Unlock()
+
+	node, exists := mc.nodes[creator]
+	if !exists {
+		node = mc.createNode(creator)
+	}
+	if status != nodetypes.NODE_STATUS_NA {
+		node.Status = status
+	}
+	if peerInfo != "" {
+		node.Peer = peerInfo
+	}
+	if description != nil {
+		node.Description = description
+	}
+	if len(txAddresses) > 0 {
+		node.TxAddresses = txAddresses
+	}
+	node.LastAliveHeight = mc.height
+
+	hash, _, err := mc.commitTx(&nodetypes.MsgReset{
+		Creator:     creator,
+		Peer:        peerInfo,
+		Status:      status,
+		TxAddresses: txAddresses,
+		Description: description,
+	}, &nodetypes.MsgResetResponse{})
+	return hash, err
+}
+
+func (mc *MockChainSvc) ClaimReward(ctx context.Context, creator string) (string, error) {
+	mc.lk.Lock()
+	defer mc.lk.Unlock()
+
+	if _, exists := mc.nodes[creator]; !exists {
+		return "", types.Wrapf(types.ErrTxProcessFailed, "MsgClaimReward: node %s not found", creator)
+	}
+	hash, _, err := mc.commitTx(&nodetypes.MsgClaimReward{Creator: creator}, &nodetypes.MsgClaimRewardResponse{})
+	return hash, err
+}
+
+func (mc *MockChainSvc) AddVstorage(ctx context.Context, creator string, size uint64) (string, error) {
+	mc.lk.Lock()
+	defer mc.lk.Unlock()
+
+	pledge := mc.pledge(creator)
+	pledge.TotalStorage += int64(size)
+	hash, _, err := mc.commitTx(&nodetypes.MsgAddVstorage{Creator: creator, Size_: size}, &nodetypes.MsgAddVstorageResponse{})
+	return hash, err
+}
+
+func (mc *MockChainSvc) RemoveVstorage(ctx context.Context, creator string, size uint64) (string, error) {
+	mc.lk.Lock()
+	defer mc.lk.Unlock()
+
+	pledge := mc.pledge(creator)
+	if pledge.TotalStorage-int64(size) < pledge.UsedStorage {
+		return "", types.Wrapf(types.ErrTxProcessFailed, "MsgRemoveVstorage: vstorage %d is less than used storage %d", pledge.TotalStorage-int64(size), pledge.UsedStorage)
+	}
+	pledge.TotalStorage -= int64(size)
+	hash, _, err := mc.commitTx(&nodetypes.MsgRemoveVstorage{Creator: creator, Size_: size}, &nodetypes.MsgRemoveVstorageResponse{})
+	return hash, err
+}
+
+func (mc *MockChainSvc) GetNodePeer(ctx context.Context, creator string) (string, error) {
+	mc.lk.Lock()
+	defer mc.lk.Unlock()
+
+	node, exists := mc.nodes[creator]
+	if !exists {
+		return "", types.Wrapf(types.ErrQueryNodeFailed, "node %s not found", creator)
+	}
+	return node.Peer, nil
+}
+
+func (mc *MockChainSvc) GetNodeStatus(ctx context.Context, creator string) (uint32, error) {
+	mc.lk.Lock()
+	defer mc.lk.Unlock()
+
+	node, exists := mc.nodes[creator]
+	if !exists {
+		return 0, types.Wrapf(types.ErrQueryNodeFailed, "node %s not found", creator)
+	}
+	return node.Status, nil
+}
+
+func (mc *MockChainSvc) ListNodes(ctx context.Context) ([]nodetypes.Node, error) {
+	mc.lk.Lock()
+	defer mc.lk.Unlock()
+
+	nodes := make([]nodetypes.Node, 0, len(mc.nodes))
+	for _, node := range mc.nodes {
+		nodes = append(nodes, *node)
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Creator < nodes[j].Creator
+	})
+	return nodes, nil
+}
+
+func (mc *MockChainSvc) GetPledgeInfo(ctx context.Context, creator string) (*sdktypes.Coin, error) {
+	mc.lk.Lock()
+	defer mc.lk.Unlock()
+
+	pledged := mc.pledge(creator).TotalStoragePledged
+	return &pledged, nil
+}
+
+func (mc *MockChainSvc) GetPledge(ctx context.Context, creator string) (*nodetypes.Pledge, error) {
+	mc.lk.Lock()
+	defer mc.lk.Unlock()
+
+	pledge := *mc.pledge(creator)
+	return &pledge, nil
+}
+
+func (mc *MockChainSvc) StartStatusReporter(ctx context.Context, creator string, status uint32) {
+	go func() {
+		ticker := time.NewTicker(15 * time.Minute)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				_, err := mc.Reset(ctx, creator, "", status, make([]string, 0), nil)
+				if err != nil {
+					log.Error(err.Error())
+				}
+			case <-mc.stopChan:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// ----------------- faults -----------------
+
+func (mc *MockChainSvc) ReportFaults(ctx context.Context, creator string, provider string, faults []*saotypes.Fault) ([]string, error) {
+	mc.lk.Lock()
+	defer mc.lk.Unlock()
+
+	isFisherman := false
+	for _, fisherman := range mc.fishmen {
+		if fisherman == creator {
+			isFisherman = true
+		}
+	}
+	if !isFisherman {
+		return nil, types.Wrapf(types.ErrTxProcessFailed, "MsgReportFaults: %s is not a fishmen", creator)
+	}
+
+	faultIds := make([]string, 0)
+	for _, f := range faults {
+		if f.Provider != provider {
+			continue
+		}
+		order, exists := mc.orders[f.OrderId]
+		if !exists || order.DataId != f.DataId {
+			continue
+		}
+		shard, exists := mc.shards[f.ShardId]
+		if !exists || shard.Sp != provider || shard.OrderId != order.Id {
+			continue
+		}
+
+		fault := mc.faultByShard(provider, f.ShardId)
+		if fault == nil {
+			fault = &nodetypes.Fault{
+				FaultId:  uuid.NewString(),
+				OrderId:  f.OrderId,
+				DataId:   f.DataId,
+				ShardId:  f.ShardId,
+				CommitId: f.CommitId,
+				Provider: provider,
+				Reporter: creator,
+				Confirms: "+" + creator,
+				Status:   nodetypes.FaultStatusConfirming,
+			}
+			mc.faults[fault.FaultId] = fault
+		} else if !strings.Contains(fault.Confirms, "+"+creator) {
+			fault.Confirms = fault.Confirms + "|+" + creator
+			if strings.Count(fault.Confirms, "+") > 2 {
+				fault.Status = nodetypes.FaultStatusConfirmed
+			}
+		} else {
+			continue
+		}
+		faultIds = append(faultIds, fault.FaultId)
+	}
+
+	_, _, err := mc.commitTx(&saotypes.MsgReportFaults{Creator: creator, Provider: provider, Faults: faults}, &saotypes.MsgReportFaultsResponse{})
+	if err != nil {
+		return nil, err
+	}
+	return faultIds, nil
+}
+
+func (mc *MockChainSvc) RecoverFaults(ctx context.Context, creator string, provider string, faults []*saotypes.Fault) ([]string, error) {
+	mc.lk.Lock()
+	defer mc.lk.Unlock()
+
+	faultIds := make([]string, 0)
+	for _, f := range faults {
+		fault := mc.faultByShard(provider, f.ShardId)
+		if fault == nil {
+			continue
+		}
+		delete(mc.faults, fault.FaultId)
+		faultIds = append(faultIds, fault.FaultId)
+	}
+
+	_, _, err := mc.commitTx(&saotypes.MsgRecoverFaults{Creator: creator, Provider: provider, Faults: faults}, &saotypes.MsgRecoverFaultsResponse{})
+	if err != nil {
+		return nil, err
+	}
+	return faultIds, nil
+}
+
+func (mc *MockChainSvc) faultByShard(provider string, shardId uint64) *nodetypes.Fault {
+	for _, fault := range mc.faults {
+		if fault.Provider == provider && fault.ShardId == shardId {
+			return fault
+		}
+	}
+	return nil
+}
+
+func (mc *MockChainSvc) GetFault(ctx context.Context, faultId string) (*nodetypes.Fault, error) {
+	mc.lk.Lock()
+	defer mc.lk.Unlock()
+
+	fault, exists := mc.faults[faultId]
+	if !exists {
+		return nil, types.Wrapf(types.ErrQueryNodeFailed, "fault %s not found", faultId)
+	}
+	f := *fault
+	return &f, nil
+}
+
+func (mc *MockChainSvc) GetMyFaults(ctx context.Context, provider string) ([]string, error) {
+	mc.lk.Lock()
+	defer mc.lk.Unlock()
+
+	faultIds := make([]string, 0)
+	for _, fault := range mc.faults {
+		if fault.Provider == provider {
+			faultIds = append(faultIds, fault.FaultId)
+		}
+	}
+	sort.Strings(faultIds)
+	return faultIds, nil
+}
+
+// ----------------- did -----------------
+
+func (mc *MockChainSvc) QueryDidParams(ctx context.Context) (string, error) {
+	mc.lk.Lock()
+	defer mc.lk.Unlock()
+
+	return mc.builtinDids, nil
+}
+
+func (mc *MockChainSvc) GetSidDocument(ctx context.Context, versionId string) (*sid.SidDocument, error) {
+	mc.lk.Lock()
+	defer mc.lk.Unlock()
+
+	doc, exists := mc.sidDocs[versionId]
+	if !exists {
+		return nil, nil
+	}
+	return doc, nil
+}
+
+func (mc *MockChainSvc) UpdateDidBinding(ctx context.Context, creator string, did string, accountId string) (string, error) {
+	mc.lk.Lock()
+	defer mc.lk.Unlock()
+
+	// account id is in the form of <namespace>:<chain>:<address>
+	parts := strings.Split(accountId, ":")
+	mc.paymentAddresses[did] = parts[len(parts)-1]
+	hash, _, err := mc.commitTx(&didtypes.MsgUpdatePaymentAddress{Creator: creator, Did: did, AccountId: accountId}, &didtypes.MsgUpdatePaymentAddressResponse{})
+	return hash, err
+}
+
+func (mc *MockChainSvc) QueryPaymentAddress(ctx context.Context, did string) (string, error) {
+	mc.lk.Lock()
+	defer mc.lk.Unlock()
+
+	address, exists := mc.paymentAddresses[did]
+	if !exists {
+		return "", xerrors.Errorf("payment address of %s not found", did)
+	}
+	return address, nil
+}
+
+func (mc *MockChainSvc) GetDidInfo(ctx context.Context, did string) (types.DidInfo, error) {
+	pd, err := parser.Parse(did)
+	if err != nil {
+		return nil, err
+	}
+
+	paymentAddress, err := mc.QueryPaymentAddress(ctx, did)
+	if err != nil {
+		return nil, err
+	}
+
+	resolve := func(did string) (saodidtypes.DidDocument, error) {
+		didManager, err := saodid.NewDidManagerWithDid(did, func(versionId string) (*sid.SidDocument, error) {
+			return mc.GetSidDocument(ctx, versionId)
+		})
+		if err != nil {
+			return saodidtypes.DidDocument{}, err
+		}
+		result := didManager.Resolver.Resolve(did, saodidtypes.DidResolutionOptions{})
+		if result.DidResolutionMetadata.Error != "" {
+			return saodidtypes.DidDocument{}, xerrors.New(result.DidResolutionMetadata.Error)
+		}
+		return result.DidDocument, nil
+	}
+
+	switch pd.Method {
+	case "sid":
+		mc.lk.Lock()
+		versions := append([]string(nil), mc.sidVersions[pd.ID]...)
+		mc.lk.Unlock()
+
+		info := types.SidInfo{
+			Did:            did,
+			PaymentAddress: paymentAddress,
+		}
+		for _, version := range versions {
+			doc, err := resolve("did:sid:" + pd.ID + "?versionId=" + version)
+			if err != nil {
+				return nil, err
+			}
+			info.DidDocuments = append(info.DidDocuments, types.DidDocument{
+				Version:  version,
+				Document: doc,
+			})
+		}
+		return info, nil
+	case "key":
+		doc, err := resolve(did)
+		if err != nil {
+			return nil, err
+		}
+		return types.KidInfo{
+			Did:            did,
+			PaymentAddress: paymentAddress,
+			Document:       doc,
+		}, nil
+	default:
+		return nil, xerrors.New("Unsupported did type")
+	}
+}
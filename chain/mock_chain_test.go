@@ -0,0 +1,250 @@
+package chain
+
+import (
+	"context"
+	"testing"
+
+	"github.com/SaoNetwork/sao-node/types"
+	"github.com/SaoNetwork/sao-node/utils"
+
+	modeltypes "github.com/SaoNetwork/sao/x/model/types"
+	nodetypes "github.com/SaoNetwork/sao/x/node/types"
+	ordertypes "github.com/SaoNetwork/sao/x/order/types"
+	saotypes "github.com/SaoNetwork/sao/x/sao/types"
+	sdktypes "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMockChainOrder(t *testing.T) {
+	ctx := context.Background()
+
+	mc, err := NewMockChainSvc(ctx, "mock://TestMockChainOrder?blocktime=1h", "")
+	require.NoError(t, err)
+	defer mc.Stop(ctx)
+
+	// nodes attached to the same name share the chain
+	var chainApi ChainSvcApi
+	chainApi, err = NewChainSvcApi(ctx, "mock://TestMockChainOrder", "", "")
+	require.NoError(t, err)
+	require.Equal(t, mc, chainApi)
+	defer chainApi.Stop(ctx)
+
+	gateway := "sao1gateway"
+	sps := []string{"sao1sp1", "sao1sp2", "sao1sp3"}
+	storageStatus := nodetypes.NODE_STATUS_ONLINE | nodetypes.NODE_STATUS_SERVE_STORAGE | nodetypes.NODE_STATUS_ACCEPT_ORDER
+	_, err = mc.Reset(ctx, gateway, "/ip4/127.0.0.1/tcp/5153/p2p/gateway", nodetypes.NODE_STATUS_ONLINE|nodetypes.NODE_STATUS_SERVE_GATEWAY, nil, nil)
+	require.NoError(t, err)
+	for _, sp := range sps {
+		_, err = mc.Reset(ctx, sp, "/ip4/127.0.0.1/tcp/5153/p2p/"+sp, storageStatus, nil, nil)
+		require.NoError(t, err)
+	}
+
+	content := []byte("hello mock chain")
+	contentCid, err := utils.CalculateCid(content)
+	require.NoError(t, err)
+	dataId := utils.GenerateDataId("mock")
+	proposal := types.OrderStoreProposal{
+		Proposal: saotypes.Proposal{
+			Owner:     "did:key:owner",
+			Provider:  gateway,
+			GroupId:   "group",
+			Duration:  100,
+			Replica:   2,
+			Timeout:   10,
+			Alias:     "alias",
+			DataId:    dataId,
+			CommitId:  dataId,
+			Cid:       contentCid.String(),
+			Size_:     uint64(len(content)),
+			Operation: 1,
+		},
+	}
+	resp, txHash, height, err := mc.StoreOrder(ctx, gateway, &proposal)
+	require.NoError(t, err)
+	require.Len(t, resp.Shards, 2)
+
+	// the store tx can be decoded like a real one
+	resultTx, err := mc.GetTx(ctx, txHash, height)
+	require.NoError(t, err)
+	var txMsgData sdktypes.TxMsgData
+	require.NoError(t, txMsgData.Unmarshal(resultTx.TxResult.Data))
+	var storeResp saotypes.MsgStoreResponse
+	require.NoError(t, storeResp.Unmarshal(txMsgData.MsgResponses[0].Value))
+	require.Equal(t, resp.OrderId, storeResp.OrderId)
+
+	// the next commit has to wait until the order completes
+	update := proposal
+	update.Proposal.CommitId = dataId + "|" + utils.GenerateCommitId("mock")
+	_, _, _, err = mc.StoreOrder(ctx, gateway, &update)
+	require.Error(t, err)
+
+	sp := resp.Shards[0].Sp
+	completeHash, completeHeight, err := mc.CompleteOrder(ctx, sp, resp.OrderId, contentCid, uint64(len(content)))
+	require.NoError(t, err)
+	resultTx, err = mc.GetTx(ctx, completeHash, completeHeight)
+	require.NoError(t, err)
+	var txb tx.Tx
+	require.NoError(t, txb.Unmarshal(resultTx.Tx))
+	var completeMsg saotypes.MsgComplete
+	require.NoError(t, completeMsg.Unmarshal(txb.Body.Messages[0].Value))
+	require.Equal(t, resp.OrderId, completeMsg.OrderId)
+
+	order, err := mc.GetOrder(ctx, resp.OrderId)
+	require.NoError(t, err)
+	require.Equal(t, int32(ordertypes.OrderCompleted), order.Status)
+	require.Equal(t, int32(ordertypes.ShardCompleted), order.Shards[sp].Status)
+	pledge, err := mc.GetPledge(ctx, sp)
+	require.NoError(t, err)
+	require.Equal(t, int64(len(content)), pledge.UsedStorage)
+
+	meta, err := mc.QueryMetadata(ctx, &types.MetadataProposal{
+		Proposal: saotypes.QueryProposal{
+			Owner:       "did:key:owner",
+			Keyword:     "alias",
+			GroupId:     "group",
+			KeywordType: 2,
+		},
+	}, 0)
+	require.NoError(t, err)
+	require.Equal(t, dataId, meta.Metadata.DataId)
+	require.Len(t, meta.Metadata.Commits, 1)
+	require.Len(t, meta.Shards, 2)
+
+	_, err = mc.QueryMetadata(ctx, &types.MetadataProposal{
+		Proposal: saotypes.QueryProposal{Owner: "did:key:other", Keyword: dataId},
+	}, 0)
+	require.Error(t, err)
+
+	// renew requires every shard to be completed
+	_, _, err = mc.CompleteOrder(ctx, resp.Shards[1].Sp, resp.OrderId, contentCid, uint64(len(content)))
+	require.NoError(t, err)
+	_, results, err := mc.RenewOrder(ctx, gateway, types.OrderRenewProposal{
+		Proposal: saotypes.RenewProposal{Owner: "did:key:owner", Duration: 50, Timeout: 10, Data: []string{dataId}},
+	})
+	require.NoError(t, err)
+	require.Contains(t, results[dataId], "SUCCESS")
+
+	_, err = mc.TerminateOrder(ctx, gateway, types.OrderTerminateProposal{
+		Proposal: saotypes.TerminateProposal{Owner: "did:key:owner", DataId: dataId},
+	})
+	require.NoError(t, err)
+	_, err = mc.GetMeta(ctx, dataId)
+	require.Error(t, err)
+	metas, total, err := mc.ListMeta(ctx, 0, 10)
+	require.NoError(t, err)
+	require.Empty(t, metas)
+	require.Zero(t, total)
+	pledge, err = mc.GetPledge(ctx, sp)
+	require.NoError(t, err)
+	require.Zero(t, pledge.UsedStorage)
+}
+
+func TestMockChainTimeout(t *testing.T) {
+	ctx := context.Background()
+
+	mc, err := NewMockChainSvc(ctx, "mock://TestMockChainTimeout?blocktime=1h", "")
+	require.NoError(t, err)
+	defer mc.Stop(ctx)
+
+	_, err = mc.Reset(ctx, "sao1gateway", "", nodetypes.NODE_STATUS_ONLINE|nodetypes.NODE_STATUS_SERVE_STORAGE|nodetypes.NODE_STATUS_ACCEPT_ORDER, nil, nil)
+	require.NoError(t, err)
+
+	contentCid, err := cid.Decode("bafkreifzjut3te2nhyekklss27nh3k72ysco7y32koao5eei66wof36n5e")
+	require.NoError(t, err)
+	dataId := utils.GenerateDataId("mock")
+	resp, _, _, err := mc.StoreOrder(ctx, "sao1gateway", &types.OrderStoreProposal{
+		Proposal: saotypes.Proposal{
+			Owner:     "did:key:owner",
+			Provider:  "sao1gateway",
+			Duration:  100,
+			Replica:   1,
+			Timeout:   5,
+			DataId:    dataId,
+			CommitId:  dataId,
+			Cid:       contentCid.String(),
+			Size_:     1,
+			Operation: 1,
+		},
+	})
+	require.NoError(t, err)
+
+	mc.AdvanceBlocks(6)
+	order, err := mc.GetOrder(ctx, resp.OrderId)
+	require.NoError(t, err)
+	require.Equal(t, int32(ordertypes.OrderExpired), order.Status)
+	require.Equal(t, int32(ordertypes.ShardTimeout), order.Shards["sao1gateway"].Status)
+
+	meta, err := mc.GetMeta(ctx, dataId)
+	require.NoError(t, err)
+	require.Equal(t, int32(modeltypes.MetaNew), meta.Metadata.Status)
+}
+
+func TestMockChainEvents(t *testing.T) {
+	ctx := context.Background()
+
+	mc, err := NewMockChainSvc(ctx, "mock://TestMockChainEvents?blocktime=1h", "")
+	require.NoError(t, err)
+	defer mc.Stop(ctx)
+
+	gateway := "sao1gateway"
+	sp := "sao1sp"
+	_, err = mc.Reset(ctx, gateway, "/ip4/127.0.0.1/tcp/5153/p2p/gateway", nodetypes.NODE_STATUS_ONLINE|nodetypes.NODE_STATUS_SERVE_GATEWAY, nil, nil)
+	require.NoError(t, err)
+	_, err = mc.Reset(ctx, sp, "/ip4/127.0.0.1/tcp/5153/p2p/sp", mockSpStatus, nil, nil)
+	require.NoError(t, err)
+	startHeight := mc.AdvanceBlocks(1)
+
+	taskChan := make(chan *ShardTask, 1)
+	require.NoError(t, mc.SubscribeShardTask(ctx, sp, 0, taskChan))
+	require.Error(t, mc.SubscribeShardTask(ctx, sp, 0, taskChan))
+	mc.AdvanceBlocks(1)
+
+	content := []byte("hello mock chain events")
+	contentCid, err := utils.CalculateCid(content)
+	require.NoError(t, err)
+	dataId := utils.GenerateDataId("mock")
+	resp, txHash, height, err := mc.StoreOrder(ctx, gateway, &types.OrderStoreProposal{
+		Proposal: saotypes.Proposal{
+			Owner:     "did:key:owner",
+			Provider:  gateway,
+			Duration:  100,
+			Replica:   1,
+			Timeout:   10,
+			DataId:    dataId,
+			CommitId:  dataId,
+			Cid:       contentCid.String(),
+			Size_:     uint64(len(content)),
+			Operation: 1,
+		},
+	})
+	require.NoError(t, err)
+
+	task := <-taskChan
+	require.Equal(t, &ShardTask{
+		OrderId:   resp.OrderId,
+		Gateway:   gateway,
+		Provider:  sp,
+		Cid:       contentCid,
+		Operation: "1",
+		TxHash:    txHash,
+		Height:    height,
+	}, task)
+	require.NoError(t, mc.UnsubscribeShardTask(ctx, sp))
+	require.Error(t, mc.UnsubscribeShardTask(ctx, sp))
+
+	completeHash, completeHeight, err := mc.CompleteOrder(ctx, sp, resp.OrderId, contentCid, uint64(len(content)))
+	require.NoError(t, err)
+
+	// a subscription from an earlier height gets the txs committed before it
+	completeChan := make(chan *ShardComplete, 1)
+	require.NoError(t, mc.SubscribeShardComplete(ctx, gateway, startHeight, completeChan))
+	defer mc.UnsubscribeShardComplete(ctx, gateway)
+	require.Equal(t, &ShardComplete{
+		OrderId:  resp.OrderId,
+		Provider: sp,
+		TxHash:   completeHash,
+		Height:   completeHeight,
+	}, <-completeChan)
+}
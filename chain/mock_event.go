@@ -0,0 +1,74 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+
+	abcitypes "github.com/tendermint/tendermint/abci/types"
+)
+
+func mockEvent(eventType string, attrs ...string) abcitypes.Event {
+	event := abcitypes.Event{Type: eventType}
+	for i := 0; i+1 < len(attrs); i += 2 {
+		event.Attributes = append(event.Attributes, abcitypes.EventAttribute{
+			Key:   []byte(attrs[i]),
+			Value: []byte(attrs[i+1]),
+			Index: true,
+		})
+	}
+	return event
+}
+
+func (mc *MockChainSvc) SubscribeShardTask(ctx context.Context, nodeAddr string, fromHeight int64, shardTaskChan chan *ShardTask) error {
+	return mc.subscribeTx(ctx, QueryOrderShard(nodeAddr), fromHeight, shardTaskHandler(nodeAddr, shardTaskChan))
+}
+
+func (mc *MockChainSvc) UnsubscribeShardTask(ctx context.Context, nodeAddr string) error {
+	return mc.subscriptions.remove(QueryOrderShard(nodeAddr))
+}
+
+func (mc *MockChainSvc) SubscribeShardComplete(ctx context.Context, gateway string, fromHeight int64, shardCompleteChan chan *ShardComplete) error {
+	// gateways attached to the mock chain share the subscriptions, so they are told apart by the gateway
+	return mc.subscribeTx(ctx, mockSubscriptionKey(QueryShardComplete(), gateway), fromHeight, shardCompleteHandler(shardCompleteChan))
+}
+
+func (mc *MockChainSvc) UnsubscribeShardComplete(ctx context.Context, gateway string) error {
+	return mc.subscriptions.remove(mockSubscriptionKey(QueryShardComplete(), gateway))
+}
+
+func mockSubscriptionKey(query string, subscriber string) string {
+	return fmt.Sprintf("%s/%s", query, subscriber)
+}
+
+/**
+ * queries are not evaluated by the mock chain, every tx after fromHeight is handed to the handler,
+ * which picks the events it's interested in.
+ */
+func (mc *MockChainSvc) subscribeTx(ctx context.Context, key string, fromHeight int64, handle txEventHandler) error {
+	if fromHeight <= 0 {
+		latest, _ := mc.GetLastHeight(ctx)
+		fromHeight = latest - 1
+	}
+	return mc.subscriptions.add(ctx, key, func(ctx context.Context) {
+		next := 0
+		for {
+			mc.lk.Lock()
+			txs := mc.txList[next:]
+			notify := mc.txNotify
+			mc.lk.Unlock()
+
+			for _, tx := range txs {
+				next++
+				if tx.Height > fromHeight {
+					handle(ctx, tx.Height, fmt.Sprintf("%X", tx.Hash), tx.TxResult.Events)
+				}
+			}
+
+			select {
+			case <-notify:
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+}
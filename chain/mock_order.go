@@ -0,0 +1,996 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/SaoNetwork/sao-node/types"
+
+	modeltypes "github.com/SaoNetwork/sao/x/model/types"
+	nodetypes "github.com/SaoNetwork/sao/x/node/types"
+	ordertypes "github.com/SaoNetwork/sao/x/order/types"
+	saotypes "github.com/SaoNetwork/sao/x/sao/types"
+	sdktypes "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ipfs/go-cid"
+	abcitypes "github.com/tendermint/tendermint/abci/types"
+	"golang.org/x/xerrors"
+)
+
+// storage providers are chosen among nodes having all these status bits
+const mockSpStatus = nodetypes.NODE_STATUS_ONLINE | nodetypes.NODE_STATUS_SERVE_STORAGE | nodetypes.NODE_STATUS_ACCEPT_ORDER
+
+/**
+ * time out orders not completed in time, and expire orders whose shards all passed the duration.
+ * timed out orders are listed in an end-block event like the chain does. the caller must hold mc.lk.
+ */
+func (mc *MockChainSvc) endBlock() {
+	height := uint64(mc.height)
+	timeouts := make([]uint64, 0)
+	for _, order := range mc.orders {
+		switch order.Status {
+		case ordertypes.OrderPending, ordertypes.OrderDataReady, ordertypes.OrderInProgress:
+			if order.CreatedAt+order.Timeout >= height {
+				continue
+			}
+			for _, id := range order.Shards {
+				if shard, exists := mc.shards[id]; exists && shard.Status == ordertypes.ShardWaiting {
+					shard.Status = ordertypes.ShardTimeout
+				}
+			}
+			order.Status = ordertypes.OrderExpired
+			timeouts = append(timeouts, order.Id)
+		case ordertypes.OrderCompleted:
+			expired := len(order.Shards) > 0
+			for _, id := range order.Shards {
+				if shard, exists := mc.shards[id]; exists && mc.shardExpireAt(shard) >= height {
+					expired = false
+				}
+			}
+			if expired {
+				order.Status = ordertypes.OrderExpired
+			}
+		}
+	}
+	if len(timeouts) > 0 {
+		sort.Slice(timeouts, func(i, j int) bool { return timeouts[i] < timeouts[j] })
+		mc.endBlockEvents[mc.height] = append(mc.endBlockEvents[mc.height],
+			mockEvent(saotypes.OrderTimeoutEventType, saotypes.EventTimeoutOrderList, fmt.Sprintf("%v", timeouts)))
+	}
+}
+
+func (mc *MockChainSvc) shardExpireAt(shard *ordertypes.Shard) uint64 {
+	expireAt := shard.CreatedAt + shard.Duration
+	for _, info := range shard.RenewInfos {
+		expireAt += info.Duration
+	}
+	return expireAt
+}
+
+/**
+ * pick count storage providers at random with enough vstorage left, except the ignored ones.
+ */
+func (mc *MockChainSvc) randomSps(count int, ignore []string, size uint64) []string {
+	candidates := make([]string, 0)
+nodeLoop:
+	for _, node := range mc.nodes {
+		if node.Status&mockSpStatus != mockSpStatus || node.Role != uint32(nodetypes.NODE_NORMAL) {
+			continue
+		}
+		for _, s := range ignore {
+			if s == node.Creator {
+				continue nodeLoop
+			}
+		}
+		pledge := mc.pledge(node.Creator)
+		if pledge.TotalStorage-pledge.UsedStorage < int64(size) {
+			continue
+		}
+		candidates = append(candidates, node.Creator)
+	}
+	sort.Strings(candidates)
+	mc.rand.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+	if len(candidates) > count {
+		candidates = candidates[:count]
+	}
+	return candidates
+}
+
+func (mc *MockChainSvc) selectSps(order *ordertypes.Order) ([]string, error) {
+	if order.Replica <= 0 {
+		return nil, xerrors.Errorf("replica should > 0")
+	}
+
+	sps := make([]string, 0)
+	if order.Operation == 2 {
+		// force push keeps the data on the providers of the last commit
+		if meta, exists := mc.metadata[order.DataId]; exists {
+			if lastOrder, exists := mc.orders[meta.OrderId]; exists {
+				for _, id := range lastOrder.Shards {
+					if shard, exists := mc.shards[id]; exists && len(sps) < int(order.Replica) {
+						sps = append(sps, shard.Sp)
+					}
+				}
+			}
+		}
+	}
+	sps = append(sps, mc.randomSps(int(order.Replica)-len(sps), sps, order.Size_)...)
+	if len(sps) < int(order.Replica) {
+		return nil, xerrors.Errorf("replica should <= %d", len(sps))
+	}
+	return sps, nil
+}
+
+/**
+ * assign a shard of the order to each of sps, returns the new-shard events.
+ */
+func (mc *MockChainSvc) assignShards(order *ordertypes.Order, sps []string) []abcitypes.Event {
+	events := make([]abcitypes.Event, 0, len(sps))
+	for _, sp := range sps {
+		mc.lastShardId++
+		mc.shards[mc.lastShardId] = &ordertypes.Shard{
+			Id:      mc.lastShardId,
+			OrderId: order.Id,
+			Status:  ordertypes.ShardWaiting,
+			Size_:   order.Size_,
+			Cid:     order.Cid,
+			Pledge:  sdktypes.NewInt64Coin(DENOM, 0),
+			Sp:      sp,
+		}
+		order.Shards = append(order.Shards, mc.lastShardId)
+		events = append(events, newShardEvent(order, sp, order.Cid))
+	}
+	order.Status = ordertypes.OrderDataReady
+	return events
+}
+
+func newShardEvent(order *ordertypes.Order, provider string, cid string) abcitypes.Event {
+	return mockEvent(ordertypes.NewShardEventType,
+		ordertypes.EventOrderId, fmt.Sprintf("%d", order.Id),
+		ordertypes.OrderEventProvider, order.Provider,
+		ordertypes.ShardEventProvider, provider,
+		ordertypes.EventCid, cid,
+		ordertypes.EventOrderId, fmt.Sprintf("%d", order.Id),
+		ordertypes.OrderEventOperation, fmt.Sprintf("%d", order.Operation),
+	)
+}
+
+func (mc *MockChainSvc) orderShardBySp(order *ordertypes.Order, sp string) *ordertypes.Shard {
+	for _, id := range order.Shards {
+		if shard, exists := mc.shards[id]; exists && shard.Sp == sp {
+			return shard
+		}
+	}
+	return nil
+}
+
+func (mc *MockChainSvc) removeShard(order *ordertypes.Order, shard *ordertypes.Shard) {
+	if shard.Status == ordertypes.ShardCompleted {
+		pledge := mc.pledge(shard.Sp)
+		pledge.UsedStorage -= int64(shard.Size_)
+	}
+	shardIds := make([]uint64, 0, len(order.Shards))
+	for _, id := range order.Shards {
+		if id != shard.Id {
+			shardIds = append(shardIds, id)
+		}
+	}
+	order.Shards = shardIds
+	delete(mc.shards, shard.Id)
+}
+
+func (mc *MockChainSvc) shardMetas(order *ordertypes.Order) map[string]*saotypes.ShardMeta {
+	shards := make(map[string]*saotypes.ShardMeta)
+	for _, id := range order.Shards {
+		shard, exists := mc.shards[id]
+		if !exists {
+			continue
+		}
+		node, exists := mc.nodes[shard.Sp]
+		if !exists {
+			continue
+		}
+		shards[shard.Sp] = &saotypes.ShardMeta{
+			ShardId:  shard.Id,
+			Peer:     node.Peer,
+			Cid:      shard.Cid,
+			Provider: shard.Sp,
+			Sp:       shard.Sp,
+		}
+	}
+	return shards
+}
+
+func (mc *MockChainSvc) newShardList(order *ordertypes.Order) []*saotypes.ShardMeta {
+	shards := make([]*saotypes.ShardMeta, 0)
+	for _, id := range order.Shards {
+		shard := mc.shards[id]
+		node, exists := mc.nodes[shard.Sp]
+		if !exists {
+			continue
+		}
+		shards = append(shards, &saotypes.ShardMeta{
+			ShardId:  shard.Id,
+			Peer:     node.Peer,
+			Cid:      shard.Cid,
+			Provider: order.Provider,
+			Sp:       shard.Sp,
+		})
+	}
+	return shards
+}
+
+func (mc *MockChainSvc) canWrite(meta *modeltypes.Metadata, did string) bool {
+	if meta.Owner == did {
+		return true
+	}
+	for _, readwriteDid := range meta.ReadwriteDids {
+		if readwriteDid == did {
+			return true
+		}
+	}
+	return false
+}
+
+func (mc *MockChainSvc) canRead(meta *modeltypes.Metadata, did string) bool {
+	if mc.canWrite(meta, did) {
+		return true
+	}
+	for _, dids := range [][]string{meta.ReadonlyDids, meta.ReadwriteDids} {
+		for _, d := range dids {
+			if d == did || (mc.builtinDids != "" && strings.Contains(mc.builtinDids, d)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func orderAmount(size uint64, replica int32, duration uint64) sdktypes.Coin {
+	// unit price is 10^-6 DENOM per byte per block
+	total := size * uint64(replica) * duration
+	amount := total / 1_000_000
+	if total%1_000_000 != 0 {
+		amount++
+	}
+	return sdktypes.NewInt64Coin(DENOM, int64(amount))
+}
+
+func copyMeta(meta *modeltypes.Metadata) modeltypes.Metadata {
+	m := *meta
+	m.Tags = append([]string(nil), meta.Tags...)
+	m.Commits = append([]string(nil), meta.Commits...)
+	m.ReadonlyDids = append([]string(nil), meta.ReadonlyDids...)
+	m.ReadwriteDids = append([]string(nil), meta.ReadwriteDids...)
+	m.Orders = append([]uint64(nil), meta.Orders...)
+	return m
+}
+
+// ----------------- order -----------------
+
+func (mc *MockChainSvc) StoreOrder(ctx context.Context, signer string, clientProposal *types.OrderStoreProposal) (saotypes.MsgStoreResponse, string, int64, error) {
+	mc.lk.Lock()
+	defer mc.lk.Unlock()
+
+	fail := func(format string, args ...interface{}) (saotypes.MsgStoreResponse, string, int64, error) {
+		return saotypes.MsgStoreResponse{}, "", -1, types.Wrapf(types.ErrTxProcessFailed, "MsgStore: "+format, args...)
+	}
+
+	proposal := clientProposal.Proposal
+	if proposal.CommitId == "" {
+		return fail("invalid commitId")
+	}
+	if proposal.DataId == "" {
+		return fail("invalid dataId")
+	}
+	if proposal.Operation < 1 || proposal.Operation > 2 {
+		return fail("invalid operation %d", proposal.Operation)
+	}
+	if _, err := cid.Decode(proposal.Cid); err != nil {
+		return fail("invalid cid: %s", proposal.Cid)
+	}
+	if proposal.Timeout == 0 {
+		return fail("invalid arguments: timeout")
+	}
+	if _, exists := mc.nodes[proposal.Provider]; !exists {
+		return fail("%s does not register yet", proposal.Provider)
+	}
+
+	meta, found := mc.metadata[proposal.DataId]
+	if !strings.Contains(proposal.CommitId, proposal.DataId) {
+		if !found {
+			return fail("metadata %s not found", proposal.DataId)
+		}
+		if !mc.canWrite(meta, proposal.Owner) {
+			return fail("no permission to update the model")
+		}
+	}
+
+	commitId := proposal.CommitId
+	lastCommitId := proposal.CommitId
+	if strings.Contains(proposal.CommitId, "|") {
+		lastCommitId = strings.Split(proposal.CommitId, "|")[0]
+		commitId = strings.Split(proposal.CommitId, "|")[1]
+	}
+	if proposal.Size_ == 0 {
+		proposal.Size_ = 1
+	}
+
+	modelKey := fmt.Sprintf("%s-%s-%s", proposal.Owner, proposal.Alias, proposal.GroupId)
+	if found {
+		lastOrder, exists := mc.orders[meta.OrderId]
+		if !exists {
+			return fail("invalid last order: %d", meta.OrderId)
+		}
+		if lastOrder.Status != ordertypes.OrderCompleted {
+			return fail("unexpected last order: %d, status: %d", meta.OrderId, lastOrder.Status)
+		}
+		if !strings.Contains(meta.Commit, lastCommitId) {
+			return fail("invalid commitId: %s, detected version conficts, should be %s", lastCommitId, meta.Commit)
+		}
+	} else {
+		if len(proposal.DataId) != 36 {
+			return fail("invalid dataId: %s", proposal.DataId)
+		}
+		if _, exists := mc.models[modelKey]; exists {
+			return fail("model key %s exists", modelKey)
+		}
+	}
+
+	order := &ordertypes.Order{
+		Creator:   signer,
+		Owner:     proposal.Owner,
+		Provider:  proposal.Provider,
+		Cid:       proposal.Cid,
+		Duration:  proposal.Duration,
+		Status:    ordertypes.OrderPending,
+		Replica:   proposal.Replica,
+		Amount:    orderAmount(proposal.Size_, proposal.Replica, proposal.Duration),
+		Size_:     proposal.Size_,
+		Operation: proposal.Operation,
+		CreatedAt: uint64(mc.height),
+		Timeout:   uint64(proposal.Timeout),
+		DataId:    proposal.DataId,
+		Commit:    commitId,
+		UnitPrice: sdktypes.NewDecCoinFromDec(DENOM, sdktypes.NewDecWithPrec(1, 6)),
+	}
+
+	// shards are assigned at once if the gateway stores the order, otherwise in OrderReady
+	isProvider := proposal.Provider == signer
+	var sps []string
+	if isProvider {
+		var err error
+		sps, err = mc.selectSps(order)
+		if err != nil {
+			return fail("%v", err)
+		}
+	}
+
+	mc.lastOrderId++
+	order.Id = mc.lastOrderId
+	mc.orders[order.Id] = order
+
+	if found {
+		meta.OrderId = order.Id
+		meta.Status = int32(order.Operation)
+	} else {
+		mc.metadata[proposal.DataId] = &modeltypes.Metadata{
+			DataId:        proposal.DataId,
+			Owner:         proposal.Owner,
+			Alias:         proposal.Alias,
+			GroupId:       proposal.GroupId,
+			OrderId:       order.Id,
+			Tags:          proposal.Tags,
+			Cid:           proposal.Cid,
+			ExtendInfo:    proposal.ExtendInfo,
+			Commit:        commitId,
+			Rule:          proposal.Rule,
+			Duration:      proposal.Duration,
+			CreatedAt:     uint64(mc.height),
+			ReadonlyDids:  proposal.ReadonlyDids,
+			ReadwriteDids: proposal.ReadwriteDids,
+			Status:        modeltypes.MetaNew,
+		}
+		mc.models[modelKey] = proposal.DataId
+	}
+
+	resp := saotypes.MsgStoreResponse{OrderId: order.Id}
+	var events []abcitypes.Event
+	if isProvider {
+		events = mc.assignShards(order, sps)
+		resp.Shards = mc.newShardList(order)
+	}
+
+	hash, height, err := mc.commitTx(&saotypes.MsgStore{
+		Creator:      signer,
+		Proposal:     proposal,
+		JwsSignature: clientProposal.JwsSignature,
+		Provider:     signer,
+	}, &resp, events...)
+	if err != nil {
+		return saotypes.MsgStoreResponse{}, "", -1, err
+	}
+	return resp, hash, height, nil
+}
+
+func (mc *MockChainSvc) OrderReady(ctx context.Context, provider string, orderId uint64) (saotypes.MsgReadyResponse, string, int64, error) {
+	mc.lk.Lock()
+	defer mc.lk.Unlock()
+
+	order, exists := mc.orders[orderId]
+	if !exists {
+		return saotypes.MsgReadyResponse{}, "", -1, types.Wrapf(types.ErrTxProcessFailed, "MsgReady: order %d not found", orderId)
+	}
+	if order.Provider != provider {
+		return saotypes.MsgReadyResponse{}, "", -1, types.Wrapf(types.ErrTxProcessFailed, "MsgReady: %s is not the provider of order %d", provider, orderId)
+	}
+	if order.Status != ordertypes.OrderPending {
+		return saotypes.MsgReadyResponse{}, "", -1, types.Wrapf(types.ErrTxProcessFailed, "MsgReady: expect pending order, but got status %d", order.Status)
+	}
+
+	sps, err := mc.selectSps(order)
+	if err != nil {
+		return saotypes.MsgReadyResponse{}, "", -1, types.Wrapf(types.ErrTxProcessFailed, "MsgReady: %v", err)
+	}
+	events := mc.assignShards(order, sps)
+
+	resp := saotypes.MsgReadyResponse{
+		OrderId: order.Id,
+		Shards:  mc.newShardList(order),
+	}
+	hash, height, err := mc.commitTx(&saotypes.MsgReady{Creator: provider, OrderId: orderId, Provider: provider}, &resp, events...)
+	if err != nil {
+		return saotypes.MsgReadyResponse{}, "", -1, err
+	}
+	return resp, hash, height, nil
+}
+
+func (mc *MockChainSvc) CompleteOrder(ctx context.Context, creator string, orderId uint64, cid cid.Cid, size uint64) (string, int64, error) {
+	mc.lk.Lock()
+	defer mc.lk.Unlock()
+
+	fail := func(format string, args ...interface{}) (string, int64, error) {
+		return "", -1, types.Wrapf(types.ErrTxProcessFailed, "MsgComplete: "+format, args...)
+	}
+
+	if size == 0 {
+		return fail("order %d shard %v: invalid shard size %d", orderId, cid, size)
+	}
+	order, exists := mc.orders[orderId]
+	if !exists {
+		return fail("order %d not found", orderId)
+	}
+	shard := mc.orderShardBySp(order, creator)
+	if shard == nil {
+		return fail("%s is not the order shard provider", creator)
+	}
+	if shard.Status == ordertypes.ShardCompleted {
+		return fail("%s already completed the shard task in order %d", creator, orderId)
+	}
+	if shard.Status != ordertypes.ShardWaiting && shard.Status != ordertypes.ShardMigrating {
+		return fail("invalid shard status, expect: waiting/migrating")
+	}
+	if size != shard.Size_ {
+		return fail("order %d shard %v: invalid shard size %d, expect %d", orderId, cid, size, shard.Size_)
+	}
+	meta, exists := mc.metadata[order.DataId]
+	if !exists {
+		return fail("metadata %s not found", order.DataId)
+	}
+
+	height := uint64(mc.height)
+	if shard.Status == ordertypes.ShardMigrating {
+		oldShard := mc.orderShardBySp(order, shard.From)
+		if oldShard == nil {
+			return fail("shard of %s to migrate from not found", shard.From)
+		}
+		shard.RenewInfos = oldShard.RenewInfos
+		shard.CreatedAt = height
+		if oldShard.CreatedAt+oldShard.Duration > height {
+			shard.Duration = oldShard.CreatedAt + oldShard.Duration - height
+		}
+		mc.removeShard(order, oldShard)
+	} else {
+		shard.CreatedAt = height
+		shard.Duration = order.Duration
+		if order.Status != ordertypes.OrderCompleted {
+			mc.completeMeta(meta, order)
+			order.Status = ordertypes.OrderCompleted
+		}
+	}
+	shard.Status = ordertypes.ShardCompleted
+	shard.Cid = cid.String()
+	mc.pledge(creator).UsedStorage += int64(shard.Size_)
+
+	return mc.commitTx(&saotypes.MsgComplete{
+		Creator:  creator,
+		OrderId:  orderId,
+		Cid:      cid.String(),
+		Size_:    size,
+		Provider: creator,
+	}, &saotypes.MsgCompleteResponse{}, mockEvent(ordertypes.ShardCompletedEventType,
+		ordertypes.EventOrderId, fmt.Sprintf("%d", orderId),
+		ordertypes.ShardEventProvider, creator,
+	))
+}
+
+/**
+ * apply a completed order to its metadata as the model keeper does.
+ */
+func (mc *MockChainSvc) completeMeta(meta *modeltypes.Metadata, order *ordertypes.Order) {
+	switch order.Operation {
+	case 1:
+		meta.Cid = order.Cid
+		meta.Commit = order.Commit
+		meta.Commits = append(meta.Commits, version(order.Commit, mc.height))
+		meta.Orders = append(meta.Orders, order.Id)
+	case 2:
+		// force push replaces the last commit
+		if len(meta.Commits) > 0 {
+			meta.Commits = meta.Commits[:len(meta.Commits)-1]
+		}
+		meta.Cid = order.Cid
+		meta.Commit = order.Commit
+		meta.Commits = append(meta.Commits, version(order.Commit, mc.height))
+		meta.Orders = append(meta.Orders, order.Id)
+	case 3:
+		meta.Orders = append(meta.Orders, order.Id)
+	}
+	meta.OrderId = order.Id
+	meta.Status = modeltypes.MetaComplete
+}
+
+func version(commit string, height int64) string {
+	return fmt.Sprintf("%s\x1a%d", commit, height)
+}
+
+func (mc *MockChainSvc) RenewOrder(ctx context.Context, creator string, orderRenewProposal types.OrderRenewProposal) (string, map[string]string, error) {
+	mc.lk.Lock()
+	defer mc.lk.Unlock()
+
+	proposal := orderRenewProposal.Proposal
+	resp := saotypes.MsgRenewResponse{Result: make([]*saotypes.KV, 0)}
+	result := func(dataId string, format string, args ...interface{}) {
+		resp.Result = append(resp.Result, &saotypes.KV{K: dataId, V: fmt.Sprintf(format, args...)})
+	}
+
+dataLoop:
+	for _, dataId := range proposal.Data {
+		meta, exists := mc.metadata[dataId]
+		if !exists {
+			result(dataId, "FAILED: dataId %s not found", dataId)
+			continue
+		}
+		if meta.Owner != proposal.Owner {
+			result(dataId, "FAILED: no permission to renew the model %s", dataId)
+			continue
+		}
+		if meta.Status != modeltypes.MetaComplete {
+			result(dataId, "FAILED: try to renew uncompleted model %s", dataId)
+			continue
+		}
+		order, exists := mc.orders[meta.OrderId]
+		if !exists {
+			result(dataId, "FAILED: invalid order id: %d", meta.OrderId)
+			continue
+		}
+		if order.Status != ordertypes.OrderCompleted {
+			result(dataId, "FAILED: invalid order status: %d", order.Status)
+			continue
+		}
+		shards := make([]*ordertypes.Shard, 0)
+		for _, id := range order.Shards {
+			shard, exists := mc.shards[id]
+			if !exists {
+				result(dataId, "FAILED: shardId %d not found", id)
+				continue dataLoop
+			}
+			if shard.Status != ordertypes.ShardCompleted && shard.Status != ordertypes.ShardMigrating {
+				result(dataId, "FAILED: invalid shard status: %d", shard.Status)
+				continue dataLoop
+			}
+			shards = append(shards, shard)
+		}
+
+		mc.lastOrderId++
+		newOrder := &ordertypes.Order{
+			Creator:   creator,
+			Owner:     order.Owner,
+			Id:        mc.lastOrderId,
+			Provider:  creator,
+			Cid:       order.Cid,
+			Duration:  proposal.Duration,
+			Status:    order.Status,
+			Replica:   order.Replica,
+			Shards:    append([]uint64(nil), order.Shards...),
+			Amount:    orderAmount(order.Size_, order.Replica, proposal.Duration),
+			Size_:     order.Size_,
+			Operation: 3,
+			CreatedAt: uint64(mc.height),
+			Timeout:   uint64(proposal.Timeout),
+			DataId:    order.DataId,
+			Commit:    order.Commit,
+			UnitPrice: order.UnitPrice,
+		}
+		mc.orders[newOrder.Id] = newOrder
+
+		var expireAt uint64
+		for _, shard := range shards {
+			if shard.Status == ordertypes.ShardMigrating {
+				continue
+			}
+			shard.RenewInfos = append(shard.RenewInfos, ordertypes.RenewInfo{
+				OrderId:  newOrder.Id,
+				Pledge:   sdktypes.NewInt64Coin(DENOM, 0),
+				Duration: proposal.Duration,
+			})
+			if mc.shardExpireAt(shard) > expireAt {
+				expireAt = mc.shardExpireAt(shard)
+			}
+		}
+		if expireAt > meta.CreatedAt {
+			meta.Duration = expireAt - meta.CreatedAt
+		}
+		mc.completeMeta(meta, newOrder)
+
+		result(dataId, "SUCCESS: orderId=%d", newOrder.Id)
+	}
+
+	hash, _, err := mc.commitTx(&saotypes.MsgRenew{
+		Creator:      creator,
+		Proposal:     proposal,
+		JwsSignature: orderRenewProposal.JwsSignature,
+		Provider:     creator,
+	}, &resp)
+	if err != nil {
+		return "", nil, err
+	}
+	results := make(map[string]string)
+	for _, r := range resp.Result {
+		results[r.K] = r.V
+	}
+	return hash, results, nil
+}
+
+func (mc *MockChainSvc) MigrateOrder(ctx context.Context, creator string, dataIds []string) (string, map[string]string, int64, error) {
+	mc.lk.Lock()
+	defer mc.lk.Unlock()
+
+	resp := saotypes.MsgMigrateResponse{Result: make([]*saotypes.KV, 0)}
+	events := make([]abcitypes.Event, 0)
+	for _, dataId := range dataIds {
+		meta, exists := mc.metadata[dataId]
+		if !exists {
+			resp.Result = append(resp.Result, &saotypes.KV{K: dataId, V: fmt.Sprintf("FAILED: dataId %s not found", dataId)})
+			continue
+		}
+
+		successInfo := "[orderId:new storage provider]: ["
+		commits := make(map[string]struct{})
+	orderLoop:
+		for i := len(meta.Orders) - 1; i >= 0; i-- {
+			order, exists := mc.orders[meta.Orders[i]]
+			if !exists {
+				continue
+			}
+			if _, exists := commits[order.Commit]; exists {
+				continue
+			}
+			commits[order.Commit] = struct{}{}
+
+			oldShard := mc.orderShardBySp(order, creator)
+			if oldShard == nil || oldShard.Status != ordertypes.ShardCompleted {
+				continue
+			}
+
+			ignore := make([]string, 0)
+			for _, id := range order.Shards {
+				shard, exists := mc.shards[id]
+				if !exists {
+					continue
+				}
+				if shard.From == creator {
+					continue orderLoop
+				}
+				ignore = append(ignore, shard.Sp)
+			}
+			sps := mc.randomSps(1, ignore, oldShard.Size_)
+			if len(sps) == 0 {
+				continue
+			}
+
+			mc.lastShardId++
+			mc.shards[mc.lastShardId] = &ordertypes.Shard{
+				Id:      mc.lastShardId,
+				OrderId: order.Id,
+				Status:  ordertypes.ShardMigrating,
+				Size_:   oldShard.Size_,
+				Cid:     oldShard.Cid,
+				Pledge:  sdktypes.NewInt64Coin(DENOM, 0),
+				From:    creator,
+				Sp:      sps[0],
+			}
+			order.Shards = append(order.Shards, mc.lastShardId)
+			// the chain names the provider migrated from in the event, not the new one
+			events = append(events, newShardEvent(order, creator, oldShard.Cid))
+			successInfo += fmt.Sprintf("%d:%v  ", order.Id, sps[0])
+		}
+		successInfo += "]"
+		resp.Result = append(resp.Result, &saotypes.KV{K: dataId, V: fmt.Sprintf("SUCCESS: %s", successInfo)})
+	}
+
+	hash, height, err := mc.commitTx(&saotypes.MsgMigrate{Creator: creator, Data: dataIds, Provider: creator}, &resp, events...)
+	if err != nil {
+		return "", nil, -1, err
+	}
+	results := make(map[string]string)
+	for _, r := range resp.Result {
+		results[r.K] = r.V
+	}
+	return hash, results, height, nil
+}
+
+func (mc *MockChainSvc) TerminateOrder(ctx context.Context, creator string, terminateProposal types.OrderTerminateProposal) (string, error) {
+	mc.lk.Lock()
+	defer mc.lk.Unlock()
+
+	proposal := terminateProposal.Proposal
+	meta, exists := mc.metadata[proposal.DataId]
+	if !exists {
+		return "", types.Wrapf(types.ErrTxProcessFailed, "MsgTerminate: metadata %s not found", proposal.DataId)
+	}
+	if meta.Owner != proposal.Owner {
+		return "", types.Wrapf(types.ErrTxProcessFailed, "MsgTerminate: no permission to terminate the model %s", proposal.DataId)
+	}
+
+	events := make([]abcitypes.Event, 0)
+	orderIds := append([]uint64{meta.OrderId}, meta.Orders...)
+	for _, orderId := range orderIds {
+		order, exists := mc.orders[orderId]
+		if !exists {
+			continue
+		}
+		for _, id := range append([]uint64(nil), order.Shards...) {
+			if shard, exists := mc.shards[id]; exists {
+				mc.removeShard(order, shard)
+			}
+		}
+		order.Status = ordertypes.OrderTerminated
+		events = append(events, mockEvent(ordertypes.TerminateOrderEventType, ordertypes.EventOrderId, fmt.Sprintf("%d", orderId)))
+	}
+	delete(mc.models, fmt.Sprintf("%s-%s-%s", meta.Owner, meta.Alias, meta.GroupId))
+	delete(mc.metadata, proposal.DataId)
+
+	hash, _, err := mc.commitTx(&saotypes.MsgTerminate{
+		Creator:      creator,
+		Proposal:     proposal,
+		JwsSignature: terminateProposal.JwsSignature,
+		Provider:     creator,
+	}, &saotypes.MsgTerminateResponse{}, events...)
+	return hash, err
+}
+
+func (mc *MockChainSvc) GetOrder(ctx context.Context, orderId uint64) (*ordertypes.FullOrder, error) {
+	mc.lk.Lock()
+	defer mc.lk.Unlock()
+
+	order, exists := mc.orders[orderId]
+	if !exists {
+		return nil, types.Wrapf(types.ErrQueryOrderFailed, "order %d not found", orderId)
+	}
+	shards := make(map[string]*ordertypes.Shard)
+	for _, id := range order.Shards {
+		if shard, exists := mc.shards[id]; exists {
+			s := *shard
+			shards[s.Sp] = &s
+		}
+	}
+	return &ordertypes.FullOrder{
+		Creator:   order.Creator,
+		Owner:     order.Owner,
+		Id:        order.Id,
+		Provider:  order.Provider,
+		Cid:       order.Cid,
+		Duration:  order.Duration,
+		Status:    order.Status,
+		Replica:   order.Replica,
+		ShardIds:  append([]uint64(nil), order.Shards...),
+		Shards:    shards,
+		Amount:    order.Amount,
+		Size_:     order.Size_,
+		Operation: order.Operation,
+		CreatedAt: order.CreatedAt,
+		Timeout:   order.Timeout,
+		DataId:    order.DataId,
+		Commit:    order.Commit,
+		UnitPrice: order.UnitPrice,
+	}, nil
+}
+
+func (mc *MockChainSvc) GetShard(ctx context.Context, shardId uint64) (*ordertypes.Shard, error) {
+	mc.lk.Lock()
+	defer mc.lk.Unlock()
+
+	shard, exists := mc.shards[shardId]
+	if !exists {
+		return nil, types.Wrapf(types.ErrQueryShardFailed, "shard %d not found", shardId)
+	}
+	s := *shard
+	return &s, nil
+}
+
+func (mc *MockChainSvc) ListShards(ctx context.Context, offset uint64, limit uint64) ([]ordertypes.Shard, uint64, error) {
+	mc.lk.Lock()
+	defer mc.lk.Unlock()
+
+	ids := make([]uint64, 0, len(mc.shards))
+	for id := range mc.shards {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	shards := make([]ordertypes.Shard, 0)
+	for i := offset; i < uint64(len(ids)) && i < offset+limit; i++ {
+		shards = append(shards, *mc.shards[ids[i]])
+	}
+	return shards, uint64(len(ids)), nil
+}
+
+// ----------------- model -----------------
+
+func (mc *MockChainSvc) QueryMetadata(ctx context.Context, req *types.MetadataProposal, height int64) (*saotypes.QueryMetadataResponse, error) {
+	mc.lk.Lock()
+	defer mc.lk.Unlock()
+
+	// the mock chain keeps no history, the latest state is returned for any height
+	proposal := req.Proposal
+	dataId := proposal.Keyword
+	if proposal.KeywordType > 1 {
+		owner := proposal.DataOwner
+		if owner == "" {
+			owner = proposal.Owner
+		}
+		var exists bool
+		dataId, exists = mc.models[fmt.Sprintf("%s-%s-%s", owner, proposal.Keyword, proposal.GroupId)]
+		if !exists {
+			return nil, types.Wrapf(types.ErrQueryMetadataFailed, "dataId not found by Alias: %s", proposal.Keyword)
+		}
+	}
+
+	meta, exists := mc.metadata[dataId]
+	if !exists {
+		return nil, types.Wrapf(types.ErrQueryMetadataFailed, "dataId:%s not found", dataId)
+	}
+	if !mc.canRead(meta, proposal.Owner) {
+		return nil, types.Wrapf(types.ErrQueryMetadataFailed, "no permission to read the model %s", dataId)
+	}
+	order, exists := mc.orders[meta.OrderId]
+	if !exists {
+		return nil, types.Wrapf(types.ErrQueryMetadataFailed, "order:%d not found", meta.OrderId)
+	}
+
+	m := copyMeta(meta)
+	return &saotypes.QueryMetadataResponse{
+		Metadata: saotypes.Metadata{
+			DataId:     m.DataId,
+			Owner:      m.Owner,
+			Alias:      m.Alias,
+			GroupId:    m.GroupId,
+			OrderId:    m.OrderId,
+			Tags:       m.Tags,
+			Cid:        m.Cid,
+			Commits:    m.Commits,
+			ExtendInfo: m.ExtendInfo,
+			Update:     m.Update,
+			Commit:     m.Commit,
+			Rule:       m.Rule,
+			Duration:   m.Duration,
+			CreatedAt:  m.CreatedAt,
+			Provider:   order.Provider,
+			Expire:     int32(order.CreatedAt + order.Timeout),
+			Status:     order.Status,
+			Replica:    order.Replica,
+			Amount:     order.Amount,
+			Size_:      order.Size_,
+			Operation:  order.Operation,
+		},
+		Shards: mc.shardMetas(order),
+	}, nil
+}
+
+func (mc *MockChainSvc) GetMeta(ctx context.Context, dataId string) (*modeltypes.QueryGetMetadataResponse, error) {
+	mc.lk.Lock()
+	defer mc.lk.Unlock()
+
+	meta, exists := mc.metadata[dataId]
+	if !exists {
+		return nil, types.Wrapf(types.ErrQueryMetadataFailed, "dataId:%s not found", dataId)
+	}
+	shards := make(map[string]*modeltypes.ShardMeta)
+	if order, exists := mc.orders[meta.OrderId]; exists {
+		for sp, shard := range mc.shardMetas(order) {
+			shards[sp] = &modeltypes.ShardMeta{
+				ShardId: shard.ShardId,
+				Peer:    shard.Peer,
+				Cid:     shard.Cid,
+			}
+		}
+	}
+	return &modeltypes.QueryGetMetadataResponse{
+		Metadata: copyMeta(meta),
+		OrderId:  meta.OrderId,
+		Shards:   shards,
+	}, nil
+}
+
+func (mc *MockChainSvc) GetModel(ctx context.Context, key string) (*modeltypes.QueryGetModelResponse, error) {
+	mc.lk.Lock()
+	defer mc.lk.Unlock()
+
+	dataId, exists := mc.models[key]
+	if !exists {
+		return nil, xerrors.Errorf("model %s not found", key)
+	}
+	return &modeltypes.QueryGetModelResponse{
+		Model: modeltypes.Model{Key: key, Data: dataId},
+	}, nil
+}
+
+func (mc *MockChainSvc) UpdatePermission(ctx context.Context, signer string, proposal *types.PermissionProposal) (string, error) {
+	mc.lk.Lock()
+	defer mc.lk.Unlock()
+
+	meta, exists := mc.metadata[proposal.Proposal.DataId]
+	if !exists {
+		return "", types.Wrapf(types.ErrTxProcessFailed, "MsgUpdataPermission: metadata %s not found", proposal.Proposal.DataId)
+	}
+	if meta.Owner != proposal.Proposal.Owner {
+		return "", types.Wrapf(types.ErrTxProcessFailed, "MsgUpdataPermission: no permission to update the model %s", meta.DataId)
+	}
+	meta.ReadonlyDids = proposal.Proposal.ReadonlyDids
+	meta.ReadwriteDids = proposal.Proposal.ReadwriteDids
+
+	hash, _, err := mc.commitTx(&saotypes.MsgUpdataPermission{
+		Creator:      signer,
+		Proposal:     proposal.Proposal,
+		JwsSignature: proposal.JwsSignature,
+		Provider:     signer,
+	}, &saotypes.MsgUpdataPermissionResponse{})
+	return hash, err
+}
+
+func (mc *MockChainSvc) ListMeta(ctx context.Context, offset uint64, limit uint64) ([]modeltypes.Metadata, uint64, error) {
+	mc.lk.Lock()
+	defer mc.lk.Unlock()
+
+	dataIds := make([]string, 0, len(mc.metadata))
+	for dataId := range mc.metadata {
+		dataIds = append(dataIds, dataId)
+	}
+	sort.Strings(dataIds)
+
+	metas := make([]modeltypes.Metadata, 0)
+	for i := offset; i < uint64(len(dataIds)) && i < offset+limit; i++ {
+		metas = append(metas, copyMeta(mc.metadata[dataIds[i]]))
+	}
+	return metas, uint64(len(dataIds)), nil
+}
+
+func (mc *MockChainSvc) ListMetaByDid(ctx context.Context, did string) ([]modeltypes.Metadata, error) {
+	mc.lk.Lock()
+	defer mc.lk.Unlock()
+
+	metas := make([]modeltypes.Metadata, 0)
+	for _, meta := range mc.metadata {
+		if meta.Owner == did {
+			metas = append(metas, copyMeta(meta))
+		}
+	}
+	sort.Slice(metas, func(i, j int) bool {
+		return metas[i].DataId < metas[j].DataId
+	})
+	return metas, nil
+}
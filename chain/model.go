@@ -100,7 +100,7 @@ func (c *ChainSvc) UpdatePermission(ctx context.Context, signer string, proposal
 	}
 
 	resultChan := make(chan BroadcastTxJobResult)
-	c.broadcastMsg(txAddress, msg, resultChan)
+	c.broadcastMsg(ctx, txAddress, msg, resultChan)
 	result := <-resultChan
 	if result.err != nil {
 		return "", types.Wrap(types.ErrTxProcessFailed, result.err)
@@ -19,7 +19,7 @@ func (c *ChainSvc) Create(ctx context.Context, creator string) (string, error) {
 	}
 
 	resultChan := make(chan BroadcastTxJobResult)
-	c.broadcastMsg(creator, msg, resultChan)
+	c.broadcastMsg(ctx, creator, msg, resultChan)
 	result := <-resultChan
 	if result.err != nil {
 		return "", types.Wrap(types.ErrTxProcessFailed, result.err)
@@ -45,7 +45,7 @@ func (c *ChainSvc) Reset(ctx context.Context, creator string, peerInfo string, s
 		Description: description,
 	}
 	resultChan := make(chan BroadcastTxJobResult)
-	c.broadcastMsg(creator, msg, resultChan)
+	c.broadcastMsg(ctx, creator, msg, resultChan)
 	result := <-resultChan
 	if result.err != nil {
 		return "", types.Wrap(types.ErrTxProcessFailed, result.err)
@@ -61,7 +61,7 @@ func (c *ChainSvc) ClaimReward(ctx context.Context, creator string) (string, err
 		Creator: creator,
 	}
 	resultChan := make(chan BroadcastTxJobResult)
-	c.broadcastMsg(creator, msg, resultChan)
+	c.broadcastMsg(ctx, creator, msg, resultChan)
 	result := <-resultChan
 
 	if result.err != nil {
@@ -105,7 +105,7 @@ func (c *ChainSvc) ReportFaults(ctx context.Context, creator string, provider st
 		Faults:   faults,
 	}
 	resultChan := make(chan BroadcastTxJobResult)
-	c.broadcastMsg(creator, msg, resultChan)
+	c.broadcastMsg(ctx, creator, msg, resultChan)
 	result := <-resultChan
 	if result.err != nil {
 		return nil, types.Wrap(types.ErrTxProcessFailed, result.err)
@@ -129,7 +129,7 @@ func (c *ChainSvc) RecoverFaults(ctx context.Context, creator string, provider s
 		Faults:   faults,
 	}
 	resultChan := make(chan BroadcastTxJobResult)
-	c.broadcastMsg(creator, msg, resultChan)
+	c.broadcastMsg(ctx, creator, msg, resultChan)
 	result := <-resultChan
 	if result.err != nil {
 		return nil, types.Wrap(types.ErrTxProcessFailed, result.err)
@@ -151,7 +151,7 @@ func (c *ChainSvc) AddVstorage(ctx context.Context, creator string, size uint64)
 		Size_:   size,
 	}
 	resultChan := make(chan BroadcastTxJobResult)
-	c.broadcastMsg(creator, msg, resultChan)
+	c.broadcastMsg(ctx, creator, msg, resultChan)
 	result := <-resultChan
 	if result.err != nil {
 		return "", types.Wrap(types.ErrTxProcessFailed, result.err)
@@ -173,7 +173,7 @@ func (c *ChainSvc) RemoveVstorage(ctx context.Context, creator string, size uint
 		Size_:   size,
 	}
 	resultChan := make(chan BroadcastTxJobResult)
-	c.broadcastMsg(creator, msg, resultChan)
+	c.broadcastMsg(ctx, creator, msg, resultChan)
 	result := <-resultChan
 	if result.err != nil {
 		return "", types.Wrap(types.ErrTxProcessFailed, result.err)
@@ -256,6 +256,16 @@ func (c *ChainSvc) GetPledgeInfo(ctx context.Context, creator string) (*sdktypes
 	return &pledgeResp.Pledge.TotalStoragePledged, nil
 }
 
+func (c *ChainSvc) GetPledge(ctx context.Context, creator string) (*nodetypes.Pledge, error) {
+	pledgeResp, err := c.nodeClient.Pledge(ctx, &nodetypes.QueryGetPledgeRequest{
+		Creator: creator,
+	})
+	if err != nil {
+		return nil, types.Wrap(types.ErrQueryPledgeFailed, err)
+	}
+	return &pledgeResp.Pledge, nil
+}
+
 func (c *ChainSvc) StartStatusReporter(ctx context.Context, creator string, status uint32) {
 	go func() {
 		ticker := time.NewTicker(15 * time.Minute)
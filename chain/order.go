@@ -18,10 +18,6 @@ const (
 	Blocktime  = 1 * time.Second
 )
 
-type OrderCompleteResult struct {
-	Result string
-}
-
 func (c *ChainSvc) OrderReady(ctx context.Context, provider string, orderId uint64) (saotypes.MsgReadyResponse, string, int64, error) {
 	txAddress := provider
 	defer func() {
@@ -49,7 +45,7 @@ func (c *ChainSvc) OrderReady(ctx context.Context, provider string, orderId uint
 		Provider: provider,
 	}
 	resultChan := make(chan BroadcastTxJobResult)
-	c.broadcastMsg(txAddress, msg, resultChan)
+	c.broadcastMsg(ctx, txAddress, msg, resultChan)
 	result := <-resultChan
 	if result.err != nil {
 		return saotypes.MsgReadyResponse{}, "", -1, types.Wrap(types.ErrTxProcessFailed, result.err)
@@ -99,7 +95,7 @@ func (c *ChainSvc) StoreOrder(ctx context.Context, signer string, clientProposal
 	}
 
 	resultChan := make(chan BroadcastTxJobResult)
-	c.broadcastMsg(txAddress, msg, resultChan)
+	c.broadcastMsg(ctx, txAddress, msg, resultChan)
 	result := <-resultChan
 	if result.err != nil {
 		return saotypes.MsgStoreResponse{}, "", -1, types.Wrap(types.ErrTxProcessFailed, result.err)
@@ -145,7 +141,7 @@ func (c *ChainSvc) CompleteOrder(ctx context.Context, creator string, orderId ui
 		Provider: creator,
 	}
 	resultChan := make(chan BroadcastTxJobResult)
-	c.broadcastMsg(txAddress, msg, resultChan)
+	c.broadcastMsg(ctx, txAddress, msg, resultChan)
 	result := <-resultChan
 	if result.err != nil {
 		return "", -1, types.Wrap(types.ErrTxProcessFailed, result.err)
@@ -184,7 +180,7 @@ func (c *ChainSvc) RenewOrder(ctx context.Context, creator string, orderRenewPro
 		Provider:     creator,
 	}
 	resultChan := make(chan BroadcastTxJobResult)
-	c.broadcastMsg(txAddress, msg, resultChan)
+	c.broadcastMsg(ctx, txAddress, msg, resultChan)
 	result := <-resultChan
 	if result.err != nil {
 		return "", nil, types.Wrap(types.ErrTxProcessFailed, result.err)
@@ -231,7 +227,7 @@ func (c *ChainSvc) MigrateOrder(ctx context.Context, creator string, dataIds []s
 		Provider: creator,
 	}
 	resultChan := make(chan BroadcastTxJobResult)
-	c.broadcastMsg(txAddress, msg, resultChan)
+	c.broadcastMsg(ctx, txAddress, msg, resultChan)
 	result := <-resultChan
 	if result.err != nil {
 		return "", nil, -1, types.Wrap(types.ErrTxProcessFailed, result.err)
@@ -279,7 +275,7 @@ func (c *ChainSvc) TerminateOrder(ctx context.Context, creator string, terminate
 		Provider:     creator,
 	}
 	resultChan := make(chan BroadcastTxJobResult)
-	c.broadcastMsg(txAddress, msg, resultChan)
+	c.broadcastMsg(ctx, txAddress, msg, resultChan)
 	result := <-resultChan
 	if result.err != nil {
 		return "", types.Wrap(types.ErrTxProcessFailed, result.err)
@@ -319,100 +315,3 @@ func (c *ChainSvc) ListShards(ctx context.Context, offset uint64, limit uint64)
 	}
 	return resp.Shard, resp.Pagination.Total, nil
 }
-
-// wsevent
-//func (cs *ChainSvc) SubscribeOrderComplete(ctx context.Context, orderId uint64, doneChan chan OrderCompleteResult) error {
-//	log.Debugf("SubscribeOrderComplete %s", QueryOrderComplete(orderId))
-//	ch, err := cs.listener.Subscribe(ctx, subscriber, QueryOrderComplete(orderId))
-//	if err != nil {
-//		return err
-//	}
-//	log.Debugf("SubscribeOrderComplete %s succeed", QueryOrderComplete(orderId))
-//
-//	go func() {
-//		log.Debugf("new thread wait chan")
-//		<-ch
-//		// TODO: replace with real data id.
-//		// uuid, _ := uuid.GenerateUUID()
-//		doneChan <- OrderCompleteResult{}
-//		log.Debugf("new thread quit chan")
-//	}()
-//	return nil
-//}
-//
-//func (cs *ChainSvc) UnsubscribeOrderComplete(ctx context.Context, orderId uint64) error {
-//	err := cs.listener.Unsubscribe(ctx, subscriber, QueryOrderComplete(orderId))
-//	if err != nil {
-//		return err
-//	}
-//	return nil
-//}
-//
-//func (cs *ChainSvc) SubscribeShardTask(ctx context.Context, nodeAddr string, shardTaskChan chan *ShardTask) error {
-//	log.Debugf("SubscribeShardTask: %s", QueryOrderShard(nodeAddr))
-//	ch, err := cs.listener.Subscribe(ctx, subscriber, QueryOrderShard(nodeAddr))
-//	if err != nil {
-//		return err
-//	}
-//
-//	go func() {
-//		for c := range ch {
-//			log.Debugf("event: ", c)
-//			providers := c.Events["new-shard.provider"]
-//			var i int
-//			for ii, provider := range providers {
-//				if provider == nodeAddr {
-//					i = ii
-//					break
-//				}
-//			}
-//			orderId, err := strconv.ParseUint(c.Events["new-shard.order-id"][i], 10, 64)
-//			if err != nil {
-//				log.Error(err)
-//				continue
-//			}
-//			gateway := c.Events["new-shard.peer"][i]
-//			shardCid := c.Events["new-shard.cid"][i]
-//			operation := c.Events["new-shard.operation"][i]
-//			cid, err := cid.Decode(shardCid)
-//			if err != nil {
-//				log.Error(err)
-//				continue
-//			}
-//
-//			order, err := cs.GetOrder(ctx, orderId)
-//			if err != nil {
-//				log.Error(err)
-//				continue
-//			}
-//
-//			shardTaskChan <- &ShardTask{
-//				Owner:          order.Owner,
-//				OrderId:        orderId,
-//				Gateway:        gateway,
-//				Cid:            cid,
-//				OrderOperation: fmt.Sprintf("%d", order.Operation),
-//				ShardOperation: operation,
-//			}
-//		}
-//		log.Info("shard task loop ends.")
-//	}()
-//	return nil
-//}
-//
-//func (cs *ChainSvc) UnsubscribeShardTask(ctx context.Context, nodeAddr string) error {
-//	log.Debug("UnsubscribeShardTask")
-//	err := cs.listener.Unsubscribe(ctx, subscriber, QueryOrderShard(nodeAddr))
-//	if err != nil {
-//		return err
-//	}
-//	return nil
-//}
-//
-//func QueryOrderShard(addr string) string {
-//	return fmt.Sprintf("new-shard.provider='%s'", addr)
-//}
-//
-//func QueryOrderComplete(orderId uint64) string {
-//	return fmt.Sprintf("order-completed.order-id=%d", orderId)
-//}
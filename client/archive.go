@@ -0,0 +1,359 @@
+package client
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/SaoNetwork/sao-node/types"
+	"github.com/SaoNetwork/sao-node/utils"
+
+	"github.com/ipfs/go-cid"
+	car "github.com/ipld/go-car"
+	carutil "github.com/ipld/go-car/util"
+)
+
+const ARCHIVE_VERSION = 1
+
+/**
+ * a commit of an archived model, the content is the block of Cid in the archive.
+ */
+type ArchiveCommit struct {
+	CommitId string
+	Height   uint64
+	Cid      string
+	Size     uint64
+}
+
+type ArchiveModel struct {
+	DataId     string
+	Alias      string
+	GroupId    string
+	Tags       []string
+	Rule       string
+	ExtendInfo string
+	// oldest first
+	Commits []ArchiveCommit
+	// why the model content isn't archived, the model is not imported then
+	Skipped string `json:",omitempty"`
+}
+
+/**
+ * the manifest is the root block of the archive, it's also written next to the archive as <archive>.json.
+ */
+type ArchiveManifest struct {
+	Version int
+	Owner   string
+	Models  []ArchiveModel
+}
+
+/**
+ * ArchiveWriter writes model contents into a CAR file whose root is the manifest.
+ * contents are staged until Close, since the root is known only once all models are added.
+ */
+type ArchiveWriter struct {
+	path     string
+	staged   *os.File
+	written  map[cid.Cid]struct{}
+	Manifest ArchiveManifest
+}
+
+func NewArchiveWriter(path string, owner string) (*ArchiveWriter, error) {
+	staged, err := os.CreateTemp("", "sao-archive-*")
+	if err != nil {
+		return nil, types.Wrap(types.ErrCreateFileFailed, err)
+	}
+	return &ArchiveWriter{
+		path:    path,
+		staged:  staged,
+		written: make(map[cid.Cid]struct{}),
+		Manifest: ArchiveManifest{
+			Version: ARCHIVE_VERSION,
+			Owner:   owner,
+		},
+	}, nil
+}
+
+/**
+ * add the content block, identical contents of several commits are stored once.
+ */
+func (w *ArchiveWriter) AddContent(content []byte) (cid.Cid, error) {
+	contentCid, err := utils.CalculateCid(content)
+	if err != nil {
+		return cid.Undef, err
+	}
+	if _, ok := w.written[contentCid]; ok {
+		return contentCid, nil
+	}
+
+	err = carutil.LdWrite(w.staged, contentCid.Bytes(), content)
+	if err != nil {
+		return cid.Undef, types.Wrap(types.ErrWriteFileFailed, err)
+	}
+	w.written[contentCid] = struct{}{}
+	return contentCid, nil
+}
+
+func (w *ArchiveWriter) AddModel(model ArchiveModel) {
+	w.Manifest.Models = append(w.Manifest.Models, model)
+}
+
+/**
+ * write the archive with the manifest as root, followed by the content blocks.
+ */
+func (w *ArchiveWriter) Close() error {
+	defer os.Remove(w.staged.Name())
+	defer w.staged.Close()
+
+	manifest, err := json.MarshalIndent(w.Manifest, "", "  ")
+	if err != nil {
+		return types.Wrap(types.ErrMarshalFailed, err)
+	}
+	manifestCid, err := utils.CalculateCid(manifest)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(w.path+".json", manifest, 0644)
+	if err != nil {
+		return types.Wrap(types.ErrWriteFileFailed, err)
+	}
+
+	file, err := os.Create(w.path)
+	if err != nil {
+		return types.Wrap(types.ErrCreateFileFailed, err)
+	}
+	defer file.Close()
+	bw := bufio.NewWriter(file)
+
+	err = car.WriteHeader(&car.CarHeader{Roots: []cid.Cid{manifestCid}, Version: 1}, bw)
+	if err != nil {
+		return types.Wrap(types.ErrWriteFileFailed, err)
+	}
+	err = carutil.LdWrite(bw, manifestCid.Bytes(), manifest)
+	if err != nil {
+		return types.Wrap(types.ErrWriteFileFailed, err)
+	}
+	_, err = w.staged.Seek(0, io.SeekStart)
+	if err != nil {
+		return types.Wrap(types.ErrReadFileFailed, err)
+	}
+	_, err = io.Copy(bw, w.staged)
+	if err != nil {
+		return types.Wrap(types.ErrWriteFileFailed, err)
+	}
+	err = bw.Flush()
+	if err != nil {
+		return types.Wrap(types.ErrWriteFileFailed, err)
+	}
+	return nil
+}
+
+type archiveBlock struct {
+	offset int64
+	size   int64
+}
+
+/**
+ * ArchiveReader reads the content blocks of an archive on demand, only their offsets are kept in memory.
+ */
+type ArchiveReader struct {
+	file     *os.File
+	blocks   map[string]archiveBlock
+	Manifest ArchiveManifest
+}
+
+/**
+ * index the blocks of an archive and read its manifest, every model content must be in the archive.
+ */
+func OpenArchive(path string) (*ArchiveReader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, types.Wrap(types.ErrOpenFileFailed, err)
+	}
+	ar := &ArchiveReader{
+		file:   file,
+		blocks: make(map[string]archiveBlock),
+	}
+	err = ar.open()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return ar, nil
+}
+
+func (ar *ArchiveReader) open() error {
+	br := bufio.NewReader(ar.file)
+	header, err := car.ReadHeader(br)
+	if err != nil {
+		return types.Wrap(types.ErrInvalidArchive, err)
+	}
+	if len(header.Roots) != 1 {
+		return types.Wrapf(types.ErrInvalidArchive, "archive has %d roots", len(header.Roots))
+	}
+
+	offset, err := car.HeaderSize(header)
+	if err != nil {
+		return types.Wrap(types.ErrInvalidArchive, err)
+	}
+	for {
+		// a block is the varint length of the section, followed by the cid and the content
+		size, err := binary.ReadUvarint(br)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return types.Wrap(types.ErrInvalidArchive, err)
+		}
+		n, blockCid, err := cid.CidFromReader(br)
+		if err != nil {
+			return types.Wrap(types.ErrInvalidArchive, err)
+		}
+		if uint64(n) > size {
+			return types.Wrapf(types.ErrInvalidArchive, "block %v exceeds its section", blockCid)
+		}
+		_, err = br.Discard(int(size) - n)
+		if err != nil {
+			return types.Wrap(types.ErrInvalidArchive, err)
+		}
+		ar.blocks[blockCid.String()] = archiveBlock{
+			offset: int64(offset) + int64(uvarintSize(size)) + int64(n),
+			size:   int64(size) - int64(n),
+		}
+		offset += uint64(uvarintSize(size)) + size
+	}
+
+	root := header.Roots[0].String()
+	data, err := ar.Content(root)
+	if err != nil {
+		return types.Wrapf(types.ErrInvalidArchive, "manifest %s: %v", root, err)
+	}
+	err = json.Unmarshal(data, &ar.Manifest)
+	if err != nil {
+		return types.Wrap(types.ErrInvalidArchive, err)
+	}
+	if ar.Manifest.Version != ARCHIVE_VERSION {
+		return types.Wrapf(types.ErrInvalidArchive, "unsupported archive version %d", ar.Manifest.Version)
+	}
+	for _, model := range ar.Manifest.Models {
+		for _, commit := range model.Commits {
+			if _, ok := ar.blocks[commit.Cid]; !ok {
+				return types.Wrapf(types.ErrInvalidArchive, "content %s of model %s not found", commit.Cid, model.DataId)
+			}
+		}
+	}
+	return nil
+}
+
+func uvarintSize(x uint64) int {
+	buf := make([]byte, binary.MaxVarintLen64)
+	return binary.PutUvarint(buf, x)
+}
+
+/**
+ * read the content block of cidStr, it's verified against the cid. safe for concurrent use.
+ */
+func (ar *ArchiveReader) Content(cidStr string) ([]byte, error) {
+	block, ok := ar.blocks[cidStr]
+	if !ok {
+		return nil, types.Wrapf(types.ErrInvalidArchive, "content %s not found", cidStr)
+	}
+	contentCid, err := cid.Decode(cidStr)
+	if err != nil {
+		return nil, types.Wrap(types.ErrInvalidCid, err)
+	}
+
+	data := make([]byte, block.size)
+	_, err = ar.file.ReadAt(data, block.offset)
+	if err != nil {
+		return nil, types.Wrap(types.ErrReadFileFailed, err)
+	}
+	sum, err := contentCid.Prefix().Sum(data)
+	if err != nil {
+		return nil, types.Wrap(types.ErrInvalidArchive, err)
+	}
+	if !sum.Equals(contentCid) {
+		return nil, types.Wrapf(types.ErrInvalidArchive, "content of %s doesn't match its cid", cidStr)
+	}
+	return data, nil
+}
+
+func (ar *ArchiveReader) Close() error {
+	return ar.file.Close()
+}
+
+/**
+ * the import state of an archived model.
+ */
+type ImportedModel struct {
+	// data id of the imported model
+	DataId string
+	// last imported commit id
+	CommitId string
+	// number of archived commits imported
+	Commits int
+	LastErr string `json:",omitempty"`
+}
+
+/**
+ * ImportProgress records imported models by their archived data id, so that an interrupted import resumes
+ * from the commits not imported yet.
+ */
+type ImportProgress struct {
+	sync.Mutex
+	path   string
+	Models map[string]ImportedModel
+}
+
+func LoadImportProgress(path string) (*ImportProgress, error) {
+	progress := &ImportProgress{
+		path:   path,
+		Models: make(map[string]ImportedModel),
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return progress, nil
+	}
+	if err != nil {
+		return nil, types.Wrap(types.ErrReadFileFailed, err)
+	}
+	err = json.Unmarshal(data, progress)
+	if err != nil {
+		return nil, types.Wrap(types.ErrUnMarshalFailed, err)
+	}
+	return progress, nil
+}
+
+func (p *ImportProgress) Get(dataId string) ImportedModel {
+	p.Lock()
+	defer p.Unlock()
+
+	return p.Models[dataId]
+}
+
+/**
+ * update the import state of a model and persist the progress.
+ */
+func (p *ImportProgress) Set(dataId string, model ImportedModel) error {
+	p.Lock()
+	defer p.Unlock()
+
+	p.Models[dataId] = model
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return types.Wrap(types.ErrMarshalFailed, err)
+	}
+	// write a new file and rename it, so that an interrupted write doesn't lose the progress
+	err = os.WriteFile(p.path+".tmp", data, 0644)
+	if err != nil {
+		return types.Wrap(types.ErrWriteFileFailed, err)
+	}
+	err = os.Rename(p.path+".tmp", p.path)
+	if err != nil {
+		return types.Wrap(types.ErrWriteFileFailed, err)
+	}
+	return nil
+}
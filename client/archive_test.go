@@ -0,0 +1,75 @@
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/SaoNetwork/sao-node/types"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "models.car")
+
+	w, err := NewArchiveWriter(path, "did:key:owner")
+	require.NoError(t, err)
+
+	c1, err := w.AddContent([]byte(`{"a":1}`))
+	require.NoError(t, err)
+	c2, err := w.AddContent([]byte(`{"a":2}`))
+	require.NoError(t, err)
+	// identical content is written once
+	c3, err := w.AddContent([]byte(`{"a":1}`))
+	require.NoError(t, err)
+	require.Equal(t, c1, c3)
+
+	w.AddModel(ArchiveModel{
+		DataId: "d1",
+		Alias:  "m1",
+		Tags:   []string{"t1"},
+		Commits: []ArchiveCommit{
+			{CommitId: "c1", Height: 10, Cid: c1.String(), Size: 7},
+			{CommitId: "c2", Height: 20, Cid: c2.String(), Size: 7},
+			{CommitId: "c3", Height: 30, Cid: c3.String(), Size: 7},
+		},
+	})
+	w.AddModel(ArchiveModel{DataId: "d2", Alias: "file_a.txt", Skipped: "file content is not archived"})
+	require.NoError(t, w.Close())
+
+	_, err = os.Stat(path + ".json")
+	require.NoError(t, err)
+
+	ar, err := OpenArchive(path)
+	require.NoError(t, err)
+	defer ar.Close()
+	require.Equal(t, w.Manifest, ar.Manifest)
+	// the manifest and the two contents
+	require.Len(t, ar.blocks, 3)
+	content, err := ar.Content(c2.String())
+	require.NoError(t, err)
+	require.Equal(t, []byte(`{"a":2}`), content)
+	content, err = ar.Content(c1.String())
+	require.NoError(t, err)
+	require.Equal(t, []byte(`{"a":1}`), content)
+
+	_, err = OpenArchive(path + ".json")
+	require.True(t, types.ErrInvalidArchive.Is(err))
+}
+
+func TestImportProgress(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "progress.json")
+
+	p, err := LoadImportProgress(path)
+	require.NoError(t, err)
+	require.Equal(t, 0, p.Get("d1").Commits)
+
+	require.NoError(t, p.Set("d1", ImportedModel{DataId: "n1", CommitId: "n1", Commits: 1}))
+	require.NoError(t, p.Set("d2", ImportedModel{LastErr: "failed"}))
+
+	p, err = LoadImportProgress(path)
+	require.NoError(t, err)
+	require.Equal(t, ImportedModel{DataId: "n1", CommitId: "n1", Commits: 1}, p.Get("d1"))
+	require.Equal(t, "failed", p.Get("d2").LastErr)
+}
@@ -0,0 +1,469 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/SaoNetwork/sao-node/types"
+
+	saokey "github.com/SaoNetwork/sao-did/key"
+	saodidtypes "github.com/SaoNetwork/sao-did/types"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/multiformats/go-multibase"
+)
+
+const ENVELOPE_VERSION = 1
+
+/**
+ * the data key of an envelope wrapped to a recipient public key, the key encryption key is derived from
+ * the ECDH secret of an ephemeral key and the recipient key.
+ */
+type EnvelopeRecipient struct {
+	// compressed secp256k1 public key of the recipient
+	PublicKey    []byte
+	EphemeralKey []byte
+	Nonce        []byte
+	WrappedKey   []byte
+}
+
+/**
+ * Envelope is the stored content of an encrypted model, the content is sealed by a per-model data key
+ * with AES-256-GCM. it's a JSON document so that updates are still sent as JSON patches.
+ */
+type Envelope struct {
+	Version    int
+	Nonce      []byte
+	Ciphertext []byte
+	Recipients []EnvelopeRecipient
+}
+
+type envelopeContent struct {
+	Envelope *Envelope `json:"saoEnvelope"`
+}
+
+func NewDataKey() ([]byte, error) {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	if err != nil {
+		return nil, types.Wrap(types.ErrEncryptFailed, err)
+	}
+	return key, nil
+}
+
+/**
+ * parse the envelope of an encrypted content, ok is false if the content isn't encrypted.
+ */
+func ParseEnvelope(content []byte) (envelope *Envelope, ok bool) {
+	if !bytes.Contains(content, []byte(`"saoEnvelope"`)) {
+		return nil, false
+	}
+	var ec envelopeContent
+	err := json.Unmarshal(content, &ec)
+	if err != nil || ec.Envelope == nil {
+		return nil, false
+	}
+	return ec.Envelope, true
+}
+
+/**
+ * encrypt the plaintext with the data key, and wrap the data key to each recipient public key.
+ */
+func SealEnvelope(plaintext []byte, dataKey []byte, recipients [][]byte) ([]byte, error) {
+	if len(recipients) == 0 {
+		return nil, types.Wrapf(types.ErrEncryptFailed, "no recipient")
+	}
+	nonce, ciphertext, err := seal(dataKey, plaintext)
+	if err != nil {
+		return nil, err
+	}
+	envelope := &Envelope{
+		Version:    ENVELOPE_VERSION,
+		Nonce:      nonce,
+		Ciphertext: ciphertext,
+	}
+
+	envelope.Recipients, err = wrapRecipients(dataKey, recipients)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := json.Marshal(envelopeContent{Envelope: envelope})
+	if err != nil {
+		return nil, types.Wrap(types.ErrMarshalFailed, err)
+	}
+	return content, nil
+}
+
+/**
+ * unwrap the data key with the private key of a recipient.
+ */
+func (e *Envelope) DataKey(privKey []byte) ([]byte, error) {
+	if e.Version != ENVELOPE_VERSION {
+		return nil, types.Wrapf(types.ErrDecryptFailed, "unsupported envelope version %d", e.Version)
+	}
+	return unwrapKey(e.Recipients, privKey)
+}
+
+func (e *Envelope) Open(dataKey []byte) ([]byte, error) {
+	return open(dataKey, e.Nonce, e.Ciphertext)
+}
+
+/**
+ * decrypt the content if it's an envelope, otherwise the content is returned as it is.
+ */
+func DecryptContent(content []byte, privKey []byte) ([]byte, error) {
+	if IsFileEnvelope(content) {
+		var buf bytes.Buffer
+		err := OpenFile(&buf, bytes.NewReader(content), privKey)
+		if err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	envelope, ok := ParseEnvelope(content)
+	if !ok {
+		return content, nil
+	}
+	dataKey, err := envelope.DataKey(privKey)
+	if err != nil {
+		return nil, err
+	}
+	return envelope.Open(dataKey)
+}
+
+// a file envelope starts with the magic, followed by the varint length of the JSON header and the sealed chunks
+const FILE_ENVELOPE_MAGIC = "SAOFENV\x01"
+
+// size of the plaintext of a sealed file chunk, the last chunk may be shorter
+const FILE_CHUNK_SIZE = 64 * 1024
+
+type fileEnvelopeHeader struct {
+	Version    int
+	ChunkSize  int
+	NonceSeed  []byte
+	Recipients []EnvelopeRecipient
+}
+
+func IsFileEnvelope(content []byte) bool {
+	return bytes.HasPrefix(content, []byte(FILE_ENVELOPE_MAGIC))
+}
+
+/**
+ * encrypt a file with the data key chunk by chunk, so that it's never held in memory as a whole. each chunk is
+ * sealed with a nonce of its index and whether it's the last one, chunks can't be reordered or truncated.
+ * returns the size of the sealed file.
+ */
+func SealFile(w io.Writer, r io.Reader, dataKey []byte, recipients [][]byte) (uint64, error) {
+	if len(recipients) == 0 {
+		return 0, types.Wrapf(types.ErrEncryptFailed, "no recipient")
+	}
+	aead, err := newAead(dataKey)
+	if err != nil {
+		return 0, types.Wrap(types.ErrEncryptFailed, err)
+	}
+	header := fileEnvelopeHeader{
+		Version:   ENVELOPE_VERSION,
+		ChunkSize: FILE_CHUNK_SIZE,
+		NonceSeed: make([]byte, aead.NonceSize()-5),
+	}
+	_, err = rand.Read(header.NonceSeed)
+	if err != nil {
+		return 0, types.Wrap(types.ErrEncryptFailed, err)
+	}
+	header.Recipients, err = wrapRecipients(dataKey, recipients)
+	if err != nil {
+		return 0, err
+	}
+	headerBytes, err := json.Marshal(header)
+	if err != nil {
+		return 0, types.Wrap(types.ErrMarshalFailed, err)
+	}
+
+	prefix := append([]byte(FILE_ENVELOPE_MAGIC), make([]byte, binary.MaxVarintLen64)...)
+	n := binary.PutUvarint(prefix[len(FILE_ENVELOPE_MAGIC):], uint64(len(headerBytes)))
+	prefix = append(prefix[:len(FILE_ENVELOPE_MAGIC)+n], headerBytes...)
+	_, err = w.Write(prefix)
+	if err != nil {
+		return 0, types.Wrap(types.ErrWriteFileFailed, err)
+	}
+	size := uint64(len(prefix))
+
+	br := bufio.NewReader(r)
+	chunk := make([]byte, FILE_CHUNK_SIZE)
+	sealed := make([]byte, 0, FILE_CHUNK_SIZE+aead.Overhead())
+	for index := uint32(0); ; index++ {
+		last, n, err := readChunk(br, chunk)
+		if err != nil {
+			return 0, types.Wrap(types.ErrReadFileFailed, err)
+		}
+		sealed = aead.Seal(sealed[:0], chunkNonce(header.NonceSeed, index, last), chunk[:n], nil)
+		_, err = w.Write(sealed)
+		if err != nil {
+			return 0, types.Wrap(types.ErrWriteFileFailed, err)
+		}
+		size += uint64(len(sealed))
+		if last {
+			return size, nil
+		}
+		if index == math.MaxUint32 {
+			return 0, types.Wrapf(types.ErrEncryptFailed, "the file is too large")
+		}
+	}
+}
+
+/**
+ * decrypt a file sealed by SealFile with the private key of a recipient.
+ */
+func OpenFile(w io.Writer, r io.Reader, privKey []byte) error {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(FILE_ENVELOPE_MAGIC))
+	_, err := io.ReadFull(br, magic)
+	if err != nil || string(magic) != FILE_ENVELOPE_MAGIC {
+		return types.Wrapf(types.ErrDecryptFailed, "not an encrypted file")
+	}
+	headerSize, err := binary.ReadUvarint(br)
+	if err != nil || headerSize > FILE_CHUNK_SIZE {
+		return types.Wrapf(types.ErrDecryptFailed, "invalid file envelope header")
+	}
+	headerBytes := make([]byte, headerSize)
+	_, err = io.ReadFull(br, headerBytes)
+	if err != nil {
+		return types.Wrap(types.ErrDecryptFailed, err)
+	}
+	var header fileEnvelopeHeader
+	err = json.Unmarshal(headerBytes, &header)
+	if err != nil {
+		return types.Wrap(types.ErrDecryptFailed, err)
+	}
+	if header.Version != ENVELOPE_VERSION {
+		return types.Wrapf(types.ErrDecryptFailed, "unsupported envelope version %d", header.Version)
+	}
+	if header.ChunkSize <= 0 || header.ChunkSize > 16*FILE_CHUNK_SIZE {
+		return types.Wrapf(types.ErrDecryptFailed, "invalid chunk size %d", header.ChunkSize)
+	}
+
+	dataKey, err := unwrapKey(header.Recipients, privKey)
+	if err != nil {
+		return err
+	}
+	aead, err := newAead(dataKey)
+	if err != nil {
+		return types.Wrap(types.ErrDecryptFailed, err)
+	}
+	if len(header.NonceSeed) != aead.NonceSize()-5 {
+		return types.Wrapf(types.ErrDecryptFailed, "invalid nonce seed")
+	}
+
+	chunk := make([]byte, header.ChunkSize+aead.Overhead())
+	plaintext := make([]byte, 0, header.ChunkSize)
+	for index := uint32(0); ; index++ {
+		last, n, err := readChunk(br, chunk)
+		if err != nil {
+			return types.Wrap(types.ErrDecryptFailed, err)
+		}
+		plaintext, err = aead.Open(plaintext[:0], chunkNonce(header.NonceSeed, index, last), chunk[:n], nil)
+		if err != nil {
+			return types.Wrap(types.ErrDecryptFailed, err)
+		}
+		_, err = w.Write(plaintext)
+		if err != nil {
+			return types.Wrap(types.ErrWriteFileFailed, err)
+		}
+		if last {
+			return nil
+		}
+		if index == math.MaxUint32 {
+			return types.Wrapf(types.ErrDecryptFailed, "too many chunks")
+		}
+	}
+}
+
+/**
+ * fill the chunk from the reader, last is true if the reader has nothing left after it.
+ */
+func readChunk(br *bufio.Reader, chunk []byte) (last bool, n int, err error) {
+	n, err = io.ReadFull(br, chunk)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return true, n, nil
+	}
+	if err != nil {
+		return false, n, err
+	}
+	_, err = br.Peek(1)
+	if err == io.EOF {
+		return true, n, nil
+	}
+	return false, n, err
+}
+
+func chunkNonce(seed []byte, index uint32, last bool) []byte {
+	nonce := make([]byte, len(seed)+5)
+	copy(nonce, seed)
+	binary.BigEndian.PutUint32(nonce[len(seed):], index)
+	if last {
+		nonce[len(nonce)-1] = 1
+	}
+	return nonce
+}
+
+/**
+ * public keys the data key is wrapped to for a did. a did:key has its own key,
+ * a did:sid is decrypted by the account dids bound to it.
+ */
+func (sc SaoClient) DidPublicKeys(ctx context.Context, did string) ([][]byte, error) {
+	if strings.HasPrefix(did, "did:key:") {
+		pub, err := didKeyPublicKey(did)
+		if err != nil {
+			return nil, err
+		}
+		return [][]byte{pub}, nil
+	}
+
+	info, err := sc.GetDidInfo(ctx, did)
+	if err != nil {
+		return nil, types.Wrap(types.ErrInvalidDid, err)
+	}
+	sidInfo, ok := info.(types.SidInfo)
+	if !ok {
+		return nil, types.Wrapf(types.ErrInvalidDid, "unsupported did %s", did)
+	}
+	var keys [][]byte
+	for _, account := range sidInfo.Accounts {
+		pub, err := didKeyPublicKey(account.AccountDid)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, pub)
+	}
+	if len(keys) == 0 {
+		return nil, types.Wrapf(types.ErrInvalidDid, "no account bound to %s", did)
+	}
+	return keys, nil
+}
+
+func didKeyPublicKey(did string) ([]byte, error) {
+	result := saokey.NewKeyResolver().Resolve(did, saodidtypes.DidResolutionOptions{})
+	if result.DidResolutionMetadata.Error != "" || len(result.DidDocument.VerificationMethod) == 0 {
+		return nil, types.Wrapf(types.ErrInvalidDid, "unresolvable did %s", did)
+	}
+	_, pub, err := multibase.Decode(result.DidDocument.VerificationMethod[0].PublicKeyMultibase)
+	if err != nil {
+		return nil, types.Wrap(types.ErrInvalidDid, err)
+	}
+	return pub, nil
+}
+
+func wrapRecipients(dataKey []byte, recipients [][]byte) ([]EnvelopeRecipient, error) {
+	var wrapped []EnvelopeRecipient
+	seen := make(map[string]struct{})
+	for _, recipient := range recipients {
+		if _, ok := seen[string(recipient)]; ok {
+			continue
+		}
+		seen[string(recipient)] = struct{}{}
+
+		r, err := wrapKey(dataKey, recipient)
+		if err != nil {
+			return nil, err
+		}
+		wrapped = append(wrapped, *r)
+	}
+	return wrapped, nil
+}
+
+func unwrapKey(recipients []EnvelopeRecipient, privKey []byte) ([]byte, error) {
+	priv := secp256k1.PrivKeyFromBytes(privKey)
+	pub := priv.PubKey().SerializeCompressed()
+	for _, r := range recipients {
+		if !bytes.Equal(r.PublicKey, pub) {
+			continue
+		}
+		ephemeral, err := secp256k1.ParsePubKey(r.EphemeralKey)
+		if err != nil {
+			return nil, types.Wrap(types.ErrDecryptFailed, err)
+		}
+		kek := deriveKek(secp256k1.GenerateSharedSecret(priv, ephemeral), r.EphemeralKey, r.PublicKey)
+		return open(kek, r.Nonce, r.WrappedKey)
+	}
+	return nil, types.Wrapf(types.ErrDecryptFailed, "the data key isn't wrapped to the current did")
+}
+
+func wrapKey(dataKey []byte, recipient []byte) (*EnvelopeRecipient, error) {
+	pub, err := secp256k1.ParsePubKey(recipient)
+	if err != nil {
+		return nil, types.Wrap(types.ErrEncryptFailed, err)
+	}
+	ephemeral, err := secp256k1.GeneratePrivateKey()
+	if err != nil {
+		return nil, types.Wrap(types.ErrEncryptFailed, err)
+	}
+	epk := ephemeral.PubKey().SerializeCompressed()
+	kek := deriveKek(secp256k1.GenerateSharedSecret(ephemeral, pub), epk, recipient)
+
+	nonce, wrapped, err := seal(kek, dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return &EnvelopeRecipient{
+		PublicKey:    recipient,
+		EphemeralKey: epk,
+		Nonce:        nonce,
+		WrappedKey:   wrapped,
+	}, nil
+}
+
+func deriveKek(secret []byte, epk []byte, recipient []byte) []byte {
+	h := sha256.New()
+	h.Write(secret)
+	h.Write(epk)
+	h.Write(recipient)
+	return h.Sum(nil)
+}
+
+func seal(key []byte, plaintext []byte) ([]byte, []byte, error) {
+	aead, err := newAead(key)
+	if err != nil {
+		return nil, nil, types.Wrap(types.ErrEncryptFailed, err)
+	}
+	nonce := make([]byte, aead.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return nil, nil, types.Wrap(types.ErrEncryptFailed, err)
+	}
+	return nonce, aead.Seal(nil, nonce, plaintext, nil), nil
+}
+
+func open(key []byte, nonce []byte, ciphertext []byte) ([]byte, error) {
+	aead, err := newAead(key)
+	if err != nil {
+		return nil, types.Wrap(types.ErrDecryptFailed, err)
+	}
+	// the nonce comes from the untrusted envelope, aead.Open panics on a nonce of a wrong size
+	if len(nonce) != aead.NonceSize() {
+		return nil, types.Wrapf(types.ErrDecryptFailed, "invalid nonce size %d", len(nonce))
+	}
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, types.Wrap(types.ErrDecryptFailed, err)
+	}
+	return plaintext, nil
+}
+
+func newAead(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,102 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"testing"
+
+	"github.com/SaoNetwork/sao-node/types"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	"github.com/multiformats/go-multibase"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvelope(t *testing.T) {
+	owner := secp256k1.GenPrivKey()
+	reader := secp256k1.GenPrivKey()
+	other := secp256k1.GenPrivKey()
+
+	encoded, err := multibase.Encode(multibase.Base58BTC, append([]byte{0xe7, 0x01}, reader.PubKey().Bytes()...))
+	require.NoError(t, err)
+	keys, err := SaoClient{}.DidPublicKeys(context.Background(), "did:key:"+encoded)
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{reader.PubKey().Bytes()}, keys)
+
+	plaintext := []byte(`{"name":"sao"}`)
+	dataKey, err := NewDataKey()
+	require.NoError(t, err)
+	content, err := SealEnvelope(plaintext, dataKey, [][]byte{owner.PubKey().Bytes(), keys[0], owner.PubKey().Bytes()})
+	require.NoError(t, err)
+	require.NotContains(t, string(content), "sao\"")
+
+	envelope, ok := ParseEnvelope(content)
+	require.True(t, ok)
+	require.Len(t, envelope.Recipients, 2)
+
+	for _, key := range []*secp256k1.PrivKey{owner, reader} {
+		decrypted, err := DecryptContent(content, key.Key)
+		require.NoError(t, err)
+		require.Equal(t, plaintext, decrypted)
+
+		unwrapped, err := envelope.DataKey(key.Key)
+		require.NoError(t, err)
+		require.Equal(t, dataKey, unwrapped)
+	}
+
+	_, err = DecryptContent(content, other.Key)
+	require.True(t, types.ErrDecryptFailed.Is(err))
+
+	// a malformed nonce fails the decryption
+	for _, nonce := range [][]byte{nil, make([]byte, 64)} {
+		_, err = (&Envelope{Version: envelope.Version, Nonce: nonce, Ciphertext: envelope.Ciphertext}).Open(dataKey)
+		require.True(t, types.ErrDecryptFailed.Is(err))
+		recipient := envelope.Recipients[0]
+		recipient.Nonce = nonce
+		_, err = (&Envelope{Version: envelope.Version, Recipients: []EnvelopeRecipient{recipient}}).DataKey(owner.Key)
+		require.True(t, types.ErrDecryptFailed.Is(err))
+	}
+
+	// plain contents are not envelopes
+	_, ok = ParseEnvelope(plaintext)
+	require.False(t, ok)
+	decrypted, err := DecryptContent(plaintext, other.Key)
+	require.NoError(t, err)
+	require.Equal(t, plaintext, decrypted)
+}
+
+func TestFileEnvelope(t *testing.T) {
+	owner := secp256k1.GenPrivKey()
+	other := secp256k1.GenPrivKey()
+	dataKey, err := NewDataKey()
+	require.NoError(t, err)
+
+	for _, size := range []int{0, 10, FILE_CHUNK_SIZE, 2*FILE_CHUNK_SIZE + 5} {
+		plaintext := make([]byte, size)
+		_, err = rand.Read(plaintext)
+		require.NoError(t, err)
+
+		var sealed bytes.Buffer
+		n, err := SealFile(&sealed, bytes.NewReader(plaintext), dataKey, [][]byte{owner.PubKey().Bytes()})
+		require.NoError(t, err)
+		require.Equal(t, uint64(sealed.Len()), n)
+		require.True(t, IsFileEnvelope(sealed.Bytes()))
+
+		decrypted, err := DecryptContent(sealed.Bytes(), owner.Key)
+		require.NoError(t, err)
+		require.Equal(t, plaintext, append([]byte{}, decrypted...))
+
+		_, err = DecryptContent(sealed.Bytes(), other.Key)
+		require.True(t, types.ErrDecryptFailed.Is(err))
+	}
+
+	// a file truncated at a chunk boundary is rejected
+	plaintext := make([]byte, 2*FILE_CHUNK_SIZE)
+	var sealed bytes.Buffer
+	_, err = SealFile(&sealed, bytes.NewReader(plaintext), dataKey, [][]byte{owner.PubKey().Bytes()})
+	require.NoError(t, err)
+	truncated := sealed.Bytes()[:sealed.Len()-FILE_CHUNK_SIZE-16]
+	_, err = DecryptContent(truncated, owner.Key)
+	require.True(t, types.ErrDecryptFailed.Is(err))
+}
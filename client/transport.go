@@ -4,18 +4,19 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/json"
-	"fmt"
-	"github.com/libp2p/go-libp2p"
 	"io"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/SaoNetwork/sao-node/types"
 	"github.com/SaoNetwork/sao-node/utils"
 
 	"github.com/ipfs/go-cid"
 	logging "github.com/ipfs/go-log/v2"
+	"github.com/libp2p/go-libp2p"
 	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/transport"
 	"github.com/mitchellh/go-homedir"
 
 	ic "github.com/libp2p/go-libp2p/core/crypto"
@@ -27,13 +28,13 @@ import (
 
 var log = logging.Logger("transport-client")
 
-func DoTransport(ctx context.Context, repo string, remoteAddr string, remotePeerId string, fpath string) cid.Cid {
-	file, err := os.Open(fpath)
-	if err != nil {
-		log.Error(err)
-		return cid.Undef
-	}
+// times an interrupted upload is resumed before giving up
+const maxUploadRetries = 3
+
+// send a rpc request to the transport server
+type rpcSender func(ctx context.Context, req types.RpcReq) (types.RpcResp, error)
 
+func DoTransport(ctx context.Context, repo string, remoteAddr string, remotePeerId string, fpath string) cid.Cid {
 	serverAddress, err := ma.NewMultiaddr(remoteAddr)
 	if err != nil {
 		log.Error(err)
@@ -58,247 +59,281 @@ func DoTransport(ctx context.Context, repo string, remoteAddr string, remotePeer
 		return cid.Undef
 	}
 
-	log.Info("Dialing ", serverId, " (", serverAddress, ")")
-	conn, err := tr.Dial(ctx, serverAddress, serverId)
-	if err != nil {
-		log.Error(err)
+	// the connection is dialed again after a network drop
+	var conn transport.CapableConn
+	defer func() {
+		if conn != nil {
+			conn.Close()
+		}
+	}()
+	send := func(ctx context.Context, req types.RpcReq) (types.RpcResp, error) {
+		if conn == nil {
+			log.Info("Dialing ", serverId, " (", serverAddress, ")")
+			c, err := tr.Dial(ctx, serverAddress, serverId)
+			if err != nil {
+				return types.RpcResp{}, err
+			}
+			conn = c
+		}
+
+		str, err := conn.OpenStream(ctx)
+		if err == nil {
+			var resp types.RpcResp
+			resp, err = doRpc(str, req)
+			if err == nil {
+				return resp, nil
+			}
+		}
+		conn.Close()
+		conn = nil
+		return types.RpcResp{}, err
+	}
+
+	return uploadFile(ctx, fpath, types.CHUNK_SIZE, send)
+}
+
+func DoTransportTCP(ctx context.Context, repo string, peerInfo string, fpath string) cid.Cid {
+	clientKey := fetchKey(repo)
+	if clientKey == nil {
+		log.Error("failed to generate transport key")
 		return cid.Undef
 	}
-	defer conn.Close()
 
-	content, err := io.ReadAll(file)
+	a, err := ma.NewMultiaddr(peerInfo)
 	if err != nil {
 		log.Error(err)
 		return cid.Undef
 	}
-
-	contentCid, err := utils.CalculateCid(content)
+	pi, err := peer.AddrInfoFromP2pAddr(a)
 	if err != nil {
 		log.Error(err)
 		return cid.Undef
 	}
 
-	rpcReq := types.RpcReq{
-		Method: "Sao.Upload",
+	listenAddrsOption := libp2p.ListenAddrStrings("/ip4/127.0.0.1/tcp/28848")
+	host, err := libp2p.New(listenAddrsOption, libp2p.Identity(clientKey))
+	if err != nil {
+		log.Error(err)
+		return cid.Undef
 	}
+	defer host.Close()
 
-	var contentLength int = len(content)
-	var totalChunks = contentLength/types.CHUNK_SIZE + 1
-	chunkId := 0
-	for chunkId <= totalChunks {
-		var chunk []byte
-		if (chunkId+1)*types.CHUNK_SIZE < len(content) {
-			chunk = content[chunkId*types.CHUNK_SIZE : (chunkId+1)*types.CHUNK_SIZE]
-		} else if chunkId*types.CHUNK_SIZE < len(content) {
-			chunk = content[chunkId*types.CHUNK_SIZE:]
-		} else {
-			chunk = make([]byte, 0)
-		}
-
-		chunkCid, err := utils.CalculateCid(chunk)
+	send := func(ctx context.Context, req types.RpcReq) (types.RpcResp, error) {
+		// no-op if connected already
+		err := host.Connect(ctx, *pi)
 		if err != nil {
-			log.Error(err)
-			return cid.Undef
-		}
-
-		log.Info("Content[", chunkId, "], CID: ", chunkCid, ", length: ", len(chunk))
-
-		str, err := conn.OpenStream(ctx)
-		if err != nil {
-			log.Error(err)
-			return cid.Undef
-		}
-		defer str.Close()
-
-		req := &types.FileChunkReq{
-			ChunkId:     chunkId,
-			TotalLength: contentLength,
-			TotalChunks: totalChunks,
-			ChunkCid:    chunkCid.String(),
-			Cid:         contentCid.String(),
-			Content:     chunk,
-		}
-		b, err := json.Marshal(req)
-		if err != nil {
-			log.Error(err)
-			return cid.Undef
-		}
-
-		rpcReq.Params = append(make([]string, 0), string(b))
-		bytes, err := json.Marshal(rpcReq)
-		if err != nil {
-			log.Error(err)
-			return cid.Undef
-		}
-
-		if _, err := str.Write(bytes); err != nil {
-			log.Error(err)
-			return cid.Undef
-		}
-		if err := str.CloseWrite(); err != nil {
-			log.Error(err)
-			return cid.Undef
-		}
-
-		buf, err := io.ReadAll(str)
-		if err != nil {
-			log.Error(err)
-			return cid.Undef
+			return types.RpcResp{}, err
 		}
 
-		var resp types.RpcResp
-		err = json.Unmarshal(buf, &resp)
+		str, err := host.NewStream(ctx, pi.ID, types.RpcProtocol)
 		if err != nil {
-			log.Error(err)
-			return cid.Undef
-		}
-
-		if resp.Error != "" {
-			log.Error("resp err: ", resp.Error)
-			return cid.Undef
-		}
-
-		remoteCid := resp.Data
-
-		if remoteCid == chunkCid.String() {
-			chunkId++
-		} else if remoteCid == contentCid.String() && len(chunk) == 0 {
-			break
-		} else {
-			log.Errorf("file cid mismatch, expected %s, but got %s", remoteCid, chunkCid)
-			return cid.Undef
+			return types.RpcResp{}, err
 		}
+		return doRpc(str, req)
 	}
 
-	return contentCid
+	return uploadFile(ctx, fpath, types.CHUNK_SIZE, send)
 }
 
-func DoTransportTCP(ctx context.Context, repo string, peerInfo string, fpath string) cid.Cid {
-	clientKey := fetchKey(repo)
-	if clientKey == nil {
-		log.Error("failed to generate transport key")
-		return cid.Undef
-	}
-	listenAddrsOption := libp2p.ListenAddrStrings("/ip4/127.0.0.1/tcp/28848")
-	host, err := libp2p.New(listenAddrsOption, libp2p.Identity(clientKey))
-	a, err := ma.NewMultiaddr(peerInfo)
-	fmt.Println(err)
-	pi, err := peer.AddrInfoFromP2pAddr(a)
-	fmt.Println(err)
-	fmt.Println(pi)
-	host.Connect(ctx, *pi)
-	fmt.Println(err)
-
+/**
+ * upload the file chunk by chunk, the chunks received by the server already are skipped,
+ * and the upload is resumed from the missing chunks if it's interrupted.
+ */
+func uploadFile(ctx context.Context, fpath string, chunkSize int, send rpcSender) cid.Cid {
 	file, err := os.Open(fpath)
 	if err != nil {
 		log.Error(err)
 		return cid.Undef
 	}
+	defer file.Close()
 
-	content, err := io.ReadAll(file)
+	contentCid, size, err := utils.CalculateCidFromReader(file)
 	if err != nil {
 		log.Error(err)
 		return cid.Undef
 	}
 
-	contentCid, err := utils.CalculateCid(content)
-	if err != nil {
-		log.Error(err)
-		return cid.Undef
+	u := &upload{
+		file:          file,
+		contentCid:    contentCid,
+		contentLength: int(size),
+		chunkSize:     chunkSize,
+		totalChunks:   (int(size) + chunkSize - 1) / chunkSize,
+		send:          send,
 	}
-
-	rpcReq := types.RpcReq{
-		Method: "Sao.Upload",
+	bufSize := chunkSize
+	if u.contentLength < bufSize {
+		bufSize = u.contentLength
 	}
+	u.buf = make([]byte, bufSize)
 
-	var contentLength int = len(content)
-	var totalChunks = contentLength/types.CHUNK_SIZE + 1
-	chunkId := 0
-	for chunkId <= totalChunks {
-		var chunk []byte
-		if (chunkId+1)*types.CHUNK_SIZE < len(content) {
-			chunk = content[chunkId*types.CHUNK_SIZE : (chunkId+1)*types.CHUNK_SIZE]
-		} else if chunkId*types.CHUNK_SIZE < len(content) {
-			chunk = content[chunkId*types.CHUNK_SIZE:]
-		} else {
-			chunk = make([]byte, 0)
+	for tries := 0; ; tries++ {
+		err = u.run(ctx)
+		if err == nil {
+			return contentCid
+		}
+		if tries >= maxUploadRetries {
+			log.Errorf("upload %s failed: %v", fpath, err)
+			return cid.Undef
 		}
 
-		chunkCid, err := utils.CalculateCid(chunk)
-		if err != nil {
-			log.Error(err)
+		log.Warnf("upload %s interrupted: %v, resuming", fpath, err)
+		select {
+		case <-time.After(time.Duration(tries+1) * time.Second):
+		case <-ctx.Done():
+			log.Error(ctx.Err())
 			return cid.Undef
 		}
+	}
+}
 
-		log.Info("Content[", chunkId, "], CID: ", chunkCid, ", length: ", len(chunk))
+type upload struct {
+	file          *os.File
+	contentCid    cid.Cid
+	contentLength int
+	chunkSize     int
+	totalChunks   int
+	buf           []byte
+	send          rpcSender
+}
 
-		str, err := host.NewStream(ctx, pi.ID, types.RpcProtocol)
-		if err != nil {
-			log.Error("1", err)
-			return cid.Undef
+func (u *upload) run(ctx context.Context) error {
+	received := u.receivedChunks(ctx)
+	if len(received) > 0 {
+		log.Infof("resume uploading %s, %d of %d chunks received already", u.contentCid, len(received), u.totalChunks)
+	}
+
+	for chunkId := 0; chunkId < u.totalChunks; chunkId++ {
+		if received[chunkId] {
+			continue
 		}
-		defer str.Close()
-
-		req := &types.FileChunkReq{
-			ChunkId:     chunkId,
-			TotalLength: contentLength,
-			TotalChunks: totalChunks,
-			ChunkCid:    chunkCid.String(),
-			Cid:         contentCid.String(),
-			Content:     chunk,
+
+		offset := int64(chunkId) * int64(u.chunkSize)
+		chunkLen := u.contentLength - int(offset)
+		if chunkLen > u.chunkSize {
+			chunkLen = u.chunkSize
 		}
-		b, err := json.Marshal(req)
+		chunk := u.buf[:chunkLen]
+		_, err := u.file.ReadAt(chunk, offset)
 		if err != nil {
-			log.Error("2", err)
-			return cid.Undef
+			return err
 		}
 
-		rpcReq.Params = append(make([]string, 0), string(b))
-		bytes, err := json.Marshal(rpcReq)
+		chunkCid, err := utils.CalculateCid(chunk)
 		if err != nil {
-			log.Error("3", err)
-			return cid.Undef
+			return err
 		}
 
-		if _, err := str.Write(bytes); err != nil {
-			log.Error(err)
-			return cid.Undef
-		}
-		if err := str.CloseWrite(); err != nil {
-			log.Error(err)
-			return cid.Undef
-		}
+		log.Info("Content[", chunkId, "], CID: ", chunkCid, ", length: ", len(chunk))
 
-		buf, err := io.ReadAll(str)
+		remoteCid, err := u.sendChunk(ctx, chunkId, chunkCid, chunk)
 		if err != nil {
-			log.Error(err)
-			return cid.Undef
+			return err
 		}
-
-		var resp types.RpcResp
-		err = json.Unmarshal(buf, &resp)
-		if err != nil {
-			log.Error(err)
-			return cid.Undef
+		if remoteCid != chunkCid.String() {
+			return types.Wrapf(types.ErrInvalidCid, "chunk cid mismatch, expected %s, but got %s", chunkCid, remoteCid)
 		}
+	}
 
-		if resp.Error != "" {
-			log.Error("resp err: ", resp.Error)
-			return cid.Undef
-		}
+	// an empty chunk tells the server that the transport is done
+	emptyCid, err := utils.CalculateCid(nil)
+	if err != nil {
+		return err
+	}
+	remoteCid, err := u.sendChunk(ctx, u.totalChunks, emptyCid, nil)
+	if err != nil {
+		return err
+	}
+	if remoteCid != u.contentCid.String() && remoteCid != emptyCid.String() {
+		return types.Wrapf(types.ErrInvalidCid, "file cid mismatch, expected %s, but got %s", u.contentCid, remoteCid)
+	}
+	return nil
+}
 
-		remoteCid := resp.Data
+/**
+ * chunks received by the server, nothing is skipped if the server doesn't support Sao.UploadStatus.
+ */
+func (u *upload) receivedChunks(ctx context.Context) map[int]bool {
+	resp, err := u.send(ctx, types.RpcReq{
+		Method: "Sao.UploadStatus",
+		Params: []string{u.contentCid.String()},
+	})
+	if err != nil || resp.Error != "" {
+		log.Debugf("query upload status of %s failed: %v %s", u.contentCid, err, resp.Error)
+		return nil
+	}
 
-		if remoteCid == chunkCid.String() {
-			chunkId++
-		} else if remoteCid == contentCid.String() && len(chunk) == 0 {
-			break
-		} else {
-			log.Errorf("file cid mismatch, expected %s, but got %s", remoteCid, chunkCid)
-			return cid.Undef
+	var status types.UploadStatusResp
+	err = json.Unmarshal([]byte(resp.Data), &status)
+	if err != nil {
+		log.Debugf("invalid upload status of %s: %v", u.contentCid, err)
+		return nil
+	}
+	if status.TotalLength != u.contentLength || status.TotalChunks != u.totalChunks {
+		return nil
+	}
+
+	received := make(map[int]bool)
+	for chunkId, chunkCid := range status.ChunkCids {
+		if chunkCid != "" {
+			received[chunkId] = true
 		}
 	}
-	return contentCid
+	return received
+}
+
+func (u *upload) sendChunk(ctx context.Context, chunkId int, chunkCid cid.Cid, chunk []byte) (string, error) {
+	req := &types.FileChunkReq{
+		ChunkId:     chunkId,
+		TotalLength: u.contentLength,
+		TotalChunks: u.totalChunks,
+		ChunkCid:    chunkCid.String(),
+		Cid:         u.contentCid.String(),
+		Content:     chunk,
+	}
+	b, err := json.Marshal(req)
+	if err != nil {
+		return "", types.Wrap(types.ErrMarshalFailed, err)
+	}
+
+	resp, err := u.send(ctx, types.RpcReq{
+		Method: "Sao.Upload",
+		Params: []string{string(b)},
+	})
+	if err != nil {
+		return "", err
+	}
+	if resp.Error != "" {
+		return "", types.Wrapf(types.ErrInvalidParameters, "resp err: %s", resp.Error)
+	}
+	return resp.Data, nil
+}
+
+func doRpc(str network.MuxedStream, req types.RpcReq) (types.RpcResp, error) {
+	defer str.Close()
+
+	bytes, err := json.Marshal(req)
+	if err != nil {
+		return types.RpcResp{}, types.Wrap(types.ErrMarshalFailed, err)
+	}
+	if _, err := str.Write(bytes); err != nil {
+		return types.RpcResp{}, err
+	}
+	if err := str.CloseWrite(); err != nil {
+		return types.RpcResp{}, err
+	}
+
+	buf, err := io.ReadAll(str)
+	if err != nil {
+		return types.RpcResp{}, err
+	}
+
+	var resp types.RpcResp
+	err = json.Unmarshal(buf, &resp)
+	if err != nil {
+		return types.RpcResp{}, types.Wrap(types.ErrUnMarshalFailed, err)
+	}
+	return resp, nil
 }
 
 func fetchKey(repo string) ic.PrivKey {
@@ -0,0 +1,108 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/SaoNetwork/sao-node/node/transport"
+	"github.com/SaoNetwork/sao-node/types"
+	"github.com/SaoNetwork/sao-node/utils"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResumeUpload(t *testing.T) {
+	ctx := context.Background()
+	staging := t.TempDir()
+	rh := &transport.RpcHandler{
+		Ctx:              ctx,
+		Db:               dssync.MutexWrap(datastore.NewMapDatastore()),
+		StagingPath:      staging,
+		StagingSapceSize: 1 << 30,
+	}
+	peerPath := filepath.Join(staging, "peer")
+
+	content := bytes.Repeat([]byte("resumable upload "), 1000)
+	fpath := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(fpath, content, 0644))
+	contentCid, err := utils.CalculateCid(content)
+	require.NoError(t, err)
+
+	chunkSize := 4096
+	totalChunks := (len(content) + chunkSize - 1) / chunkSize
+
+	var uploads int
+	failAt := -1
+	sendFrom := func(peerPath string) func(ctx context.Context, req types.RpcReq) (types.RpcResp, error) {
+		return func(ctx context.Context, req types.RpcReq) (types.RpcResp, error) {
+			var result string
+			var err error
+			switch req.Method {
+			case "Sao.Upload":
+				if uploads == failAt {
+					uploads++
+					return types.RpcResp{}, os.ErrDeadlineExceeded
+				}
+				uploads++
+				result, err = rh.Upload(append(req.Params, peerPath))
+			case "Sao.UploadStatus":
+				result, err = rh.UploadStatus(append(req.Params, peerPath))
+			}
+			if err != nil {
+				return types.RpcResp{Error: err.Error()}, nil
+			}
+			return types.RpcResp{Data: result}, nil
+		}
+	}
+	send := sendFrom(peerPath)
+
+	// the 3rd chunk is dropped, only the missing chunks are sent again
+	failAt = 2
+	c := uploadFile(ctx, fpath, chunkSize, send)
+	require.Equal(t, contentCid, c)
+	require.Equal(t, totalChunks+2, uploads)
+
+	received, err := os.ReadFile(filepath.Join(peerPath, contentCid.String(), contentCid.String()))
+	require.NoError(t, err)
+	require.Equal(t, content, received)
+
+	// all chunks are received already, only the final message is sent
+	uploads = 0
+	failAt = -1
+	c = uploadFile(ctx, fpath, chunkSize, send)
+	require.Equal(t, contentCid, c)
+	require.Equal(t, 1, uploads)
+
+	// a removed chunk is uploaded again
+	require.NoError(t, os.Remove(filepath.Join(peerPath, contentCid.String(), chunkCidAt(t, content, chunkSize, 1).String())))
+	uploads = 0
+	c = uploadFile(ctx, fpath, chunkSize, send)
+	require.Equal(t, contentCid, c)
+	require.Equal(t, 2, uploads)
+
+	// another peer uploading the same content keeps its own record
+	uploads = 0
+	c = uploadFile(ctx, fpath, chunkSize/2, sendFrom(filepath.Join(staging, "peer2")))
+	require.Equal(t, contentCid, c)
+	require.Equal(t, (len(content)+chunkSize/2-1)/(chunkSize/2)+1, uploads)
+	uploads = 0
+	c = uploadFile(ctx, fpath, chunkSize, send)
+	require.Equal(t, contentCid, c)
+	require.Equal(t, 1, uploads)
+}
+
+func chunkCidAt(t *testing.T, content []byte, chunkSize int, chunkId int) cid.Cid {
+	end := (chunkId + 1) * chunkSize
+	if end > len(content) {
+		end = len(content)
+	}
+	c, err := utils.CalculateCid(content[chunkId*chunkSize : end])
+	require.NoError(t, err)
+	return c
+}
@@ -0,0 +1,402 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SaoNetwork/sao-node/chain"
+	"github.com/SaoNetwork/sao-node/client"
+	cliutil "github.com/SaoNetwork/sao-node/cmd"
+	"github.com/SaoNetwork/sao-node/types"
+	"github.com/SaoNetwork/sao-node/utils"
+
+	did "github.com/SaoNetwork/sao-did"
+	saotypes "github.com/SaoNetwork/sao/x/sao/types"
+	"github.com/urfave/cli/v2"
+)
+
+var exportCmd = &cli.Command{
+	Name:      "export",
+	Usage:     "export data models with all their commits into an archive",
+	UsageText: "the archive is a CAR file of the model contents, whose root is the manifest of the models. the manifest is also written to <output>.json.",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "did",
+			Usage: "export models owned by the given did, models without read permission are skipped. default is the current did",
+		},
+		&cli.StringFlag{
+			Name:     "output",
+			Usage:    "archive file path",
+			Required: true,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		ctx := cctx.Context
+
+		saoClient, closer, err := getSaoClient(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		didManager, _, err := cliutil.GetDidManager(cctx, saoClient.Cfg.KeyName)
+		if err != nil {
+			return err
+		}
+		owner := didManager.Id
+		if cctx.IsSet("did") {
+			owner = cctx.String("did")
+		}
+
+		gatewayAddress, err := saoClient.GetNodeAddress(ctx)
+		if err != nil {
+			return err
+		}
+
+		metadatas, err := saoClient.ListMetaByDid(ctx, owner)
+		if err != nil {
+			return err
+		}
+
+		writer, err := client.NewArchiveWriter(cctx.String("output"), owner)
+		if err != nil {
+			return err
+		}
+
+		skipped := 0
+		for i, meta := range metadatas {
+			model := client.ArchiveModel{
+				DataId:     meta.DataId,
+				Alias:      meta.Alias,
+				GroupId:    meta.GroupId,
+				Tags:       meta.Tags,
+				Rule:       meta.Rule,
+				ExtendInfo: meta.ExtendInfo,
+			}
+			if strings.HasPrefix(meta.Alias, types.Type_Prefix_File) {
+				model.Skipped = "file content is not archived"
+			}
+
+			for _, commit := range meta.Commits {
+				if model.Skipped != "" {
+					break
+				}
+				commitInfo, err := types.ParseMetaCommit(commit)
+				if err != nil {
+					return types.Wrapf(types.ErrInvalidCommitInfo, "invalid commit information: %s", commit)
+				}
+
+				proposal := saotypes.QueryProposal{
+					Owner:    didManager.Id,
+					Keyword:  meta.DataId,
+					GroupId:  meta.GroupId,
+					CommitId: commitInfo.CommitId,
+				}
+				request, err := buildQueryRequest(ctx, didManager, proposal, saoClient, gatewayAddress)
+				if err != nil {
+					return err
+				}
+				resp, err := saoClient.ModelLoad(ctx, request)
+				if err != nil {
+					model.Skipped = fmt.Sprintf("load commit %s failed: %v", commitInfo.CommitId, err)
+					break
+				}
+
+				contentCid, err := writer.AddContent(resp.Content)
+				if err != nil {
+					return err
+				}
+				model.Commits = append(model.Commits, client.ArchiveCommit{
+					CommitId: commitInfo.CommitId,
+					Height:   commitInfo.Height,
+					Cid:      contentCid.String(),
+					Size:     uint64(len(resp.Content)),
+				})
+			}
+			if model.Skipped != "" {
+				model.Commits = nil
+				skipped++
+				fmt.Printf("[%d/%d] %s skipped: %s\r\n", i+1, len(metadatas), meta.DataId, model.Skipped)
+			} else {
+				fmt.Printf("[%d/%d] %s exported %d commits\r\n", i+1, len(metadatas), meta.DataId, len(model.Commits))
+			}
+			writer.AddModel(model)
+		}
+
+		err = writer.Close()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%d models exported, %d skipped, archive: %s\r\n", len(metadatas)-skipped, skipped, cctx.String("output"))
+		return nil
+	},
+}
+
+var importCmd = &cli.Command{
+	Name:      "import",
+	Usage:     "import data models of an archive into the current account",
+	UsageText: "commits of each model are replayed in order. an interrupted import resumes from the progress file.",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "input",
+			Usage:    "archive file path",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:  "progress",
+			Usage: "progress file path, default is <input>.progress.json",
+		},
+		&cli.IntFlag{
+			Name:  "batch-size",
+			Usage: "models imported at the same time",
+			Value: 10,
+		},
+		&cli.IntFlag{
+			Name:  "duration",
+			Usage: "how many days do you want to store the data",
+			Value: DEFAULT_DURATION,
+		},
+		&cli.IntFlag{
+			Name:  "delay",
+			Usage: "how many epochs to wait for the content to be completed storing",
+			Value: 1 * 60,
+		},
+		&cli.IntFlag{
+			Name:  "replica",
+			Usage: "how many copies to store",
+			Value: DEFAULT_REPLICA,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		ctx := cctx.Context
+
+		batchSize := cctx.Int("batch-size")
+		if batchSize <= 0 {
+			return types.Wrapf(types.ErrInvalidParameters, "invalid batch size %d", batchSize)
+		}
+
+		archive, err := client.OpenArchive(cctx.String("input"))
+		if err != nil {
+			return err
+		}
+		defer archive.Close()
+		manifest := archive.Manifest
+		progressPath := cctx.String("progress")
+		if progressPath == "" {
+			progressPath = cctx.String("input") + ".progress.json"
+		}
+		progress, err := client.LoadImportProgress(progressPath)
+		if err != nil {
+			return err
+		}
+
+		saoClient, closer, err := getSaoClient(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		groupId := cctx.String("platform")
+		if groupId == "" {
+			groupId = saoClient.Cfg.GroupId
+		}
+
+		// proposals of all models are signed by the same did, the key is unlocked once
+		didManager, _, err := cliutil.GetDidManager(cctx, saoClient.Cfg.KeyName)
+		if err != nil {
+			return err
+		}
+
+		gatewayAddress, err := saoClient.GetNodeAddress(ctx)
+		if err != nil {
+			return err
+		}
+
+		im := &modelImporter{
+			client:         saoClient,
+			didManager:     didManager,
+			gatewayAddress: gatewayAddress,
+			groupId:        groupId,
+			duration:       uint64(time.Duration(60*60*24*cctx.Int("duration")) * time.Second / chain.Blocktime),
+			delay:          int32(cctx.Int("delay")),
+			replica:        int32(cctx.Int("replica")),
+			archive:        archive,
+			progress:       progress,
+		}
+
+		var pending []client.ArchiveModel
+		for _, model := range manifest.Models {
+			if model.Skipped == "" && progress.Get(model.DataId).Commits < len(model.Commits) {
+				pending = append(pending, model)
+			}
+		}
+		fmt.Printf("%d models to import, %d imported before\r\n", len(pending), len(progress.Models))
+
+		failed := 0
+		for start := 0; start < len(pending); start += batchSize {
+			end := start + batchSize
+			if end > len(pending) {
+				end = len(pending)
+			}
+
+			var wg sync.WaitGroup
+			errs := make([]error, end-start)
+			for i, model := range pending[start:end] {
+				wg.Add(1)
+				go func(i int, model client.ArchiveModel) {
+					defer wg.Done()
+					errs[i] = im.importModel(ctx, model)
+				}(i, model)
+			}
+			wg.Wait()
+
+			for i, err := range errs {
+				model := pending[start+i]
+				if err != nil {
+					failed++
+					fmt.Printf("[%d/%d] %s failed: %v\r\n", start+i+1, len(pending), model.DataId, err)
+				} else {
+					fmt.Printf("[%d/%d] %s imported as %s\r\n", start+i+1, len(pending), model.DataId, progress.Get(model.DataId).DataId)
+				}
+			}
+		}
+
+		fmt.Printf("%d models imported, %d failed, progress: %s\r\n", len(pending)-failed, failed, progressPath)
+		if failed > 0 {
+			return types.Wrapf(types.ErrInvalidParameters, "%d models failed to import, run the import again to retry them", failed)
+		}
+		return nil
+	},
+}
+
+type modelImporter struct {
+	client         *client.SaoClient
+	didManager     *did.DidManager
+	gatewayAddress string
+	groupId        string
+	duration       uint64
+	delay          int32
+	replica        int32
+	archive        *client.ArchiveReader
+	progress       *client.ImportProgress
+}
+
+/**
+ * replay the commits of the model not imported yet, the first one creates the model and the others update it.
+ */
+func (im *modelImporter) importModel(ctx context.Context, model client.ArchiveModel) error {
+	imported := im.progress.Get(model.DataId)
+	owner := im.didManager.Id
+
+	for i := imported.Commits; i < len(model.Commits); i++ {
+		content, err := im.archive.Content(model.Commits[i].Cid)
+		if err != nil {
+			return err
+		}
+		proposal := saotypes.Proposal{
+			Owner:      owner,
+			Provider:   im.gatewayAddress,
+			GroupId:    im.groupId,
+			Duration:   im.duration,
+			Replica:    im.replica,
+			Timeout:    im.delay,
+			Alias:      model.Alias,
+			Tags:       model.Tags,
+			Cid:        model.Commits[i].Cid,
+			Rule:       model.Rule,
+			Size_:      uint64(len(content)),
+			Operation:  1,
+			ExtendInfo: model.ExtendInfo,
+		}
+
+		if i == 0 {
+			proposal.DataId = utils.GenerateDataId(owner + im.groupId)
+			proposal.CommitId = proposal.DataId
+			var dataId string
+			dataId, err = im.create(ctx, proposal, content)
+			if err == nil {
+				imported.DataId = dataId
+				imported.CommitId = proposal.CommitId
+			}
+		} else {
+			proposal.DataId = imported.DataId
+			proposal.CommitId = imported.CommitId + "|" + utils.GenerateCommitId(owner+im.groupId)
+			var prev []byte
+			prev, err = im.archive.Content(model.Commits[i-1].Cid)
+			if err != nil {
+				return err
+			}
+			// the commit id is kept until the update succeeds, a retry patches against the last imported commit
+			var commitId string
+			commitId, err = im.update(ctx, proposal, prev, content)
+			if err == nil {
+				imported.CommitId = commitId
+			}
+		}
+		if err != nil {
+			imported.LastErr = err.Error()
+			if e := im.progress.Set(model.DataId, imported); e != nil {
+				return e
+			}
+			return err
+		}
+
+		imported.Commits = i + 1
+		imported.LastErr = ""
+		err = im.progress.Set(model.DataId, imported)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (im *modelImporter) create(ctx context.Context, proposal saotypes.Proposal, content []byte) (string, error) {
+	clientProposal, err := buildClientProposal(ctx, im.didManager, proposal, im.client)
+	if err != nil {
+		return "", err
+	}
+	queryProposal := saotypes.QueryProposal{
+		Owner:   proposal.Owner,
+		Keyword: proposal.DataId,
+	}
+	request, err := buildQueryRequest(ctx, im.didManager, queryProposal, im.client, im.gatewayAddress)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := im.client.ModelCreate(ctx, request, clientProposal, 0, content)
+	if err != nil {
+		return "", err
+	}
+	return resp.DataId, nil
+}
+
+func (im *modelImporter) update(ctx context.Context, proposal saotypes.Proposal, prev []byte, content []byte) (string, error) {
+	patch, err := utils.GeneratePatch(string(prev), string(content))
+	if err != nil {
+		return "", err
+	}
+	clientProposal, err := buildClientProposal(ctx, im.didManager, proposal, im.client)
+	if err != nil {
+		return "", err
+	}
+	queryProposal := saotypes.QueryProposal{
+		Owner:   proposal.Owner,
+		Keyword: proposal.DataId,
+		GroupId: proposal.GroupId,
+	}
+	request, err := buildQueryRequest(ctx, im.didManager, queryProposal, im.client, im.gatewayAddress)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := im.client.ModelUpdate(ctx, request, clientProposal, 0, []byte(patch))
+	if err != nil {
+		return "", err
+	}
+	return resp.CommitId, nil
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+
+	saoclient "github.com/SaoNetwork/sao-node/client"
+	cliutil "github.com/SaoNetwork/sao-node/cmd"
+	"github.com/SaoNetwork/sao-node/utils"
+
+	did "github.com/SaoNetwork/sao-did"
+	saotypes "github.com/SaoNetwork/sao/x/sao/types"
+	"github.com/urfave/cli/v2"
+)
+
+var flagEncrypt = &cli.BoolFlag{
+	Name:  "encrypt",
+	Usage: "encrypt the content with a data key wrapped to the owner and the dids with read permission",
+}
+
+/**
+ * public keys of the owner and the readers, the data key of an encrypted model is wrapped to them.
+ */
+func envelopeRecipients(ctx context.Context, client *saoclient.SaoClient, owner string, readers ...[]string) ([][]byte, error) {
+	dids := []string{owner}
+	for _, r := range readers {
+		dids = append(dids, r...)
+	}
+
+	var keys [][]byte
+	for _, did := range dids {
+		if did == "" {
+			continue
+		}
+		pubs, err := client.DidPublicKeys(ctx, did)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, pubs...)
+	}
+	return keys, nil
+}
+
+/**
+ * decrypt the loaded content if it's encrypted, the did key is only derived for encrypted contents.
+ */
+func decryptContent(cctx *cli.Context, client *saoclient.SaoClient, content []byte) ([]byte, error) {
+	if _, ok := saoclient.ParseEnvelope(content); !ok && !saoclient.IsFileEnvelope(content) {
+		return content, nil
+	}
+	privKey, err := cliutil.GetDidPrivKey(cctx, client.Cfg.KeyName)
+	if err != nil {
+		return nil, err
+	}
+	return saoclient.DecryptContent(content, privKey)
+}
+
+/**
+ * seal the current content with the patch applied to the recipients. the data key of an encrypted content
+ * is kept, a plain content is encrypted with a new data key.
+ */
+func resealContent(cctx *cli.Context, client *saoclient.SaoClient, current []byte, patch []byte, recipients [][]byte) ([]byte, error) {
+	var plaintext, dataKey []byte
+	if envelope, ok := saoclient.ParseEnvelope(current); ok {
+		privKey, err := cliutil.GetDidPrivKey(cctx, client.Cfg.KeyName)
+		if err != nil {
+			return nil, err
+		}
+		dataKey, err = envelope.DataKey(privKey)
+		if err != nil {
+			return nil, err
+		}
+		plaintext, err = envelope.Open(dataKey)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		dataKey, err = saoclient.NewDataKey()
+		if err != nil {
+			return nil, err
+		}
+		plaintext = current
+	}
+
+	if len(patch) > 0 {
+		var err error
+		plaintext, err = utils.ApplyPatch(plaintext, patch)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return saoclient.SealEnvelope(plaintext, dataKey, recipients)
+}
+
+/**
+ * reseal an encrypted model to the dids of the permission by committing the content sealed with a new data key,
+ * so that the dids removed from the permission can't decrypt the new commits with the key they unwrapped before.
+ * nothing is committed for a plain model. returns the new commit id.
+ */
+func resealPermission(cctx *cli.Context, client *saoclient.SaoClient, didManager *did.DidManager, permission saotypes.PermissionProposal) (string, error) {
+	ctx := cctx.Context
+
+	gatewayAddress, err := client.GetNodeAddress(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	queryProposal := saotypes.QueryProposal{
+		Owner:   didManager.Id,
+		Keyword: permission.DataId,
+	}
+	request, err := buildQueryRequest(ctx, didManager, queryProposal, client, gatewayAddress)
+	if err != nil {
+		return "", err
+	}
+
+	loaded, err := client.ModelLoad(ctx, request)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := saoclient.ParseEnvelope(loaded.Content); !ok {
+		return "", nil
+	}
+
+	res, err := client.QueryMetadata(ctx, request, 0)
+	if err != nil {
+		return "", err
+	}
+	meta := res.Metadata
+
+	recipients, err := envelopeRecipients(ctx, client, didManager.Id, permission.ReadonlyDids, permission.ReadwriteDids)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := decryptContent(cctx, client, loaded.Content)
+	if err != nil {
+		return "", err
+	}
+	dataKey, err := saoclient.NewDataKey()
+	if err != nil {
+		return "", err
+	}
+	content, err := saoclient.SealEnvelope(plaintext, dataKey, recipients)
+	if err != nil {
+		return "", err
+	}
+	patch, err := utils.GeneratePatch(string(loaded.Content), string(content))
+	if err != nil {
+		return "", err
+	}
+	contentCid, err := utils.CalculateCid(content)
+	if err != nil {
+		return "", err
+	}
+
+	proposal := saotypes.Proposal{
+		Owner:      didManager.Id,
+		Provider:   gatewayAddress,
+		GroupId:    meta.GroupId,
+		Duration:   meta.Duration,
+		Replica:    meta.Replica,
+		Timeout:    int32(1 * 60),
+		DataId:     meta.DataId,
+		Alias:      meta.Alias,
+		Tags:       meta.Tags,
+		Cid:        contentCid.String(),
+		CommitId:   loaded.CommitId + "|" + utils.GenerateCommitId(didManager.Id+meta.GroupId),
+		Rule:       meta.Rule,
+		Operation:  1,
+		Size_:      uint64(len(content)),
+		ExtendInfo: meta.ExtendInfo,
+	}
+	clientProposal, err := buildClientProposal(ctx, didManager, proposal, client)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := client.ModelUpdate(ctx, request, clientProposal, 0, []byte(patch))
+	if err != nil {
+		return "", err
+	}
+	return resp.CommitId, nil
+}
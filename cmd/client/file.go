@@ -67,12 +67,14 @@ var createFileCmd = &cli.Command{
 		},
 		&cli.StringFlag{
 			Name:     "cid",
+			Usage:    "cid of the uploaded file, required without --encrypt",
 			Value:    "",
-			Required: true,
+			Required: false,
 		},
 		&cli.IntFlag{
 			Name:     "size",
-			Required: true,
+			Usage:    "size of the uploaded file, required without --encrypt",
+			Required: false,
 		},
 		&cli.IntFlag{
 			Name:     "replica",
@@ -86,6 +88,19 @@ var createFileCmd = &cli.Command{
 			Value:    "",
 			Required: false,
 		},
+		&cli.BoolFlag{
+			Name:  "encrypt",
+			Usage: "encrypt the local file --file-name with a data key wrapped to the owner, and upload the encrypted file to --multiaddr",
+		},
+		&cli.StringFlag{
+			Name:  "multiaddr",
+			Usage: "remote multiaddr the encrypted file is uploaded to",
+		},
+		&cli.StringFlag{
+			Name:  "protocol",
+			Usage: "protocol to upload the encrypted file (tcp/udp)",
+			Value: "udp",
+		},
 	},
 	Action: func(cctx *cli.Context) error {
 		ctx := cctx.Context
@@ -120,9 +135,9 @@ var createFileCmd = &cli.Command{
 			groupId = client.Cfg.GroupId
 		}
 
-		contentCid, err := cid.Decode(cctx.String("cid"))
-		if err != nil {
-			return types.Wrap(types.ErrInvalidCid, err)
+		encrypt := cctx.Bool("encrypt")
+		if encrypt && !strings.Contains(cctx.String("multiaddr"), "/p2p/") {
+			return types.Wrapf(types.ErrInvalidParameters, "invalid multiaddr: %s", cctx.String("multiaddr"))
 		}
 
 		didManager, signer, err := cliutil.GetDidManager(cctx, client.Cfg.KeyName)
@@ -130,6 +145,19 @@ var createFileCmd = &cli.Command{
 			return err
 		}
 
+		var contentCid cid.Cid
+		if encrypt {
+			contentCid, size, err = uploadEncryptedFile(cctx, client, didManager.Id, cctx.String("file-name"))
+			if err != nil {
+				return err
+			}
+		} else {
+			contentCid, err = cid.Decode(cctx.String("cid"))
+			if err != nil {
+				return types.Wrap(types.ErrInvalidCid, err)
+			}
+		}
+
 		gatewayAddress, err := client.GetNodeAddress(ctx)
 		if err != nil {
 			return err
@@ -336,6 +364,10 @@ var downloadCmd = &cli.Command{
 			if err != nil {
 				return err
 			}
+			resp.Content, err = decryptContent(cctx, client, resp.Content)
+			if err != nil {
+				return err
+			}
 
 			console := color.New(color.FgMagenta, color.Bold)
 
@@ -372,3 +404,47 @@ var downloadCmd = &cli.Command{
 		return nil
 	},
 }
+
+/**
+ * encrypt the local file for the owner in chunks and upload the encrypted file, returns the cid and size of the uploaded file.
+ */
+func uploadEncryptedFile(cctx *cli.Context, client *saoclient.SaoClient, owner string, path string) (cid.Cid, uint64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return cid.Undef, 0, types.Wrap(types.ErrOpenFileFailed, err)
+	}
+	defer file.Close()
+	recipients, err := envelopeRecipients(cctx.Context, client, owner)
+	if err != nil {
+		return cid.Undef, 0, err
+	}
+	dataKey, err := saoclient.NewDataKey()
+	if err != nil {
+		return cid.Undef, 0, err
+	}
+
+	staged, err := os.CreateTemp("", "sao-encrypted-*")
+	if err != nil {
+		return cid.Undef, 0, types.Wrap(types.ErrCreateFileFailed, err)
+	}
+	defer os.Remove(staged.Name())
+	size, err := saoclient.SealFile(staged, file, dataKey, recipients)
+	staged.Close()
+	if err != nil {
+		return cid.Undef, 0, err
+	}
+
+	multiaddr := cctx.String("multiaddr")
+	repo := cctx.String(FlagClientRepo)
+	var c cid.Cid
+	if cctx.String("protocol") == "tcp" {
+		c = saoclient.DoTransportTCP(cctx.Context, repo, multiaddr, staged.Name())
+	} else {
+		c = saoclient.DoTransport(cctx.Context, repo, multiaddr, strings.Split(multiaddr, "/p2p/")[1], staged.Name())
+	}
+	if c == cid.Undef {
+		return cid.Undef, 0, types.Wrapf(types.ErrInvalidParameters, "failed to upload the encrypted file %s", path)
+	}
+	fmt.Printf("encrypted file [%s] uploaded, CID is %s.\r\n", path, c.String())
+	return c, size, nil
+}
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/SaoNetwork/sao-node/chain"
+	saoclient "github.com/SaoNetwork/sao-node/client"
 	cliutil "github.com/SaoNetwork/sao-node/cmd"
 	"github.com/SaoNetwork/sao-node/types"
 	"github.com/SaoNetwork/sao-node/utils"
@@ -48,6 +49,8 @@ var modelCmd = &cli.Command{
 		statusCmd,
 		metaCmd,
 		orderCmd,
+		exportCmd,
+		importCmd,
 	},
 }
 
@@ -116,6 +119,7 @@ var createCmd = &cli.Command{
 			Value:    false,
 			Required: false,
 		},
+		flagEncrypt,
 	},
 	Action: func(cctx *cli.Context) error {
 		ctx := cctx.Context
@@ -133,6 +137,10 @@ var createCmd = &cli.Command{
 		replicas := cctx.Int("replica")
 		delay := cctx.Int("delay")
 		isPublic := cctx.Bool("public")
+		encrypt := cctx.Bool("encrypt")
+		if encrypt && isPublic {
+			return types.Wrapf(types.ErrInvalidParameters, "an encrypted model can't be public")
+		}
 
 		extendInfo := cctx.String("extend-info")
 		if len(extendInfo) > 1024 {
@@ -154,12 +162,27 @@ var createCmd = &cli.Command{
 			groupId = client.Cfg.GroupId
 		}
 
-		contentCid, err := utils.CalculateCid(content)
+		didManager, signer, err := cliutil.GetDidManager(cctx, client.Cfg.KeyName)
 		if err != nil {
 			return err
 		}
 
-		didManager, signer, err := cliutil.GetDidManager(cctx, client.Cfg.KeyName)
+		if encrypt {
+			recipients, err := envelopeRecipients(ctx, client, didManager.Id)
+			if err != nil {
+				return err
+			}
+			dataKey, err := saoclient.NewDataKey()
+			if err != nil {
+				return err
+			}
+			content, err = saoclient.SealEnvelope(content, dataKey, recipients)
+			if err != nil {
+				return err
+			}
+		}
+
+		contentCid, err := utils.CalculateCid(content)
 		if err != nil {
 			return err
 		}
@@ -353,6 +376,10 @@ var loadCmd = &cli.Command{
 		if err != nil {
 			return err
 		}
+		resp.Content, err = decryptContent(cctx, client, resp.Content)
+		if err != nil {
+			return err
+		}
 
 		console := color.New(color.FgMagenta, color.Bold)
 
@@ -1004,7 +1031,7 @@ var commitsCmd = &cli.Command{
 var updateCmd = &cli.Command{
 	Name:      "update",
 	Usage:     "update an existing data model",
-	UsageText: "use patch cmd to generate --patch flag and --cid first. permission error will be reported if you don't have model write perm",
+	UsageText: "use patch cmd to generate --patch flag and --cid first. permission error will be reported if you don't have model write perm. with --encrypt, the patch is generated against the decrypted content and --cid and --size are computed by the client",
 	Flags: []cli.Flag{
 		&cli.StringFlag{
 			Name:     "patch",
@@ -1056,13 +1083,13 @@ var updateCmd = &cli.Command{
 		},
 		&cli.StringFlag{
 			Name:     "cid",
-			Usage:    "target content cid",
-			Required: true,
+			Usage:    "target content cid, required without --encrypt",
+			Required: false,
 		},
 		&cli.IntFlag{
 			Name:     "size",
-			Usage:    "target content size",
-			Required: true,
+			Usage:    "target content size, required without --encrypt",
+			Required: false,
 		},
 		&cli.IntFlag{
 			Name:     "replica",
@@ -1075,6 +1102,7 @@ var updateCmd = &cli.Command{
 			Usage:    "extend information for the model",
 			Required: false,
 		},
+		flagEncrypt,
 	},
 	Action: func(cctx *cli.Context) error {
 		ctx := cctx.Context
@@ -1085,16 +1113,18 @@ var updateCmd = &cli.Command{
 		}
 		keyword := cctx.String("keyword")
 
-		size := cctx.Int("size")
-		if size <= 0 {
-			return types.Wrapf(types.ErrInvalidParameters, "invalid size")
-		}
-
+		encrypt := cctx.Bool("encrypt")
 		patch := []byte(cctx.String("patch"))
+		size := cctx.Int("size")
 		contentCid := cctx.String("cid")
-		newCid, err := cid.Decode(contentCid)
-		if err != nil {
-			return types.Wrapf(types.ErrInvalidCid, "cid=%s", contentCid)
+		if !encrypt {
+			if size <= 0 {
+				return types.Wrapf(types.ErrInvalidParameters, "invalid size")
+			}
+			_, err := cid.Decode(contentCid)
+			if err != nil {
+				return types.Wrapf(types.ErrInvalidCid, "cid=%s", contentCid)
+			}
 		}
 
 		extendInfo := cctx.String("extend-info")
@@ -1150,6 +1180,36 @@ var updateCmd = &cli.Command{
 			return err
 		}
 
+		if encrypt {
+			loaded, err := client.ModelLoad(ctx, request)
+			if err != nil {
+				return err
+			}
+			meta, err := client.GetMeta(ctx, res.Metadata.DataId)
+			if err != nil {
+				return err
+			}
+			recipients, err := envelopeRecipients(ctx, client, meta.Metadata.Owner, meta.Metadata.ReadonlyDids, meta.Metadata.ReadwriteDids)
+			if err != nil {
+				return err
+			}
+			content, err := resealContent(cctx, client, loaded.Content, patch, recipients)
+			if err != nil {
+				return err
+			}
+			encryptedPatch, err := utils.GeneratePatch(string(loaded.Content), string(content))
+			if err != nil {
+				return err
+			}
+			newCid, err := utils.CalculateCid(content)
+			if err != nil {
+				return err
+			}
+			patch = []byte(encryptedPatch)
+			contentCid = newCid.String()
+			size = len(content)
+		}
+
 		force := cctx.Bool("force")
 
 		operation := uint32(1)
@@ -1168,7 +1228,7 @@ var updateCmd = &cli.Command{
 			DataId:     res.Metadata.DataId,
 			Alias:      res.Metadata.Alias,
 			Tags:       cctx.StringSlice("tags"),
-			Cid:        newCid.String(),
+			Cid:        contentCid,
 			CommitId:   commitId + "|" + utils.GenerateCommitId(didManager.Id+groupId),
 			Rule:       cctx.String("rule"),
 			Operation:  operation,
@@ -1202,7 +1262,7 @@ var updateCmd = &cli.Command{
 var updatePermissionCmd = &cli.Command{
 	Name:      "update-permission",
 	Usage:     "update data model's permission",
-	UsageText: "only data model owner can update permission",
+	UsageText: "only data model owner can update permission. the data key of an encrypted model is wrapped to the dids by a new commit",
 	Flags: []cli.Flag{
 		&cli.StringFlag{
 			Name:     "data-id",
@@ -1278,6 +1338,14 @@ var updatePermissionCmd = &cli.Command{
 		}
 
 		fmt.Printf("Data model[%s]'s permission updated.\r\n", dataId)
+
+		commitId, err := resealPermission(cctx, client, didManager, proposal)
+		if err != nil {
+			return err
+		}
+		if commitId != "" {
+			fmt.Printf("Data model[%s]'s data key wrapped to the dids with read permission, commit id: %s.\r\n", dataId, commitId)
+		}
 		return nil
 	},
 }
@@ -22,6 +22,7 @@ import (
 
 	saodid "github.com/SaoNetwork/sao-did"
 	saokey "github.com/SaoNetwork/sao-did/key"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
 	"github.com/filecoin-project/go-jsonrpc"
 	"github.com/gbrlsnchs/jwt/v3"
 	"github.com/multiformats/go-multiaddr"
@@ -82,23 +83,11 @@ func AskForPassphrase() (string, error) {
 }
 
 func GetDidManager(cctx *cli.Context, keyName string) (*saodid.DidManager, string, error) {
-	if cctx.IsSet(FlagKeyName) {
-		keyName = cctx.String(FlagKeyName)
-	}
-
-	// repo := cctx.String("repo")
-
-	address, err := chain.GetAddress(cctx.Context, KeyringHome, keyName)
+	secret, address, err := didSecret(cctx, keyName)
 	if err != nil {
 		return nil, "", err
 	}
 
-	payload := fmt.Sprintf("cosmos %s allows to generate did", address)
-	secret, err := chain.SignByAccount(cctx.Context, KeyringHome, keyName, []byte(payload))
-	if err != nil {
-		return nil, "", types.Wrap(types.ErrSignedFailed, err)
-	}
-
 	provider, err := saokey.NewSecp256k1Provider(secret)
 	if err != nil {
 		return nil, "", types.Wrap(types.ErrCreateProviderFailed, err)
@@ -114,6 +103,35 @@ func GetDidManager(cctx *cli.Context, keyName string) (*saodid.DidManager, strin
 	return &didManager, address, nil
 }
 
+/**
+ * the secp256k1 private key of the did of the account, used to decrypt the data keys wrapped to the did.
+ */
+func GetDidPrivKey(cctx *cli.Context, keyName string) ([]byte, error) {
+	secret, _, err := didSecret(cctx, keyName)
+	if err != nil {
+		return nil, err
+	}
+	return secp256k1.GenPrivKeyFromSecret(secret).Key, nil
+}
+
+func didSecret(cctx *cli.Context, keyName string) ([]byte, string, error) {
+	if cctx.IsSet(FlagKeyName) {
+		keyName = cctx.String(FlagKeyName)
+	}
+
+	address, err := chain.GetAddress(cctx.Context, KeyringHome, keyName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	payload := fmt.Sprintf("cosmos %s allows to generate did", address)
+	secret, err := chain.SignByAccount(cctx.Context, KeyringHome, keyName, []byte(payload))
+	if err != nil {
+		return nil, "", types.Wrap(types.ErrSignedFailed, err)
+	}
+	return secret, address, nil
+}
+
 // TODO: move to makefile
 var GenerateDocCmd = &cli.Command{
 	Name:   "clidoc",
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	cliutil "github.com/SaoNetwork/sao-node/cmd"
+
+	"github.com/filecoin-project/lotus/lib/tablewriter"
+	"github.com/urfave/cli/v2"
+)
+
+var auditsCmd = &cli.Command{
+	Name:  "audits",
+	Usage: "fisherman audit history",
+	Subcommands: []*cli.Command{
+		auditListCmd,
+	},
+}
+
+var auditListCmd = &cli.Command{
+	Name:  "list",
+	Usage: "List shard audits, latest first",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "provider",
+			Usage: "only list audits of this provider",
+		},
+		&cli.BoolFlag{
+			Name:  "failed",
+			Usage: "only list failed audits, pending ones are not failed",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		ctx := cctx.Context
+		apiClient, closer, err := cliutil.GetNodeApi(cctx, cctx.String(FlagStorageRepo), NodeApi, cliutil.ApiToken)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		records, err := apiClient.AuditHistory(ctx, cctx.String("provider"))
+		if err != nil {
+			return err
+		}
+
+		tw := tablewriter.New(
+			tablewriter.Col("Time"),
+			tablewriter.Col("Provider"),
+			tablewriter.Col("DataId"),
+			tablewriter.Col("ShardId"),
+			tablewriter.Col("Cid"),
+			tablewriter.Col("Passed"),
+			tablewriter.Col("Pending"),
+			tablewriter.Col("FaultOpen"),
+			tablewriter.NewLineCol("Message"),
+		)
+		count := 0
+		for _, record := range records {
+			if cctx.Bool("failed") && (record.Passed || record.Pending) {
+				continue
+			}
+			count++
+			tw.Write(map[string]interface{}{
+				"Time":      time.Unix(record.AuditAt, 0).Format(time.RFC3339),
+				"Provider":  record.Provider,
+				"DataId":    record.DataId,
+				"ShardId":   record.ShardId,
+				"Cid":       record.Cid,
+				"Passed":    record.Passed,
+				"Pending":   record.Pending,
+				"FaultOpen": record.FaultOpen,
+				"Message":   record.Message,
+			})
+		}
+		if count == 0 {
+			fmt.Println("No audit records.")
+			return nil
+		}
+		return tw.Flush(os.Stdout)
+	},
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	cliutil "github.com/SaoNetwork/sao-node/cmd"
+
+	"github.com/filecoin-project/lotus/lib/tablewriter"
+	"github.com/urfave/cli/v2"
+)
+
+var gcCmd = &cli.Command{
+	Name:  "gc",
+	Usage: "remove stale staging, upload and cache files",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "only report the stale files without removing them",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		ctx := cctx.Context
+		apiClient, closer, err := cliutil.GetNodeApi(cctx, cctx.String(FlagStorageRepo), NodeApi, cliutil.ApiToken)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		report, err := apiClient.GcStaging(ctx, cctx.Bool("dry-run"))
+		if err != nil {
+			return err
+		}
+		if len(report.Entries) == 0 {
+			fmt.Println("No stale files.")
+			return nil
+		}
+
+		tw := tablewriter.New(
+			tablewriter.Col("Kind"),
+			tablewriter.Col("Path"),
+			tablewriter.Col("Size"),
+			tablewriter.Col("Removed"),
+			tablewriter.NewLineCol("Reason"),
+			tablewriter.NewLineCol("Error"),
+		)
+		for _, entry := range report.Entries {
+			tw.Write(map[string]interface{}{
+				"Kind":    entry.Kind,
+				"Path":    entry.Path,
+				"Size":    entry.Size,
+				"Removed": entry.Removed,
+				"Reason":  entry.Reason,
+				"Error":   entry.Error,
+			})
+		}
+		err = tw.Flush(os.Stdout)
+		if err != nil {
+			return err
+		}
+
+		if report.DryRun {
+			fmt.Printf("%d stale entries, %d bytes can be reclaimed.\n", len(report.Entries), report.Reclaimed)
+		} else {
+			fmt.Printf("%d stale entries, %d bytes reclaimed.\n", len(report.Entries), report.Reclaimed)
+		}
+		return nil
+	},
+}
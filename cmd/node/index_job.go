@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	cliutil "github.com/SaoNetwork/sao-node/cmd"
+	"github.com/SaoNetwork/sao-node/types"
+
+	"github.com/filecoin-project/lotus/lib/tablewriter"
+	"github.com/urfave/cli/v2"
+)
+
+var indexJobAddCmd = &cli.Command{
+	Name:      "add",
+	Usage:     "Add an indexer job collecting the metadata of platforms or the shards of storage providers",
+	ArgsUsage: "<platformId,...|provider,...>",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "type",
+			Usage:    fmt.Sprintf("job type, %s or %s", types.IndexJobTypeMetadata, types.IndexJobTypeShard),
+			Required: true,
+		},
+		&cli.DurationFlag{
+			Name:     "interval",
+			Usage:    "run the job again after the interval, the job runs only once if it's not set",
+			Required: false,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		ctx := cctx.Context
+		if cctx.Args().Len() <= 0 {
+			return types.Wrapf(types.ErrInvalidParameters, "missing platform ids or provider addresses parameter.")
+		}
+
+		apiClient, closer, err := cliutil.GetNodeApi(cctx, cctx.String(FlagStorageRepo), NodeApi, cliutil.ApiToken)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		interval := uint64(cctx.Duration("interval") / time.Second)
+		jobId, err := apiClient.IndexJobAdd(ctx, cctx.String("type"), cctx.Args().Get(0), interval)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("job %s is added.\n", jobId)
+		return nil
+	},
+}
+
+var indexJobListCmd = &cli.Command{
+	Name:  "list",
+	Usage: "List indexer jobs",
+	Action: func(cctx *cli.Context) error {
+		ctx := cctx.Context
+		apiClient, closer, err := cliutil.GetNodeApi(cctx, cctx.String(FlagStorageRepo), NodeApi, cliutil.ApiToken)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		jobs, err := apiClient.IndexJobList(ctx)
+		if err != nil {
+			return err
+		}
+
+		if len(jobs) > 0 {
+			tw := tablewriter.New(
+				tablewriter.Col("JobId"),
+				tablewriter.Col("Type"),
+				tablewriter.Col("Param"),
+				tablewriter.Col("Interval"),
+				tablewriter.Col("Status"),
+				tablewriter.Col("LastRun"),
+				tablewriter.Col("Retries"),
+				tablewriter.NewLineCol("LastError"),
+			)
+			for _, job := range jobs {
+				lastRun := ""
+				if job.LastRunAt > 0 {
+					lastRun = time.Unix(job.LastRunAt, 0).Format(time.RFC3339)
+				}
+				tw.Write(map[string]interface{}{
+					"JobId":     job.JobId,
+					"Type":      job.JobType,
+					"Param":     job.Param,
+					"Interval":  time.Duration(job.Interval) * time.Second,
+					"Status":    job.Status,
+					"LastRun":   lastRun,
+					"Retries":   job.Retries,
+					"LastError": job.LastError,
+				})
+			}
+			return tw.Flush(os.Stdout)
+		} else {
+			fmt.Println("No indexer jobs.")
+			return nil
+		}
+	},
+}
+
+var indexJobKillCmd = &cli.Command{
+	Name:      "kill",
+	Usage:     "Stop an indexer job and delete it",
+	ArgsUsage: "<jobId>",
+	Action: func(cctx *cli.Context) error {
+		ctx := cctx.Context
+		if cctx.Args().Len() <= 0 {
+			return types.Wrapf(types.ErrInvalidParameters, "missing job id parameter.")
+		}
+
+		apiClient, closer, err := cliutil.GetNodeApi(cctx, cctx.String(FlagStorageRepo), NodeApi, cliutil.ApiToken)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		jobId := cctx.Args().Get(0)
+		err = apiClient.IndexJobKill(ctx, jobId)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("job %s is killed.\n", jobId)
+		return nil
+	},
+}
+
+var indexJobReindexCmd = &cli.Command{
+	Name:      "reindex",
+	Usage:     "Rebuild the rows of an indexer job from the current chain state, then follow the blocks from there",
+	ArgsUsage: "<jobId>",
+	Action: func(cctx *cli.Context) error {
+		ctx := cctx.Context
+		if cctx.Args().Len() <= 0 {
+			return types.Wrapf(types.ErrInvalidParameters, "missing job id parameter.")
+		}
+
+		apiClient, closer, err := cliutil.GetNodeApi(cctx, cctx.String(FlagStorageRepo), NodeApi, cliutil.ApiToken)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		jobId := cctx.Args().Get(0)
+		err = apiClient.IndexJobReindex(ctx, jobId)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("job %s is scheduled to reindex.\n", jobId)
+		return nil
+	},
+}
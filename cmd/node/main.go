@@ -125,6 +125,7 @@ func main() {
 			queryFaultsCmd,
 			declareFaultsRecoverCmd,
 			jobsCmd,
+			gcCmd,
 			initTxAddressPoolCmd,
 			account.AccountCmd,
 			cliutil.GenerateDocCmd,
@@ -144,6 +145,11 @@ var jobsCmd = &cli.Command{
 		ordersCmd,
 		shardsCmd,
 		migrationsCmd,
+		auditsCmd,
+		indexJobAddCmd,
+		indexJobListCmd,
+		indexJobKillCmd,
+		indexJobReindexCmd,
 	},
 }
 
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"time"
 
 	apiclient "github.com/SaoNetwork/sao-node/api/client"
 	cliutil "github.com/SaoNetwork/sao-node/cmd"
@@ -18,7 +19,10 @@ var ordersCmd = &cli.Command{
 	Subcommands: []*cli.Command{
 		orderStatusCmd,
 		orderListCmd,
-		// orderFixCmd,
+		orderHistoryCmd,
+		orderFixCmd,
+		orderCancelCmd,
+		orderReassignCmd,
 	},
 }
 
@@ -54,6 +58,59 @@ var orderListCmd = &cli.Command{
 	},
 }
 
+var orderHistoryCmd = &cli.Command{
+	Name:      "history",
+	Usage:     "List state transitions of the orders of the data and their shards, oldest first",
+	ArgsUsage: "<dataId>",
+	Flags: []cli.Flag{
+		&cli.Uint64Flag{
+			Name:  "orderId",
+			Usage: "only list the transitions of this order",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		ctx := cctx.Context
+		if cctx.Args().Len() <= 0 {
+			return types.Wrapf(types.ErrInvalidParameters, "missing data id parameter.")
+		}
+
+		apiClient, closer, err := cliutil.GetNodeApi(cctx, cctx.String(FlagStorageRepo), NodeApi, cliutil.ApiToken)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		events, err := apiClient.OrderHistory(ctx, cctx.Args().Get(0), cctx.Uint64("orderId"))
+		if err != nil {
+			return err
+		}
+
+		tw := tablewriter.New(
+			tablewriter.Col("Time"),
+			tablewriter.Col("OrderId"),
+			tablewriter.Col("Kind"),
+			tablewriter.Col("Subject"),
+			tablewriter.Col("From"),
+			tablewriter.Col("To"),
+			tablewriter.Col("Tries"),
+			tablewriter.NewLineCol("Message"),
+		)
+		for _, event := range events {
+			tw.Write(map[string]interface{}{
+				"Time":    time.UnixMilli(event.Time).Format(time.RFC3339),
+				"OrderId": event.OrderId,
+				"Kind":    event.Kind,
+				"Subject": event.Subject,
+				"From":    event.From,
+				"To":      event.To,
+				"Tries":   event.Tries,
+				"Message": event.Message,
+			})
+		}
+		return tw.Flush(os.Stdout)
+	},
+}
+
 var orderStatusCmd = &cli.Command{
 	Name:  "status",
 	Usage: "",
@@ -76,6 +133,109 @@ var orderStatusCmd = &cli.Command{
 		fmt.Println("Id: ", orderInfo.DataId)
 		fmt.Println("OrderId: ", orderInfo.OrderId)
 		fmt.Println("State: ", orderInfo.State.String())
+		fmt.Println("Tries: ", orderInfo.Tries)
+		if orderInfo.QueuePosition > 0 {
+			fmt.Println("QueuePosition: ", orderInfo.QueuePosition)
+		}
+		if orderInfo.LastErr != "" {
+			fmt.Println("LastErr: ", orderInfo.LastErr)
+		}
+		if orderInfo.RetryPolicy != "" {
+			fmt.Println("RetryPolicy: ", orderInfo.RetryPolicy)
+		}
+		if orderInfo.RetryAt > 0 {
+			fmt.Println("NextAttempt: ", time.Unix(orderInfo.RetryAt, 0).Format(time.RFC3339))
+		}
+		return nil
+	},
+}
+
+var orderFixCmd = &cli.Command{
+	Name:      "fix",
+	Usage:     "Reset the retries of an order which is still in progress on chain and process it again",
+	ArgsUsage: "<dataId>",
+	Action: func(cctx *cli.Context) error {
+		ctx := cctx.Context
+		if cctx.Args().Len() <= 0 {
+			return types.Wrapf(types.ErrInvalidParameters, "missing data id parameter.")
+		}
+
+		apiClient, closer, err := cliutil.GetNodeApi(cctx, cctx.String(FlagStorageRepo), NodeApi, cliutil.ApiToken)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		dataId := cctx.Args().Get(0)
+		err = apiClient.OrderFix(ctx, dataId)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("order %s is scheduled again.\n", dataId)
+		return nil
+	},
+}
+
+var orderCancelCmd = &cli.Command{
+	Name:      "cancel",
+	Usage:     "Stop processing an in-flight order and release its staging files",
+	ArgsUsage: "<dataId>",
+	Action: func(cctx *cli.Context) error {
+		ctx := cctx.Context
+		if cctx.Args().Len() <= 0 {
+			return types.Wrapf(types.ErrInvalidParameters, "missing data id parameter.")
+		}
+
+		apiClient, closer, err := cliutil.GetNodeApi(cctx, cctx.String(FlagStorageRepo), NodeApi, cliutil.ApiToken)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		dataId := cctx.Args().Get(0)
+		err = apiClient.OrderCancel(ctx, dataId)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("order %s is cancelled.\n", dataId)
+		return nil
+	},
+}
+
+var orderReassignCmd = &cli.Command{
+	Name:      "reassign",
+	Usage:     "Hand the shard of a provider over to another provider holding a waiting shard of the order on chain",
+	ArgsUsage: "<dataId>",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "from",
+			Usage:    "provider the shard is assigned to",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:     "to",
+			Usage:    "provider to assign the shard to",
+			Required: true,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		ctx := cctx.Context
+		if cctx.Args().Len() <= 0 {
+			return types.Wrapf(types.ErrInvalidParameters, "missing data id parameter.")
+		}
+
+		apiClient, closer, err := cliutil.GetNodeApi(cctx, cctx.String(FlagStorageRepo), NodeApi, cliutil.ApiToken)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		dataId := cctx.Args().Get(0)
+		err = apiClient.OrderReassign(ctx, dataId, cctx.String("from"), cctx.String("to"))
+		if err != nil {
+			return err
+		}
+		fmt.Printf("order %s shard of %s is reassigned to %s.\n", dataId, cctx.String("from"), cctx.String("to"))
 		return nil
 	},
 }
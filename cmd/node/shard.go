@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"time"
 
 	cliutil "github.com/SaoNetwork/sao-node/cmd"
 	"github.com/SaoNetwork/sao-node/types"
@@ -19,7 +20,7 @@ var shardsCmd = &cli.Command{
 	Subcommands: []*cli.Command{
 		shardStatusCmd,
 		shardListCmd,
-		// shardFixCmd,
+		shardFixCmd,
 	},
 }
 
@@ -57,6 +58,16 @@ var shardStatusCmd = &cli.Command{
 		fmt.Println("OrderId: ", orderId)
 		fmt.Println("Cid: ", shardCid)
 		fmt.Println("State: ", shardInfo.State)
+		fmt.Println("Tries: ", shardInfo.Tries)
+		if shardInfo.LastErr != "" {
+			fmt.Println("LastErr: ", shardInfo.LastErr)
+		}
+		if shardInfo.RetryPolicy != "" {
+			fmt.Println("RetryPolicy: ", shardInfo.RetryPolicy)
+		}
+		if shardInfo.RetryAt > 0 {
+			fmt.Println("NextAttempt: ", time.Unix(shardInfo.RetryAt, 0).Format(time.RFC3339))
+		}
 
 		return nil
 	},
@@ -106,3 +117,39 @@ var shardListCmd = &cli.Command{
 		return tw.Flush(os.Stdout)
 	},
 }
+
+var shardFixCmd = &cli.Command{
+	Name:  "fix",
+	Usage: "reset the retries of a shard which is still waiting on chain and process it again",
+	Flags: []cli.Flag{
+		&cli.Uint64Flag{
+			Name:     "orderId",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:     "cid",
+			Required: true,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		ctx := cctx.Context
+		orderId := cctx.Uint64("orderId")
+		shardCid, err := cid.Decode(cctx.String("cid"))
+		if err != nil {
+			return types.Wrap(types.ErrInvalidCid, err)
+		}
+
+		apiClient, closer, err := cliutil.GetNodeApi(cctx, cctx.String(FlagStorageRepo), NodeApi, cliutil.ApiToken)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		err = apiClient.ShardFix(ctx, orderId, shardCid)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("shard of order %d cid %v is scheduled again.\n", orderId, shardCid)
+		return nil
+	},
+}
@@ -200,7 +200,7 @@ func init() {
 		CommitId: "fd248a7c-cf9f-4902-8327-58629aef96e9",
 		Version:  "v0",
 		Cid:      "bafkreide7eax3pd3qsbolguprfta7thinb4wmbvyh2kestrdeiydg77tsq",
-		Content:  `{"content":"","isEdit":false,"time":"2022-12-20 06:41","title":"sample"}`,
+		Content:  []byte(`{"content":"","isEdit":false,"time":"2022-12-20 06:41","title":"sample"}`),
 	}
 	addExample(loadResp)
 
@@ -308,6 +308,18 @@ func init() {
 		State:            types.MigrateStateComplete,
 	}})
 
+	addExample([]types.IndexJobInfo{{
+		JobId:       "0e1d6a28-6e7e-11ed-8e07-dcca376a53d2",
+		JobType:     types.IndexJobTypeMetadata,
+		Param:       "dcca376a-53d2-4a0e-a77f-b52ff19a5eda",
+		Description: "build metadata index for models with specified groupIds",
+		Interval:    600,
+		Status:      types.JobStatusSuccessed,
+		CreatedAt:   1669884331,
+		LastRunAt:   1669884931,
+		LastError:   "",
+	}})
+
 	addExample(apitypes.MigrateResp{
 		TxHash: "",
 		Results: map[string]string{
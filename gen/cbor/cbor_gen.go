@@ -20,20 +20,33 @@ func main() {
 		types.OrderIndex{},
 		types.OrderShardInfo{},
 		types.OrderInfo{},
+		types.OrderEvent{},
+		types.OrderHistory{},
 		// shard state
 		types.ShardKey{},
 		types.ShardInfo{},
 		types.ShardIndex{},
+		types.ShardCommitment{},
 		// migrate state
 		types.MigrateKey{},
 		types.MigrateInfo{},
 		types.MigrateIndex{},
+		// audit state
+		types.AuditRecord{},
+		types.AuditHistory{},
+		types.AuditKey{},
+		types.AuditIndex{},
+		// indexer job state
+		types.IndexJobInfo{},
+		types.IndexJobKey{},
+		types.IndexJobIndex{},
 
 		types.QueryProposal{},
 		types.RelayProposal{},
 		types.JwsSignature{},
 		types.MetadataProposalCbor{},
 		types.RelayProposalCbor{},
+		types.TraceContext{},
 		types.ShardAssignReq{},
 		types.ShardAssignResp{},
 		types.ShardCompleteReq{},
@@ -43,6 +56,11 @@ func main() {
 		types.ShardMigrateReq{},
 		types.ShardMigrateResp{},
 		types.ShardPingPong{},
+		types.ShardChunk{},
+		types.ShardStreamAck{},
+		types.ShardChallengeReq{},
+		types.ShardLeafProof{},
+		types.ShardChallengeResp{},
 	)
 	if err != nil {
 		fmt.Println(err)
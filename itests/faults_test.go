@@ -0,0 +1,55 @@
+package itests
+
+import (
+	"testing"
+
+	apitypes "github.com/SaoNetwork/sao-node/api/types"
+	"github.com/SaoNetwork/sao-node/itests/kit"
+	"github.com/SaoNetwork/sao-node/types"
+
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFaultsCheck(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping multi-node test in short mode")
+	}
+
+	ens := kit.NewEnsemble(t, kit.DefaultBlocktime)
+	ctx := ens.Context()
+
+	fisherman := ens.Fisherman()
+	healthy := ens.Storage()
+	broken := ens.Storage()
+
+	client := ens.Client()
+	created := client.CreateModel(ctx, fisherman, "itest-faults", []byte(`{"name": "faults"}`), 2)
+	orderInfo := fisherman.WaitOrderState(ctx, created.DataId, types.OrderStateComplete)
+	shardCid, err := cid.Decode(created.Cid)
+	require.NoError(t, err)
+	for _, sp := range []*kit.TestNode{healthy, broken} {
+		sp.WaitShardState(ctx, orderInfo.OrderId, shardCid, types.ShardStateComplete)
+	}
+
+	// the first audit is pending while the commitment to the shard content is built, no fault is reported
+	_, err = fisherman.FaultsCheck(ctx, []string{created.DataId})
+	require.Error(t, err)
+
+	broken.DropShard(ctx, shardCid)
+	var report *apitypes.FileFaultsReportResp
+	require.Eventually(t, func() bool {
+		report, err = fisherman.FaultsCheck(ctx, []string{created.DataId})
+		return err == nil
+	}, kit.DefaultWaitTimeout, kit.DefaultWaitTick)
+	require.Len(t, report.Faults, 1)
+	require.Len(t, report.Faults[broken.Address], 1)
+	require.Equal(t, created.DataId, report.Faults[broken.Address][0].DataId)
+
+	faultIds, err := ens.Chain.GetMyFaults(ctx, broken.Address)
+	require.NoError(t, err)
+	require.Len(t, faultIds, 1)
+	faultIds, err = ens.Chain.GetMyFaults(ctx, healthy.Address)
+	require.NoError(t, err)
+	require.Empty(t, faultIds)
+}
@@ -0,0 +1,142 @@
+package kit
+
+import (
+	"context"
+	"crypto/rand"
+	"testing"
+
+	apitypes "github.com/SaoNetwork/sao-node/api/types"
+	"github.com/SaoNetwork/sao-node/chain"
+	"github.com/SaoNetwork/sao-node/types"
+	"github.com/SaoNetwork/sao-node/utils"
+
+	saodid "github.com/SaoNetwork/sao-did"
+	saokey "github.com/SaoNetwork/sao-did/key"
+	saotypes "github.com/SaoNetwork/sao/x/sao/types"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	DefaultOrderDuration = 1000
+	DefaultOrderTimeout  = 100
+)
+
+/**
+ * TestClient signs proposals with a fresh key did, the way saoclient does, and sends them to gateways in process.
+ */
+type TestClient struct {
+	t     *testing.T
+	chain chain.ChainSvcApi
+
+	DidManager *saodid.DidManager
+	GroupId    string
+}
+
+func (e *Ensemble) Client() *TestClient {
+	seed := make([]byte, 32)
+	_, err := rand.Read(seed)
+	require.NoError(e.t, err)
+
+	provider, err := saokey.NewSecp256k1Provider(seed)
+	require.NoError(e.t, err)
+	didManager := saodid.NewDidManager(provider, saokey.NewKeyResolver())
+	_, err = didManager.Authenticate([]string{}, "")
+	require.NoError(e.t, err)
+
+	return &TestClient{
+		t:          e.t,
+		chain:      e.Chain,
+		DidManager: &didManager,
+		GroupId:    "itest",
+	}
+}
+
+func (c *TestClient) Did() string {
+	return c.DidManager.Id
+}
+
+/**
+ * create a model through the gateway, the order is published by the gateway.
+ */
+func (c *TestClient) CreateModel(ctx context.Context, gateway *TestNode, alias string, content []byte, replica int32) apitypes.CreateResp {
+	contentCid, err := utils.CalculateCid(content)
+	require.NoError(c.t, err)
+
+	dataId := utils.GenerateDataId(c.Did() + c.GroupId)
+	proposal := saotypes.Proposal{
+		DataId:    dataId,
+		Owner:     c.Did(),
+		Provider:  gateway.Address,
+		GroupId:   c.GroupId,
+		Duration:  DefaultOrderDuration,
+		Replica:   replica,
+		Timeout:   DefaultOrderTimeout,
+		Alias:     alias,
+		Cid:       contentCid.String(),
+		CommitId:  dataId,
+		Size_:     uint64(len(content)),
+		Operation: 1,
+	}
+	orderProposal := c.OrderProposal(proposal)
+	request := c.QueryRequest(ctx, gateway, saotypes.QueryProposal{
+		Owner:   c.Did(),
+		Keyword: dataId,
+	})
+
+	resp, err := gateway.ModelCreate(ctx, request, orderProposal, 0, content)
+	require.NoError(c.t, err)
+	return resp
+}
+
+/**
+ * load the latest version of the model through the gateway.
+ */
+func (c *TestClient) LoadModel(ctx context.Context, gateway *TestNode, keyword string) (apitypes.LoadResp, error) {
+	request := c.QueryRequest(ctx, gateway, saotypes.QueryProposal{
+		Owner:   c.Did(),
+		Keyword: keyword,
+		GroupId: c.GroupId,
+	})
+	return gateway.ModelLoad(ctx, request)
+}
+
+func (c *TestClient) OrderProposal(proposal saotypes.Proposal) *types.OrderStoreProposal {
+	proposalBytes, err := proposal.Marshal()
+	require.NoError(c.t, err)
+	jws, err := c.DidManager.CreateJWS(proposalBytes)
+	require.NoError(c.t, err)
+
+	return &types.OrderStoreProposal{
+		Proposal: proposal,
+		JwsSignature: saotypes.JwsSignature{
+			Protected: jws.Signatures[0].Protected,
+			Signature: jws.Signatures[0].Signature,
+		},
+	}
+}
+
+/**
+ * sign the query proposal, it's valid for 200 blocks on the given gateway.
+ */
+func (c *TestClient) QueryRequest(ctx context.Context, gateway *TestNode, proposal saotypes.QueryProposal) *types.MetadataProposal {
+	lastHeight, err := c.chain.GetLastHeight(ctx)
+	require.NoError(c.t, err)
+	peerInfo, err := c.chain.GetNodePeer(ctx, gateway.Address)
+	require.NoError(c.t, err)
+
+	proposal.LastValidHeight = uint64(lastHeight + 200)
+	proposal.Gateway = peerInfo
+
+	proposalBytes, err := proposal.Marshal()
+	require.NoError(c.t, err)
+	jws, err := c.DidManager.CreateJWS(proposalBytes)
+	require.NoError(c.t, err)
+
+	return &types.MetadataProposal{
+		Proposal: proposal,
+		JwsSignature: saotypes.JwsSignature{
+			Protected: jws.Signatures[0].Protected,
+			Signature: jws.Signatures[0].Signature,
+		},
+	}
+}
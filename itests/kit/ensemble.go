@@ -0,0 +1,186 @@
+package kit
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SaoNetwork/sao-node/chain"
+	"github.com/SaoNetwork/sao-node/node"
+	"github.com/SaoNetwork/sao-node/node/config"
+	"github.com/SaoNetwork/sao-node/node/repo"
+
+	"github.com/ipfs/go-datastore"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// default block time of the ensemble chain, orders and shards are measured in blocks.
+	DefaultBlocktime = 200 * time.Millisecond
+	// how long the Wait* helpers poll before failing the test.
+	DefaultWaitTimeout = time.Minute
+	DefaultWaitTick    = 200 * time.Millisecond
+)
+
+var ensembleSeq int64
+
+type NodeOpt func(cfg *config.Node)
+
+/**
+ * Ensemble runs in-process nodes with temporary repos, all attached to one mock chain and
+ * connected to each other with libp2p over loopback.
+ */
+type Ensemble struct {
+	t      *testing.T
+	ctx    context.Context
+	remote string
+
+	Chain   *chain.MockChainSvc
+	Nodes   []*TestNode
+	fishmen []string
+}
+
+func NewEnsemble(t *testing.T, blocktime time.Duration) *Ensemble {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	remote := fmt.Sprintf("%sitest-%d-%d?blocktime=%v", chain.MOCK_CHAIN_PREFIX, os.Getpid(), atomic.AddInt64(&ensembleSeq, 1), blocktime)
+	mc, err := chain.NewMockChainSvc(ctx, remote, "")
+	require.NoError(t, err)
+
+	e := &Ensemble{
+		t:      t,
+		ctx:    ctx,
+		remote: remote,
+		Chain:  mc,
+	}
+	t.Cleanup(func() {
+		for i := len(e.Nodes) - 1; i >= 0; i-- {
+			if err := e.Nodes[i].Stop(context.Background()); err != nil {
+				t.Logf("stop node %s: %v", e.Nodes[i].Address, err)
+			}
+		}
+		mc.Stop(context.Background())
+		cancel()
+	})
+	return e
+}
+
+func (e *Ensemble) Context() context.Context {
+	return e.ctx
+}
+
+/**
+ * start a gateway node which doesn't store shards.
+ */
+func (e *Ensemble) Gateway(opts ...NodeOpt) *TestNode {
+	return e.AddNode(append([]NodeOpt{func(cfg *config.Node) {
+		cfg.Module.GatewayEnable = true
+		cfg.Module.StorageEnable = false
+	}}, opts...)...)
+}
+
+/**
+ * start a storage node accepting orders.
+ */
+func (e *Ensemble) Storage(opts ...NodeOpt) *TestNode {
+	return e.AddNode(append([]NodeOpt{func(cfg *config.Node) {
+		cfg.Module.StorageEnable = true
+		cfg.Storage.AcceptOrder = true
+	}}, opts...)...)
+}
+
+/**
+ * start a gateway node registered as a fisherman on the chain.
+ */
+func (e *Ensemble) Fisherman(opts ...NodeOpt) *TestNode {
+	return e.Gateway(append([]NodeOpt{func(cfg *config.Node) {
+		cfg.Module.FishermanEnable = true
+		// audits are driven by the tests
+		cfg.Fisherman.AuditInterval = 0
+	}}, opts...)...)
+}
+
+/**
+ * init a temporary repo and keyring, start the node on it and connect it to the running nodes.
+ */
+func (e *Ensemble) AddNode(opts ...NodeOpt) *TestNode {
+	t := e.t
+	dir := t.TempDir()
+
+	tn := &TestNode{
+		t:           t,
+		RepoPath:    filepath.Join(dir, "repo"),
+		KeyringHome: filepath.Join(dir, "keyring"),
+		StorePath:   filepath.Join(dir, "store"),
+	}
+
+	_, address, _, err := chain.Create(e.ctx, tn.KeyringHome, "node")
+	require.NoError(t, err)
+	tn.Address = address
+
+	cfg := config.DefaultSaoNode()
+	cfg.Chain.Remote = e.remote
+	cfg.Api.ListenAddress = "/ip4/127.0.0.1/tcp/0/http"
+	cfg.Libp2p.ListenAddress = []string{"/ip4/127.0.0.1/tcp/0"}
+	cfg.Transport.TransportListenAddress = []string{}
+	cfg.SaoIpfs.Enable = false
+	cfg.SaoHttpFileServer.Enable = false
+	cfg.SaoHttpFileServer.HttpFileServerPath = filepath.Join(dir, "http-files")
+	cfg.Storage.Local = []config.Local{{Path: tn.StorePath}}
+	cfg.Indexer.DbPath = filepath.Join(dir, "indexer")
+	cfg.Module.IndexerEnable = false
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	tn.Config = cfg
+
+	// write the config before init, init keeps an existing config
+	require.NoError(t, os.MkdirAll(tn.RepoPath, 0755))
+	require.NoError(t, os.MkdirAll(cfg.SaoHttpFileServer.HttpFileServerPath, 0755))
+	cfgBytes, err := config.ConfigUpdate(cfg, config.DefaultSaoNode(), false)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(tn.RepoPath, "config.toml"), cfgBytes, 0644))
+
+	r, err := repo.NewRepo(tn.RepoPath)
+	require.NoError(t, err)
+	require.NoError(t, r.Init(e.remote, 0))
+	mds, err := r.Datastore(e.ctx, "/metadata")
+	require.NoError(t, err)
+	require.NoError(t, mds.Put(e.ctx, datastore.NewKey("node-address"), []byte(address)))
+
+	n, err := node.NewNode(e.ctx, r, tn.KeyringHome, nil)
+	require.NoError(t, err)
+	tn.Node = n
+
+	pi, err := n.NetAddrsListen(e.ctx)
+	require.NoError(t, err)
+	for _, other := range e.Nodes {
+		require.NoError(t, other.NetConnect(e.ctx, pi))
+	}
+	e.Nodes = append(e.Nodes, tn)
+
+	if cfg.Module.FishermanEnable {
+		// storage nodes serve whole shards to fishermen matched by peer id, and challenges by address.
+		e.fishmen = append(e.fishmen, address, pi.ID.String())
+		e.Chain.SetFishmen(e.fishmen...)
+	}
+
+	return tn
+}
+
+/**
+ * the running node of the given account address.
+ */
+func (e *Ensemble) Node(address string) *TestNode {
+	for _, n := range e.Nodes {
+		if n.Address == address {
+			return n
+		}
+	}
+	e.t.Fatalf("no node with address %s", address)
+	return nil
+}
@@ -0,0 +1,98 @@
+package kit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/SaoNetwork/sao-node/node"
+	"github.com/SaoNetwork/sao-node/node/config"
+	"github.com/SaoNetwork/sao-node/store"
+	"github.com/SaoNetwork/sao-node/types"
+
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+)
+
+type TestNode struct {
+	*node.Node
+	t *testing.T
+
+	Address     string
+	RepoPath    string
+	KeyringHome string
+	StorePath   string
+	Config      *config.Node
+}
+
+/**
+ * wait until the gateway order of dataId reaches the state, returns the last order info.
+ */
+func (tn *TestNode) WaitOrderState(ctx context.Context, dataId string, state types.OrderState) types.OrderInfo {
+	var info types.OrderInfo
+	require.Eventuallyf(tn.t, func() bool {
+		var err error
+		info, err = tn.OrderStatus(ctx, dataId)
+		return err == nil && info.State == state
+	}, DefaultWaitTimeout, DefaultWaitTick, "order %s of %s not %v, last: %+v", dataId, tn.Address, state, &info)
+	return info
+}
+
+/**
+ * wait until the shard of the order stored by this node reaches the state, returns the last shard info.
+ */
+func (tn *TestNode) WaitShardState(ctx context.Context, orderId uint64, shardCid cid.Cid, state types.ShardState) types.ShardInfo {
+	var info types.ShardInfo
+	require.Eventuallyf(tn.t, func() bool {
+		var err error
+		info, err = tn.ShardStatus(ctx, orderId, shardCid)
+		return err == nil && info.State == state
+	}, DefaultWaitTimeout, DefaultWaitTick, "shard %d-%v of %s not %v, last: %+v", orderId, shardCid, tn.Address, state, &info)
+	return info
+}
+
+/**
+ * wait until the migration of dataId started by this node reaches the state.
+ */
+func (tn *TestNode) WaitMigrateState(ctx context.Context, dataId string, state types.MigrateState) types.MigrateInfo {
+	var found types.MigrateInfo
+	require.Eventuallyf(tn.t, func() bool {
+		infos, err := tn.MigrateJobList(ctx)
+		if err != nil {
+			return false
+		}
+		for _, info := range infos {
+			if info.DataId == dataId && info.FromProvider == tn.Address {
+				found = info
+				return info.State == state
+			}
+		}
+		return false
+	}, DefaultWaitTimeout, DefaultWaitTick, "migration of %s from %s not %v, last: %+v", dataId, tn.Address, state, &found)
+	return found
+}
+
+/**
+ * remove the shard content from the local store of the node behind its back, to simulate data loss.
+ */
+func (tn *TestNode) DropShard(ctx context.Context, shardCid cid.Cid) {
+	backend, err := store.NewLocalBackend(tn.StorePath)
+	require.NoError(tn.t, err)
+	require.NoError(tn.t, backend.Remove(ctx, shardCid))
+}
+
+/**
+ * make writes to the local store of the node fail until RepairStore, its temporary directory is replaced by a file.
+ */
+func (tn *TestNode) BreakStore() {
+	tmpDir := filepath.Join(tn.StorePath, "tmp")
+	require.NoError(tn.t, os.RemoveAll(tmpDir))
+	require.NoError(tn.t, os.WriteFile(tmpDir, []byte{}, 0644))
+}
+
+func (tn *TestNode) RepairStore() {
+	tmpDir := filepath.Join(tn.StorePath, "tmp")
+	require.NoError(tn.t, os.Remove(tmpDir))
+	require.NoError(tn.t, os.MkdirAll(tmpDir, 0755))
+}
@@ -0,0 +1,63 @@
+package itests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/SaoNetwork/sao-node/itests/kit"
+	"github.com/SaoNetwork/sao-node/node/config"
+	"github.com/SaoNetwork/sao-node/types"
+
+	ordertypes "github.com/SaoNetwork/sao/x/order/types"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModelMigrate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping multi-node test in short mode")
+	}
+
+	ens := kit.NewEnsemble(t, kit.DefaultBlocktime)
+	ctx := ens.Context()
+
+	gateway := ens.Gateway(func(cfg *config.Node) {
+		cfg.Cache.EnableCache = false
+	})
+	ens.Storage()
+	ens.Storage()
+
+	client := ens.Client()
+	created := client.CreateModel(ctx, gateway, "itest-migrate", []byte(`{"name": "migrate"}`), 1)
+	orderInfo := gateway.WaitOrderState(ctx, created.DataId, types.OrderStateComplete)
+	require.Len(t, orderInfo.Shards, 1)
+
+	var from *kit.TestNode
+	for address := range orderInfo.Shards {
+		from = ens.Node(address)
+	}
+	shardCid, err := cid.Decode(created.Cid)
+	require.NoError(t, err)
+	from.WaitShardState(ctx, orderInfo.OrderId, shardCid, types.ShardStateComplete)
+
+	resp, err := from.ModelMigrate(ctx, []string{created.DataId})
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(resp.Results[created.DataId], "SUCCESS"), resp.Results[created.DataId])
+
+	migrated := from.WaitMigrateState(ctx, created.DataId, types.MigrateStateComplete)
+	require.NotEqual(t, from.Address, migrated.ToProvider)
+	require.NotEmpty(t, migrated.CompleteTxHash)
+
+	order, err := ens.Chain.GetOrder(ctx, orderInfo.OrderId)
+	require.NoError(t, err)
+	require.NotContains(t, order.Shards, from.Address)
+	shard, exists := order.Shards[migrated.ToProvider]
+	require.True(t, exists)
+	require.Equal(t, int32(ordertypes.ShardCompleted), shard.Status)
+	require.Equal(t, from.Address, shard.From)
+
+	// the content is served by the new provider
+	loaded, err := client.LoadModel(ctx, gateway, created.DataId)
+	require.NoError(t, err)
+	require.Equal(t, created.Cid, loaded.Cid)
+}
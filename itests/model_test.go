@@ -0,0 +1,165 @@
+package itests
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SaoNetwork/sao-node/itests/kit"
+	"github.com/SaoNetwork/sao-node/node/config"
+	"github.com/SaoNetwork/sao-node/types"
+	"github.com/SaoNetwork/sao-node/utils"
+
+	ordertypes "github.com/SaoNetwork/sao/x/order/types"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModelCreateLoad(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping multi-node test in short mode")
+	}
+
+	ens := kit.NewEnsemble(t, kit.DefaultBlocktime)
+	ctx := ens.Context()
+
+	gateway := ens.Gateway()
+	sps := []*kit.TestNode{ens.Storage(), ens.Storage()}
+	// a second gateway without the model cached has to fetch it from the storage nodes
+	reader := ens.Gateway(func(cfg *config.Node) {
+		cfg.Cache.EnableCache = false
+	})
+
+	client := ens.Client()
+	content := []byte(`{"name": "itest", "value": 1}`)
+	created := client.CreateModel(ctx, gateway, "itest-model", content, 2)
+
+	orderInfo := gateway.WaitOrderState(ctx, created.DataId, types.OrderStateComplete)
+	require.Len(t, orderInfo.Shards, 2)
+
+	shardCid, err := cid.Decode(created.Cid)
+	require.NoError(t, err)
+	for _, sp := range sps {
+		require.Contains(t, orderInfo.Shards, sp.Address)
+		require.Equal(t, types.ShardStateCompleted, orderInfo.Shards[sp.Address].State)
+
+		shardInfo := sp.WaitShardState(ctx, orderInfo.OrderId, shardCid, types.ShardStateComplete)
+		require.Equal(t, gateway.Address, shardInfo.Gateway)
+		require.Equal(t, uint64(len(content)), shardInfo.Size)
+	}
+
+	order, err := ens.Chain.GetOrder(ctx, orderInfo.OrderId)
+	require.NoError(t, err)
+	require.Equal(t, int32(ordertypes.OrderCompleted), order.Status)
+
+	// the gateway recorded the order passing through its states
+	events, err := gateway.OrderHistory(ctx, created.DataId, orderInfo.OrderId)
+	require.NoError(t, err)
+	var states []string
+	for _, event := range events {
+		if event.Kind == types.OrderEventOrder && event.From != event.To {
+			states = append(states, event.To)
+		}
+	}
+	require.Equal(t, []string{"Staged", "Ready", "Complete"}, states)
+
+	// a completed order can't be fixed or cancelled any more
+	require.True(t, types.ErrInvalidOrderState.Is(gateway.OrderFix(ctx, created.DataId)))
+	require.True(t, types.ErrInvalidOrderState.Is(gateway.OrderCancel(ctx, created.DataId)))
+	require.True(t, types.ErrInvalidShardState.Is(sps[0].ShardFix(ctx, orderInfo.OrderId, shardCid)))
+
+	for _, gw := range []*kit.TestNode{gateway, reader} {
+		loaded, err := client.LoadModel(ctx, gw, created.DataId)
+		require.NoError(t, err)
+		require.Equal(t, created.DataId, loaded.DataId)
+		require.Equal(t, created.Cid, loaded.Cid)
+		require.Equal(t, "v0", loaded.Version)
+		require.Equal(t, content, loaded.Content)
+	}
+}
+
+func TestShardTaskFromChain(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping multi-node test in short mode")
+	}
+
+	ens := kit.NewEnsemble(t, kit.DefaultBlocktime)
+	ctx := ens.Context()
+
+	gateway := ens.Gateway()
+	sp := ens.Storage()
+
+	// the gateway can't reach the storage node, its shard assign push fails
+	pi, err := sp.NetAddrsListen(ctx)
+	require.NoError(t, err)
+	require.NoError(t, gateway.NetDisconnect(ctx, pi.ID))
+
+	client := ens.Client()
+	content := []byte(`{"name": "itest", "value": 2}`)
+	created := client.CreateModel(ctx, gateway, "itest-shard-task", content, 1)
+
+	// the storage node picks the shard up from the new-shard event and fetches it from the gateway
+	orderInfo := gateway.WaitOrderState(ctx, created.DataId, types.OrderStateComplete)
+	require.Equal(t, types.ShardStateCompleted, orderInfo.Shards[sp.Address].State)
+
+	shardCid, err := cid.Decode(created.Cid)
+	require.NoError(t, err)
+	shardInfo := sp.WaitShardState(ctx, orderInfo.OrderId, shardCid, types.ShardStateComplete)
+	require.Equal(t, gateway.Address, shardInfo.Gateway)
+	require.Equal(t, created.DataId, shardInfo.DataId)
+}
+
+func TestModelErasureCoded(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping multi-node test in short mode")
+	}
+
+	ens := kit.NewEnsemble(t, kit.DefaultBlocktime)
+	ctx := ens.Context()
+
+	gateway := ens.Gateway(func(cfg *config.Node) {
+		cfg.Gateway.ErasureDataShards = 2
+	})
+	sps := []*kit.TestNode{ens.Storage(), ens.Storage(), ens.Storage()}
+	reader := ens.Gateway(func(cfg *config.Node) {
+		cfg.Cache.EnableCache = false
+	})
+
+	client := ens.Client()
+	content := []byte(`{"name": "itest", "value": "` + strings.Repeat("erasure", 100) + `"}`)
+	created := client.CreateModel(ctx, gateway, "itest-erasure", content, 3)
+
+	orderInfo := gateway.WaitOrderState(ctx, created.DataId, types.OrderStateComplete)
+	require.Len(t, orderInfo.Shards, 3)
+	for _, shard := range orderInfo.Shards {
+		require.NotEqual(t, created.Cid, shard.Cid)
+	}
+
+	loaded, err := client.LoadModel(ctx, reader, created.DataId)
+	require.NoError(t, err)
+	require.Equal(t, content, loaded.Content)
+
+	// a provider serving a corrupted shard with a valid header is skipped, the other two shards restore the content
+	err = filepath.WalkDir(sps[0].StorePath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		shard, err := os.ReadFile(path)
+		if err != nil || !utils.IsErasureShard(shard) {
+			return err
+		}
+		copy(shard[utils.ErasureShardHeaderSize:], bytes.Repeat([]byte{'x'}, len(shard)))
+		return os.WriteFile(path, shard, 0644)
+	})
+	require.NoError(t, err)
+
+	// shards are picked in random order, load a few times so the corrupted one is fetched
+	for i := 0; i < 5; i++ {
+		loaded, err = client.LoadModel(ctx, reader, created.DataId)
+		require.NoError(t, err)
+		require.Equal(t, content, loaded.Content)
+	}
+}
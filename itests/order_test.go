@@ -0,0 +1,59 @@
+package itests
+
+import (
+	"testing"
+
+	"github.com/SaoNetwork/sao-node/itests/kit"
+	"github.com/SaoNetwork/sao-node/node/config"
+	"github.com/SaoNetwork/sao-node/types"
+	"github.com/SaoNetwork/sao-node/utils"
+
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderControls(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping multi-node test in short mode")
+	}
+
+	ens := kit.NewEnsemble(t, kit.DefaultBlocktime)
+	ctx := ens.Context()
+
+	gateway := ens.Gateway()
+	// a failed shard terminates at once and is left to the operator
+	sp := ens.Storage(func(cfg *config.Node) {
+		cfg.Retry.Default = config.RetryPolicy{Kind: utils.RetryNone}
+	})
+
+	client := ens.Client()
+	sp.BreakStore()
+	created := client.CreateModel(ctx, gateway, "itest-fix", []byte(`{"name": "fix"}`), 1)
+	orderInfo := gateway.WaitOrderState(ctx, created.DataId, types.OrderStateReady)
+	shardCid, err := cid.Decode(created.Cid)
+	require.NoError(t, err)
+	shardInfo := sp.WaitShardState(ctx, orderInfo.OrderId, shardCid, types.ShardStateTerminate)
+	require.NotEmpty(t, shardInfo.LastErr)
+
+	// the shard can only be handed over to a provider holding a waiting shard of the order on chain
+	require.True(t, types.ErrInvalidProvider.Is(gateway.OrderReassign(ctx, created.DataId, sp.Address, gateway.Address)))
+	require.True(t, types.ErrInvalidProvider.Is(gateway.OrderReassign(ctx, created.DataId, gateway.Address, sp.Address)))
+
+	// the order waits for the shard, it's still in flight on the gateway and on chain
+	require.NoError(t, gateway.OrderFix(ctx, created.DataId))
+	fixed, err := gateway.OrderStatus(ctx, created.DataId)
+	require.NoError(t, err)
+	require.Equal(t, types.OrderStateReady, fixed.State)
+
+	sp.RepairStore()
+	require.NoError(t, sp.ShardFix(ctx, orderInfo.OrderId, shardCid))
+	sp.WaitShardState(ctx, orderInfo.OrderId, shardCid, types.ShardStateComplete)
+	gateway.WaitOrderState(ctx, created.DataId, types.OrderStateComplete)
+
+	sp.BreakStore()
+	cancelled := client.CreateModel(ctx, gateway, "itest-cancel", []byte(`{"name": "cancel"}`), 1)
+	gateway.WaitOrderState(ctx, cancelled.DataId, types.OrderStateReady)
+	require.NoError(t, gateway.OrderCancel(ctx, cancelled.DataId))
+	gateway.WaitOrderState(ctx, cancelled.DataId, types.OrderStateCancelled)
+	require.True(t, types.ErrInvalidOrderState.Is(gateway.OrderFix(ctx, cancelled.DataId)))
+}
@@ -0,0 +1,108 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "sao"
+
+// metrics shared by all nodes of the process, node specific ones are added to the handler of each node.
+var registry = prometheus.NewRegistry()
+
+var factory = promauto.With(registry)
+
+var (
+	OrderRetries = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "gateway",
+		Name:      "order_retries_total",
+		Help:      "Number of order processing retries.",
+	})
+
+	ShardRetries = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "storage",
+		Name:      "shard_retries_total",
+		Help:      "Number of shard processing retries.",
+	})
+
+	ShardLoadDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "storage",
+		Name:      "shard_load_duration_seconds",
+		Help:      "Latency of shard load requests served by the storage node.",
+		Buckets:   prometheus.ExponentialBuckets(0.005, 4, 8),
+	}, []string{"code"})
+
+	StoredBytes = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "store",
+		Name:      "stored_bytes_total",
+		Help:      "Bytes stored per store backend.",
+	}, []string{"backend", "type"})
+
+	CacheRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "cache",
+		Name:      "requests_total",
+		Help:      "Cache lookups by result, the hit ratio is hit / (hit + miss).",
+	}, []string{"cache", "result"})
+
+	BroadcastQueueDepth = factory.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "chain",
+		Name:      "broadcast_queue_depth",
+		Help:      "Messages waiting to be broadcast per signer.",
+	}, []string{"signer"})
+
+	UploadBytes = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "transport",
+		Name:      "upload_bytes_total",
+		Help:      "Bytes of content chunks uploaded to this node.",
+	})
+
+	UploadChunks = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "transport",
+		Name:      "upload_chunks_total",
+		Help:      "Content chunks uploaded to this node.",
+	})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+/**
+ * the /metrics handler of a node, serving the process wide metrics together with the given node collectors.
+ */
+func Handler(nodeCollectors ...prometheus.Collector) http.Handler {
+	nodeRegistry := prometheus.NewRegistry()
+	nodeRegistry.MustRegister(nodeCollectors...)
+	return promhttp.HandlerFor(prometheus.Gatherers{registry, nodeRegistry}, promhttp.HandlerOpts{})
+}
+
+/**
+ * CountingReader counts the bytes read through it, the count is added to a counter by the caller once the
+ * read content is known to be used.
+ */
+type CountingReader struct {
+	io.Reader
+	N int64
+}
+
+func (r *CountingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.N += int64(n)
+	return n, err
+}
@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+)
+
+func TestStateCollector(t *testing.T) {
+	collector := NewStateCollector("storage", "shards", "Number of shards per state.", func() (map[string]int, error) {
+		return map[string]int{"stored": 2, "completed": 0}, nil
+	})
+
+	expected := `
+# HELP sao_storage_shards Number of shards per state.
+# TYPE sao_storage_shards gauge
+sao_storage_shards{state="completed"} 0
+sao_storage_shards{state="stored"} 2
+`
+	require.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected)))
+
+	failing := NewStateCollector("storage", "shards", "Number of shards per state.", func() (map[string]int, error) {
+		return nil, xerrors.New("datastore closed")
+	})
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(failing)
+	_, err := registry.Gather()
+	require.Error(t, err)
+}
+
+func TestCountingReader(t *testing.T) {
+	reader := &CountingReader{Reader: bytes.NewReader(make([]byte, 1000))}
+
+	n, err := io.Copy(io.Discard, reader)
+	require.NoError(t, err)
+	require.Equal(t, int64(1000), n)
+	require.Equal(t, int64(1000), reader.N)
+}
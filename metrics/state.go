@@ -0,0 +1,35 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+/**
+ * StateCollector reports the number of items per state, the states are counted when the metrics are scraped.
+ */
+type StateCollector struct {
+	desc  *prometheus.Desc
+	count func() (map[string]int, error)
+}
+
+func NewStateCollector(subsystem string, name string, help string, count func() (map[string]int, error)) *StateCollector {
+	return &StateCollector{
+		desc:  prometheus.NewDesc(prometheus.BuildFQName(namespace, subsystem, name), help, []string{"state"}, nil),
+		count: count,
+	}
+}
+
+func (c *StateCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *StateCollector) Collect(ch chan<- prometheus.Metric) {
+	counts, err := c.count()
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.desc, err)
+		return
+	}
+	for state, count := range counts {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(count), state)
+	}
+}
@@ -0,0 +1,30 @@
+package cache
+
+import (
+	"github.com/SaoNetwork/sao-node/metrics"
+)
+
+/**
+ * MetricsCacheSvc counts the hits and misses of the lookups to the underlying cache service.
+ */
+type MetricsCacheSvc struct {
+	CacheSvcApi
+	cacheType string
+}
+
+func NewMetricsCacheSvc(svc CacheSvcApi, cacheType string) *MetricsCacheSvc {
+	return &MetricsCacheSvc{
+		CacheSvcApi: svc,
+		cacheType:   cacheType,
+	}
+}
+
+func (svc *MetricsCacheSvc) Get(name string, key string) (interface{}, error) {
+	value, err := svc.CacheSvcApi.Get(name, key)
+	if err == nil && value != nil {
+		metrics.CacheRequests.WithLabelValues(svc.cacheType, "hit").Inc()
+	} else {
+		metrics.CacheRequests.WithLabelValues(svc.cacheType, "miss").Inc()
+	}
+	return value, err
+}
@@ -23,9 +23,41 @@ func DefaultSaoNode() *Node {
 			EnableHttpFileServerLog: false,
 			TokenPeriod:             24 * time.Hour,
 		},
+		Gateway: Gateway{
+			ErasureDataShards: 0,
+			Scheduler: Scheduler{
+				TenantKey:         "owner",
+				Concurrency:       10,
+				TenantWeight:      1,
+				TenantConcurrency: 0,
+				Tenants:           []Tenant{},
+			},
+		},
 		Storage: Storage{
 			AcceptOrder: true,
 			Ipfs:        []Ipfs{},
+			Local:       []Local{},
+			S3:          []S3{},
+			Replication: Replication{
+				Policy:             "write-all",
+				Quorum:             0,
+				FailureThreshold:   3,
+				CircuitBreakPeriod: time.Minute,
+				RepairInterval:     time.Hour,
+			},
+			Capacity:          0,
+			ReconcileInterval: time.Hour,
+			AutoReconcile:     false,
+		},
+		Fisherman: Fisherman{
+			AuditTimeout:      time.Minute,
+			CommitmentTimeout: 30 * time.Minute,
+			ChallengeLeaves:   8,
+			LeafSize:          4096,
+
+			AuditInterval:    10 * time.Minute,
+			AuditSampleSize:  20,
+			AuditConcurrency: 4,
 		},
 		SaoIpfs: SaoIpfs{
 			Enable: true,
@@ -34,6 +66,50 @@ func DefaultSaoNode() *Node {
 			DbPath:        "~/.sao-node/datastore",
 			ListenAddress: "localhost:5155",
 		},
+		Gc: Gc{
+			Interval:        time.Hour,
+			GracePeriod:     24 * time.Hour,
+			CacheExpiration: 24 * time.Hour,
+		},
+		Tracing: Tracing{
+			Enable:      false,
+			Endpoint:    "localhost:4318",
+			Insecure:    true,
+			SampleRatio: 1,
+		},
+		Retry: Retry{
+			Default: RetryPolicy{
+				Kind:        "exponential",
+				MaxRetries:  3,
+				Interval:    3 * time.Second,
+				MaxInterval: 10 * time.Minute,
+				Multiplier:  3,
+				Jitter:      0.2,
+			},
+			Chain: RetryPolicy{
+				Kind:        "exponential",
+				MaxRetries:  5,
+				Interval:    3 * time.Second,
+				MaxInterval: 5 * time.Minute,
+				Multiplier:  2,
+				Jitter:      0.2,
+			},
+			Connect: RetryPolicy{
+				Kind:        "exponential",
+				MaxRetries:  5,
+				Interval:    3 * time.Second,
+				MaxInterval: 5 * time.Minute,
+				Multiplier:  2,
+				Jitter:      0.2,
+			},
+			Network: RetryPolicy{
+				Kind:       "immediate",
+				MaxRetries: 5,
+			},
+			InvalidCid: RetryPolicy{
+				Kind: "none",
+			},
+		},
 	}
 }
 
@@ -57,9 +133,10 @@ func defCommon() Common {
 			StagingSapceSize: 32 * 1024 * 1024 * 1024,
 		},
 		Module: Module{
-			GatewayEnable: false,
-			StorageEnable: true,
-			IndexerEnable: false,
+			GatewayEnable:   false,
+			StorageEnable:   true,
+			IndexerEnable:   false,
+			FishermanEnable: false,
 		},
 	}
 }
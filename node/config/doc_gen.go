@@ -78,7 +78,7 @@ var Doc = map[string][]DocField{
 			Name: "Remote",
 			Type: "string",
 
-			Comment: `remote connection string`,
+			Comment: `remote connection string, mock://<name>[?blocktime=<duration>] runs an in-memory chain shared by nodes in the same process`,
 		},
 		{
 			Name: "WsEndpoint",
@@ -87,10 +87,10 @@ var Doc = map[string][]DocField{
 			Comment: `websocket endpoint`,
 		},
 		{
-			Name: "AddressPrefix",
-			Type: "string",
+			Name: "TxPoolSize",
+			Type: "uint",
 
-			Comment: `sao chain account prefix`,
+			Comment: `tx address pool size`,
 		},
 	},
 	"Common": []DocField{
@@ -119,6 +119,100 @@ var Doc = map[string][]DocField{
 			Comment: ``,
 		},
 	},
+	"Fisherman": []DocField{
+		{
+			Name: "AuditTimeout",
+			Type: "time.Duration",
+
+			Comment: `timeout of auditing a single shard`,
+		},
+		{
+			Name: "CommitmentTimeout",
+			Type: "time.Duration",
+
+			Comment: `timeout of downloading a shard to build its merkle commitment before the first audit of it`,
+		},
+		{
+			Name: "ChallengeLeaves",
+			Type: "int",
+
+			Comment: `number of random merkle leaves challenged in each audit`,
+		},
+		{
+			Name: "LeafSize",
+			Type: "uint64",
+
+			Comment: `merkle leaf size in bytes of shard commitments`,
+		},
+		{
+			Name: "AuditInterval",
+			Type: "time.Duration",
+
+			Comment: `interval of scheduled audit rounds`,
+		},
+		{
+			Name: "AuditSampleSize",
+			Type: "uint64",
+
+			Comment: `number of metadata sampled from chain in each audit round`,
+		},
+		{
+			Name: "AuditConcurrency",
+			Type: "int",
+
+			Comment: `maximum shards audited at the same time`,
+		},
+	},
+	"Gateway": []DocField{
+		{
+			Name: "ErasureDataShards",
+			Type: "int",
+
+			Comment: `number of data shards when erasure coding order content, 0 means plain replicas.
+content is only encoded when chain assigns more shards than this number,
+and the remaining shards carry reed-solomon parity.`,
+		},
+		{
+			Name: "Scheduler",
+			Type: "Scheduler",
+
+			Comment: ``,
+		},
+	},
+	"Gc": []DocField{
+		{
+			Name: "Interval",
+			Type: "time.Duration",
+
+			Comment: `interval of scheduled garbage collection, 0 disables it`,
+		},
+		{
+			Name: "GracePeriod",
+			Type: "time.Duration",
+
+			Comment: `files without an order referencing them are kept for this period, they may be in use by a running upload`,
+		},
+		{
+			Name: "CacheExpiration",
+			Type: "time.Duration",
+
+			Comment: `files cached for the http file server are removed after this period, 0 keeps them`,
+		},
+	},
+	"Indexer": []DocField{
+		{
+			Name: "DbPath",
+			Type: "string",
+
+			Comment: `indexer db path`,
+		},
+		{
+			Name: "ListenAddress",
+			Type: "string",
+
+			Comment: `Binding address for the graphsql service`,
+		},
+	},
 	"Ipfs": []DocField{
 		{
 			Name: "Conn",
@@ -135,6 +229,20 @@ var Doc = map[string][]DocField{
 			Comment: `Binding address for the libp2p host - 0 means random port.
 Format: multiaddress; see https://multiformats.io/multiaddr/`,
 		},
+		{
+			Name: "AnnounceAddresses",
+			Type: "[]string",
+
+			Comment: ``,
+		},
+	},
+	"Local": []DocField{
+		{
+			Name: "Path",
+			Type: "string",
+
+			Comment: `root directory of the content addressed blocks`,
+		},
 	},
 	"Module": []DocField{
 		{
@@ -149,6 +257,18 @@ Format: multiaddress; see https://multiformats.io/multiaddr/`,
 
 			Comment: `Enable storage module`,
 		},
+		{
+			Name: "IndexerEnable",
+			Type: "bool",
+
+			Comment: `Enable indexer module`,
+		},
+		{
+			Name: "FishermanEnable",
+			Type: "bool",
+
+			Comment: `Enable scheduled shard audits of fisherman, requires gateway module`,
+		},
 	},
 	"Node": []DocField{
 		{
@@ -169,18 +289,210 @@ Format: multiaddress; see https://multiformats.io/multiaddr/`,
 
 			Comment: ``,
 		},
+		{
+			Name: "Gateway",
+			Type: "Gateway",
+
+			Comment: ``,
+		},
 		{
 			Name: "Storage",
 			Type: "Storage",
 
 			Comment: ``,
 		},
+		{
+			Name: "Fisherman",
+			Type: "Fisherman",
+
+			Comment: ``,
+		},
 		{
 			Name: "SaoIpfs",
 			Type: "SaoIpfs",
 
 			Comment: ``,
 		},
+		{
+			Name: "Indexer",
+			Type: "Indexer",
+
+			Comment: ``,
+		},
+		{
+			Name: "Gc",
+			Type: "Gc",
+
+			Comment: ``,
+		},
+		{
+			Name: "Tracing",
+			Type: "Tracing",
+
+			Comment: ``,
+		},
+		{
+			Name: "Retry",
+			Type: "Retry",
+
+			Comment: ``,
+		},
+	},
+	"Replication": []DocField{
+		{
+			Name: "Policy",
+			Type: "string",
+
+			Comment: `write-all: a shard is stored only if all backends succeed
+write-quorum: a shard is stored if at least Quorum backends succeed
+primary-mirror: a shard is stored once the first backend succeeds, other backends are written asynchronously`,
+		},
+		{
+			Name: "Quorum",
+			Type: "int",
+
+			Comment: `minimum successful backends in write-quorum policy, between 1 and the number of backends`,
+		},
+		{
+			Name: "FailureThreshold",
+			Type: "int",
+
+			Comment: `consecutive failures before a backend is skipped`,
+		},
+		{
+			Name: "CircuitBreakPeriod",
+			Type: "time.Duration",
+
+			Comment: `how long a failing backend is skipped before it's tried again`,
+		},
+		{
+			Name: "RepairInterval",
+			Type: "time.Duration",
+
+			Comment: `interval of copying shards missing from some backends, 0 disables repairing`,
+		},
+	},
+	"Retry": []DocField{
+		{
+			Name: "Default",
+			Type: "RetryPolicy",
+
+			Comment: `errors not matching any class below`,
+		},
+		{
+			Name: "Chain",
+			Type: "RetryPolicy",
+
+			Comment: `chain queries and transactions`,
+		},
+		{
+			Name: "Connect",
+			Type: "RetryPolicy",
+
+			Comment: `connecting or opening a stream to other nodes, the node may be down for a while`,
+		},
+		{
+			Name: "Network",
+			Type: "RetryPolicy",
+
+			Comment: `streams to other nodes broken in the middle, and failures responded by them`,
+		},
+		{
+			Name: "InvalidCid",
+			Type: "RetryPolicy",
+
+			Comment: `content not matching the cid, retrying usually doesn't help`,
+		},
+	},
+	"RetryPolicy": []DocField{
+		{
+			Name: "Kind",
+			Type: "string",
+
+			Comment: `exponential: wait Interval * Multiplier^(tries-1) before the next attempt, capped at MaxInterval
+immediate: retry at once
+none: give up after the first failure`,
+		},
+		{
+			Name: "MaxRetries",
+			Type: "uint64",
+
+			Comment: `retries after the first attempt before giving up, so an order or shard is tried MaxRetries+1 times`,
+		},
+		{
+			Name: "Interval",
+			Type: "time.Duration",
+
+			Comment: ``,
+		},
+		{
+			Name: "MaxInterval",
+			Type: "time.Duration",
+
+			Comment: ``,
+		},
+		{
+			Name: "Multiplier",
+			Type: "float64",
+
+			Comment: ``,
+		},
+		{
+			Name: "Jitter",
+			Type: "float64",
+
+			Comment: `fraction of the interval randomly added or removed, so that failed orders don't retry at the same time`,
+		},
+	},
+	"S3": []DocField{
+		{
+			Name: "Endpoint",
+			Type: "string",
+
+			Comment: `endpoint of the S3 compatible service, empty for AWS S3`,
+		},
+		{
+			Name: "Region",
+			Type: "string",
+
+			Comment: `region of the bucket, us-east-1 if empty`,
+		},
+		{
+			Name: "Bucket",
+			Type: "string",
+
+			Comment: `bucket to store shard objects`,
+		},
+		{
+			Name: "Prefix",
+			Type: "string",
+
+			Comment: `key prefix of shard objects in the bucket`,
+		},
+		{
+			Name: "AccessKeyId",
+			Type: "string",
+
+			Comment: `credentials, the default AWS credential chain is used if AccessKeyId is empty`,
+		},
+		{
+			Name: "SecretAccessKey",
+			Type: "string",
+
+			Comment: ``,
+		},
+		{
+			Name: "PathStyle",
+			Type: "bool",
+
+			Comment: `use path style addressing, required by MinIO`,
+		},
+		{
+			Name: "PartSize",
+			Type: "int64",
+
+			Comment: `part size in bytes of multipart uploads, content larger than that is uploaded in parts, 5MiB if 0`,
+		},
 	},
 	"SaoHttpFileServer": []DocField{
 		{
@@ -196,14 +508,14 @@ Format: multiaddress; see https://multiformats.io/multiaddr/`,
 			Comment: ``,
 		},
 		{
-			Name: "HttpFileServerPath",
-			Type: "string",
+			Name: "EnableHttpFileServerLog",
+			Type: "bool",
 
 			Comment: ``,
 		},
 		{
-			Name: "EnableHttpFileServerLog",
-			Type: "bool",
+			Name: "HttpFileServerPath",
+			Type: "string",
 
 			Comment: ``,
 		},
@@ -211,6 +523,12 @@ Format: multiaddress; see https://multiformats.io/multiaddr/`,
 			Name: "TokenPeriod",
 			Type: "time.Duration",
 
+			Comment: ``,
+		},
+		{
+			Name: "CacheSize",
+			Type: "int",
+
 			Comment: ``,
 		},
 	},
@@ -221,11 +539,38 @@ Format: multiaddress; see https://multiformats.io/multiaddr/`,
 
 			Comment: `Enable in process ipfs instance`,
 		},
+	},
+	"Scheduler": []DocField{
 		{
-			Name: "Repo",
+			Name: "TenantKey",
 			Type: "string",
 
-			Comment: `ipfs repo path`,
+			Comment: `owner: orders are grouped into tenants by the owner DID
+platform: orders are grouped by the group id of the data model, or by the owner if it has no group`,
+		},
+		{
+			Name: "Concurrency",
+			Type: "int",
+
+			Comment: `orders processed at the same time`,
+		},
+		{
+			Name: "TenantWeight",
+			Type: "int",
+
+			Comment: `weight of tenants not listed in Tenants, a tenant with weight 2 is served twice as often as one with weight 1`,
+		},
+		{
+			Name: "TenantConcurrency",
+			Type: "int",
+
+			Comment: `orders of a tenant not listed in Tenants processed at the same time, 0 means no cap`,
+		},
+		{
+			Name: "Tenants",
+			Type: "[]Tenant",
+
+			Comment: ``,
 		},
 	},
 	"Storage": []DocField{
@@ -241,18 +586,95 @@ Format: multiaddress; see https://multiformats.io/multiaddr/`,
 
 			Comment: ``,
 		},
+		{
+			Name: "Local",
+			Type: "[]Local",
+
+			Comment: ``,
+		},
+		{
+			Name: "S3",
+			Type: "[]S3",
+
+			Comment: ``,
+		},
+		{
+			Name: "Replication",
+			Type: "Replication",
+
+			Comment: ``,
+		},
+		{
+			Name: "Capacity",
+			Type: "uint64",
+
+			Comment: `maximum bytes of shards accepted by this node, 0 means the vstorage declared on chain`,
+		},
+		{
+			Name: "ReconcileInterval",
+			Type: "time.Duration",
+
+			Comment: `interval of comparing the declared vstorage on chain with the actual usage, 0 disables reconciling`,
+		},
+		{
+			Name: "AutoReconcile",
+			Type: "bool",
+
+			Comment: `adjust the declared vstorage on chain to Capacity, or to the backends capacity if Capacity is 0,
+it never goes below the used bytes`,
+		},
 	},
-	"Transport": []DocField{
+	"Tenant": []DocField{
 		{
-			Name: "TransportListenAddress",
-			Type: "[]string",
+			Name: "Key",
+			Type: "string",
+
+			Comment: `owner DID or group id, depending on the TenantKey`,
+		},
+		{
+			Name: "Weight",
+			Type: "int",
 
 			Comment: ``,
 		},
 		{
-			Name: "StagingPath",
+			Name: "Concurrency",
+			Type: "int",
+
+			Comment: `0 means no cap`,
+		},
+	},
+	"Tracing": []DocField{
+		{
+			Name: "Enable",
+			Type: "bool",
+
+			Comment: `export spans of this node to the collector`,
+		},
+		{
+			Name: "Endpoint",
 			Type: "string",
 
+			Comment: `OTLP/HTTP endpoint of the collector, host:port`,
+		},
+		{
+			Name: "Insecure",
+			Type: "bool",
+
+			Comment: `connect to the collector over plain http`,
+		},
+		{
+			Name: "SampleRatio",
+			Type: "float64",
+
+			Comment: `fraction of traces started by this node that are sampled, traces from other nodes keep their sampling decision`,
+		},
+	},
+	"Transport": []DocField{
+		{
+			Name: "TransportListenAddress",
+			Type: "[]string",
+
 			Comment: ``,
 		},
 		{
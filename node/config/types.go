@@ -16,9 +16,14 @@ type Node struct {
 	SaoHttpFileServer SaoHttpFileServer
 	Api               API
 
-	Storage Storage
-	SaoIpfs SaoIpfs
-	Indexer Indexer
+	Gateway   Gateway
+	Storage   Storage
+	Fisherman Fisherman
+	SaoIpfs   SaoIpfs
+	Indexer   Indexer
+	Gc        Gc
+	Tracing   Tracing
+	Retry     Retry
 }
 
 type SaoHttpFileServer struct {
@@ -36,12 +41,181 @@ type SaoIpfs struct {
 	Enable bool
 }
 
+// Gateway contains configs for gateway module
+type Gateway struct {
+
+	// number of data shards when erasure coding order content, 0 means plain replicas.
+	// content is only encoded when chain assigns more shards than this number,
+	// and the remaining shards carry reed-solomon parity.
+	ErasureDataShards int
+
+	Scheduler Scheduler
+}
+
+// Scheduler contains configs of sharing the order processing of the gateway among tenants
+type Scheduler struct {
+
+	// owner: orders are grouped into tenants by the owner DID
+	// platform: orders are grouped by the group id of the data model, or by the owner if it has no group
+	TenantKey string
+
+	// orders processed at the same time
+	Concurrency int
+
+	// weight of tenants not listed in Tenants, a tenant with weight 2 is served twice as often as one with weight 1
+	TenantWeight int
+
+	// orders of a tenant not listed in Tenants processed at the same time, 0 means no cap
+	TenantConcurrency int
+
+	Tenants []Tenant
+}
+
+// Tenant contains scheduling configs of a single tenant
+type Tenant struct {
+
+	// owner DID or group id, depending on the TenantKey
+	Key string
+
+	Weight int
+
+	// 0 means no cap
+	Concurrency int
+}
+
+// Fisherman contains configs for auditing shards stored by other nodes
+type Fisherman struct {
+
+	// timeout of auditing a single shard
+	AuditTimeout time.Duration
+
+	// timeout of downloading a shard to build its merkle commitment before the first audit of it
+	CommitmentTimeout time.Duration
+
+	// number of random merkle leaves challenged in each audit
+	ChallengeLeaves int
+
+	// merkle leaf size in bytes of shard commitments
+	LeafSize uint64
+
+	// interval of scheduled audit rounds
+	AuditInterval time.Duration
+
+	// number of metadata sampled from chain in each audit round
+	AuditSampleSize uint64
+
+	// maximum shards audited at the same time
+	AuditConcurrency int
+}
+
+// Gc contains configs for removing stale staging and cache files
+type Gc struct {
+
+	// interval of scheduled garbage collection, 0 disables it
+	Interval time.Duration
+
+	// files without an order referencing them are kept for this period, they may be in use by a running upload
+	GracePeriod time.Duration
+
+	// files cached for the http file server are removed after this period, 0 keeps them
+	CacheExpiration time.Duration
+}
+
+// Tracing contains configs for exporting OpenTelemetry spans
+type Tracing struct {
+
+	// export spans of this node to the collector
+	Enable bool
+
+	// OTLP/HTTP endpoint of the collector, host:port
+	Endpoint string
+
+	// connect to the collector over plain http
+	Insecure bool
+
+	// fraction of traces started by this node that are sampled, traces from other nodes keep their sampling decision
+	SampleRatio float64
+}
+
+// Retry contains policies of retrying failed orders of the gateway and shards of the storage node by the error
+type Retry struct {
+
+	// errors not matching any class below
+	Default RetryPolicy
+
+	// chain queries and transactions
+	Chain RetryPolicy
+
+	// connecting or opening a stream to other nodes, the node may be down for a while
+	Connect RetryPolicy
+
+	// streams to other nodes broken in the middle, and failures responded by them
+	Network RetryPolicy
+
+	// content not matching the cid, retrying usually doesn't help
+	InvalidCid RetryPolicy
+}
+
+// RetryPolicy contains how a failed order or shard is retried
+type RetryPolicy struct {
+
+	// exponential: wait Interval * Multiplier^(tries-1) before the next attempt, capped at MaxInterval
+	// immediate: retry at once
+	// none: give up after the first failure
+	Kind string
+
+	// retries after the first attempt before giving up, so an order or shard is tried MaxRetries+1 times
+	MaxRetries uint64
+
+	Interval    time.Duration
+	MaxInterval time.Duration
+	Multiplier  float64
+
+	// fraction of the interval randomly added or removed, so that failed orders don't retry at the same time
+	Jitter float64
+}
+
 // Storage contains configs for backend storages
 type Storage struct {
 
 	// if this node is open to accept order shards
 	AcceptOrder bool
 	Ipfs        []Ipfs
+	Local       []Local
+	S3          []S3
+
+	Replication Replication
+
+	// maximum bytes of shards accepted by this node, 0 means the vstorage declared on chain
+	Capacity uint64
+
+	// interval of comparing the declared vstorage on chain with the actual usage, 0 disables reconciling
+	ReconcileInterval time.Duration
+
+	// adjust the declared vstorage on chain to Capacity, or to the backends capacity if Capacity is 0,
+	// it never goes below the used bytes
+	AutoReconcile bool
+}
+
+// Replication contains policies of storing shards into multiple backends
+type Replication struct {
+
+	// write-all: a shard is stored only if all backends succeed
+	// write-quorum: a shard is stored if at least Quorum backends succeed
+	// primary-mirror: a shard is stored once the first backend succeeds, other backends are written asynchronously
+	Policy string
+
+	// minimum successful backends in write-quorum policy, between 1 and the number of backends
+	Quorum int
+
+	// consecutive failures before a backend is skipped
+	FailureThreshold int
+
+	// how long a failing backend is skipped before it's tried again
+	CircuitBreakPeriod time.Duration
+
+	// interval of copying shards missing from some backends, 0 disables repairing
+	RepairInterval time.Duration
 }
 
 // Ipfs contains configs for backend ipfs
@@ -51,6 +225,39 @@ type Ipfs struct {
 	Conn string
 }
 
+// Local contains configs for backend local filesystem storage
+type Local struct {
+
+	// root directory of the content addressed blocks
+	Path string
+}
+
+// S3 contains configs for backend S3 compatible object storage
+type S3 struct {
+
+	// endpoint of the S3 compatible service, empty for AWS S3
+	Endpoint string
+
+	// region of the bucket, us-east-1 if empty
+	Region string
+
+	// bucket to store shard objects
+	Bucket string
+
+	// key prefix of shard objects in the bucket
+	Prefix string
+
+	// credentials, the default AWS credential chain is used if AccessKeyId is empty
+	AccessKeyId     string
+	SecretAccessKey string
+
+	// use path style addressing, required by MinIO
+	PathStyle bool
+
+	// part size in bytes of multipart uploads, content larger than that is uploaded in parts, 5MiB if 0
+	PartSize int64
+}
+
 // Indexer contains configs for indexing and graphsql service
 type Indexer struct {
 	// indexer db path
@@ -71,6 +278,9 @@ type Module struct {
 
 	// Enable indexer module
 	IndexerEnable bool
+
+	// Enable scheduled shard audits of fisherman, requires gateway module
+	FishermanEnable bool
 }
 
 // API contains configs for API endpoint
@@ -87,7 +297,7 @@ type API struct {
 // Chain contains configs for sao chain information
 type Chain struct {
 
-	// remote connection string
+	// remote connection string, mock://<name>[?blocktime=<duration>] runs an in-memory chain shared by nodes in the same process
 	Remote string
 
 	// websocket endpoint
@@ -0,0 +1,338 @@
+package fisherman
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SaoNetwork/sao-node/chain"
+	"github.com/SaoNetwork/sao-node/node/config"
+	"github.com/SaoNetwork/sao-node/types"
+	"github.com/SaoNetwork/sao-node/utils"
+
+	modeltypes "github.com/SaoNetwork/sao/x/model/types"
+	saotypes "github.com/SaoNetwork/sao/x/sao/types"
+	"github.com/ipfs/go-datastore"
+	logging "github.com/ipfs/go-log/v2"
+)
+
+var log = logging.Logger("fisherman")
+
+type ShardAuditor interface {
+	AuditShard(ctx context.Context, provider string, cidStr string, peer string, dataId string, orderId uint64) error
+}
+
+type FishermanSvc struct {
+	ctx         context.Context
+	cancel      context.CancelFunc
+	nodeAddress string
+	chainSvc    chain.ChainSvcApi
+	auditor     ShardAuditor
+	ds          datastore.Batching
+	cfg         *config.Fisherman
+	rand        *rand.Rand
+	wg          sync.WaitGroup
+}
+
+type auditTask struct {
+	provider string
+	shard    *modeltypes.ShardMeta
+	meta     *auditMeta
+}
+
+type auditMeta struct {
+	dataId  string
+	orderId uint64
+	commit  string
+}
+
+func NewFishermanSvc(
+	ctx context.Context,
+	nodeAddress string,
+	chainSvc chain.ChainSvcApi,
+	auditor ShardAuditor,
+	ds datastore.Batching,
+	cfg *config.Fisherman,
+) *FishermanSvc {
+	ctx, cancel := context.WithCancel(ctx)
+	return &FishermanSvc{
+		ctx:         ctx,
+		cancel:      cancel,
+		nodeAddress: nodeAddress,
+		chainSvc:    chainSvc,
+		auditor:     auditor,
+		ds:          ds,
+		cfg:         cfg,
+		rand:        rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+/**
+ * audit sampled shards every AuditInterval until stopped.
+ */
+func (fs *FishermanSvc) Start() {
+	if fs.cfg.AuditInterval <= 0 {
+		log.Warn("fisherman audit interval is not set, scheduled audits are disabled")
+		return
+	}
+
+	fs.wg.Add(1)
+	go func() {
+		defer fs.wg.Done()
+
+		ticker := time.NewTicker(fs.cfg.AuditInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				err := fs.AuditRound(fs.ctx)
+				if err != nil {
+					log.Errorf("audit round error: %v", err)
+				}
+			case <-fs.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (fs *FishermanSvc) Stop(_ context.Context) error {
+	log.Info("stopping fisherman service...")
+	fs.cancel()
+	fs.wg.Wait()
+	return nil
+}
+
+/**
+ * sample metadata from chain, audit their shards, then report new faults and recover fixed ones.
+ */
+func (fs *FishermanSvc) AuditRound(ctx context.Context) error {
+	fishmen, err := fs.chainSvc.GetFishmen(ctx)
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(fishmen, fs.nodeAddress) {
+		log.Warnf("%s is not a fisherman, skip the audit round", fs.nodeAddress)
+		return nil
+	}
+
+	tasks, err := fs.sampleTasks(ctx)
+	if err != nil {
+		return err
+	}
+	log.Infof("auditing %d shards", len(tasks))
+
+	records := make([]types.AuditRecord, len(tasks))
+	concurrency := fs.cfg.AuditConcurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	throttle := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, task := range tasks {
+		throttle <- struct{}{}
+		wg.Add(1)
+		go func(i int, task auditTask) {
+			defer func() {
+				<-throttle
+				wg.Done()
+			}()
+
+			record := types.AuditRecord{
+				Provider: task.provider,
+				DataId:   task.meta.dataId,
+				OrderId:  task.meta.orderId,
+				ShardId:  task.shard.ShardId,
+				Cid:      task.shard.Cid,
+				Passed:   true,
+			}
+			err := fs.auditor.AuditShard(ctx, task.provider, task.shard.Cid, task.shard.Peer, task.meta.dataId, task.meta.orderId)
+			if types.ErrAuditPending.Is(err) {
+				log.Infof("audit of shard %d of %s is pending: %v", task.shard.ShardId, task.provider, err)
+				record.Passed = false
+				record.Pending = true
+				record.Message = err.Error()
+			} else if err != nil {
+				log.Warnf("shard %d of %s failed the audit: %v", task.shard.ShardId, task.provider, err)
+				record.Passed = false
+				record.Message = err.Error()
+			}
+			record.AuditAt = time.Now().Unix()
+			records[i] = record
+		}(i, task)
+	}
+	wg.Wait()
+
+	byProvider := make(map[string][]int)
+	for i, record := range records {
+		byProvider[record.Provider] = append(byProvider[record.Provider], i)
+	}
+	for provider, indexes := range byProvider {
+		providerRecords := make([]types.AuditRecord, 0, len(indexes))
+		providerTasks := make([]auditTask, 0, len(indexes))
+		for _, i := range indexes {
+			providerRecords = append(providerRecords, records[i])
+			providerTasks = append(providerTasks, tasks[i])
+		}
+
+		err := fs.settle(ctx, provider, providerRecords, providerTasks)
+		if err != nil {
+			log.Errorf("settle audits of %s error: %v", provider, err)
+		}
+	}
+	return nil
+}
+
+/**
+ * pick AuditSampleSize metadata from a random offset.
+ */
+func (fs *FishermanSvc) sampleTasks(ctx context.Context) ([]auditTask, error) {
+	_, total, err := fs.chainSvc.ListMeta(ctx, 0, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	sampleSize := fs.cfg.AuditSampleSize
+	if sampleSize == 0 {
+		sampleSize = 1
+	}
+	var offset uint64
+	if total > sampleSize {
+		offset = uint64(fs.rand.Int63n(int64(total - sampleSize + 1)))
+	}
+	metas, _, err := fs.chainSvc.ListMeta(ctx, offset, sampleSize)
+	if err != nil {
+		return nil, err
+	}
+
+	var tasks []auditTask
+	for _, m := range metas {
+		resp, err := fs.chainSvc.GetMeta(ctx, m.DataId)
+		if err != nil {
+			log.Errorf("get meta %s error: %v", m.DataId, err)
+			continue
+		}
+		if len(resp.Metadata.Commits) == 0 {
+			continue
+		}
+
+		meta := &auditMeta{
+			dataId:  resp.Metadata.DataId,
+			orderId: resp.Metadata.OrderId,
+			commit:  resp.Metadata.Commits[len(resp.Metadata.Commits)-1],
+		}
+		for provider, shard := range resp.Shards {
+			tasks = append(tasks, auditTask{
+				provider: provider,
+				shard:    shard,
+				meta:     meta,
+			})
+		}
+	}
+	return tasks, nil
+}
+
+/**
+ * report the shards failing for the first time and recover the ones passing again,
+ * then save the records with the fault state on chain.
+ */
+func (fs *FishermanSvc) settle(ctx context.Context, provider string, records []types.AuditRecord, tasks []auditTask) error {
+	// the open faults are taken from chain, the audit history is truncated and may miss them
+	faultIds, err := fs.chainSvc.GetMyFaults(ctx, provider)
+	if err != nil {
+		return err
+	}
+	faultOpen := make(map[uint64]bool)
+	for _, faultId := range faultIds {
+		fault, err := fs.chainSvc.GetFault(ctx, faultId)
+		if err != nil {
+			return err
+		}
+		// a fault reported by other fishermen only is still to be confirmed by this one
+		faultOpen[fault.ShardId] = strings.Contains(fault.Confirms, "+"+fs.nodeAddress)
+	}
+
+	var reports, recovers []*saotypes.Fault
+	var reportIndexes, recoverIndexes []int
+	for i, record := range records {
+		open := faultOpen[record.ShardId]
+		records[i].FaultOpen = open
+		if record.Pending || record.Passed == !open {
+			continue
+		}
+
+		fault := &saotypes.Fault{
+			DataId:   record.DataId,
+			OrderId:  record.OrderId,
+			ShardId:  record.ShardId,
+			CommitId: tasks[i].meta.commit,
+			Provider: provider,
+			Reporter: fs.nodeAddress,
+		}
+		if record.Passed {
+			fault.CommitId = strings.Split(fault.CommitId, "\032")[0]
+			recovers = append(recovers, fault)
+			recoverIndexes = append(recoverIndexes, i)
+		} else {
+			reports = append(reports, fault)
+			reportIndexes = append(reportIndexes, i)
+		}
+	}
+
+	if len(reports) > 0 {
+		_, err := fs.chainSvc.ReportFaults(ctx, fs.nodeAddress, provider, reports)
+		if err != nil {
+			log.Errorf("report %d faults of %s error: %v", len(reports), provider, err)
+		} else {
+			for _, i := range reportIndexes {
+				records[i].FaultOpen = true
+			}
+		}
+	}
+	if len(recovers) > 0 {
+		_, err := fs.chainSvc.RecoverFaults(ctx, fs.nodeAddress, provider, recovers)
+		if err != nil {
+			log.Errorf("recover %d faults of %s error: %v", len(recovers), provider, err)
+		} else {
+			for _, i := range recoverIndexes {
+				records[i].FaultOpen = false
+			}
+		}
+	}
+
+	return utils.SaveAuditRecords(ctx, fs.ds, provider, records)
+}
+
+/**
+ * audit records of the provider, or of all providers if provider is empty, latest first.
+ */
+func (fs *FishermanSvc) AuditHistory(ctx context.Context, provider string) ([]types.AuditRecord, error) {
+	var providers []string
+	if provider != "" {
+		providers = []string{provider}
+	} else {
+		index, err := utils.GetAuditIndex(ctx, fs.ds)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range index.All {
+			providers = append(providers, key.Provider)
+		}
+	}
+
+	var records []types.AuditRecord
+	for _, p := range providers {
+		history, err := utils.GetAuditHistory(ctx, fs.ds, p)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, history.Records...)
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].AuditAt > records[j].AuditAt
+	})
+	return records, nil
+}
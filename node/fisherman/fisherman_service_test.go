@@ -0,0 +1,104 @@
+package fisherman
+
+import (
+	"context"
+	"testing"
+
+	"github.com/SaoNetwork/sao-node/chain"
+	"github.com/SaoNetwork/sao-node/node/config"
+	"github.com/SaoNetwork/sao-node/types"
+	"github.com/SaoNetwork/sao-node/utils"
+
+	nodetypes "github.com/SaoNetwork/sao/x/node/types"
+	saotypes "github.com/SaoNetwork/sao/x/sao/types"
+	"github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAuditor fails the audits of every shard while failing is set.
+type fakeAuditor struct {
+	failing bool
+}
+
+func (a *fakeAuditor) AuditShard(ctx context.Context, provider string, cidStr string, peer string, dataId string, orderId uint64) error {
+	if a.failing {
+		return types.Wrapf(types.ErrInvalidCid, "shard %s of %s is lost", cidStr, provider)
+	}
+	return nil
+}
+
+func TestSettle(t *testing.T) {
+	ctx := context.Background()
+
+	mc, err := chain.NewMockChainSvc(ctx, "mock://TestSettle?blocktime=1h", "")
+	require.NoError(t, err)
+	defer mc.Stop(ctx)
+
+	gateway := "sao1gateway"
+	sp := "sao1sp1"
+	fisherman := "sao1fisherman"
+	_, err = mc.Reset(ctx, gateway, "/ip4/127.0.0.1/tcp/5153/p2p/gateway", nodetypes.NODE_STATUS_ONLINE|nodetypes.NODE_STATUS_SERVE_GATEWAY, nil, nil)
+	require.NoError(t, err)
+	_, err = mc.Reset(ctx, sp, "/ip4/127.0.0.1/tcp/5153/p2p/"+sp, nodetypes.NODE_STATUS_ONLINE|nodetypes.NODE_STATUS_SERVE_STORAGE|nodetypes.NODE_STATUS_ACCEPT_ORDER, nil, nil)
+	require.NoError(t, err)
+	mc.SetFishmen(fisherman)
+
+	content := []byte("hello fisherman")
+	contentCid, err := utils.CalculateCid(content)
+	require.NoError(t, err)
+	dataId := utils.GenerateDataId("fisherman")
+	resp, _, _, err := mc.StoreOrder(ctx, gateway, &types.OrderStoreProposal{
+		Proposal: saotypes.Proposal{
+			Owner:     "did:key:owner",
+			Provider:  gateway,
+			GroupId:   "group",
+			Duration:  100,
+			Replica:   1,
+			Timeout:   10,
+			Alias:     "alias",
+			DataId:    dataId,
+			CommitId:  dataId,
+			Cid:       contentCid.String(),
+			Size_:     uint64(len(content)),
+			Operation: 1,
+		},
+	})
+	require.NoError(t, err)
+	_, _, err = mc.CompleteOrder(ctx, sp, resp.OrderId, contentCid, uint64(len(content)))
+	require.NoError(t, err)
+
+	auditor := &fakeAuditor{}
+	cfg := &config.Fisherman{AuditSampleSize: 1, AuditConcurrency: 1}
+	newSvc := func() *FishermanSvc {
+		return NewFishermanSvc(ctx, fisherman, mc, auditor, dssync.MutexWrap(datastore.NewMapDatastore()), cfg)
+	}
+	faults := func() []string {
+		faultIds, err := mc.GetMyFaults(ctx, sp)
+		require.NoError(t, err)
+		return faultIds
+	}
+
+	// a failed audit reports the fault once
+	fs := newSvc()
+	auditor.failing = true
+	require.NoError(t, fs.AuditRound(ctx))
+	require.Len(t, faults(), 1)
+	require.NoError(t, fs.AuditRound(ctx))
+	require.Len(t, faults(), 1)
+	records, err := fs.AuditHistory(ctx, sp)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	require.True(t, records[0].FaultOpen)
+
+	// the open fault is known without the audit history, a passed audit recovers it
+	fs = newSvc()
+	auditor.failing = false
+	require.NoError(t, fs.AuditRound(ctx))
+	require.Empty(t, faults())
+	records, err = fs.AuditHistory(ctx, sp)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	require.False(t, records[0].FaultOpen)
+	require.True(t, records[0].Passed)
+}
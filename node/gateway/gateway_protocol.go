@@ -2,6 +2,7 @@ package gateway
 
 import (
 	"context"
+	"io"
 
 	"github.com/SaoNetwork/sao-node/types"
 )
@@ -9,6 +10,10 @@ import (
 type GatewayProtocol interface {
 	RequestShardAssign(ctx context.Context, req types.ShardAssignReq, peer string) types.ShardAssignResp
 	RequestShardLoad(ctx context.Context, req types.ShardLoadReq, peer string, isForward bool) types.ShardLoadResp
+	// RequestShardLoadStream writes the shard content into w instead of buffering it in the response.
+	RequestShardLoadStream(ctx context.Context, req types.ShardLoadReq, peer string, w io.Writer) types.ShardLoadResp
+	// RequestShardChallenge asks the storage node for merkle proofs of the challenged shard leaves.
+	RequestShardChallenge(ctx context.Context, req types.ShardChallengeReq, peer string) types.ShardChallengeResp
 	GetPeers(ctx context.Context) string
 	Stop(ctx context.Context) error
 }
@@ -21,4 +26,9 @@ type GatewayProtocolHandler interface {
 	HandleShardComplete(types.ShardCompleteReq) types.ShardCompleteResp
 
 	HandleShardStore(types.ShardLoadReq) types.ShardLoadResp
+
+	/**
+	 * the returned reader, if not nil, provides the staged shard content to be sent after the response.
+	 */
+	HandleShardStoreStream(types.ShardLoadReq) (types.ShardLoadResp, io.Reader)
 }
@@ -8,6 +8,7 @@ import (
 
 	"github.com/SaoNetwork/sao-node/store"
 	"github.com/SaoNetwork/sao-node/types"
+	"github.com/SaoNetwork/sao-node/utils"
 )
 
 type LocalGatewayProtocol struct {
@@ -100,6 +101,85 @@ func (l LocalGatewayProtocol) RequestShardLoad(ctx context.Context, req types.Sh
 	}
 }
 
+func (l LocalGatewayProtocol) RequestShardLoadStream(ctx context.Context, req types.ShardLoadReq, _ string, w io.Writer) types.ShardLoadResp {
+	returnErr := func(code uint64, errMsg string) types.ShardLoadResp {
+		return types.ShardLoadResp{
+			Code:       code,
+			Message:    errMsg,
+			OrderId:    req.OrderId,
+			Cid:        req.Cid,
+			Content:    nil,
+			RequestId:  req.RequestId,
+			ResponseId: time.Now().UnixMilli(),
+		}
+	}
+
+	reader, err := l.storeManager.Get(ctx, req.Cid)
+	if err != nil {
+		return returnErr(
+			types.ErrorCodeInternalErr,
+			fmt.Sprintf("get cid(%v) from store manager error: %v", req.Cid, err),
+		)
+	}
+	if c, ok := reader.(io.Closer); ok {
+		defer c.Close()
+	}
+
+	_, err = io.Copy(w, reader)
+	if err != nil {
+		return returnErr(
+			types.ErrorCodeInternalErr,
+			fmt.Sprintf("failed to read from store manager: %v", err),
+		)
+	}
+	return types.ShardLoadResp{
+		Code:       0,
+		OrderId:    req.OrderId,
+		Cid:        req.Cid,
+		RequestId:  req.RequestId,
+		ResponseId: time.Now().UnixMilli(),
+	}
+}
+
+func (l LocalGatewayProtocol) RequestShardChallenge(ctx context.Context, req types.ShardChallengeReq, _ string) types.ShardChallengeResp {
+	returnErr := func(code uint64, errMsg string) types.ShardChallengeResp {
+		return types.ShardChallengeResp{
+			Code:       code,
+			Message:    errMsg,
+			Cid:        req.Cid,
+			RequestId:  req.RequestId,
+			ResponseId: time.Now().UnixMilli(),
+		}
+	}
+
+	reader, err := l.storeManager.Get(ctx, req.Cid)
+	if err != nil {
+		return returnErr(
+			types.ErrorCodeInternalErr,
+			fmt.Sprintf("get cid(%v) from store manager error: %v", req.Cid, err),
+		)
+	}
+	if c, ok := reader.(io.Closer); ok {
+		defer c.Close()
+	}
+
+	leafCount, proofs, err := utils.BuildShardProofs(reader, req.LeafSize, req.Leaves)
+	if err != nil {
+		return returnErr(
+			types.ErrorCodeInternalErr,
+			fmt.Sprintf("failed to build proofs: %v", err),
+		)
+	}
+	return types.ShardChallengeResp{
+		Code:       0,
+		Cid:        req.Cid,
+		LeafCount:  leafCount,
+		Proofs:     proofs,
+		RequestId:  req.RequestId,
+		ResponseId: time.Now().UnixMilli(),
+	}
+}
+
 func (l LocalGatewayProtocol) GetPeers(_ context.Context) string {
 	return ""
 }
@@ -5,11 +5,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/SaoNetwork/sao-node/node/transport"
+	"github.com/SaoNetwork/sao-node/tracing"
 	"github.com/SaoNetwork/sao-node/types"
 
 	"github.com/libp2p/go-libp2p/core/host"
@@ -33,6 +35,7 @@ func NewStreamGatewayProtocol(ctx context.Context, host host.Host, handler Gatew
 		RH:                     rh,
 	}
 	host.SetStreamHandler(types.ShardStoreProtocol, sgp.handleShardStoreStream)
+	host.SetStreamHandler(types.ShardStoreStreamProtocol, sgp.handleShardStoreChunkStream)
 	host.SetStreamHandler(types.ShardCompleteProtocol, sgp.handleShardCompleteStream)
 	host.SetStreamHandler(types.ShardLoadProtocol, sgp.handleRelayStream)
 	host.SetStreamHandler(types.ShardPingPongProtocol, transport.HandlePingRequest)
@@ -43,6 +46,7 @@ func NewStreamGatewayProtocol(ctx context.Context, host host.Host, handler Gatew
 func (l StreamGatewayProtocol) Stop(ctx context.Context) error {
 	log.Info("stopping stream gateway protocol ...")
 	l.host.RemoveStreamHandler(types.ShardStoreProtocol)
+	l.host.RemoveStreamHandler(types.ShardStoreStreamProtocol)
 	l.host.RemoveStreamHandler(types.ShardCompleteProtocol)
 	return nil
 }
@@ -68,6 +72,9 @@ func (l StreamGatewayProtocol) HandleRPCRequest(s network.Stream) {
 		case "Sao.Upload":
 			req.Params = append(req.Params, filepath.Join(l.RH.StagingPath, s.Conn().RemotePeer().String()))
 			result, err = l.RH.Upload(req.Params)
+		case "Sao.UploadStatus":
+			req.Params = append(req.Params, filepath.Join(l.RH.StagingPath, s.Conn().RemotePeer().String()))
+			result, err = l.RH.UploadStatus(req.Params)
 		case "Sao.ModelCreate":
 			result, err = l.RH.Create(req.Params)
 		case "Sao.ModelLoad":
@@ -139,7 +146,36 @@ func (l StreamGatewayProtocol) handleShardStoreStream(s network.Stream) {
 	}
 	log.Debugf("receive ShardLoadReq: orderId=%d cid=%v requestId=%d", req.OrderId, req.Cid, req.RequestId)
 
-	respond(l.HandleShardStore(req))
+	_, span := tracing.StartHandlerSpan(context.Background(), "HandleShardStore", &req.TraceContext, tracing.CidKey.String(req.Cid.String()))
+	resp := l.HandleShardStore(req)
+	tracing.EndResponse(span, resp.Code, resp.Message)
+	respond(resp)
+}
+
+func (l StreamGatewayProtocol) handleShardStoreChunkStream(s network.Stream) {
+	log.Infof("handling %s ...", types.ShardStoreStreamProtocol)
+	defer s.Close()
+
+	// Set a deadline on reading from the stream so it doesn't hang
+	_ = s.SetReadDeadline(time.Now().Add(30 * time.Second))
+	defer s.SetReadDeadline(time.Time{}) // nolint
+
+	var req types.ShardLoadReq
+	err := req.Unmarshal(s, types.FormatCbor)
+	if err != nil {
+		log.Error(types.Wrap(types.ErrUnMarshalFailed, err))
+		transport.RespondShardStream(s, types.ShardLoadResp{
+			Code:    types.ErrorCodeInvalidRequest,
+			Message: fmt.Sprintf("failed to unmarshal request: %v", err),
+		}, nil, 0)
+		return
+	}
+	log.Debugf("receive ShardLoadReq: orderId=%d cid=%v requestId=%d offset=%d", req.OrderId, req.Cid, req.RequestId, req.Offset)
+
+	_, span := tracing.StartHandlerSpan(context.Background(), "HandleShardStore", &req.TraceContext, tracing.CidKey.String(req.Cid.String()))
+	resp, reader := l.HandleShardStoreStream(req)
+	transport.RespondShardStream(s, resp, reader, req.Offset)
+	tracing.EndResponse(span, resp.Code, resp.Message)
 }
 
 func (l StreamGatewayProtocol) handleShardCompleteStream(s network.Stream) {
@@ -174,7 +210,10 @@ func (l StreamGatewayProtocol) handleShardCompleteStream(s network.Stream) {
 		return
 	}
 
-	respond(l.HandleShardComplete(req))
+	_, span := tracing.StartHandlerSpan(context.Background(), "HandleShardComplete", &req.TraceContext, tracing.OrderIdKey.Int64(int64(req.OrderId)))
+	resp := l.HandleShardComplete(req)
+	tracing.EndResponse(span, resp.Code, resp.Message)
+	respond(resp)
 }
 
 func (l StreamGatewayProtocol) handleRelayStream(s network.Stream) {
@@ -236,7 +275,7 @@ func (l StreamGatewayProtocol) RequestShardAssign(ctx context.Context, req types
 	)
 	if err != nil {
 		resp = types.ShardAssignResp{
-			Code:    types.ErrorCodeInternalErr,
+			Code:    types.TransportErrorCode(err),
 			Message: fmt.Sprintf("transport assign request error: %v", err),
 		}
 	}
@@ -256,7 +295,7 @@ func (l StreamGatewayProtocol) RequestShardLoad(ctx context.Context, req types.S
 	)
 	if err != nil {
 		resp = types.ShardLoadResp{
-			Code:       types.ErrorCodeInternalErr,
+			Code:       types.TransportErrorCode(err),
 			Message:    fmt.Sprintf("transport assign request error: %v", err),
 			OrderId:    req.OrderId,
 			Cid:        req.Cid,
@@ -268,6 +307,76 @@ func (l StreamGatewayProtocol) RequestShardLoad(ctx context.Context, req types.S
 	return resp
 }
 
+func (l StreamGatewayProtocol) RequestShardLoadStream(ctx context.Context, req types.ShardLoadReq, peer string, w io.Writer) types.ShardLoadResp {
+	var resp types.ShardLoadResp
+	cw := &countingWriter{w: w}
+	err := transport.RequestShardStream(
+		ctx,
+		peer,
+		l.host,
+		types.ShardLoadStreamProtocol,
+		types.ShardLoadProtocol,
+		req,
+		&resp,
+		cw,
+	)
+	if err != nil && cw.n == 0 {
+		// the peer may be unreachable directly, try the relay then.
+		log.Warnf("shard load stream error, fallback to relay: %v", err)
+		resp = l.RequestShardLoad(ctx, req, peer, true)
+		err = nil
+		if resp.Code == 0 {
+			_, err = w.Write(resp.Content)
+			resp.Content = nil
+		}
+	}
+	if err != nil {
+		resp = types.ShardLoadResp{
+			Code:       types.TransportErrorCode(err),
+			Message:    fmt.Sprintf("transport load stream error: %v", err),
+			OrderId:    req.OrderId,
+			Cid:        req.Cid,
+			RequestId:  req.RequestId,
+			ResponseId: time.Now().UnixMilli(),
+		}
+	}
+	return resp
+}
+
+func (l StreamGatewayProtocol) RequestShardChallenge(ctx context.Context, req types.ShardChallengeReq, peer string) types.ShardChallengeResp {
+	var resp types.ShardChallengeResp
+	err := transport.HandleRequest(
+		ctx,
+		peer,
+		l.host,
+		types.ShardChallengeProtocol,
+		&req,
+		&resp,
+		false,
+	)
+	if err != nil {
+		resp = types.ShardChallengeResp{
+			Code:       types.TransportErrorCode(err),
+			Message:    fmt.Sprintf("transport challenge request error: %v", err),
+			Cid:        req.Cid,
+			RequestId:  req.RequestId,
+			ResponseId: time.Now().UnixMilli(),
+		}
+	}
+	return resp
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
 func (l StreamGatewayProtocol) GetPeers(_ context.Context) string {
 	return l.host.Peerstore().Peers().String()
 }
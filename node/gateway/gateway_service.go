@@ -1,19 +1,28 @@
 package gateway
 
 import (
+	"bufio"
 	"bytes"
 	"context"
+	"crypto/rand"
+	"errors"
 	"fmt"
+	"io"
+	"math/big"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/SaoNetwork/sao-node/chain"
+	"github.com/SaoNetwork/sao-node/metrics"
 	"github.com/SaoNetwork/sao-node/node/config"
 	"github.com/SaoNetwork/sao-node/node/queue"
 	"github.com/SaoNetwork/sao-node/node/transport"
 	"github.com/SaoNetwork/sao-node/store"
+	"github.com/SaoNetwork/sao-node/tracing"
 	"github.com/SaoNetwork/sao-node/types"
 	"github.com/SaoNetwork/sao-node/utils"
 
@@ -28,15 +37,19 @@ import (
 
 	logging "github.com/ipfs/go-log/v2"
 	"github.com/libp2p/go-libp2p/core/host"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var log = logging.Logger("gateway")
 
+// returned by loadErasureContent when the shards are plain fragments of the content
+var errFragmentShards = errors.New("shards are content fragments")
+
 const (
-	WINDOW_SIZE       = 10
 	SCHEDULE_INTERVAL = 1
 	LOCKNAME_COMPLETE = "complete"
-	MAX_RETRIES       = 3
+
+	SHARD_COMPLETE_EVENT = "shard-complete"
 )
 
 type CommitResult struct {
@@ -64,13 +77,16 @@ type GatewaySvcApi interface {
 	Stop(ctx context.Context) error
 	OrderStatus(ctx context.Context, id string) (types.OrderInfo, error)
 	OrderFix(ctx context.Context, id string) error
+	OrderCancel(ctx context.Context, id string) error
+	OrderReassign(ctx context.Context, id string, from string, to string) error
 	OrderList(ctx context.Context) ([]types.OrderInfo, error)
 	FetchShard(ctx context.Context, provider string, cidStr string, peer string, dataId string, orderId uint64) types.ShardLoadResp
+	AuditShard(ctx context.Context, provider string, cidStr string, peer string, dataId string, orderId uint64) error
 }
 
 type GatewaySvc struct {
 	ctx                context.Context
-	chainSvc           *chain.ChainSvc
+	chainSvc           chain.ChainSvcApi
 	storeManager       *store.StoreManager
 	keyringHome        string
 	nodeAddress        string
@@ -84,15 +100,23 @@ type GatewaySvc struct {
 	schedQueue *queue.RequestQueue
 	timeoutMap map[uint64][]types.OrderInfo
 	locks      *utils.Maplock
+	retry      utils.RetryPolicies
 
 	completeResultChan chan string
 	completeMap        map[string]int64
+	shardCompleteChan  chan *chain.ShardComplete
+
+	commitmentLk sync.Mutex
+	// shard cids whose commitments are being built
+	committing map[string]struct{}
+	// provider + shard cid -> content mismatch found while building the commitment
+	commitFailures map[string]error
 }
 
 func NewGatewaySvc(
 	ctx context.Context,
 	nodeAddress string,
-	chainSvc *chain.ChainSvc,
+	chainSvc chain.ChainSvcApi,
 	host host.Host,
 	cfg *config.Node,
 	storeManager *store.StoreManager,
@@ -102,6 +126,7 @@ func NewGatewaySvc(
 	stagingPath string,
 	serverPath string,
 	rh *transport.RpcHandler,
+	retry utils.RetryPolicies,
 ) *GatewaySvc {
 	cs := &GatewaySvc{
 		ctx:                ctx,
@@ -115,10 +140,14 @@ func NewGatewaySvc(
 		cfg:                cfg,
 		completeResultChan: make(chan string),
 		completeMap:        make(map[string]int64),
+		shardCompleteChan:  make(chan *chain.ShardComplete),
 		orderDs:            orderDs,
-		schedQueue:         &queue.RequestQueue{},
+		schedQueue:         queue.NewRequestQueue(tenantPolicy(&cfg.Gateway.Scheduler)),
 		timeoutMap:         make(map[uint64][]types.OrderInfo),
 		locks:              utils.NewMapLock(),
+		retry:              retry,
+		committing:         make(map[string]struct{}),
+		commitFailures:     make(map[string]error),
 	}
 	cs.gatewayProtocolMap = make(map[string]GatewayProtocol)
 
@@ -142,6 +171,19 @@ func NewGatewaySvc(
 	go cs.completeLoop(ctx)
 	go cs.checkTimeout(ctx)
 
+	go func() {
+		err := chain.RetrySubscribe(ctx, SHARD_COMPLETE_EVENT, func(ctx context.Context) error {
+			fromHeight, err := utils.GetEventHeight(ctx, orderDs, SHARD_COMPLETE_EVENT)
+			if err != nil {
+				return err
+			}
+			return chainSvc.SubscribeShardComplete(ctx, nodeAddress, fromHeight, cs.shardCompleteChan)
+		})
+		if err == nil {
+			cs.processShardCompletes(ctx)
+		}
+	}()
+
 	return cs
 }
 
@@ -174,15 +216,58 @@ func (gs *GatewaySvc) processIncompleteOrders(ctx context.Context) {
 	}
 }
 
+/**
+ * the tenant policy of the scheduling queue, orders of a tenant are processed in order and tenants share the
+ * processing by their weights.
+ */
+func tenantPolicy(cfg *config.Scheduler) queue.TenantPolicy {
+	policy := queue.TenantPolicy{
+		Default: queue.Tenant{
+			Weight:      cfg.TenantWeight,
+			Concurrency: cfg.TenantConcurrency,
+		},
+		Tenants: make(map[string]queue.Tenant),
+	}
+	for _, t := range cfg.Tenants {
+		policy.Tenants[t.Key] = queue.Tenant{
+			Weight:      t.Weight,
+			Concurrency: t.Concurrency,
+		}
+	}
+
+	switch cfg.TenantKey {
+	case "platform":
+		policy.Key = func(r *queue.WorkRequest) string {
+			if r.Order.GroupId != "" {
+				return r.Order.GroupId
+			}
+			return r.Order.Owner
+		}
+	default:
+		if cfg.TenantKey != "owner" {
+			log.Warnf("unknown scheduler tenant key %s, orders are grouped by owner", cfg.TenantKey)
+		}
+		policy.Key = func(r *queue.WorkRequest) string {
+			return r.Order.Owner
+		}
+	}
+	return policy
+}
+
 func (gs *GatewaySvc) runSched(ctx context.Context, host host.Host) {
-	throttle := make(chan struct{}, WINDOW_SIZE)
+	concurrency := gs.cfg.Gateway.Scheduler.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	throttle := make(chan struct{}, concurrency)
 	for {
-		if gs.schedQueue.Len() == 0 {
+		// requests of tenants at their concurrency cap wait until some of them are done, failed ones until their retry
+		if gs.schedQueue.Runnable() == 0 {
 			time.Sleep(time.Second * SCHEDULE_INTERVAL)
 			continue
 		}
 
-		len := gs.schedQueue.Len()
+		len := gs.schedQueue.Runnable()
 		for i := 0; i < len; i++ {
 			throttle <- struct{}{}
 
@@ -195,15 +280,14 @@ func (gs *GatewaySvc) runSched(ctx context.Context, host host.Host) {
 				if task == nil {
 					return
 				}
-				if task.Order.RetryAt > time.Now().Unix() {
-					gs.schedQueue.Push(task)
-					return
-				}
+				defer gs.schedQueue.Done(task)
 
 				err := gs.process(ctx, &task.Order)
 				if err != nil {
 					log.Warnf("process order %d error: %v", task.Order.OrderId, err)
-					gs.schedQueue.Push(task)
+					if gs.retryLater(ctx, &task.Order, err) {
+						gs.schedQueue.Push(task)
+					}
 				}
 			}()
 		}
@@ -291,8 +375,8 @@ func (gs *GatewaySvc) HandleShardComplete(req types.ShardCompleteReq) types.Shar
 		}
 	}
 
-	gs.locks.Lock(lockname(req.OrderId))
-	defer gs.locks.Unlock(lockname(req.OrderId))
+	gs.locks.Lock(lockname(req.DataId))
+	defer gs.locks.Unlock(lockname(req.DataId))
 
 	orderInfo, err := utils.GetOrder(gs.ctx, gs.orderDs, req.DataId)
 	if err != nil {
@@ -301,10 +385,20 @@ func (gs *GatewaySvc) HandleShardComplete(req types.ShardCompleteReq) types.Shar
 			types.ErrorCodeInternalErr,
 		)
 	}
-	shardInfo := orderInfo.Shards[m.Provider]
+	gs.completeShard(orderInfo, order, m.Provider, req.TxHash)
+	return types.ShardCompleteResp{Code: 0}
+}
+
+/**
+ * mark the shard of provider completed, and the order once all shards on chain are completed.
+ * the caller must hold the order lock.
+ */
+func (gs *GatewaySvc) completeShard(orderInfo types.OrderInfo, order *ordertypes.FullOrder, provider string, txHash string) {
+	shardInfo := orderInfo.Shards[provider]
 	shardInfo.State = types.ShardStateCompleted
-	shardInfo.CompleteHash = req.TxHash
-	err = utils.SaveOrder(gs.ctx, gs.orderDs, orderInfo)
+	shardInfo.CompleteHash = txHash
+	orderInfo.Shards[provider] = shardInfo
+	err := utils.SaveOrder(gs.ctx, gs.orderDs, orderInfo)
 	if err != nil {
 		log.Warn("put order %d error: %v", orderInfo.OrderId, err)
 	}
@@ -317,15 +411,60 @@ func (gs *GatewaySvc) HandleShardComplete(req types.ShardCompleteReq) types.Shar
 			log.Warn("put order %d error: %v", orderInfo.OrderId, err)
 		}
 
-		log.Debugf("unstage shard %s/%s/%v", gs.stagingPath, orderInfo.Owner, orderInfo.Cid)
-		err := UnstageShard(gs.stagingPath, orderInfo.Owner, orderInfo.Cid.String(), orderInfo.DataId)
-		if err != nil {
-			log.Warnf("unstage shard error: %v", err)
-		}
+		gs.unstageOrder(orderInfo)
 
 		gs.completeResultChan <- orderInfo.DataId
 	}
-	return types.ShardCompleteResp{Code: 0}
+}
+
+/**
+ * complete the shards of this gateway's orders from chain events, so that an order completes even if the
+ * storage provider's complete request is lost.
+ */
+func (gs *GatewaySvc) processShardCompletes(ctx context.Context) {
+	for {
+		select {
+		case complete := <-gs.shardCompleteChan:
+			gs.handleShardComplete(ctx, complete)
+			err := utils.SaveEventHeight(ctx, gs.orderDs, SHARD_COMPLETE_EVENT, complete.Height-1)
+			if err != nil {
+				log.Warnf("save %s event height %d failed: %v", SHARD_COMPLETE_EVENT, complete.Height, err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (gs *GatewaySvc) handleShardComplete(ctx context.Context, complete *chain.ShardComplete) {
+	// completions of all orders on chain are received, only the orders of this gateway are queried
+	orderInfo, err := utils.GetOrderByOrderId(ctx, gs.orderDs, complete.OrderId)
+	if err != nil || orderInfo.DataId == "" {
+		return
+	}
+
+	gs.locks.Lock(lockname(orderInfo.DataId))
+	defer gs.locks.Unlock(lockname(orderInfo.DataId))
+
+	orderInfo, err = utils.GetOrder(ctx, gs.orderDs, orderInfo.DataId)
+	if err != nil || orderInfo.OrderId != complete.OrderId {
+		return
+	}
+	shardInfo, exists := orderInfo.Shards[complete.Provider]
+	if !exists || shardInfo.State == types.ShardStateCompleted {
+		return
+	}
+
+	order, err := gs.chainSvc.GetOrder(ctx, complete.OrderId)
+	if err != nil {
+		log.Warnf("get order %d error: %v", complete.OrderId, err)
+		return
+	}
+	if order.Provider != gs.nodeAddress {
+		return
+	}
+	log.Infof("order %d shard of %s completed in tx %s", order.Id, complete.Provider, complete.TxHash)
+	gs.completeShard(orderInfo, order, complete.Provider, complete.TxHash)
 }
 
 func (gs *GatewaySvc) HandleShardStore(req types.ShardLoadReq) types.ShardLoadResp {
@@ -347,6 +486,24 @@ func (gs *GatewaySvc) HandleShardStore(req types.ShardLoadReq) types.ShardLoadRe
 	return resp
 }
 
+func (gs *GatewaySvc) HandleShardStoreStream(req types.ShardLoadReq) (types.ShardLoadResp, io.Reader) {
+	resp := types.ShardLoadResp{
+		OrderId:    req.OrderId,
+		Cid:        req.Cid,
+		RequestId:  req.RequestId,
+		ResponseId: time.Now().UnixMilli(),
+	}
+
+	file, err := OpenStagedShard(gs.stagingPath, req.Owner, req.Cid, req.DataId)
+	if err != nil {
+		resp.Code = types.ErrorCodeInternalErr
+		resp.Message = fmt.Sprintf("Get staged shard(%v) error: %v", req.Cid, err)
+		return resp, nil
+	}
+	resp.Code = 0
+	return resp, file
+}
+
 func (gs *GatewaySvc) QueryMeta(ctx context.Context, req *types.MetadataProposal, height int64) (*types.Model, error) {
 	res, err := gs.chainSvc.QueryMetadata(ctx, req, height)
 	if err != nil {
@@ -383,6 +540,33 @@ func (gs *GatewaySvc) QueryMeta(ctx context.Context, req *types.MetadataProposal
 }
 
 func (gs *GatewaySvc) FetchShard(ctx context.Context, provider string, cidStr string, peer string, dataId string, orderId uint64) types.ShardLoadResp {
+	shardCid, err := cid.Decode(cidStr)
+	if err != nil {
+		return types.ShardLoadResp{Code: 1, Message: "invalid cid"}
+	}
+
+	gp, req := gs.buildShardLoadReq(ctx, provider, shardCid, peer, dataId, orderId)
+	ctx, span := tracing.StartRequestSpan(ctx, "RequestShardLoad", &req.TraceContext, tracing.ProviderKey.String(provider), tracing.CidKey.String(cidStr))
+	resp := gp.RequestShardLoad(ctx, req, peer, true)
+	tracing.EndResponse(span, resp.Code, resp.Message)
+	return resp
+}
+
+/**
+ * same as FetchShard, but the shard content is written into w instead of the response.
+ */
+func (gs *GatewaySvc) fetchShardStream(ctx context.Context, provider string, shardCid cid.Cid, peer string, dataId string, orderId uint64, w io.Writer) types.ShardLoadResp {
+	gp, req := gs.buildShardLoadReq(ctx, provider, shardCid, peer, dataId, orderId)
+	ctx, span := tracing.StartRequestSpan(ctx, "RequestShardLoad", &req.TraceContext, tracing.ProviderKey.String(provider), tracing.CidKey.String(shardCid.String()))
+	resp := gp.RequestShardLoadStream(ctx, req, peer, w)
+	tracing.EndResponse(span, resp.Code, resp.Message)
+	return resp
+}
+
+/**
+ * the request of this node loading a shard for itself, e.g. to audit it.
+ */
+func (gs *GatewaySvc) buildShardLoadReq(ctx context.Context, provider string, shardCid cid.Cid, peer string, dataId string, orderId uint64) (GatewayProtocol, types.ShardLoadReq) {
 	var gp GatewayProtocol
 	if provider == gs.nodeAddress {
 		gp = gs.gatewayProtocolMap["local"]
@@ -390,12 +574,7 @@ func (gs *GatewaySvc) FetchShard(ctx context.Context, provider string, cidStr st
 		gp = gs.gatewayProtocolMap["stream"]
 	}
 
-	shardCid, err := cid.Decode(cidStr)
-	if err != nil {
-		return types.ShardLoadResp{Code: 1, Message: "invalid cid"}
-	}
-
-	return gp.RequestShardLoad(ctx, types.ShardLoadReq{
+	return gp, types.ShardLoadReq{
 		Owner:   gs.nodeAddress,
 		Cid:     shardCid,
 		DataId:  dataId,
@@ -408,81 +587,223 @@ func (gs *GatewaySvc) FetchShard(ctx context.Context, provider string, cidStr st
 		},
 		RequestId:     time.Now().UnixMilli(),
 		RelayProposal: gs.buildRelayProposal(ctx, gp, peer),
-	}, peer, true)
+	}
 }
 
-func (gs *GatewaySvc) FetchContent(ctx context.Context, req *types.MetadataProposal, meta *types.Model) (*FetchResult, error) {
-	contentList := make(map[uint64][]byte)
+/**
+ * audit the shard stored by the provider, nil error means the provider proves it holds the shard.
+ *
+ * audits only challenge random leaves against the merkle commitment of the shard cid. the commitment takes
+ * a full download, so it's built in background on the first audit, and the audit is pending until then.
+ */
+func (gs *GatewaySvc) AuditShard(ctx context.Context, provider string, cidStr string, peer string, dataId string, orderId uint64) error {
+	shardCid, err := cid.Decode(cidStr)
+	if err != nil {
+		return types.Wrapf(types.ErrInvalidCid, "%s", cidStr)
+	}
 
-	var res *FetchResult
-	var content []byte
-	var contentCid cid.Cid
-	var err error
+	gs.commitmentLk.Lock()
+	failure, failed := gs.commitFailures[provider+cidStr]
+	delete(gs.commitFailures, provider+cidStr)
+	gs.commitmentLk.Unlock()
+	if failed {
+		return failure
+	}
 
-	for key, shard := range meta.Shards {
-		if contentList[shard.ShardId] != nil {
-			continue
+	commitment, err := utils.GetShardCommitment(ctx, gs.orderDs, cidStr)
+	if err != nil {
+		return err
+	}
+	if commitment.Cid == "" {
+		gs.startCommitment(provider, shardCid, peer, dataId, orderId)
+		return types.Wrapf(types.ErrAuditPending, "%s of %s: the commitment is being built", cidStr, provider)
+	}
+
+	if gs.cfg.Fisherman.AuditTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, gs.cfg.Fisherman.AuditTimeout)
+		defer cancel()
+	}
+
+	leaves, err := randomLeaves(commitment.LeafCount, gs.cfg.Fisherman.ChallengeLeaves)
+	if err != nil {
+		return err
+	}
+
+	var gp GatewayProtocol
+	if provider == gs.nodeAddress {
+		gp = gs.gatewayProtocolMap["local"]
+	} else {
+		gp = gs.gatewayProtocolMap["stream"]
+	}
+	resp := gp.RequestShardChallenge(ctx, types.ShardChallengeReq{
+		Fisherman: gs.nodeAddress,
+		OrderId:   orderId,
+		Cid:       shardCid,
+		LeafSize:  commitment.LeafSize,
+		Leaves:    leaves,
+		RequestId: time.Now().UnixMilli(),
+	}, peer)
+	if resp.Code != 0 {
+		return types.ResponseError(resp.Code, fmt.Sprintf("challenge %s of %s: %s", cidStr, provider, resp.Message))
+	}
+
+	if resp.LeafCount != commitment.LeafCount || len(resp.Proofs) != len(leaves) {
+		return types.Wrapf(types.ErrInvalidStorageProof, "%s of %s: unexpected leaves", cidStr, provider)
+	}
+	for i, proof := range resp.Proofs {
+		if proof.Index != leaves[i] ||
+			!utils.VerifyMerkleProof(commitment.Root, proof.Content, proof.Index, commitment.LeafCount, proof.Path) {
+			return types.Wrapf(types.ErrInvalidStorageProof, "%s of %s: leaf %d", cidStr, provider, leaves[i])
 		}
+	}
+	return nil
+}
 
-		shardCid, err := cid.Decode(shard.Cid)
-		if err != nil {
-			return nil, types.Wrapf(types.ErrInvalidCid, "%s", shard.Cid)
+/**
+ * build the commitment of the shard from the provider in background, unless it's being built already.
+ * a content mismatch fails the next audit of the provider, other errors leave the audit pending.
+ */
+func (gs *GatewaySvc) startCommitment(provider string, shardCid cid.Cid, peer string, dataId string, orderId uint64) {
+	key := shardCid.String()
+	gs.commitmentLk.Lock()
+	if _, exists := gs.committing[key]; exists {
+		gs.commitmentLk.Unlock()
+		return
+	}
+	gs.committing[key] = struct{}{}
+	gs.commitmentLk.Unlock()
+
+	go func() {
+		ctx := gs.ctx
+		if gs.cfg.Fisherman.CommitmentTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, gs.cfg.Fisherman.CommitmentTimeout)
+			defer cancel()
 		}
 
-		var gp GatewayProtocol
-		if key == gs.nodeAddress {
-			gp = gs.gatewayProtocolMap["local"]
-		} else {
-			gp = gs.gatewayProtocolMap["stream"]
-		}
-
-		resp := gp.RequestShardLoad(ctx, types.ShardLoadReq{
-			Cid:     shardCid,
-			DataId:  meta.DataId,
-			OrderId: meta.OrderId,
-			Proposal: types.MetadataProposalCbor{
-				Proposal: types.QueryProposal{
-					Owner:           req.Proposal.Owner,
-					Keyword:         req.Proposal.Keyword,
-					GroupId:         req.Proposal.GroupId,
-					KeywordType:     uint64(req.Proposal.KeywordType),
-					LastValidHeight: req.Proposal.LastValidHeight,
-					Gateway:         req.Proposal.Gateway,
-					CommitId:        req.Proposal.CommitId,
-					Version:         req.Proposal.Version,
-				},
-				JwsSignature: types.JwsSignature{
-					Protected: req.JwsSignature.Protected,
-					Signature: req.JwsSignature.Signature,
-				},
-			},
-			RequestId:     time.Now().UnixMilli(),
-			RelayProposal: gs.buildRelayProposal(ctx, gp, shard.Peer),
-		}, shard.Peer, true)
-		if resp.Code == 0 {
-			if meta.Cid == shard.Cid {
-				contentCid = shardCid
-				content = resp.Content
-				// replica mode res
-				res = &FetchResult{
-					Cid:     meta.Cid,
-					Content: resp.Content,
-				}
-				break
+		err := gs.commitShard(ctx, provider, shardCid, peer, dataId, orderId)
+
+		gs.commitmentLk.Lock()
+		defer gs.commitmentLk.Unlock()
+		delete(gs.committing, key)
+		if err != nil {
+			log.Warnf("failed to build the commitment of shard %v from %s: %v", shardCid, provider, err)
+			if types.ErrInvalidStorageProof.Is(err) {
+				gs.commitFailures[provider+key] = err
 			}
-			contentList[shard.ShardId] = resp.Content
-		} else {
-			continue
 		}
+	}()
+}
+
+/**
+ * stream the whole shard once, verify its cid and save the merkle commitment of it.
+ */
+func (gs *GatewaySvc) commitShard(ctx context.Context, provider string, shardCid cid.Cid, peer string, dataId string, orderId uint64) error {
+	// storage nodes reject challenges out of these bounds
+	leafSize := gs.cfg.Fisherman.LeafSize
+	if leafSize < types.ShardChallengeMinLeafSize {
+		leafSize = types.ShardChallengeMinLeafSize
+	} else if leafSize > types.ShardChallengeMaxLeafSize {
+		leafSize = types.ShardChallengeMaxLeafSize
 	}
 
-	if content == nil && len(contentList) == 0 {
-		return nil, types.Wrapf(types.ErrFailuresResponsed, "%s", meta.DataId)
+	pr, pw := io.Pipe()
+	defer pr.Close()
+	go func() {
+		resp := gs.fetchShardStream(ctx, provider, shardCid, peer, dataId, orderId, pw)
+		if resp.Code != 0 {
+			pw.CloseWithError(types.ResponseError(resp.Code, fmt.Sprintf("fetch %v of %s: %s", shardCid, provider, resp.Message)))
+			return
+		}
+		pw.Close()
+	}()
+
+	hasher := utils.NewCidHasher()
+	tree, err := utils.BuildMerkleTree(io.TeeReader(pr, hasher), leafSize, nil)
+	if err != nil {
+		return err
 	}
 
-	if res == nil {
-		for _, c := range contentList {
-			content = append(content, c...)
+	contentCid, err := hasher.Cid()
+	if err != nil {
+		return err
+	}
+	if !contentCid.Equals(shardCid) {
+		return types.Wrapf(types.ErrInvalidStorageProof, "%v of %s: content cid %v mismatch", shardCid, provider, contentCid)
+	}
+
+	return utils.SaveShardCommitment(ctx, gs.orderDs, types.ShardCommitment{
+		Cid:       shardCid.String(),
+		Size:      tree.Size,
+		LeafSize:  tree.LeafSize,
+		LeafCount: tree.LeafCount(),
+		Root:      tree.Root(),
+		CreatedAt: time.Now().Unix(),
+	})
+}
+
+// randomLeaves picks up to count distinct leaf indexes in ascending order, the storage node must not predict them.
+func randomLeaves(leafCount uint64, count int) ([]uint64, error) {
+	if count <= 0 {
+		count = 1
+	}
+	if count > types.ShardChallengeMaxLeaves {
+		count = types.ShardChallengeMaxLeaves
+	}
+	if uint64(count) > leafCount {
+		count = int(leafCount)
+	}
+
+	picked := make(map[uint64]bool)
+	for len(picked) < count {
+		index, err := rand.Int(rand.Reader, new(big.Int).SetUint64(leafCount))
+		if err != nil {
+			return nil, err
+		}
+		picked[index.Uint64()] = true
+	}
+	leaves := make([]uint64, 0, count)
+	for index := range picked {
+		leaves = append(leaves, index)
+	}
+	sort.Slice(leaves, func(i, j int) bool { return leaves[i] < leaves[j] })
+	return leaves, nil
+}
+
+func (gs *GatewaySvc) FetchContent(ctx context.Context, req *types.MetadataProposal, meta *types.Model) (*FetchResult, error) {
+	var content []byte
+	var contentCid cid.Cid
+	var err error
+
+	// replica shards hold the whole content, otherwise the content is erasure coded into the shards,
+	// or split into plain fragments by the older layout, which is told by the missing erasure shard header.
+	erasure := true
+	for _, shard := range meta.Shards {
+		if shard.Cid == meta.Cid {
+			erasure = false
+			break
+		}
+	}
+
+	// each distinct shard is loaded from one provider at a time
+	var keys []string
+	listed := make(map[string]bool)
+	for key, shard := range meta.Shards {
+		if listed[shard.Cid] || (!erasure && shard.Cid != meta.Cid) {
+			continue
+		}
+		listed[shard.Cid] = true
+		keys = append(keys, key)
+	}
+
+	if erasure {
+		content, err = gs.loadErasureContent(ctx, req, meta, keys)
+		if err == errFragmentShards {
+			content, err = gs.loadFragments(ctx, req, meta)
+		}
+		if err != nil {
+			return nil, err
 		}
 
 		contentCid, err = utils.CalculateCid(content)
@@ -493,14 +814,34 @@ func (gs *GatewaySvc) FetchContent(ctx context.Context, req *types.MetadataPropo
 			log.Errorf("cid mismatch, expected %s, but got %s", meta.Cid, contentCid.String())
 			return nil, types.Wrapf(types.ErrInvalidCid, "%s", contentCid.String())
 		}
+	} else {
+		loaded := false
+		for _, key := range keys {
+			var buf bytes.Buffer
+			err = gs.loadShard(ctx, req, meta, key, &buf)
+			if err != nil {
+				log.Warnf("failed to load shard %s from %s: %v", meta.Shards[key].Cid, key, err)
+				continue
+			}
+			content = buf.Bytes()
+			loaded = true
+			break
+		}
+		if !loaded {
+			return nil, types.Wrapf(types.ErrFailuresResponsed, "%s", meta.DataId)
+		}
 
-		// fragmentation mode res
-		res = &FetchResult{
-			Cid:     contentCid.String(),
-			Content: content,
+		contentCid, err = cid.Decode(meta.Cid)
+		if err != nil {
+			return nil, types.Wrapf(types.ErrInvalidCid, "%s", meta.Cid)
 		}
 	}
 
+	res := &FetchResult{
+		Cid:     contentCid.String(),
+		Content: content,
+	}
+
 	match, err := regexp.Match("^"+types.Type_Prefix_File, []byte(meta.Alias))
 	if err != nil {
 		return nil, types.Wrapf(types.ErrInvalidAlias, "%s", meta.Alias)
@@ -539,6 +880,183 @@ func (gs *GatewaySvc) FetchContent(ctx context.Context, req *types.MetadataPropo
 	return res, nil
 }
 
+/**
+ * load the shard stored by the provider key into w. a shard only counts once its content matches the cid,
+ * so that a bad provider can't corrupt the result.
+ */
+func (gs *GatewaySvc) loadShard(ctx context.Context, req *types.MetadataProposal, meta *types.Model, key string, w io.Writer) error {
+	shard := meta.Shards[key]
+	shardCid, err := cid.Decode(shard.Cid)
+	if err != nil {
+		return types.Wrapf(types.ErrInvalidCid, "%s", shard.Cid)
+	}
+
+	var gp GatewayProtocol
+	if key == gs.nodeAddress {
+		gp = gs.gatewayProtocolMap["local"]
+	} else {
+		gp = gs.gatewayProtocolMap["stream"]
+	}
+
+	loadReq := types.ShardLoadReq{
+		Cid:     shardCid,
+		DataId:  meta.DataId,
+		OrderId: meta.OrderId,
+		Proposal: types.MetadataProposalCbor{
+			Proposal: types.QueryProposal{
+				Owner:           req.Proposal.Owner,
+				Keyword:         req.Proposal.Keyword,
+				GroupId:         req.Proposal.GroupId,
+				KeywordType:     uint64(req.Proposal.KeywordType),
+				LastValidHeight: req.Proposal.LastValidHeight,
+				Gateway:         req.Proposal.Gateway,
+				CommitId:        req.Proposal.CommitId,
+				Version:         req.Proposal.Version,
+			},
+			JwsSignature: types.JwsSignature{
+				Protected: req.JwsSignature.Protected,
+				Signature: req.JwsSignature.Signature,
+			},
+		},
+		RequestId:     time.Now().UnixMilli(),
+		RelayProposal: gs.buildRelayProposal(ctx, gp, shard.Peer),
+	}
+
+	hasher := utils.NewCidHasher()
+	loadCtx, span := tracing.StartRequestSpan(ctx, "RequestShardLoad", &loadReq.TraceContext, tracing.ProviderKey.String(key), tracing.CidKey.String(shard.Cid))
+	resp := gp.RequestShardLoadStream(loadCtx, loadReq, shard.Peer, io.MultiWriter(w, hasher))
+	tracing.EndResponse(span, resp.Code, resp.Message)
+	if resp.Code != 0 {
+		return types.ResponseError(resp.Code, resp.Message)
+	}
+
+	fetchedCid, err := hasher.Cid()
+	if err != nil {
+		return err
+	}
+	if !fetchedCid.Equals(shardCid) {
+		return types.Wrapf(types.ErrInvalidCid, "content cid %s mismatch", fetchedCid)
+	}
+	return nil
+}
+
+/**
+ * restore the erasure coded content from the shards of the providers keys. the shards are streamed into the
+ * decoder while they're loaded, a shard failing to load or to verify is replaced with the shard of the next provider.
+ */
+func (gs *GatewaySvc) loadErasureContent(ctx context.Context, req *types.MetadataProposal, meta *types.Model, keys []string) ([]byte, error) {
+	// the order size bounds the content size claimed by the shard headers
+	order, err := gs.chainSvc.GetOrder(ctx, meta.OrderId)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var pipes []*io.PipeReader
+	closePipes := func() {
+		for _, pr := range pipes {
+			pr.Close()
+		}
+		pipes = nil
+	}
+	defer closePipes()
+
+	// a shard failing the verification fails the read at its end, so the decoder drops it
+	open := func(key string) *bufio.Reader {
+		pr, pw := io.Pipe()
+		pipes = append(pipes, pr)
+		go func() {
+			pw.CloseWithError(gs.loadShard(ctx, req, meta, key, pw))
+		}()
+		return bufio.NewReader(pr)
+	}
+
+	candidates := keys
+	dataShards := 0
+	for {
+		closePipes()
+
+		// the good shards of the last try are loaded again along with the next candidate
+		var readers []io.Reader
+		for i := 0; i < len(candidates) && (dataShards == 0 || len(readers) < dataShards); {
+			reader := open(candidates[i])
+			if dataShards == 0 {
+				// the first shard header tells how many shards are needed
+				header, err := reader.Peek(utils.ErasureShardHeaderSize)
+				if (err == nil || err == io.EOF) && !utils.IsErasureShard(header) {
+					// a verified shard without the erasure shard header is a fragment of the older layout
+					_, err = io.Copy(io.Discard, reader)
+					if err == nil {
+						return nil, errFragmentShards
+					}
+				} else if err == nil {
+					var h utils.ErasureShardHeader
+					h, err = utils.ParseErasureShardHeader(header)
+					dataShards = int(h.DataShards)
+				}
+				if err != nil {
+					log.Warnf("failed to load erasure shard %s from %s: %v", meta.Shards[candidates[i]].Cid, candidates[i], err)
+					candidates = append(candidates[:i:i], candidates[i+1:]...)
+					continue
+				}
+			}
+			readers = append(readers, reader)
+			i++
+		}
+		if len(readers) == 0 || len(readers) < dataShards {
+			return nil, types.Wrapf(types.ErrFailuresResponsed, "%s", meta.DataId)
+		}
+
+		content, bad, err := utils.ErasureDecodeReaders(readers, order.Size_)
+		if err == nil {
+			return content, nil
+		}
+		if bad < 0 {
+			return nil, err
+		}
+		log.Warnf("erasure shard %s from %s is dropped: %v", meta.Shards[candidates[bad]].Cid, candidates[bad], err)
+		candidates = append(candidates[:bad:bad], candidates[bad+1:]...)
+	}
+}
+
+/**
+ * load the content of the older layout, where each shard holds a plain fragment of the content,
+ * the fragments are joined in the order of their shard ids.
+ */
+func (gs *GatewaySvc) loadFragments(ctx context.Context, req *types.MetadataProposal, meta *types.Model) ([]byte, error) {
+	providers := make(map[uint64][]string)
+	for key, shard := range meta.Shards {
+		providers[shard.ShardId] = append(providers[shard.ShardId], key)
+	}
+	shardIds := make([]uint64, 0, len(providers))
+	for shardId := range providers {
+		shardIds = append(shardIds, shardId)
+	}
+	sort.Slice(shardIds, func(i, j int) bool { return shardIds[i] < shardIds[j] })
+
+	var content bytes.Buffer
+	for _, shardId := range shardIds {
+		loaded := false
+		for _, key := range providers[shardId] {
+			var buf bytes.Buffer
+			err := gs.loadShard(ctx, req, meta, key, &buf)
+			if err != nil {
+				log.Warnf("failed to load shard %s from %s: %v", meta.Shards[key].Cid, key, err)
+				continue
+			}
+			content.Write(buf.Bytes())
+			loaded = true
+			break
+		}
+		if !loaded {
+			return nil, types.Wrapf(types.ErrFailuresResponsed, "%s", meta.DataId)
+		}
+	}
+	return content.Bytes(), nil
+}
+
 func (gs *GatewaySvc) buildRelayProposal(ctx context.Context, gp GatewayProtocol, peerInfos string) types.RelayProposalCbor {
 	if gp.GetPeers(ctx) == "" {
 		return types.RelayProposalCbor{
@@ -581,15 +1099,30 @@ func (gs *GatewaySvc) buildRelayProposal(ctx context.Context, gp GatewayProtocol
 	}
 }
 
-func (gs *GatewaySvc) process(ctx context.Context, orderInfo *types.OrderInfo) error {
-	gs.locks.Lock(lockname(orderInfo.OrderId))
-	defer gs.locks.Unlock(lockname(orderInfo.OrderId))
+func (gs *GatewaySvc) process(ctx context.Context, orderInfo *types.OrderInfo) (err error) {
+	gs.locks.Lock(lockname(orderInfo.DataId))
+	defer gs.locks.Unlock(lockname(orderInfo.DataId))
+
+	ctx, span := tracing.StartSpan(tracing.Extract(ctx, orderInfo.TraceContext), "GatewaySvc.process", trace.WithAttributes(
+		tracing.DataIdKey.String(orderInfo.DataId),
+		tracing.OrderIdKey.Int64(int64(orderInfo.OrderId)),
+	))
+	defer func() {
+		tracing.RecordError(span, err)
+		span.End()
+	}()
 
 	if orderInfo.State == types.OrderStateTerminate {
 		log.Warn("stop process, order ", orderInfo.OrderId, " has terminated")
 		return nil
 	}
 
+	// the queued order may be outdated if it's cancelled meanwhile
+	if stored, e := utils.GetOrder(ctx, gs.orderDs, orderInfo.DataId); e == nil && stored.State == types.OrderStateCancelled {
+		log.Warn("stop process, order ", orderInfo.OrderId, " has been cancelled")
+		return nil
+	}
+
 	if orderInfo.State == types.OrderStateComplete {
 		gs.completeResultChan <- orderInfo.DataId
 		log.Warn("stop process, order ", orderInfo.OrderId, " has completed")
@@ -597,19 +1130,10 @@ func (gs *GatewaySvc) process(ctx context.Context, orderInfo *types.OrderInfo) e
 	}
 
 	orderInfo.Tries++
-	orderInfo.RetryAt = utils.GetRetryAt(orderInfo.Tries)
-	log.Infof("order dataid=%s tries=%d", orderInfo.DataId, orderInfo.Tries)
-	if orderInfo.Tries >= MAX_RETRIES {
-		orderInfo.State = types.OrderStateTerminate
-		errMsg := fmt.Sprintf("order %d too many retries %d", orderInfo.OrderId, orderInfo.Tries)
-		orderInfo.LastErr = errMsg
-		e := utils.SaveOrder(ctx, gs.orderDs, *orderInfo)
-		if e != nil {
-			log.Warn("put order %d error: %v", orderInfo.OrderId, e)
-		}
-		log.Warn("stop process,", errMsg)
-		return nil
+	if orderInfo.Tries > 1 {
+		metrics.OrderRetries.Inc()
 	}
+	log.Infof("order dataid=%s tries=%d", orderInfo.DataId, orderInfo.Tries)
 
 	if orderInfo.ExpireHeight > 0 {
 		latestHeight, err := gs.chainSvc.GetLastHeight(ctx)
@@ -652,7 +1176,17 @@ func (gs *GatewaySvc) process(ctx context.Context, orderInfo *types.OrderInfo) e
 					AssignTxType:  orderInfo.OrderTxType,
 					TimeoutHeight: orderInfo.ExpireHeight,
 				}
-				resp := gp.RequestShardAssign(ctx, req, shard.Peer)
+				if shard.Cid != orderInfo.Cid.String() {
+					size, err := StagedShardSize(gs.stagingPath, orderInfo.Owner, shard.Cid, orderInfo.DataId)
+					if err != nil {
+						return err
+					}
+					req.ShardCid = shard.Cid
+					req.ShardSize = size
+				}
+				reqCtx, span := tracing.StartRequestSpan(ctx, "RequestShardAssign", &req.TraceContext, tracing.ProviderKey.String(node))
+				resp := gp.RequestShardAssign(reqCtx, req, shard.Peer)
+				tracing.EndResponse(span, resp.Code, resp.Message)
 				if resp.Code == 0 {
 					shard.State = types.ShardStateNotified
 					log.Infof("assigned order %d shard to node %s.", orderInfo.OrderId, node)
@@ -660,6 +1194,7 @@ func (gs *GatewaySvc) process(ctx context.Context, orderInfo *types.OrderInfo) e
 					shard.State = types.ShardStateError
 					log.Errorf("assigned order %d shards to node %s failed: %v", orderInfo.OrderId, node, resp.Message)
 				}
+				orderInfo.Shards[node] = shard
 			}
 		}
 		log.Debugf("assigned order %d done.", orderInfo.OrderId)
@@ -680,7 +1215,42 @@ func (gs *GatewaySvc) process(ctx context.Context, orderInfo *types.OrderInfo) e
 	return nil
 }
 
+/**
+ * schedule the next attempt of a failed order by the retry policy of the error, the order terminates if the policy gives up.
+ */
+func (gs *GatewaySvc) retryLater(ctx context.Context, orderInfo *types.OrderInfo, err error) bool {
+	gs.locks.Lock(lockname(orderInfo.DataId))
+	defer gs.locks.Unlock(lockname(orderInfo.DataId))
+
+	policy, retryAt, ok := gs.retry.NextRetry(err, orderInfo.Tries)
+	orderInfo.RetryPolicy = policy
+	orderInfo.RetryAt = retryAt
+	orderInfo.LastErr = err.Error()
+	if !ok {
+		orderInfo.State = types.OrderStateTerminate
+		orderInfo.LastErr = fmt.Sprintf("order %d gives up after %d tries by %s retry policy: %v", orderInfo.OrderId, orderInfo.Tries, policy, err)
+		log.Warn("stop process, ", orderInfo.LastErr)
+	}
+	e := utils.SaveOrder(ctx, gs.orderDs, *orderInfo)
+	if e != nil {
+		log.Warnf("put order %d error: %v", orderInfo.OrderId, e)
+	}
+	return ok
+}
+
 func (gs *GatewaySvc) CommitModel(ctx context.Context, clientProposal *types.OrderStoreProposal, orderId uint64, content []byte) (*CommitResult, error) {
+	ctx, span := tracing.StartSpan(ctx, "GatewaySvc.CommitModel", trace.WithAttributes(
+		tracing.DataIdKey.String(clientProposal.Proposal.DataId),
+		tracing.OrderIdKey.Int64(int64(orderId)),
+	))
+	defer span.End()
+
+	result, err := gs.commitModel(ctx, clientProposal, orderId, content)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+func (gs *GatewaySvc) commitModel(ctx context.Context, clientProposal *types.OrderStoreProposal, orderId uint64, content []byte) (*CommitResult, error) {
 	// stage order data.
 	orderProposal := clientProposal.Proposal
 	stagePath, err := StageShard(gs.stagingPath, orderProposal, content)
@@ -707,7 +1277,9 @@ func (gs *GatewaySvc) CommitModel(ctx context.Context, clientProposal *types.Ord
 		OrderId:   orderId,
 		Owner:     clientProposal.Proposal.Owner,
 		Cid:       cid,
+		GroupId:   clientProposal.Proposal.GroupId,
 	}
+	tracing.Inject(ctx, &orderInfo.TraceContext)
 	err = utils.SaveOrder(ctx, gs.orderDs, orderInfo)
 	if err != nil {
 		return nil, err
@@ -774,6 +1346,11 @@ func (gs *GatewaySvc) CommitModel(ctx context.Context, clientProposal *types.Ord
 			}
 		}
 
+		err = gs.stageErasureShards(&orderInfo, content)
+		if err != nil {
+			return nil, err
+		}
+
 		order, err := gs.chainSvc.GetOrder(ctx, orderInfo.OrderId)
 		if err == nil {
 			orderInfo.ExpireHeight = order.CreatedAt + order.Timeout
@@ -798,14 +1375,6 @@ func (gs *GatewaySvc) CommitModel(ctx context.Context, clientProposal *types.Ord
 	}
 	gs.locks.Unlock("timeout")
 
-	// TODO: wsevent
-	//err = gs.chainSvc.UnsubscribeOrderComplete(ctx, orderId)
-	//if err != nil {
-	//	log.Error(err)
-	//} else {
-	//	log.Debugf("UnsubscribeOrderComplete")
-	//}
-
 	oi, err := utils.GetOrder(ctx, gs.orderDs, orderInfo.DataId)
 	if err != nil {
 		return nil, err
@@ -849,6 +1418,10 @@ func (gs *GatewaySvc) UpdateModelPermission(ctx context.Context, req *types.Perm
 func (gs *GatewaySvc) Stop(ctx context.Context) error {
 	log.Info("stopping gateway service...")
 
+	if err := gs.chainSvc.UnsubscribeShardComplete(ctx, gs.nodeAddress); err != nil {
+		log.Errorf("unsubscribe shard complete failed: %v", err)
+	}
+
 	var err error
 	for k, p := range gs.gatewayProtocolMap {
 		err = p.Stop(ctx)
@@ -866,7 +1439,15 @@ func (gs *GatewaySvc) Stop(ctx context.Context) error {
 }
 
 func (gs *GatewaySvc) OrderStatus(ctx context.Context, id string) (types.OrderInfo, error) {
-	return utils.GetOrder(ctx, gs.orderDs, id)
+	orderInfo, err := utils.GetOrder(ctx, gs.orderDs, id)
+	if err != nil {
+		return orderInfo, err
+	}
+
+	orderInfo.QueuePosition = int64(gs.schedQueue.Position(func(r *queue.WorkRequest) bool {
+		return r.Order.DataId == id
+	}))
+	return orderInfo, nil
 }
 
 func (gs *GatewaySvc) getOrderKeys(ctx context.Context) ([]types.OrderKey, error) {
@@ -895,23 +1476,199 @@ func (gs *GatewaySvc) OrderList(ctx context.Context) ([]types.OrderInfo, error)
 	return orderInfos, nil
 }
 
+/**
+ * reset the retries of an order and schedule it again, a terminated order is resumed if it's still in progress on chain.
+ */
 func (gs *GatewaySvc) OrderFix(ctx context.Context, dataId string) error {
-	orderInfo, err := utils.GetOrder(ctx, gs.orderDs, dataId)
+	orderInfo, err := gs.lockOrder(ctx, dataId)
+	if err != nil {
+		return err
+	}
+	defer gs.locks.Unlock(lockname(dataId))
+
+	_, err = gs.checkInflight(ctx, orderInfo)
+	if err != nil {
+		return err
+	}
+
+	orderInfo.Tries = 0
+	orderInfo.RetryAt = 0
+	if orderInfo.State == types.OrderStateTerminate {
+		orderInfo.State = types.OrderStateReady
+		if orderInfo.OrderId == 0 {
+			orderInfo.State = types.OrderStateStaged
+		}
+	}
+	orderInfo.LastErr = ""
+	err = utils.SaveOrder(ctx, gs.orderDs, orderInfo)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("order %d is fixed, re-sched it", orderInfo.OrderId)
+	gs.schedQueue.Push(&queue.WorkRequest{Order: orderInfo})
+	return nil
+}
+
+/**
+ * stop processing an in-flight order and release its staging files, the order on chain is left to timeout.
+ */
+func (gs *GatewaySvc) OrderCancel(ctx context.Context, dataId string) error {
+	orderInfo, err := gs.lockOrder(ctx, dataId)
+	if err != nil {
+		return err
+	}
+	defer gs.locks.Unlock(lockname(dataId))
+
+	_, err = gs.checkInflight(ctx, orderInfo)
+	if err != nil {
+		return err
+	}
+
+	orderInfo.State = types.OrderStateCancelled
+	orderInfo.LastErr = "cancelled by the operator"
+	err = utils.SaveOrder(ctx, gs.orderDs, orderInfo)
+	if err != nil {
+		return err
+	}
+
+	gs.unstageOrder(orderInfo)
+
+	gs.locks.Lock(LOCKNAME_COMPLETE)
+	delete(gs.completeMap, orderInfo.DataId)
+	gs.locks.Unlock(LOCKNAME_COMPLETE)
+
+	log.Infof("order %d is cancelled", orderInfo.OrderId)
+	return nil
+}
+
+/**
+ * hand the shard of provider from over to provider to. the chain decides the shard providers, so provider to
+ * must hold a waiting shard of the order on chain which isn't assigned by this gateway yet.
+ */
+func (gs *GatewaySvc) OrderReassign(ctx context.Context, dataId string, from string, to string) error {
+	orderInfo, err := gs.lockOrder(ctx, dataId)
+	if err != nil {
+		return err
+	}
+	defer gs.locks.Unlock(lockname(dataId))
+
+	order, err := gs.checkInflight(ctx, orderInfo)
+	if err != nil {
+		return err
+	}
+	if order == nil {
+		return types.Wrapf(types.ErrInvalidOrderState, "order %s is not submitted to chain yet", dataId)
+	}
+
+	shard, exists := orderInfo.Shards[from]
+	if !exists {
+		return types.Wrapf(types.ErrInvalidProvider, "order %d has no shard of provider %s", order.Id, from)
+	}
+	if shard.State == types.ShardStateCompleted {
+		return types.Wrapf(types.ErrInvalidShardState, "shard of provider %s has completed", from)
+	}
+	if fromShard, exists := order.Shards[from]; exists && fromShard.Status == ordertypes.ShardCompleted {
+		return types.Wrapf(types.ErrInvalidShardState, "shard of provider %s has completed on chain", from)
+	}
+	if _, exists := orderInfo.Shards[to]; exists {
+		return types.Wrapf(types.ErrInvalidProvider, "provider %s already has a shard of order %d", to, order.Id)
+	}
+	toShard, exists := order.Shards[to]
+	if !exists {
+		return types.Wrapf(types.ErrInvalidProvider, "provider %s has no shard of order %d on chain", to, order.Id)
+	}
+	if toShard.Status != ordertypes.ShardWaiting {
+		return types.Wrapf(types.ErrInvalidShardState, "shard of provider %s is not waiting on chain, status %d", to, toShard.Status)
+	}
+	peer, err := gs.chainSvc.GetNodePeer(ctx, to)
 	if err != nil {
 		return err
 	}
 
+	err = utils.AppendOrderEvents(ctx, gs.orderDs, dataId, order.Id, types.OrderEvent{
+		Kind:    types.OrderEventOrderShard,
+		Subject: from,
+		From:    string(shard.State),
+		To:      "reassigned",
+		Message: fmt.Sprintf("reassigned to %s by the operator", to),
+		Time:    time.Now().UnixMilli(),
+	})
+	if err != nil {
+		log.Warnf("record reassign of order %d error: %v", order.Id, err)
+	}
+
+	// the new provider takes over the shard content, which differs from the order cid if erasure coded
+	delete(orderInfo.Shards, from)
+	orderInfo.Shards[to] = types.OrderShardInfo{
+		ShardId:  toShard.Id,
+		Peer:     peer,
+		Cid:      shard.Cid,
+		Provider: shard.Provider,
+		State:    types.ShardStateAssigned,
+	}
+	orderInfo.Tries = 0
+	orderInfo.RetryAt = 0
+	if orderInfo.State == types.OrderStateTerminate {
+		orderInfo.State = types.OrderStateReady
+	}
+	err = utils.SaveOrder(ctx, gs.orderDs, orderInfo)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("order %d shard of %s is reassigned to %s, re-sched it", order.Id, from, to)
 	gs.schedQueue.Push(&queue.WorkRequest{Order: orderInfo})
 	return nil
 }
 
+/**
+ * get the order and hold its lock, the caller must release the lock if no error is returned.
+ */
+func (gs *GatewaySvc) lockOrder(ctx context.Context, dataId string) (types.OrderInfo, error) {
+	gs.locks.Lock(lockname(dataId))
+	orderInfo, err := utils.GetOrder(ctx, gs.orderDs, dataId)
+	if err != nil {
+		gs.locks.Unlock(lockname(dataId))
+		return types.OrderInfo{}, err
+	}
+	if orderInfo.DataId == "" {
+		gs.locks.Unlock(lockname(dataId))
+		return types.OrderInfo{}, types.Wrapf(types.ErrNotFound, "order %s", dataId)
+	}
+	return orderInfo, nil
+}
+
+/**
+ * check the order is still in flight on this gateway and on chain, the chain order is nil if the order isn't submitted yet.
+ */
+func (gs *GatewaySvc) checkInflight(ctx context.Context, orderInfo types.OrderInfo) (*ordertypes.FullOrder, error) {
+	switch orderInfo.State {
+	case types.OrderStateComplete, types.OrderStateExpired, types.OrderStateCancelled:
+		return nil, types.Wrapf(types.ErrInvalidOrderState, "order %s is %s", orderInfo.DataId, orderInfo.State)
+	}
+	if orderInfo.OrderId == 0 {
+		return nil, nil
+	}
+
+	order, err := gs.chainSvc.GetOrder(ctx, orderInfo.OrderId)
+	if err != nil {
+		return nil, err
+	}
+	if order.Provider != gs.nodeAddress {
+		return nil, types.Wrapf(types.ErrInvalidProvider, "order %d provider is %s, not %s", order.Id, order.Provider, gs.nodeAddress)
+	}
+	switch order.Status {
+	case ordertypes.OrderPending, ordertypes.OrderInProgress, ordertypes.OrderDataReady:
+		return order, nil
+	}
+	return nil, types.Wrapf(types.ErrInvalidOrderState, "order %d is not in progress on chain, status %d", order.Id, order.Status)
+}
+
 func (gs *GatewaySvc) getPendingOrders(ctx context.Context) ([]types.OrderInfo, error) {
 	orderKeys, err := gs.getOrderKeys(ctx)
 	if err != nil {
-		return nil, err // wsevent way to receive shard assign
-		//if err := ss.chainSvc.SubscribeShardTask(ctx, ss.nodeAddress, ss.taskChan); err != nil {
-		//	return nil, err
-		//}
+		return nil, err
 	}
 
 	var orders []types.OrderInfo
@@ -927,8 +1684,99 @@ func (gs *GatewaySvc) getPendingOrders(ctx context.Context) ([]types.OrderInfo,
 	return orders, nil
 }
 
-func lockname(orderId uint64) string {
-	return fmt.Sprintf("lk-order-%d", orderId)
+// stageErasureShards encodes the order content into reed-solomon shards when erasure coding is enabled,
+// each storage provider is assigned a shard instead of a full replica.
+func (gs *GatewaySvc) stageErasureShards(orderInfo *types.OrderInfo, content []byte) error {
+	dataShards := gs.cfg.Gateway.ErasureDataShards
+	if dataShards <= 0 {
+		return nil
+	}
+	if len(orderInfo.Shards) <= dataShards {
+		log.Warnf("order %d has %d shards, not enough for %d data shards, store replicas instead",
+			orderInfo.OrderId, len(orderInfo.Shards), dataShards)
+		return nil
+	}
+
+	sps := make([]string, 0, len(orderInfo.Shards))
+	for sp := range orderInfo.Shards {
+		sps = append(sps, sp)
+	}
+	sort.Slice(sps, func(i, j int) bool {
+		return orderInfo.Shards[sps[i]].ShardId < orderInfo.Shards[sps[j]].ShardId
+	})
+
+	cids, err := StageErasureShards(gs.stagingPath, orderInfo.Owner, orderInfo.DataId, content, dataShards, len(sps)-dataShards)
+	if err != nil {
+		return err
+	}
+	for i, sp := range sps {
+		shard := orderInfo.Shards[sp]
+		shard.Cid = cids[i]
+		orderInfo.Shards[sp] = shard
+	}
+	log.Infof("order %d content is erasure coded into %d data shards and %d parity shards",
+		orderInfo.OrderId, dataShards, len(sps)-dataShards)
+	return nil
+}
+
+// reuseErasureShards keeps the erasure shard cids of the order after shards are re-assigned,
+// the shards of timeout providers are handed over to the newly assigned ones.
+func reuseErasureShards(orderInfo types.OrderInfo, newShards map[string]types.OrderShardInfo) {
+	pending := make(map[string]struct{})
+	for _, shard := range orderInfo.Shards {
+		if shard.Cid != orderInfo.Cid.String() {
+			pending[shard.Cid] = struct{}{}
+		}
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	for sp, shard := range newShards {
+		if old, ok := orderInfo.Shards[sp]; ok && shard.State != types.ShardStateCompleted {
+			shard.Cid = old.Cid
+			newShards[sp] = shard
+		}
+		delete(pending, newShards[sp].Cid)
+	}
+	for sp, shard := range newShards {
+		if len(pending) == 0 {
+			break
+		}
+		if shard.Cid != orderInfo.Cid.String() {
+			continue
+		}
+		for c := range pending {
+			shard.Cid = c
+			newShards[sp] = shard
+			delete(pending, c)
+			break
+		}
+	}
+}
+
+func (gs *GatewaySvc) unstageOrder(orderInfo types.OrderInfo) {
+	log.Debugf("unstage shard %s/%s/%v", gs.stagingPath, orderInfo.Owner, orderInfo.Cid)
+	err := UnstageShard(gs.stagingPath, orderInfo.Owner, orderInfo.Cid.String(), orderInfo.DataId)
+	if err != nil {
+		log.Warnf("unstage shard error: %v", err)
+	}
+
+	for _, shard := range orderInfo.Shards {
+		if shard.Cid == orderInfo.Cid.String() {
+			continue
+		}
+		log.Debugf("unstage erasure shard %s/%s/%s", gs.stagingPath, orderInfo.Owner, shard.Cid)
+		err = UnstageShard(gs.stagingPath, orderInfo.Owner, shard.Cid, orderInfo.DataId)
+		if err != nil && !os.IsNotExist(err) {
+			log.Warnf("unstage erasure shard error: %v", err)
+		}
+	}
+}
+
+// orders are locked by data id, the order id isn't known until the order is submitted to chain
+func lockname(dataId string) string {
+	return fmt.Sprintf("lk-order-%s", dataId)
 }
 
 func (gs *GatewaySvc) checkTimeout(ctx context.Context) {
@@ -941,7 +1789,7 @@ func (gs *GatewaySvc) checkTimeout(ctx context.Context) {
 		for _, orderKey := range orderIdx.Alls {
 			if _, ok := metaSet[orderKey.DataId]; !ok {
 				metaSet[orderKey.DataId] = struct{}{}
-				orderInfo, err := gs.OrderStatus(ctx, orderKey.DataId)
+				orderInfo, err := utils.GetOrder(ctx, gs.orderDs, orderKey.DataId)
 				if err == nil {
 					meta, err := gs.chainSvc.GetMeta(ctx, orderKey.DataId)
 					if err == nil && meta.Metadata.Status != modeltypes.MetaComplete {
@@ -993,6 +1841,11 @@ func (gs *GatewaySvc) checkTimeout(ctx context.Context) {
 						continue
 					}
 
+					stored, err := utils.GetOrder(ctx, gs.orderDs, orderInfo.DataId)
+					if err == nil && stored.State == types.OrderStateCancelled {
+						continue
+					}
+
 					newShards := make(map[string]types.OrderShardInfo)
 					for sp, shard := range order.Shards {
 						if shard.Status == ordertypes.ShardTimeout {
@@ -1011,6 +1864,8 @@ func (gs *GatewaySvc) checkTimeout(ctx context.Context) {
 						}
 						newShards[sp] = newShard
 					}
+					gs.recordTimeout(ctx, orderInfo, order, latestHeight)
+					reuseErasureShards(orderInfo, newShards)
 					orderInfo.Shards = newShards
 					orderInfo.ExpireHeight = orderInfo.ExpireHeight + order.Timeout
 					orderInfo.Tries = 0
@@ -1035,6 +1890,38 @@ func (gs *GatewaySvc) checkTimeout(ctx context.Context) {
 	}
 }
 
+/**
+ * record the timeout shards in the order history, the order itself is saved once it's processed again.
+ */
+func (gs *GatewaySvc) recordTimeout(ctx context.Context, orderInfo types.OrderInfo, order *ordertypes.FullOrder, height uint64) {
+	now := time.Now().UnixMilli()
+	message := fmt.Sprintf("shards timeout at height %d", height)
+	events := []types.OrderEvent{{
+		Kind:    types.OrderEventOrder,
+		From:    orderInfo.State.String(),
+		To:      types.OrderStateReady.String(),
+		Message: message,
+		Time:    now,
+	}}
+	for sp, shard := range order.Shards {
+		if shard.Status != ordertypes.ShardTimeout {
+			continue
+		}
+		events = append(events, types.OrderEvent{
+			Kind:    types.OrderEventOrderShard,
+			Subject: sp,
+			From:    string(orderInfo.Shards[sp].State),
+			To:      "timeout",
+			Message: message,
+			Time:    now,
+		})
+	}
+	err := utils.AppendOrderEvents(ctx, gs.orderDs, orderInfo.DataId, orderInfo.OrderId, events...)
+	if err != nil {
+		log.Warnf("record timeout of order %d error: %v", orderInfo.OrderId, err)
+	}
+}
+
 func allShardsCompleted(order *ordertypes.FullOrder) bool {
 
 	isComplete := true
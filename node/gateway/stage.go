@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 
 	"github.com/SaoNetwork/sao-node/types"
+	"github.com/SaoNetwork/sao-node/utils"
 
 	saotypes "github.com/SaoNetwork/sao/x/sao/types"
 	"github.com/ipfs/go-cid"
@@ -57,6 +58,34 @@ func GetStagedShard(basedir string, creator string, cid cid.Cid, dataId string)
 	}
 }
 
+func StagedShardSize(basedir string, creator string, cid string, dataId string) (uint64, error) {
+	path, err := homedir.Expand(basedir)
+	if err != nil {
+		return 0, types.Wrapf(types.ErrInvalidPath, "%s", basedir)
+	}
+
+	filename := cid + "-" + dataId
+	info, err := os.Stat(filepath.Join(path, creator, filename))
+	if err != nil {
+		return 0, types.Wrap(types.ErrReadFileFailed, err)
+	}
+	return uint64(info.Size()), nil
+}
+
+func OpenStagedShard(basedir string, creator string, cid cid.Cid, dataId string) (*os.File, error) {
+	path, err := homedir.Expand(basedir)
+	if err != nil {
+		return nil, types.Wrapf(types.ErrInvalidPath, "%s", basedir)
+	}
+
+	filename := cid.String() + "-" + dataId
+	file, err := os.Open(filepath.Join(path, creator, filename))
+	if err != nil {
+		return nil, types.Wrap(types.ErrOpenFileFailed, err)
+	}
+	return file, nil
+}
+
 func UnstageShard(basedir string, creator string, cid string, dataId string) error {
 	path, err := homedir.Expand(basedir)
 	if err != nil {
@@ -65,3 +94,37 @@ func UnstageShard(basedir string, creator string, cid string, dataId string) err
 
 	return os.Remove(filepath.Join(path, creator, fmt.Sprintf("%s-%s", cid, dataId)))
 }
+
+func StageErasureShards(basedir string, owner string, dataId string, content []byte, dataShards int, parityShards int) ([]string, error) {
+	shards, err := utils.ErasureEncode(content, dataShards, parityShards)
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := homedir.Expand(basedir)
+	if err != nil {
+		return nil, types.Wrapf(types.ErrInvalidPath, "%s", basedir)
+	}
+
+	err = os.MkdirAll(filepath.Join(path, owner), 0755)
+	if err != nil && !os.IsExist(err) {
+		return nil, types.Wrap(types.ErrCreateDirFailed, err)
+	}
+
+	var cids []string
+	for _, shard := range shards {
+		shardCid, err := utils.CalculateCid(shard)
+		if err != nil {
+			return nil, err
+		}
+
+		filename := fmt.Sprintf("%s-%s", shardCid, dataId)
+		log.Debugf("staging erasure shard: %s/%s/%s", path, owner, filename)
+		err = os.WriteFile(filepath.Join(path, owner, filename), shard, 0644)
+		if err != nil {
+			return nil, types.Wrap(types.ErrWriteFileFailed, err)
+		}
+		cids = append(cids, shardCid.String())
+	}
+	return cids, nil
+}
@@ -0,0 +1,536 @@
+package gc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SaoNetwork/sao-node/chain"
+	"github.com/SaoNetwork/sao-node/node/config"
+	"github.com/SaoNetwork/sao-node/types"
+	"github.com/SaoNetwork/sao-node/utils"
+
+	ordertypes "github.com/SaoNetwork/sao/x/order/types"
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+	logging "github.com/ipfs/go-log/v2"
+	"github.com/mitchellh/go-homedir"
+)
+
+var log = logging.Logger("gc")
+
+// staging sub directories of the storage module, files are named <orderId>-<cid>
+var shardPartDirs = map[string]bool{
+	"shard-parts": true,
+	"migrate":     true,
+}
+
+type GcSvc struct {
+	ctx         context.Context
+	cancel      context.CancelFunc
+	chainSvc    chain.ChainSvcApi
+	stagingPath string
+	cachePath   string
+	orderDs     datastore.Batching
+	transportDs datastore.Batching
+	cfg         *config.Gc
+
+	// only one collection runs at a time
+	lk sync.Mutex
+	wg sync.WaitGroup
+}
+
+func NewGcSvc(
+	ctx context.Context,
+	chainSvc chain.ChainSvcApi,
+	stagingPath string,
+	cachePath string,
+	orderDs datastore.Batching,
+	transportDs datastore.Batching,
+	cfg *config.Gc,
+) *GcSvc {
+	ctx, cancel := context.WithCancel(ctx)
+	return &GcSvc{
+		ctx:         ctx,
+		cancel:      cancel,
+		chainSvc:    chainSvc,
+		stagingPath: stagingPath,
+		cachePath:   cachePath,
+		orderDs:     orderDs,
+		transportDs: transportDs,
+		cfg:         cfg,
+	}
+}
+
+/**
+ * collect stale files every Interval until stopped.
+ */
+func (g *GcSvc) Start() {
+	if g.cfg.Interval <= 0 {
+		log.Info("gc interval is not set, scheduled collection is disabled")
+		return
+	}
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+
+		ticker := time.NewTicker(g.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				report, err := g.Collect(g.ctx, false)
+				if err != nil {
+					log.Errorf("gc error: %v", err)
+					continue
+				}
+				if len(report.Entries) > 0 {
+					log.Infof("gc removed %d stale entries, reclaimed %d bytes", len(report.Entries), report.Reclaimed)
+				}
+			case <-g.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (g *GcSvc) Stop(_ context.Context) error {
+	log.Info("stopping gc service...")
+	g.cancel()
+	g.wg.Wait()
+	return nil
+}
+
+/**
+ * find stale staging files, transport uploads and cache files, they are removed unless dryRun is set.
+ */
+func (g *GcSvc) Collect(ctx context.Context, dryRun bool) (types.GcReport, error) {
+	g.lk.Lock()
+	defer g.lk.Unlock()
+
+	report := types.GcReport{
+		DryRun:  dryRun,
+		StartAt: time.Now().Unix(),
+	}
+
+	c := &collector{
+		GcSvc:        g,
+		orders:       make(map[string]types.OrderInfo),
+		ordersByCid:  make(map[string][]types.OrderInfo),
+		chainStatus:  make(map[uint64]int32),
+		now:          time.Now(),
+		dryRun:       dryRun,
+		stagingFound: make(map[string]bool),
+	}
+	err := c.loadOrders(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	if g.stagingPath != "" {
+		err = c.scanStaging(ctx)
+		if err != nil {
+			return report, err
+		}
+		err = c.scanUploadRecords(ctx)
+		if err != nil {
+			return report, err
+		}
+	}
+	if g.cachePath != "" && g.cfg.CacheExpiration > 0 {
+		err = c.scanCache()
+		if err != nil {
+			return report, err
+		}
+	}
+
+	report.Entries = c.entries
+	for _, entry := range c.entries {
+		if dryRun || entry.Removed {
+			report.Reclaimed += entry.Size
+		}
+	}
+	return report, nil
+}
+
+type collector struct {
+	*GcSvc
+
+	orders      map[string]types.OrderInfo
+	ordersByCid map[string][]types.OrderInfo
+	chainStatus map[uint64]int32
+	now         time.Time
+	dryRun      bool
+	entries     []types.GcEntry
+	// upload directories seen in the staging path
+	stagingFound map[string]bool
+}
+
+func (c *collector) loadOrders(ctx context.Context) error {
+	index, err := utils.GetOrderIndex(ctx, c.orderDs)
+	if err != nil {
+		return err
+	}
+	for _, key := range index.Alls {
+		order, err := utils.GetOrder(ctx, c.orderDs, key.DataId)
+		if err != nil {
+			return err
+		}
+		if order.DataId == "" {
+			continue
+		}
+		c.orders[order.DataId] = order
+		if order.Cid.Defined() {
+			c.ordersByCid[order.Cid.String()] = append(c.ordersByCid[order.Cid.String()], order)
+		}
+	}
+	return nil
+}
+
+func (c *collector) scanStaging(ctx context.Context) error {
+	root, err := homedir.Expand(c.stagingPath)
+	if err != nil {
+		return types.Wrapf(types.ErrInvalidPath, "%s", c.stagingPath)
+	}
+
+	dirs, err := os.ReadDir(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return types.Wrap(types.ErrReadFileFailed, err)
+	}
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		dirPath := filepath.Join(root, dir.Name())
+		entries, err := os.ReadDir(dirPath)
+		if err != nil {
+			log.Warnf("read %s error: %v", dirPath, err)
+			continue
+		}
+		for _, entry := range entries {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			entryPath := filepath.Join(dirPath, entry.Name())
+			if shardPartDirs[dir.Name()] {
+				c.checkShardPart(ctx, entryPath, entry)
+			} else if entry.IsDir() {
+				c.checkUpload(ctx, entryPath, entry)
+			} else {
+				c.checkStagedShard(ctx, entryPath, entry)
+			}
+		}
+	}
+	return nil
+}
+
+/**
+ * <staging>/<owner>/<cid>-<dataId> written by the gateway for its orders.
+ */
+func (c *collector) checkStagedShard(ctx context.Context, path string, entry fs.DirEntry) {
+	// cids never contain '-', data ids are uuids
+	parts := strings.SplitN(entry.Name(), "-", 2)
+	if len(parts) != 2 {
+		return
+	}
+	shardCid, dataId := parts[0], parts[1]
+
+	order, ok := c.orders[dataId]
+	if !ok {
+		if c.expired(entry) {
+			c.remove(types.GcKindStagedShard, path, "no order record")
+		}
+		return
+	}
+
+	if !orderReferences(order, shardCid) {
+		// the data model is updated, and the order of this commit is replaced
+		if c.expired(entry) {
+			c.remove(types.GcKindStagedShard, path, fmt.Sprintf("order %s is for cid %v", dataId, order.Cid))
+		}
+		return
+	}
+
+	switch order.State {
+	case types.OrderStateExpired, types.OrderStateTerminate, types.OrderStateComplete, types.OrderStateCancelled:
+		c.remove(types.GcKindStagedShard, path, fmt.Sprintf("order %s %s", dataId, order.State))
+		return
+	}
+
+	if order.OrderId > 0 {
+		status, ok := c.orderStatus(ctx, order.OrderId)
+		if ok && isFinalStatus(status) {
+			c.remove(types.GcKindStagedShard, path, fmt.Sprintf("order %d %s on chain", order.OrderId, statusString(status)))
+		}
+	}
+}
+
+/**
+ * <staging>/<peer>/<cid>/ written by the transport, the uploaded content is staged again when the model is created.
+ */
+func (c *collector) checkUpload(ctx context.Context, path string, entry fs.DirEntry) {
+	cidStr := entry.Name()
+	peer := filepath.Base(filepath.Dir(path))
+	c.stagingFound[types.FileInfoKey(peer, cidStr)] = true
+
+	orders := c.ordersByCid[cidStr]
+	for _, order := range orders {
+		if order.State == types.OrderStateStaged || order.State == types.OrderStateReady {
+			return
+		}
+	}
+
+	reason := ""
+	if len(orders) > 0 {
+		reason = fmt.Sprintf("order %s %s", orders[0].DataId, orders[0].State)
+	} else if c.expired(entry) {
+		info, ok := c.uploadRecord(ctx, peer, cidStr)
+		if ok && info.ReceivedLength < info.TotalLength {
+			reason = fmt.Sprintf("incomplete upload, received %d of %d bytes", info.ReceivedLength, info.TotalLength)
+		} else {
+			reason = "no order for the uploaded content"
+		}
+	}
+	if reason == "" {
+		return
+	}
+
+	if c.remove(types.GcKindUpload, path, reason) {
+		c.removeUploadRecord(ctx, peer, cidStr)
+	}
+}
+
+/**
+ * <staging>/shard-parts|migrate/<orderId>-<cid> written by the storage module while receiving shards.
+ */
+func (c *collector) checkShardPart(ctx context.Context, path string, entry fs.DirEntry) {
+	parts := strings.SplitN(entry.Name(), "-", 2)
+	if len(parts) != 2 {
+		return
+	}
+	orderId, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return
+	}
+
+	status, ok := c.orderStatus(ctx, orderId)
+	if !ok {
+		return
+	}
+	// a completed order doesn't need the parts anymore, but the shard may be still migrating
+	if isFinalStatus(status) || (status == ordertypes.OrderCompleted && c.expired(entry)) {
+		c.remove(types.GcKindShardPart, path, fmt.Sprintf("order %d %s on chain", orderId, statusString(status)))
+	}
+}
+
+/**
+ * ReceivedFileInfo records left without the upload directory.
+ */
+func (c *collector) scanUploadRecords(ctx context.Context) error {
+	results, err := c.transportDs.Query(ctx, query.Query{Prefix: types.FILE_INFO_NAMESPACE})
+	if err != nil {
+		return err
+	}
+	defer results.Close()
+
+	for result := range results.Next() {
+		if result.Error != nil {
+			return result.Error
+		}
+		if c.stagingFound[strings.TrimPrefix(result.Key, "/")] {
+			continue
+		}
+
+		var info types.ReceivedFileInfo
+		err := json.Unmarshal(result.Value, &info)
+		if err == nil {
+			path, err := homedir.Expand(info.Path)
+			if err == nil {
+				_, err = os.Stat(path)
+			}
+			if err == nil || !os.IsNotExist(err) {
+				continue
+			}
+		}
+
+		entry := types.GcEntry{
+			Kind:   types.GcKindUploadRecord,
+			Path:   result.Key,
+			Reason: "upload content is missing",
+		}
+		if !c.dryRun {
+			err := c.transportDs.Delete(ctx, datastore.NewKey(result.Key))
+			if err != nil {
+				entry.Error = err.Error()
+			} else {
+				entry.Removed = true
+			}
+		}
+		c.entries = append(c.entries, entry)
+	}
+	return nil
+}
+
+/**
+ * <http-files>/<dataId> written when large content is fetched by the gateway.
+ */
+func (c *collector) scanCache() error {
+	root, err := homedir.Expand(c.cachePath)
+	if err != nil {
+		return types.Wrapf(types.ErrInvalidPath, "%s", c.cachePath)
+	}
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return types.Wrap(types.ErrReadFileFailed, err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if c.now.Sub(info.ModTime()) > c.cfg.CacheExpiration {
+			c.remove(types.GcKindCache, filepath.Join(root, entry.Name()), fmt.Sprintf("cached at %s", info.ModTime().Format(time.RFC3339)))
+		}
+	}
+	return nil
+}
+
+/**
+ * record the path in the report and remove it unless in a dry run, returns false if removing fails.
+ */
+func (c *collector) remove(kind types.GcKind, path string, reason string) bool {
+	entry := types.GcEntry{
+		Kind:   kind,
+		Path:   path,
+		Size:   pathSize(path),
+		Reason: reason,
+	}
+	ok := true
+	if !c.dryRun {
+		err := os.RemoveAll(path)
+		if err != nil {
+			entry.Error = err.Error()
+			ok = false
+		} else {
+			entry.Removed = true
+		}
+	}
+	log.Debugf("gc %s %s: %s, dry run: %v", kind, path, reason, c.dryRun)
+	c.entries = append(c.entries, entry)
+	return ok
+}
+
+func (c *collector) uploadRecord(ctx context.Context, peer string, cidStr string) (types.ReceivedFileInfo, bool) {
+	var info types.ReceivedFileInfo
+	data, err := c.transportDs.Get(ctx, datastore.NewKey(types.FileInfoKey(peer, cidStr)))
+	if err != nil {
+		return info, false
+	}
+	err = json.Unmarshal(data, &info)
+	return info, err == nil
+}
+
+func (c *collector) removeUploadRecord(ctx context.Context, peer string, cidStr string) {
+	if c.dryRun {
+		return
+	}
+	err := c.transportDs.Delete(ctx, datastore.NewKey(types.FileInfoKey(peer, cidStr)))
+	if err != nil {
+		log.Warnf("delete upload record %s of %s error: %v", cidStr, peer, err)
+	}
+}
+
+/**
+ * order status on chain, false if the order can't be queried.
+ */
+func (c *collector) orderStatus(ctx context.Context, orderId uint64) (int32, bool) {
+	if status, ok := c.chainStatus[orderId]; ok {
+		return status, true
+	}
+	order, err := c.chainSvc.GetOrder(ctx, orderId)
+	if err != nil {
+		log.Warnf("get order %d error: %v", orderId, err)
+		return 0, false
+	}
+	c.chainStatus[orderId] = order.Status
+	return order.Status, true
+}
+
+// files without any order state are kept for GracePeriod, they may be written just now
+func (c *collector) expired(entry fs.DirEntry) bool {
+	info, err := entry.Info()
+	if err != nil {
+		return false
+	}
+	return c.now.Sub(info.ModTime()) > c.cfg.GracePeriod
+}
+
+func orderReferences(order types.OrderInfo, cidStr string) bool {
+	if order.Cid.String() == cidStr {
+		return true
+	}
+	for _, shard := range order.Shards {
+		if shard.Cid == cidStr {
+			return true
+		}
+	}
+	return false
+}
+
+func isFinalStatus(status int32) bool {
+	switch status {
+	case ordertypes.OrderCanceled, ordertypes.OrderExpired, ordertypes.OrderTerminated:
+		return true
+	}
+	return false
+}
+
+func statusString(status int32) string {
+	switch status {
+	case ordertypes.OrderCompleted:
+		return "completed"
+	case ordertypes.OrderCanceled:
+		return "canceled"
+	case ordertypes.OrderExpired:
+		return "expired"
+	case ordertypes.OrderTerminated:
+		return "terminated"
+	}
+	return fmt.Sprintf("status %d", status)
+}
+
+func pathSize(path string) int64 {
+	var size int64
+	_ = filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if !d.IsDir() {
+			info, err := d.Info()
+			if err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
@@ -0,0 +1,145 @@
+package gc
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SaoNetwork/sao-node/chain"
+	"github.com/SaoNetwork/sao-node/node/config"
+	"github.com/SaoNetwork/sao-node/types"
+	"github.com/SaoNetwork/sao-node/utils"
+
+	ordertypes "github.com/SaoNetwork/sao/x/order/types"
+	"github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeChain struct {
+	chain.ChainSvcApi
+	orders map[uint64]int32
+}
+
+func (f *fakeChain) GetOrder(_ context.Context, orderId uint64) (*ordertypes.FullOrder, error) {
+	status, ok := f.orders[orderId]
+	if !ok {
+		return nil, types.Wrapf(types.ErrQueryOrderFailed, "order %d not found", orderId)
+	}
+	return &ordertypes.FullOrder{Id: orderId, Status: status}, nil
+}
+
+func writeFile(t *testing.T, path string, size int, old bool) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, make([]byte, size), 0644))
+	if old {
+		at := time.Now().Add(-48 * time.Hour)
+		require.NoError(t, os.Chtimes(path, at, at))
+		require.NoError(t, os.Chtimes(filepath.Dir(path), at, at))
+	}
+}
+
+func TestCollect(t *testing.T) {
+	ctx := context.Background()
+	staging := t.TempDir()
+	cache := t.TempDir()
+	orderDs := dssync.MutexWrap(datastore.NewMapDatastore())
+	transportDs := dssync.MutexWrap(datastore.NewMapDatastore())
+
+	cidA, _ := utils.CalculateCid([]byte("a"))
+	cidB, _ := utils.CalculateCid([]byte("b"))
+	require.NoError(t, utils.SaveOrder(ctx, orderDs, types.OrderInfo{DataId: "data-1", Cid: cidA, State: types.OrderStateExpired}))
+	require.NoError(t, utils.SaveOrder(ctx, orderDs, types.OrderInfo{DataId: "data-2", Cid: cidB, State: types.OrderStateStaged}))
+	require.NoError(t, utils.SaveOrder(ctx, orderDs, types.OrderInfo{DataId: "data-3", Cid: cidB, OrderId: 3, State: types.OrderStateReady}))
+
+	expired := filepath.Join(staging, "owner", cidA.String()+"-data-1")
+	staged := filepath.Join(staging, "owner", cidB.String()+"-data-2")
+	terminated := filepath.Join(staging, "owner", cidB.String()+"-data-3")
+	fresh := filepath.Join(staging, "owner", cidA.String()+"-data-4")
+	writeFile(t, expired, 10, false)
+	writeFile(t, staged, 10, false)
+	writeFile(t, terminated, 10, false)
+	writeFile(t, fresh, 10, false)
+	orphan := filepath.Join(staging, "owner2", cidA.String()+"-data-5")
+	writeFile(t, orphan, 10, true)
+
+	// an abandoned upload and a record without content
+	upload := filepath.Join(staging, "peer", "cidU")
+	writeFile(t, filepath.Join(upload, "chunk"), 20, true)
+	for _, info := range []types.ReceivedFileInfo{
+		{Cid: "cidU", TotalLength: 40, ReceivedLength: 20, Path: upload},
+		{Cid: "cidM", Path: filepath.Join(staging, "peer", "cidM")},
+	} {
+		data, err := json.Marshal(info)
+		require.NoError(t, err)
+		require.NoError(t, transportDs.Put(ctx, datastore.NewKey(types.FileInfoKey("peer", info.Cid)), data))
+	}
+
+	part := filepath.Join(staging, "shard-parts", "7-"+cidA.String())
+	activePart := filepath.Join(staging, "shard-parts", "8-"+cidA.String())
+	writeFile(t, part, 30, false)
+	writeFile(t, activePart, 30, false)
+
+	cached := filepath.Join(cache, "data-1")
+	writeFile(t, cached, 5, true)
+	writeFile(t, filepath.Join(cache, "data-2"), 5, false)
+
+	chainSvc := &fakeChain{orders: map[uint64]int32{
+		3: ordertypes.OrderTerminated,
+		7: ordertypes.OrderCanceled,
+		8: ordertypes.OrderInProgress,
+	}}
+	cfg := &config.Gc{GracePeriod: 24 * time.Hour, CacheExpiration: 24 * time.Hour}
+	g := NewGcSvc(ctx, chainSvc, staging, cache, orderDs, transportDs, cfg)
+
+	expected := map[string]types.GcKind{
+		expired:                                 types.GcKindStagedShard,
+		terminated:                              types.GcKindStagedShard,
+		orphan:                                  types.GcKindStagedShard,
+		upload:                                  types.GcKindUpload,
+		"/" + types.FileInfoKey("peer", "cidM"): types.GcKindUploadRecord,
+		part:                                    types.GcKindShardPart,
+		cached:                                  types.GcKindCache,
+	}
+
+	report, err := g.Collect(ctx, true)
+	require.NoError(t, err)
+	require.Len(t, report.Entries, len(expected))
+	for _, entry := range report.Entries {
+		require.Equal(t, expected[entry.Path], entry.Kind, entry.Path)
+		require.False(t, entry.Removed)
+	}
+	require.Equal(t, int64(10*3+20+30+5), report.Reclaimed)
+	_, err = os.Stat(expired)
+	require.NoError(t, err)
+
+	report, err = g.Collect(ctx, false)
+	require.NoError(t, err)
+	require.Len(t, report.Entries, len(expected))
+	for _, entry := range report.Entries {
+		require.True(t, entry.Removed, entry.Path)
+	}
+	for path, kind := range expected {
+		if kind == types.GcKindUploadRecord {
+			continue
+		}
+		_, err = os.Stat(path)
+		require.True(t, os.IsNotExist(err), path)
+	}
+	for _, path := range []string{staged, fresh, activePart, filepath.Join(cache, "data-2")} {
+		_, err = os.Stat(path)
+		require.NoError(t, err, path)
+	}
+	for _, c := range []string{"cidU", "cidM"} {
+		exists, err := transportDs.Has(ctx, datastore.NewKey(types.FileInfoKey("peer", c)))
+		require.NoError(t, err)
+		require.False(t, exists)
+	}
+
+	report, err = g.Collect(ctx, false)
+	require.NoError(t, err)
+	require.Empty(t, report.Entries)
+}
@@ -2,6 +2,7 @@ package gql
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 
 	"github.com/SaoNetwork/sao-node/node/indexer/gql/types"
@@ -10,6 +11,16 @@ import (
 	"github.com/graph-gophers/graphql-go"
 )
 
+const metadataColumns = "COMMITID, DID, CID, DATAID, ALIAS, PLAT, VER, SIZE, EXPIRATION, READER, WRITER"
+
+var metadataOrderColumns = map[string]string{
+	"COMMIT_ID":  "COMMITID",
+	"DATA_ID":    "DATAID",
+	"ALIAS":      "ALIAS",
+	"SIZE":       "SIZE",
+	"EXPIRATION": "EXPIRATION",
+}
+
 type metadata struct {
 	CommitId   string
 	Did        string
@@ -28,6 +39,63 @@ type metadataList struct {
 	TotalCount int32
 	Metadatas  []*metadata
 	More       bool
+	Cursor     string
+}
+
+type metadataFilter struct {
+	Did            *string
+	Platform       *string
+	Alias          *string
+	DataId         *string
+	ExpirationFrom *types.Uint64
+	ExpirationTo   *types.Uint64
+}
+
+type metadatasArgs struct {
+	Filter  *metadataFilter
+	First   *int32
+	After   *string
+	OrderBy string
+	Order   string
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanMetadata(row rowScanner) (*metadata, error) {
+	var m metadata
+	err := row.Scan(&m.CommitId, &m.Did, &m.Cid, &m.DataId, &m.Alias, &m.GroupId, &m.Version, &m.Size, &m.Expiration, &m.Readers, &m.Writers)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (f *metadataFilter) query() sqlQuery {
+	var q sqlQuery
+	if f == nil {
+		return q
+	}
+	if f.Did != nil {
+		q.where("DID=?", *f.Did)
+	}
+	if f.Platform != nil {
+		q.where("PLAT=?", *f.Platform)
+	}
+	if f.Alias != nil {
+		q.where("ALIAS=?", *f.Alias)
+	}
+	if f.DataId != nil {
+		q.where("DATAID=?", *f.DataId)
+	}
+	if f.ExpirationFrom != nil {
+		q.where("EXPIRATION>=?", uint64(*f.ExpirationFrom))
+	}
+	if f.ExpirationTo != nil {
+		q.where("EXPIRATION<=?", uint64(*f.ExpirationTo))
+	}
+	return q
 }
 
 // query: metadata(id) Metadata
@@ -38,35 +106,35 @@ func (r *resolver) Metadata(ctx context.Context, args struct{ ID graphql.ID }) (
 		return nil, fmt.Errorf("parsing graphql ID '%s' as UUID: %w", args.ID, err)
 	}
 
-	row := r.indexSvc.Db.QueryRowContext(ctx, "SELECT COMMITID, DID, CID, DATAID, ALIAS, PLAT, VER, SIZE, EXPIRATION, READER, WRITER FROM METADATA WHERE COMMITID="+commitId.String())
-	var CommitId string
-	var Did string
-	var DataId string
-	var Cid string
-	var Alias string
-	var GroupId string
-	var Version string
-	var Size int32
-	var Expiration types.Uint64
-	var Readers string
-	var Writers string
-	err = row.Scan(&CommitId, &Did, &Cid, &DataId, &Alias, &GroupId, &Version, &Size, &Expiration, &Readers, &Writers)
+	row := r.indexSvc.Db.QueryRowContext(ctx, "SELECT "+metadataColumns+" FROM METADATA WHERE COMMITID=?", commitId.String())
+	m, err := scanMetadata(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return m, err
+}
+
+// query: metadatas(filter, first, after, orderBy, order) MetadataList
+func (r *resolver) Metadatas(ctx context.Context, args metadatasArgs) (*metadataList, error) {
+	p, err := parsePage(args.First, args.After)
+	if err != nil {
+		return nil, err
+	}
+	order, err := orderClause(metadataOrderColumns, args.OrderBy, args.Order, "COMMITID")
 	if err != nil {
 		return nil, err
 	}
 
-	return &metadata{
-		CommitId, Did, Cid, DataId, Alias, GroupId, Version, Size, Expiration, Readers, Writers,
-	}, nil
-}
+	q := args.Filter.query()
 
-// query: metadatas(cursor, offset, limit) MetaList
-func (r *resolver) Metadatas(ctx context.Context, args struct{ Query graphql.NullString }) (*metadataList, error) {
-	queryStr := "SELECT COMMITID, DID, CID, DATAID, ALIAS, PLAT, VER, SIZE, EXPIRATION, READER, WRITER FROM METADATA "
-	if args.Query.Set && args.Query.Value != nil {
-		queryStr = queryStr + *args.Query.Value
+	var total int
+	err = r.indexSvc.Db.QueryRowContext(ctx, "SELECT COUNT(*) FROM METADATA"+q.whereClause(), q.args...).Scan(&total)
+	if err != nil {
+		return nil, err
 	}
-	rows, err := r.indexSvc.Db.QueryContext(ctx, queryStr)
+
+	rows, err := r.indexSvc.Db.QueryContext(ctx, "SELECT "+metadataColumns+" FROM METADATA"+q.whereClause()+order+" LIMIT ? OFFSET ?",
+		append(q.args, p.limit, p.offset)...)
 	if err != nil {
 		return nil, err
 	}
@@ -74,40 +142,25 @@ func (r *resolver) Metadatas(ctx context.Context, args struct{ Query graphql.Nul
 
 	metadatas := make([]*metadata, 0)
 	for rows.Next() {
-		var CommitId string
-		var Did string
-		var Cid string
-		var DataId string
-		var Alias string
-		var GroupId string
-		var Version string
-		var Size int32
-		var Expiration types.Uint64
-		var Readers string
-		var Writers string
-		err = rows.Scan(&CommitId, &Did, &Cid, &DataId, &Alias, &GroupId, &Version, &Size, &Expiration, &Readers, &Writers)
+		m, err := scanMetadata(rows)
 		if err != nil {
 			return nil, err
 		}
-		metadatas = append(metadatas, &metadata{
-			CommitId, Did, Cid, DataId, Alias, GroupId, Version, Size, Expiration, Readers, Writers,
-		})
+		metadatas = append(metadatas, m)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 
+	more, cursor := p.next(len(metadatas), total)
 	return &metadataList{
-		TotalCount: int32(len(metadatas)),
+		TotalCount: int32(total),
 		Metadatas:  metadatas,
-		More:       false,
+		More:       more,
+		Cursor:     cursor,
 	}, nil
 }
 
-func (r *resolver) MetadataCount(ctx context.Context) (int32, error) {
-	return 0, nil
-}
-
 func (m *metadata) ID() graphql.ID {
 	return graphql.ID(m.CommitId)
 }
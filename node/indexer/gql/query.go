@@ -0,0 +1,107 @@
+package gql
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	DEFAULT_PAGE_SIZE = 20
+	MAX_PAGE_SIZE     = 100
+)
+
+const cursorPrefix = "offset:"
+
+/**
+ * sqlQuery collects the conditions of a query, values are always bound as statement parameters.
+ */
+type sqlQuery struct {
+	conds []string
+	args  []interface{}
+}
+
+func (q *sqlQuery) where(cond string, arg interface{}) {
+	q.conds = append(q.conds, cond)
+	q.args = append(q.args, arg)
+}
+
+/**
+ * a copy of the query with one more condition, the query itself is left unchanged.
+ */
+func (q sqlQuery) with(cond string, arg interface{}) sqlQuery {
+	return sqlQuery{
+		conds: append([]string{cond}, q.conds...),
+		args:  append([]interface{}{arg}, q.args...),
+	}
+}
+
+func (q *sqlQuery) whereClause() string {
+	if len(q.conds) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(q.conds, " AND ")
+}
+
+/**
+ * the ORDER BY clause of the column of the sort field, the primary key breaks ties so that pages are stable.
+ * fields and orders are enums validated by the schema, they're still looked up instead of being interpolated.
+ */
+func orderClause(columns map[string]string, field string, order string, primaryKey string) (string, error) {
+	column, ok := columns[field]
+	if !ok {
+		return "", fmt.Errorf("unsupported order field %s", field)
+	}
+	direction := "ASC"
+	switch order {
+	case "", "ASC":
+	case "DESC":
+		direction = "DESC"
+	default:
+		return "", fmt.Errorf("unsupported sort order %s", order)
+	}
+
+	clause := " ORDER BY " + column + " " + direction
+	if column != primaryKey {
+		clause += ", " + primaryKey + " " + direction
+	}
+	return clause, nil
+}
+
+type page struct {
+	offset int
+	limit  int
+}
+
+func parsePage(first *int32, after *string) (page, error) {
+	p := page{limit: DEFAULT_PAGE_SIZE}
+	if first != nil {
+		if *first <= 0 || *first > MAX_PAGE_SIZE {
+			return p, fmt.Errorf("first should be in [1, %d]", MAX_PAGE_SIZE)
+		}
+		p.limit = int(*first)
+	}
+	if after != nil && *after != "" {
+		decoded, err := base64.StdEncoding.DecodeString(*after)
+		if err != nil || !strings.HasPrefix(string(decoded), cursorPrefix) {
+			return p, fmt.Errorf("invalid cursor %s", *after)
+		}
+		p.offset, err = strconv.Atoi(strings.TrimPrefix(string(decoded), cursorPrefix))
+		if err != nil || p.offset < 0 {
+			return p, fmt.Errorf("invalid cursor %s", *after)
+		}
+	}
+	return p, nil
+}
+
+/**
+ * whether there are more records after the fetched ones, and the cursor of the next page.
+ */
+func (p page) next(fetched int, total int) (bool, string) {
+	end := p.offset + fetched
+	if end >= total {
+		return false, ""
+	}
+	return true, base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s%d", cursorPrefix, end)))
+}
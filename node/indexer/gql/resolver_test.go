@@ -0,0 +1,112 @@
+package gql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/SaoNetwork/sao-node/node/indexer"
+	"github.com/SaoNetwork/sao-node/node/indexer/jobs"
+
+	"github.com/google/uuid"
+	"github.com/graph-gophers/graphql-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueries(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "indexer.db"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	// the job builders create the tables
+	jobs.BuildMetadataIndexJob(ctx, nil, db, "", nil)
+	jobs.BuildSpShardIndexJob(ctx, nil, db, "", nil)
+
+	commitIds := make([]string, 5)
+	for i := range commitIds {
+		commitIds[i] = uuid.New().String()
+		did := "did:key:a"
+		if i%2 == 1 {
+			did = "did:key:b"
+		}
+		_, err = db.Exec("INSERT INTO METADATA (COMMITID, DID, CID, DATAID, ALIAS, PLAT, VER, SIZE, EXPIRATION, READER, WRITER) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+			commitIds[i], did, "cid", fmt.Sprintf("data-%d", i), fmt.Sprintf("alias-%d", i), "plat", "v1", 10*i, 100+i, "", "")
+		require.NoError(t, err)
+		_, err = db.Exec("INSERT INTO SP_SHARD (SHARDID, ORDERID, SP, CID) VALUES (?, ?, ?, ?)", i+1, i%2, "sp", "cid")
+		require.NoError(t, err)
+	}
+
+	schema, err := graphql.ParseSchema(schemaGraqhql, &resolver{&indexer.IndexSvc{Db: db}}, graphql.UseFieldResolvers())
+	require.NoError(t, err)
+
+	exec := func(query string, variables map[string]interface{}, result interface{}) {
+		resp := schema.Exec(ctx, query, "", variables)
+		require.Empty(t, resp.Errors)
+		require.NoError(t, json.Unmarshal(resp.Data, result))
+	}
+
+	type metadataList struct {
+		Metadatas struct {
+			TotalCount int
+			More       bool
+			Cursor     string
+			Metadatas  []struct{ Alias string }
+		}
+	}
+	query := `query($after: String, $did: String) {
+		metadatas(filter: {did: $did, expirationFrom: 101}, first: 1, after: $after, orderBy: SIZE, order: DESC) {
+			totalCount more cursor Metadatas { Alias }
+		}
+	}`
+
+	// did:key:a owns alias-0, alias-2 and alias-4, the expiration excludes alias-0
+	var list metadataList
+	var aliases []string
+	after := ""
+	for {
+		exec(query, map[string]interface{}{"did": "did:key:a", "after": after}, &list)
+		require.Equal(t, 2, list.Metadatas.TotalCount)
+		for _, m := range list.Metadatas.Metadatas {
+			aliases = append(aliases, m.Alias)
+		}
+		if !list.Metadatas.More {
+			require.Empty(t, list.Metadatas.Cursor)
+			break
+		}
+		after = list.Metadatas.Cursor
+	}
+	require.Equal(t, []string{"alias-4", "alias-2"}, aliases)
+
+	// values are bound instead of being appended to the statement
+	exec(query, map[string]interface{}{"did": "x' OR 1=1 --"}, &list)
+	require.Equal(t, 0, list.Metadatas.TotalCount)
+
+	var one struct{ Metadata struct{ DataId string } }
+	exec(`query($id: ID!) { metadata(id: $id) { DataId } }`, map[string]interface{}{"id": commitIds[3]}, &one)
+	require.Equal(t, "data-3", one.Metadata.DataId)
+
+	var shards struct {
+		Shards struct {
+			TotalCount int
+			More       bool
+			Shards     []struct{ ID string }
+		}
+	}
+	exec(`{ shards(filter: {orderId: 1}, orderBy: SHARD_ID, order: DESC) { totalCount more Shards { ID } } }`, nil, &shards)
+	require.Equal(t, 2, shards.Shards.TotalCount)
+	require.False(t, shards.Shards.More)
+	require.Equal(t, "4", shards.Shards.Shards[0].ID)
+
+	var s struct {
+		Shard struct{ OrderId json.RawMessage }
+	}
+	exec(`{ shard(id: "3") { OrderId } }`, nil, &s)
+	require.NotEmpty(t, s.Shard.OrderId)
+
+	resp := schema.Exec(ctx, `{ shards(first: 1000) { totalCount } }`, "", nil)
+	require.NotEmpty(t, resp.Errors)
+}
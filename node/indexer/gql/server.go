@@ -46,6 +46,8 @@ func (s *Server) Start(ctx context.Context) error {
 	s.srv = &http.Server{Addr: s.listenAddr, Handler: mux}
 	log.Infof("graphql server listening on %s", s.listenAddr)
 	mux.Handle("/graphql/query", &corsHandler{queryHandler})
+	// subscriptions over websocket, the endpoint graphiql connects to
+	mux.Handle("/graphql", newWsHandler(schema))
 
 	s.wg.Add(1)
 	go func() {
@@ -2,15 +2,22 @@ package gql
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"strconv"
 
 	"github.com/SaoNetwork/sao-node/node/indexer/gql/types"
-	"github.com/SaoNetwork/sao-node/utils"
 
-	"github.com/google/uuid"
 	"github.com/graph-gophers/graphql-go"
 )
 
+const shardColumns = "SHARDID, ORDERID, SP, CID"
+
+var shardOrderColumns = map[string]string{
+	"SHARD_ID": "SHARDID",
+	"ORDER_ID": "ORDERID",
+}
+
 type shard struct {
 	ShardId types.Uint64
 	OrderId types.Uint64
@@ -22,38 +29,85 @@ type shardList struct {
 	TotalCount int32
 	Shards     []*shard
 	More       bool
+	Cursor     string
+}
+
+type shardFilter struct {
+	Sp      *string
+	OrderId *types.Uint64
+	Cid     *string
+}
+
+type shardsArgs struct {
+	Filter  *shardFilter
+	First   *int32
+	After   *string
+	OrderBy string
+	Order   string
+}
+
+func scanShard(row rowScanner) (*shard, error) {
+	var s shard
+	err := row.Scan(&s.ShardId, &s.OrderId, &s.Sp, &s.Cid)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (f *shardFilter) query() sqlQuery {
+	var q sqlQuery
+	if f == nil {
+		return q
+	}
+	if f.Sp != nil {
+		q.where("SP=?", *f.Sp)
+	}
+	if f.OrderId != nil {
+		q.where("ORDERID=?", uint64(*f.OrderId))
+	}
+	if f.Cid != nil {
+		q.where("CID=?", *f.Cid)
+	}
+	return q
 }
 
 // query: shard(id) Shard
 func (r *resolver) Shard(ctx context.Context, args struct{ ID graphql.ID }) (*shard, error) {
-	var shardId uuid.UUID
-	err := shardId.UnmarshalText([]byte(args.ID))
+	shardId, err := strconv.ParseUint(string(args.ID), 10, 64)
 	if err != nil {
-		return nil, fmt.Errorf("parsing graphql ID '%s' as UUID: %w", args.ID, err)
+		return nil, fmt.Errorf("parsing graphql ID '%s' as shard id: %w", args.ID, err)
+	}
+
+	row := r.indexSvc.Db.QueryRowContext(ctx, "SELECT "+shardColumns+" FROM SP_SHARD WHERE SHARDID=?", shardId)
+	s, err := scanShard(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
 	}
+	return s, err
+}
 
-	row := r.indexSvc.Db.QueryRowContext(ctx, "SELECT SHARDID, ORDERID, SP, CID WHERE COMMITID="+shardId.String())
-	var ShardId types.Uint64
-	var OrderId types.Uint64
-	var Sp string
-	var Cid string
-	err = row.Scan(&ShardId, &OrderId, &Sp, &Cid)
+// query: shards(filter, first, after, orderBy, order) ShardList
+func (r *resolver) Shards(ctx context.Context, args shardsArgs) (*shardList, error) {
+	p, err := parsePage(args.First, args.After)
+	if err != nil {
+		return nil, err
+	}
+	order, err := orderClause(shardOrderColumns, args.OrderBy, args.Order, "SHARDID")
 	if err != nil {
 		return nil, err
 	}
 
-	return &shard{
-		ShardId, OrderId, Sp, Cid,
-	}, nil
-}
+	q := args.Filter.query()
 
-// query: shards(cursor, offset, limit) ShardList
-func (r *resolver) Shards(ctx context.Context, args struct{ Query graphql.NullString }) (*shardList, error) {
-	queryStr := "SELECT SHARDID, ORDERID, SP, CID WRITER FROM SP_SHARD "
-	if args.Query.Set && args.Query.Value != nil {
-		queryStr = queryStr + *args.Query.Value
+	var total int
+	err = r.indexSvc.Db.QueryRowContext(ctx, "SELECT COUNT(*) FROM SP_SHARD"+q.whereClause(), q.args...).Scan(&total)
+	if err != nil {
+		return nil, err
 	}
-	rows, err := r.indexSvc.Db.QueryContext(ctx, queryStr)
+
+	rows, err := r.indexSvc.Db.QueryContext(ctx, "SELECT "+shardColumns+" FROM SP_SHARD"+q.whereClause()+order+" LIMIT ? OFFSET ?",
+		append(q.args, p.limit, p.offset)...)
 	if err != nil {
 		return nil, err
 	}
@@ -61,33 +115,25 @@ func (r *resolver) Shards(ctx context.Context, args struct{ Query graphql.NullSt
 
 	shards := make([]*shard, 0)
 	for rows.Next() {
-		var ShardId types.Uint64
-		var OrderId types.Uint64
-		var Sp string
-		var Cid string
-		err = rows.Scan(&ShardId, &OrderId, &Sp, &Cid)
+		s, err := scanShard(rows)
 		if err != nil {
 			return nil, err
 		}
-		shards = append(shards, &shard{
-			ShardId, OrderId, Sp, Cid,
-		})
+		shards = append(shards, s)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 
+	more, cursor := p.next(len(shards), total)
 	return &shardList{
-		TotalCount: int32(len(shards)),
+		TotalCount: int32(total),
 		Shards:     shards,
-		More:       false,
+		More:       more,
+		Cursor:     cursor,
 	}, nil
 }
 
-func (r *resolver) ShardCount(ctx context.Context) (int32, error) {
-	return 0, nil
-}
-
 func (s *shard) ID() graphql.ID {
-	return graphql.ID(utils.GenerateDataId(fmt.Sprintf("%d", s.ShardId)))
+	return graphql.ID(strconv.FormatUint(uint64(s.ShardId), 10))
 }
@@ -0,0 +1,147 @@
+package gql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+
+	"github.com/SaoNetwork/sao-node/node/indexer/jobs"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+var errSubscriberTooSlow = fmt.Errorf("the subscriber is too slow to receive the changes, subscribe again")
+
+type subscriptionStateKey struct{}
+
+/**
+ * subscriptionState records why a subscription ended, the transport reports the error before completing it.
+ */
+type subscriptionState struct {
+	lk  sync.Mutex
+	err error
+}
+
+func withSubscriptionState(ctx context.Context) (context.Context, *subscriptionState) {
+	state := &subscriptionState{}
+	return context.WithValue(ctx, subscriptionStateKey{}, state), state
+}
+
+func (s *subscriptionState) setErr(err error) {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	s.err = err
+}
+
+func (s *subscriptionState) Err() error {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	return s.err
+}
+
+type metadataChange struct {
+	CommitId string
+	Deleted  bool
+	Metadata *metadata
+}
+
+func (m *metadataChange) ID() graphql.ID {
+	return graphql.ID(m.CommitId)
+}
+
+type shardChange struct {
+	ShardId string
+	Deleted bool
+	Shard   *shard
+}
+
+func (s *shardChange) ID() graphql.ID {
+	return graphql.ID(s.ShardId)
+}
+
+/**
+ * the rows of the table changed by the index jobs, the changed row is looked up with the filter of the subscription
+ * and passed to found, a deleted row is passed as a nil row. rows not matching the filter are skipped.
+ * done is called once ctx is done or the subscriber falls behind, and no more rows are found.
+ */
+func (r *resolver) watch(ctx context.Context, table string, keyColumn string, columns string, q sqlQuery, found func(change jobs.RowChange, row *sql.Row) error, done func()) error {
+	if r.indexSvc.Changes == nil {
+		return fmt.Errorf("subscriptions are not supported")
+	}
+
+	sub := r.indexSvc.Changes.Subscribe(ctx)
+	go func() {
+		defer done()
+		for changes := range sub.Changes() {
+			for _, change := range changes {
+				if change.Table != table {
+					continue
+				}
+				var row *sql.Row
+				if !change.Deleted {
+					rq := q.with(keyColumn+"=?", change.Key)
+					row = r.indexSvc.Db.QueryRowContext(ctx, "SELECT "+columns+" FROM "+table+rq.whereClause(), rq.args...)
+				}
+				err := found(change, row)
+				if err != nil && err != sql.ErrNoRows && ctx.Err() == nil {
+					log.Warnf("failed to load %s row %s: %v", table, change.Key, err)
+				}
+			}
+		}
+		if sub.Overflowed() {
+			if state, ok := ctx.Value(subscriptionStateKey{}).(*subscriptionState); ok {
+				state.setErr(errSubscriberTooSlow)
+			}
+		}
+	}()
+	return nil
+}
+
+// subscription: metadataChanged(filter) MetadataChange
+func (r *resolver) MetadataChanged(ctx context.Context, args struct{ Filter *metadataFilter }) (<-chan *metadataChange, error) {
+	c := make(chan *metadataChange)
+	err := r.watch(ctx, jobs.TableMetadata, "COMMITID", metadataColumns, args.Filter.query(), func(change jobs.RowChange, row *sql.Row) error {
+		mc := &metadataChange{CommitId: change.Key, Deleted: change.Deleted}
+		if row != nil {
+			m, err := scanMetadata(row)
+			if err != nil {
+				return err
+			}
+			mc.Metadata = m
+		}
+		select {
+		case c <- mc:
+		case <-ctx.Done():
+		}
+		return nil
+	}, func() { close(c) })
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// subscription: shardChanged(filter) ShardChange
+func (r *resolver) ShardChanged(ctx context.Context, args struct{ Filter *shardFilter }) (<-chan *shardChange, error) {
+	c := make(chan *shardChange)
+	err := r.watch(ctx, jobs.TableSpShard, "SHARDID", shardColumns, args.Filter.query(), func(change jobs.RowChange, row *sql.Row) error {
+		sc := &shardChange{ShardId: change.Key, Deleted: change.Deleted}
+		if row != nil {
+			s, err := scanShard(row)
+			if err != nil {
+				return err
+			}
+			sc.Shard = s
+		}
+		select {
+		case c <- sc:
+		case <-ctx.Done():
+		}
+		return nil
+	}, func() { close(c) })
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
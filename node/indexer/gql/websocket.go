@@ -0,0 +1,173 @@
+package gql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/graph-gophers/graphql-go"
+)
+
+// message types of the graphql-ws protocol of subscriptions-transport-ws
+const (
+	wsConnectionInit      = "connection_init"
+	wsConnectionAck       = "connection_ack"
+	wsConnectionError     = "connection_error"
+	wsConnectionKeepAlive = "ka"
+	wsConnectionTerminate = "connection_terminate"
+	wsStart               = "start"
+	wsStop                = "stop"
+	wsData                = "data"
+	wsError               = "error"
+	wsComplete            = "complete"
+)
+
+const WS_KEEP_ALIVE_INTERVAL = 30 * time.Second
+
+type wsMessage struct {
+	Id      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+type wsStartPayload struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+/**
+ * wsHandler serves the operations of the schema over websocket, subscriptions push a data message for each event
+ * until the client stops them or the connection is closed.
+ */
+type wsHandler struct {
+	schema   *graphql.Schema
+	upgrader websocket.Upgrader
+}
+
+func newWsHandler(schema *graphql.Schema) *wsHandler {
+	return &wsHandler{
+		schema: schema,
+		upgrader: websocket.Upgrader{
+			Subprotocols: []string{"graphql-ws"},
+			// the query endpoint allows any origin as well
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+	}
+}
+
+func (h *wsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Warnf("failed to upgrade websocket connection from %s: %v", r.RemoteAddr, err)
+		return
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var writeLk sync.Mutex
+	send := func(id string, msgType string, payload interface{}) {
+		msg := wsMessage{Id: id, Type: msgType}
+		if payload != nil {
+			data, err := json.Marshal(payload)
+			if err != nil {
+				log.Errorf("failed to marshal %s message: %v", msgType, err)
+				return
+			}
+			msg.Payload = data
+		}
+
+		writeLk.Lock()
+		defer writeLk.Unlock()
+		if err := conn.WriteJSON(msg); err != nil {
+			log.Debugf("failed to write %s message to %s: %v", msgType, r.RemoteAddr, err)
+			cancel()
+		}
+	}
+
+	go func() {
+		ticker := time.NewTicker(WS_KEEP_ALIVE_INTERVAL)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				send("", wsConnectionKeepAlive, nil)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// operations running on the connection, they're removed once completed
+	var opsLk sync.Mutex
+	operations := make(map[string]context.CancelFunc)
+	stopOperation := func(id string) {
+		opsLk.Lock()
+		defer opsLk.Unlock()
+		if stop, exists := operations[id]; exists {
+			stop()
+			delete(operations, id)
+		}
+	}
+
+	for {
+		var msg wsMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			if !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				log.Debugf("websocket connection from %s closed: %v", r.RemoteAddr, err)
+			}
+			return
+		}
+
+		switch msg.Type {
+		case wsConnectionInit:
+			send("", wsConnectionAck, nil)
+			send("", wsConnectionKeepAlive, nil)
+		case wsConnectionTerminate:
+			return
+		case wsStart:
+			var payload wsStartPayload
+			if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+				send(msg.Id, wsError, map[string]string{"message": "invalid start payload: " + err.Error()})
+				continue
+			}
+
+			opsLk.Lock()
+			if _, exists := operations[msg.Id]; exists {
+				opsLk.Unlock()
+				send(msg.Id, wsError, map[string]string{"message": "operation " + msg.Id + " is already running"})
+				continue
+			}
+			opCtx, stop := context.WithCancel(ctx)
+			operations[msg.Id] = stop
+			opsLk.Unlock()
+			opCtx, state := withSubscriptionState(opCtx)
+
+			responses, err := h.schema.Subscribe(opCtx, payload.Query, payload.OperationName, payload.Variables)
+			if err != nil {
+				stopOperation(msg.Id)
+				send(msg.Id, wsError, map[string]string{"message": err.Error()})
+				continue
+			}
+			go func(id string) {
+				defer stopOperation(id)
+				for resp := range responses {
+					send(id, wsData, resp)
+				}
+				if err := state.Err(); err != nil {
+					send(id, wsError, map[string]string{"message": err.Error()})
+				}
+				send(id, wsComplete, nil)
+			}(msg.Id)
+		case wsStop:
+			stopOperation(msg.Id)
+		default:
+			send(msg.Id, wsConnectionError, map[string]string{"message": "unsupported message type " + msg.Type})
+		}
+	}
+}
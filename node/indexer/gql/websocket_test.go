@@ -0,0 +1,150 @@
+package gql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SaoNetwork/sao-node/node/indexer"
+	"github.com/SaoNetwork/sao-node/node/indexer/jobs"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/graph-gophers/graphql-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebsocket(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "indexer.db"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	jobs.BuildMetadataIndexJob(ctx, nil, db, "", nil)
+	commitIds := []string{uuid.New().String(), uuid.New().String()}
+	for i, did := range []string{"did:key:a", "did:key:b"} {
+		_, err = db.Exec("INSERT INTO METADATA (COMMITID, DID, CID, DATAID, ALIAS, PLAT, VER, SIZE, EXPIRATION, READER, WRITER) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+			commitIds[i], did, "cid", fmt.Sprintf("data-%d", i), fmt.Sprintf("alias-%d", i), "plat", "v1", 10, 100, "", "")
+		require.NoError(t, err)
+	}
+
+	feed := jobs.NewChangeFeed()
+	schema, err := graphql.ParseSchema(schemaGraqhql, &resolver{&indexer.IndexSvc{Db: db, Changes: feed}}, graphql.UseFieldResolvers())
+	require.NoError(t, err)
+	srv := httptest.NewServer(newWsHandler(schema))
+	defer srv.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.NoError(t, conn.WriteJSON(wsMessage{Type: wsConnectionInit}))
+	var msg wsMessage
+	require.NoError(t, conn.ReadJSON(&msg))
+	require.Equal(t, wsConnectionAck, msg.Type)
+
+	start := func(id string, query string, variables map[string]interface{}) {
+		payload, err := json.Marshal(wsStartPayload{Query: query, Variables: variables})
+		require.NoError(t, err)
+		require.NoError(t, conn.WriteJSON(wsMessage{Id: id, Type: wsStart, Payload: payload}))
+	}
+	// subscriptions start asynchronously, the changes are published until stopped
+	publish := func(changes ...jobs.RowChange) (stop func()) {
+		done := make(chan struct{})
+		go func() {
+			for {
+				feed.Publish(changes)
+				select {
+				case <-done:
+					return
+				case <-time.After(50 * time.Millisecond):
+				}
+			}
+		}()
+		return func() { close(done) }
+	}
+	type metadataChanged struct {
+		Data struct {
+			MetadataChanged struct {
+				ID       string
+				Deleted  bool
+				Metadata *struct{ DataId, Did string }
+			}
+		}
+		Errors []interface{}
+	}
+	readMetadata := func() metadataChanged {
+		for {
+			require.NoError(t, conn.ReadJSON(&msg))
+			if msg.Type == wsData {
+				break
+			}
+		}
+		require.Equal(t, "1", msg.Id)
+		var resp metadataChanged
+		require.NoError(t, json.Unmarshal(msg.Payload, &resp))
+		require.Empty(t, resp.Errors)
+		return resp
+	}
+
+	start("1", `subscription($did: String) { metadataChanged(filter: {did: $did}) { ID Deleted Metadata { DataId Did } } }`,
+		map[string]interface{}{"did": "did:key:a"})
+
+	// changed rows not matching the filter are skipped
+	stop := publish(
+		jobs.RowChange{Table: jobs.TableMetadata, Key: commitIds[1]},
+		jobs.RowChange{Table: jobs.TableSpShard, Key: "1"},
+		jobs.RowChange{Table: jobs.TableMetadata, Key: commitIds[0]},
+	)
+	resp := readMetadata()
+	stop()
+	require.Equal(t, commitIds[0], resp.Data.MetadataChanged.ID)
+	require.False(t, resp.Data.MetadataChanged.Deleted)
+	require.Equal(t, "data-0", resp.Data.MetadataChanged.Metadata.DataId)
+	require.Equal(t, "did:key:a", resp.Data.MetadataChanged.Metadata.Did)
+
+	// deleted rows are delivered as tombstones of their keys
+	feed.Publish([]jobs.RowChange{{Table: jobs.TableMetadata, Key: commitIds[1], Deleted: true}})
+	for !resp.Data.MetadataChanged.Deleted {
+		resp = readMetadata()
+	}
+	require.Equal(t, commitIds[1], resp.Data.MetadataChanged.ID)
+	require.Nil(t, resp.Data.MetadataChanged.Metadata)
+
+	require.NoError(t, conn.WriteJSON(wsMessage{Id: "1", Type: wsStop}))
+	for msg.Type != wsComplete {
+		require.NoError(t, conn.ReadJSON(&msg))
+	}
+	require.Equal(t, "1", msg.Id)
+
+	// a subscriber falling behind gets an error, then the subscription is completed
+	start("2", `subscription { shardChanged { ID Deleted } }`, nil)
+	stop = publish(jobs.RowChange{Table: jobs.TableSpShard, Key: "1", Deleted: true})
+	for msg.Type != wsData {
+		require.NoError(t, conn.ReadJSON(&msg))
+	}
+	stop()
+	for i := 0; i < 10*jobs.CHANGE_BUFFER_SIZE; i++ {
+		feed.Publish([]jobs.RowChange{{Table: jobs.TableSpShard, Key: fmt.Sprint(i), Deleted: true}})
+	}
+
+	var errMsg wsMessage
+	for msg.Type != wsComplete {
+		msg = wsMessage{}
+		require.NoError(t, conn.ReadJSON(&msg))
+		if msg.Type == wsConnectionKeepAlive {
+			continue
+		}
+		require.Equal(t, "2", msg.Id)
+		if msg.Type == wsError {
+			errMsg = msg
+		}
+	}
+	require.Contains(t, string(errMsg.Payload), "too slow")
+}
@@ -3,12 +3,11 @@ package indexer
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
-	"fmt"
-	"strings"
+	"sync"
 	"time"
 
 	"github.com/SaoNetwork/sao-node/chain"
+	"github.com/SaoNetwork/sao-node/node/indexer/jobs"
 	"github.com/SaoNetwork/sao-node/node/queue"
 	"github.com/SaoNetwork/sao-node/types"
 	"github.com/SaoNetwork/sao-node/utils"
@@ -25,29 +24,41 @@ const (
 	WINDOW_SIZE       = 20
 	SCHEDULE_INTERVAL = 60
 	MAX_RETRIES       = 10
+	// seconds a failed job waits at most before it's retried
+	MAX_RETRY_BACKOFF = 60 * 60
 )
 
 type IndexSvcApi interface {
-	AddJob(ctx context.Context, job types.Job) (string, error)
+	AddJob(ctx context.Context, jobType string, param string, interval uint64) (string, error)
 	KillJob(ctx context.Context, jobId string) error
-	CheckJob(ctx context.Context) string
-	ListJobs(ctx context.Context) map[string]string
+	ListJobs(ctx context.Context) ([]types.IndexJobInfo, error)
+	ReindexJob(ctx context.Context, jobId string) error
 }
 
 type IndexSvc struct {
 	ctx      context.Context
-	ChainSvc *chain.ChainSvc
+	ChainSvc chain.ChainSvcApi
 	jobDs    datastore.Batching
 
 	schedQueue *queue.RequestQueue
 	locks      *utils.Maplock
 	JobsMap    map[string]*types.Job
 	Db         *sql.DB
+	// rows changed by the jobs as they follow the chain
+	Changes *jobs.ChangeFeed
+
+	jobsLk   sync.Mutex
+	jobInfos map[string]*types.IndexJobInfo
+	// cancels the running job when it's killed
+	cancels map[string]context.CancelFunc
+	// the next run of the job, a job is either waiting on its timer, queued, or running
+	timers map[string]*time.Timer
+	queued map[string]bool
 }
 
 func NewIndexSvc(
 	ctx context.Context,
-	chainSvc *chain.ChainSvc,
+	chainSvc chain.ChainSvcApi,
 	jobsDs datastore.Batching,
 	dbPath string,
 ) *IndexSvc {
@@ -57,7 +68,15 @@ func NewIndexSvc(
 		return nil
 	}
 
-	is := &IndexSvc{
+	is := newIndexSvc(ctx, chainSvc, jobsDs, db)
+	is.processPendingJobs(ctx)
+	go is.runSched(ctx)
+
+	return is
+}
+
+func newIndexSvc(ctx context.Context, chainSvc chain.ChainSvcApi, jobsDs datastore.Batching, db *sql.DB) *IndexSvc {
+	return &IndexSvc{
 		ctx:        ctx,
 		ChainSvc:   chainSvc,
 		jobDs:      jobsDs,
@@ -65,27 +84,12 @@ func NewIndexSvc(
 		locks:      utils.NewMapLock(),
 		JobsMap:    make(map[string]*types.Job),
 		Db:         db,
+		Changes:    jobs.NewChangeFeed(),
+		jobInfos:   make(map[string]*types.IndexJobInfo),
+		cancels:    make(map[string]context.CancelFunc),
+		timers:     make(map[string]*time.Timer),
+		queued:     make(map[string]bool),
 	}
-
-	go is.runSched(ctx)
-	go is.processPendingJobs(ctx)
-
-	// two examples
-	// examples1: create a job to collect the metadata created on dapp whose platform id is 30293f0f-3e0f-4b3c-aff1-890a2fdf063b
-	// job1 := jobs.BuildMetadataIndexJob(ctx, is.ChainSvc, is.Db, "dcca376a-53d2-4a0e-a77f-b52ff19a5eda")
-	// is.JobsMap[job1.ID] = job1
-	// is.schedQueue.Push(&queue.WorkRequest{
-	// 	Job: job1,
-	// })
-
-	// examples2: create a job to collect the shards which assigned to sp 30293f0f-3e0f-4b3c-aff1-890a2fdf063b
-	// job2 := jobs.BuildSpShardIndexJob(ctx, is.ChainSvc, is.Db, "sao1w0y5r9kkva8pees574xx3dee9rtex0ne9y03m6")
-	// is.JobsMap[job2.ID] = job2
-	// is.schedQueue.Push(&queue.WorkRequest{
-	// 	Job: job2,
-	// })
-
-	return is
 }
 
 func (is *IndexSvc) runSched(ctx context.Context) {
@@ -107,57 +111,319 @@ func (is *IndexSvc) runSched(ctx context.Context) {
 				}()
 
 				sq := is.schedQueue.PopFront()
-				if sq == nil || sq.Job.ID == "" {
+				if sq == nil {
+					return
+				}
+				defer is.schedQueue.Done(sq)
+				if sq.Job.ID == "" {
 					return
 				}
 
 				log.Infof("job[%s] loaded.", sq.Job.ID)
 
-				err := is.excute(ctx, sq.Job)
 				log.Infof("job[%s] running...", sq.Job.ID)
-
+				err := is.excute(ctx, sq.Job)
 				if err != nil {
 					log.Errorf("job[%s] failed due to %v", sq.Job.ID, err)
-					is.schedQueue.Push(sq)
-					sq.Job.Status = types.JobStatusPending
 				} else {
 					log.Infof("job[%s] done.", sq.Job.ID)
 				}
+				is.finish(ctx, sq.Job, err)
 			}()
 		}
 	}
 }
 
+/**
+ * reload the jobs persisted in the job datastore, jobs of intervals are resumed from their last runs.
+ */
 func (is *IndexSvc) processPendingJobs(ctx context.Context) {
 	log.Info("process pending jobs...")
 
-	key := datastore.NewKey("pending_jobs")
-	value, err := is.jobDs.Get(ctx, key)
+	index, err := utils.GetIndexJobIndex(ctx, is.jobDs)
 	if err != nil {
-		if strings.Contains(fmt.Sprintf("%v", err), "key not found") {
-			log.Infof("no pending jobs found")
-		} else {
-			log.Errorf("failed to obtain the pending jobs, %v", err)
+		log.Errorf("failed to obtain the pending jobs, %v", err)
+		return
+	}
+
+	for _, key := range index.All {
+		info, err := utils.GetIndexJob(ctx, is.jobDs, key.JobId)
+		if err != nil {
+			log.Errorf("failed to load job[%s], %v", key.JobId, err)
+			continue
 		}
-	} else {
-		var pendingJobs []queue.WorkRequest
-		err = json.Unmarshal(value, &pendingJobs)
+
+		job, err := is.buildJob(ctx, info)
 		if err != nil {
-			log.Errorf("process pending jobs error: %v", err)
-		} else {
-			for _, job := range pendingJobs {
-				is.schedQueue.Push(&job)
-			}
+			log.Errorf("failed to build job[%s], %v", key.JobId, err)
+			continue
+		}
+
+		is.jobsLk.Lock()
+		is.jobInfos[info.JobId] = &info
+		is.JobsMap[info.JobId] = job
+		is.jobsLk.Unlock()
+
+		if info.Status == types.JobStatusKilled || (info.Interval == 0 && info.Status == types.JobStatusSuccessed) {
+			continue
+		}
+
+		var delay time.Duration
+		if info.Status == types.JobStatusFailed {
+			delay = time.Until(time.Unix(info.LastRunAt, 0).Add(retryBackoff(info.Retries)))
+		} else if info.LastRunAt > 0 {
+			delay = time.Until(time.Unix(info.LastRunAt, 0).Add(time.Duration(info.Interval) * time.Second))
+		}
+		is.schedule(job, delay)
+	}
+	log.Infof("%d jobs loaded", len(index.All))
+}
+
+func (is *IndexSvc) buildJob(ctx context.Context, info types.IndexJobInfo) (*types.Job, error) {
+	var job *types.Job
+	switch info.JobType {
+	case types.IndexJobTypeMetadata:
+		job = jobs.BuildMetadataIndexJob(ctx, is.ChainSvc, is.Db, info.Param, is.Changes)
+	case types.IndexJobTypeShard:
+		job = jobs.BuildSpShardIndexJob(ctx, is.ChainSvc, is.Db, info.Param, is.Changes)
+	default:
+		return nil, types.Wrapf(types.ErrInvalidParameters, "unsupported job type %s", info.JobType)
+	}
+	if info.JobId != "" {
+		job.ID = info.JobId
+		job.Status = info.Status
+	}
+	return job, nil
+}
+
+/**
+ * push the job to the schedule queue after the delay, unless it's killed by then.
+ * the delay replaces the one the job is waiting on, so that a job is never scheduled twice.
+ */
+func (is *IndexSvc) schedule(job *types.Job, delay time.Duration) {
+	is.jobsLk.Lock()
+	defer is.jobsLk.Unlock()
+
+	if timer, ok := is.timers[job.ID]; ok {
+		timer.Stop()
+		delete(is.timers, job.ID)
+	}
+	if delay <= 0 {
+		is.push(job)
+		return
+	}
+
+	var timer *time.Timer
+	timer = time.AfterFunc(delay, func() {
+		is.jobsLk.Lock()
+		defer is.jobsLk.Unlock()
+
+		if is.timers[job.ID] == timer {
+			delete(is.timers, job.ID)
+			is.push(job)
+		}
+	})
+	is.timers[job.ID] = timer
+}
+
+// push requires jobsLk
+func (is *IndexSvc) push(job *types.Job) {
+	info := is.jobInfos[job.ID]
+	if info == nil || info.Status == types.JobStatusKilled || is.queued[job.ID] {
+		return
+	}
+	is.queued[job.ID] = true
+	is.schedQueue.Push(&queue.WorkRequest{
+		Job: job,
+	})
+}
+
+/**
+ * delay before a job failed retries times in a row runs again, it's doubled per failure up to MAX_RETRY_BACKOFF.
+ */
+func retryBackoff(retries uint64) time.Duration {
+	backoff := time.Second * SCHEDULE_INTERVAL
+	for i := uint64(1); i < retries && backoff < time.Second*MAX_RETRY_BACKOFF; i++ {
+		backoff *= 2
+	}
+	if backoff > time.Second*MAX_RETRY_BACKOFF {
+		backoff = time.Second * MAX_RETRY_BACKOFF
+	}
+	return backoff
+}
+
+/**
+ * record the run of the job, it's scheduled again after its interval, or retried with backoff if the run failed.
+ */
+func (is *IndexSvc) finish(ctx context.Context, job *types.Job, err error) {
+	is.jobsLk.Lock()
+	info := is.jobInfos[job.ID]
+	if info == nil || info.Status == types.JobStatusKilled {
+		is.jobsLk.Unlock()
+		return
+	}
+	info.Status = job.Status
+	info.LastRunAt = time.Now().Unix()
+	if err != nil {
+		info.LastError = err.Error()
+		info.Retries++
+	} else {
+		info.LastError = ""
+		info.Retries = 0
+	}
+	saveErr := utils.SaveIndexJob(ctx, is.jobDs, *info)
+	interval := info.Interval
+	retries := info.Retries
+	is.jobsLk.Unlock()
+
+	if saveErr != nil {
+		log.Errorf("failed to save job[%s], %v", job.ID, saveErr)
+	}
+
+	if err != nil {
+		is.schedule(job, retryBackoff(retries))
+	} else if interval > 0 {
+		is.schedule(job, time.Duration(interval)*time.Second)
+	}
+}
+
+func (is *IndexSvc) AddJob(ctx context.Context, jobType string, param string, interval uint64) (string, error) {
+	if param == "" {
+		return "", types.Wrapf(types.ErrInvalidParameters, "platform ids or provider addresses are required")
+	}
+
+	job, err := is.buildJob(is.ctx, types.IndexJobInfo{JobType: jobType, Param: param})
+	if err != nil {
+		return "", err
+	}
+	info := &types.IndexJobInfo{
+		JobId:       job.ID,
+		JobType:     jobType,
+		Param:       param,
+		Description: job.Description,
+		Interval:    interval,
+		Status:      job.Status,
+		CreatedAt:   time.Now().Unix(),
+	}
+
+	is.jobsLk.Lock()
+	err = utils.SaveIndexJob(ctx, is.jobDs, *info)
+	if err != nil {
+		is.jobsLk.Unlock()
+		return "", err
+	}
+	is.jobInfos[job.ID] = info
+	is.JobsMap[job.ID] = job
+	is.jobsLk.Unlock()
+
+	is.schedule(job, 0)
+	log.Infof("job[%s] added, %s", job.ID, job.Description)
+
+	return job.ID, nil
+}
+
+/**
+ * stop the job and delete its record, a running job is cancelled and its result dropped.
+ */
+func (is *IndexSvc) KillJob(ctx context.Context, jobId string) error {
+	is.jobsLk.Lock()
+	defer is.jobsLk.Unlock()
+
+	info := is.jobInfos[jobId]
+	if info == nil {
+		return types.Wrapf(types.ErrInvalidParameters, "job %s not found", jobId)
+	}
+	err := utils.DeleteIndexJob(ctx, is.jobDs, jobId)
+	if err != nil {
+		return err
+	}
+
+	info.Status = types.JobStatusKilled
+	is.JobsMap[jobId].Status = types.JobStatusKilled
+	delete(is.jobInfos, jobId)
+	delete(is.JobsMap, jobId)
+	if timer, ok := is.timers[jobId]; ok {
+		timer.Stop()
+		delete(is.timers, jobId)
+	}
+	if cancel, ok := is.cancels[jobId]; ok {
+		cancel()
+	}
+	log.Infof("job[%s] killed.", jobId)
+
+	return nil
+}
+
+func (is *IndexSvc) ListJobs(ctx context.Context) ([]types.IndexJobInfo, error) {
+	index, err := utils.GetIndexJobIndex(ctx, is.jobDs)
+	if err != nil {
+		return nil, err
+	}
+
+	is.jobsLk.Lock()
+	defer is.jobsLk.Unlock()
+
+	infos := make([]types.IndexJobInfo, 0, len(index.All))
+	for _, key := range index.All {
+		if info, ok := is.jobInfos[key.JobId]; ok {
+			infos = append(infos, *info)
 		}
 	}
+	return infos, nil
+}
+
+/**
+ * drop the checkpoint of the job and run it now, the job rebuilds its rows from the current chain state.
+ */
+func (is *IndexSvc) ReindexJob(ctx context.Context, jobId string) error {
+	is.jobsLk.Lock()
+	info := is.jobInfos[jobId]
+	var job *types.Job
+	if info != nil && info.Status != types.JobStatusKilled {
+		job = is.JobsMap[jobId]
+	}
+	is.jobsLk.Unlock()
+	if job == nil {
+		return types.Wrapf(types.ErrInvalidParameters, "job %s not found or killed", jobId)
+	}
+
+	// a running job may save the checkpoint again, the lock waits for it to finish
+	is.locks.Lock(jobId)
+	err := jobs.ResetCheckpoint(ctx, is.Db, jobs.CheckpointName(info.JobType, info.Param))
+	is.locks.Unlock(jobId)
+	if err != nil {
+		return err
+	}
+
+	is.schedule(job, 0)
+	log.Infof("job[%s] scheduled to reindex.", jobId)
+	return nil
 }
 
 func (is *IndexSvc) excute(ctx context.Context, job *types.Job) error {
 	is.locks.Lock(job.ID)
 	defer is.locks.Unlock(job.ID)
 
+	is.jobsLk.Lock()
+	delete(is.queued, job.ID)
+	if job.Status == types.JobStatusKilled {
+		is.jobsLk.Unlock()
+		return nil
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	is.cancels[job.ID] = cancel
 	job.Status = types.JobStatusRuning
+	is.jobsLk.Unlock()
+
 	result, err := job.ExecFunc(ctx, job.Args)
+
+	is.jobsLk.Lock()
+	defer is.jobsLk.Unlock()
+	delete(is.cancels, job.ID)
+	if job.Status == types.JobStatusKilled {
+		return nil
+	}
 	if err != nil {
 		job.Error = err
 		job.Status = types.JobStatusFailed
@@ -0,0 +1,83 @@
+package indexer
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SaoNetwork/sao-node/types"
+	"github.com/SaoNetwork/sao-node/utils"
+
+	"github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndexJobs(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "indexer.db"))
+	require.NoError(t, err)
+	defer db.Close()
+	jobDs := dssync.MutexWrap(datastore.NewMapDatastore())
+
+	// the scheduler is not started, jobs stay in the queue
+	is := newIndexSvc(ctx, nil, jobDs, db)
+	_, err = is.AddJob(ctx, "unknown", "platform", 0)
+	require.True(t, types.ErrInvalidParameters.Is(err))
+	_, err = is.AddJob(ctx, types.IndexJobTypeShard, "", 0)
+	require.True(t, types.ErrInvalidParameters.Is(err))
+
+	metadataJob, err := is.AddJob(ctx, types.IndexJobTypeMetadata, "platform", 600)
+	require.NoError(t, err)
+	shardJob, err := is.AddJob(ctx, types.IndexJobTypeShard, "sao1provider", 0)
+	require.NoError(t, err)
+	require.Equal(t, 2, is.schedQueue.Len())
+
+	// killed jobs are deleted
+	require.NoError(t, is.KillJob(ctx, shardJob))
+	require.True(t, types.ErrInvalidParameters.Is(is.KillJob(ctx, "missing")))
+	require.True(t, types.ErrInvalidParameters.Is(is.KillJob(ctx, shardJob)))
+	info, err := utils.GetIndexJob(ctx, jobDs, shardJob)
+	require.NoError(t, err)
+	require.Empty(t, info.JobId)
+
+	jobs, err := is.ListJobs(ctx)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	require.Equal(t, metadataJob, jobs[0].JobId)
+	require.Equal(t, uint64(600), jobs[0].Interval)
+	require.Equal(t, types.JobStatusPending, jobs[0].Status)
+
+	// jobs are resumed after restart
+	is = newIndexSvc(ctx, nil, jobDs, db)
+	is.processPendingJobs(ctx)
+	restored, err := is.ListJobs(ctx)
+	require.NoError(t, err)
+	require.Equal(t, jobs, restored)
+	require.Equal(t, 1, is.schedQueue.Len())
+	sq := is.schedQueue.PopFront()
+	require.Equal(t, metadataJob, sq.Job.ID)
+
+	// failed runs are retried with backoff, a successful run resets it
+	for retries := uint64(1); retries <= 2; retries++ {
+		sq.Job.Status = types.JobStatusFailed
+		is.finish(ctx, sq.Job, types.ErrInvalidParameters)
+		jobs, err = is.ListJobs(ctx)
+		require.NoError(t, err)
+		require.Equal(t, retries, jobs[0].Retries)
+		require.Contains(t, is.timers, metadataJob)
+	}
+	sq.Job.Status = types.JobStatusSuccessed
+	is.finish(ctx, sq.Job, nil)
+	jobs, err = is.ListJobs(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), jobs[0].Retries)
+	require.Empty(t, jobs[0].LastError)
+	require.NoError(t, is.KillJob(ctx, metadataJob))
+
+	require.Equal(t, time.Minute, retryBackoff(1))
+	require.Equal(t, 4*time.Minute, retryBackoff(3))
+	require.Equal(t, time.Hour, retryBackoff(100))
+}
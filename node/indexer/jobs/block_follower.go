@@ -0,0 +1,382 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/SaoNetwork/sao-node/chain"
+
+	ordertypes "github.com/SaoNetwork/sao/x/order/types"
+	saotypes "github.com/SaoNetwork/sao/x/sao/types"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdktypes "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx"
+	abcitypes "github.com/tendermint/tendermint/abci/types"
+)
+
+// blocks applied in one database transaction, the checkpoint moves forward after each batch
+const BLOCK_BATCH_SIZE = 100
+
+//go:embed sqls/create_checkpoint_table.sql
+var createCheckpointDBSQL string
+
+/**
+ * chainChanges collects the orders and data touched by the txs of a block range, and the rows they changed.
+ */
+type chainChanges struct {
+	orderIds map[uint64]struct{}
+	dataIds  map[string]struct{}
+	rows     []RowChange
+}
+
+func newChainChanges() *chainChanges {
+	return &chainChanges{
+		orderIds: make(map[uint64]struct{}),
+		dataIds:  make(map[string]struct{}),
+	}
+}
+
+func (c *chainChanges) addDataIds(dataIds ...string) {
+	for _, dataId := range dataIds {
+		if dataId != "" {
+			c.dataIds[dataId] = struct{}{}
+		}
+	}
+}
+
+func (c *chainChanges) rowChanged(table string, key string) {
+	c.rows = append(c.rows, RowChange{Table: table, Key: key})
+}
+
+func (c *chainChanges) rowDeleted(table string, key string) {
+	c.rows = append(c.rows, RowChange{Table: table, Key: key, Deleted: true})
+}
+
+/**
+ * the final change of each row, a row deleted and inserted again, e.g. a renewed shard, is published as changed.
+ */
+func (c *chainChanges) published() []RowChange {
+	last := make(map[RowChange]int)
+	for i, row := range c.rows {
+		last[RowChange{Table: row.Table, Key: row.Key}] = i
+	}
+	rows := make([]RowChange, 0, len(last))
+	for i, row := range c.rows {
+		if last[RowChange{Table: row.Table, Key: row.Key}] == i {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+/**
+ * delete the rows of the table matching the condition, their keys are recorded as tombstones.
+ */
+func (c *chainChanges) deleteRows(ctx context.Context, tx *sql.Tx, table string, keyColumn string, cond string, args ...interface{}) error {
+	rows, err := tx.QueryContext(ctx, "SELECT "+keyColumn+" FROM "+table+" WHERE "+cond, args...)
+	if err != nil {
+		return err
+	}
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			rows.Close()
+			return err
+		}
+		keys = append(keys, key)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM "+table+" WHERE "+cond, args...)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		c.rowDeleted(table, key)
+	}
+	return nil
+}
+
+/**
+ * collect the order ids of the events, an order timeout event of the end-block lists the order ids in "[1 2 3]".
+ */
+func (c *chainChanges) addEvents(source string, events []abcitypes.Event) {
+	for _, event := range events {
+		for _, attr := range event.Attributes {
+			var values []string
+			switch string(attr.Key) {
+			case ordertypes.EventOrderId:
+				values = []string{string(attr.Value)}
+			case saotypes.EventTimeoutOrderList:
+				values = strings.Fields(strings.Trim(string(attr.Value), "[]"))
+			default:
+				continue
+			}
+			for _, value := range values {
+				orderId, err := strconv.ParseUint(value, 10, 64)
+				if err != nil {
+					log.Warnf("invalid order id of %s event in %s: %v", event.Type, source, err)
+					continue
+				}
+				c.orderIds[orderId] = struct{}{}
+			}
+		}
+	}
+}
+
+func (c *chainChanges) addMsg(msg *codectypes.Any) error {
+	switch msg.TypeUrl {
+	case sdktypes.MsgTypeURL(&saotypes.MsgStore{}):
+		var m saotypes.MsgStore
+		if err := m.Unmarshal(msg.Value); err != nil {
+			return err
+		}
+		c.addDataIds(m.Proposal.DataId)
+	case sdktypes.MsgTypeURL(&saotypes.MsgReady{}):
+		var m saotypes.MsgReady
+		if err := m.Unmarshal(msg.Value); err != nil {
+			return err
+		}
+		c.orderIds[m.OrderId] = struct{}{}
+	case sdktypes.MsgTypeURL(&saotypes.MsgComplete{}):
+		var m saotypes.MsgComplete
+		if err := m.Unmarshal(msg.Value); err != nil {
+			return err
+		}
+		c.orderIds[m.OrderId] = struct{}{}
+	case sdktypes.MsgTypeURL(&saotypes.MsgCancel{}):
+		var m saotypes.MsgCancel
+		if err := m.Unmarshal(msg.Value); err != nil {
+			return err
+		}
+		c.orderIds[m.OrderId] = struct{}{}
+	case sdktypes.MsgTypeURL(&saotypes.MsgRenew{}):
+		var m saotypes.MsgRenew
+		if err := m.Unmarshal(msg.Value); err != nil {
+			return err
+		}
+		c.addDataIds(m.Proposal.Data...)
+	case sdktypes.MsgTypeURL(&saotypes.MsgMigrate{}):
+		var m saotypes.MsgMigrate
+		if err := m.Unmarshal(msg.Value); err != nil {
+			return err
+		}
+		c.addDataIds(m.Data...)
+	case sdktypes.MsgTypeURL(&saotypes.MsgTerminate{}):
+		var m saotypes.MsgTerminate
+		if err := m.Unmarshal(msg.Value); err != nil {
+			return err
+		}
+		c.addDataIds(m.Proposal.DataId)
+	case sdktypes.MsgTypeURL(&saotypes.MsgUpdataPermission{}):
+		var m saotypes.MsgUpdataPermission
+		if err := m.Unmarshal(msg.Value); err != nil {
+			return err
+		}
+		c.addDataIds(m.Proposal.DataId)
+	}
+	return nil
+}
+
+/**
+ * collect the changes of the block at height, from the msgs of the txs and the order ids of their events, and the
+ * order ids of the begin-block and end-block events, such as order timeouts. failed txs don't change the chain
+ * state and are skipped.
+ */
+func scanBlock(ctx context.Context, chainSvc chain.ChainSvcApi, height int64, changes *chainChanges) error {
+	block, err := chainSvc.GetBlock(ctx, height)
+	if err != nil {
+		return err
+	}
+
+	for _, rawTx := range block.Block.Txs {
+		hash := fmt.Sprintf("%X", rawTx.Hash())
+		resultTx, err := chainSvc.GetTx(ctx, hash, height)
+		if err != nil {
+			return err
+		}
+		if resultTx.TxResult.Code != 0 {
+			continue
+		}
+
+		changes.addEvents("tx "+hash, resultTx.TxResult.Events)
+
+		var txb tx.Tx
+		err = txb.Unmarshal(rawTx)
+		if err != nil || txb.Body == nil {
+			log.Warnf("failed to decode tx %s at height %d: %v", hash, height, err)
+			continue
+		}
+		for _, msg := range txb.Body.Messages {
+			err = changes.addMsg(msg)
+			if err != nil {
+				log.Warnf("failed to decode %s of tx %s: %v", msg.TypeUrl, hash, err)
+			}
+		}
+	}
+
+	results, err := chainSvc.GetBlockResults(ctx, height)
+	if err != nil {
+		return err
+	}
+	source := fmt.Sprintf("block %d", height)
+	changes.addEvents(source, results.BeginBlockEvents)
+	changes.addEvents(source, results.EndBlockEvents)
+	return nil
+}
+
+/**
+ * blockFollower keeps tables in sync with the chain from a checkpoint height, the checkpoint is saved in the
+ * same database transaction as the rows so that a restarted job resumes exactly where it stopped.
+ */
+type blockFollower struct {
+	chainSvc   chain.ChainSvcApi
+	db         *sql.DB
+	checkpoint string
+	feed       *ChangeFeed
+	// rebuild the rows from the current chain state, it runs when there is no checkpoint yet.
+	// the rows recorded in changes are published once committed
+	full func(ctx context.Context, tx *sql.Tx, changes *chainChanges) error
+	// apply the changes of the blocks up to height, the rows recorded in changes are published once committed
+	apply func(ctx context.Context, tx *sql.Tx, height int64, changes *chainChanges) error
+}
+
+func (f *blockFollower) run(ctx context.Context) error {
+	from, err := GetCheckpoint(ctx, f.db, f.checkpoint)
+	if err != nil {
+		return err
+	}
+	latest, err := f.chainSvc.GetLastHeight(ctx)
+	if err != nil {
+		return err
+	}
+
+	if from == 0 {
+		log.Infof("[%s] full index at height %d", f.checkpoint, latest)
+		changes := newChainChanges()
+		err := f.commit(ctx, latest, func(tx *sql.Tx) error {
+			return f.full(ctx, tx, changes)
+		})
+		if err != nil {
+			return err
+		}
+		f.feed.Publish(changes.published())
+		return nil
+	}
+
+	for start := from + 1; start <= latest; start += BLOCK_BATCH_SIZE {
+		end := start + BLOCK_BATCH_SIZE - 1
+		if end > latest {
+			end = latest
+		}
+
+		changes := newChainChanges()
+		for height := start; height <= end; height++ {
+			err := scanBlock(ctx, f.chainSvc, height, changes)
+			if err != nil {
+				return err
+			}
+		}
+
+		err := f.commit(ctx, end, func(tx *sql.Tx) error {
+			return f.apply(ctx, tx, end, changes)
+		})
+		if err != nil {
+			return err
+		}
+		f.feed.Publish(changes.published())
+		log.Debugf("[%s] blocks %d-%d indexed, %d orders and %d data changed", f.checkpoint, start, end, len(changes.orderIds), len(changes.dataIds))
+	}
+	return nil
+}
+
+func (f *blockFollower) commit(ctx context.Context, height int64, write func(tx *sql.Tx) error) error {
+	tx, err := f.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = write(tx)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, "INSERT OR REPLACE INTO CHECKPOINT (NAME, HEIGHT) VALUES (?, ?)", f.checkpoint, height)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+/**
+ * name of the checkpoint of an index job, jobs of the same type and param share the checkpoint.
+ */
+func CheckpointName(jobType string, param string) string {
+	return jobType + ":" + param
+}
+
+/**
+ * the height the named checkpoint has indexed up to, 0 if nothing is indexed yet.
+ */
+func GetCheckpoint(ctx context.Context, db *sql.DB, name string) (int64, error) {
+	var height int64
+	err := db.QueryRowContext(ctx, "SELECT HEIGHT FROM CHECKPOINT WHERE NAME=?", name).Scan(&height)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return height, err
+}
+
+/**
+ * drop the named checkpoint, the next run of its job rebuilds the rows from the current chain state.
+ */
+func ResetCheckpoint(ctx context.Context, db *sql.DB, name string) error {
+	_, err := db.ExecContext(ctx, "DELETE FROM CHECKPOINT WHERE NAME=?", name)
+	return err
+}
+
+/**
+ * the comma separated values of a job param.
+ */
+func splitParam(param string) []string {
+	values := make([]string, 0)
+	for _, value := range strings.Split(param, ",") {
+		value = strings.TrimSpace(value)
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+/**
+ * the "column IN (?, ...)" condition of the values and its statement parameters.
+ */
+func inClause(column string, values []string) (string, []interface{}) {
+	args := make([]interface{}, len(values))
+	for i, value := range values {
+		args[i] = value
+	}
+	return column + " IN (" + strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ") + ")", args
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func isNotFound(err error) bool {
+	return strings.Contains(err.Error(), "not found")
+}
@@ -0,0 +1,226 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SaoNetwork/sao-node/chain"
+	"github.com/SaoNetwork/sao-node/types"
+	"github.com/SaoNetwork/sao-node/utils"
+
+	nodetypes "github.com/SaoNetwork/sao/x/node/types"
+	saotypes "github.com/SaoNetwork/sao/x/sao/types"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockFollower(t *testing.T) {
+	ctx := context.Background()
+
+	mc, err := chain.NewMockChainSvc(ctx, "mock://TestBlockFollower?blocktime=1h", "")
+	require.NoError(t, err)
+	defer mc.Stop(ctx)
+
+	gateway := "sao1gateway"
+	sps := []string{"sao1sp1", "sao1sp2"}
+	storageStatus := nodetypes.NODE_STATUS_ONLINE | nodetypes.NODE_STATUS_SERVE_STORAGE | nodetypes.NODE_STATUS_ACCEPT_ORDER
+	_, err = mc.Reset(ctx, gateway, "/ip4/127.0.0.1/tcp/5153/p2p/gateway", nodetypes.NODE_STATUS_ONLINE|nodetypes.NODE_STATUS_SERVE_GATEWAY, nil, nil)
+	require.NoError(t, err)
+	for _, sp := range sps {
+		_, err = mc.Reset(ctx, sp, "/ip4/127.0.0.1/tcp/5153/p2p/"+sp, storageStatus, nil, nil)
+		require.NoError(t, err)
+	}
+
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "indexer.db"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	feed := NewChangeFeed()
+	subCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	sub := feed.Subscribe(subCtx)
+	// the published changes by table, and whether they're tombstones
+	drain := func() map[RowChange]int {
+		published := make(map[RowChange]int)
+		for len(sub.Changes()) > 0 {
+			for _, change := range <-sub.Changes() {
+				published[RowChange{Table: change.Table, Deleted: change.Deleted}]++
+			}
+		}
+		return published
+	}
+
+	metadataJob := BuildMetadataIndexJob(ctx, mc, db, "group", feed)
+	shardJob := BuildSpShardIndexJob(ctx, mc, db, strings.Join(sps, ","), feed)
+	// txs of the mock chain go to the current block, a new block is started after each run
+	run := func() int64 {
+		latest, err := mc.GetLastHeight(ctx)
+		require.NoError(t, err)
+		_, err = metadataJob.Execute(ctx)
+		require.NoError(t, err)
+		_, err = shardJob.Execute(ctx)
+		require.NoError(t, err)
+		mc.AdvanceBlocks(1)
+		return latest
+	}
+	count := func(query string, args ...interface{}) int {
+		var n int
+		require.NoError(t, db.QueryRow(query, args...).Scan(&n))
+		return n
+	}
+
+	// the first run indexes the current chain state and sets the checkpoint
+	latest := run()
+	checkpoint := CheckpointName(types.IndexJobTypeMetadata, "group")
+	height, err := GetCheckpoint(ctx, db, checkpoint)
+	require.NoError(t, err)
+	require.Equal(t, latest, height)
+
+	store := func(groupId string) string {
+		content := []byte("hello " + groupId)
+		contentCid, err := utils.CalculateCid(content)
+		require.NoError(t, err)
+		dataId := utils.GenerateDataId(groupId)
+		resp, _, _, err := mc.StoreOrder(ctx, gateway, &types.OrderStoreProposal{
+			Proposal: saotypes.Proposal{
+				Owner:     "did:key:owner",
+				Provider:  gateway,
+				GroupId:   groupId,
+				Duration:  100,
+				Replica:   2,
+				Timeout:   10,
+				Alias:     groupId,
+				DataId:    dataId,
+				CommitId:  dataId,
+				Cid:       contentCid.String(),
+				Size_:     uint64(len(content)),
+				Operation: 1,
+			},
+		})
+		require.NoError(t, err)
+		for _, shard := range resp.Shards {
+			_, _, err = mc.CompleteOrder(ctx, shard.Sp, resp.OrderId, contentCid, uint64(len(content)))
+			require.NoError(t, err)
+		}
+		return dataId
+	}
+	dataId := store("group")
+	store("other")
+
+	// only the changes of the new blocks are applied
+	latest = run()
+	require.Equal(t, 1, count("SELECT COUNT(*) FROM METADATA"))
+	require.Equal(t, 1, count("SELECT COUNT(*) FROM METADATA WHERE DATAID=?", dataId))
+	require.Equal(t, 4, count("SELECT COUNT(*) FROM SP_SHARD"))
+	height, err = GetCheckpoint(ctx, db, checkpoint)
+	require.NoError(t, err)
+	require.Equal(t, latest, height)
+
+	// the committed rows are published
+	require.Equal(t, map[RowChange]int{{Table: TableMetadata}: 1, {Table: TableSpShard}: 4}, drain())
+
+	var expiration int64
+	require.NoError(t, db.QueryRow("SELECT EXPIRATION FROM METADATA WHERE DATAID=?", dataId).Scan(&expiration))
+	_, results, err := mc.RenewOrder(ctx, gateway, types.OrderRenewProposal{
+		Proposal: saotypes.RenewProposal{Owner: "did:key:owner", Duration: 50, Timeout: 10, Data: []string{dataId}},
+	})
+	require.NoError(t, err)
+	require.Contains(t, results[dataId], "SUCCESS")
+	run()
+	var renewed int64
+	require.NoError(t, db.QueryRow("SELECT EXPIRATION FROM METADATA WHERE DATAID=?", dataId).Scan(&renewed))
+	require.Greater(t, renewed, expiration)
+
+	drain()
+
+	// a reindex rebuilds the same rows from the current chain state, and publishes them as changed
+	require.NoError(t, ResetCheckpoint(ctx, db, checkpoint))
+	require.NoError(t, ResetCheckpoint(ctx, db, CheckpointName(types.IndexJobTypeShard, strings.Join(sps, ","))))
+	run()
+	require.Equal(t, 1, count("SELECT COUNT(*) FROM METADATA WHERE DATAID=?", dataId))
+	require.Equal(t, map[RowChange]int{{Table: TableMetadata}: 1, {Table: TableSpShard}: 4}, drain())
+
+	_, err = mc.TerminateOrder(ctx, gateway, types.OrderTerminateProposal{
+		Proposal: saotypes.TerminateProposal{Owner: "did:key:owner", DataId: dataId},
+	})
+	require.NoError(t, err)
+	latest = run()
+	require.Zero(t, count("SELECT COUNT(*) FROM METADATA"))
+	require.Equal(t, 2, count("SELECT COUNT(*) FROM SP_SHARD"))
+
+	// deleted rows are published as tombstones of their keys
+	require.Equal(t, map[RowChange]int{{Table: TableMetadata, Deleted: true}: 1, {Table: TableSpShard, Deleted: true}: 2}, drain())
+
+	// expired shards are dropped without any tx
+	var shardExpiration int64
+	require.NoError(t, db.QueryRow("SELECT MAX(EXPIRATION) FROM SP_SHARD").Scan(&shardExpiration))
+	mc.AdvanceBlocks(shardExpiration - latest)
+	run()
+	require.Zero(t, count("SELECT COUNT(*) FROM SP_SHARD"))
+	require.Equal(t, map[RowChange]int{{Table: TableSpShard, Deleted: true}: 2}, drain())
+}
+
+func TestSpShardMigration(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "indexer.db"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	// a shard index built before the expiration column
+	checkpoint := CheckpointName(types.IndexJobTypeShard, "sao1sp1")
+	_, err = db.Exec("CREATE TABLE SP_SHARD (SHARDID INT, ORDERID INT, SP TEXT, CID TEXT, PRIMARY KEY(SHARDID)) WITHOUT ROWID;" + createCheckpointDBSQL)
+	require.NoError(t, err)
+	_, err = db.Exec("INSERT INTO CHECKPOINT (NAME, HEIGHT) VALUES (?, ?)", checkpoint, 10)
+	require.NoError(t, err)
+
+	BuildSpShardIndexJob(ctx, nil, db, "sao1sp1", NewChangeFeed())
+	_, err = db.Exec("SELECT EXPIRATION FROM SP_SHARD")
+	require.NoError(t, err)
+	height, err := GetCheckpoint(ctx, db, checkpoint)
+	require.NoError(t, err)
+	require.Zero(t, height)
+}
+
+func TestScanBlockTimeout(t *testing.T) {
+	ctx := context.Background()
+
+	mc, err := chain.NewMockChainSvc(ctx, "mock://TestScanBlockTimeout?blocktime=1h", "")
+	require.NoError(t, err)
+	defer mc.Stop(ctx)
+
+	_, err = mc.Reset(ctx, "sao1gateway", "", nodetypes.NODE_STATUS_ONLINE|nodetypes.NODE_STATUS_SERVE_STORAGE|nodetypes.NODE_STATUS_ACCEPT_ORDER, nil, nil)
+	require.NoError(t, err)
+
+	content := []byte("timeout")
+	contentCid, err := utils.CalculateCid(content)
+	require.NoError(t, err)
+	dataId := utils.GenerateDataId("timeout")
+	resp, _, height, err := mc.StoreOrder(ctx, "sao1gateway", &types.OrderStoreProposal{
+		Proposal: saotypes.Proposal{
+			Owner:     "did:key:owner",
+			Provider:  "sao1gateway",
+			Duration:  100,
+			Replica:   1,
+			Timeout:   5,
+			DataId:    dataId,
+			CommitId:  dataId,
+			Cid:       contentCid.String(),
+			Size_:     uint64(len(content)),
+			Operation: 1,
+		},
+	})
+	require.NoError(t, err)
+
+	// the blocks after the store have no tx, the order is only found by the timeout event of the end-block
+	latest := mc.AdvanceBlocks(6)
+	changes := newChainChanges()
+	for h := height + 1; h <= latest; h++ {
+		require.NoError(t, scanBlock(ctx, mc, h, changes))
+	}
+	require.Equal(t, map[uint64]struct{}{resp.OrderId: {}}, changes.orderIds)
+	require.Empty(t, changes.dataIds)
+}
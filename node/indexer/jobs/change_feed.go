@@ -0,0 +1,109 @@
+package jobs
+
+import (
+	"context"
+	"sync"
+)
+
+const (
+	TableMetadata = "METADATA"
+	TableSpShard  = "SP_SHARD"
+)
+
+// commits buffered for a subscriber, a subscriber whose buffer is full is closed. the rows of a commit take
+// a single slot however many they are
+const CHANGE_BUFFER_SIZE = 100
+
+/**
+ * RowChange identifies a row inserted, updated or deleted by an index job, Key is the primary key of the row.
+ */
+type RowChange struct {
+	Table string
+	Key   string
+	// the row is deleted, the change is a tombstone of the key
+	Deleted bool
+}
+
+/**
+ * Subscription receives the rows changed by each commit from the feed, its channel is closed once the context
+ * is done or the subscriber falls behind.
+ */
+type Subscription struct {
+	ch         chan []RowChange
+	overflowed bool
+}
+
+func (s *Subscription) Changes() <-chan []RowChange {
+	return s.ch
+}
+
+/**
+ * whether the subscription is closed because its buffer was full, it's valid once the channel is closed.
+ */
+func (s *Subscription) Overflowed() bool {
+	return s.overflowed
+}
+
+/**
+ * ChangeFeed delivers the rows changed by the index jobs to the subscribers once they're committed.
+ */
+type ChangeFeed struct {
+	lk   sync.Mutex
+	subs map[*Subscription]struct{}
+}
+
+func NewChangeFeed() *ChangeFeed {
+	return &ChangeFeed{subs: make(map[*Subscription]struct{})}
+}
+
+/**
+ * receive the changed rows until ctx is done.
+ */
+func (f *ChangeFeed) Subscribe(ctx context.Context) *Subscription {
+	sub := &Subscription{ch: make(chan []RowChange, CHANGE_BUFFER_SIZE)}
+
+	f.lk.Lock()
+	f.subs[sub] = struct{}{}
+	f.lk.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		f.lk.Lock()
+		defer f.lk.Unlock()
+		f.remove(sub)
+	}()
+	return sub
+}
+
+// remove requires lk
+func (f *ChangeFeed) remove(sub *Subscription) {
+	if _, ok := f.subs[sub]; ok {
+		delete(f.subs, sub)
+		close(sub.ch)
+	}
+}
+
+/**
+ * deliver the changes of a commit to the subscribers as one batch, the batch is shared and must not be modified.
+ * publishing never blocks the index jobs, a subscriber with a full buffer is closed rather than silently missing
+ * changes.
+ */
+func (f *ChangeFeed) Publish(changes []RowChange) {
+	if f == nil || len(changes) == 0 {
+		return
+	}
+
+	f.lk.Lock()
+	defer f.lk.Unlock()
+
+	for sub := range f.subs {
+		select {
+		case sub.ch <- changes:
+		default:
+			log.Warnf("change feed subscriber is too slow, it's closed at a commit of %d rows", len(changes))
+			sub.overflowed = true
+			f.remove(sub)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangeFeed(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	feed := NewChangeFeed()
+	sub := feed.Subscribe(ctx)
+
+	// the rows of a commit take one slot however many they are
+	changes := make([]RowChange, 10*CHANGE_BUFFER_SIZE)
+	for i := range changes {
+		changes[i] = RowChange{Table: TableSpShard, Key: fmt.Sprint(i)}
+	}
+	feed.Publish(changes)
+	require.Equal(t, changes, <-sub.Changes())
+
+	// a subscriber falling behind by more commits than its buffer is closed
+	for i := 0; i <= CHANGE_BUFFER_SIZE; i++ {
+		feed.Publish(changes[i : i+1])
+	}
+	count := 0
+	for range sub.Changes() {
+		count++
+	}
+	require.Equal(t, CHANGE_BUFFER_SIZE, count)
+	require.True(t, sub.Overflowed())
+}
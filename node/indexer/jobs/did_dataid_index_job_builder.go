@@ -20,83 +20,112 @@ var log = logging.Logger("indexer-jobs")
 //go:embed sqls/create_metadata_table.sql
 var createMetadataDBSQL string
 
-func BuildMetadataIndexJob(ctx context.Context, chainSvc *chain.ChainSvc, db *sql.DB, platFormIds string) *types.Job {
+const insertMetadataSQL = "INSERT INTO METADATA (COMMITID, DID, CID, DATAID, ALIAS, PLAT, VER, SIZE, EXPIRATION, READER, WRITER) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+
+func metadataValues(meta modeltypes.Metadata) []interface{} {
+	return []interface{}{meta.Commit, meta.Owner, meta.Cid, meta.DataId, meta.Alias, meta.GroupId, fmt.Sprintf("v%d", len(meta.Commits)),
+		meta.Size(), meta.CreatedAt + meta.Duration, strings.Join(meta.ReadonlyDids, ","), strings.Join(meta.ReadwriteDids, ",")}
+}
+
+func BuildMetadataIndexJob(ctx context.Context, chainSvc chain.ChainSvcApi, db *sql.DB, platFormIds string, feed *ChangeFeed) *types.Job {
 	// initialize the metadata database tables
 	log.Info("creating metadata tables...")
-	if _, err := db.ExecContext(ctx, createMetadataDBSQL); err != nil {
+	if _, err := db.ExecContext(ctx, createMetadataDBSQL+createCheckpointDBSQL); err != nil {
 		log.Errorf("failed to create tables: %w", err)
 	}
 	log.Info("creating metadata tables done.")
 
-	execFn := func(ctx context.Context, _ []interface{}) (interface{}, error) {
-		var offset uint64 = 0
-		var limit uint64 = 100
-		owenedMeta := make([]modeltypes.Metadata, 0)
-		for {
-			metaList, total, err := chainSvc.ListMeta(ctx, offset, limit)
+	platforms := splitParam(platFormIds)
+	platformCond, platformArgs := inClause("PLAT", platforms)
+
+	follower := &blockFollower{
+		chainSvc:   chainSvc,
+		db:         db,
+		checkpoint: CheckpointName(types.IndexJobTypeMetadata, platFormIds),
+		feed:       feed,
+		full: func(ctx context.Context, tx *sql.Tx, changes *chainChanges) error {
+			err := changes.deleteRows(ctx, tx, TableMetadata, "COMMITID", platformCond, platformArgs...)
 			if err != nil {
-				return nil, err
+				return err
 			}
-			if offset*limit <= total {
-				offset++
-			} else {
-				break
+
+			insert, err := tx.PrepareContext(ctx, insertMetadataSQL)
+			if err != nil {
+				return err
 			}
+			defer insert.Close()
 
-			for _, meta := range metaList {
-				qry := "SELECT COUNT(*) FROM METADATA WHERE COMMITID=?"
-				row := db.QueryRowContext(ctx, qry, meta.Commit)
-				var count int
-				err := row.Scan(&count)
+			var offset uint64 = 0
+			var limit uint64 = 100
+			count := 0
+			for {
+				metaList, total, err := chainSvc.ListMeta(ctx, offset, limit)
 				if err != nil {
-					return nil, err
+					return err
 				}
-
-				if count > 0 {
-					continue
-				} else {
-					if strings.Contains(platFormIds, meta.GroupId) {
-						owenedMeta = append(owenedMeta, meta)
+				for _, meta := range metaList {
+					if !contains(platforms, meta.GroupId) {
+						continue
+					}
+					_, err = insert.ExecContext(ctx, metadataValues(meta)...)
+					if err != nil {
+						return err
 					}
+					changes.rowChanged(TableMetadata, meta.Commit)
+					count++
+				}
+
+				offset += limit
+				if offset >= total || len(metaList) == 0 {
+					break
 				}
 			}
-		}
-
-		if len(owenedMeta) > 0 {
-			valueArgs := ""
-			log.Infof("batch prepare, %d metadata records to be saved.", len(owenedMeta))
-			for index, meta := range owenedMeta {
-				if valueArgs != "" {
-					valueArgs += ", "
+			log.Infof("%d metadata records saved.", count)
+			return nil
+		},
+		apply: func(ctx context.Context, tx *sql.Tx, height int64, changes *chainChanges) error {
+			// completed orders update the metadata of their data
+			for orderId := range changes.orderIds {
+				order, err := chainSvc.GetOrder(ctx, orderId)
+				if err != nil {
+					if isNotFound(err) {
+						continue
+					}
+					return err
 				}
+				changes.addDataIds(order.DataId)
+			}
 
-				valueArgs = fmt.Sprintf(`%s("%s", "%s", "%s", "%s", "%s", "v%d", %d, %d, "%s", "%s")`,
-					valueArgs, meta.Owner, meta.DataId, meta.Alias, meta.GroupId, meta.Commit, len(meta.Commits), meta.Size(), meta.CreatedAt+meta.Duration, meta.ReadonlyDids, meta.ReadwriteDids)
+			for dataId := range changes.dataIds {
+				resp, err := chainSvc.GetMeta(ctx, dataId)
+				if err != nil && !isNotFound(err) {
+					return err
+				}
+				if err == nil && !contains(platforms, resp.Metadata.GroupId) {
+					continue
+				}
 
-				if index > 0 && index%500 == 0 {
-					log.Infof("sub batch prepare, 500 metadata records to be saved.")
-					stmt := fmt.Sprintf("INSERT INTO METADATA (DID, DATAID, ALIAS, PLAT, COMMITID, VER, SIZE, EXPIRATION, READER, WRITER) VALUES %s",
-						valueArgs)
-					_, err := db.Exec(stmt)
+				// the row of the latest commit replaces the previous one, deleted data leave no row
+				err = changes.deleteRows(ctx, tx, TableMetadata, "COMMITID", "DATAID=?", dataId)
+				if err != nil {
+					return err
+				}
+				if resp != nil {
+					_, err = tx.ExecContext(ctx, insertMetadataSQL, metadataValues(resp.Metadata)...)
 					if err != nil {
-						return nil, err
+						return err
 					}
-					valueArgs = ""
-					log.Infof("sub batch done, %d metadata records saved.", len(owenedMeta))
+					changes.rowChanged(TableMetadata, resp.Metadata.Commit)
 				}
 			}
-			if len(valueArgs) > 0 {
-				stmt := fmt.Sprintf("INSERT INTO METADATA (DID, DATAID, ALIAS, PLAT, COMMITID, VER, SIZE, EXPIRATION, READER, WRITER) VALUES %s",
-					valueArgs)
-				_, err := db.Exec(stmt)
-				if err != nil {
-					return nil, err
-				}
-				log.Infof("batch done, %d metadata records saved.", len(owenedMeta))
-			}
-		}
 
-		return nil, nil
+			// expired data are removed by the chain without any tx
+			return changes.deleteRows(ctx, tx, TableMetadata, "COMMITID", "EXPIRATION<? AND "+platformCond, append([]interface{}{height}, platformArgs...)...)
+		},
+	}
+
+	execFn := func(ctx context.Context, _ []interface{}) (interface{}, error) {
+		return nil, follower.run(ctx)
 	}
 
 	return &types.Job{
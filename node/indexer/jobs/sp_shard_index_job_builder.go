@@ -4,12 +4,13 @@ import (
 	"context"
 	"database/sql"
 	_ "embed"
-	"fmt"
-	"strings"
+	"strconv"
 
 	"github.com/SaoNetwork/sao-node/chain"
 	"github.com/SaoNetwork/sao-node/types"
 	"github.com/SaoNetwork/sao-node/utils"
+
+	ordertypes "github.com/SaoNetwork/sao/x/order/types"
 )
 
 //go:embed sqls/create_sp_shard_table.sql
@@ -22,89 +23,147 @@ type Shard struct {
 	Cid     string
 }
 
-func BuildSpShardIndexJob(ctx context.Context, chainSvc *chain.ChainSvc, db *sql.DB, providers string) *types.Job {
+const insertSpShardSQL = "INSERT OR REPLACE INTO SP_SHARD (SHARDID, ORDERID, SP, CID, EXPIRATION) VALUES (?, ?, ?, ?, ?)"
+
+/**
+ * the height after which the chain drops the shard, renewals of the shard extend it.
+ */
+func shardExpiration(shard *ordertypes.Shard) uint64 {
+	expiration := shard.CreatedAt + shard.Duration
+	for _, info := range shard.RenewInfos {
+		expiration += info.Duration
+	}
+	return expiration
+}
+
+/**
+ * add the EXPIRATION column to a SP_SHARD table created before it, true is returned if the column is added.
+ */
+func migrateSpShardTable(ctx context.Context, db *sql.DB) (bool, error) {
+	var columns, expiration int
+	err := db.QueryRowContext(ctx, "SELECT COUNT(*), COUNT(CASE WHEN name='EXPIRATION' THEN 1 END) FROM pragma_table_info('SP_SHARD')").Scan(&columns, &expiration)
+	if err != nil || columns == 0 || expiration > 0 {
+		return false, err
+	}
+	_, err = db.ExecContext(ctx, "ALTER TABLE SP_SHARD ADD COLUMN EXPIRATION INT")
+	return err == nil, err
+}
+
+func BuildSpShardIndexJob(ctx context.Context, chainSvc chain.ChainSvcApi, db *sql.DB, providers string, feed *ChangeFeed) *types.Job {
+	checkpoint := CheckpointName(types.IndexJobTypeShard, providers)
+
 	// initialize the sp shard database tables
 	log.Info("creating sp shard tables...")
-	if _, err := db.ExecContext(ctx, createSpShardDBSQL); err != nil {
+	migrated, err := migrateSpShardTable(ctx, db)
+	if err != nil {
+		log.Error("failed to migrate tables: ", err)
+	}
+	if _, err := db.ExecContext(ctx, createSpShardDBSQL+createCheckpointDBSQL); err != nil {
 		log.Error("failed to create tables: ", err)
 	}
+	// the shards indexed without expiration are rebuilt
+	if migrated {
+		if err := ResetCheckpoint(ctx, db, checkpoint); err != nil {
+			log.Error("failed to reset checkpoint: ", err)
+		}
+	}
 	log.Info("creating sp shard tables done.")
 
-	execFn := func(ctx context.Context, _ []interface{}) (interface{}, error) {
-		var offset uint64 = 0
-		var limit uint64 = 100
-		shards := make([]Shard, 0)
-		for {
-			shardList, total, err := chainSvc.ListShards(ctx, offset, limit)
+	sps := splitParam(providers)
+	spCond, spArgs := inClause("SP", sps)
+
+	follower := &blockFollower{
+		chainSvc:   chainSvc,
+		db:         db,
+		checkpoint: checkpoint,
+		feed:       feed,
+		full: func(ctx context.Context, tx *sql.Tx, changes *chainChanges) error {
+			err := changes.deleteRows(ctx, tx, TableSpShard, "SHARDID", spCond, spArgs...)
 			if err != nil {
-				return nil, err
+				return err
 			}
-			if offset*limit <= total {
-				offset++
-			} else {
-				break
+
+			insert, err := tx.PrepareContext(ctx, insertSpShardSQL)
+			if err != nil {
+				return err
 			}
+			defer insert.Close()
 
-			for _, shard := range shardList {
-				qry := "SELECT COUNT(*) FROM SP_SHARD WHERE ORDERID=? AND SHARDID=?"
-				row := db.QueryRowContext(ctx, qry, shard.OrderId, shard.Id)
-				var count int
-				err := row.Scan(&count)
+			var offset uint64 = 0
+			var limit uint64 = 100
+			count := 0
+			for {
+				shardList, total, err := chainSvc.ListShards(ctx, offset, limit)
 				if err != nil {
-					return nil, err
+					return err
+				}
+				for _, shard := range shardList {
+					if !contains(sps, shard.Sp) {
+						continue
+					}
+					_, err = insert.ExecContext(ctx, shard.Id, shard.OrderId, shard.Sp, shard.Cid, shardExpiration(&shard))
+					if err != nil {
+						return err
+					}
+					changes.rowChanged(TableSpShard, strconv.FormatUint(shard.Id, 10))
+					count++
 				}
 
-				if count > 0 {
-					continue
-				} else {
-					if strings.Contains(providers, shard.Sp) {
-						shards = append(shards, Shard{
-							ShardId: shard.Id,
-							OrderId: shard.OrderId,
-							Sp:      shard.Sp,
-							Cid:     shard.Cid,
-						})
+				offset += limit
+				if offset >= total || len(shardList) == 0 {
+					break
+				}
+			}
+			log.Infof("%d sp shard records saved.", count)
+			return nil
+		},
+		apply: func(ctx context.Context, tx *sql.Tx, height int64, changes *chainChanges) error {
+			// migrated, renewed and terminated data change the shards of their orders
+			for dataId := range changes.dataIds {
+				resp, err := chainSvc.GetMeta(ctx, dataId)
+				if err != nil {
+					if isNotFound(err) {
+						continue
 					}
+					return err
 				}
+				changes.orderIds[resp.Metadata.OrderId] = struct{}{}
 			}
 
-		}
-
-		if len(shards) > 0 {
-			valueArgs := ""
-			log.Infof("batch prepare, %d sp shard records to be saved.", len(shards))
-			for index, shard := range shards {
-				if valueArgs != "" {
-					valueArgs += ", "
+			for orderId := range changes.orderIds {
+				order, err := chainSvc.GetOrder(ctx, orderId)
+				if err != nil && !isNotFound(err) {
+					return err
 				}
 
-				valueArgs = fmt.Sprintf(`%s(%d, %d, "%s", "%s")`,
-					valueArgs, shard.ShardId, shard.OrderId, shard.Sp, shard.Cid)
-
-				if index > 0 && index%500 == 0 {
-					log.Infof("sub batch prepare, 500 sp shard records to be saved.")
-					stmt := fmt.Sprintf("INSERT INTO SP_SHARD (SHARDID, ORDERID, SP, CID) VALUES %s",
-						valueArgs)
-					_, err := db.Exec(stmt)
+				// the shards of the order replace the indexed ones, removed orders leave no shard.
+				// renewed orders take over the shards of the previous order with the same shard ids
+				err = changes.deleteRows(ctx, tx, TableSpShard, "SHARDID", "ORDERID=? AND "+spCond, append([]interface{}{orderId}, spArgs...)...)
+				if err != nil {
+					return err
+				}
+				if order == nil {
+					continue
+				}
+				for _, shard := range order.Shards {
+					if !contains(sps, shard.Sp) {
+						continue
+					}
+					_, err = tx.ExecContext(ctx, insertSpShardSQL, shard.Id, orderId, shard.Sp, shard.Cid, shardExpiration(shard))
 					if err != nil {
-						return nil, err
+						return err
 					}
-					valueArgs = ""
-					log.Infof("sub batch done, %d sp shard records saved.", len(shards))
-				}
-			}
-			if len(valueArgs) > 0 {
-				stmt := fmt.Sprintf("INSERT INTO SP_SHARD (SHARDID, ORDERID, SP, CID) VALUES %s",
-					valueArgs)
-				_, err := db.Exec(stmt)
-				if err != nil {
-					return nil, err
+					changes.rowChanged(TableSpShard, strconv.FormatUint(shard.Id, 10))
 				}
-				log.Infof("batch done, %d sp shard records saved.", len(shards))
 			}
-		}
 
-		return nil, nil
+			// expired shards are removed by the chain without any tx
+			return changes.deleteRows(ctx, tx, TableSpShard, "SHARDID", "EXPIRATION<? AND "+spCond, append([]interface{}{height}, spArgs...)...)
+		},
+	}
+
+	execFn := func(ctx context.Context, _ []interface{}) (interface{}, error) {
+		return nil, follower.run(ctx)
 	}
 
 	return &types.Job{
@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS CHECKPOINT (
+    NAME TEXT,
+    HEIGHT INT,
+    PRIMARY KEY(NAME)
+) WITHOUT ROWID;
@@ -3,9 +3,11 @@ CREATE TABLE IF NOT EXISTS SP_SHARD (
     ORDERID INT,
     SP TEXT,
     CID TEXT,
+    EXPIRATION INT,
     PRIMARY KEY(SHARDID)
 ) WITHOUT ROWID;
 
 CREATE INDEX IF NOT EXISTS index_sp_shard on SP_SHARD(SP);
 CREATE INDEX IF NOT EXISTS index_orderid_shard on SP_SHARD(ORDERID);
 CREATE INDEX IF NOT EXISTS index_cid_shard on SP_SHARD(CID);
+CREATE INDEX IF NOT EXISTS index_expiration_shard on SP_SHARD(EXPIRATION);
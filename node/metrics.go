@@ -0,0 +1,50 @@
+package node
+
+import (
+	"context"
+
+	"github.com/SaoNetwork/sao-node/metrics"
+	"github.com/SaoNetwork/sao-node/types"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+/**
+ * collectors of the order and shard states kept by the enabled modules of this node.
+ */
+func (n *Node) metricsCollectors() []prometheus.Collector {
+	var collectors []prometheus.Collector
+	if n.gatewaySvc != nil {
+		collectors = append(collectors, metrics.NewStateCollector("gateway", "orders", "Number of orders per state.", func() (map[string]int, error) {
+			orders, err := n.gatewaySvc.OrderList(context.Background())
+			if err != nil {
+				return nil, err
+			}
+			counts := make(map[string]int)
+			for state := types.OrderStateStaged; state <= types.OrderStateCancelled; state++ {
+				counts[state.String()] = 0
+			}
+			for _, order := range orders {
+				counts[order.State.String()]++
+			}
+			return counts, nil
+		}))
+	}
+	if n.storeSvc != nil {
+		collectors = append(collectors, metrics.NewStateCollector("storage", "shards", "Number of shards per state.", func() (map[string]int, error) {
+			shards, err := n.storeSvc.ShardList(context.Background())
+			if err != nil {
+				return nil, err
+			}
+			counts := make(map[string]int)
+			for state := types.ShardStateValidated; state <= types.ShardStateExpired; state++ {
+				counts[state.String()] = 0
+			}
+			for _, shard := range shards {
+				counts[shard.State.String()]++
+			}
+			return counts, nil
+		}))
+	}
+	return collectors
+}
@@ -8,7 +8,6 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
-	"sync"
 
 	"github.com/SaoNetwork/sao-node/node/cache"
 	"github.com/SaoNetwork/sao-node/node/config"
@@ -35,30 +34,24 @@ type ModelManager struct {
 	GatewaySvc gateway.GatewaySvcApi
 }
 
-var (
-	modelManager *ModelManager
-	once         sync.Once
-)
-
+/**
+ * every gateway gets its own manager bound to its gateway service, the cache service is shared by the process.
+ */
 func NewModelManager(cacheCfg *config.Cache, gatewaySvc gateway.GatewaySvcApi) *ModelManager {
-	once.Do(func() {
-		var cacheSvc cache.CacheSvcApi
-		if cacheCfg.RedisConn == "" && cacheCfg.MemcachedConn == "" {
-			cacheSvc = cache.NewLruCacheSvc()
-		} else if cacheCfg.RedisConn != "" {
-			cacheSvc = cache.NewRedisCacheSvc(cacheCfg.RedisConn, cacheCfg.RedisPassword, cacheCfg.RedisPoolSize)
-		} else if cacheCfg.MemcachedConn != "" {
-			cacheSvc = cache.NewMemcachedCacheSvc(cacheCfg.MemcachedConn)
-		}
-
-		modelManager = &ModelManager{
-			CacheCfg:   cacheCfg,
-			CacheSvc:   cacheSvc,
-			GatewaySvc: gatewaySvc,
-		}
-	})
+	var cacheSvc cache.CacheSvcApi
+	if cacheCfg.RedisConn == "" && cacheCfg.MemcachedConn == "" {
+		cacheSvc = cache.NewMetricsCacheSvc(cache.NewLruCacheSvc(), "lru")
+	} else if cacheCfg.RedisConn != "" {
+		cacheSvc = cache.NewMetricsCacheSvc(cache.NewRedisCacheSvc(cacheCfg.RedisConn, cacheCfg.RedisPassword, cacheCfg.RedisPoolSize), "redis")
+	} else if cacheCfg.MemcachedConn != "" {
+		cacheSvc = cache.NewMetricsCacheSvc(cache.NewMemcachedCacheSvc(cacheCfg.MemcachedConn), "memcached")
+	}
 
-	return modelManager
+	return &ModelManager{
+		CacheCfg:   cacheCfg,
+		CacheSvc:   cacheSvc,
+		GatewaySvc: gatewaySvc,
+	}
 }
 
 func (mm *ModelManager) Stop(ctx context.Context) error {
@@ -15,12 +15,15 @@ import (
 	saokey "github.com/SaoNetwork/sao-did/key"
 	"github.com/SaoNetwork/sao-node/api"
 	"github.com/SaoNetwork/sao-node/chain"
+	"github.com/SaoNetwork/sao-node/metrics"
+	"github.com/SaoNetwork/sao-node/node/fisherman"
 	"github.com/SaoNetwork/sao-node/node/gateway"
+	"github.com/SaoNetwork/sao-node/node/gc"
 	"github.com/SaoNetwork/sao-node/node/indexer"
 	"github.com/SaoNetwork/sao-node/node/indexer/gql"
 	"github.com/SaoNetwork/sao-node/node/transport"
 	"github.com/SaoNetwork/sao-node/store"
-	"github.com/SaoNetwork/sao-node/utils"
+	"github.com/SaoNetwork/sao-node/tracing"
 
 	"cosmossdk.io/math"
 	saodid "github.com/SaoNetwork/sao-did"
@@ -42,9 +45,11 @@ import (
 	"github.com/SaoNetwork/sao-node/node/repo"
 	"github.com/SaoNetwork/sao-node/node/storage"
 	"github.com/SaoNetwork/sao-node/types"
+	"github.com/SaoNetwork/sao-node/utils"
 
 	"github.com/ipfs/go-cid"
 	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
 	"github.com/libp2p/go-libp2p"
 	"github.com/libp2p/go-libp2p/core/host"
 	"github.com/libp2p/go-libp2p/core/peer"
@@ -72,12 +77,16 @@ type Node struct {
 	gatewaySvc gateway.GatewaySvcApi
 	// used by store module
 	storeSvc  *storage.StoreSvc
-	chainSvc  *chain.ChainSvc
+	chainSvc  chain.ChainSvcApi
 	manager   *model.ModelManager
 	tds       datastore.Read
+	ods       datastore.Batching
 	hfs       *gateway.HttpFileServer
 	rpcServer *http.Server
 	indexSvc  *indexer.IndexSvc
+	// used by fisherman module
+	fishermanSvc *fisherman.FishermanSvc
+	gcSvc        *gc.GcSvc
 }
 
 type JwtPayload struct {
@@ -106,6 +115,11 @@ func NewNode(ctx context.Context, repo *repo.Repo, keyringHome string, cctx *cli
 	}
 	nodeAddr := string(abytes)
 
+	stopTracing, err := tracing.Setup(ctx, &cfg.Tracing, nodeAddr)
+	if err != nil {
+		return nil, err
+	}
+
 	// p2p
 	peerKey, err := repo.PeerId()
 	if err != nil {
@@ -139,7 +153,7 @@ func NewNode(ctx context.Context, repo *repo.Repo, keyringHome string, cctx *cli
 	}
 
 	// chain
-	chainSvc, err := chain.NewChainSvc(ctx, cfg.Chain.Remote, cfg.Chain.WsEndpoint, keyringHome)
+	chainSvc, err := chain.NewChainSvcApi(ctx, cfg.Chain.Remote, cfg.Chain.WsEndpoint, keyringHome)
 	if err != nil {
 		return nil, err
 	}
@@ -181,6 +195,7 @@ func NewNode(ctx context.Context, repo *repo.Repo, keyringHome string, cctx *cli
 		stopFuncs: stopFuncs,
 		host:      host,
 		tds:       tds,
+		ods:       ods,
 		chainSvc:  chainSvc,
 	}
 
@@ -246,9 +261,26 @@ func NewNode(ctx context.Context, repo *repo.Repo, keyringHome string, cctx *cli
 		log.Info("ipfs daemon initialized")
 	}
 
-	storageManager = store.NewStoreManager(backends)
+	storageManager = store.NewStoreManager(backends, store.ReplicationPolicy{
+		Mode:               cfg.Storage.Replication.Policy,
+		Quorum:             cfg.Storage.Replication.Quorum,
+		FailureThreshold:   cfg.Storage.Replication.FailureThreshold,
+		CircuitBreakPeriod: cfg.Storage.Replication.CircuitBreakPeriod,
+	})
 	log.Info("store manager daemon initialized")
 
+	retryPolicies := utils.RetryPolicies{
+		Default:    retryPolicy(cfg.Retry.Default),
+		Chain:      retryPolicy(cfg.Retry.Chain),
+		Connect:    retryPolicy(cfg.Retry.Connect),
+		Network:    retryPolicy(cfg.Retry.Network),
+		InvalidCid: retryPolicy(cfg.Retry.InvalidCid),
+	}
+	err = retryPolicies.Validate()
+	if err != nil {
+		return nil, err
+	}
+
 	if cfg.Module.StorageEnable && cfg.Module.GatewayEnable {
 		notifyChan[types.ShardAssignProtocol] = make(chan interface{})
 		notifyChan[types.ShardCompleteProtocol] = make(chan interface{})
@@ -271,24 +303,61 @@ func NewNode(ctx context.Context, repo *repo.Repo, keyringHome string, cctx *cli
 				storageManager.AddBackend(ipfsBackend)
 			}
 		}
+		for _, f := range cfg.Storage.Local {
+			localBackend, err := store.NewLocalBackend(f.Path)
+			if err != nil {
+				return nil, err
+			}
+			err = localBackend.Open()
+			if err != nil {
+				return nil, err
+			}
+			storageManager.AddBackend(localBackend)
+		}
+		for _, f := range cfg.Storage.S3 {
+			s3Backend, err := store.NewS3Backend(store.S3Config{
+				Endpoint:        f.Endpoint,
+				Region:          f.Region,
+				Bucket:          f.Bucket,
+				Prefix:          f.Prefix,
+				AccessKeyId:     f.AccessKeyId,
+				SecretAccessKey: f.SecretAccessKey,
+				PathStyle:       f.PathStyle,
+				PartSize:        f.PartSize,
+			})
+			if err != nil {
+				return nil, err
+			}
+			err = s3Backend.Open()
+			if err != nil {
+				return nil, err
+			}
+			storageManager.AddBackend(s3Backend)
+		}
+		err = storageManager.Validate()
+		if err != nil {
+			return nil, err
+		}
 
-		sn.storeSvc, err = storage.NewStoreService(ctx, nodeAddr, chainSvc, host, transportStagingPath, storageManager, notifyChan, ods)
+		sn.storeSvc, err = storage.NewStoreService(ctx, nodeAddr, chainSvc, host, transportStagingPath, storageManager, notifyChan, ods, &cfg.Storage, retryPolicies)
 		if err != nil {
 			return nil, err
 		}
 		log.Info("storage node initialized")
 		go sn.storeSvc.Start(ctx)
+		storageManager.StartRepair(ctx, cfg.Storage.Replication.RepairInterval, sn.storeSvc.StoredShardCids)
 		sn.stopFuncs = append(sn.stopFuncs, sn.storeSvc.Stop)
 	}
 
+	var serverPath string
 	if cfg.Module.GatewayEnable {
-		serverPath := cfg.SaoHttpFileServer.HttpFileServerPath
+		serverPath = cfg.SaoHttpFileServer.HttpFileServerPath
 		if serverPath == "" {
 			serverPath = path.Join(repo.Path, "http-files")
 		}
 
 		status = status | NODE_STATUS_SERVE_GATEWAY
-		var gatewaySvc = gateway.NewGatewaySvc(ctx, nodeAddr, chainSvc, host, cfg, storageManager, notifyChan, ods, keyringHome, transportStagingPath, serverPath, rpcHandler)
+		var gatewaySvc = gateway.NewGatewaySvc(ctx, nodeAddr, chainSvc, host, cfg, storageManager, notifyChan, ods, keyringHome, transportStagingPath, serverPath, rpcHandler, retryPolicies)
 		sn.manager = model.NewModelManager(&cfg.Cache, gatewaySvc)
 		sn.gatewaySvc = gatewaySvc
 		sn.stopFuncs = append(sn.stopFuncs, sn.manager.Stop)
@@ -308,6 +377,21 @@ func NewNode(ctx context.Context, repo *repo.Repo, keyringHome string, cctx *cli
 		log.Info("gateway node initialized")
 	}
 
+	if cfg.Module.FishermanEnable {
+		if sn.gatewaySvc == nil {
+			return nil, types.Wrapf(types.ErrInvalidParameters, "fisherman module requires gateway module")
+		}
+		sn.fishermanSvc = fisherman.NewFishermanSvc(ctx, nodeAddr, chainSvc, sn.gatewaySvc, ods, &cfg.Fisherman)
+		sn.fishermanSvc.Start()
+		sn.stopFuncs = append(sn.stopFuncs, sn.fishermanSvc.Stop)
+
+		log.Info("fisherman node initialized")
+	}
+
+	sn.gcSvc = gc.NewGcSvc(ctx, chainSvc, transportStagingPath, serverPath, ods, tds, &cfg.Gc)
+	sn.gcSvc.Start()
+	sn.stopFuncs = append(sn.stopFuncs, sn.gcSvc.Stop)
+
 	if cfg.Module.IndexerEnable {
 		status = status | NODE_STATUS_SERVE_INDEXER
 		jobsDs, err := repo.Datastore(ctx, "/indexer")
@@ -334,7 +418,7 @@ func NewNode(ctx context.Context, repo *repo.Repo, keyringHome string, cctx *cli
 	}
 
 	// api server
-	rpcServer, err := newRpcServer(&sn, &cfg.Api)
+	rpcServer, err := newRpcServer(&sn, &cfg.Api, metrics.Handler(sn.metricsCollectors()...))
 	if err != nil {
 		return nil, err
 	}
@@ -423,14 +507,27 @@ func NewNode(ctx context.Context, repo *repo.Repo, keyringHome string, cctx *cli
 		}
 		return nil
 	})
+	// flush spans of the stopping modules
+	sn.stopFuncs = append(sn.stopFuncs, stopTracing)
 
 	return &sn, nil
 }
 
-func newRpcServer(ga api.SaoApi, cfg *config.API) (*http.Server, error) {
+func retryPolicy(cfg config.RetryPolicy) utils.RetryPolicy {
+	return utils.RetryPolicy{
+		Kind:        cfg.Kind,
+		MaxRetries:  cfg.MaxRetries,
+		Interval:    cfg.Interval,
+		MaxInterval: cfg.MaxInterval,
+		Multiplier:  cfg.Multiplier,
+		Jitter:      cfg.Jitter,
+	}
+}
+
+func newRpcServer(ga api.SaoApi, cfg *config.API, metricsHandler http.Handler) (*http.Server, error) {
 	log.Info("initialize rpc server")
 
-	handler, err := GatewayRpcHandler(ga, cfg.EnablePermission)
+	handler, err := GatewayRpcHandler(ga, cfg.EnablePermission, metricsHandler)
 	if err != nil {
 		return nil, types.Wrapf(types.ErrStartPRPCServerFailed, "failed to instantiate rpc handler: %v", err)
 	}
@@ -590,20 +687,7 @@ func (n *Node) ModelCreate(ctx context.Context, req *types.MetadataProposal, ord
 func (n *Node) ModelCreateFile(ctx context.Context, req *types.MetadataProposal, orderProposal *types.OrderStoreProposal, orderId uint64) (apitypes.CreateResp, error) {
 	// Asynchronous order and the content has been uploaded already
 	cidStr := orderProposal.Proposal.Cid
-	key := datastore.NewKey(types.FILE_INFO_PREFIX + cidStr)
-	if info, err := n.tds.Get(ctx, key); err == nil {
-		var fileInfo *types.ReceivedFileInfo
-		err := json.Unmarshal(info, &fileInfo)
-		if err != nil {
-			return apitypes.CreateResp{}, types.Wrap(types.ErrUnMarshalFailed, err)
-		}
-
-		basePath, err := homedir.Expand(fileInfo.Path)
-		if err != nil {
-			return apitypes.CreateResp{}, types.Wrap(types.ErrInvalidPath, err)
-		}
-
-		var path = filepath.Join(basePath, cidStr)
+	if path, err := n.uploadedFile(ctx, cidStr); err == nil {
 		file, err := os.Open(path)
 		if err != nil {
 			return apitypes.CreateResp{}, types.Wrap(types.ErrOpenFileFailed, err)
@@ -640,6 +724,38 @@ func (n *Node) ModelCreateFile(ctx context.Context, req *types.MetadataProposal,
 	}
 }
 
+/**
+ * path of the content assembled from the chunks uploaded by any peer.
+ */
+func (n *Node) uploadedFile(ctx context.Context, cidStr string) (string, error) {
+	results, err := n.tds.Query(ctx, query.Query{Prefix: types.FILE_INFO_NAMESPACE + "/" + cidStr})
+	if err != nil {
+		return "", err
+	}
+	defer results.Close()
+
+	for result := range results.Next() {
+		if result.Error != nil {
+			return "", result.Error
+		}
+
+		var fileInfo types.ReceivedFileInfo
+		err := json.Unmarshal(result.Value, &fileInfo)
+		if err != nil {
+			return "", types.Wrap(types.ErrUnMarshalFailed, err)
+		}
+		basePath, err := homedir.Expand(fileInfo.Path)
+		if err != nil {
+			return "", types.Wrap(types.ErrInvalidPath, err)
+		}
+		path := filepath.Join(basePath, cidStr)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", datastore.ErrNotFound
+}
+
 func (n *Node) ModelLoad(ctx context.Context, req *types.MetadataProposal) (apitypes.LoadResp, error) {
 	err := n.validSignature(ctx, &req.Proposal, req.Proposal.Owner, req.JwsSignature)
 	if err != nil {
@@ -859,6 +975,37 @@ func (n *Node) GetNetPeers(context.Context) ([]types.PeerInfo, error) {
 	return out, nil
 }
 
+/**
+ * the libp2p address of the node, peers found on chain with loopback addresses are never dialed,
+ * so nodes sharing a host have to be connected with NetConnect.
+ */
+func (n *Node) NetAddrsListen(context.Context) (peer.AddrInfo, error) {
+	return peer.AddrInfo{
+		ID:    n.host.ID(),
+		Addrs: n.host.Addrs(),
+	}, nil
+}
+
+func (n *Node) NetConnect(ctx context.Context, pi peer.AddrInfo) error {
+	err := n.host.Connect(ctx, pi)
+	if err != nil {
+		return types.Wrap(types.ErrConnectFailed, err)
+	}
+	return nil
+}
+
+/**
+ * close the connections to the peer and forget its addresses, it's not dialed again until it connects to this node.
+ */
+func (n *Node) NetDisconnect(ctx context.Context, p peer.ID) error {
+	n.host.Peerstore().ClearAddrs(p)
+	err := n.host.Network().ClosePeer(p)
+	if err != nil {
+		return types.Wrap(types.ErrConnectFailed, err)
+	}
+	return nil
+}
+
 func (n *Node) getSidDocFunc() func(versionId string) (*sid.SidDocument, error) {
 	return func(versionId string) (*sid.SidDocument, error) {
 		return n.chainSvc.GetSidDocument(n.ctx, versionId)
@@ -932,8 +1079,32 @@ func (n *Node) OrderList(ctx context.Context) ([]types.OrderInfo, error) {
 	return n.gatewaySvc.OrderList(ctx)
 }
 
-func (n *Node) OrderFix(ctx context.Context, id string) error {
-	return n.gatewaySvc.OrderFix(ctx, id)
+func (n *Node) OrderHistory(ctx context.Context, dataId string, orderId uint64) ([]types.OrderEvent, error) {
+	if orderId == 0 {
+		events, err := utils.ListOrderEvents(ctx, n.ods, dataId)
+		if err != nil {
+			return nil, types.Wrap(types.ErrGetFailed, err)
+		}
+		return events, nil
+	}
+
+	history, err := utils.GetOrderHistory(ctx, n.ods, dataId, orderId)
+	if err != nil {
+		return nil, types.Wrap(types.ErrGetFailed, err)
+	}
+	return history.Events, nil
+}
+
+func (n *Node) OrderFix(ctx context.Context, dataId string) error {
+	return n.gatewaySvc.OrderFix(ctx, dataId)
+}
+
+func (n *Node) OrderCancel(ctx context.Context, dataId string) error {
+	return n.gatewaySvc.OrderCancel(ctx, dataId)
+}
+
+func (n *Node) OrderReassign(ctx context.Context, dataId string, from string, to string) error {
+	return n.gatewaySvc.OrderReassign(ctx, dataId, from, to)
 }
 
 func (n *Node) ShardStatus(ctx context.Context, orderId uint64, cid cid.Cid) (types.ShardInfo, error) {
@@ -960,6 +1131,45 @@ func (n *Node) MigrateJobList(ctx context.Context) ([]types.MigrateInfo, error)
 	return n.storeSvc.MigrateList(ctx)
 }
 
+func (n *Node) IndexJobAdd(ctx context.Context, jobType string, param string, interval uint64) (string, error) {
+	if n.indexSvc == nil {
+		return "", types.Wrapf(types.ErrUnSupport, "indexer module is not enabled")
+	}
+	return n.indexSvc.AddJob(ctx, jobType, param, interval)
+}
+
+func (n *Node) IndexJobList(ctx context.Context) ([]types.IndexJobInfo, error) {
+	if n.indexSvc == nil {
+		return nil, types.Wrapf(types.ErrUnSupport, "indexer module is not enabled")
+	}
+	return n.indexSvc.ListJobs(ctx)
+}
+
+func (n *Node) IndexJobKill(ctx context.Context, jobId string) error {
+	if n.indexSvc == nil {
+		return types.Wrapf(types.ErrUnSupport, "indexer module is not enabled")
+	}
+	return n.indexSvc.KillJob(ctx, jobId)
+}
+
+func (n *Node) IndexJobReindex(ctx context.Context, jobId string) error {
+	if n.indexSvc == nil {
+		return types.Wrapf(types.ErrUnSupport, "indexer module is not enabled")
+	}
+	return n.indexSvc.ReindexJob(ctx, jobId)
+}
+
+func (n *Node) AuditHistory(ctx context.Context, provider string) ([]types.AuditRecord, error) {
+	if n.fishermanSvc == nil {
+		return nil, types.Wrapf(types.ErrUnSupport, "fisherman module is not enabled")
+	}
+	return n.fishermanSvc.AuditHistory(ctx, provider)
+}
+
+func (n *Node) GcStaging(ctx context.Context, dryRun bool) (types.GcReport, error) {
+	return n.gcSvc.Collect(ctx, dryRun)
+}
+
 func (n *Node) FaultsCheck(ctx context.Context, dataIds []string) (*apitypes.FileFaultsReportResp, error) {
 	fishmen, err := n.chainSvc.GetFishmen(ctx)
 	if err != nil {
@@ -979,34 +1189,11 @@ func (n *Node) FaultsCheck(ctx context.Context, dataIds []string) (*apitypes.Fil
 		}
 
 		for provider, shard := range meta.Shards {
-
-			passCheck := false
-
-			result := make(chan types.ShardLoadResp)
-
-			go func(result chan types.ShardLoadResp) {
-				result <- n.gatewaySvc.FetchShard(ctx, provider, shard.Cid, shard.Peer, meta.Metadata.DataId, meta.Metadata.OrderId)
-			}(result)
-
-			select {
-			case resp := <-result:
-				if resp.Code == 0 {
-					cid, err := utils.CalculateCid(resp.Content)
-					if err != nil {
-						log.Error(err.Error())
-						continue
-					}
-
-					if cid.String() == meta.Metadata.Cid {
-						passCheck = true
-					}
-				}
-
-			case <-time.After(10 * time.Second):
-				fmt.Println("Timeout")
-			}
-
-			if !passCheck {
+			err := n.gatewaySvc.AuditShard(ctx, provider, shard.Cid, shard.Peer, meta.Metadata.DataId, meta.Metadata.OrderId)
+			if types.ErrAuditPending.Is(err) {
+				log.Infof("audit of shard %d of %s is pending: %v", shard.ShardId, provider, err)
+			} else if err != nil {
+				log.Warnf("shard %d of %s failed the audit: %v", shard.ShardId, provider, err)
 				faults := faultsMap[provider]
 				if faults == nil {
 					faultsMap[provider] = make([]*saotypes.Fault, 0)
@@ -1086,44 +1273,22 @@ func (n *Node) RecoverCheck(ctx context.Context, provider string, faultIds []str
 			continue
 		}
 
-		passCheck := false
-
-		result := make(chan types.ShardLoadResp)
-
-		go func(result chan types.ShardLoadResp) {
-			result <- n.gatewaySvc.FetchShard(ctx, provider, shardMeta.Cid, peer, meta.Metadata.DataId, meta.Metadata.OrderId)
-		}(result)
-
-		select {
-		case resp := <-result:
-			if resp.Code == 0 {
-				cid, err := utils.CalculateCid(resp.Content)
-				if err != nil {
-					log.Error(err.Error())
-					continue
-				}
-
-				if cid.String() == meta.Metadata.Cid {
-					passCheck = true
-				}
-			}
-
-		case <-time.After(10 * time.Second):
-			fmt.Println("Timeout")
+		err = n.gatewaySvc.AuditShard(ctx, fault.Provider, shardMeta.Cid, peer, meta.Metadata.DataId, meta.Metadata.OrderId)
+		if err != nil {
+			log.Warnf("shard %d of %s failed the audit: %v", fault.ShardId, fault.Provider, err)
+			continue
 		}
 
-		if passCheck {
-			commit := meta.Metadata.Commits[len(meta.Metadata.Commits)-1]
-			commitId := strings.Split(commit, "\032")[0]
-			recoverableFaults = append(recoverableFaults, &saotypes.Fault{
-				DataId:   meta.Metadata.DataId,
-				OrderId:  meta.Metadata.OrderId,
-				ShardId:  fault.ShardId,
-				CommitId: commitId,
-				Provider: fault.Provider,
-				Reporter: n.address,
-			})
-		}
+		commit := meta.Metadata.Commits[len(meta.Metadata.Commits)-1]
+		commitId := strings.Split(commit, "\032")[0]
+		recoverableFaults = append(recoverableFaults, &saotypes.Fault{
+			DataId:   meta.Metadata.DataId,
+			OrderId:  meta.Metadata.OrderId,
+			ShardId:  fault.ShardId,
+			CommitId: commitId,
+			Provider: fault.Provider,
+			Reporter: n.address,
+		})
 	}
 
 	if len(recoverableFaults) > 0 {
@@ -2,51 +2,258 @@ package queue
 
 import (
 	"sync"
+	"time"
 
 	"github.com/SaoNetwork/sao-node/types"
 )
 
+// pass advanced by a request of a tenant with weight 1
+const stride = 1 << 20
+
 type WorkRequest struct {
 	Order types.OrderInfo
 	Shard types.ShardInfo
 	Job   *types.Job
 }
 
+// unix time the request is due at, a failed request waits for its next retry
+func (r *WorkRequest) retryAt() int64 {
+	if r.Order.RetryAt > r.Shard.RetryAt {
+		return r.Order.RetryAt
+	}
+	return r.Shard.RetryAt
+}
+
+type Tenant struct {
+	// share of the queue relative to other tenants, 1 if not set
+	Weight int
+	// requests of the tenant popped and not done yet, 0 means no cap
+	Concurrency int
+}
+
+/**
+ * TenantPolicy shares the queue among tenants in proportion to their weights, requests of a tenant are served in order,
+ * except that the requests not due yet are skipped.
+ */
+type TenantPolicy struct {
+	// tenant of a request, all requests belong to a single tenant if it's nil
+	Key func(*WorkRequest) string
+	// settings of the tenants not in Tenants
+	Default Tenant
+	Tenants map[string]Tenant
+}
+
+type tenantQueue struct {
+	requests []*WorkRequest
+	// virtual time of the next request, the tenant with the lowest pass is served first
+	pass    uint64
+	running int
+}
+
+/**
+ * RequestQueue is a weighted fair queue of requests, the zero value is a FIFO queue without concurrency caps.
+ */
 type RequestQueue struct {
 	sync.Mutex
-	queue []*WorkRequest
+	policy  TenantPolicy
+	tenants map[string]*tenantQueue
+	len     int
+	// pass of the last popped request, idle tenants rejoin from here instead of catching up on the idle time
+	pass uint64
+}
+
+func NewRequestQueue(policy TenantPolicy) *RequestQueue {
+	return &RequestQueue{policy: policy}
+}
+
+func (q *RequestQueue) key(x *WorkRequest) string {
+	if q.policy.Key == nil {
+		return ""
+	}
+	return q.policy.Key(x)
+}
+
+func (q *RequestQueue) tenant(key string) Tenant {
+	t, ok := q.policy.Tenants[key]
+	if !ok {
+		t = q.policy.Default
+	}
+	if t.Weight <= 0 {
+		t.Weight = 1
+	}
+	return t
 }
 
 func (q *RequestQueue) Len() int {
 	q.Lock()
 	defer q.Unlock()
 
-	return len(q.queue)
+	return q.len
+}
+
+/**
+ * index of the first request of the tenant which is due, -1 if none is.
+ */
+func (tq *tenantQueue) firstDue(now int64) int {
+	for i, r := range tq.requests {
+		if r.retryAt() <= now {
+			return i
+		}
+	}
+	return -1
+}
+
+/**
+ * number of due requests which can be popped without exceeding the concurrency caps of their tenants.
+ */
+func (q *RequestQueue) Runnable() int {
+	q.Lock()
+	defer q.Unlock()
+
+	now := time.Now().Unix()
+	n := 0
+	for key, tq := range q.tenants {
+		runnable := 0
+		for _, r := range tq.requests {
+			if r.retryAt() <= now {
+				runnable++
+			}
+		}
+		if limit := q.tenant(key).Concurrency; limit > 0 && runnable > limit-tq.running {
+			runnable = limit - tq.running
+		}
+		if runnable > 0 {
+			n += runnable
+		}
+	}
+	return n
 }
 
 func (q *RequestQueue) Push(x *WorkRequest) {
 	q.Lock()
 	defer q.Unlock()
 
-	item := x
-	q.queue = append(q.queue, item)
+	if q.tenants == nil {
+		q.tenants = make(map[string]*tenantQueue)
+	}
+	key := q.key(x)
+	tq, ok := q.tenants[key]
+	if !ok {
+		tq = &tenantQueue{pass: q.pass}
+		q.tenants[key] = tq
+	}
+	if len(tq.requests) == 0 && tq.pass < q.pass {
+		tq.pass = q.pass
+	}
+	tq.requests = append(tq.requests, x)
+	q.len++
 }
 
+/**
+ * pop the first due request of the tenant with the lowest pass which is under its concurrency cap, tenants without
+ * due requests keep their pass. Done must be called once the request is processed, or nil is returned if all tenants
+ * are capped or have no due requests.
+ */
 func (q *RequestQueue) PopFront() *WorkRequest {
 	q.Lock()
 	defer q.Unlock()
-	if len(q.queue) == 0 {
+
+	now := time.Now().Unix()
+	var key string
+	var next *tenantQueue
+	index := -1
+	for k, tq := range q.tenants {
+		if limit := q.tenant(k).Concurrency; limit > 0 && tq.running >= limit {
+			continue
+		}
+		i := tq.firstDue(now)
+		if i < 0 {
+			continue
+		}
+		if next == nil || tq.pass < next.pass || (tq.pass == next.pass && k < key) {
+			key, next, index = k, tq, i
+		}
+	}
+	if next == nil {
 		return nil
 	}
 
-	item := q.queue[0]
-	q.queue = q.queue[1:]
+	item := next.requests[index]
+	next.requests = append(next.requests[:index], next.requests[index+1:]...)
+	next.running++
+	q.pass = next.pass
+	next.pass += stride / uint64(q.tenant(key).Weight)
+	q.len--
 	return item
 }
 
+/**
+ * release the concurrency slot of a popped request.
+ */
+func (q *RequestQueue) Done(x *WorkRequest) {
+	q.Lock()
+	defer q.Unlock()
+
+	key := q.key(x)
+	tq, ok := q.tenants[key]
+	if !ok {
+		return
+	}
+	if tq.running > 0 {
+		tq.running--
+	}
+	if tq.running == 0 && len(tq.requests) == 0 {
+		delete(q.tenants, key)
+	}
+}
+
+/**
+ * 1-based position of the first queued request matching, in the order they would be popped regardless of
+ * concurrency caps. 0 if no queued request matches.
+ */
+func (q *RequestQueue) Position(match func(*WorkRequest) bool) int {
+	q.Lock()
+	defer q.Unlock()
+
+	type cursor struct {
+		key  string
+		tq   *tenantQueue
+		pass uint64
+		next int
+	}
+	var cursors []*cursor
+	for k, tq := range q.tenants {
+		if len(tq.requests) > 0 {
+			cursors = append(cursors, &cursor{key: k, tq: tq, pass: tq.pass})
+		}
+	}
+
+	for position := 1; position <= q.len; position++ {
+		var c *cursor
+		for _, cur := range cursors {
+			if cur.next >= len(cur.tq.requests) {
+				continue
+			}
+			if c == nil || cur.pass < c.pass || (cur.pass == c.pass && cur.key < c.key) {
+				c = cur
+			}
+		}
+		if c == nil {
+			break
+		}
+		if match(c.tq.requests[c.next]) {
+			return position
+		}
+		c.next++
+		c.pass += stride / uint64(q.tenant(c.key).Weight)
+	}
+	return 0
+}
+
 func (q *RequestQueue) Clean() {
 	q.Lock()
 	defer q.Unlock()
 
-	q.queue = []*WorkRequest{}
+	q.tenants = nil
+	q.len = 0
 }
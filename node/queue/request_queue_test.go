@@ -0,0 +1,99 @@
+package queue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/SaoNetwork/sao-node/types"
+
+	"github.com/stretchr/testify/require"
+)
+
+func order(owner string, dataId string) *WorkRequest {
+	return &WorkRequest{Order: types.OrderInfo{Owner: owner, DataId: dataId}}
+}
+
+func popAll(q *RequestQueue) []string {
+	var ids []string
+	for {
+		r := q.PopFront()
+		if r == nil {
+			return ids
+		}
+		ids = append(ids, r.Order.DataId)
+		q.Done(r)
+	}
+}
+
+func TestRequestQueueFifo(t *testing.T) {
+	q := &RequestQueue{}
+	q.Push(order("a", "1"))
+	q.Push(order("b", "2"))
+	q.Push(order("a", "3"))
+	require.Equal(t, 3, q.Len())
+	require.Equal(t, []string{"1", "2", "3"}, popAll(q))
+	require.Equal(t, 0, q.Len())
+}
+
+func TestRequestQueueFairness(t *testing.T) {
+	q := NewRequestQueue(TenantPolicy{
+		Key:     func(r *WorkRequest) string { return r.Order.Owner },
+		Default: Tenant{Weight: 1},
+		Tenants: map[string]Tenant{"dapp": {Weight: 2}},
+	})
+
+	// the bulk uploader queues first, but doesn't starve the others
+	for _, id := range []string{"b1", "b2", "b3", "b4", "b5", "b6"} {
+		q.Push(order("bulk", id))
+	}
+	for _, id := range []string{"d1", "d2", "d3", "d4"} {
+		q.Push(order("dapp", id))
+	}
+
+	require.Equal(t, 2, q.Position(func(r *WorkRequest) bool { return r.Order.DataId == "d1" }))
+	require.Equal(t, 0, q.Position(func(r *WorkRequest) bool { return r.Order.DataId == "x" }))
+	require.Equal(t, []string{"b1", "d1", "d2", "b2", "d3", "d4", "b3", "b4", "b5", "b6"}, popAll(q))
+}
+
+func TestRequestQueueConcurrency(t *testing.T) {
+	q := NewRequestQueue(TenantPolicy{
+		Key:     func(r *WorkRequest) string { return r.Order.Owner },
+		Default: Tenant{Concurrency: 1},
+	})
+	q.Push(order("a", "1"))
+	q.Push(order("a", "2"))
+	q.Push(order("b", "3"))
+	require.Equal(t, 2, q.Runnable())
+
+	first := q.PopFront()
+	second := q.PopFront()
+	require.Equal(t, []string{"1", "3"}, []string{first.Order.DataId, second.Order.DataId})
+	// a has reached its cap
+	require.Nil(t, q.PopFront())
+	require.Equal(t, 0, q.Runnable())
+
+	q.Done(first)
+	require.Equal(t, "2", q.PopFront().Order.DataId)
+}
+
+func TestRequestQueueRetryAt(t *testing.T) {
+	q := NewRequestQueue(TenantPolicy{
+		Key: func(r *WorkRequest) string { return r.Order.Owner },
+	})
+	waiting := order("a", "1")
+	waiting.Order.RetryAt = time.Now().Add(time.Hour).Unix()
+	q.Push(waiting)
+	q.Push(order("a", "2"))
+	q.Push(order("b", "3"))
+	q.Push(order("b", "4"))
+	require.Equal(t, 3, q.Runnable())
+
+	// the waiting request is skipped, neither it nor its tenant lose their turn
+	require.Equal(t, []string{"2", "3", "4"}, popAll(q))
+	require.Equal(t, 1, q.Len())
+	require.Equal(t, 0, q.Runnable())
+
+	waiting.Order.RetryAt = 0
+	q.Push(order("b", "5"))
+	require.Equal(t, []string{"1", "5"}, popAll(q))
+}
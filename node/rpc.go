@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/SaoNetwork/sao-node/api"
+	"github.com/SaoNetwork/sao-node/tracing"
 
 	"github.com/filecoin-project/go-jsonrpc"
 	"github.com/filecoin-project/go-jsonrpc/auth"
@@ -39,7 +40,7 @@ func ServeRPC(h http.Handler, addr multiaddr.Multiaddr) (*http.Server, error) {
 	return srv, err
 }
 
-func GatewayRpcHandler(ga api.SaoApi, enablePermission bool) (http.Handler, error) {
+func GatewayRpcHandler(ga api.SaoApi, enablePermission bool, metricsHandler http.Handler) (http.Handler, error) {
 	m := mux.NewRouter()
 
 	if enablePermission {
@@ -49,7 +50,8 @@ func GatewayRpcHandler(ga api.SaoApi, enablePermission bool) (http.Handler, erro
 	rpcServer := jsonrpc.NewServer()
 	rpcServer.Register("Sao", ga)
 
-	m.Handle("/rpc/v0", rpcServer)
+	m.Handle("/rpc/v0", tracing.HttpHandler("Sao.rpc", rpcServer))
+	m.Handle("/metrics", metricsHandler)
 
 	var handler = &auth.Handler{
 		Next: m.ServeHTTP,
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"io"
 
 	"github.com/SaoNetwork/sao-node/types"
 )
@@ -9,12 +10,25 @@ import (
 type StorageProtocol interface {
 	RequestShardComplete(ctx context.Context, req types.ShardCompleteReq, peer string) types.ShardCompleteResp
 	RequestShardStore(ctx context.Context, req types.ShardLoadReq, peer string) types.ShardLoadResp
+	// RequestShardStoreStream writes the shard content into w starting from req.Offset instead of buffering it in the response.
+	RequestShardStoreStream(ctx context.Context, req types.ShardLoadReq, peer string, w io.Writer) types.ShardLoadResp
 	RequestShardMigrate(ctx context.Context, req types.ShardMigrateReq, peer string) types.ShardMigrateResp
+	// RequestShardMigrateStream sends the content returned by newReader in chunks, newReader is called again on every retry.
+	RequestShardMigrateStream(ctx context.Context, req types.ShardMigrateReq, peer string, newReader func() (io.Reader, error)) types.ShardMigrateResp
 	Stop(ctx context.Context) error
 }
 
 type StorageProtocolHandler interface {
 	HandleShardAssign(req types.ShardAssignReq) types.ShardAssignResp
 	HandleShardLoad(req types.ShardLoadReq, remotePeerId string) types.ShardLoadResp
+	/**
+	 * the returned reader, if not nil, provides the shard content to be sent after the response.
+	 */
+	HandleShardLoadStream(req types.ShardLoadReq, remotePeerId string) (types.ShardLoadResp, io.Reader)
 	HandleShardMigrate(req types.ShardMigrateReq) types.ShardMigrateResp
+	/**
+	 * acked is false if the request is rejected before the content offset is acknowledged to the sender.
+	 */
+	HandleShardMigrateStream(req types.ShardMigrateReq, s io.ReadWriter) (resp types.ShardMigrateResp, acked bool)
+	HandleShardChallenge(req types.ShardChallengeReq, remotePeerId string) types.ShardChallengeResp
 }
@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"time"
@@ -91,9 +92,53 @@ func (l LocalStorageProtocol) RequestShardStore(ctx context.Context, req types.S
 	}
 }
 
+func (l LocalStorageProtocol) RequestShardStoreStream(ctx context.Context, req types.ShardLoadReq, _ string, w io.Writer) types.ShardLoadResp {
+	resp := types.ShardLoadResp{
+		OrderId:   req.OrderId,
+		Cid:       req.Cid,
+		RequestId: req.RequestId,
+	}
+
+	path, err := homedir.Expand(l.stagingPath)
+	if err != nil {
+		resp.ResponseId = time.Now().UnixMilli()
+		resp.Code = types.ErrorCodeInternalErr
+		resp.Message = fmt.Sprintf("invalid path: %s", l.stagingPath)
+		return resp
+	}
+
+	filename := filepath.Join(path, req.Owner, req.Cid.String()+"-"+req.DataId)
+	file, err := os.Open(filename)
+	if err != nil {
+		resp.ResponseId = time.Now().UnixMilli()
+		resp.Code = types.ErrorCodeInternalErr
+		resp.Message = fmt.Sprintf("open file failed: %s", filename)
+		return resp
+	}
+	defer file.Close()
+
+	_, err = file.Seek(int64(req.Offset), io.SeekStart)
+	if err == nil {
+		_, err = io.Copy(w, file)
+	}
+	resp.ResponseId = time.Now().UnixMilli()
+	if err != nil {
+		resp.Code = types.ErrorCodeInternalErr
+		resp.Message = fmt.Sprintf("read file failed: %s, %v", filename, err)
+	}
+	return resp
+}
+
 func (l LocalStorageProtocol) RequestShardMigrate(ctx context.Context, req types.ShardMigrateReq, _ string) types.ShardMigrateResp {
 	return types.ShardMigrateResp{
 		Code:    types.ErrorCodeInternalErr,
 		Message: "unsupported",
 	}
 }
+
+func (l LocalStorageProtocol) RequestShardMigrateStream(ctx context.Context, req types.ShardMigrateReq, _ string, _ func() (io.Reader, error)) types.ShardMigrateResp {
+	return types.ShardMigrateResp{
+		Code:    types.ErrorCodeInternalErr,
+		Message: "unsupported",
+	}
+}
@@ -3,9 +3,13 @@ package storage
 import (
 	"context"
 	"fmt"
+	"io"
+	"strconv"
 	"time"
 
+	"github.com/SaoNetwork/sao-node/metrics"
 	"github.com/SaoNetwork/sao-node/node/transport"
+	"github.com/SaoNetwork/sao-node/tracing"
 	"github.com/SaoNetwork/sao-node/types"
 
 	"github.com/libp2p/go-libp2p/core/host"
@@ -28,6 +32,9 @@ func NewStreamStorageProtocol(
 	host.SetStreamHandler(types.ShardAssignProtocol, ssp.handleShardAssign)
 	host.SetStreamHandler(types.ShardLoadProtocol, ssp.handleShardLoad)
 	host.SetStreamHandler(types.ShardMigrateProtocol, ssp.handleShardMigrate)
+	host.SetStreamHandler(types.ShardLoadStreamProtocol, ssp.handleShardLoadStream)
+	host.SetStreamHandler(types.ShardMigrateStreamProtocol, ssp.handleShardMigrateStream)
+	host.SetStreamHandler(types.ShardChallengeProtocol, ssp.handleShardChallenge)
 	host.SetStreamHandler(types.ShardPingPongProtocol, transport.HandlePingRequest)
 
 	return ssp
@@ -38,6 +45,9 @@ func (l StreamStorageProtocol) Stop(ctx context.Context) error {
 	l.host.RemoveStreamHandler(types.ShardAssignProtocol)
 	l.host.RemoveStreamHandler(types.ShardLoadProtocol)
 	l.host.RemoveStreamHandler(types.ShardMigrateProtocol)
+	l.host.RemoveStreamHandler(types.ShardLoadStreamProtocol)
+	l.host.RemoveStreamHandler(types.ShardMigrateStreamProtocol)
+	l.host.RemoveStreamHandler(types.ShardChallengeProtocol)
 	return nil
 }
 
@@ -73,6 +83,79 @@ func (l StreamStorageProtocol) handleShardMigrate(s network.Stream) {
 	respond(l.HandleShardMigrate(req))
 }
 
+func (l StreamStorageProtocol) handleShardMigrateStream(s network.Stream) {
+	defer s.Close()
+
+	// Set a deadline on reading from the stream so it doesn't hang
+	_ = s.SetReadDeadline(time.Now().Add(transport.STREAM_TIMEOUT))
+	defer s.SetReadDeadline(time.Time{}) // nolint
+
+	var req types.ShardMigrateReq
+	err := req.Unmarshal(s, types.FormatCbor)
+	if err != nil {
+		ack := types.ShardStreamAck{
+			Code:    types.ErrorCodeInvalidRequest,
+			Message: fmt.Sprintf("failed to unmarshal request: %v", err),
+		}
+		if err = ack.Marshal(s, types.FormatCbor); err != nil {
+			log.Error(err.Error())
+		}
+		return
+	}
+
+	resp, acked := l.HandleShardMigrateStream(req, s)
+	if !acked {
+		ack := types.ShardStreamAck{
+			Code:    resp.Code,
+			Message: resp.Message,
+		}
+		if err = ack.Marshal(s, types.FormatCbor); err != nil {
+			log.Error(err.Error())
+		}
+		return
+	}
+
+	err = resp.Marshal(s, types.FormatCbor)
+	if err != nil {
+		log.Error(err.Error())
+		return
+	}
+
+	if err = s.CloseWrite(); err != nil {
+		log.Error(err.Error())
+		return
+	}
+}
+
+func (l StreamStorageProtocol) handleShardLoadStream(s network.Stream) {
+	defer s.Close()
+
+	// Set a deadline on reading from the stream so it doesn't hang
+	_ = s.SetReadDeadline(time.Now().Add(30 * time.Second))
+	defer s.SetReadDeadline(time.Time{}) // nolint
+
+	var req types.ShardLoadReq
+	err := req.Unmarshal(s, types.FormatCbor)
+	if err != nil {
+		transport.RespondShardStream(s, types.ShardLoadResp{
+			Code:       types.ErrorCodeInvalidRequest,
+			Message:    fmt.Sprintf("failed to unmarshal request: %v", err),
+			OrderId:    req.OrderId,
+			Cid:        req.Cid,
+			RequestId:  req.RequestId,
+			ResponseId: time.Now().UnixMilli(),
+		}, nil, 0)
+		return
+	}
+
+	start := time.Now()
+	_, span := tracing.StartHandlerSpan(context.Background(), "HandleShardLoad", &req.TraceContext, tracing.CidKey.String(req.Cid.String()))
+	resp, reader := l.HandleShardLoadStream(req, s.Conn().RemotePeer().String())
+	transport.RespondShardStream(s, resp, reader, req.Offset)
+	tracing.EndResponse(span, resp.Code, resp.Message)
+	observeShardLoad(start, resp.Code)
+}
+
 func (l StreamStorageProtocol) handleShardLoad(s network.Stream) {
 	defer s.Close()
 
@@ -107,7 +190,52 @@ func (l StreamStorageProtocol) handleShardLoad(s network.Stream) {
 		return
 	}
 
-	respond(l.HandleShardLoad(req, s.Conn().RemotePeer().String()))
+	start := time.Now()
+	_, span := tracing.StartHandlerSpan(context.Background(), "HandleShardLoad", &req.TraceContext, tracing.CidKey.String(req.Cid.String()))
+	resp := l.HandleShardLoad(req, s.Conn().RemotePeer().String())
+	respond(resp)
+	tracing.EndResponse(span, resp.Code, resp.Message)
+	observeShardLoad(start, resp.Code)
+}
+
+func observeShardLoad(start time.Time, code uint64) {
+	metrics.ShardLoadDuration.WithLabelValues(strconv.FormatUint(code, 10)).Observe(time.Since(start).Seconds())
+}
+
+func (l StreamStorageProtocol) handleShardChallenge(s network.Stream) {
+	defer s.Close()
+
+	respond := func(resp types.ShardChallengeResp) {
+		err := resp.Marshal(s, types.FormatCbor)
+		if err != nil {
+			log.Error(err.Error())
+			return
+		}
+
+		if err = s.CloseWrite(); err != nil {
+			log.Error(err.Error())
+			return
+		}
+	}
+
+	// Set a deadline on reading from the stream so it doesn't hang
+	_ = s.SetReadDeadline(time.Now().Add(30 * time.Second))
+	defer s.SetReadDeadline(time.Time{}) // nolint
+
+	var req types.ShardChallengeReq
+	err := req.Unmarshal(s, types.FormatCbor)
+	if err != nil {
+		respond(types.ShardChallengeResp{
+			Code:       types.ErrorCodeInvalidRequest,
+			Message:    fmt.Sprintf("failed to unmarshal request: %v", err),
+			Cid:        req.Cid,
+			RequestId:  req.RequestId,
+			ResponseId: time.Now().UnixMilli(),
+		})
+		return
+	}
+
+	respond(l.HandleShardChallenge(req, s.Conn().RemotePeer().String()))
 }
 
 func (l StreamStorageProtocol) handleShardAssign(s network.Stream) {
@@ -138,7 +266,10 @@ func (l StreamStorageProtocol) handleShardAssign(s network.Stream) {
 			Message: fmt.Sprintf("failed to unmarshal request: %v", err),
 		})
 	}
-	respond(l.HandleShardAssign(req))
+	_, span := tracing.StartHandlerSpan(context.Background(), "HandleShardAssign", &req.TraceContext, tracing.OrderIdKey.Int64(int64(req.OrderId)))
+	resp := l.HandleShardAssign(req)
+	tracing.EndResponse(span, resp.Code, resp.Message)
+	respond(resp)
 }
 
 func (l StreamStorageProtocol) RequestShardMigrate(
@@ -150,13 +281,30 @@ func (l StreamStorageProtocol) RequestShardMigrate(
 	err := transport.HandleRequest(ctx, peer, l.host, types.ShardMigrateProtocol, &req, &resp, false)
 	if err != nil {
 		resp = types.ShardMigrateResp{
-			Code:    types.ErrorCodeInternalErr,
+			Code:    types.TransportErrorCode(err),
 			Message: fmt.Sprintf("transport migrate request error: %v", err),
 		}
 	}
 	return resp
 }
 
+func (l StreamStorageProtocol) RequestShardMigrateStream(
+	ctx context.Context,
+	req types.ShardMigrateReq,
+	peer string,
+	newReader func() (io.Reader, error),
+) types.ShardMigrateResp {
+	resp := types.ShardMigrateResp{}
+	err := transport.RequestShardMigrateStream(ctx, peer, l.host, req, &resp, newReader)
+	if err != nil {
+		resp = types.ShardMigrateResp{
+			Code:    types.TransportErrorCode(err),
+			Message: fmt.Sprintf("transport migrate stream error: %v", err),
+		}
+	}
+	return resp
+}
+
 func (l StreamStorageProtocol) RequestShardComplete(ctx context.Context, req types.ShardCompleteReq, peer string) types.ShardCompleteResp {
 	resp := types.ShardCompleteResp{}
 	err := transport.HandleRequest(
@@ -170,7 +318,7 @@ func (l StreamStorageProtocol) RequestShardComplete(ctx context.Context, req typ
 	)
 	if err != nil {
 		resp = types.ShardCompleteResp{
-			Code:        types.ErrorCodeInternalErr,
+			Code:        types.TransportErrorCode(err),
 			Message:     fmt.Sprintf("transport complete request error: %v", err),
 			Recoverable: true,
 		}
@@ -191,7 +339,7 @@ func (l StreamStorageProtocol) RequestShardStore(ctx context.Context, req types.
 	)
 	if err != nil {
 		resp = types.ShardLoadResp{
-			Code:       types.ErrorCodeInternalErr,
+			Code:       types.TransportErrorCode(err),
 			Message:    fmt.Sprintf("transport complete request error: %v", err),
 			OrderId:    req.OrderId,
 			Cid:        req.Cid,
@@ -201,3 +349,28 @@ func (l StreamStorageProtocol) RequestShardStore(ctx context.Context, req types.
 	}
 	return resp
 }
+
+func (l StreamStorageProtocol) RequestShardStoreStream(ctx context.Context, req types.ShardLoadReq, peer string, w io.Writer) types.ShardLoadResp {
+	resp := types.ShardLoadResp{}
+	err := transport.RequestShardStream(
+		ctx,
+		peer,
+		l.host,
+		types.ShardStoreStreamProtocol,
+		types.ShardStoreProtocol,
+		req,
+		&resp,
+		w,
+	)
+	if err != nil {
+		resp = types.ShardLoadResp{
+			Code:       types.TransportErrorCode(err),
+			Message:    fmt.Sprintf("transport store stream error: %v", err),
+			OrderId:    req.OrderId,
+			Cid:        req.Cid,
+			RequestId:  req.RequestId,
+			ResponseId: time.Now().UnixMilli(),
+		}
+	}
+	return resp
+}
@@ -0,0 +1,176 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/SaoNetwork/sao-node/types"
+)
+
+// shards in these states hold space in the store backends
+func holdsSpace(state types.ShardState) bool {
+	return state < types.ShardStateTerminate
+}
+
+/**
+ * recompute the bytes held by the local shards from the datastore.
+ */
+func (ss *StoreSvc) refreshUsage(ctx context.Context) (uint64, error) {
+	shards, err := ss.ShardList(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var used uint64
+	for _, shard := range shards {
+		if holdsSpace(shard.State) {
+			used += shard.Size
+		}
+	}
+
+	ss.usageLk.Lock()
+	ss.usedBytes = used
+	ss.usageLk.Unlock()
+	return used, nil
+}
+
+/**
+ * maximum bytes of shards this node accepts, 0 means unlimited.
+ */
+func (ss *StoreSvc) capacityLimit(ctx context.Context) (uint64, error) {
+	if ss.cfg.Capacity > 0 {
+		return ss.cfg.Capacity, nil
+	}
+
+	pledge, err := ss.chainSvc.GetPledge(ctx, ss.nodeAddress)
+	if err != nil {
+		return 0, err
+	}
+	if pledge.TotalStorage <= 0 {
+		return 0, nil
+	}
+	return uint64(pledge.TotalStorage), nil
+}
+
+/**
+ * account size bytes for a new shard, fails if the declared capacity or the free space of backends is exceeded.
+ */
+func (ss *StoreSvc) reserveSpace(ctx context.Context, size uint64) error {
+	limit, err := ss.capacityLimit(ctx)
+	if err != nil {
+		return err
+	}
+	total, free, err := ss.storeManager.Capacity()
+	if err != nil {
+		return err
+	}
+
+	ss.usageLk.Lock()
+	defer ss.usageLk.Unlock()
+
+	if limit > 0 && ss.usedBytes+size > limit {
+		return types.Wrapf(types.ErrInsufficientSpace, "used %d + shard %d exceeds capacity %d", ss.usedBytes, size, limit)
+	}
+	if total > 0 && free < size {
+		return types.Wrapf(types.ErrInsufficientSpace, "shard %d exceeds free space %d of backends", size, free)
+	}
+	ss.usedBytes += size
+	return nil
+}
+
+func (ss *StoreSvc) releaseSpace(size uint64) {
+	ss.usageLk.Lock()
+	defer ss.usageLk.Unlock()
+
+	if ss.usedBytes > size {
+		ss.usedBytes -= size
+	} else {
+		ss.usedBytes = 0
+	}
+}
+
+func (ss *StoreSvc) reconcileLoop(ctx context.Context) {
+	if ss.cfg.ReconcileInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(ss.cfg.ReconcileInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			err := ss.Reconcile(ctx)
+			if err != nil {
+				log.Errorf("reconcile vstorage error: %v", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+/**
+ * compare the vstorage declared on chain with the local usage and the backends capacity,
+ * the declared vstorage is adjusted if AutoReconcile is set.
+ */
+func (ss *StoreSvc) Reconcile(ctx context.Context) error {
+	used, err := ss.refreshUsage(ctx)
+	if err != nil {
+		return err
+	}
+	for _, usage := range ss.storeManager.Usage() {
+		log.Infof("backend %s: used=%d(known=%v) total=%d free=%d(known=%v)",
+			usage.Id, usage.Used, usage.UsedKnown, usage.Total, usage.Free, usage.CapacityKnown)
+	}
+
+	pledge, err := ss.chainSvc.GetPledge(ctx, ss.nodeAddress)
+	if err != nil {
+		return err
+	}
+	declared := uint64(0)
+	if pledge.TotalStorage > 0 {
+		declared = uint64(pledge.TotalStorage)
+	}
+	if pledge.UsedStorage >= 0 && uint64(pledge.UsedStorage) != used {
+		log.Warnf("used storage on chain is %d, but shards hold %d bytes locally", pledge.UsedStorage, used)
+	}
+
+	target := ss.cfg.Capacity
+	if target == 0 {
+		total, _, err := ss.storeManager.Capacity()
+		if err != nil {
+			return err
+		}
+		target = total
+	}
+	if target == 0 {
+		// capacity of backends is unknown
+		return nil
+	}
+	if target < used {
+		target = used
+	}
+	if target == declared {
+		return nil
+	}
+
+	log.Warnf("declared vstorage %d differs from the capacity %d", declared, target)
+	if !ss.cfg.AutoReconcile {
+		return nil
+	}
+
+	if target > declared {
+		tx, err := ss.chainSvc.AddVstorage(ctx, ss.nodeAddress, target-declared)
+		if err != nil {
+			return err
+		}
+		log.Infof("added vstorage %d, tx: %s", target-declared, tx)
+	} else {
+		tx, err := ss.chainSvc.RemoveVstorage(ctx, ss.nodeAddress, declared-target)
+		if err != nil {
+			return err
+		}
+		log.Infof("removed vstorage %d, tx: %s", declared-target, tx)
+	}
+	return nil
+}
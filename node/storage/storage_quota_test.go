@@ -0,0 +1,182 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/SaoNetwork/sao-node/chain"
+	"github.com/SaoNetwork/sao-node/node/config"
+	"github.com/SaoNetwork/sao-node/node/queue"
+	"github.com/SaoNetwork/sao-node/store"
+	"github.com/SaoNetwork/sao-node/types"
+	"github.com/SaoNetwork/sao-node/utils"
+
+	nodetypes "github.com/SaoNetwork/sao/x/node/types"
+	saotypes "github.com/SaoNetwork/sao/x/sao/types"
+	"github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	"github.com/stretchr/testify/require"
+)
+
+func newQuotaTestSvc(t *testing.T, mc *chain.MockChainSvc, nodeAddress string, cfg *config.Storage) *StoreSvc {
+	backend, err := store.NewLocalBackend(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, backend.Open())
+
+	return &StoreSvc{
+		nodeAddress:  nodeAddress,
+		chainSvc:     mc,
+		schedQueue:   &queue.RequestQueue{},
+		storeManager: store.NewStoreManager([]store.StoreBackend{backend}, store.ReplicationPolicy{}),
+		ctx:          context.Background(),
+		orderDs:      dssync.MutexWrap(datastore.NewMapDatastore()),
+		cfg:          cfg,
+		processing:   make(map[string]struct{}),
+	}
+}
+
+func TestReserveSpace(t *testing.T) {
+	ctx := context.Background()
+
+	mc, err := chain.NewMockChainSvc(ctx, "mock://TestReserveSpace?blocktime=1h", "")
+	require.NoError(t, err)
+	defer mc.Stop(ctx)
+
+	ss := newQuotaTestSvc(t, mc, "sao1sp1", &config.Storage{Capacity: 100})
+
+	require.NoError(t, ss.reserveSpace(ctx, 60))
+	err = ss.reserveSpace(ctx, 50)
+	require.True(t, types.ErrInsufficientSpace.Is(err))
+	require.Equal(t, uint64(60), ss.usedBytes)
+
+	ss.releaseSpace(20)
+	require.NoError(t, ss.reserveSpace(ctx, 50))
+	require.Equal(t, uint64(90), ss.usedBytes)
+
+	// released bytes never go below zero
+	ss.releaseSpace(200)
+	require.Zero(t, ss.usedBytes)
+
+	// the declared vstorage is the limit without a configured capacity
+	ss.cfg.Capacity = 0
+	pledge, err := mc.GetPledge(ctx, ss.nodeAddress)
+	require.NoError(t, err)
+	_, err = mc.RemoveVstorage(ctx, ss.nodeAddress, uint64(pledge.TotalStorage)-100)
+	require.NoError(t, err)
+	require.NoError(t, ss.reserveSpace(ctx, 100))
+	require.True(t, types.ErrInsufficientSpace.Is(ss.reserveSpace(ctx, 1)))
+}
+
+func TestHandleShardAssignQuota(t *testing.T) {
+	ctx := context.Background()
+
+	mc, err := chain.NewMockChainSvc(ctx, "mock://TestHandleShardAssignQuota?blocktime=1h", "")
+	require.NoError(t, err)
+	defer mc.Stop(ctx)
+
+	gateway := "sao1gateway"
+	sp := "sao1sp1"
+	_, err = mc.Reset(ctx, gateway, "/ip4/127.0.0.1/tcp/5153/p2p/gateway", nodetypes.NODE_STATUS_ONLINE|nodetypes.NODE_STATUS_SERVE_GATEWAY, nil, nil)
+	require.NoError(t, err)
+	_, err = mc.Reset(ctx, sp, "/ip4/127.0.0.1/tcp/5153/p2p/"+sp, nodetypes.NODE_STATUS_ONLINE|nodetypes.NODE_STATUS_SERVE_STORAGE|nodetypes.NODE_STATUS_ACCEPT_ORDER, nil, nil)
+	require.NoError(t, err)
+
+	content := []byte("hello storage quota")
+	contentCid, err := utils.CalculateCid(content)
+	require.NoError(t, err)
+	dataId := utils.GenerateDataId("quota")
+	resp, txHash, height, err := mc.StoreOrder(ctx, gateway, &types.OrderStoreProposal{
+		Proposal: saotypes.Proposal{
+			Owner:     "did:key:owner",
+			Provider:  gateway,
+			GroupId:   "group",
+			Duration:  100,
+			Replica:   1,
+			Timeout:   10,
+			DataId:    dataId,
+			CommitId:  dataId,
+			Cid:       contentCid.String(),
+			Size_:     uint64(len(content)),
+			Operation: 1,
+		},
+	})
+	require.NoError(t, err)
+
+	ss := newQuotaTestSvc(t, mc, sp, &config.Storage{Capacity: uint64(len(content)) - 1})
+	req := types.ShardAssignReq{
+		OrderId:       resp.OrderId,
+		DataId:        dataId,
+		Assignee:      sp,
+		TxHash:        txHash,
+		Height:        height,
+		AssignTxType:  types.AssignTxTypeStore,
+		TimeoutHeight: uint64(height) + 10,
+	}
+
+	// the shard is refused without enough space
+	assignResp := ss.HandleShardAssign(req)
+	require.Equal(t, uint64(types.ErrorCodeInsufficientSpace), assignResp.Code)
+	require.Zero(t, ss.usedBytes)
+
+	// the shard is accepted once, the space is reserved by its size
+	ss.cfg.Capacity = 100
+	for i := 0; i < 2; i++ {
+		assignResp = ss.HandleShardAssign(req)
+		require.Equal(t, uint64(0), assignResp.Code, assignResp.Message)
+		require.Equal(t, uint64(len(content)), ss.usedBytes)
+	}
+	shard, err := utils.GetShard(ctx, ss.orderDs, resp.OrderId, contentCid)
+	require.NoError(t, err)
+	require.Equal(t, uint64(len(content)), shard.Size)
+}
+
+func TestReconcile(t *testing.T) {
+	ctx := context.Background()
+
+	mc, err := chain.NewMockChainSvc(ctx, "mock://TestReconcile?blocktime=1h", "")
+	require.NoError(t, err)
+	defer mc.Stop(ctx)
+
+	sp := "sao1sp1"
+	ss := newQuotaTestSvc(t, mc, sp, &config.Storage{})
+	for i, state := range []types.ShardState{types.ShardStateStored, types.ShardStateComplete, types.ShardStateTerminate} {
+		content := []byte{byte(i)}
+		shardCid, err := utils.CalculateCid(content)
+		require.NoError(t, err)
+		require.NoError(t, utils.SaveShard(ctx, ss.orderDs, types.ShardInfo{
+			OrderId: uint64(i + 1),
+			Cid:     shardCid,
+			Size:    100,
+			State:   state,
+		}))
+	}
+
+	// the drifted usage is recomputed from the local shards, terminated ones hold no space
+	ss.usedBytes = 1000
+	require.NoError(t, ss.Reconcile(ctx))
+	require.Equal(t, uint64(200), ss.usedBytes)
+
+	pledge, err := mc.GetPledge(ctx, sp)
+	require.NoError(t, err)
+	declared := uint64(pledge.TotalStorage)
+
+	// the declared vstorage is only adjusted with AutoReconcile
+	ss.cfg.Capacity = declared + 1000
+	require.NoError(t, ss.Reconcile(ctx))
+	pledge, err = mc.GetPledge(ctx, sp)
+	require.NoError(t, err)
+	require.Equal(t, int64(declared), pledge.TotalStorage)
+
+	ss.cfg.AutoReconcile = true
+	require.NoError(t, ss.Reconcile(ctx))
+	pledge, err = mc.GetPledge(ctx, sp)
+	require.NoError(t, err)
+	require.Equal(t, int64(declared+1000), pledge.TotalStorage)
+
+	// the capacity is never declared below the local usage
+	ss.cfg.Capacity = 1
+	require.NoError(t, ss.Reconcile(ctx))
+	pledge, err = mc.GetPledge(ctx, sp)
+	require.NoError(t, err)
+	require.Equal(t, int64(200), pledge.TotalStorage)
+}
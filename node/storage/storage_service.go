@@ -5,12 +5,19 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/SaoNetwork/sao-node/chain"
+	"github.com/SaoNetwork/sao-node/metrics"
+	"github.com/SaoNetwork/sao-node/node/config"
 	"github.com/SaoNetwork/sao-node/node/queue"
+	"github.com/SaoNetwork/sao-node/node/transport"
 	"github.com/SaoNetwork/sao-node/store"
+	"github.com/SaoNetwork/sao-node/tracing"
 	"github.com/SaoNetwork/sao-node/types"
 	"github.com/SaoNetwork/sao-node/utils"
 
@@ -25,6 +32,7 @@ import (
 	"github.com/dvsekhvalnov/jose2go/base64url"
 	"github.com/ipfs/go-cid"
 	"github.com/ipfs/go-datastore"
+	"github.com/mitchellh/go-homedir"
 
 	"github.com/SaoNetwork/sao-did/sid"
 	logging "github.com/ipfs/go-log/v2"
@@ -33,6 +41,7 @@ import (
 	saodidtypes "github.com/SaoNetwork/sao-did/types"
 
 	"github.com/libp2p/go-libp2p/core/host"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var log = logging.Logger("storage")
@@ -40,7 +49,9 @@ var log = logging.Logger("storage")
 const (
 	WINDOW_SIZE       = 10
 	SCHEDULE_INTERVAL = 1
-	MAX_RETRIES       = 3
+	// blocks the gateway is given to push a shard assigned on chain before it's picked up from the chain event
+	SHARD_TASK_GRACE_BLOCKS = 3
+	SHARD_TASK_EVENT        = "shard-task"
 )
 
 type MigrateRequest struct {
@@ -55,7 +66,7 @@ type MigrateRequest struct {
 
 type StoreSvc struct {
 	nodeAddress        string
-	chainSvc           *chain.ChainSvc
+	chainSvc           chain.ChainSvcApi
 	schedQueue         *queue.RequestQueue
 	migrateChan        chan MigrateRequest
 	host               host.Host
@@ -64,28 +75,48 @@ type StoreSvc struct {
 	ctx                context.Context
 	orderDs            datastore.Batching
 	storageProtocolMap map[string]StorageProtocol
+	cfg                *config.Storage
+	retry              utils.RetryPolicies
+	shardTaskChan      chan *chain.ShardTask
+
+	// serializes the shard assignments pushed by gateways and the ones picked up from chain events
+	taskLk sync.Mutex
+
+	processingLk sync.Mutex
+	// shards being processed by a worker, a shard is queued again by gateway pushes, chain events and fixes
+	processing map[string]struct{}
+
+	usageLk sync.Mutex
+	// bytes held by shards accepted by this node
+	usedBytes uint64
 }
 
 func NewStoreService(
 	ctx context.Context,
 	nodeAddress string,
-	chainSvc *chain.ChainSvc,
+	chainSvc chain.ChainSvcApi,
 	host host.Host,
 	stagingPath string,
 	storeManager *store.StoreManager,
 	notifyChan map[string]chan interface{},
 	orderDs datastore.Batching,
+	cfg *config.Storage,
+	retry utils.RetryPolicies,
 ) (*StoreSvc, error) {
 	ss := &StoreSvc{
-		nodeAddress:  nodeAddress,
-		chainSvc:     chainSvc,
-		schedQueue:   &queue.RequestQueue{},
-		migrateChan:  make(chan MigrateRequest),
-		host:         host,
-		stagingPath:  stagingPath,
-		storeManager: storeManager,
-		ctx:          ctx,
-		orderDs:      orderDs,
+		nodeAddress:   nodeAddress,
+		chainSvc:      chainSvc,
+		schedQueue:    &queue.RequestQueue{},
+		migrateChan:   make(chan MigrateRequest),
+		host:          host,
+		stagingPath:   stagingPath,
+		storeManager:  storeManager,
+		ctx:           ctx,
+		orderDs:       orderDs,
+		cfg:           cfg,
+		retry:         retry,
+		shardTaskChan: make(chan *chain.ShardTask),
+		processing:    make(map[string]struct{}),
 	}
 
 	ss.storageProtocolMap = make(map[string]StorageProtocol)
@@ -102,13 +133,133 @@ func NewStoreService(
 		return nil, err
 	}
 
+	_, err = ss.refreshUsage(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		err := chain.RetrySubscribe(ctx, SHARD_TASK_EVENT, func(ctx context.Context) error {
+			fromHeight, err := utils.GetEventHeight(ctx, orderDs, SHARD_TASK_EVENT)
+			if err != nil {
+				return err
+			}
+			return chainSvc.SubscribeShardTask(ctx, nodeAddress, fromHeight, ss.shardTaskChan)
+		})
+		if err == nil {
+			ss.processShardTasks(ctx)
+		}
+	}()
+
 	go ss.processIncompleteShards(ctx)
 	go ss.processMigrateLoop(ctx)
 	go ss.processExpire(ctx)
+	go ss.reconcileLoop(ctx)
 
 	return ss, nil
 }
 
+/**
+ * handle the shards assigned to this node on chain, so that a shard is stored even if the gateway's push is lost.
+ */
+func (ss *StoreSvc) processShardTasks(ctx context.Context) {
+	for {
+		select {
+		case task := <-ss.shardTaskChan:
+			err := ss.handleShardTask(ctx, task)
+			if err != nil {
+				log.Warnf("handle shard task of order %d failed: %v", task.OrderId, err)
+			}
+			// txs of the same height are replayed after restart, handling a shard task twice is harmless
+			err = utils.SaveEventHeight(ctx, ss.orderDs, SHARD_TASK_EVENT, task.Height-1)
+			if err != nil {
+				log.Warnf("save %s event height %d failed: %v", SHARD_TASK_EVENT, task.Height, err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (ss *StoreSvc) handleShardTask(ctx context.Context, task *chain.ShardTask) error {
+	// the gateway's push goes first, it carries the shard content
+	for {
+		height, err := ss.chainSvc.GetLastHeight(ctx)
+		if err != nil {
+			return err
+		}
+		if height >= task.Height+SHARD_TASK_GRACE_BLOCKS {
+			break
+		}
+		select {
+		case <-time.After(time.Second * SCHEDULE_INTERVAL):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	ss.taskLk.Lock()
+	defer ss.taskLk.Unlock()
+
+	assigned, err := ss.hasOrderShard(ctx, task.OrderId)
+	if err != nil || assigned {
+		return err
+	}
+
+	order, err := ss.chainSvc.GetOrder(ctx, task.OrderId)
+	if err != nil {
+		return err
+	}
+	shard, exists := order.Shards[ss.nodeAddress]
+	if !exists || shard.Status != ordertypes.ShardWaiting {
+		// completed, timed out or migrated already
+		return nil
+	}
+	// the chain only knows the order content cid until the shard completes, the gateway tells the erasure
+	// coded shard when it assigns the shard, so the shard picked up here is stored as a full replica.
+	shardCid, err := cid.Decode(shard.Cid)
+	if err != nil {
+		return types.Wrapf(types.ErrInvalidCid, "%s", shard.Cid)
+	}
+
+	err = ss.reserveSpace(ctx, shard.Size_)
+	if err != nil {
+		return err
+	}
+	shardInfo := types.ShardInfo{
+		Owner:          order.Owner,
+		OrderId:        order.Id,
+		Gateway:        order.Provider,
+		Cid:            shardCid,
+		DataId:         order.DataId,
+		OrderOperation: fmt.Sprintf("%d", order.Operation),
+		ShardOperation: task.Operation,
+		Size:           shard.Size_,
+		State:          types.ShardStateValidated,
+		ExpireHeight:   order.CreatedAt + order.Timeout,
+	}
+	err = utils.SaveShard(ctx, ss.orderDs, shardInfo)
+	if err != nil {
+		log.Warnf("put shard order=%d cid=%v error: %v", shardInfo.OrderId, shardInfo.Cid, err)
+	}
+	log.Infof("order %d shard %v is not pushed by gateway %s, picked up from tx %s", order.Id, shardCid, task.Gateway, task.TxHash)
+	ss.schedQueue.Push(&queue.WorkRequest{Shard: shardInfo})
+	return nil
+}
+
+func (ss *StoreSvc) hasOrderShard(ctx context.Context, orderId uint64) (bool, error) {
+	index, err := utils.GetShardIndex(ctx, ss.orderDs)
+	if err != nil {
+		return false, err
+	}
+	for _, key := range index.All {
+		if key.OrderId == orderId {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func (ss *StoreSvc) processExpire(ctx context.Context) error {
 	t := time.NewTicker(24 * 60 * time.Minute)
 	defer t.Stop()
@@ -181,10 +332,13 @@ func (ss *StoreSvc) processExpire(ctx context.Context) error {
 						log.Error("get shard from local error", err)
 						continue
 					}
+					held := holdsSpace(sf.State)
 					sf.State = types.ShardStateExpired
 					err = utils.SaveShard(ctx, ss.orderDs, sf)
 					if err != nil {
 						log.Error("save shard error: ", err)
+					} else if held {
+						ss.releaseSpace(sf.Size)
 					}
 				}
 			}
@@ -251,13 +405,8 @@ func (ss *StoreSvc) processMigrate(ctx context.Context, req MigrateRequest) erro
 	if err != nil {
 		return err
 	}
-	reader, err := ss.storeManager.Get(ss.ctx, cid)
-	if err != nil {
-		return err
-	}
-	shardContent, err := io.ReadAll(reader)
-	if err != nil {
-		return err
+	if !ss.storeManager.IsExist(ctx, cid) {
+		return types.Wrapf(types.ErrDataMissing, "shard with cid %s not found", cid)
 	}
 
 	peer, err := ss.chainSvc.GetNodePeer(ctx, req.ToProvider)
@@ -265,14 +414,15 @@ func (ss *StoreSvc) processMigrate(ctx context.Context, req MigrateRequest) erro
 		return err
 	}
 	p := ss.storageProtocolMap["stream"]
-	resp := p.RequestShardMigrate(ctx, types.ShardMigrateReq{
+	resp := p.RequestShardMigrateStream(ctx, types.ShardMigrateReq{
 		MigrateFrom: req.FromProvider,
 		OrderId:     req.OrderId,
 		DataId:      req.DataId,
 		TxHash:      req.MigrateTxHash,
 		Cid:         req.Cid,
-		Content:     shardContent,
-	}, peer)
+	}, peer, func() (io.Reader, error) {
+		return ss.storeManager.Get(ss.ctx, cid)
+	})
 	if resp.Code != 0 {
 		return xerrors.Errorf(resp.Message)
 	}
@@ -336,6 +486,24 @@ func (ss *StoreSvc) processIncompleteShards(ctx context.Context) {
 }
 
 func (ss *StoreSvc) HandleShardMigrate(req types.ShardMigrateReq) types.ShardMigrateResp {
+	shard, err := ss.validateShardMigrate(req)
+	if err != nil {
+		log.Error(err.Error())
+		return types.ShardMigrateResp{
+			Code:    types.ErrorCodeInternalErr,
+			Message: err.Error(),
+		}
+	}
+
+	// TODO: size check
+	return ss.completeShardMigrate(req.OrderId, shard, bytes.NewReader(req.Content))
+}
+
+/**
+ * the migrated content is received into a partial file under the staging path,
+ * so that an interrupted migration can be resumed from the size of that file.
+ */
+func (ss *StoreSvc) HandleShardMigrateStream(req types.ShardMigrateReq, s io.ReadWriter) (types.ShardMigrateResp, bool) {
 	logAndRespond := func(code uint64, errMsg string) types.ShardMigrateResp {
 		log.Error(errMsg)
 		return types.ShardMigrateResp{
@@ -344,37 +512,66 @@ func (ss *StoreSvc) HandleShardMigrate(req types.ShardMigrateReq) types.ShardMig
 		}
 	}
 
+	shard, err := ss.validateShardMigrate(req)
+	if err != nil {
+		return logAndRespond(types.ErrorCodeInternalErr, err.Error()), false
+	}
+
+	filename, err := ss.shardPartPath("migrate", req.OrderId, shard.Cid)
+	if err != nil {
+		return logAndRespond(types.ErrorCodeInternalErr, err.Error()), false
+	}
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return logAndRespond(types.ErrorCodeInternalErr, fmt.Sprintf("open file %s error: %v", filename, err)), false
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return logAndRespond(types.ErrorCodeInternalErr, fmt.Sprintf("stat file %s error: %v", filename, err)), false
+	}
+
+	ack := types.ShardStreamAck{Offset: uint64(info.Size())}
+	err = ack.Marshal(s, types.FormatCbor)
+	if err != nil {
+		return logAndRespond(types.ErrorCodeInternalErr, fmt.Sprintf("send ack error: %v", err)), true
+	}
+
+	_, err = transport.ReceiveShardChunks(s, file, ack.Offset)
+	if err != nil {
+		return logAndRespond(types.ErrorCodeInternalErr, fmt.Sprintf("receive shard %s error: %v", shard.Cid, err)), true
+	}
+
+	reader, err := ss.openShardPart(filename, shard.Cid)
+	if err != nil {
+		return logAndRespond(types.ErrorCodeInternalErr, err.Error()), true
+	}
+	defer os.Remove(filename)
+	defer reader.Close()
+
+	return ss.completeShardMigrate(req.OrderId, shard, reader), true
+}
+
+func (ss *StoreSvc) validateShardMigrate(req types.ShardMigrateReq) (*ordertypes.Shard, error) {
 	resultTx, err := ss.chainSvc.GetTx(ss.ctx, req.TxHash, req.TxHeight)
 	if err != nil {
-		return logAndRespond(
-			types.ErrorCodeInternalErr,
-			fmt.Sprintf("Get tx %s error: ", req.TxHash),
-		)
+		return nil, xerrors.Errorf("Get tx %s error: ", req.TxHash)
 	}
 
 	if resultTx.TxResult.Code != 0 {
-		return logAndRespond(
-			types.ErrorCodeInternalErr,
-			fmt.Sprintf("Tx %s failed with code: %d", req.TxHash, resultTx.TxResult.Code),
-		)
+		return nil, xerrors.Errorf("Tx %s failed with code: %d", req.TxHash, resultTx.TxResult.Code)
 	}
 
 	var txMsgData sdktypes.TxMsgData
 	err = txMsgData.Unmarshal(resultTx.TxResult.Data)
 	if err != nil {
-		return logAndRespond(
-			types.ErrorCodeInternalErr,
-			fmt.Sprintf("unmarshal tx error: %v", err),
-		)
+		return nil, xerrors.Errorf("unmarshal tx error: %v", err)
 	}
 
 	mr := saotypes.MsgMigrateResponse{}
 	err = mr.Unmarshal(txMsgData.MsgResponses[0].Value)
 	if err != nil {
-		return logAndRespond(
-			types.ErrorCodeInternalErr,
-			fmt.Sprintf("unmarshal tx error: %v", err),
-		)
+		return nil, xerrors.Errorf("unmarshal tx error: %v", err)
 	}
 	var m string
 	for _, r := range mr.Result {
@@ -384,49 +581,39 @@ func (ss *StoreSvc) HandleShardMigrate(req types.ShardMigrateReq) types.ShardMig
 		}
 	}
 	if m == "" {
-		return logAndRespond(
-			types.ErrorCodeInternalErr,
-			fmt.Sprintf("invalid data id: given dataId %s not in tx %s", req.DataId, req.TxHash),
-		)
+		return nil, xerrors.Errorf("invalid data id: given dataId %s not in tx %s", req.DataId, req.TxHash)
 	}
 	if !strings.HasPrefix(m, "SUCCESS") {
-		return logAndRespond(
-			types.ErrorCodeInternalErr,
-			fmt.Sprintf("dataId migrate fails: %s", m),
-		)
+		return nil, xerrors.Errorf("dataId migrate fails: %s", m)
 	}
 	order, err := ss.chainSvc.GetOrder(ss.ctx, req.OrderId)
 	if err != nil {
-		return logAndRespond(
-			types.ErrorCodeInternalErr,
-			fmt.Sprintf("get order %d error: %d", order.Id, err),
-		)
+		return nil, xerrors.Errorf("get order %d error: %v", req.OrderId, err)
 	}
 	shard, exists := order.Shards[ss.nodeAddress]
 	if !exists {
-		return logAndRespond(
-			types.ErrorCodeInternalErr,
-			fmt.Sprintf("no shard to current provider %s", ss.nodeAddress),
-		)
+		return nil, xerrors.Errorf("no shard to current provider %s", ss.nodeAddress)
 	}
 	if shard.From != req.MigrateFrom {
-		return logAndRespond(
-			types.ErrorCodeInternalErr,
-			fmt.Sprintf("unmatched migrate from: expected %s, actual %s", req.MigrateFrom, shard.From),
-		)
+		return nil, xerrors.Errorf("unmatched migrate from: expected %s, actual %s", req.MigrateFrom, shard.From)
 	}
 	if shard.Cid != req.Cid {
-		return logAndRespond(
-			types.ErrorCodeInternalErr,
-			fmt.Sprintf("unmatched cid: expected %s, actual %s", req.Cid, shard.Cid),
-		)
+		return nil, xerrors.Errorf("unmatched cid: expected %s, actual %s", req.Cid, shard.Cid)
 	}
 
 	if shard.Status != ordertypes.ShardMigrating {
-		return logAndRespond(
-			types.ErrorCodeInternalErr,
-			fmt.Sprintf("shard status is not invalid, expected ShardWaiting, actual %d", shard.Status),
-		)
+		return nil, xerrors.Errorf("shard status is not invalid, expected ShardWaiting, actual %d", shard.Status)
+	}
+	return shard, nil
+}
+
+func (ss *StoreSvc) completeShardMigrate(orderId uint64, shard *ordertypes.Shard, reader io.Reader) types.ShardMigrateResp {
+	logAndRespond := func(code uint64, errMsg string) types.ShardMigrateResp {
+		log.Error(errMsg)
+		return types.ShardMigrateResp{
+			Code:    code,
+			Message: errMsg,
+		}
 	}
 
 	cid, err := cid.Decode(shard.Cid)
@@ -436,13 +623,12 @@ func (ss *StoreSvc) HandleShardMigrate(req types.ShardMigrateReq) types.ShardMig
 			fmt.Sprintf("invalid cid %s error: %v", shard.Cid, err),
 		)
 	}
-	// TODO: size check
-	_, err = ss.storeManager.Store(ss.ctx, cid, bytes.NewReader(req.Content))
+	_, err = ss.storeManager.Store(ss.ctx, cid, reader)
 	if err != nil {
 		return logAndRespond(types.ErrorCodeInternalErr, fmt.Sprintf("store cid %s error: %v", cid, err))
 	}
 	// send tx
-	txHash, height, err := ss.chainSvc.CompleteOrder(ss.ctx, ss.nodeAddress, order.Id, cid, uint64(len(req.Content)))
+	txHash, height, err := ss.chainSvc.CompleteOrder(ss.ctx, ss.nodeAddress, orderId, cid, shard.Size_)
 	if err != nil {
 		return logAndRespond(
 			types.ErrorCodeInvalidTx,
@@ -457,8 +643,73 @@ func (ss *StoreSvc) HandleShardMigrate(req types.ShardMigrateReq) types.ShardMig
 	}
 }
 
+/**
+ * path of the partially received shard content, kept across retries until the shard is stored.
+ */
+func (ss *StoreSvc) shardPartPath(dir string, orderId uint64, shardCid string) (string, error) {
+	path, err := homedir.Expand(ss.stagingPath)
+	if err != nil {
+		return "", types.Wrapf(types.ErrInvalidPath, "%s", ss.stagingPath)
+	}
+
+	err = os.MkdirAll(filepath.Join(path, dir), 0755)
+	if err != nil && !os.IsExist(err) {
+		return "", types.Wrap(types.ErrCreateDirFailed, err)
+	}
+	return filepath.Join(path, dir, fmt.Sprintf("%d-%s", orderId, shardCid)), nil
+}
+
+/**
+ * open the received shard content after verifying its cid, the part file is removed if the cid doesn't match.
+ */
+func (ss *StoreSvc) openShardPart(filename string, shardCid string) (*os.File, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, types.Wrap(types.ErrReadFileFailed, err)
+	}
+
+	contentCid, _, err := utils.CalculateCidFromReader(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if contentCid.String() != shardCid {
+		file.Close()
+		os.Remove(filename)
+		return nil, types.Wrapf(types.ErrInvalidCid, "content cid %v != shard cid %s", contentCid, shardCid)
+	}
+
+	_, err = file.Seek(0, io.SeekStart)
+	if err != nil {
+		file.Close()
+		return nil, types.Wrap(types.ErrReadFileFailed, err)
+	}
+	return file, nil
+}
+
 func (ss *StoreSvc) HandleShardLoad(req types.ShardLoadReq, remotePeerId string) types.ShardLoadResp {
-	logAndRespond := func(code uint64, errMsg string) types.ShardLoadResp {
+	resp, reader := ss.HandleShardLoadStream(req, remotePeerId)
+	if reader == nil {
+		return resp
+	}
+	if c, ok := reader.(io.Closer); ok {
+		defer c.Close()
+	}
+
+	shardContent, err := io.ReadAll(reader)
+	if err != nil {
+		errMsg := fmt.Sprintf("get %v from store error: %v", req.Cid, err)
+		log.Error(errMsg)
+		resp.Code = types.ErrorCodeInternalErr
+		resp.Message = errMsg
+		return resp
+	}
+	resp.Content = shardContent
+	return resp
+}
+
+func (ss *StoreSvc) HandleShardLoadStream(req types.ShardLoadReq, remotePeerId string) (types.ShardLoadResp, io.Reader) {
+	logAndRespond := func(code uint64, errMsg string) (types.ShardLoadResp, io.Reader) {
 		log.Error(errMsg)
 		return types.ShardLoadResp{
 			Code:       code,
@@ -467,7 +718,7 @@ func (ss *StoreSvc) HandleShardLoad(req types.ShardLoadReq, remotePeerId string)
 			Cid:        req.Cid,
 			RequestId:  req.RequestId,
 			ResponseId: time.Now().UnixMilli(),
-		}
+		}, nil
 	}
 
 	if req.Owner == req.Proposal.Proposal.Gateway && req.Proposal.Proposal.Owner == req.Owner {
@@ -592,18 +843,69 @@ func (ss *StoreSvc) HandleShardLoad(req types.ShardLoadReq, remotePeerId string)
 			fmt.Sprintf("get %v from store error: %v", req.Cid, err),
 		)
 	}
-	shardContent, err := io.ReadAll(reader)
-	if err != nil {
-		return logAndRespond(
-			types.ErrorCodeInternalErr,
-			fmt.Sprintf("get %v from store error: %v", req.Cid, err),
-		)
-	}
 
 	return types.ShardLoadResp{
 		OrderId:    req.OrderId,
 		Cid:        req.Cid,
-		Content:    shardContent,
+		RequestId:  req.RequestId,
+		ResponseId: time.Now().UnixMilli(),
+	}, reader
+}
+
+/**
+ * answer a proof-of-storage challenge of a fisherman with the merkle proofs of the requested leaves.
+ */
+func (ss *StoreSvc) HandleShardChallenge(req types.ShardChallengeReq, remotePeerId string) types.ShardChallengeResp {
+	logAndRespond := func(code uint64, errMsg string) types.ShardChallengeResp {
+		log.Error(errMsg)
+		return types.ShardChallengeResp{
+			Code:       code,
+			Message:    errMsg,
+			Cid:        req.Cid,
+			RequestId:  req.RequestId,
+			ResponseId: time.Now().UnixMilli(),
+		}
+	}
+
+	fishmen, err := ss.chainSvc.GetFishmen(ss.ctx)
+	if err != nil {
+		return logAndRespond(types.ErrorCodeInternalErr, fmt.Sprintf("failed to get fishmen info: %v", err))
+	}
+	if req.Fisherman == "" || !strings.Contains(fishmen, req.Fisherman) {
+		return logAndRespond(types.ErrorCodeInvalidRequest, fmt.Sprintf("invalid challenge, %s is not a fishman", req.Fisherman))
+	}
+	peerInfo, err := ss.chainSvc.GetNodePeer(ss.ctx, req.Fisherman)
+	if err != nil {
+		return logAndRespond(types.ErrorCodeInternalErr, fmt.Sprintf("failed to get fishman peer info: %v", err))
+	}
+	if !strings.Contains(peerInfo, remotePeerId) {
+		return logAndRespond(types.ErrorCodeInvalidRequest, fmt.Sprintf("invalid challenge, unexpect peer %s of fishman %s", remotePeerId, req.Fisherman))
+	}
+
+	if req.LeafSize < types.ShardChallengeMinLeafSize || req.LeafSize > types.ShardChallengeMaxLeafSize {
+		return logAndRespond(types.ErrorCodeInvalidRequest, fmt.Sprintf("invalid leaf size %d", req.LeafSize))
+	}
+	if len(req.Leaves) == 0 || len(req.Leaves) > types.ShardChallengeMaxLeaves {
+		return logAndRespond(types.ErrorCodeInvalidRequest, fmt.Sprintf("invalid leaves count %d", len(req.Leaves)))
+	}
+
+	reader, err := ss.storeManager.Get(ss.ctx, req.Cid)
+	if err != nil {
+		return logAndRespond(types.ErrorCodeInternalErr, fmt.Sprintf("get %v from store error: %v", req.Cid, err))
+	}
+	if c, ok := reader.(io.Closer); ok {
+		defer c.Close()
+	}
+
+	leafCount, proofs, err := utils.BuildShardProofs(reader, req.LeafSize, req.Leaves)
+	if err != nil {
+		return logAndRespond(types.ErrorCodeInternalErr, fmt.Sprintf("build proofs of %v error: %v", req.Cid, err))
+	}
+
+	return types.ShardChallengeResp{
+		Cid:        req.Cid,
+		LeafCount:  leafCount,
+		Proofs:     proofs,
 		RequestId:  req.RequestId,
 		ResponseId: time.Now().UnixMilli(),
 	}
@@ -679,7 +981,24 @@ func (ss *StoreSvc) HandleShardAssign(req types.ShardAssignReq) types.ShardAssig
 				fmt.Sprintf("order %d doesn't have shard provider %s", req.OrderId, ss.nodeAddress),
 			)
 		}
+
+		ss.taskLk.Lock()
+		defer ss.taskLk.Unlock()
 		for _, shardCid := range shardCids {
+			shardSize := order.Size_
+			if req.ShardCid != "" {
+				// the erasure coded shard is only known by the gateway until it's completed on chain,
+				// a shard is never larger than the content plus its header, the content is verified by the cid once fetched.
+				if (shardCid != order.Cid && shardCid != req.ShardCid) || req.ShardCid == order.Cid ||
+					req.ShardSize <= utils.ErasureShardHeaderSize || req.ShardSize > order.Size_+utils.ErasureShardHeaderSize {
+					return logAndRespond(
+						types.ErrorCodeInvalidShardCid,
+						fmt.Sprintf("invalid erasure shard %s of size %d for order %d", req.ShardCid, req.ShardSize, req.OrderId),
+					)
+				}
+				shardCid = req.ShardCid
+				shardSize = req.ShardSize
+			}
 			cid, err := cid.Decode(shardCid)
 			if err != nil {
 				return logAndRespond(
@@ -690,6 +1009,19 @@ func (ss *StoreSvc) HandleShardAssign(req types.ShardAssignReq) types.ShardAssig
 
 			shardInfo, _ := utils.GetShard(ss.ctx, ss.orderDs, req.OrderId, cid)
 			if (types.ShardInfo{} == shardInfo) {
+				err = ss.reserveSpace(ss.ctx, shardSize)
+				if err != nil {
+					if types.ErrInsufficientSpace.Is(err) {
+						return logAndRespond(
+							types.ErrorCodeInsufficientSpace,
+							fmt.Sprintf("refuse shard of order %d: %v", req.OrderId, err),
+						)
+					}
+					return logAndRespond(
+						types.ErrorCodeInternalErr,
+						fmt.Sprintf("internal error: %v", err),
+					)
+				}
 				shardInfo = types.ShardInfo{
 					Owner:          order.Owner,
 					OrderId:        req.OrderId,
@@ -698,8 +1030,10 @@ func (ss *StoreSvc) HandleShardAssign(req types.ShardAssignReq) types.ShardAssig
 					DataId:         req.DataId,
 					OrderOperation: fmt.Sprintf("%d", order.Operation),
 					ShardOperation: fmt.Sprintf("%d", order.Operation),
+					Size:           shardSize,
 					State:          types.ShardStateValidated,
 					ExpireHeight:   req.TimeoutHeight,
+					TraceContext:   req.TraceContext,
 				}
 				err = utils.SaveShard(ss.ctx, ss.orderDs, shardInfo)
 				if err != nil {
@@ -726,12 +1060,13 @@ func (ss *StoreSvc) HandleShardAssign(req types.ShardAssignReq) types.ShardAssig
 func (ss *StoreSvc) Start(ctx context.Context) error {
 	throttle := make(chan struct{}, WINDOW_SIZE)
 	for {
-		if ss.schedQueue.Len() == 0 {
+		// failed shards wait in the queue until their next retry
+		if ss.schedQueue.Runnable() == 0 {
 			time.Sleep(time.Second * SCHEDULE_INTERVAL)
 			continue
 		}
 
-		len := ss.schedQueue.Len()
+		len := ss.schedQueue.Runnable()
 		for i := 0; i < len; i++ {
 			throttle <- struct{}{}
 
@@ -744,36 +1079,79 @@ func (ss *StoreSvc) Start(ctx context.Context) error {
 				if task == nil {
 					return
 				}
-				if task.Shard.RetryAt > time.Now().Unix() {
-					ss.schedQueue.Push(task)
+				defer ss.schedQueue.Done(task)
+				if !ss.startProcessing(task.Shard) {
+					log.Infof("shard orderid=%d cid=%v is being processed, skip it", task.Shard.OrderId, task.Shard.Cid)
 					return
 				}
+				defer ss.doneProcessing(task.Shard)
 				err := ss.process(ctx, &task.Shard)
 				if err != nil {
 					log.Errorf("process shard %s error: %v", task.Shard.Cid, err)
-					ss.schedQueue.Push(task)
+					if ss.retryLater(&task.Shard, err) {
+						ss.schedQueue.Push(task)
+					}
 				}
 			}()
 		}
 	}
 }
 
-func (ss *StoreSvc) process(ctx context.Context, task *types.ShardInfo) error {
+func processingKey(shard types.ShardInfo) string {
+	return fmt.Sprintf("%d-%v", shard.OrderId, shard.Cid)
+}
+
+/**
+ * mark the shard as being processed, false if another worker is processing it already.
+ */
+func (ss *StoreSvc) startProcessing(shard types.ShardInfo) bool {
+	ss.processingLk.Lock()
+	defer ss.processingLk.Unlock()
+
+	key := processingKey(shard)
+	if _, exists := ss.processing[key]; exists {
+		return false
+	}
+	ss.processing[key] = struct{}{}
+	return true
+}
+
+func (ss *StoreSvc) doneProcessing(shard types.ShardInfo) {
+	ss.processingLk.Lock()
+	defer ss.processingLk.Unlock()
+
+	delete(ss.processing, processingKey(shard))
+}
+
+func (ss *StoreSvc) isProcessing(shard types.ShardInfo) bool {
+	ss.processingLk.Lock()
+	defer ss.processingLk.Unlock()
+
+	_, exists := ss.processing[processingKey(shard)]
+	return exists
+}
+
+func (ss *StoreSvc) process(ctx context.Context, task *types.ShardInfo) (err error) {
 	log.Infof("start processing: order id=%d gateway=%s shard_cid=%v", task.OrderId, task.Gateway, task.Cid)
 
+	ctx, span := tracing.StartSpan(tracing.Extract(ctx, task.TraceContext), "StoreSvc.process", trace.WithAttributes(
+		tracing.OrderIdKey.Int64(int64(task.OrderId)),
+		tracing.CidKey.String(task.Cid.String()),
+	))
+	defer func() {
+		tracing.RecordError(span, err)
+		span.End()
+	}()
+
 	if task.State == types.ShardStateTerminate {
 		return nil
 	}
 
 	task.Tries++
-	task.RetryAt = utils.GetRetryAt(task.Tries)
-	log.Infof("shard orderid=%d cid=%v: %d", task.OrderId, task.Cid, task.Tries)
-	if task.Tries >= MAX_RETRIES {
-		task.State = types.ShardStateTerminate
-		errMsg := fmt.Sprintf("order %d shard %v too many retries %d", task.OrderId, task.DataId, task.Tries)
-		ss.updateShardError(task, xerrors.Errorf(errMsg))
-		return nil
+	if task.Tries > 1 {
+		metrics.ShardRetries.Inc()
 	}
+	log.Infof("shard orderid=%d cid=%v: %d", task.OrderId, task.Cid, task.Tries)
 
 	if task.ExpireHeight > 0 {
 		latestHeight, err := ss.chainSvc.GetLastHeight(ctx)
@@ -785,6 +1163,7 @@ func (ss *StoreSvc) process(ctx context.Context, task *types.ShardInfo) error {
 			task.State = types.ShardStateTerminate
 			errStr := fmt.Sprintf("order expired: latest=%d expireAt=%d", latestHeight, task.ExpireHeight)
 			ss.updateShardError(task, xerrors.Errorf(errStr))
+			ss.releaseSpace(task.Size)
 			return nil
 		}
 	}
@@ -798,31 +1177,11 @@ func (ss *StoreSvc) process(ctx context.Context, task *types.ShardInfo) error {
 	if task.State < types.ShardStateStored {
 		// check if it's a renew order(Operation is 3)
 		if task.OrderOperation != "3" || task.ShardOperation != "3" {
-			resp := sp.RequestShardStore(ctx, types.ShardLoadReq{
-				Owner:   task.Owner,
-				DataId:  task.DataId,
-				OrderId: task.OrderId,
-				Cid:     task.Cid,
-			}, peerInfo)
-			if resp.Code != 0 {
-				ss.updateShardError(task, types.Wrapf(types.ErrFailuresResponsed, resp.Message))
-				return types.Wrapf(types.ErrFailuresResponsed, resp.Message)
-			} else {
-				cid, _ := utils.CalculateCid(resp.Content)
-				log.Debugf("ipfs cid %v, task cid %v, order id %v", cid, task.Cid, task.OrderId)
-				if cid.String() != task.Cid.String() {
-					ss.updateShardError(task, err)
-					return types.Wrapf(types.ErrInvalidCid, "ipfs cid %v != task cid %v", cid, task.Cid)
-				}
-			}
-
-			// store to backends
-			_, err = ss.storeManager.Store(ctx, task.Cid, bytes.NewReader(resp.Content))
+			_, err := ss.fetchShard(ctx, sp, peerInfo, task)
 			if err != nil {
 				ss.updateShardError(task, err)
-				return types.Wrap(types.ErrStoreFailed, err)
+				return err
 			}
-			task.Size = uint64(len(resp.Content))
 		} else {
 			// make sure the data is still there
 			isExist := ss.storeManager.IsExist(ctx, task.Cid)
@@ -839,7 +1198,20 @@ func (ss *StoreSvc) process(ctx context.Context, task *types.ShardInfo) error {
 	}
 
 	if task.State < types.ShardStateTxSent {
-		txHash, height, err := ss.chainSvc.CompleteOrder(ctx, ss.nodeAddress, task.OrderId, task.Cid, task.Size)
+		order, err := ss.chainSvc.GetOrder(ctx, task.OrderId)
+		if err != nil {
+			ss.updateShardError(task, err)
+			return err
+		}
+		shard, exists := order.Shards[ss.nodeAddress]
+		if !exists {
+			err = types.Wrapf(types.ErrInvalidShardState, "order %d doesn't have shard provider %s", task.OrderId, ss.nodeAddress)
+			ss.updateShardError(task, err)
+			return err
+		}
+
+		// the chain takes the shard size of the order, the size stored locally differs for an erasure coded shard
+		txHash, height, err := ss.chainSvc.CompleteOrder(ctx, ss.nodeAddress, task.OrderId, task.Cid, shard.Size_)
 		if err != nil {
 			ss.updateShardError(task, err)
 			return err
@@ -853,28 +1225,24 @@ func (ss *StoreSvc) process(ctx context.Context, task *types.ShardInfo) error {
 		if err != nil {
 			log.Warnf("put shard order=%d cid=%v error: %v", task.OrderId, task.Cid, err)
 		}
-		order, err := ss.chainSvc.GetOrder(ctx, task.OrderId)
+		err = utils.SaveShardExpire(ctx, ss.orderDs, shard.Id, task.Cid.String(), shard.OrderId)
 		if err != nil {
-			log.Warn("failed to get order: ", err)
-		} else {
-			shard := order.Shards[ss.nodeAddress]
-			err = utils.SaveShardExpire(ctx, ss.orderDs, shard.Id, shard.Cid, shard.OrderId)
-			if err != nil {
-				log.Warn("save shard expire error: ", err)
-			}
+			log.Warn("save shard expire error: ", err)
 		}
-
 	}
 
-	resp := sp.RequestShardComplete(ctx, types.ShardCompleteReq{
+	completeReq := types.ShardCompleteReq{
 		OrderId: task.OrderId,
 		DataId:  task.DataId,
 		Cids:    []cid.Cid{task.Cid},
 		Height:  task.CompleteHeight,
 		TxHash:  task.CompleteHash,
-	}, peerInfo)
+	}
+	completeCtx, completeSpan := tracing.StartRequestSpan(ctx, "RequestShardComplete", &completeReq.TraceContext, tracing.ProviderKey.String(task.Gateway))
+	resp := sp.RequestShardComplete(completeCtx, completeReq, peerInfo)
+	tracing.EndResponse(completeSpan, resp.Code, resp.Message)
 	if resp.Code != 0 {
-		ss.updateShardError(task, types.Wrapf(types.ErrFailuresResponsed, resp.Message))
+		ss.updateShardError(task, types.ResponseError(resp.Code, resp.Message))
 		// return types.Wrapf(types.ErrFailuresResponsed, resp.Message)
 	}
 	if task.State < types.ShardStateComplete {
@@ -887,13 +1255,79 @@ func (ss *StoreSvc) process(ctx context.Context, task *types.ShardInfo) error {
 	return nil
 }
 
+/**
+ * stream the shard content from the gateway into a part file, and store it to backends once the cid is verified.
+ * returns the size of the shard content.
+ */
+func (ss *StoreSvc) fetchShard(ctx context.Context, sp StorageProtocol, peerInfo string, task *types.ShardInfo) (uint64, error) {
+	filename, err := ss.shardPartPath("shard-parts", task.OrderId, task.Cid.String())
+	if err != nil {
+		return 0, err
+	}
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return 0, types.Wrap(types.ErrOpenFileFailed, err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return 0, types.Wrap(types.ErrOpenFileFailed, err)
+	}
+
+	req := types.ShardLoadReq{
+		Owner:   task.Owner,
+		DataId:  task.DataId,
+		OrderId: task.OrderId,
+		Cid:     task.Cid,
+		Offset:  uint64(info.Size()),
+	}
+	reqCtx, span := tracing.StartRequestSpan(ctx, "RequestShardStore", &req.TraceContext, tracing.ProviderKey.String(task.Gateway))
+	resp := sp.RequestShardStoreStream(reqCtx, req, peerInfo, file)
+	tracing.EndResponse(span, resp.Code, resp.Message)
+	file.Close()
+	if resp.Code != 0 {
+		return 0, types.ResponseError(resp.Code, resp.Message)
+	}
+
+	reader, err := ss.openShardPart(filename, task.Cid.String())
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(filename)
+	defer reader.Close()
+
+	info, err = reader.Stat()
+	if err != nil {
+		return 0, types.Wrap(types.ErrReadFileFailed, err)
+	}
+	size := uint64(info.Size())
+
+	// the space is reserved by the assigned size, it's corrected by the verified content
+	if task.Size != 0 && size > task.Size {
+		err = ss.reserveSpace(ctx, size-task.Size)
+		if err != nil {
+			return 0, err
+		}
+	} else if size < task.Size {
+		ss.releaseSpace(task.Size - size)
+	}
+	task.Size = size
+
+	// store to backends
+	_, err = ss.storeManager.Store(ctx, task.Cid, reader)
+	if err != nil {
+		return 0, types.Wrap(types.ErrStoreFailed, err)
+	}
+	return size, nil
+}
+
 func (ss *StoreSvc) Stop(ctx context.Context) error {
-	// TODO: wsevent
-	//if err := ss.chainSvc.UnsubscribeShardTask(ctx, ss.nodeAddress); err != nil {
-	//	return err
-	//}
 	log.Info("stopping storage service...")
 
+	if err := ss.chainSvc.UnsubscribeShardTask(ctx, ss.nodeAddress); err != nil {
+		log.Errorf("unsubscribe shard task failed: %v", err)
+	}
+
 	var err error
 	for k, p := range ss.storageProtocolMap {
 		err = p.Stop(ctx)
@@ -929,6 +1363,23 @@ func (ss *StoreSvc) getStorageProtocolAndPeer(
 	return sp, peer, err
 }
 
+/**
+ * schedule the next attempt of a failed shard by the retry policy of the error, the shard terminates if the policy gives up.
+ */
+func (ss *StoreSvc) retryLater(task *types.ShardInfo, err error) bool {
+	policy, retryAt, ok := ss.retry.NextRetry(err, task.Tries)
+	task.RetryPolicy = policy
+	task.RetryAt = retryAt
+	if !ok {
+		task.State = types.ShardStateTerminate
+		err = xerrors.Errorf("order %d shard %v gives up after %d tries by %s retry policy: %w", task.OrderId, task.Cid, task.Tries, policy, err)
+		log.Warn(err)
+		ss.releaseSpace(task.Size)
+	}
+	ss.updateShardError(task, err)
+	return ok
+}
+
 func (ss *StoreSvc) updateShardError(shard *types.ShardInfo, err error) {
 	shard.LastErr = err.Error()
 	err = utils.SaveShard(ss.ctx, ss.orderDs, *shard)
@@ -986,12 +1437,74 @@ func (ss *StoreSvc) ShardList(ctx context.Context) ([]types.ShardInfo, error) {
 	return shardInfos, nil
 }
 
+/**
+ * cids of the shards stored in this node, they're kept consistent across store backends.
+ */
+func (ss *StoreSvc) StoredShardCids(ctx context.Context) ([]cid.Cid, error) {
+	shards, err := ss.ShardList(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var cids []cid.Cid
+	for _, shard := range shards {
+		if shard.State >= types.ShardStateStored && shard.State < types.ShardStateTerminate {
+			cids = append(cids, shard.Cid)
+		}
+	}
+	return cids, nil
+}
+
+/**
+ * reset the retries of a shard and schedule it again, a terminated shard is resumed if it's still waiting on chain.
+ */
 func (ss *StoreSvc) ShardFix(ctx context.Context, orderId uint64, cid cid.Cid) error {
+	ss.taskLk.Lock()
+	defer ss.taskLk.Unlock()
+
 	shardInfo, err := utils.GetShard(ctx, ss.orderDs, orderId, cid)
 	if err != nil {
-		return nil
+		return err
+	}
+	if shardInfo.OrderId == 0 {
+		return types.Wrapf(types.ErrNotFound, "shard of order %d cid %v", orderId, cid)
+	}
+	if shardInfo.State == types.ShardStateComplete {
+		return types.Wrapf(types.ErrInvalidShardState, "shard of order %d cid %v has completed", orderId, cid)
+	}
+	if ss.isProcessing(shardInfo) {
+		return types.Wrapf(types.ErrInvalidShardState, "shard of order %d cid %v is being processed", orderId, cid)
+	}
+
+	order, err := ss.chainSvc.GetOrder(ctx, orderId)
+	if err != nil {
+		return err
+	}
+	shard, exists := order.Shards[ss.nodeAddress]
+	if !exists {
+		return types.Wrapf(types.ErrInvalidProvider, "order %d doesn't have shard provider %s", orderId, ss.nodeAddress)
+	}
+	if shard.Status != ordertypes.ShardWaiting {
+		return types.Wrapf(types.ErrInvalidShardState, "shard of order %d is not waiting on chain, status %d", orderId, shard.Status)
+	}
+
+	if shardInfo.State == types.ShardStateTerminate {
+		// the space is released once the shard terminates
+		err = ss.reserveSpace(ctx, shardInfo.Size)
+		if err != nil {
+			return err
+		}
+		shardInfo.State = types.ShardStateValidated
+	}
+	shardInfo.Tries = 0
+	shardInfo.RetryAt = 0
+	shardInfo.LastErr = ""
+	err = utils.SaveShard(ctx, ss.orderDs, shardInfo)
+	if err != nil {
+		return err
 	}
 
+	log.Infof("shard of order %d cid %v is fixed, re-sched it", orderId, cid)
 	ss.schedQueue.Push(&queue.WorkRequest{Shard: shardInfo})
 	return nil
 }
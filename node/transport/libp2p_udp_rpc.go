@@ -116,6 +116,9 @@ func (rs *Libp2pRpcServer) HandleStream(s network.Stream) {
 		case "Sao.Upload":
 			req.Params = append(req.Params, filepath.Join(rs.RH.StagingPath, s.Conn().RemotePeer().String()))
 			result, err = rs.RH.Upload(req.Params)
+		case "Sao.UploadStatus":
+			req.Params = append(req.Params, filepath.Join(rs.RH.StagingPath, s.Conn().RemotePeer().String()))
+			result, err = rs.RH.UploadStatus(req.Params)
 		case "Sao.ModelCreate":
 			result, err = rs.RH.Create(req.Params)
 		case "Sao.ModelLoad":
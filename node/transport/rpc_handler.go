@@ -10,9 +10,11 @@ import (
 	"sync"
 
 	"github.com/SaoNetwork/sao-node/api"
+	"github.com/SaoNetwork/sao-node/metrics"
 	"github.com/SaoNetwork/sao-node/node/config"
 	"github.com/SaoNetwork/sao-node/types"
 	"github.com/SaoNetwork/sao-node/utils"
+	"github.com/ipfs/go-cid"
 	"github.com/ipfs/go-datastore"
 	"github.com/mitchellh/go-homedir"
 )
@@ -38,54 +40,63 @@ func NewHandler(ctx context.Context, ga api.SaoApi, db datastore.Batching, cfg *
 	return &handler
 }
 
-func (rs *RpcHandler) handleChunkInfo(req *types.FileChunkReq, path string) {
-	rs.DbLk.Lock()
-	defer rs.DbLk.Unlock()
+func (rs *RpcHandler) getFileInfo(peer string, cidStr string) (*types.ReceivedFileInfo, error) {
+	info, err := rs.Db.Get(rs.Ctx, datastore.NewKey(types.FileInfoKey(peer, cidStr)))
+	if err != nil {
+		if err == datastore.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
 
 	var fileInfo *types.ReceivedFileInfo
-	key := datastore.NewKey(types.FILE_INFO_PREFIX + req.Cid)
+	err = json.Unmarshal(info, &fileInfo)
+	if err != nil {
+		return nil, types.Wrap(types.ErrUnMarshalFailed, err)
+	}
+	return fileInfo, nil
+}
 
-	if req.ChunkId == 0 {
-		fileInfo = &types.ReceivedFileInfo{
-			Cid:            req.Cid,
-			TotalLength:    req.TotalLength,
-			TotalChunks:    req.TotalChunks,
-			ReceivedLength: len(req.Content),
-			Path:           path,
-			ChunkCids:      make([]string, req.TotalChunks),
-		}
-		fileInfo.ChunkCids[0] = req.ChunkCid
-	} else if info, err := rs.Db.Get(rs.Ctx, key); err == nil {
-		err := json.Unmarshal(info, &fileInfo)
-		if err != nil {
-			log.Error(err.Error())
-			return
-		}
+/**
+ * record the chunk received from the peer, a chunk received already is accepted again so that the client can resend it safely.
+ */
+func (rs *RpcHandler) handleChunkInfo(req *types.FileChunkReq, peer string, path string) error {
+	rs.DbLk.Lock()
+	defer rs.DbLk.Unlock()
 
-		if fileInfo.ChunkCids[req.ChunkId] == "" {
-			fileInfo.ChunkCids[req.ChunkId] = req.ChunkCid
-			fileInfo.ReceivedLength += len(req.Content)
-		} else {
-			log.Error("invalid chunk ", req.Cid, ", received already")
-			return
+	fileInfo, err := rs.getFileInfo(peer, req.Cid)
+	if err != nil {
+		return err
+	}
+	if fileInfo == nil || fileInfo.Path != path || fileInfo.TotalLength != req.TotalLength || fileInfo.TotalChunks != req.TotalChunks {
+		// a new upload, or the same content uploaded again with another chunking
+		fileInfo = &types.ReceivedFileInfo{
+			Cid:         req.Cid,
+			TotalLength: req.TotalLength,
+			TotalChunks: req.TotalChunks,
+			Path:        path,
+			ChunkCids:   make([]string, req.TotalChunks),
 		}
-	} else {
-		// should not happen
-		log.Error("invalid req, ", err.Error())
-		return
 	}
 
-	info, err := json.Marshal(fileInfo)
-	if err != nil {
-		log.Error(err.Error())
-		return
+	if req.ChunkId < 0 || req.ChunkId >= len(fileInfo.ChunkCids) {
+		return types.Wrapf(types.ErrInvalidParameters, "invalid chunk id %d, total chunks %d", req.ChunkId, fileInfo.TotalChunks)
+	}
+	if fileInfo.ChunkCids[req.ChunkId] == req.ChunkCid {
+		log.Debugf("chunk[%d] of %s is received already", req.ChunkId, req.Cid)
+		return nil
 	}
+	if fileInfo.ChunkCids[req.ChunkId] != "" {
+		return types.Wrapf(types.ErrInvalidParameters, "chunk[%d] of %s is received with another cid %s", req.ChunkId, req.Cid, fileInfo.ChunkCids[req.ChunkId])
+	}
+	fileInfo.ChunkCids[req.ChunkId] = req.ChunkCid
+	fileInfo.ReceivedLength += len(req.Content)
 
-	err = rs.Db.Put(rs.Ctx, key, info)
+	info, err := json.Marshal(fileInfo)
 	if err != nil {
-		log.Error(err.Error())
-		return
+		return types.Wrap(types.ErrMarshalFailed, err)
 	}
+	return rs.Db.Put(rs.Ctx, datastore.NewKey(types.FileInfoKey(peer, req.Cid)), info)
 }
 
 func (rs *RpcHandler) Upload(params []string) (string, error) {
@@ -99,6 +110,8 @@ func (rs *RpcHandler) Upload(params []string) (string, error) {
 		log.Error(err.Error())
 		return "", err
 	}
+	// params[1] is the staging directory of the remote peer
+	peer := filepath.Base(params[1])
 
 	localCid, err := utils.CalculateCid(req.Content)
 	if err != nil {
@@ -106,6 +119,10 @@ func (rs *RpcHandler) Upload(params []string) (string, error) {
 	}
 
 	if len(req.Content) > 0 {
+		if localCid.String() != req.ChunkCid {
+			return "", types.Wrapf(types.ErrInvalidCid, "chunk[%d] cid mismatch, expected %s, but got %s", req.ChunkId, req.ChunkCid, localCid)
+		}
+
 		stagingPath, err := homedir.Expand(rs.StagingPath)
 		if err != nil {
 			return "", err
@@ -129,97 +146,155 @@ func (rs *RpcHandler) Upload(params []string) (string, error) {
 			}
 		}
 
-		path := filepath.Join(params[1], req.Cid)
-		rs.handleChunkInfo(&req, path)
-
-		path, err = homedir.Expand(path)
+		chunkPath := filepath.Join(params[1], req.Cid)
+		path, err := homedir.Expand(chunkPath)
 		if err != nil {
 			return "", err
 		}
 		log.Info("path: ", path)
-		_, err = os.Stat(path)
-		if err != nil {
-			if !os.IsExist(err) {
-				err = os.MkdirAll(path, 0755)
-				if err != nil && !os.IsExist(err) {
-					return "", err
-				}
-			} else {
-				return "", err
-			}
+		err = os.MkdirAll(path, 0755)
+		if err != nil && !os.IsExist(err) {
+			return "", err
 		}
 
-		file, err := os.Create(filepath.Join(path, req.ChunkCid))
+		// the chunk is recorded only after it's written, so that a crash never leaves a recorded chunk missing
+		err = os.WriteFile(filepath.Join(path, req.ChunkCid), req.Content, 0644)
 		if err != nil {
 			return "", err
 		}
-
-		_, err = file.Write(req.Content)
+		err = rs.handleChunkInfo(&req, peer, chunkPath)
 		if err != nil {
 			return "", err
 		}
 
+		metrics.UploadChunks.Inc()
+		metrics.UploadBytes.Add(float64(len(req.Content)))
+
 		log.Infof("Received file chunk[%d], remote CID: %s, local CID: %s", req.ChunkId, req.ChunkCid, localCid)
 		log.Infof("Staging file %s generated", filepath.Join(path, req.ChunkCid))
 	} else {
 		// Transport is done
-		key := datastore.NewKey(types.FILE_INFO_PREFIX + req.Cid)
-		if info, err := rs.Db.Get(rs.Ctx, key); err == nil {
-			var fileInfo *types.ReceivedFileInfo
-			err := json.Unmarshal(info, &fileInfo)
-			if err != nil {
-				return "", err
-			}
+		rs.DbLk.Lock()
+		fileInfo, err := rs.getFileInfo(peer, req.Cid)
+		rs.DbLk.Unlock()
+		if err != nil {
+			return "", err
+		}
+		if fileInfo == nil {
+			return "", types.Wrapf(types.ErrInvalidCid, "no chunks of %s received", req.Cid)
+		}
 
-			basePath, err := homedir.Expand(fileInfo.Path)
-			if err != nil {
-				return "", err
-			}
-			log.Info("path: ", basePath)
-
-			var fileContent []byte
-			for _, chunkCid := range fileInfo.ChunkCids {
-				var path = filepath.Join(basePath, chunkCid)
-				file, err := os.Open(path)
-				if err != nil {
-					return "", err
-				}
-
-				content, err := io.ReadAll(file)
-				if err != nil {
-					return "", err
-				}
-
-				fileContent = append(fileContent, content...)
+		var missing []int
+		for chunkId, chunkCid := range fileInfo.ChunkCids {
+			if chunkCid == "" {
+				missing = append(missing, chunkId)
 			}
+		}
+		if len(missing) > 0 {
+			return "", types.Wrapf(types.ErrInvalidParameters, "chunks %v of %s are missing", missing, req.Cid)
+		}
 
-			contentCid, err := utils.CalculateCid(fileContent)
-			if err != nil {
-				return "", err
-			}
+		basePath, err := homedir.Expand(fileInfo.Path)
+		if err != nil {
+			return "", err
+		}
+		log.Info("path: ", basePath)
 
-			log.Info("Requested file, CID: ", req.Cid)
-			log.Info("Requested file, length: ", req.TotalLength)
-			log.Info("Received file, CID: ", contentCid)
-			log.Info("Received file, length: ", len(fileContent))
+		contentCid, size, err := assembleChunks(basePath, req.Cid, fileInfo.ChunkCids)
+		if err != nil {
+			return "", err
+		}
 
-			file, err := os.Create(filepath.Join(basePath, req.Cid))
-			if err != nil {
-				return "", err
-			}
+		log.Info("Requested file, CID: ", req.Cid)
+		log.Info("Requested file, length: ", req.TotalLength)
+		log.Info("Received file, CID: ", contentCid)
+		log.Info("Received file, length: ", size)
 
-			_, err = file.Write(fileContent)
-			if err != nil {
-				return "", err
-			}
-		} else {
-			return "", err
+		if contentCid.String() != req.Cid {
+			_ = os.Remove(filepath.Join(basePath, req.Cid))
+			return "", types.Wrapf(types.ErrInvalidCid, "file cid mismatch, expected %s, but got %s", req.Cid, contentCid)
 		}
+		return contentCid.String(), nil
 	}
 
 	return localCid.String(), nil
 }
 
+/**
+ * concatenate the chunk files into <basePath>/<cid> without loading them into memory.
+ */
+func assembleChunks(basePath string, cidStr string, chunkCids []string) (cid.Cid, uint64, error) {
+	file, err := os.Create(filepath.Join(basePath, cidStr))
+	if err != nil {
+		return cid.Undef, 0, err
+	}
+	defer file.Close()
+
+	for _, chunkCid := range chunkCids {
+		chunk, err := os.Open(filepath.Join(basePath, chunkCid))
+		if err != nil {
+			return cid.Undef, 0, err
+		}
+		_, err = io.Copy(file, chunk)
+		chunk.Close()
+		if err != nil {
+			return cid.Undef, 0, err
+		}
+	}
+
+	_, err = file.Seek(0, io.SeekStart)
+	if err != nil {
+		return cid.Undef, 0, err
+	}
+	return utils.CalculateCidFromReader(file)
+}
+
+/**
+ * report the chunks of the content received from the peer, so that an interrupted upload can be resumed.
+ */
+func (rs *RpcHandler) UploadStatus(params []string) (string, error) {
+	if len(params) != 2 {
+		return "", types.Wrapf(types.ErrInvalidParameters, "invalid params length")
+	}
+	cidStr := params[0]
+	status := types.UploadStatusResp{
+		Cid: cidStr,
+	}
+
+	rs.DbLk.Lock()
+	fileInfo, err := rs.getFileInfo(filepath.Base(params[1]), cidStr)
+	rs.DbLk.Unlock()
+	if err != nil {
+		return "", err
+	}
+
+	if fileInfo != nil && fileInfo.Path == filepath.Join(params[1], cidStr) {
+		basePath, err := homedir.Expand(fileInfo.Path)
+		if err != nil {
+			return "", err
+		}
+
+		status.TotalLength = fileInfo.TotalLength
+		status.TotalChunks = fileInfo.TotalChunks
+		status.ChunkCids = make([]string, len(fileInfo.ChunkCids))
+		for chunkId, chunkCid := range fileInfo.ChunkCids {
+			if chunkCid == "" {
+				continue
+			}
+			// the chunk files may be removed by gc
+			if _, err := os.Stat(filepath.Join(basePath, chunkCid)); err == nil {
+				status.ChunkCids[chunkId] = chunkCid
+			}
+		}
+	}
+
+	b, err := json.Marshal(status)
+	if err != nil {
+		return "", types.Wrap(types.ErrMarshalFailed, err)
+	}
+	return string(b), nil
+}
+
 func (rs *RpcHandler) Create(params []string) (string, error) {
 	if len(params) != 3 {
 		return "", types.Wrapf(types.ErrInvalidParameters, "invalid params length")
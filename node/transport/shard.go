@@ -27,7 +27,7 @@ type CommonMarshaler interface {
 	Marshal(io.Writer, string) error
 }
 
-func HandleRequest(ctx context.Context, peerInfos string, host host.Host, protocol protocol.ID, req interface{}, resp interface{}, isForward bool) error {
+func parsePeerInfos(peerInfos string) (*peer.AddrInfo, error) {
 	var pi *peer.AddrInfo
 	for _, peerInfo := range strings.Split(peerInfos, ",") {
 		if strings.Contains(peerInfo, "udp") || strings.Contains(peerInfo, "127.0.0.1") {
@@ -36,15 +36,22 @@ func HandleRequest(ctx context.Context, peerInfos string, host host.Host, protoc
 
 		a, err := ma.NewMultiaddr(peerInfo)
 		if err != nil {
-			return types.Wrapf(types.ErrInvalidServerAddress, "peerInfo=%s", peerInfo)
+			return nil, types.Wrapf(types.ErrInvalidServerAddress, "peerInfo=%s", peerInfo)
 		}
 		pi, err = peer.AddrInfoFromP2pAddr(a)
 		if err != nil {
-			return types.Wrapf(types.ErrInvalidServerAddress, "a=%v", a)
+			return nil, types.Wrapf(types.ErrInvalidServerAddress, "a=%v", a)
 		}
 	}
+	return pi, nil
+}
+
+func HandleRequest(ctx context.Context, peerInfos string, host host.Host, protocol protocol.ID, req interface{}, resp interface{}, isForward bool) error {
+	pi, err := parsePeerInfos(peerInfos)
+	if err != nil {
+		return err
+	}
 	var stream network.Stream = nil
-	var err error = nil
 	if pi == nil {
 		for _, peerId := range host.Peerstore().Peers() {
 			log.Debug("peerId", peerId)
@@ -0,0 +1,373 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/SaoNetwork/sao-node/types"
+	"github.com/SaoNetwork/sao-node/utils"
+
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/protocol"
+)
+
+const (
+	STREAM_MAX_RETRIES = 3
+	STREAM_TIMEOUT     = 300 * time.Second
+)
+
+/**
+ * open a stream to the peer, the first protocol supported by the peer is selected.
+ */
+func OpenStream(ctx context.Context, peerInfos string, host host.Host, protocols ...protocol.ID) (network.Stream, error) {
+	pi, err := parsePeerInfos(peerInfos)
+	if err != nil {
+		return nil, err
+	}
+
+	if pi == nil {
+		for _, peerId := range host.Peerstore().Peers() {
+			if strings.Contains(peerInfos, peerId.String()) {
+				stream, err := host.NewStream(ctx, peerId, protocols...)
+				if err != nil {
+					return nil, types.Wrap(types.ErrCreateStreamFailed, err)
+				}
+				return stream, nil
+			}
+		}
+		return nil, types.Wrap(types.ErrInvalidServerAddress, nil)
+	}
+
+	err = host.Connect(ctx, *pi)
+	if err != nil {
+		return nil, types.Wrap(types.ErrConnectFailed, err)
+	}
+	stream, err := host.NewStream(ctx, pi.ID, protocols...)
+	if err != nil {
+		return nil, types.Wrap(types.ErrCreateStreamFailed, err)
+	}
+	return stream, nil
+}
+
+/**
+ * move the reader to the offset of shard content.
+ */
+func SeekShardContent(reader io.Reader, offset uint64) error {
+	if offset == 0 {
+		return nil
+	}
+	if s, ok := reader.(io.Seeker); ok {
+		_, err := s.Seek(int64(offset), io.SeekStart)
+		return err
+	}
+	_, err := io.CopyN(io.Discard, reader, int64(offset))
+	return err
+}
+
+/**
+ * send shard content from reader as ShardChunk frames, the first chunk starts at offset.
+ */
+func SendShardChunks(w io.Writer, reader io.Reader, offset uint64) error {
+	buf := make([]byte, types.ShardChunkSize)
+	for {
+		n, err := io.ReadFull(reader, buf)
+		last := false
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			last = true
+		} else if err != nil {
+			return types.Wrap(types.ErrReadFileFailed, err)
+		}
+
+		chunkCid, err := utils.CalculateCid(buf[:n])
+		if err != nil {
+			return err
+		}
+		chunk := types.ShardChunk{
+			Offset:  offset,
+			Cid:     chunkCid,
+			Content: buf[:n],
+			Last:    last,
+		}
+		err = chunk.Marshal(w, types.FormatCbor)
+		if err != nil {
+			return types.Wrap(types.ErrSendRequestFailed, err)
+		}
+		offset += uint64(n)
+
+		if last {
+			return nil
+		}
+	}
+}
+
+/**
+ * receive ShardChunk frames and write the verified content into w until the last chunk,
+ * returns the offset after the last written byte, so that an interrupted transfer can be resumed from there.
+ */
+func ReceiveShardChunks(r io.Reader, w io.Writer, offset uint64) (uint64, error) {
+	for {
+		var chunk types.ShardChunk
+		err := chunk.Unmarshal(r, types.FormatCbor)
+		if err != nil {
+			return offset, types.Wrap(types.ErrReadResponseFailed, err)
+		}
+
+		if chunk.Offset != offset {
+			return offset, types.Wrapf(types.ErrReadResponseFailed, "unexpected chunk offset %d, expected %d", chunk.Offset, offset)
+		}
+		chunkCid, err := utils.CalculateCid(chunk.Content)
+		if err != nil {
+			return offset, err
+		}
+		if !chunkCid.Equals(chunk.Cid) {
+			return offset, types.Wrapf(types.ErrInvalidCid, "chunk at offset %d: expected %v, got %v", offset, chunk.Cid, chunkCid)
+		}
+
+		_, err = w.Write(chunk.Content)
+		if err != nil {
+			return offset, types.Wrap(types.ErrWriteFileFailed, err)
+		}
+		offset += uint64(len(chunk.Content))
+
+		if chunk.Last {
+			return offset, nil
+		}
+	}
+}
+
+/**
+ * respond a shard load request in stream protocols, the content from reader is sent after the response header.
+ */
+func RespondShardStream(s network.Stream, resp types.ShardLoadResp, reader io.Reader, offset uint64) {
+	if c, ok := reader.(io.Closer); ok {
+		defer c.Close()
+	}
+
+	err := resp.Marshal(s, types.FormatCbor)
+	if err != nil {
+		log.Error(types.Wrap(types.ErrMarshalFailed, err))
+		return
+	}
+
+	if resp.Code == 0 && reader != nil {
+		err = SeekShardContent(reader, offset)
+		if err != nil {
+			log.Error(types.Wrap(types.ErrReadFileFailed, err))
+			return
+		}
+		err = SendShardChunks(s, reader, offset)
+		if err != nil {
+			log.Error(err)
+			return
+		}
+	}
+
+	if err = s.CloseWrite(); err != nil {
+		log.Error(types.Wrap(types.ErrCloseStreamFailed, err))
+	}
+}
+
+/**
+ * request shard content via streamProtocol and write it into w, interrupted transfers are resumed from
+ * the received offset. falls back to the given non-stream protocol if the peer doesn't support streaming.
+ */
+func RequestShardStream(
+	ctx context.Context,
+	peerInfos string,
+	host host.Host,
+	streamProtocol protocol.ID,
+	fallbackProtocol protocol.ID,
+	req types.ShardLoadReq,
+	resp *types.ShardLoadResp,
+	w io.Writer,
+) error {
+	var err error
+	for retryTimes := 0; retryTimes < STREAM_MAX_RETRIES; retryTimes++ {
+		var offset uint64
+		offset, err = doShardStream(ctx, peerInfos, host, streamProtocol, fallbackProtocol, req, resp, w)
+		if err == nil || resp.Code != 0 {
+			return err
+		}
+
+		log.Warnf("shard %v stream interrupted at offset %d: %v", req.Cid, offset, err)
+		req.Offset = offset
+		select {
+		case <-time.After(time.Second * time.Duration(retryTimes+1)):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return err
+}
+
+func doShardStream(
+	ctx context.Context,
+	peerInfos string,
+	host host.Host,
+	streamProtocol protocol.ID,
+	fallbackProtocol protocol.ID,
+	req types.ShardLoadReq,
+	resp *types.ShardLoadResp,
+	w io.Writer,
+) (uint64, error) {
+	stream, err := OpenStream(ctx, peerInfos, host, streamProtocol, fallbackProtocol)
+	if err != nil {
+		return req.Offset, err
+	}
+	defer stream.Close()
+
+	_ = stream.SetReadDeadline(time.Now().Add(STREAM_TIMEOUT))
+	defer stream.SetReadDeadline(time.Time{}) // nolint
+
+	if stream.Protocol() != streamProtocol {
+		log.Debugf("peer doesn't support %s, fallback to %s", streamProtocol, stream.Protocol())
+		err = DoRequest(ctx, stream, &req, resp, types.FormatCbor)
+		if err != nil {
+			return req.Offset, err
+		}
+		if resp.Code == 0 {
+			err = writeFallbackContent(resp, req.Offset, w)
+		}
+		return req.Offset, err
+	}
+
+	err = req.Marshal(stream, types.FormatCbor)
+	if err != nil {
+		return req.Offset, types.Wrap(types.ErrSendRequestFailed, err)
+	}
+	err = stream.CloseWrite()
+	if err != nil {
+		log.Error(types.Wrap(types.ErrCloseStreamFailed, err))
+	}
+
+	err = resp.Unmarshal(stream, types.FormatCbor)
+	if err != nil {
+		return req.Offset, types.Wrap(types.ErrReadResponseFailed, err)
+	}
+	if resp.Code != 0 {
+		return req.Offset, nil
+	}
+
+	return ReceiveShardChunks(stream, w, req.Offset)
+}
+
+/**
+ * write the whole shard content of a non-stream response from the offset already received.
+ * a response shorter than the offset fails the request instead of being retried.
+ */
+func writeFallbackContent(resp *types.ShardLoadResp, offset uint64, w io.Writer) error {
+	content := resp.Content
+	resp.Content = nil
+	if offset > uint64(len(content)) {
+		resp.Code = types.ErrorCodeInternalErr
+		resp.Message = fmt.Sprintf("offset %d exceeds the shard size %d", offset, len(content))
+		return types.Wrapf(types.ErrReadResponseFailed, "%s", resp.Message)
+	}
+	_, err := io.Copy(w, bytes.NewReader(content[offset:]))
+	return err
+}
+
+/**
+ * send shard content to the new provider via migrate stream protocol, the receiver acknowledges the offset
+ * it already has so that an interrupted migration only sends the missing part.
+ * falls back to the non-stream migrate protocol if the peer doesn't support streaming.
+ */
+func RequestShardMigrateStream(
+	ctx context.Context,
+	peerInfos string,
+	host host.Host,
+	req types.ShardMigrateReq,
+	resp *types.ShardMigrateResp,
+	newReader func() (io.Reader, error),
+) error {
+	var err error
+	for retryTimes := 0; retryTimes < STREAM_MAX_RETRIES; retryTimes++ {
+		err = doShardMigrateStream(ctx, peerInfos, host, req, resp, newReader)
+		if err == nil {
+			return nil
+		}
+
+		log.Warnf("shard %s migrate stream interrupted: %v", req.Cid, err)
+		select {
+		case <-time.After(time.Second * time.Duration(retryTimes+1)):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return err
+}
+
+func doShardMigrateStream(
+	ctx context.Context,
+	peerInfos string,
+	host host.Host,
+	req types.ShardMigrateReq,
+	resp *types.ShardMigrateResp,
+	newReader func() (io.Reader, error),
+) error {
+	reader, err := newReader()
+	if err != nil {
+		return err
+	}
+	if c, ok := reader.(io.Closer); ok {
+		defer c.Close()
+	}
+
+	stream, err := OpenStream(ctx, peerInfos, host, types.ShardMigrateStreamProtocol, types.ShardMigrateProtocol)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	_ = stream.SetReadDeadline(time.Now().Add(STREAM_TIMEOUT))
+	defer stream.SetReadDeadline(time.Time{}) // nolint
+
+	if stream.Protocol() != types.ShardMigrateStreamProtocol {
+		log.Debugf("peer doesn't support %s, fallback to %s", types.ShardMigrateStreamProtocol, stream.Protocol())
+		req.Content, err = io.ReadAll(reader)
+		if err != nil {
+			return types.Wrap(types.ErrReadFileFailed, err)
+		}
+		return DoRequest(ctx, stream, &req, resp, types.FormatCbor)
+	}
+
+	err = req.Marshal(stream, types.FormatCbor)
+	if err != nil {
+		return types.Wrap(types.ErrSendRequestFailed, err)
+	}
+
+	var ack types.ShardStreamAck
+	err = ack.Unmarshal(stream, types.FormatCbor)
+	if err != nil {
+		return types.Wrap(types.ErrReadResponseFailed, err)
+	}
+	if ack.Code != 0 {
+		resp.Code = ack.Code
+		resp.Message = ack.Message
+		return nil
+	}
+
+	err = SeekShardContent(reader, ack.Offset)
+	if err != nil {
+		return types.Wrap(types.ErrReadFileFailed, err)
+	}
+	err = SendShardChunks(stream, reader, ack.Offset)
+	if err != nil {
+		return err
+	}
+	err = stream.CloseWrite()
+	if err != nil {
+		log.Error(types.Wrap(types.ErrCloseStreamFailed, err))
+	}
+
+	err = resp.Unmarshal(stream, types.FormatCbor)
+	if err != nil {
+		return types.Wrap(types.ErrReadResponseFailed, err)
+	}
+	return nil
+}
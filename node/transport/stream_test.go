@@ -0,0 +1,89 @@
+package transport
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/SaoNetwork/sao-node/types"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestShardChunks(t *testing.T) {
+	content := bytes.Repeat([]byte("sao network shard chunk "), types.ShardChunkSize/8)
+
+	var stream bytes.Buffer
+	err := SendShardChunks(&stream, bytes.NewReader(content), 0)
+	require.NoError(t, err)
+
+	var received bytes.Buffer
+	offset, err := ReceiveShardChunks(&stream, &received, 0)
+	require.NoError(t, err)
+	require.Equal(t, uint64(len(content)), offset)
+	require.Equal(t, content, received.Bytes())
+
+	// resume from an offset
+	resumeAt := uint64(types.ShardChunkSize + 100)
+	reader := bytes.NewReader(content)
+	require.NoError(t, SeekShardContent(reader, resumeAt))
+	stream.Reset()
+	err = SendShardChunks(&stream, reader, resumeAt)
+	require.NoError(t, err)
+
+	received.Reset()
+	received.Write(content[:resumeAt])
+	offset, err = ReceiveShardChunks(&stream, &received, resumeAt)
+	require.NoError(t, err)
+	require.Equal(t, uint64(len(content)), offset)
+	require.Equal(t, content, received.Bytes())
+
+	// unexpected offset
+	stream.Reset()
+	err = SendShardChunks(&stream, bytes.NewReader(content), 0)
+	require.NoError(t, err)
+	_, err = ReceiveShardChunks(&stream, &received, 10)
+	require.Error(t, err)
+
+	// empty content
+	stream.Reset()
+	err = SendShardChunks(&stream, bytes.NewReader(nil), 0)
+	require.NoError(t, err)
+	received.Reset()
+	offset, err = ReceiveShardChunks(&stream, &received, 0)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), offset)
+}
+
+func TestShardChunksInterrupted(t *testing.T) {
+	content := bytes.Repeat([]byte{0x5a}, types.ShardChunkSize*2+1)
+
+	var stream bytes.Buffer
+	err := SendShardChunks(&stream, bytes.NewReader(content), 0)
+	require.NoError(t, err)
+
+	// only the first chunk and a part of the second arrived
+	truncated := bytes.NewReader(stream.Bytes()[:types.ShardChunkSize+types.ShardChunkSize/2])
+	var received bytes.Buffer
+	offset, err := ReceiveShardChunks(truncated, &received, 0)
+	require.Error(t, err)
+	require.Equal(t, uint64(types.ShardChunkSize), offset)
+	require.Equal(t, content[:offset], received.Bytes())
+}
+
+func TestFallbackContent(t *testing.T) {
+	content := []byte("sao network fallback content")
+
+	var received bytes.Buffer
+	resp := types.ShardLoadResp{Content: content}
+	require.NoError(t, writeFallbackContent(&resp, 4, &received))
+	require.Equal(t, content[4:], received.Bytes())
+	require.Nil(t, resp.Content)
+
+	// the peer returned less than what is already received
+	received.Reset()
+	resp = types.ShardLoadResp{Content: content}
+	err := writeFallbackContent(&resp, uint64(len(content)+1), &received)
+	require.True(t, types.ErrReadResponseFailed.Is(err))
+	require.NotZero(t, resp.Code)
+	require.Zero(t, received.Len())
+}
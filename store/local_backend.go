@@ -0,0 +1,224 @@
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/SaoNetwork/sao-node/types"
+
+	"github.com/ipfs/go-cid"
+	"github.com/mitchellh/go-homedir"
+	"github.com/multiformats/go-multihash"
+)
+
+const (
+	localBlocksDir = "blocks"
+	localTmpDir    = "tmp"
+)
+
+/**
+ * LocalBackend stores content on the local filesystem, keyed by the multihash of the content,
+ * so that the same content is found by both CIDv0 and CIDv1.
+ * layout: <path>/blocks/<next to last 2 chars of key>/<key>
+ */
+type LocalBackend struct {
+	path string
+}
+
+func NewLocalBackend(path string) (*LocalBackend, error) {
+	if path == "" {
+		return nil, types.Wrapf(types.ErrInvalidPath, "empty local backend path")
+	}
+
+	root, err := homedir.Expand(path)
+	if err != nil {
+		return nil, types.Wrapf(types.ErrInvalidPath, "%s", path)
+	}
+	root, err = filepath.Abs(root)
+	if err != nil {
+		return nil, types.Wrapf(types.ErrInvalidPath, "%s", path)
+	}
+
+	return &LocalBackend{
+		path: root,
+	}, nil
+}
+
+func (b *LocalBackend) Id() string {
+	return fmt.Sprintf("%s-%s", b.Type(), b.path)
+}
+
+func (b *LocalBackend) Type() string {
+	return "local"
+}
+
+func (b *LocalBackend) Open() error {
+	for _, dir := range []string{localBlocksDir, localTmpDir} {
+		err := os.MkdirAll(filepath.Join(b.path, dir), 0755)
+		if err != nil {
+			return types.Wrap(types.ErrCreateDirFailed, err)
+		}
+	}
+
+	// leftovers of interrupted writes
+	tmpFiles, err := os.ReadDir(filepath.Join(b.path, localTmpDir))
+	if err != nil {
+		return types.Wrap(types.ErrOpenLocalBackendFailed, err)
+	}
+	for _, f := range tmpFiles {
+		err = os.Remove(filepath.Join(b.path, localTmpDir, f.Name()))
+		if err != nil {
+			log.Warnf("%s remove tmp file %s error: %v", b.Id(), f.Name(), err)
+		}
+	}
+	return nil
+}
+
+func (b *LocalBackend) Close() error {
+	return nil
+}
+
+/**
+ * content is written into a temporary file first and renamed to its content address after fsync,
+ * so a crash never leaves a partial block behind the key.
+ */
+func (b *LocalBackend) Store(ctx context.Context, reader io.Reader) (any, error) {
+	tmp, err := os.CreateTemp(filepath.Join(b.path, localTmpDir), "block-")
+	if err != nil {
+		return nil, types.Wrap(types.ErrStoreFailed, err)
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName)
+
+	hasher := sha256.New()
+	_, err = io.Copy(io.MultiWriter(tmp, hasher), reader)
+	if err != nil {
+		tmp.Close()
+		return nil, types.Wrap(types.ErrStoreFailed, err)
+	}
+	err = tmp.Sync()
+	if err != nil {
+		tmp.Close()
+		return nil, types.Wrap(types.ErrStoreFailed, err)
+	}
+	err = tmp.Close()
+	if err != nil {
+		return nil, types.Wrap(types.ErrStoreFailed, err)
+	}
+
+	mh, err := multihash.Encode(hasher.Sum(nil), multihash.SHA2_256)
+	if err != nil {
+		return nil, types.Wrap(types.ErrStoreFailed, err)
+	}
+	c := cid.NewCidV1(cid.Raw, mh)
+
+	blockPath := b.blockPath(c)
+	err = os.MkdirAll(filepath.Dir(blockPath), 0755)
+	if err != nil {
+		return nil, types.Wrap(types.ErrCreateDirFailed, err)
+	}
+	err = os.Rename(tmpName, blockPath)
+	if err != nil {
+		return nil, types.Wrap(types.ErrStoreFailed, err)
+	}
+	err = syncDir(filepath.Dir(blockPath))
+	if err != nil {
+		return nil, types.Wrap(types.ErrStoreFailed, err)
+	}
+
+	log.Debugf("%s store hash: %v", b.Id(), c)
+	return c.String(), nil
+}
+
+func (b *LocalBackend) IsExist(ctx context.Context, cid cid.Cid) (bool, error) {
+	_, err := os.Stat(b.blockPath(cid))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, types.Wrap(types.ErrStatFailed, err)
+	}
+	return true, nil
+}
+
+/**
+ * the returned reader is an *os.File, caller should close it.
+ */
+func (b *LocalBackend) Get(ctx context.Context, cid cid.Cid) (io.Reader, error) {
+	file, err := os.Open(b.blockPath(cid))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, types.Wrapf(types.ErrDataMissing, "%v", cid)
+		}
+		return nil, types.Wrap(types.ErrGetFailed, err)
+	}
+	return file, nil
+}
+
+func (b *LocalBackend) Remove(ctx context.Context, cid cid.Cid) error {
+	err := os.Remove(b.blockPath(cid))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return types.Wrapf(types.ErrDataMissing, "%v", cid)
+		}
+		return types.Wrap(types.ErrRemoveFailed, err)
+	}
+	return nil
+}
+
+/**
+ * total and available bytes of the filesystem where the blocks are stored.
+ */
+func (b *LocalBackend) Capacity() (uint64, uint64, error) {
+	var stat syscall.Statfs_t
+	err := syscall.Statfs(b.path, &stat)
+	if err != nil {
+		return 0, 0, types.Wrap(types.ErrStatFailed, err)
+	}
+	return stat.Blocks * uint64(stat.Bsize), stat.Bavail * uint64(stat.Bsize), nil
+}
+
+/**
+ * bytes of all blocks, the blocks directory is walked on every call.
+ */
+func (b *LocalBackend) Used() (uint64, error) {
+	var used uint64
+	err := filepath.WalkDir(filepath.Join(b.path, localBlocksDir), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		used += uint64(info.Size())
+		return nil
+	})
+	if err != nil {
+		return 0, types.Wrap(types.ErrStatFailed, err)
+	}
+	return used, nil
+}
+
+func (b *LocalBackend) blockPath(c cid.Cid) string {
+	key := cid.NewCidV1(cid.Raw, c.Hash()).String()
+	return filepath.Join(b.path, localBlocksDir, key[len(key)-3:len(key)-1], key)
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
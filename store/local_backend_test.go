@@ -0,0 +1,75 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/SaoNetwork/sao-node/utils"
+
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalBackend(t *testing.T) {
+	ctx := context.Background()
+	b, err := NewLocalBackend(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, b.Open())
+
+	data := bytes.Repeat([]byte{0x01}, 1024*257)
+	c, err := utils.CalculateCid(data)
+	require.NoError(t, err)
+
+	exists, err := b.IsExist(ctx, c)
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	s, err := b.Store(ctx, bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Equal(t, cid.NewCidV1(cid.Raw, c.Hash()).String(), s)
+
+	// both cid versions refer to the same block
+	for _, key := range []cid.Cid{c, cid.NewCidV1(cid.Raw, c.Hash())} {
+		exists, err = b.IsExist(ctx, key)
+		require.NoError(t, err)
+		require.True(t, exists)
+
+		reader, err := b.Get(ctx, key)
+		require.NoError(t, err)
+		content, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		require.NoError(t, reader.(io.Closer).Close())
+		require.Equal(t, data, content)
+	}
+
+	// storing the same content again is fine
+	_, err = b.Store(ctx, bytes.NewReader(data))
+	require.NoError(t, err)
+
+	used, err := b.Used()
+	require.NoError(t, err)
+	require.Equal(t, uint64(len(data)), used)
+
+	total, free, err := b.Capacity()
+	require.NoError(t, err)
+	require.True(t, total >= free)
+
+	require.NoError(t, b.Remove(ctx, c))
+	exists, err = b.IsExist(ctx, c)
+	require.NoError(t, err)
+	require.False(t, exists)
+	_, err = b.Get(ctx, c)
+	require.Error(t, err)
+	require.Error(t, b.Remove(ctx, c))
+
+	used, err = b.Used()
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), used)
+
+	tmpFiles, err := os.ReadDir(b.path + "/" + localTmpDir)
+	require.NoError(t, err)
+	require.Len(t, tmpFiles, 0)
+}
@@ -0,0 +1,234 @@
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path"
+
+	"github.com/SaoNetwork/sao-node/types"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/ipfs/go-cid"
+	"github.com/multiformats/go-multihash"
+)
+
+type S3Config struct {
+	Endpoint        string
+	Region          string
+	Bucket          string
+	Prefix          string
+	AccessKeyId     string
+	SecretAccessKey string
+	PathStyle       bool
+	PartSize        int64
+}
+
+/**
+ * S3Backend stores content as objects of a S3 compatible storage, e.g. AWS S3 or MinIO.
+ * objects are keyed by the multihash of the content like LocalBackend: <prefix>/<raw cidv1>
+ */
+type S3Backend struct {
+	cfg      S3Config
+	client   *s3.S3
+	uploader *s3manager.Uploader
+}
+
+func NewS3Backend(cfg S3Config) (*S3Backend, error) {
+	if cfg.Bucket == "" {
+		return nil, types.Wrapf(types.ErrOpenS3BackendFailed, "empty bucket")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if cfg.PartSize == 0 {
+		cfg.PartSize = s3manager.DefaultUploadPartSize
+	}
+	if cfg.PartSize < s3manager.MinUploadPartSize {
+		return nil, types.Wrapf(types.ErrOpenS3BackendFailed, "part size should be at least %d", s3manager.MinUploadPartSize)
+	}
+
+	return &S3Backend{
+		cfg: cfg,
+	}, nil
+}
+
+func (b *S3Backend) Id() string {
+	return fmt.Sprintf("%s-%s/%s/%s", b.Type(), b.cfg.Endpoint, b.cfg.Bucket, b.cfg.Prefix)
+}
+
+func (b *S3Backend) Type() string {
+	return "s3"
+}
+
+func (b *S3Backend) Open() error {
+	awsCfg := aws.NewConfig().
+		WithRegion(b.cfg.Region).
+		WithS3ForcePathStyle(b.cfg.PathStyle)
+	if b.cfg.Endpoint != "" {
+		awsCfg = awsCfg.WithEndpoint(b.cfg.Endpoint)
+	}
+	if b.cfg.AccessKeyId != "" {
+		awsCfg = awsCfg.WithCredentials(credentials.NewStaticCredentials(b.cfg.AccessKeyId, b.cfg.SecretAccessKey, ""))
+	}
+
+	sess, err := session.NewSession(awsCfg)
+	if err != nil {
+		return types.Wrap(types.ErrOpenS3BackendFailed, err)
+	}
+	b.client = s3.New(sess)
+	b.uploader = s3manager.NewUploaderWithClient(b.client, func(u *s3manager.Uploader) {
+		u.PartSize = b.cfg.PartSize
+	})
+
+	_, err = b.client.HeadBucket(&s3.HeadBucketInput{
+		Bucket: aws.String(b.cfg.Bucket),
+	})
+	if err != nil {
+		return types.Wrap(types.ErrOpenS3BackendFailed, err)
+	}
+	return nil
+}
+
+func (b *S3Backend) Close() error {
+	return nil
+}
+
+/**
+ * the object key depends on the content hash, so the content is spooled into a temporary file before uploading.
+ * content larger than the part size is uploaded in multiple parts.
+ */
+func (b *S3Backend) Store(ctx context.Context, reader io.Reader) (any, error) {
+	tmp, err := os.CreateTemp("", "sao-s3-")
+	if err != nil {
+		return nil, types.Wrap(types.ErrStoreFailed, err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	hasher := sha256.New()
+	_, err = io.Copy(io.MultiWriter(tmp, hasher), reader)
+	if err != nil {
+		return nil, types.Wrap(types.ErrStoreFailed, err)
+	}
+	_, err = tmp.Seek(0, io.SeekStart)
+	if err != nil {
+		return nil, types.Wrap(types.ErrStoreFailed, err)
+	}
+
+	mh, err := multihash.Encode(hasher.Sum(nil), multihash.SHA2_256)
+	if err != nil {
+		return nil, types.Wrap(types.ErrStoreFailed, err)
+	}
+	c := cid.NewCidV1(cid.Raw, mh)
+
+	_, err = b.uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket: aws.String(b.cfg.Bucket),
+		Key:    aws.String(b.objectKey(c)),
+		Body:   tmp,
+	})
+	if err != nil {
+		return nil, types.Wrap(types.ErrStoreFailed, err)
+	}
+
+	log.Debugf("%s store hash: %v", b.Id(), c)
+	return c.String(), nil
+}
+
+func (b *S3Backend) IsExist(ctx context.Context, cid cid.Cid) (bool, error) {
+	_, err := b.client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(b.cfg.Bucket),
+		Key:    aws.String(b.objectKey(cid)),
+	})
+	if err != nil {
+		if isS3NotFound(err) {
+			return false, nil
+		}
+		return false, types.Wrap(types.ErrStatFailed, err)
+	}
+	return true, nil
+}
+
+/**
+ * the returned reader is the object body, caller should close it.
+ */
+func (b *S3Backend) Get(ctx context.Context, cid cid.Cid) (io.Reader, error) {
+	out, err := b.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(b.cfg.Bucket),
+		Key:    aws.String(b.objectKey(cid)),
+	})
+	if err != nil {
+		if isS3NotFound(err) {
+			return nil, types.Wrapf(types.ErrDataMissing, "%v", cid)
+		}
+		return nil, types.Wrap(types.ErrGetFailed, err)
+	}
+	return out.Body, nil
+}
+
+/**
+ * S3 deletes a missing object silently, it's looked up first to report ErrDataMissing like Get does.
+ */
+func (b *S3Backend) Remove(ctx context.Context, cid cid.Cid) error {
+	exists, err := b.IsExist(ctx, cid)
+	if err != nil {
+		return types.Wrap(types.ErrRemoveFailed, err)
+	}
+	if !exists {
+		return types.Wrapf(types.ErrDataMissing, "%v", cid)
+	}
+
+	_, err = b.client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(b.cfg.Bucket),
+		Key:    aws.String(b.objectKey(cid)),
+	})
+	if err != nil {
+		return types.Wrap(types.ErrRemoveFailed, err)
+	}
+	return nil
+}
+
+/**
+ * bytes of all objects under the prefix, objects are listed on every call.
+ */
+func (b *S3Backend) Used() (uint64, error) {
+	var used uint64
+	prefix := b.cfg.Prefix
+	if prefix != "" && prefix[len(prefix)-1] != '/' {
+		prefix += "/"
+	}
+	err := b.client.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+		Bucket: aws.String(b.cfg.Bucket),
+		Prefix: aws.String(prefix),
+	}, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		for _, obj := range page.Contents {
+			used += uint64(aws.Int64Value(obj.Size))
+		}
+		return true
+	})
+	if err != nil {
+		return 0, types.Wrap(types.ErrStatFailed, err)
+	}
+	return used, nil
+}
+
+func (b *S3Backend) objectKey(c cid.Cid) string {
+	return path.Join(b.cfg.Prefix, cid.NewCidV1(cid.Raw, c.Hash()).String())
+}
+
+func isS3NotFound(err error) bool {
+	if aerr, ok := err.(awserr.RequestFailure); ok && aerr.StatusCode() == 404 {
+		return true
+	}
+	if aerr, ok := err.(awserr.Error); ok {
+		return aerr.Code() == s3.ErrCodeNoSuchKey || aerr.Code() == "NotFound"
+	}
+	return false
+}
@@ -0,0 +1,168 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/SaoNetwork/sao-node/types"
+	"github.com/SaoNetwork/sao-node/utils"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeS3 is a minimal in-memory stand-in of a path style S3 service.
+type fakeS3 struct {
+	sync.Mutex
+	bucket     string
+	objects    map[string][]byte
+	uploads    map[string]map[int][]byte
+	multiparts int
+}
+
+func newFakeS3(bucket string) *fakeS3 {
+	return &fakeS3{
+		bucket:  bucket,
+		objects: make(map[string][]byte),
+		uploads: make(map[string]map[int][]byte),
+	}
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.Lock()
+	defer f.Unlock()
+
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+	if parts[0] != f.bucket {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if len(parts) == 1 {
+		// HeadBucket
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	key := parts[1]
+	query := r.URL.Query()
+	body, _ := io.ReadAll(r.Body)
+
+	switch {
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		uploadId := fmt.Sprintf("upload-%d", len(f.uploads))
+		f.uploads[uploadId] = make(map[int][]byte)
+		fmt.Fprintf(w, "<InitiateMultipartUploadResult><Bucket>%s</Bucket><Key>%s</Key><UploadId>%s</UploadId></InitiateMultipartUploadResult>", f.bucket, key, uploadId)
+	case r.Method == http.MethodPut && query.Has("uploadId"):
+		partNumber, _ := strconv.Atoi(query.Get("partNumber"))
+		f.uploads[query.Get("uploadId")][partNumber] = body
+		w.Header().Set("ETag", fmt.Sprintf("\"%d\"", partNumber))
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		upload := f.uploads[query.Get("uploadId")]
+		var numbers []int
+		for n := range upload {
+			numbers = append(numbers, n)
+		}
+		sort.Ints(numbers)
+		var content []byte
+		for _, n := range numbers {
+			content = append(content, upload[n]...)
+		}
+		f.objects[key] = content
+		f.multiparts++
+		delete(f.uploads, query.Get("uploadId"))
+		fmt.Fprintf(w, "<CompleteMultipartUploadResult><Bucket>%s</Bucket><Key>%s</Key><ETag>\"x\"</ETag></CompleteMultipartUploadResult>", f.bucket, key)
+	case r.Method == http.MethodDelete && query.Has("uploadId"):
+		delete(f.uploads, query.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut:
+		f.objects[key] = body
+		w.Header().Set("ETag", "\"x\"")
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		content, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			if r.Method == http.MethodGet {
+				fmt.Fprint(w, "<Error><Code>NoSuchKey</Code><Message>not found</Message></Error>")
+			}
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(content)
+		}
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+func TestS3Backend(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeS3("sao")
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	b, err := NewS3Backend(S3Config{
+		Endpoint:        server.URL,
+		Bucket:          "sao",
+		Prefix:          "shards",
+		AccessKeyId:     "test",
+		SecretAccessKey: "test",
+		PathStyle:       true,
+	})
+	require.NoError(t, err)
+	require.NoError(t, b.Open())
+
+	for _, size := range []int{3, 11 * 1024 * 1024} {
+		data := bytes.Repeat([]byte{0x01}, size)
+		c, err := utils.CalculateCid(data)
+		require.NoError(t, err)
+
+		exists, err := b.IsExist(ctx, c)
+		require.NoError(t, err)
+		require.False(t, exists)
+
+		_, err = b.Store(ctx, bytes.NewReader(data))
+		require.NoError(t, err)
+
+		exists, err = b.IsExist(ctx, c)
+		require.NoError(t, err)
+		require.True(t, exists)
+
+		reader, err := b.Get(ctx, c)
+		require.NoError(t, err)
+		content, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		require.Equal(t, data, content)
+
+		require.NoError(t, b.Remove(ctx, c))
+		exists, err = b.IsExist(ctx, c)
+		require.NoError(t, err)
+		require.False(t, exists)
+		_, err = b.Get(ctx, c)
+		require.True(t, types.ErrDataMissing.Is(err))
+		err = b.Remove(ctx, c)
+		require.True(t, types.ErrDataMissing.Is(err))
+	}
+	// only the large content is uploaded in parts
+	require.Equal(t, 1, fake.multiparts)
+
+	wrongBucket, err := NewS3Backend(S3Config{
+		Endpoint:        server.URL,
+		Bucket:          "unknown",
+		AccessKeyId:     "test",
+		SecretAccessKey: "test",
+		PathStyle:       true,
+	})
+	require.NoError(t, err)
+	require.Error(t, wrongBucket.Open())
+}
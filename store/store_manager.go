@@ -3,15 +3,33 @@ package store
 import (
 	"context"
 	"io"
+	"os"
+	"sync"
+	"time"
 
+	"github.com/SaoNetwork/sao-node/metrics"
 	"github.com/SaoNetwork/sao-node/types"
 
 	"github.com/ipfs/go-cid"
 	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
 )
 
 var log = logging.Logger("store")
 
+const (
+	// a shard is stored only if all backends succeed
+	ReplicationWriteAll = "write-all"
+	// a shard is stored if at least quorum backends succeed
+	ReplicationWriteQuorum = "write-quorum"
+	// a shard is stored once the first backend succeeds, other backends are written asynchronously
+	ReplicationPrimaryMirror = "primary-mirror"
+
+	DEFAULT_FAILURE_THRESHOLD    = 3
+	DEFAULT_CIRCUIT_BREAK_PERIOD = time.Minute
+	EXIST_CHECK_TIMEOUT          = 10 * time.Second
+)
+
 type StoreBackend interface {
 	Id() string
 	Type() string
@@ -23,18 +41,99 @@ type StoreBackend interface {
 	IsExist(ctx context.Context, cid cid.Cid) (bool, error)
 }
 
+// CapacityReporter is implemented by backends which know their storage capacity.
+type CapacityReporter interface {
+	Capacity() (total uint64, free uint64, err error)
+}
+
+// UsageReporter is implemented by backends which know the bytes they store.
+type UsageReporter interface {
+	Used() (uint64, error)
+}
+
+type BackendUsage struct {
+	Id string
+	// false if the backend doesn't report its usage
+	UsedKnown bool
+	Used      uint64
+	// false if the backend doesn't report its capacity
+	CapacityKnown bool
+	Total         uint64
+	Free          uint64
+}
+
+type ReplicationPolicy struct {
+	Mode string
+	// minimum successful backends in write-quorum mode, between 1 and the number of backends
+	Quorum int
+	// consecutive failures before a backend is skipped
+	FailureThreshold int
+	// how long a failing backend is skipped before it's tried again
+	CircuitBreakPeriod time.Duration
+}
+
+type backendState struct {
+	StoreBackend
+	failures    int
+	lastErr     string
+	lastFailure time.Time
+	brokenUntil time.Time
+}
+
 type StoreManager struct {
-	backends []StoreBackend
+	lk       sync.RWMutex
+	backends []*backendState
+	policy   ReplicationPolicy
+	// backend id -> cids which failed to be written into the backend
+	repairs map[string]map[cid.Cid]struct{}
 }
 
-func NewStoreManager(initial []StoreBackend) *StoreManager {
-	return &StoreManager{
-		backends: initial,
+func NewStoreManager(initial []StoreBackend, policy ReplicationPolicy) *StoreManager {
+	if policy.Mode == "" {
+		policy.Mode = ReplicationWriteAll
+	}
+	if policy.FailureThreshold <= 0 {
+		policy.FailureThreshold = DEFAULT_FAILURE_THRESHOLD
+	}
+	if policy.CircuitBreakPeriod <= 0 {
+		policy.CircuitBreakPeriod = DEFAULT_CIRCUIT_BREAK_PERIOD
 	}
+
+	ss := &StoreManager{
+		policy:  policy,
+		repairs: make(map[string]map[cid.Cid]struct{}),
+	}
+	for _, back := range initial {
+		ss.AddBackend(back)
+	}
+	return ss
+}
+
+/**
+ * check the replication policy against the backends, it should be called once all backends are added.
+ */
+func (ss *StoreManager) Validate() error {
+	return ss.policy.Validate(len(ss.getBackends()))
+}
+
+func (p ReplicationPolicy) Validate(backends int) error {
+	switch p.Mode {
+	case ReplicationWriteAll, ReplicationPrimaryMirror:
+	case ReplicationWriteQuorum:
+		if p.Quorum < 1 || p.Quorum > backends {
+			return types.Wrapf(types.ErrInvalidParameters, "invalid replication quorum %d, there are %d backends", p.Quorum, backends)
+		}
+	default:
+		return types.Wrapf(types.ErrInvalidParameters, "invalid replication policy %s", p.Mode)
+	}
+	return nil
 }
 
 func (ss *StoreManager) AddBackend(backend StoreBackend) {
-	ss.backends = append(ss.backends, backend)
+	ss.lk.Lock()
+	defer ss.lk.Unlock()
+
+	ss.backends = append(ss.backends, &backendState{StoreBackend: backend})
 }
 
 func (ss *StoreManager) Type() string {
@@ -45,7 +144,7 @@ func (ss *StoreManager) Open() error {
 	// TODO: any backend open error will return error.
 	// in error case, handle already opened backend.
 	var err error
-	for _, back := range ss.backends {
+	for _, back := range ss.getBackends() {
 		err = back.Open()
 		if err != nil {
 			log.Errorf("%s open error: %v", back.Id(), err)
@@ -57,7 +156,7 @@ func (ss *StoreManager) Open() error {
 
 func (ss *StoreManager) Close() error {
 	var err error
-	for _, back := range ss.backends {
+	for _, back := range ss.getBackends() {
 		err = back.Close()
 		if err != nil {
 			log.Errorf("%s close err: %v", back.Id(), err)
@@ -67,46 +166,132 @@ func (ss *StoreManager) Close() error {
 	return nil
 }
 
+/**
+ * store the content into backends according to the replication policy.
+ * backends which are skipped or failed are remembered, the repair loop copies the content to them later.
+ */
 func (ss *StoreManager) Store(ctx context.Context, cid cid.Cid, reader io.Reader) (any, error) {
-	var err error
-	for _, back := range ss.backends {
-		_, err = back.Store(ctx, reader)
-		if err != nil {
-			log.Errorf("%s store error: %v", back.Id(), err)
+	backends := ss.getBackends()
+	if len(backends) == 0 {
+		return nil, types.Wrapf(types.ErrStoreFailed, "no backend")
+	}
+
+	var targets []*backendState
+	for _, back := range backends {
+		if ss.isHealthy(back) {
+			targets = append(targets, back)
 		} else {
-			err = nil
+			log.Warnf("%s is unhealthy, skip storing cid=%v", back.Id(), cid)
+			ss.addRepair(back, cid)
+		}
+	}
+	if len(targets) == 0 {
+		return nil, types.Wrapf(types.ErrStoreFailed, "no healthy backend")
+	}
+
+	if len(backends) == 1 {
+		return nil, ss.storeTo(ctx, targets[0], cid, reader)
+	}
+
+	// every backend consumes the content, so keep it in a temporary file.
+	spool, size, err := spoolContent(reader)
+	if err != nil {
+		return nil, err
+	}
+	newReader := func() io.Reader {
+		return io.NewSectionReader(spool, 0, size)
+	}
+
+	switch ss.policy.Mode {
+	case ReplicationPrimaryMirror:
+		err = ss.storeTo(ctx, targets[0], cid, newReader())
+		if err != nil {
+			closeSpool(spool)
+			for _, back := range targets[1:] {
+				ss.addRepair(back, cid)
+			}
+			return nil, err
+		}
+		go func() {
+			defer closeSpool(spool)
+			for _, back := range targets[1:] {
+				// the request context may be done before mirroring completes
+				_ = ss.storeTo(context.Background(), back, cid, newReader())
+			}
+		}()
+		return nil, nil
+	case ReplicationWriteQuorum:
+		defer closeSpool(spool)
+		quorum := ss.policy.Quorum
+		succeeded := 0
+		for _, back := range targets {
+			if ss.storeTo(ctx, back, cid, newReader()) == nil {
+				succeeded++
+			}
+		}
+		if succeeded < quorum {
+			return nil, types.Wrapf(types.ErrStoreFailed, "cid=%v stored in %d backends, quorum is %d", cid, succeeded, quorum)
+		}
+		return nil, nil
+	default:
+		defer closeSpool(spool)
+		for _, back := range targets {
+			if e := ss.storeTo(ctx, back, cid, newReader()); e != nil {
+				err = e
+			}
+		}
+		if err == nil && len(targets) < len(backends) {
+			err = types.Wrapf(types.ErrStoreFailed, "cid=%v stored in %d of %d backends", cid, len(targets), len(backends))
 		}
+		return nil, err
 	}
-	return nil, err
 }
 
 func (ss *StoreManager) Remove(ctx context.Context, cid cid.Cid) error {
 	var err error
-	for _, back := range ss.backends {
-		err = back.Remove(ctx, cid)
-		if err != nil {
-			log.Errorf("%s remove cid=%v error: %v", back.Id(), cid, err)
-		} else {
-			err = nil
+	for _, back := range ss.getBackends() {
+		ss.removeRepair(back, cid)
+		e := back.Remove(ctx, cid)
+		if e != nil {
+			// a backend which doesn't hold the cid has nothing to remove
+			if types.ErrDataMissing.Is(e) {
+				continue
+			}
+			log.Errorf("%s remove cid=%v error: %v", back.Id(), cid, e)
+			err = e
 		}
 	}
 	return err
 }
 
+/**
+ * healthy backends are tried first, unhealthy ones are only the last resort.
+ */
 func (ss *StoreManager) Get(ctx context.Context, cid cid.Cid) (io.Reader, error) {
-	for _, back := range ss.backends {
+	var missing []*backendState
+	for _, back := range ss.orderedBackends() {
 		reader, err := back.Get(ctx, cid)
 		if err != nil {
 			log.Errorf("%s get cid=%v error: %v", back.Id(), cid, err)
+			if types.ErrDataMissing.Is(err) {
+				missing = append(missing, back)
+			} else {
+				ss.recordFailure(back, err)
+			}
 			continue
 		}
+		ss.recordSuccess(back)
+		// the content diverged, copy it to the backends missing it
+		for _, m := range missing {
+			ss.addRepair(m, cid)
+		}
 		return reader, nil
 	}
 	return nil, types.Wrapf(types.ErrGetFailed, "failed to get cid %s", cid)
 }
 
 func (ss *StoreManager) IsExist(ctx context.Context, cid cid.Cid) bool {
-	for _, back := range ss.backends {
+	for _, back := range ss.orderedBackends() {
 		isExist, err := back.IsExist(ctx, cid)
 		if err != nil {
 			log.Errorf("%s get cid=%v error: %v", back.Id(), cid, err)
@@ -122,3 +307,265 @@ func (ss *StoreManager) IsExist(ctx context.Context, cid cid.Cid) bool {
 
 	return false
 }
+
+/**
+ * sum of the capacity of all backends which report it, backends share the same filesystem are counted repeatedly.
+ */
+func (ss *StoreManager) Capacity() (uint64, uint64, error) {
+	var total, free uint64
+	for _, back := range ss.getBackends() {
+		if r, ok := back.StoreBackend.(CapacityReporter); ok {
+			t, f, err := r.Capacity()
+			if err != nil {
+				log.Errorf("%s capacity error: %v", back.Id(), err)
+				return 0, 0, err
+			}
+			total += t
+			free += f
+		}
+	}
+	return total, free, nil
+}
+
+/**
+ * used bytes and capacity of each backend, errors are logged and the value is reported as unknown.
+ */
+func (ss *StoreManager) Usage() []BackendUsage {
+	var res []BackendUsage
+	for _, back := range ss.getBackends() {
+		usage := BackendUsage{Id: back.Id()}
+		if r, ok := back.StoreBackend.(UsageReporter); ok {
+			used, err := r.Used()
+			if err != nil {
+				log.Errorf("%s usage error: %v", back.Id(), err)
+			} else {
+				usage.UsedKnown = true
+				usage.Used = used
+			}
+		}
+		if r, ok := back.StoreBackend.(CapacityReporter); ok {
+			total, free, err := r.Capacity()
+			if err != nil {
+				log.Errorf("%s capacity error: %v", back.Id(), err)
+			} else {
+				usage.CapacityKnown = true
+				usage.Total = total
+				usage.Free = free
+			}
+		}
+		res = append(res, usage)
+	}
+	return res
+}
+
+/**
+ * repair the given cids and the ones failed to be written before, periodically.
+ * listCids returns the cids this node should keep.
+ */
+func (ss *StoreManager) StartRepair(ctx context.Context, interval time.Duration, listCids func(ctx context.Context) ([]cid.Cid, error)) {
+	if interval <= 0 {
+		log.Info("store repair disabled")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				cids, err := listCids(ctx)
+				if err != nil {
+					log.Errorf("list cids to repair error: %v", err)
+				}
+				repaired := ss.Repair(ctx, cids)
+				if repaired > 0 {
+					log.Infof("repaired %d cids", repaired)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+/**
+ * copy cids missing from some healthy backends from a backend which has them, returns the number of copies made.
+ */
+func (ss *StoreManager) Repair(ctx context.Context, cids []cid.Cid) int {
+	backends := ss.getBackends()
+	if len(backends) < 2 {
+		return 0
+	}
+
+	toRepair := make(map[cid.Cid]struct{})
+	for _, c := range cids {
+		toRepair[c] = struct{}{}
+	}
+	ss.lk.RLock()
+	for _, pending := range ss.repairs {
+		for c := range pending {
+			toRepair[c] = struct{}{}
+		}
+	}
+	ss.lk.RUnlock()
+
+	repaired := 0
+	for c := range toRepair {
+		if ctx.Err() != nil {
+			return repaired
+		}
+
+		var source *backendState
+		var missing []*backendState
+		for _, back := range backends {
+			if !ss.isHealthy(back) {
+				continue
+			}
+			existCtx, cancel := context.WithTimeout(ctx, EXIST_CHECK_TIMEOUT)
+			exists, err := back.IsExist(existCtx, c)
+			cancel()
+			if err == nil && exists {
+				if source == nil {
+					source = back
+				}
+			} else {
+				missing = append(missing, back)
+			}
+		}
+		if source == nil {
+			log.Warnf("cid=%v not found in any healthy backend", c)
+			continue
+		}
+
+		for _, back := range missing {
+			reader, err := source.Get(ctx, c)
+			if err != nil {
+				log.Errorf("repair %s get cid=%v error: %v", source.Id(), c, err)
+				break
+			}
+			err = ss.storeTo(ctx, back, c, reader)
+			if r, ok := reader.(io.Closer); ok {
+				r.Close()
+			}
+			if err == nil {
+				log.Infof("repaired cid=%v from %s to %s", c, source.Id(), back.Id())
+				repaired++
+			}
+		}
+		// cids remembered for unhealthy backends are kept till they're back
+		for _, back := range backends {
+			if ss.isHealthy(back) {
+				ss.removeRepair(back, c)
+			}
+		}
+		for _, back := range missing {
+			if exists, _ := back.IsExist(ctx, c); !exists {
+				ss.addRepair(back, c)
+			}
+		}
+	}
+	return repaired
+}
+
+func (ss *StoreManager) storeTo(ctx context.Context, back *backendState, cid cid.Cid, reader io.Reader) error {
+	counting := &metrics.CountingReader{Reader: reader}
+	_, err := back.Store(ctx, counting)
+	if err != nil {
+		log.Errorf("%s store cid=%v error: %v", back.Id(), cid, err)
+		ss.recordFailure(back, err)
+		ss.addRepair(back, cid)
+		return err
+	}
+	metrics.StoredBytes.WithLabelValues(back.Id(), back.Type()).Add(float64(counting.N))
+	ss.recordSuccess(back)
+	ss.removeRepair(back, cid)
+	return nil
+}
+
+func (ss *StoreManager) getBackends() []*backendState {
+	ss.lk.RLock()
+	defer ss.lk.RUnlock()
+
+	return append([]*backendState{}, ss.backends...)
+}
+
+func (ss *StoreManager) orderedBackends() []*backendState {
+	var healthy, unhealthy []*backendState
+	for _, back := range ss.getBackends() {
+		if ss.isHealthy(back) {
+			healthy = append(healthy, back)
+		} else {
+			unhealthy = append(unhealthy, back)
+		}
+	}
+	return append(healthy, unhealthy...)
+}
+
+/**
+ * a backend is unhealthy after FailureThreshold consecutive failures, and it's tried again after CircuitBreakPeriod.
+ */
+func (ss *StoreManager) isHealthy(back *backendState) bool {
+	ss.lk.RLock()
+	defer ss.lk.RUnlock()
+
+	return back.failures < ss.policy.FailureThreshold || time.Now().After(back.brokenUntil)
+}
+
+func (ss *StoreManager) recordSuccess(back *backendState) {
+	ss.lk.Lock()
+	defer ss.lk.Unlock()
+
+	if back.failures >= ss.policy.FailureThreshold {
+		log.Infof("%s is healthy again", back.Id())
+	}
+	back.failures = 0
+}
+
+func (ss *StoreManager) recordFailure(back *backendState, err error) {
+	ss.lk.Lock()
+	defer ss.lk.Unlock()
+
+	back.failures++
+	back.lastErr = err.Error()
+	back.lastFailure = time.Now()
+	if back.failures >= ss.policy.FailureThreshold {
+		back.brokenUntil = back.lastFailure.Add(ss.policy.CircuitBreakPeriod)
+		log.Errorf("%s failed %d times, skipped until %v: %v", back.Id(), back.failures, back.brokenUntil, err)
+	}
+}
+
+func (ss *StoreManager) addRepair(back *backendState, c cid.Cid) {
+	ss.lk.Lock()
+	defer ss.lk.Unlock()
+
+	if ss.repairs[back.Id()] == nil {
+		ss.repairs[back.Id()] = make(map[cid.Cid]struct{})
+	}
+	ss.repairs[back.Id()][c] = struct{}{}
+}
+
+func (ss *StoreManager) removeRepair(back *backendState, c cid.Cid) {
+	ss.lk.Lock()
+	defer ss.lk.Unlock()
+
+	delete(ss.repairs[back.Id()], c)
+}
+
+func spoolContent(reader io.Reader) (*os.File, int64, error) {
+	spool, err := os.CreateTemp("", "sao-store-")
+	if err != nil {
+		return nil, 0, types.Wrap(types.ErrStoreFailed, err)
+	}
+	size, err := io.Copy(spool, reader)
+	if err != nil {
+		closeSpool(spool)
+		return nil, 0, types.Wrap(types.ErrStoreFailed, xerrors.Errorf("spool content: %w", err))
+	}
+	return spool, size, nil
+}
+
+func closeSpool(spool *os.File) {
+	spool.Close()
+	os.Remove(spool.Name())
+}
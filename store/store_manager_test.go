@@ -0,0 +1,149 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/SaoNetwork/sao-node/types"
+	"github.com/SaoNetwork/sao-node/utils"
+
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyBackend fails every operation while broken is set.
+type flakyBackend struct {
+	*LocalBackend
+	broken bool
+}
+
+func (b *flakyBackend) Id() string {
+	return "flaky-" + b.LocalBackend.Id()
+}
+
+func (b *flakyBackend) Store(ctx context.Context, reader io.Reader) (any, error) {
+	if b.broken {
+		return nil, types.Wrapf(types.ErrStoreFailed, "broken")
+	}
+	return b.LocalBackend.Store(ctx, reader)
+}
+
+func (b *flakyBackend) Get(ctx context.Context, cid cid.Cid) (io.Reader, error) {
+	if b.broken {
+		return nil, types.Wrapf(types.ErrGetFailed, "broken")
+	}
+	return b.LocalBackend.Get(ctx, cid)
+}
+
+func newTestLocalBackend(t *testing.T) *LocalBackend {
+	b, err := NewLocalBackend(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, b.Open())
+	return b
+}
+
+func TestStoreManagerReplication(t *testing.T) {
+	ctx := context.Background()
+	data := []byte("sao network store manager")
+	c, err := utils.CalculateCid(data)
+	require.NoError(t, err)
+
+	healthy := newTestLocalBackend(t)
+	flaky := &flakyBackend{LocalBackend: newTestLocalBackend(t), broken: true}
+
+	// write-all fails if any backend fails
+	sm := NewStoreManager([]StoreBackend{healthy, flaky}, ReplicationPolicy{Mode: ReplicationWriteAll})
+	_, err = sm.Store(ctx, c, bytes.NewReader(data))
+	require.Error(t, err)
+
+	// the content is written to the healthy backend anyway
+	exists, err := healthy.IsExist(ctx, c)
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	// quorum of 1 is fine with one broken backend
+	sm = NewStoreManager([]StoreBackend{healthy, flaky}, ReplicationPolicy{Mode: ReplicationWriteQuorum, Quorum: 1})
+	_, err = sm.Store(ctx, c, bytes.NewReader(data))
+	require.NoError(t, err)
+	_, err = NewStoreManager([]StoreBackend{healthy, flaky}, ReplicationPolicy{Mode: ReplicationWriteQuorum, Quorum: 2}).Store(ctx, c, bytes.NewReader(data))
+	require.Error(t, err)
+
+	// the quorum can't be met by the backends
+	require.Error(t, NewStoreManager([]StoreBackend{healthy, flaky}, ReplicationPolicy{Mode: ReplicationWriteQuorum}).Validate())
+	require.Error(t, NewStoreManager([]StoreBackend{healthy, flaky}, ReplicationPolicy{Mode: ReplicationWriteQuorum, Quorum: 3}).Validate())
+	require.Error(t, NewStoreManager([]StoreBackend{healthy, flaky}, ReplicationPolicy{Mode: "write-some"}).Validate())
+	require.NoError(t, NewStoreManager([]StoreBackend{healthy, flaky}, ReplicationPolicy{}).Validate())
+
+	// primary-mirror only waits for the first backend
+	sm = NewStoreManager([]StoreBackend{healthy, flaky}, ReplicationPolicy{Mode: ReplicationPrimaryMirror})
+	_, err = sm.Store(ctx, c, bytes.NewReader(data))
+	require.NoError(t, err)
+}
+
+func TestStoreManagerHealthAndRepair(t *testing.T) {
+	ctx := context.Background()
+	data := []byte("sao network store manager repair")
+	c, err := utils.CalculateCid(data)
+	require.NoError(t, err)
+
+	healthy := newTestLocalBackend(t)
+	flaky := &flakyBackend{LocalBackend: newTestLocalBackend(t), broken: true}
+	sm := NewStoreManager([]StoreBackend{flaky, healthy}, ReplicationPolicy{
+		Mode:               ReplicationWriteQuorum,
+		Quorum:             1,
+		FailureThreshold:   2,
+		CircuitBreakPeriod: time.Hour,
+	})
+
+	for i := 0; i < 2; i++ {
+		_, err = sm.Store(ctx, c, bytes.NewReader(data))
+		require.NoError(t, err)
+	}
+
+	require.False(t, sm.isHealthy(sm.backends[0]))
+	require.Equal(t, 2, sm.backends[0].failures)
+	require.Len(t, sm.repairs[flaky.Id()], 1)
+	require.True(t, sm.isHealthy(sm.backends[1]))
+
+	// the broken backend is skipped, reads go to the healthy one
+	reader, err := sm.Get(ctx, c)
+	require.NoError(t, err)
+	content, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	require.Equal(t, data, content)
+
+	// nothing is repaired while the backend is broken
+	require.Equal(t, 0, sm.Repair(ctx, nil))
+
+	// back online
+	flaky.broken = false
+	sm.policy.CircuitBreakPeriod = 0
+	for _, back := range sm.backends {
+		back.brokenUntil = time.Now()
+	}
+	require.Equal(t, 1, sm.Repair(ctx, nil))
+	exists, err := flaky.IsExist(ctx, c)
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	require.True(t, sm.isHealthy(sm.backends[0]))
+	require.Empty(t, sm.repairs[flaky.Id()])
+
+	// cids removed from one backend out of band are repaired as well
+	require.NoError(t, healthy.Remove(ctx, c))
+	require.Equal(t, 1, sm.Repair(ctx, []cid.Cid{c}))
+	exists, err = healthy.IsExist(ctx, c)
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	// a backend missing the cid doesn't fail the removal
+	require.NoError(t, healthy.Remove(ctx, c))
+	require.NoError(t, sm.Remove(ctx, c))
+	require.NoError(t, sm.Remove(ctx, c))
+	exists, err = flaky.IsExist(ctx, c)
+	require.NoError(t, err)
+	require.False(t, exists)
+}
@@ -0,0 +1,142 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/SaoNetwork/sao-node/node/config"
+	"github.com/SaoNetwork/sao-node/types"
+
+	logging "github.com/ipfs/go-log/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var log = logging.Logger("tracing")
+
+const (
+	serviceName         = "saonode"
+	instrumentationName = "github.com/SaoNetwork/sao-node"
+)
+
+var (
+	OrderIdKey  = attribute.Key("sao.order.id")
+	DataIdKey   = attribute.Key("sao.data.id")
+	CidKey      = attribute.Key("sao.cid")
+	ProviderKey = attribute.Key("sao.provider")
+)
+
+// trace context is propagated even if this node doesn't export spans, so that traces of other nodes stay connected
+var propagator = propagation.TraceContext{}
+
+/**
+ * export spans of this node to the OTLP collector, the returned function flushes and stops the exporter.
+ * the tracer provider is global, nodes sharing a process share it as well.
+ */
+func Setup(ctx context.Context, cfg *config.Tracing, nodeAddress string) (func(context.Context) error, error) {
+	if !cfg.Enable {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Endpoint)}
+	if cfg.Insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, types.Wrap(types.ErrStartTracingFailed, err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceNameKey.String(serviceName),
+			attribute.String("sao.node.address", nodeAddress),
+		)),
+	)
+	otel.SetTracerProvider(provider)
+	log.Infof("exporting spans to %s, sample ratio %v", cfg.Endpoint, cfg.SampleRatio)
+
+	return provider.Shutdown, nil
+}
+
+func StartSpan(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, opts...)
+}
+
+/**
+ * record the error on the span unless it's nil.
+ */
+func RecordError(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
+func Inject(ctx context.Context, tc *types.TraceContext) {
+	propagator.Inject(ctx, tc)
+}
+
+func Extract(ctx context.Context, tc types.TraceContext) context.Context {
+	return propagator.Extract(ctx, &tc)
+}
+
+/**
+ * start a client span of a protocol request, the request carries the span context to the peer.
+ */
+func StartRequestSpan(ctx context.Context, name string, tc *types.TraceContext, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	ctx, span := StartSpan(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+	Inject(ctx, tc)
+	return ctx, span
+}
+
+/**
+ * start a server span joining the trace carried by the request, the request then carries the server span context
+ * so that work scheduled by the handler joins the trace as well.
+ */
+func StartHandlerSpan(ctx context.Context, name string, tc *types.TraceContext, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	ctx, span := StartSpan(Extract(ctx, *tc), name, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(attrs...))
+	Inject(ctx, tc)
+	return ctx, span
+}
+
+/**
+ * end the span of a protocol request with the response code.
+ */
+func EndResponse(span trace.Span, code uint64, message string) {
+	span.SetAttributes(attribute.Int64("sao.response.code", int64(code)))
+	if code != 0 {
+		span.SetStatus(codes.Error, message)
+	}
+	span.End()
+}
+
+func InjectHeader(ctx context.Context, header http.Header) {
+	propagator.Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+/**
+ * start a server span for each http request, joining the trace of the caller from the request headers.
+ */
+func HttpHandler(name string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := StartSpan(ctx, name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(semconv.HTTPMethodKey.String(r.Method), semconv.HTTPTargetKey.String(r.URL.Path)),
+		)
+		defer span.End()
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,69 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SaoNetwork/sao-node/types"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestProtocolPropagation(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	req := types.ShardAssignReq{OrderId: 1, DataId: "data"}
+	ctx, clientSpan := StartRequestSpan(context.Background(), "RequestShardAssign", &req.TraceContext)
+	require.NotEmpty(t, req.TraceContext.TraceParent)
+
+	// the trace context survives the wire format
+	var buf bytes.Buffer
+	require.NoError(t, req.Marshal(&buf, types.FormatCbor))
+	var received types.ShardAssignReq
+	require.NoError(t, received.Unmarshal(&buf, types.FormatCbor))
+	require.Equal(t, req.TraceContext, received.TraceContext)
+
+	_, serverSpan := StartHandlerSpan(context.Background(), "HandleShardAssign", &received.TraceContext)
+	EndResponse(serverSpan, 0, "")
+	clientSpan.End()
+
+	// work scheduled by the handler continues the server span
+	_, taskSpan := StartSpan(Extract(context.Background(), received.TraceContext), "StoreSvc.process")
+	taskSpan.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	traceId := trace.SpanContextFromContext(ctx).TraceID()
+	for _, span := range spans {
+		require.Equal(t, traceId, span.SpanContext().TraceID())
+	}
+	require.Equal(t, clientSpan.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	require.Equal(t, serverSpan.SpanContext().SpanID(), spans[2].Parent().SpanID())
+}
+
+func TestHttpHandler(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	var handled trace.SpanContext
+	handler := HttpHandler("Sao.rpc", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handled = trace.SpanContextFromContext(r.Context())
+	}))
+
+	ctx, clientSpan := StartSpan(context.Background(), "client")
+	r := httptest.NewRequest(http.MethodPost, "/rpc/v0", nil)
+	InjectHeader(ctx, r.Header)
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+	clientSpan.End()
+
+	require.Equal(t, clientSpan.SpanContext().TraceID(), handled.TraceID())
+	require.NotEqual(t, clientSpan.SpanContext().SpanID(), handled.SpanID())
+}
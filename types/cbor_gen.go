@@ -864,7 +864,7 @@ func (t *OrderInfo) MarshalCBOR(w io.Writer) error {
 
 	cw := cbg.NewCborWriter(w)
 
-	if _, err := cw.Write([]byte{174}); err != nil {
+	if _, err := cw.Write([]byte{178}); err != nil {
 		return err
 	}
 
@@ -930,6 +930,29 @@ func (t *OrderInfo) MarshalCBOR(w io.Writer) error {
 		return xerrors.Errorf("failed to write cid field t.Cid: %w", err)
 	}
 
+	// t.GroupId (string) (string)
+	if len("GroupId") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"GroupId\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("GroupId"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("GroupId")); err != nil {
+		return err
+	}
+
+	if len(t.GroupId) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.GroupId was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.GroupId))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.GroupId)); err != nil {
+		return err
+	}
+
 	// t.StagePath (string) (string)
 	if len("StagePath") > cbg.MaxLength {
 		return xerrors.Errorf("Value in field \"StagePath\" was too long")
@@ -1176,6 +1199,67 @@ func (t *OrderInfo) MarshalCBOR(w io.Writer) error {
 	if _, err := io.WriteString(w, string(t.LastErr)); err != nil {
 		return err
 	}
+
+	// t.RetryPolicy (string) (string)
+	if len("RetryPolicy") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"RetryPolicy\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("RetryPolicy"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("RetryPolicy")); err != nil {
+		return err
+	}
+
+	if len(t.RetryPolicy) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.RetryPolicy was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.RetryPolicy))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.RetryPolicy)); err != nil {
+		return err
+	}
+
+	// t.QueuePosition (int64) (int64)
+	if len("QueuePosition") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"QueuePosition\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("QueuePosition"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("QueuePosition")); err != nil {
+		return err
+	}
+
+	if t.QueuePosition >= 0 {
+		if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.QueuePosition)); err != nil {
+			return err
+		}
+	} else {
+		if err := cw.WriteMajorTypeHeader(cbg.MajNegativeInt, uint64(-t.QueuePosition-1)); err != nil {
+			return err
+		}
+	}
+
+	// t.TraceContext (types.TraceContext) (struct)
+	if len("TraceContext") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"TraceContext\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("TraceContext"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("TraceContext")); err != nil {
+		return err
+	}
+
+	if err := t.TraceContext.MarshalCBOR(cw); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -1252,6 +1336,17 @@ func (t *OrderInfo) UnmarshalCBOR(r io.Reader) (err error) {
 				t.Cid = c
 
 			}
+			// t.GroupId (string) (string)
+		case "GroupId":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.GroupId = string(sval)
+			}
 			// t.StagePath (string) (string)
 		case "StagePath":
 
@@ -1450,127 +1545,52 @@ func (t *OrderInfo) UnmarshalCBOR(r io.Reader) (err error) {
 
 				t.LastErr = string(sval)
 			}
+			// t.RetryPolicy (string) (string)
+		case "RetryPolicy":
 
-		default:
-			// Field doesn't exist on this type, so ignore it
-			cbg.ScanForLinks(r, func(cid.Cid) {})
-		}
-	}
-
-	return nil
-}
-func (t *ShardKey) MarshalCBOR(w io.Writer) error {
-	if t == nil {
-		_, err := w.Write(cbg.CborNull)
-		return err
-	}
-
-	cw := cbg.NewCborWriter(w)
-
-	if _, err := cw.Write([]byte{162}); err != nil {
-		return err
-	}
-
-	// t.OrderId (uint64) (uint64)
-	if len("OrderId") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"OrderId\" was too long")
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("OrderId"))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string("OrderId")); err != nil {
-		return err
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.OrderId)); err != nil {
-		return err
-	}
-
-	// t.Cid (cid.Cid) (struct)
-	if len("Cid") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"Cid\" was too long")
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Cid"))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string("Cid")); err != nil {
-		return err
-	}
-
-	if err := cbg.WriteCid(cw, t.Cid); err != nil {
-		return xerrors.Errorf("failed to write cid field t.Cid: %w", err)
-	}
-
-	return nil
-}
-
-func (t *ShardKey) UnmarshalCBOR(r io.Reader) (err error) {
-	*t = ShardKey{}
-
-	cr := cbg.NewCborReader(r)
-
-	maj, extra, err := cr.ReadHeader()
-	if err != nil {
-		return err
-	}
-	defer func() {
-		if err == io.EOF {
-			err = io.ErrUnexpectedEOF
-		}
-	}()
-
-	if maj != cbg.MajMap {
-		return fmt.Errorf("cbor input should be of type map")
-	}
-
-	if extra > cbg.MaxLength {
-		return fmt.Errorf("ShardKey: map struct too large (%d)", extra)
-	}
-
-	var name string
-	n := extra
-
-	for i := uint64(0); i < n; i++ {
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
 
-		{
-			sval, err := cbg.ReadString(cr)
-			if err != nil {
-				return err
+				t.RetryPolicy = string(sval)
 			}
-
-			name = string(sval)
-		}
-
-		switch name {
-		// t.OrderId (uint64) (uint64)
-		case "OrderId":
-
+			// t.QueuePosition (int64) (int64)
+		case "QueuePosition":
 			{
-
-				maj, extra, err = cr.ReadHeader()
+				maj, extra, err := cr.ReadHeader()
+				var extraI int64
 				if err != nil {
 					return err
 				}
-				if maj != cbg.MajUnsignedInt {
-					return fmt.Errorf("wrong type for uint64 field")
+				switch maj {
+				case cbg.MajUnsignedInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 positive overflow")
+					}
+				case cbg.MajNegativeInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 negative oveflow")
+					}
+					extraI = -1 - extraI
+				default:
+					return fmt.Errorf("wrong type for int64 field: %d", maj)
 				}
-				t.OrderId = uint64(extra)
 
+				t.QueuePosition = int64(extraI)
 			}
-			// t.Cid (cid.Cid) (struct)
-		case "Cid":
+			// t.TraceContext (types.TraceContext) (struct)
+		case "TraceContext":
 
 			{
 
-				c, err := cbg.ReadCid(cr)
-				if err != nil {
-					return xerrors.Errorf("failed to read cid field t.Cid: %w", err)
+				if err := t.TraceContext.UnmarshalCBOR(cr); err != nil {
+					return xerrors.Errorf("unmarshaling t.TraceContext: %w", err)
 				}
 
-				t.Cid = c
-
 			}
 
 		default:
@@ -1581,7 +1601,7 @@ func (t *ShardKey) UnmarshalCBOR(r io.Reader) (err error) {
 
 	return nil
 }
-func (t *ShardInfo) MarshalCBOR(w io.Writer) error {
+func (t *OrderEvent) MarshalCBOR(w io.Writer) error {
 	if t == nil {
 		_, err := w.Write(cbg.CborNull)
 		return err
@@ -1589,7 +1609,7 @@ func (t *ShardInfo) MarshalCBOR(w io.Writer) error {
 
 	cw := cbg.NewCborWriter(w)
 
-	if _, err := cw.Write([]byte{175}); err != nil {
+	if _, err := cw.Write([]byte{168}); err != nil {
 		return err
 	}
 
@@ -1609,295 +1629,163 @@ func (t *ShardInfo) MarshalCBOR(w io.Writer) error {
 		return err
 	}
 
-	// t.DataId (string) (string)
-	if len("DataId") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"DataId\" was too long")
+	// t.Kind (string) (string)
+	if len("Kind") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Kind\" was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("DataId"))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Kind"))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string("DataId")); err != nil {
+	if _, err := io.WriteString(w, string("Kind")); err != nil {
 		return err
 	}
 
-	if len(t.DataId) > cbg.MaxLength {
-		return xerrors.Errorf("Value in field t.DataId was too long")
+	if len(t.Kind) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.Kind was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.DataId))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.Kind))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string(t.DataId)); err != nil {
+	if _, err := io.WriteString(w, string(t.Kind)); err != nil {
 		return err
 	}
 
-	// t.Cid (cid.Cid) (struct)
-	if len("Cid") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"Cid\" was too long")
+	// t.Subject (string) (string)
+	if len("Subject") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Subject\" was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Cid"))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Subject"))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string("Cid")); err != nil {
+	if _, err := io.WriteString(w, string("Subject")); err != nil {
 		return err
 	}
 
-	if err := cbg.WriteCid(cw, t.Cid); err != nil {
-		return xerrors.Errorf("failed to write cid field t.Cid: %w", err)
-	}
-
-	// t.Owner (string) (string)
-	if len("Owner") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"Owner\" was too long")
+	if len(t.Subject) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.Subject was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Owner"))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.Subject))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string("Owner")); err != nil {
+	if _, err := io.WriteString(w, string(t.Subject)); err != nil {
 		return err
 	}
 
-	if len(t.Owner) > cbg.MaxLength {
-		return xerrors.Errorf("Value in field t.Owner was too long")
+	// t.From (string) (string)
+	if len("From") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"From\" was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.Owner))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("From"))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string(t.Owner)); err != nil {
+	if _, err := io.WriteString(w, string("From")); err != nil {
 		return err
 	}
 
-	// t.Gateway (string) (string)
-	if len("Gateway") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"Gateway\" was too long")
+	if len(t.From) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.From was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Gateway"))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.From))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string("Gateway")); err != nil {
+	if _, err := io.WriteString(w, string(t.From)); err != nil {
 		return err
 	}
 
-	if len(t.Gateway) > cbg.MaxLength {
-		return xerrors.Errorf("Value in field t.Gateway was too long")
+	// t.To (string) (string)
+	if len("To") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"To\" was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.Gateway))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("To"))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string(t.Gateway)); err != nil {
+	if _, err := io.WriteString(w, string("To")); err != nil {
 		return err
 	}
 
-	// t.OrderOperation (string) (string)
-	if len("OrderOperation") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"OrderOperation\" was too long")
+	if len(t.To) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.To was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("OrderOperation"))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.To))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string("OrderOperation")); err != nil {
+	if _, err := io.WriteString(w, string(t.To)); err != nil {
 		return err
 	}
 
-	if len(t.OrderOperation) > cbg.MaxLength {
-		return xerrors.Errorf("Value in field t.OrderOperation was too long")
+	// t.Tries (uint64) (uint64)
+	if len("Tries") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Tries\" was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.OrderOperation))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Tries"))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string(t.OrderOperation)); err != nil {
+	if _, err := io.WriteString(w, string("Tries")); err != nil {
 		return err
 	}
 
-	// t.ShardOperation (string) (string)
-	if len("ShardOperation") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"ShardOperation\" was too long")
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.Tries)); err != nil {
+		return err
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("ShardOperation"))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string("ShardOperation")); err != nil {
-		return err
-	}
-
-	if len(t.ShardOperation) > cbg.MaxLength {
-		return xerrors.Errorf("Value in field t.ShardOperation was too long")
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.ShardOperation))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string(t.ShardOperation)); err != nil {
-		return err
-	}
-
-	// t.CompleteHash (string) (string)
-	if len("CompleteHash") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"CompleteHash\" was too long")
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("CompleteHash"))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string("CompleteHash")); err != nil {
-		return err
-	}
-
-	if len(t.CompleteHash) > cbg.MaxLength {
-		return xerrors.Errorf("Value in field t.CompleteHash was too long")
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.CompleteHash))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string(t.CompleteHash)); err != nil {
-		return err
-	}
-
-	// t.CompleteHeight (int64) (int64)
-	if len("CompleteHeight") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"CompleteHeight\" was too long")
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("CompleteHeight"))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string("CompleteHeight")); err != nil {
-		return err
-	}
-
-	if t.CompleteHeight >= 0 {
-		if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.CompleteHeight)); err != nil {
-			return err
-		}
-	} else {
-		if err := cw.WriteMajorTypeHeader(cbg.MajNegativeInt, uint64(-t.CompleteHeight-1)); err != nil {
-			return err
-		}
-	}
-
-	// t.Size (uint64) (uint64)
-	if len("Size") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"Size\" was too long")
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Size"))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string("Size")); err != nil {
-		return err
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.Size)); err != nil {
-		return err
-	}
-
-	// t.Tries (uint64) (uint64)
-	if len("Tries") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"Tries\" was too long")
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Tries"))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string("Tries")); err != nil {
-		return err
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.Tries)); err != nil {
-		return err
-	}
-
-	// t.ExpireHeight (uint64) (uint64)
-	if len("ExpireHeight") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"ExpireHeight\" was too long")
+	// t.Message (string) (string)
+	if len("Message") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Message\" was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("ExpireHeight"))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string("ExpireHeight")); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Message"))); err != nil {
 		return err
 	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.ExpireHeight)); err != nil {
+	if _, err := io.WriteString(w, string("Message")); err != nil {
 		return err
 	}
 
-	// t.State (types.ShardState) (uint64)
-	if len("State") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"State\" was too long")
+	if len(t.Message) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.Message was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("State"))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string("State")); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.Message))); err != nil {
 		return err
 	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.State)); err != nil {
+	if _, err := io.WriteString(w, string(t.Message)); err != nil {
 		return err
 	}
 
-	// t.RetryAt (int64) (int64)
-	if len("RetryAt") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"RetryAt\" was too long")
+	// t.Time (int64) (int64)
+	if len("Time") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Time\" was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("RetryAt"))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Time"))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string("RetryAt")); err != nil {
+	if _, err := io.WriteString(w, string("Time")); err != nil {
 		return err
 	}
 
-	if t.RetryAt >= 0 {
-		if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.RetryAt)); err != nil {
+	if t.Time >= 0 {
+		if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.Time)); err != nil {
 			return err
 		}
 	} else {
-		if err := cw.WriteMajorTypeHeader(cbg.MajNegativeInt, uint64(-t.RetryAt-1)); err != nil {
+		if err := cw.WriteMajorTypeHeader(cbg.MajNegativeInt, uint64(-t.Time-1)); err != nil {
 			return err
 		}
 	}
-
-	// t.LastErr (string) (string)
-	if len("LastErr") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"LastErr\" was too long")
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("LastErr"))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string("LastErr")); err != nil {
-		return err
-	}
-
-	if len(t.LastErr) > cbg.MaxLength {
-		return xerrors.Errorf("Value in field t.LastErr was too long")
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.LastErr))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string(t.LastErr)); err != nil {
-		return err
-	}
 	return nil
 }
 
-func (t *ShardInfo) UnmarshalCBOR(r io.Reader) (err error) {
-	*t = ShardInfo{}
+func (t *OrderEvent) UnmarshalCBOR(r io.Reader) (err error) {
+	*t = OrderEvent{}
 
 	cr := cbg.NewCborReader(r)
 
@@ -1916,7 +1804,7 @@ func (t *ShardInfo) UnmarshalCBOR(r io.Reader) (err error) {
 	}
 
 	if extra > cbg.MaxLength {
-		return fmt.Errorf("ShardInfo: map struct too large (%d)", extra)
+		return fmt.Errorf("OrderEvent: map struct too large (%d)", extra)
 	}
 
 	var name string
@@ -1949,8 +1837,8 @@ func (t *ShardInfo) UnmarshalCBOR(r io.Reader) (err error) {
 				t.OrderId = uint64(extra)
 
 			}
-			// t.DataId (string) (string)
-		case "DataId":
+			// t.Kind (string) (string)
+		case "Kind":
 
 			{
 				sval, err := cbg.ReadString(cr)
@@ -1958,23 +1846,10 @@ func (t *ShardInfo) UnmarshalCBOR(r io.Reader) (err error) {
 					return err
 				}
 
-				t.DataId = string(sval)
-			}
-			// t.Cid (cid.Cid) (struct)
-		case "Cid":
-
-			{
-
-				c, err := cbg.ReadCid(cr)
-				if err != nil {
-					return xerrors.Errorf("failed to read cid field t.Cid: %w", err)
-				}
-
-				t.Cid = c
-
+				t.Kind = string(sval)
 			}
-			// t.Owner (string) (string)
-		case "Owner":
+			// t.Subject (string) (string)
+		case "Subject":
 
 			{
 				sval, err := cbg.ReadString(cr)
@@ -1982,10 +1857,10 @@ func (t *ShardInfo) UnmarshalCBOR(r io.Reader) (err error) {
 					return err
 				}
 
-				t.Owner = string(sval)
+				t.Subject = string(sval)
 			}
-			// t.Gateway (string) (string)
-		case "Gateway":
+			// t.From (string) (string)
+		case "From":
 
 			{
 				sval, err := cbg.ReadString(cr)
@@ -1993,10 +1868,10 @@ func (t *ShardInfo) UnmarshalCBOR(r io.Reader) (err error) {
 					return err
 				}
 
-				t.Gateway = string(sval)
+				t.From = string(sval)
 			}
-			// t.OrderOperation (string) (string)
-		case "OrderOperation":
+			// t.To (string) (string)
+		case "To":
 
 			{
 				sval, err := cbg.ReadString(cr)
@@ -2004,21 +1879,25 @@ func (t *ShardInfo) UnmarshalCBOR(r io.Reader) (err error) {
 					return err
 				}
 
-				t.OrderOperation = string(sval)
+				t.To = string(sval)
 			}
-			// t.ShardOperation (string) (string)
-		case "ShardOperation":
+			// t.Tries (uint64) (uint64)
+		case "Tries":
 
 			{
-				sval, err := cbg.ReadString(cr)
+
+				maj, extra, err = cr.ReadHeader()
 				if err != nil {
 					return err
 				}
+				if maj != cbg.MajUnsignedInt {
+					return fmt.Errorf("wrong type for uint64 field")
+				}
+				t.Tries = uint64(extra)
 
-				t.ShardOperation = string(sval)
 			}
-			// t.CompleteHash (string) (string)
-		case "CompleteHash":
+			// t.Message (string) (string)
+		case "Message":
 
 			{
 				sval, err := cbg.ReadString(cr)
@@ -2026,10 +1905,10 @@ func (t *ShardInfo) UnmarshalCBOR(r io.Reader) (err error) {
 					return err
 				}
 
-				t.CompleteHash = string(sval)
+				t.Message = string(sval)
 			}
-			// t.CompleteHeight (int64) (int64)
-		case "CompleteHeight":
+			// t.Time (int64) (int64)
+		case "Time":
 			{
 				maj, extra, err := cr.ReadHeader()
 				var extraI int64
@@ -2052,146 +1931,88 @@ func (t *ShardInfo) UnmarshalCBOR(r io.Reader) (err error) {
 					return fmt.Errorf("wrong type for int64 field: %d", maj)
 				}
 
-				t.CompleteHeight = int64(extraI)
+				t.Time = int64(extraI)
 			}
-			// t.Size (uint64) (uint64)
-		case "Size":
 
-			{
-
-				maj, extra, err = cr.ReadHeader()
-				if err != nil {
-					return err
-				}
-				if maj != cbg.MajUnsignedInt {
-					return fmt.Errorf("wrong type for uint64 field")
-				}
-				t.Size = uint64(extra)
+		default:
+			// Field doesn't exist on this type, so ignore it
+			cbg.ScanForLinks(r, func(cid.Cid) {})
+		}
+	}
 
-			}
-			// t.Tries (uint64) (uint64)
-		case "Tries":
+	return nil
+}
+func (t *OrderHistory) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
 
-			{
+	cw := cbg.NewCborWriter(w)
 
-				maj, extra, err = cr.ReadHeader()
-				if err != nil {
-					return err
-				}
-				if maj != cbg.MajUnsignedInt {
-					return fmt.Errorf("wrong type for uint64 field")
-				}
-				t.Tries = uint64(extra)
+	if _, err := cw.Write([]byte{163}); err != nil {
+		return err
+	}
 
-			}
-			// t.ExpireHeight (uint64) (uint64)
-		case "ExpireHeight":
+	// t.DataId (string) (string)
+	if len("DataId") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"DataId\" was too long")
+	}
 
-			{
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("DataId"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("DataId")); err != nil {
+		return err
+	}
 
-				maj, extra, err = cr.ReadHeader()
-				if err != nil {
-					return err
-				}
-				if maj != cbg.MajUnsignedInt {
-					return fmt.Errorf("wrong type for uint64 field")
-				}
-				t.ExpireHeight = uint64(extra)
-
-			}
-			// t.State (types.ShardState) (uint64)
-		case "State":
-
-			{
-
-				maj, extra, err = cr.ReadHeader()
-				if err != nil {
-					return err
-				}
-				if maj != cbg.MajUnsignedInt {
-					return fmt.Errorf("wrong type for uint64 field")
-				}
-				t.State = ShardState(extra)
-
-			}
-			// t.RetryAt (int64) (int64)
-		case "RetryAt":
-			{
-				maj, extra, err := cr.ReadHeader()
-				var extraI int64
-				if err != nil {
-					return err
-				}
-				switch maj {
-				case cbg.MajUnsignedInt:
-					extraI = int64(extra)
-					if extraI < 0 {
-						return fmt.Errorf("int64 positive overflow")
-					}
-				case cbg.MajNegativeInt:
-					extraI = int64(extra)
-					if extraI < 0 {
-						return fmt.Errorf("int64 negative oveflow")
-					}
-					extraI = -1 - extraI
-				default:
-					return fmt.Errorf("wrong type for int64 field: %d", maj)
-				}
-
-				t.RetryAt = int64(extraI)
-			}
-			// t.LastErr (string) (string)
-		case "LastErr":
-
-			{
-				sval, err := cbg.ReadString(cr)
-				if err != nil {
-					return err
-				}
+	if len(t.DataId) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.DataId was too long")
+	}
 
-				t.LastErr = string(sval)
-			}
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.DataId))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.DataId)); err != nil {
+		return err
+	}
 
-		default:
-			// Field doesn't exist on this type, so ignore it
-			cbg.ScanForLinks(r, func(cid.Cid) {})
-		}
+	// t.OrderId (uint64) (uint64)
+	if len("OrderId") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"OrderId\" was too long")
 	}
 
-	return nil
-}
-func (t *ShardIndex) MarshalCBOR(w io.Writer) error {
-	if t == nil {
-		_, err := w.Write(cbg.CborNull)
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("OrderId"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("OrderId")); err != nil {
 		return err
 	}
 
-	cw := cbg.NewCborWriter(w)
-
-	if _, err := cw.Write([]byte{161}); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.OrderId)); err != nil {
 		return err
 	}
 
-	// t.All ([]types.ShardKey) (slice)
-	if len("All") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"All\" was too long")
+	// t.Events ([]types.OrderEvent) (slice)
+	if len("Events") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Events\" was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("All"))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Events"))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string("All")); err != nil {
+	if _, err := io.WriteString(w, string("Events")); err != nil {
 		return err
 	}
 
-	if len(t.All) > cbg.MaxLength {
-		return xerrors.Errorf("Slice value in field t.All was too long")
+	if len(t.Events) > cbg.MaxLength {
+		return xerrors.Errorf("Slice value in field t.Events was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajArray, uint64(len(t.All))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajArray, uint64(len(t.Events))); err != nil {
 		return err
 	}
-	for _, v := range t.All {
+	for _, v := range t.Events {
 		if err := v.MarshalCBOR(cw); err != nil {
 			return err
 		}
@@ -2199,8 +2020,8 @@ func (t *ShardIndex) MarshalCBOR(w io.Writer) error {
 	return nil
 }
 
-func (t *ShardIndex) UnmarshalCBOR(r io.Reader) (err error) {
-	*t = ShardIndex{}
+func (t *OrderHistory) UnmarshalCBOR(r io.Reader) (err error) {
+	*t = OrderHistory{}
 
 	cr := cbg.NewCborReader(r)
 
@@ -2219,7 +2040,7 @@ func (t *ShardIndex) UnmarshalCBOR(r io.Reader) (err error) {
 	}
 
 	if extra > cbg.MaxLength {
-		return fmt.Errorf("ShardIndex: map struct too large (%d)", extra)
+		return fmt.Errorf("OrderHistory: map struct too large (%d)", extra)
 	}
 
 	var name string
@@ -2237,8 +2058,34 @@ func (t *ShardIndex) UnmarshalCBOR(r io.Reader) (err error) {
 		}
 
 		switch name {
-		// t.All ([]types.ShardKey) (slice)
-		case "All":
+		// t.DataId (string) (string)
+		case "DataId":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.DataId = string(sval)
+			}
+			// t.OrderId (uint64) (uint64)
+		case "OrderId":
+
+			{
+
+				maj, extra, err = cr.ReadHeader()
+				if err != nil {
+					return err
+				}
+				if maj != cbg.MajUnsignedInt {
+					return fmt.Errorf("wrong type for uint64 field")
+				}
+				t.OrderId = uint64(extra)
+
+			}
+			// t.Events ([]types.OrderEvent) (slice)
+		case "Events":
 
 			maj, extra, err = cr.ReadHeader()
 			if err != nil {
@@ -2246,7 +2093,7 @@ func (t *ShardIndex) UnmarshalCBOR(r io.Reader) (err error) {
 			}
 
 			if extra > cbg.MaxLength {
-				return fmt.Errorf("t.All: array too large (%d)", extra)
+				return fmt.Errorf("t.Events: array too large (%d)", extra)
 			}
 
 			if maj != cbg.MajArray {
@@ -2254,17 +2101,17 @@ func (t *ShardIndex) UnmarshalCBOR(r io.Reader) (err error) {
 			}
 
 			if extra > 0 {
-				t.All = make([]ShardKey, extra)
+				t.Events = make([]OrderEvent, extra)
 			}
 
 			for i := 0; i < int(extra); i++ {
 
-				var v ShardKey
+				var v OrderEvent
 				if err := v.UnmarshalCBOR(cr); err != nil {
 					return err
 				}
 
-				t.All[i] = v
+				t.Events[i] = v
 			}
 
 		default:
@@ -2275,7 +2122,7 @@ func (t *ShardIndex) UnmarshalCBOR(r io.Reader) (err error) {
 
 	return nil
 }
-func (t *MigrateKey) MarshalCBOR(w io.Writer) error {
+func (t *ShardKey) MarshalCBOR(w io.Writer) error {
 	if t == nil {
 		_, err := w.Write(cbg.CborNull)
 		return err
@@ -2287,56 +2134,43 @@ func (t *MigrateKey) MarshalCBOR(w io.Writer) error {
 		return err
 	}
 
-	// t.DataId (string) (string)
-	if len("DataId") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"DataId\" was too long")
+	// t.OrderId (uint64) (uint64)
+	if len("OrderId") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"OrderId\" was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("DataId"))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("OrderId"))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string("DataId")); err != nil {
+	if _, err := io.WriteString(w, string("OrderId")); err != nil {
 		return err
 	}
 
-	if len(t.DataId) > cbg.MaxLength {
-		return xerrors.Errorf("Value in field t.DataId was too long")
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.DataId))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string(t.DataId)); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.OrderId)); err != nil {
 		return err
 	}
 
-	// t.FromProvider (string) (string)
-	if len("FromProvider") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"FromProvider\" was too long")
+	// t.Cid (cid.Cid) (struct)
+	if len("Cid") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Cid\" was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("FromProvider"))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Cid"))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string("FromProvider")); err != nil {
+	if _, err := io.WriteString(w, string("Cid")); err != nil {
 		return err
 	}
 
-	if len(t.FromProvider) > cbg.MaxLength {
-		return xerrors.Errorf("Value in field t.FromProvider was too long")
+	if err := cbg.WriteCid(cw, t.Cid); err != nil {
+		return xerrors.Errorf("failed to write cid field t.Cid: %w", err)
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.FromProvider))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string(t.FromProvider)); err != nil {
-		return err
-	}
 	return nil
 }
 
-func (t *MigrateKey) UnmarshalCBOR(r io.Reader) (err error) {
-	*t = MigrateKey{}
+func (t *ShardKey) UnmarshalCBOR(r io.Reader) (err error) {
+	*t = ShardKey{}
 
 	cr := cbg.NewCborReader(r)
 
@@ -2355,7 +2189,7 @@ func (t *MigrateKey) UnmarshalCBOR(r io.Reader) (err error) {
 	}
 
 	if extra > cbg.MaxLength {
-		return fmt.Errorf("MigrateKey: map struct too large (%d)", extra)
+		return fmt.Errorf("ShardKey: map struct too large (%d)", extra)
 	}
 
 	var name string
@@ -2373,27 +2207,33 @@ func (t *MigrateKey) UnmarshalCBOR(r io.Reader) (err error) {
 		}
 
 		switch name {
-		// t.DataId (string) (string)
-		case "DataId":
+		// t.OrderId (uint64) (uint64)
+		case "OrderId":
 
 			{
-				sval, err := cbg.ReadString(cr)
+
+				maj, extra, err = cr.ReadHeader()
 				if err != nil {
 					return err
 				}
+				if maj != cbg.MajUnsignedInt {
+					return fmt.Errorf("wrong type for uint64 field")
+				}
+				t.OrderId = uint64(extra)
 
-				t.DataId = string(sval)
 			}
-			// t.FromProvider (string) (string)
-		case "FromProvider":
+			// t.Cid (cid.Cid) (struct)
+		case "Cid":
 
 			{
-				sval, err := cbg.ReadString(cr)
+
+				c, err := cbg.ReadCid(cr)
 				if err != nil {
-					return err
+					return xerrors.Errorf("failed to read cid field t.Cid: %w", err)
 				}
 
-				t.FromProvider = string(sval)
+				t.Cid = c
+
 			}
 
 		default:
@@ -2404,7 +2244,7 @@ func (t *MigrateKey) UnmarshalCBOR(r io.Reader) (err error) {
 
 	return nil
 }
-func (t *MigrateInfo) MarshalCBOR(w io.Writer) error {
+func (t *ShardInfo) MarshalCBOR(w io.Writer) error {
 	if t == nil {
 		_, err := w.Write(cbg.CborNull)
 		return err
@@ -2412,7 +2252,23 @@ func (t *MigrateInfo) MarshalCBOR(w io.Writer) error {
 
 	cw := cbg.NewCborWriter(w)
 
-	if _, err := cw.Write([]byte{170}); err != nil {
+	if _, err := cw.Write([]byte{177}); err != nil {
+		return err
+	}
+
+	// t.OrderId (uint64) (uint64)
+	if len("OrderId") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"OrderId\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("OrderId"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("OrderId")); err != nil {
+		return err
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.OrderId)); err != nil {
 		return err
 	}
 
@@ -2439,182 +2295,208 @@ func (t *MigrateInfo) MarshalCBOR(w io.Writer) error {
 		return err
 	}
 
-	// t.OrderId (uint64) (uint64)
-	if len("OrderId") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"OrderId\" was too long")
+	// t.Cid (cid.Cid) (struct)
+	if len("Cid") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Cid\" was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("OrderId"))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Cid"))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string("OrderId")); err != nil {
+	if _, err := io.WriteString(w, string("Cid")); err != nil {
 		return err
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.OrderId)); err != nil {
-		return err
+	if err := cbg.WriteCid(cw, t.Cid); err != nil {
+		return xerrors.Errorf("failed to write cid field t.Cid: %w", err)
 	}
 
-	// t.Cid (string) (string)
-	if len("Cid") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"Cid\" was too long")
+	// t.Owner (string) (string)
+	if len("Owner") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Owner\" was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Cid"))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Owner"))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string("Cid")); err != nil {
+	if _, err := io.WriteString(w, string("Owner")); err != nil {
 		return err
 	}
 
-	if len(t.Cid) > cbg.MaxLength {
-		return xerrors.Errorf("Value in field t.Cid was too long")
+	if len(t.Owner) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.Owner was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.Cid))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.Owner))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string(t.Cid)); err != nil {
+	if _, err := io.WriteString(w, string(t.Owner)); err != nil {
 		return err
 	}
 
-	// t.FromProvider (string) (string)
-	if len("FromProvider") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"FromProvider\" was too long")
+	// t.Gateway (string) (string)
+	if len("Gateway") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Gateway\" was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("FromProvider"))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Gateway"))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string("FromProvider")); err != nil {
+	if _, err := io.WriteString(w, string("Gateway")); err != nil {
 		return err
 	}
 
-	if len(t.FromProvider) > cbg.MaxLength {
-		return xerrors.Errorf("Value in field t.FromProvider was too long")
+	if len(t.Gateway) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.Gateway was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.FromProvider))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.Gateway))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string(t.FromProvider)); err != nil {
+	if _, err := io.WriteString(w, string(t.Gateway)); err != nil {
 		return err
 	}
 
-	// t.ToProvider (string) (string)
-	if len("ToProvider") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"ToProvider\" was too long")
+	// t.OrderOperation (string) (string)
+	if len("OrderOperation") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"OrderOperation\" was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("ToProvider"))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("OrderOperation"))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string("ToProvider")); err != nil {
+	if _, err := io.WriteString(w, string("OrderOperation")); err != nil {
 		return err
 	}
 
-	if len(t.ToProvider) > cbg.MaxLength {
-		return xerrors.Errorf("Value in field t.ToProvider was too long")
+	if len(t.OrderOperation) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.OrderOperation was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.ToProvider))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.OrderOperation))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string(t.ToProvider)); err != nil {
+	if _, err := io.WriteString(w, string(t.OrderOperation)); err != nil {
 		return err
 	}
 
-	// t.MigrateTxHash (string) (string)
-	if len("MigrateTxHash") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"MigrateTxHash\" was too long")
+	// t.ShardOperation (string) (string)
+	if len("ShardOperation") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"ShardOperation\" was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("MigrateTxHash"))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("ShardOperation"))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string("MigrateTxHash")); err != nil {
+	if _, err := io.WriteString(w, string("ShardOperation")); err != nil {
 		return err
 	}
 
-	if len(t.MigrateTxHash) > cbg.MaxLength {
-		return xerrors.Errorf("Value in field t.MigrateTxHash was too long")
+	if len(t.ShardOperation) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.ShardOperation was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.MigrateTxHash))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.ShardOperation))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string(t.MigrateTxHash)); err != nil {
+	if _, err := io.WriteString(w, string(t.ShardOperation)); err != nil {
 		return err
 	}
 
-	// t.MigrateTxHeight (int64) (int64)
-	if len("MigrateTxHeight") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"MigrateTxHeight\" was too long")
+	// t.CompleteHash (string) (string)
+	if len("CompleteHash") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"CompleteHash\" was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("MigrateTxHeight"))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("CompleteHash"))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string("MigrateTxHeight")); err != nil {
+	if _, err := io.WriteString(w, string("CompleteHash")); err != nil {
 		return err
 	}
 
-	if t.MigrateTxHeight >= 0 {
-		if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.MigrateTxHeight)); err != nil {
+	if len(t.CompleteHash) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.CompleteHash was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.CompleteHash))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.CompleteHash)); err != nil {
+		return err
+	}
+
+	// t.CompleteHeight (int64) (int64)
+	if len("CompleteHeight") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"CompleteHeight\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("CompleteHeight"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("CompleteHeight")); err != nil {
+		return err
+	}
+
+	if t.CompleteHeight >= 0 {
+		if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.CompleteHeight)); err != nil {
 			return err
 		}
 	} else {
-		if err := cw.WriteMajorTypeHeader(cbg.MajNegativeInt, uint64(-t.MigrateTxHeight-1)); err != nil {
+		if err := cw.WriteMajorTypeHeader(cbg.MajNegativeInt, uint64(-t.CompleteHeight-1)); err != nil {
 			return err
 		}
 	}
 
-	// t.CompleteTxHash (string) (string)
-	if len("CompleteTxHash") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"CompleteTxHash\" was too long")
+	// t.Size (uint64) (uint64)
+	if len("Size") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Size\" was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("CompleteTxHash"))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Size"))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string("CompleteTxHash")); err != nil {
+	if _, err := io.WriteString(w, string("Size")); err != nil {
 		return err
 	}
 
-	if len(t.CompleteTxHash) > cbg.MaxLength {
-		return xerrors.Errorf("Value in field t.CompleteTxHash was too long")
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.Size)); err != nil {
+		return err
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.CompleteTxHash))); err != nil {
+	// t.Tries (uint64) (uint64)
+	if len("Tries") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Tries\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Tries"))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string(t.CompleteTxHash)); err != nil {
+	if _, err := io.WriteString(w, string("Tries")); err != nil {
 		return err
 	}
 
-	// t.CompleteTxHeight (int64) (int64)
-	if len("CompleteTxHeight") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"CompleteTxHeight\" was too long")
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.Tries)); err != nil {
+		return err
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("CompleteTxHeight"))); err != nil {
+	// t.ExpireHeight (uint64) (uint64)
+	if len("ExpireHeight") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"ExpireHeight\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("ExpireHeight"))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string("CompleteTxHeight")); err != nil {
+	if _, err := io.WriteString(w, string("ExpireHeight")); err != nil {
 		return err
 	}
 
-	if t.CompleteTxHeight >= 0 {
-		if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.CompleteTxHeight)); err != nil {
-			return err
-		}
-	} else {
-		if err := cw.WriteMajorTypeHeader(cbg.MajNegativeInt, uint64(-t.CompleteTxHeight-1)); err != nil {
-			return err
-		}
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.ExpireHeight)); err != nil {
+		return err
 	}
 
-	// t.State (types.MigrateState) (uint64)
+	// t.State (types.ShardState) (uint64)
 	if len("State") > cbg.MaxLength {
 		return xerrors.Errorf("Value in field \"State\" was too long")
 	}
@@ -2630,11 +2512,94 @@ func (t *MigrateInfo) MarshalCBOR(w io.Writer) error {
 		return err
 	}
 
+	// t.RetryAt (int64) (int64)
+	if len("RetryAt") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"RetryAt\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("RetryAt"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("RetryAt")); err != nil {
+		return err
+	}
+
+	if t.RetryAt >= 0 {
+		if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.RetryAt)); err != nil {
+			return err
+		}
+	} else {
+		if err := cw.WriteMajorTypeHeader(cbg.MajNegativeInt, uint64(-t.RetryAt-1)); err != nil {
+			return err
+		}
+	}
+
+	// t.LastErr (string) (string)
+	if len("LastErr") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"LastErr\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("LastErr"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("LastErr")); err != nil {
+		return err
+	}
+
+	if len(t.LastErr) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.LastErr was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.LastErr))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.LastErr)); err != nil {
+		return err
+	}
+
+	// t.RetryPolicy (string) (string)
+	if len("RetryPolicy") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"RetryPolicy\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("RetryPolicy"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("RetryPolicy")); err != nil {
+		return err
+	}
+
+	if len(t.RetryPolicy) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.RetryPolicy was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.RetryPolicy))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.RetryPolicy)); err != nil {
+		return err
+	}
+
+	// t.TraceContext (types.TraceContext) (struct)
+	if len("TraceContext") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"TraceContext\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("TraceContext"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("TraceContext")); err != nil {
+		return err
+	}
+
+	if err := t.TraceContext.MarshalCBOR(cw); err != nil {
+		return err
+	}
 	return nil
 }
 
-func (t *MigrateInfo) UnmarshalCBOR(r io.Reader) (err error) {
-	*t = MigrateInfo{}
+func (t *ShardInfo) UnmarshalCBOR(r io.Reader) (err error) {
+	*t = ShardInfo{}
 
 	cr := cbg.NewCborReader(r)
 
@@ -2653,7 +2618,7 @@ func (t *MigrateInfo) UnmarshalCBOR(r io.Reader) (err error) {
 	}
 
 	if extra > cbg.MaxLength {
-		return fmt.Errorf("MigrateInfo: map struct too large (%d)", extra)
+		return fmt.Errorf("ShardInfo: map struct too large (%d)", extra)
 	}
 
 	var name string
@@ -2671,18 +2636,7 @@ func (t *MigrateInfo) UnmarshalCBOR(r io.Reader) (err error) {
 		}
 
 		switch name {
-		// t.DataId (string) (string)
-		case "DataId":
-
-			{
-				sval, err := cbg.ReadString(cr)
-				if err != nil {
-					return err
-				}
-
-				t.DataId = string(sval)
-			}
-			// t.OrderId (uint64) (uint64)
+		// t.OrderId (uint64) (uint64)
 		case "OrderId":
 
 			{
@@ -2697,8 +2651,8 @@ func (t *MigrateInfo) UnmarshalCBOR(r io.Reader) (err error) {
 				t.OrderId = uint64(extra)
 
 			}
-			// t.Cid (string) (string)
-		case "Cid":
+			// t.DataId (string) (string)
+		case "DataId":
 
 			{
 				sval, err := cbg.ReadString(cr)
@@ -2706,21 +2660,23 @@ func (t *MigrateInfo) UnmarshalCBOR(r io.Reader) (err error) {
 					return err
 				}
 
-				t.Cid = string(sval)
+				t.DataId = string(sval)
 			}
-			// t.FromProvider (string) (string)
-		case "FromProvider":
+			// t.Cid (cid.Cid) (struct)
+		case "Cid":
 
 			{
-				sval, err := cbg.ReadString(cr)
+
+				c, err := cbg.ReadCid(cr)
 				if err != nil {
-					return err
+					return xerrors.Errorf("failed to read cid field t.Cid: %w", err)
 				}
 
-				t.FromProvider = string(sval)
+				t.Cid = c
+
 			}
-			// t.ToProvider (string) (string)
-		case "ToProvider":
+			// t.Owner (string) (string)
+		case "Owner":
 
 			{
 				sval, err := cbg.ReadString(cr)
@@ -2728,10 +2684,10 @@ func (t *MigrateInfo) UnmarshalCBOR(r io.Reader) (err error) {
 					return err
 				}
 
-				t.ToProvider = string(sval)
+				t.Owner = string(sval)
 			}
-			// t.MigrateTxHash (string) (string)
-		case "MigrateTxHash":
+			// t.Gateway (string) (string)
+		case "Gateway":
 
 			{
 				sval, err := cbg.ReadString(cr)
@@ -2739,10 +2695,43 @@ func (t *MigrateInfo) UnmarshalCBOR(r io.Reader) (err error) {
 					return err
 				}
 
-				t.MigrateTxHash = string(sval)
+				t.Gateway = string(sval)
 			}
-			// t.MigrateTxHeight (int64) (int64)
-		case "MigrateTxHeight":
+			// t.OrderOperation (string) (string)
+		case "OrderOperation":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.OrderOperation = string(sval)
+			}
+			// t.ShardOperation (string) (string)
+		case "ShardOperation":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.ShardOperation = string(sval)
+			}
+			// t.CompleteHash (string) (string)
+		case "CompleteHash":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.CompleteHash = string(sval)
+			}
+			// t.CompleteHeight (int64) (int64)
+		case "CompleteHeight":
 			{
 				maj, extra, err := cr.ReadHeader()
 				var extraI int64
@@ -2765,21 +2754,70 @@ func (t *MigrateInfo) UnmarshalCBOR(r io.Reader) (err error) {
 					return fmt.Errorf("wrong type for int64 field: %d", maj)
 				}
 
-				t.MigrateTxHeight = int64(extraI)
+				t.CompleteHeight = int64(extraI)
 			}
-			// t.CompleteTxHash (string) (string)
-		case "CompleteTxHash":
+			// t.Size (uint64) (uint64)
+		case "Size":
 
 			{
-				sval, err := cbg.ReadString(cr)
+
+				maj, extra, err = cr.ReadHeader()
 				if err != nil {
 					return err
 				}
+				if maj != cbg.MajUnsignedInt {
+					return fmt.Errorf("wrong type for uint64 field")
+				}
+				t.Size = uint64(extra)
 
-				t.CompleteTxHash = string(sval)
 			}
-			// t.CompleteTxHeight (int64) (int64)
-		case "CompleteTxHeight":
+			// t.Tries (uint64) (uint64)
+		case "Tries":
+
+			{
+
+				maj, extra, err = cr.ReadHeader()
+				if err != nil {
+					return err
+				}
+				if maj != cbg.MajUnsignedInt {
+					return fmt.Errorf("wrong type for uint64 field")
+				}
+				t.Tries = uint64(extra)
+
+			}
+			// t.ExpireHeight (uint64) (uint64)
+		case "ExpireHeight":
+
+			{
+
+				maj, extra, err = cr.ReadHeader()
+				if err != nil {
+					return err
+				}
+				if maj != cbg.MajUnsignedInt {
+					return fmt.Errorf("wrong type for uint64 field")
+				}
+				t.ExpireHeight = uint64(extra)
+
+			}
+			// t.State (types.ShardState) (uint64)
+		case "State":
+
+			{
+
+				maj, extra, err = cr.ReadHeader()
+				if err != nil {
+					return err
+				}
+				if maj != cbg.MajUnsignedInt {
+					return fmt.Errorf("wrong type for uint64 field")
+				}
+				t.State = ShardState(extra)
+
+			}
+			// t.RetryAt (int64) (int64)
+		case "RetryAt":
 			{
 				maj, extra, err := cr.ReadHeader()
 				var extraI int64
@@ -2802,21 +2840,38 @@ func (t *MigrateInfo) UnmarshalCBOR(r io.Reader) (err error) {
 					return fmt.Errorf("wrong type for int64 field: %d", maj)
 				}
 
-				t.CompleteTxHeight = int64(extraI)
+				t.RetryAt = int64(extraI)
 			}
-			// t.State (types.MigrateState) (uint64)
-		case "State":
+			// t.LastErr (string) (string)
+		case "LastErr":
 
 			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
 
-				maj, extra, err = cr.ReadHeader()
+				t.LastErr = string(sval)
+			}
+			// t.RetryPolicy (string) (string)
+		case "RetryPolicy":
+
+			{
+				sval, err := cbg.ReadString(cr)
 				if err != nil {
 					return err
 				}
-				if maj != cbg.MajUnsignedInt {
-					return fmt.Errorf("wrong type for uint64 field")
+
+				t.RetryPolicy = string(sval)
+			}
+			// t.TraceContext (types.TraceContext) (struct)
+		case "TraceContext":
+
+			{
+
+				if err := t.TraceContext.UnmarshalCBOR(cr); err != nil {
+					return xerrors.Errorf("unmarshaling t.TraceContext: %w", err)
 				}
-				t.State = MigrateState(extra)
 
 			}
 
@@ -2828,7 +2883,7 @@ func (t *MigrateInfo) UnmarshalCBOR(r io.Reader) (err error) {
 
 	return nil
 }
-func (t *MigrateIndex) MarshalCBOR(w io.Writer) error {
+func (t *ShardIndex) MarshalCBOR(w io.Writer) error {
 	if t == nil {
 		_, err := w.Write(cbg.CborNull)
 		return err
@@ -2840,7 +2895,7 @@ func (t *MigrateIndex) MarshalCBOR(w io.Writer) error {
 		return err
 	}
 
-	// t.All ([]types.MigrateKey) (slice)
+	// t.All ([]types.ShardKey) (slice)
 	if len("All") > cbg.MaxLength {
 		return xerrors.Errorf("Value in field \"All\" was too long")
 	}
@@ -2867,8 +2922,8 @@ func (t *MigrateIndex) MarshalCBOR(w io.Writer) error {
 	return nil
 }
 
-func (t *MigrateIndex) UnmarshalCBOR(r io.Reader) (err error) {
-	*t = MigrateIndex{}
+func (t *ShardIndex) UnmarshalCBOR(r io.Reader) (err error) {
+	*t = ShardIndex{}
 
 	cr := cbg.NewCborReader(r)
 
@@ -2887,7 +2942,7 @@ func (t *MigrateIndex) UnmarshalCBOR(r io.Reader) (err error) {
 	}
 
 	if extra > cbg.MaxLength {
-		return fmt.Errorf("MigrateIndex: map struct too large (%d)", extra)
+		return fmt.Errorf("ShardIndex: map struct too large (%d)", extra)
 	}
 
 	var name string
@@ -2905,7 +2960,7 @@ func (t *MigrateIndex) UnmarshalCBOR(r io.Reader) (err error) {
 		}
 
 		switch name {
-		// t.All ([]types.MigrateKey) (slice)
+		// t.All ([]types.ShardKey) (slice)
 		case "All":
 
 			maj, extra, err = cr.ReadHeader()
@@ -2922,12 +2977,12 @@ func (t *MigrateIndex) UnmarshalCBOR(r io.Reader) (err error) {
 			}
 
 			if extra > 0 {
-				t.All = make([]MigrateKey, extra)
+				t.All = make([]ShardKey, extra)
 			}
 
 			for i := 0; i < int(extra); i++ {
 
-				var v MigrateKey
+				var v ShardKey
 				if err := v.UnmarshalCBOR(cr); err != nil {
 					return err
 				}
@@ -2943,7 +2998,7 @@ func (t *MigrateIndex) UnmarshalCBOR(r io.Reader) (err error) {
 
 	return nil
 }
-func (t *QueryProposal) MarshalCBOR(w io.Writer) error {
+func (t *ShardCommitment) MarshalCBOR(w io.Writer) error {
 	if t == nil {
 		_, err := w.Write(cbg.CborNull)
 		return err
@@ -2951,203 +3006,150 @@ func (t *QueryProposal) MarshalCBOR(w io.Writer) error {
 
 	cw := cbg.NewCborWriter(w)
 
-	if _, err := cw.Write([]byte{168}); err != nil {
+	if _, err := cw.Write([]byte{166}); err != nil {
 		return err
 	}
 
-	// t.Owner (string) (string)
-	if len("Owner") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"Owner\" was too long")
+	// t.Cid (string) (string)
+	if len("Cid") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Cid\" was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Owner"))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Cid"))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string("Owner")); err != nil {
+	if _, err := io.WriteString(w, string("Cid")); err != nil {
 		return err
 	}
 
-	if len(t.Owner) > cbg.MaxLength {
-		return xerrors.Errorf("Value in field t.Owner was too long")
+	if len(t.Cid) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.Cid was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.Owner))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.Cid))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string(t.Owner)); err != nil {
+	if _, err := io.WriteString(w, string(t.Cid)); err != nil {
 		return err
 	}
 
-	// t.Keyword (string) (string)
-	if len("Keyword") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"Keyword\" was too long")
+	// t.Size (uint64) (uint64)
+	if len("Size") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Size\" was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Keyword"))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Size"))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string("Keyword")); err != nil {
+	if _, err := io.WriteString(w, string("Size")); err != nil {
 		return err
 	}
 
-	if len(t.Keyword) > cbg.MaxLength {
-		return xerrors.Errorf("Value in field t.Keyword was too long")
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.Keyword))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string(t.Keyword)); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.Size)); err != nil {
 		return err
 	}
 
-	// t.GroupId (string) (string)
-	if len("GroupId") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"GroupId\" was too long")
+	// t.LeafSize (uint64) (uint64)
+	if len("LeafSize") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"LeafSize\" was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("GroupId"))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("LeafSize"))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string("GroupId")); err != nil {
+	if _, err := io.WriteString(w, string("LeafSize")); err != nil {
 		return err
 	}
 
-	if len(t.GroupId) > cbg.MaxLength {
-		return xerrors.Errorf("Value in field t.GroupId was too long")
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.GroupId))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string(t.GroupId)); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.LeafSize)); err != nil {
 		return err
 	}
 
-	// t.KeywordType (uint64) (uint64)
-	if len("KeywordType") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"KeywordType\" was too long")
+	// t.LeafCount (uint64) (uint64)
+	if len("LeafCount") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"LeafCount\" was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("KeywordType"))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("LeafCount"))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string("KeywordType")); err != nil {
+	if _, err := io.WriteString(w, string("LeafCount")); err != nil {
 		return err
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.KeywordType)); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.LeafCount)); err != nil {
 		return err
 	}
 
-	// t.LastValidHeight (uint64) (uint64)
-	if len("LastValidHeight") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"LastValidHeight\" was too long")
+	// t.Root ([]uint8) (slice)
+	if len("Root") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Root\" was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("LastValidHeight"))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string("LastValidHeight")); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Root"))); err != nil {
 		return err
 	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.LastValidHeight)); err != nil {
+	if _, err := io.WriteString(w, string("Root")); err != nil {
 		return err
 	}
 
-	// t.Gateway (string) (string)
-	if len("Gateway") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"Gateway\" was too long")
+	if len(t.Root) > cbg.ByteArrayMaxLen {
+		return xerrors.Errorf("Byte array in field t.Root was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Gateway"))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajByteString, uint64(len(t.Root))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string("Gateway")); err != nil {
+
+	if _, err := cw.Write(t.Root[:]); err != nil {
 		return err
 	}
 
-	if len(t.Gateway) > cbg.MaxLength {
-		return xerrors.Errorf("Value in field t.Gateway was too long")
+	// t.CreatedAt (int64) (int64)
+	if len("CreatedAt") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"CreatedAt\" was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.Gateway))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("CreatedAt"))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string(t.Gateway)); err != nil {
+	if _, err := io.WriteString(w, string("CreatedAt")); err != nil {
 		return err
 	}
 
-	// t.CommitId (string) (string)
-	if len("CommitId") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"CommitId\" was too long")
+	if t.CreatedAt >= 0 {
+		if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.CreatedAt)); err != nil {
+			return err
+		}
+	} else {
+		if err := cw.WriteMajorTypeHeader(cbg.MajNegativeInt, uint64(-t.CreatedAt-1)); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("CommitId"))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string("CommitId")); err != nil {
-		return err
-	}
+func (t *ShardCommitment) UnmarshalCBOR(r io.Reader) (err error) {
+	*t = ShardCommitment{}
 
-	if len(t.CommitId) > cbg.MaxLength {
-		return xerrors.Errorf("Value in field t.CommitId was too long")
-	}
+	cr := cbg.NewCborReader(r)
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.CommitId))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string(t.CommitId)); err != nil {
+	maj, extra, err := cr.ReadHeader()
+	if err != nil {
 		return err
 	}
+	defer func() {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+	}()
 
-	// t.Version (string) (string)
-	if len("Version") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"Version\" was too long")
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Version"))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string("Version")); err != nil {
-		return err
-	}
-
-	if len(t.Version) > cbg.MaxLength {
-		return xerrors.Errorf("Value in field t.Version was too long")
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.Version))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string(t.Version)); err != nil {
-		return err
-	}
-	return nil
-}
-
-func (t *QueryProposal) UnmarshalCBOR(r io.Reader) (err error) {
-	*t = QueryProposal{}
-
-	cr := cbg.NewCborReader(r)
-
-	maj, extra, err := cr.ReadHeader()
-	if err != nil {
-		return err
-	}
-	defer func() {
-		if err == io.EOF {
-			err = io.ErrUnexpectedEOF
-		}
-	}()
-
-	if maj != cbg.MajMap {
-		return fmt.Errorf("cbor input should be of type map")
+	if maj != cbg.MajMap {
+		return fmt.Errorf("cbor input should be of type map")
 	}
 
 	if extra > cbg.MaxLength {
-		return fmt.Errorf("QueryProposal: map struct too large (%d)", extra)
+		return fmt.Errorf("ShardCommitment: map struct too large (%d)", extra)
 	}
 
 	var name string
@@ -3165,8 +3167,8 @@ func (t *QueryProposal) UnmarshalCBOR(r io.Reader) (err error) {
 		}
 
 		switch name {
-		// t.Owner (string) (string)
-		case "Owner":
+		// t.Cid (string) (string)
+		case "Cid":
 
 			{
 				sval, err := cbg.ReadString(cr)
@@ -3174,32 +3176,25 @@ func (t *QueryProposal) UnmarshalCBOR(r io.Reader) (err error) {
 					return err
 				}
 
-				t.Owner = string(sval)
+				t.Cid = string(sval)
 			}
-			// t.Keyword (string) (string)
-		case "Keyword":
+			// t.Size (uint64) (uint64)
+		case "Size":
 
 			{
-				sval, err := cbg.ReadString(cr)
-				if err != nil {
-					return err
-				}
-
-				t.Keyword = string(sval)
-			}
-			// t.GroupId (string) (string)
-		case "GroupId":
 
-			{
-				sval, err := cbg.ReadString(cr)
+				maj, extra, err = cr.ReadHeader()
 				if err != nil {
 					return err
 				}
+				if maj != cbg.MajUnsignedInt {
+					return fmt.Errorf("wrong type for uint64 field")
+				}
+				t.Size = uint64(extra)
 
-				t.GroupId = string(sval)
 			}
-			// t.KeywordType (uint64) (uint64)
-		case "KeywordType":
+			// t.LeafSize (uint64) (uint64)
+		case "LeafSize":
 
 			{
 
@@ -3210,11 +3205,11 @@ func (t *QueryProposal) UnmarshalCBOR(r io.Reader) (err error) {
 				if maj != cbg.MajUnsignedInt {
 					return fmt.Errorf("wrong type for uint64 field")
 				}
-				t.KeywordType = uint64(extra)
+				t.LeafSize = uint64(extra)
 
 			}
-			// t.LastValidHeight (uint64) (uint64)
-		case "LastValidHeight":
+			// t.LeafCount (uint64) (uint64)
+		case "LeafCount":
 
 			{
 
@@ -3225,41 +3220,56 @@ func (t *QueryProposal) UnmarshalCBOR(r io.Reader) (err error) {
 				if maj != cbg.MajUnsignedInt {
 					return fmt.Errorf("wrong type for uint64 field")
 				}
-				t.LastValidHeight = uint64(extra)
+				t.LeafCount = uint64(extra)
 
 			}
-			// t.Gateway (string) (string)
-		case "Gateway":
-
-			{
-				sval, err := cbg.ReadString(cr)
-				if err != nil {
-					return err
-				}
+			// t.Root ([]uint8) (slice)
+		case "Root":
 
-				t.Gateway = string(sval)
+			maj, extra, err = cr.ReadHeader()
+			if err != nil {
+				return err
 			}
-			// t.CommitId (string) (string)
-		case "CommitId":
 
-			{
-				sval, err := cbg.ReadString(cr)
-				if err != nil {
-					return err
-				}
+			if extra > cbg.ByteArrayMaxLen {
+				return fmt.Errorf("t.Root: byte array too large (%d)", extra)
+			}
+			if maj != cbg.MajByteString {
+				return fmt.Errorf("expected byte array")
+			}
 
-				t.CommitId = string(sval)
+			if extra > 0 {
+				t.Root = make([]uint8, extra)
 			}
-			// t.Version (string) (string)
-		case "Version":
 
+			if _, err := io.ReadFull(cr, t.Root[:]); err != nil {
+				return err
+			}
+			// t.CreatedAt (int64) (int64)
+		case "CreatedAt":
 			{
-				sval, err := cbg.ReadString(cr)
+				maj, extra, err := cr.ReadHeader()
+				var extraI int64
 				if err != nil {
 					return err
 				}
+				switch maj {
+				case cbg.MajUnsignedInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 positive overflow")
+					}
+				case cbg.MajNegativeInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 negative oveflow")
+					}
+					extraI = -1 - extraI
+				default:
+					return fmt.Errorf("wrong type for int64 field: %d", maj)
+				}
 
-				t.Version = string(sval)
+				t.CreatedAt = int64(extraI)
 			}
 
 		default:
@@ -3270,7 +3280,7 @@ func (t *QueryProposal) UnmarshalCBOR(r io.Reader) (err error) {
 
 	return nil
 }
-func (t *RelayProposal) MarshalCBOR(w io.Writer) error {
+func (t *MigrateKey) MarshalCBOR(w io.Writer) error {
 	if t == nil {
 		_, err := w.Write(cbg.CborNull)
 		return err
@@ -3278,106 +3288,60 @@ func (t *RelayProposal) MarshalCBOR(w io.Writer) error {
 
 	cw := cbg.NewCborWriter(w)
 
-	if _, err := cw.Write([]byte{164}); err != nil {
-		return err
-	}
-
-	// t.NodeAddress (string) (string)
-	if len("NodeAddress") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"NodeAddress\" was too long")
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("NodeAddress"))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string("NodeAddress")); err != nil {
-		return err
-	}
-
-	if len(t.NodeAddress) > cbg.MaxLength {
-		return xerrors.Errorf("Value in field t.NodeAddress was too long")
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.NodeAddress))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string(t.NodeAddress)); err != nil {
-		return err
-	}
-
-	// t.LocalPeerId (string) (string)
-	if len("LocalPeerId") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"LocalPeerId\" was too long")
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("LocalPeerId"))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string("LocalPeerId")); err != nil {
-		return err
-	}
-
-	if len(t.LocalPeerId) > cbg.MaxLength {
-		return xerrors.Errorf("Value in field t.LocalPeerId was too long")
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.LocalPeerId))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string(t.LocalPeerId)); err != nil {
+	if _, err := cw.Write([]byte{162}); err != nil {
 		return err
 	}
 
-	// t.RelayPeerIds (string) (string)
-	if len("RelayPeerIds") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"RelayPeerIds\" was too long")
+	// t.DataId (string) (string)
+	if len("DataId") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"DataId\" was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("RelayPeerIds"))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("DataId"))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string("RelayPeerIds")); err != nil {
+	if _, err := io.WriteString(w, string("DataId")); err != nil {
 		return err
 	}
 
-	if len(t.RelayPeerIds) > cbg.MaxLength {
-		return xerrors.Errorf("Value in field t.RelayPeerIds was too long")
+	if len(t.DataId) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.DataId was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.RelayPeerIds))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.DataId))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string(t.RelayPeerIds)); err != nil {
+	if _, err := io.WriteString(w, string(t.DataId)); err != nil {
 		return err
 	}
 
-	// t.TargetPeerInfo (string) (string)
-	if len("TargetPeerInfo") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"TargetPeerInfo\" was too long")
+	// t.FromProvider (string) (string)
+	if len("FromProvider") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"FromProvider\" was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("TargetPeerInfo"))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("FromProvider"))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string("TargetPeerInfo")); err != nil {
+	if _, err := io.WriteString(w, string("FromProvider")); err != nil {
 		return err
 	}
 
-	if len(t.TargetPeerInfo) > cbg.MaxLength {
-		return xerrors.Errorf("Value in field t.TargetPeerInfo was too long")
+	if len(t.FromProvider) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.FromProvider was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.TargetPeerInfo))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.FromProvider))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string(t.TargetPeerInfo)); err != nil {
+	if _, err := io.WriteString(w, string(t.FromProvider)); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (t *RelayProposal) UnmarshalCBOR(r io.Reader) (err error) {
-	*t = RelayProposal{}
+func (t *MigrateKey) UnmarshalCBOR(r io.Reader) (err error) {
+	*t = MigrateKey{}
 
 	cr := cbg.NewCborReader(r)
 
@@ -3396,7 +3360,7 @@ func (t *RelayProposal) UnmarshalCBOR(r io.Reader) (err error) {
 	}
 
 	if extra > cbg.MaxLength {
-		return fmt.Errorf("RelayProposal: map struct too large (%d)", extra)
+		return fmt.Errorf("MigrateKey: map struct too large (%d)", extra)
 	}
 
 	var name string
@@ -3414,30 +3378,8 @@ func (t *RelayProposal) UnmarshalCBOR(r io.Reader) (err error) {
 		}
 
 		switch name {
-		// t.NodeAddress (string) (string)
-		case "NodeAddress":
-
-			{
-				sval, err := cbg.ReadString(cr)
-				if err != nil {
-					return err
-				}
-
-				t.NodeAddress = string(sval)
-			}
-			// t.LocalPeerId (string) (string)
-		case "LocalPeerId":
-
-			{
-				sval, err := cbg.ReadString(cr)
-				if err != nil {
-					return err
-				}
-
-				t.LocalPeerId = string(sval)
-			}
-			// t.RelayPeerIds (string) (string)
-		case "RelayPeerIds":
+		// t.DataId (string) (string)
+		case "DataId":
 
 			{
 				sval, err := cbg.ReadString(cr)
@@ -3445,10 +3387,10 @@ func (t *RelayProposal) UnmarshalCBOR(r io.Reader) (err error) {
 					return err
 				}
 
-				t.RelayPeerIds = string(sval)
+				t.DataId = string(sval)
 			}
-			// t.TargetPeerInfo (string) (string)
-		case "TargetPeerInfo":
+			// t.FromProvider (string) (string)
+		case "FromProvider":
 
 			{
 				sval, err := cbg.ReadString(cr)
@@ -3456,7 +3398,7 @@ func (t *RelayProposal) UnmarshalCBOR(r io.Reader) (err error) {
 					return err
 				}
 
-				t.TargetPeerInfo = string(sval)
+				t.FromProvider = string(sval)
 			}
 
 		default:
@@ -3467,7 +3409,7 @@ func (t *RelayProposal) UnmarshalCBOR(r io.Reader) (err error) {
 
 	return nil
 }
-func (t *JwsSignature) MarshalCBOR(w io.Writer) error {
+func (t *MigrateInfo) MarshalCBOR(w io.Writer) error {
 	if t == nil {
 		_, err := w.Write(cbg.CborNull)
 		return err
@@ -3475,175 +3417,229 @@ func (t *JwsSignature) MarshalCBOR(w io.Writer) error {
 
 	cw := cbg.NewCborWriter(w)
 
-	if _, err := cw.Write([]byte{162}); err != nil {
+	if _, err := cw.Write([]byte{170}); err != nil {
 		return err
 	}
 
-	// t.Protected (string) (string)
-	if len("Protected") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"Protected\" was too long")
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Protected"))); err != nil {
+	// t.DataId (string) (string)
+	if len("DataId") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"DataId\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("DataId"))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string("Protected")); err != nil {
+	if _, err := io.WriteString(w, string("DataId")); err != nil {
 		return err
 	}
 
-	if len(t.Protected) > cbg.MaxLength {
-		return xerrors.Errorf("Value in field t.Protected was too long")
+	if len(t.DataId) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.DataId was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.Protected))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.DataId))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string(t.Protected)); err != nil {
+	if _, err := io.WriteString(w, string(t.DataId)); err != nil {
 		return err
 	}
 
-	// t.Signature (string) (string)
-	if len("Signature") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"Signature\" was too long")
+	// t.OrderId (uint64) (uint64)
+	if len("OrderId") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"OrderId\" was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Signature"))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("OrderId"))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string("Signature")); err != nil {
+	if _, err := io.WriteString(w, string("OrderId")); err != nil {
 		return err
 	}
 
-	if len(t.Signature) > cbg.MaxLength {
-		return xerrors.Errorf("Value in field t.Signature was too long")
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.OrderId)); err != nil {
+		return err
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.Signature))); err != nil {
+	// t.Cid (string) (string)
+	if len("Cid") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Cid\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Cid"))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string(t.Signature)); err != nil {
+	if _, err := io.WriteString(w, string("Cid")); err != nil {
 		return err
 	}
-	return nil
-}
 
-func (t *JwsSignature) UnmarshalCBOR(r io.Reader) (err error) {
-	*t = JwsSignature{}
+	if len(t.Cid) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.Cid was too long")
+	}
 
-	cr := cbg.NewCborReader(r)
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.Cid))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.Cid)); err != nil {
+		return err
+	}
 
-	maj, extra, err := cr.ReadHeader()
-	if err != nil {
+	// t.FromProvider (string) (string)
+	if len("FromProvider") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"FromProvider\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("FromProvider"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("FromProvider")); err != nil {
 		return err
 	}
-	defer func() {
-		if err == io.EOF {
-			err = io.ErrUnexpectedEOF
-		}
-	}()
 
-	if maj != cbg.MajMap {
-		return fmt.Errorf("cbor input should be of type map")
+	if len(t.FromProvider) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.FromProvider was too long")
 	}
 
-	if extra > cbg.MaxLength {
-		return fmt.Errorf("JwsSignature: map struct too large (%d)", extra)
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.FromProvider))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.FromProvider)); err != nil {
+		return err
 	}
 
-	var name string
-	n := extra
+	// t.ToProvider (string) (string)
+	if len("ToProvider") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"ToProvider\" was too long")
+	}
 
-	for i := uint64(0); i < n; i++ {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("ToProvider"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("ToProvider")); err != nil {
+		return err
+	}
 
-		{
-			sval, err := cbg.ReadString(cr)
-			if err != nil {
-				return err
-			}
+	if len(t.ToProvider) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.ToProvider was too long")
+	}
 
-			name = string(sval)
-		}
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.ToProvider))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.ToProvider)); err != nil {
+		return err
+	}
 
-		switch name {
-		// t.Protected (string) (string)
-		case "Protected":
+	// t.MigrateTxHash (string) (string)
+	if len("MigrateTxHash") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"MigrateTxHash\" was too long")
+	}
 
-			{
-				sval, err := cbg.ReadString(cr)
-				if err != nil {
-					return err
-				}
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("MigrateTxHash"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("MigrateTxHash")); err != nil {
+		return err
+	}
 
-				t.Protected = string(sval)
-			}
-			// t.Signature (string) (string)
-		case "Signature":
+	if len(t.MigrateTxHash) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.MigrateTxHash was too long")
+	}
 
-			{
-				sval, err := cbg.ReadString(cr)
-				if err != nil {
-					return err
-				}
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.MigrateTxHash))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.MigrateTxHash)); err != nil {
+		return err
+	}
 
-				t.Signature = string(sval)
-			}
+	// t.MigrateTxHeight (int64) (int64)
+	if len("MigrateTxHeight") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"MigrateTxHeight\" was too long")
+	}
 
-		default:
-			// Field doesn't exist on this type, so ignore it
-			cbg.ScanForLinks(r, func(cid.Cid) {})
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("MigrateTxHeight"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("MigrateTxHeight")); err != nil {
+		return err
+	}
+
+	if t.MigrateTxHeight >= 0 {
+		if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.MigrateTxHeight)); err != nil {
+			return err
+		}
+	} else {
+		if err := cw.WriteMajorTypeHeader(cbg.MajNegativeInt, uint64(-t.MigrateTxHeight-1)); err != nil {
+			return err
 		}
 	}
 
-	return nil
-}
-func (t *MetadataProposalCbor) MarshalCBOR(w io.Writer) error {
-	if t == nil {
-		_, err := w.Write(cbg.CborNull)
+	// t.CompleteTxHash (string) (string)
+	if len("CompleteTxHash") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"CompleteTxHash\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("CompleteTxHash"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("CompleteTxHash")); err != nil {
 		return err
 	}
 
-	cw := cbg.NewCborWriter(w)
+	if len(t.CompleteTxHash) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.CompleteTxHash was too long")
+	}
 
-	if _, err := cw.Write([]byte{162}); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.CompleteTxHash))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.CompleteTxHash)); err != nil {
 		return err
 	}
 
-	// t.Proposal (types.QueryProposal) (struct)
-	if len("Proposal") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"Proposal\" was too long")
+	// t.CompleteTxHeight (int64) (int64)
+	if len("CompleteTxHeight") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"CompleteTxHeight\" was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Proposal"))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("CompleteTxHeight"))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string("Proposal")); err != nil {
+	if _, err := io.WriteString(w, string("CompleteTxHeight")); err != nil {
 		return err
 	}
 
-	if err := t.Proposal.MarshalCBOR(cw); err != nil {
-		return err
+	if t.CompleteTxHeight >= 0 {
+		if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.CompleteTxHeight)); err != nil {
+			return err
+		}
+	} else {
+		if err := cw.WriteMajorTypeHeader(cbg.MajNegativeInt, uint64(-t.CompleteTxHeight-1)); err != nil {
+			return err
+		}
 	}
 
-	// t.JwsSignature (types.JwsSignature) (struct)
-	if len("JwsSignature") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"JwsSignature\" was too long")
+	// t.State (types.MigrateState) (uint64)
+	if len("State") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"State\" was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("JwsSignature"))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("State"))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string("JwsSignature")); err != nil {
+	if _, err := io.WriteString(w, string("State")); err != nil {
 		return err
 	}
 
-	if err := t.JwsSignature.MarshalCBOR(cw); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.State)); err != nil {
 		return err
 	}
+
 	return nil
 }
 
-func (t *MetadataProposalCbor) UnmarshalCBOR(r io.Reader) (err error) {
-	*t = MetadataProposalCbor{}
+func (t *MigrateInfo) UnmarshalCBOR(r io.Reader) (err error) {
+	*t = MigrateInfo{}
 
 	cr := cbg.NewCborReader(r)
 
@@ -3662,7 +3658,7 @@ func (t *MetadataProposalCbor) UnmarshalCBOR(r io.Reader) (err error) {
 	}
 
 	if extra > cbg.MaxLength {
-		return fmt.Errorf("MetadataProposalCbor: map struct too large (%d)", extra)
+		return fmt.Errorf("MigrateInfo: map struct too large (%d)", extra)
 	}
 
 	var name string
@@ -3680,27 +3676,4018 @@ func (t *MetadataProposalCbor) UnmarshalCBOR(r io.Reader) (err error) {
 		}
 
 		switch name {
-		// t.Proposal (types.QueryProposal) (struct)
-		case "Proposal":
+		// t.DataId (string) (string)
+		case "DataId":
 
 			{
-
-				if err := t.Proposal.UnmarshalCBOR(cr); err != nil {
-					return xerrors.Errorf("unmarshaling t.Proposal: %w", err)
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
 				}
 
+				t.DataId = string(sval)
 			}
-			// t.JwsSignature (types.JwsSignature) (struct)
-		case "JwsSignature":
+			// t.OrderId (uint64) (uint64)
+		case "OrderId":
 
 			{
 
-				if err := t.JwsSignature.UnmarshalCBOR(cr); err != nil {
-					return xerrors.Errorf("unmarshaling t.JwsSignature: %w", err)
+				maj, extra, err = cr.ReadHeader()
+				if err != nil {
+					return err
+				}
+				if maj != cbg.MajUnsignedInt {
+					return fmt.Errorf("wrong type for uint64 field")
 				}
+				t.OrderId = uint64(extra)
 
 			}
-
+			// t.Cid (string) (string)
+		case "Cid":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.Cid = string(sval)
+			}
+			// t.FromProvider (string) (string)
+		case "FromProvider":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.FromProvider = string(sval)
+			}
+			// t.ToProvider (string) (string)
+		case "ToProvider":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.ToProvider = string(sval)
+			}
+			// t.MigrateTxHash (string) (string)
+		case "MigrateTxHash":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.MigrateTxHash = string(sval)
+			}
+			// t.MigrateTxHeight (int64) (int64)
+		case "MigrateTxHeight":
+			{
+				maj, extra, err := cr.ReadHeader()
+				var extraI int64
+				if err != nil {
+					return err
+				}
+				switch maj {
+				case cbg.MajUnsignedInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 positive overflow")
+					}
+				case cbg.MajNegativeInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 negative oveflow")
+					}
+					extraI = -1 - extraI
+				default:
+					return fmt.Errorf("wrong type for int64 field: %d", maj)
+				}
+
+				t.MigrateTxHeight = int64(extraI)
+			}
+			// t.CompleteTxHash (string) (string)
+		case "CompleteTxHash":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.CompleteTxHash = string(sval)
+			}
+			// t.CompleteTxHeight (int64) (int64)
+		case "CompleteTxHeight":
+			{
+				maj, extra, err := cr.ReadHeader()
+				var extraI int64
+				if err != nil {
+					return err
+				}
+				switch maj {
+				case cbg.MajUnsignedInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 positive overflow")
+					}
+				case cbg.MajNegativeInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 negative oveflow")
+					}
+					extraI = -1 - extraI
+				default:
+					return fmt.Errorf("wrong type for int64 field: %d", maj)
+				}
+
+				t.CompleteTxHeight = int64(extraI)
+			}
+			// t.State (types.MigrateState) (uint64)
+		case "State":
+
+			{
+
+				maj, extra, err = cr.ReadHeader()
+				if err != nil {
+					return err
+				}
+				if maj != cbg.MajUnsignedInt {
+					return fmt.Errorf("wrong type for uint64 field")
+				}
+				t.State = MigrateState(extra)
+
+			}
+
+		default:
+			// Field doesn't exist on this type, so ignore it
+			cbg.ScanForLinks(r, func(cid.Cid) {})
+		}
+	}
+
+	return nil
+}
+func (t *MigrateIndex) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+
+	cw := cbg.NewCborWriter(w)
+
+	if _, err := cw.Write([]byte{161}); err != nil {
+		return err
+	}
+
+	// t.All ([]types.MigrateKey) (slice)
+	if len("All") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"All\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("All"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("All")); err != nil {
+		return err
+	}
+
+	if len(t.All) > cbg.MaxLength {
+		return xerrors.Errorf("Slice value in field t.All was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajArray, uint64(len(t.All))); err != nil {
+		return err
+	}
+	for _, v := range t.All {
+		if err := v.MarshalCBOR(cw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *MigrateIndex) UnmarshalCBOR(r io.Reader) (err error) {
+	*t = MigrateIndex{}
+
+	cr := cbg.NewCborReader(r)
+
+	maj, extra, err := cr.ReadHeader()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+	}()
+
+	if maj != cbg.MajMap {
+		return fmt.Errorf("cbor input should be of type map")
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("MigrateIndex: map struct too large (%d)", extra)
+	}
+
+	var name string
+	n := extra
+
+	for i := uint64(0); i < n; i++ {
+
+		{
+			sval, err := cbg.ReadString(cr)
+			if err != nil {
+				return err
+			}
+
+			name = string(sval)
+		}
+
+		switch name {
+		// t.All ([]types.MigrateKey) (slice)
+		case "All":
+
+			maj, extra, err = cr.ReadHeader()
+			if err != nil {
+				return err
+			}
+
+			if extra > cbg.MaxLength {
+				return fmt.Errorf("t.All: array too large (%d)", extra)
+			}
+
+			if maj != cbg.MajArray {
+				return fmt.Errorf("expected cbor array")
+			}
+
+			if extra > 0 {
+				t.All = make([]MigrateKey, extra)
+			}
+
+			for i := 0; i < int(extra); i++ {
+
+				var v MigrateKey
+				if err := v.UnmarshalCBOR(cr); err != nil {
+					return err
+				}
+
+				t.All[i] = v
+			}
+
+		default:
+			// Field doesn't exist on this type, so ignore it
+			cbg.ScanForLinks(r, func(cid.Cid) {})
+		}
+	}
+
+	return nil
+}
+func (t *AuditRecord) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+
+	cw := cbg.NewCborWriter(w)
+
+	if _, err := cw.Write([]byte{170}); err != nil {
+		return err
+	}
+
+	// t.Provider (string) (string)
+	if len("Provider") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Provider\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Provider"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Provider")); err != nil {
+		return err
+	}
+
+	if len(t.Provider) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.Provider was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.Provider))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.Provider)); err != nil {
+		return err
+	}
+
+	// t.DataId (string) (string)
+	if len("DataId") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"DataId\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("DataId"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("DataId")); err != nil {
+		return err
+	}
+
+	if len(t.DataId) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.DataId was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.DataId))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.DataId)); err != nil {
+		return err
+	}
+
+	// t.OrderId (uint64) (uint64)
+	if len("OrderId") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"OrderId\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("OrderId"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("OrderId")); err != nil {
+		return err
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.OrderId)); err != nil {
+		return err
+	}
+
+	// t.ShardId (uint64) (uint64)
+	if len("ShardId") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"ShardId\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("ShardId"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("ShardId")); err != nil {
+		return err
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.ShardId)); err != nil {
+		return err
+	}
+
+	// t.Cid (string) (string)
+	if len("Cid") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Cid\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Cid"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Cid")); err != nil {
+		return err
+	}
+
+	if len(t.Cid) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.Cid was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.Cid))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.Cid)); err != nil {
+		return err
+	}
+
+	// t.Passed (bool) (bool)
+	if len("Passed") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Passed\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Passed"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Passed")); err != nil {
+		return err
+	}
+
+	if err := cbg.WriteBool(w, t.Passed); err != nil {
+		return err
+	}
+
+	// t.Pending (bool) (bool)
+	if len("Pending") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Pending\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Pending"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Pending")); err != nil {
+		return err
+	}
+
+	if err := cbg.WriteBool(w, t.Pending); err != nil {
+		return err
+	}
+
+	// t.Message (string) (string)
+	if len("Message") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Message\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Message"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Message")); err != nil {
+		return err
+	}
+
+	if len(t.Message) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.Message was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.Message))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.Message)); err != nil {
+		return err
+	}
+
+	// t.FaultOpen (bool) (bool)
+	if len("FaultOpen") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"FaultOpen\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("FaultOpen"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("FaultOpen")); err != nil {
+		return err
+	}
+
+	if err := cbg.WriteBool(w, t.FaultOpen); err != nil {
+		return err
+	}
+
+	// t.AuditAt (int64) (int64)
+	if len("AuditAt") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"AuditAt\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("AuditAt"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("AuditAt")); err != nil {
+		return err
+	}
+
+	if t.AuditAt >= 0 {
+		if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.AuditAt)); err != nil {
+			return err
+		}
+	} else {
+		if err := cw.WriteMajorTypeHeader(cbg.MajNegativeInt, uint64(-t.AuditAt-1)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *AuditRecord) UnmarshalCBOR(r io.Reader) (err error) {
+	*t = AuditRecord{}
+
+	cr := cbg.NewCborReader(r)
+
+	maj, extra, err := cr.ReadHeader()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+	}()
+
+	if maj != cbg.MajMap {
+		return fmt.Errorf("cbor input should be of type map")
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("AuditRecord: map struct too large (%d)", extra)
+	}
+
+	var name string
+	n := extra
+
+	for i := uint64(0); i < n; i++ {
+
+		{
+			sval, err := cbg.ReadString(cr)
+			if err != nil {
+				return err
+			}
+
+			name = string(sval)
+		}
+
+		switch name {
+		// t.Provider (string) (string)
+		case "Provider":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.Provider = string(sval)
+			}
+			// t.DataId (string) (string)
+		case "DataId":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.DataId = string(sval)
+			}
+			// t.OrderId (uint64) (uint64)
+		case "OrderId":
+
+			{
+
+				maj, extra, err = cr.ReadHeader()
+				if err != nil {
+					return err
+				}
+				if maj != cbg.MajUnsignedInt {
+					return fmt.Errorf("wrong type for uint64 field")
+				}
+				t.OrderId = uint64(extra)
+
+			}
+			// t.ShardId (uint64) (uint64)
+		case "ShardId":
+
+			{
+
+				maj, extra, err = cr.ReadHeader()
+				if err != nil {
+					return err
+				}
+				if maj != cbg.MajUnsignedInt {
+					return fmt.Errorf("wrong type for uint64 field")
+				}
+				t.ShardId = uint64(extra)
+
+			}
+			// t.Cid (string) (string)
+		case "Cid":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.Cid = string(sval)
+			}
+			// t.Passed (bool) (bool)
+		case "Passed":
+
+			maj, extra, err = cr.ReadHeader()
+			if err != nil {
+				return err
+			}
+			if maj != cbg.MajOther {
+				return fmt.Errorf("booleans must be major type 7")
+			}
+			switch extra {
+			case 20:
+				t.Passed = false
+			case 21:
+				t.Passed = true
+			default:
+				return fmt.Errorf("booleans are either major type 7, value 20 or 21 (got %d)", extra)
+			}
+			// t.Pending (bool) (bool)
+		case "Pending":
+
+			maj, extra, err = cr.ReadHeader()
+			if err != nil {
+				return err
+			}
+			if maj != cbg.MajOther {
+				return fmt.Errorf("booleans must be major type 7")
+			}
+			switch extra {
+			case 20:
+				t.Pending = false
+			case 21:
+				t.Pending = true
+			default:
+				return fmt.Errorf("booleans are either major type 7, value 20 or 21 (got %d)", extra)
+			}
+			// t.Message (string) (string)
+		case "Message":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.Message = string(sval)
+			}
+			// t.FaultOpen (bool) (bool)
+		case "FaultOpen":
+
+			maj, extra, err = cr.ReadHeader()
+			if err != nil {
+				return err
+			}
+			if maj != cbg.MajOther {
+				return fmt.Errorf("booleans must be major type 7")
+			}
+			switch extra {
+			case 20:
+				t.FaultOpen = false
+			case 21:
+				t.FaultOpen = true
+			default:
+				return fmt.Errorf("booleans are either major type 7, value 20 or 21 (got %d)", extra)
+			}
+			// t.AuditAt (int64) (int64)
+		case "AuditAt":
+			{
+				maj, extra, err := cr.ReadHeader()
+				var extraI int64
+				if err != nil {
+					return err
+				}
+				switch maj {
+				case cbg.MajUnsignedInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 positive overflow")
+					}
+				case cbg.MajNegativeInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 negative oveflow")
+					}
+					extraI = -1 - extraI
+				default:
+					return fmt.Errorf("wrong type for int64 field: %d", maj)
+				}
+
+				t.AuditAt = int64(extraI)
+			}
+
+		default:
+			// Field doesn't exist on this type, so ignore it
+			cbg.ScanForLinks(r, func(cid.Cid) {})
+		}
+	}
+
+	return nil
+}
+func (t *AuditHistory) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+
+	cw := cbg.NewCborWriter(w)
+
+	if _, err := cw.Write([]byte{162}); err != nil {
+		return err
+	}
+
+	// t.Provider (string) (string)
+	if len("Provider") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Provider\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Provider"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Provider")); err != nil {
+		return err
+	}
+
+	if len(t.Provider) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.Provider was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.Provider))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.Provider)); err != nil {
+		return err
+	}
+
+	// t.Records ([]types.AuditRecord) (slice)
+	if len("Records") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Records\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Records"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Records")); err != nil {
+		return err
+	}
+
+	if len(t.Records) > cbg.MaxLength {
+		return xerrors.Errorf("Slice value in field t.Records was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajArray, uint64(len(t.Records))); err != nil {
+		return err
+	}
+	for _, v := range t.Records {
+		if err := v.MarshalCBOR(cw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *AuditHistory) UnmarshalCBOR(r io.Reader) (err error) {
+	*t = AuditHistory{}
+
+	cr := cbg.NewCborReader(r)
+
+	maj, extra, err := cr.ReadHeader()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+	}()
+
+	if maj != cbg.MajMap {
+		return fmt.Errorf("cbor input should be of type map")
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("AuditHistory: map struct too large (%d)", extra)
+	}
+
+	var name string
+	n := extra
+
+	for i := uint64(0); i < n; i++ {
+
+		{
+			sval, err := cbg.ReadString(cr)
+			if err != nil {
+				return err
+			}
+
+			name = string(sval)
+		}
+
+		switch name {
+		// t.Provider (string) (string)
+		case "Provider":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.Provider = string(sval)
+			}
+			// t.Records ([]types.AuditRecord) (slice)
+		case "Records":
+
+			maj, extra, err = cr.ReadHeader()
+			if err != nil {
+				return err
+			}
+
+			if extra > cbg.MaxLength {
+				return fmt.Errorf("t.Records: array too large (%d)", extra)
+			}
+
+			if maj != cbg.MajArray {
+				return fmt.Errorf("expected cbor array")
+			}
+
+			if extra > 0 {
+				t.Records = make([]AuditRecord, extra)
+			}
+
+			for i := 0; i < int(extra); i++ {
+
+				var v AuditRecord
+				if err := v.UnmarshalCBOR(cr); err != nil {
+					return err
+				}
+
+				t.Records[i] = v
+			}
+
+		default:
+			// Field doesn't exist on this type, so ignore it
+			cbg.ScanForLinks(r, func(cid.Cid) {})
+		}
+	}
+
+	return nil
+}
+func (t *AuditKey) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+
+	cw := cbg.NewCborWriter(w)
+
+	if _, err := cw.Write([]byte{161}); err != nil {
+		return err
+	}
+
+	// t.Provider (string) (string)
+	if len("Provider") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Provider\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Provider"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Provider")); err != nil {
+		return err
+	}
+
+	if len(t.Provider) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.Provider was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.Provider))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.Provider)); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *AuditKey) UnmarshalCBOR(r io.Reader) (err error) {
+	*t = AuditKey{}
+
+	cr := cbg.NewCborReader(r)
+
+	maj, extra, err := cr.ReadHeader()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+	}()
+
+	if maj != cbg.MajMap {
+		return fmt.Errorf("cbor input should be of type map")
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("AuditKey: map struct too large (%d)", extra)
+	}
+
+	var name string
+	n := extra
+
+	for i := uint64(0); i < n; i++ {
+
+		{
+			sval, err := cbg.ReadString(cr)
+			if err != nil {
+				return err
+			}
+
+			name = string(sval)
+		}
+
+		switch name {
+		// t.Provider (string) (string)
+		case "Provider":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.Provider = string(sval)
+			}
+
+		default:
+			// Field doesn't exist on this type, so ignore it
+			cbg.ScanForLinks(r, func(cid.Cid) {})
+		}
+	}
+
+	return nil
+}
+func (t *AuditIndex) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+
+	cw := cbg.NewCborWriter(w)
+
+	if _, err := cw.Write([]byte{161}); err != nil {
+		return err
+	}
+
+	// t.All ([]types.AuditKey) (slice)
+	if len("All") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"All\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("All"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("All")); err != nil {
+		return err
+	}
+
+	if len(t.All) > cbg.MaxLength {
+		return xerrors.Errorf("Slice value in field t.All was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajArray, uint64(len(t.All))); err != nil {
+		return err
+	}
+	for _, v := range t.All {
+		if err := v.MarshalCBOR(cw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *AuditIndex) UnmarshalCBOR(r io.Reader) (err error) {
+	*t = AuditIndex{}
+
+	cr := cbg.NewCborReader(r)
+
+	maj, extra, err := cr.ReadHeader()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+	}()
+
+	if maj != cbg.MajMap {
+		return fmt.Errorf("cbor input should be of type map")
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("AuditIndex: map struct too large (%d)", extra)
+	}
+
+	var name string
+	n := extra
+
+	for i := uint64(0); i < n; i++ {
+
+		{
+			sval, err := cbg.ReadString(cr)
+			if err != nil {
+				return err
+			}
+
+			name = string(sval)
+		}
+
+		switch name {
+		// t.All ([]types.AuditKey) (slice)
+		case "All":
+
+			maj, extra, err = cr.ReadHeader()
+			if err != nil {
+				return err
+			}
+
+			if extra > cbg.MaxLength {
+				return fmt.Errorf("t.All: array too large (%d)", extra)
+			}
+
+			if maj != cbg.MajArray {
+				return fmt.Errorf("expected cbor array")
+			}
+
+			if extra > 0 {
+				t.All = make([]AuditKey, extra)
+			}
+
+			for i := 0; i < int(extra); i++ {
+
+				var v AuditKey
+				if err := v.UnmarshalCBOR(cr); err != nil {
+					return err
+				}
+
+				t.All[i] = v
+			}
+
+		default:
+			// Field doesn't exist on this type, so ignore it
+			cbg.ScanForLinks(r, func(cid.Cid) {})
+		}
+	}
+
+	return nil
+}
+func (t *IndexJobInfo) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+
+	cw := cbg.NewCborWriter(w)
+
+	if _, err := cw.Write([]byte{170}); err != nil {
+		return err
+	}
+
+	// t.JobId (string) (string)
+	if len("JobId") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"JobId\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("JobId"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("JobId")); err != nil {
+		return err
+	}
+
+	if len(t.JobId) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.JobId was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.JobId))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.JobId)); err != nil {
+		return err
+	}
+
+	// t.JobType (string) (string)
+	if len("JobType") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"JobType\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("JobType"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("JobType")); err != nil {
+		return err
+	}
+
+	if len(t.JobType) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.JobType was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.JobType))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.JobType)); err != nil {
+		return err
+	}
+
+	// t.Param (string) (string)
+	if len("Param") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Param\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Param"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Param")); err != nil {
+		return err
+	}
+
+	if len(t.Param) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.Param was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.Param))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.Param)); err != nil {
+		return err
+	}
+
+	// t.Description (string) (string)
+	if len("Description") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Description\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Description"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Description")); err != nil {
+		return err
+	}
+
+	if len(t.Description) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.Description was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.Description))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.Description)); err != nil {
+		return err
+	}
+
+	// t.Interval (uint64) (uint64)
+	if len("Interval") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Interval\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Interval"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Interval")); err != nil {
+		return err
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.Interval)); err != nil {
+		return err
+	}
+
+	// t.Status (string) (string)
+	if len("Status") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Status\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Status"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Status")); err != nil {
+		return err
+	}
+
+	if len(t.Status) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.Status was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.Status))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.Status)); err != nil {
+		return err
+	}
+
+	// t.CreatedAt (int64) (int64)
+	if len("CreatedAt") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"CreatedAt\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("CreatedAt"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("CreatedAt")); err != nil {
+		return err
+	}
+
+	if t.CreatedAt >= 0 {
+		if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.CreatedAt)); err != nil {
+			return err
+		}
+	} else {
+		if err := cw.WriteMajorTypeHeader(cbg.MajNegativeInt, uint64(-t.CreatedAt-1)); err != nil {
+			return err
+		}
+	}
+
+	// t.LastRunAt (int64) (int64)
+	if len("LastRunAt") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"LastRunAt\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("LastRunAt"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("LastRunAt")); err != nil {
+		return err
+	}
+
+	if t.LastRunAt >= 0 {
+		if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.LastRunAt)); err != nil {
+			return err
+		}
+	} else {
+		if err := cw.WriteMajorTypeHeader(cbg.MajNegativeInt, uint64(-t.LastRunAt-1)); err != nil {
+			return err
+		}
+	}
+
+	// t.LastError (string) (string)
+	if len("LastError") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"LastError\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("LastError"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("LastError")); err != nil {
+		return err
+	}
+
+	if len(t.LastError) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.LastError was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.LastError))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.LastError)); err != nil {
+		return err
+	}
+
+	// t.Retries (uint64) (uint64)
+	if len("Retries") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Retries\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Retries"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Retries")); err != nil {
+		return err
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.Retries)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (t *IndexJobInfo) UnmarshalCBOR(r io.Reader) (err error) {
+	*t = IndexJobInfo{}
+
+	cr := cbg.NewCborReader(r)
+
+	maj, extra, err := cr.ReadHeader()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+	}()
+
+	if maj != cbg.MajMap {
+		return fmt.Errorf("cbor input should be of type map")
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("IndexJobInfo: map struct too large (%d)", extra)
+	}
+
+	var name string
+	n := extra
+
+	for i := uint64(0); i < n; i++ {
+
+		{
+			sval, err := cbg.ReadString(cr)
+			if err != nil {
+				return err
+			}
+
+			name = string(sval)
+		}
+
+		switch name {
+		// t.JobId (string) (string)
+		case "JobId":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.JobId = string(sval)
+			}
+			// t.JobType (string) (string)
+		case "JobType":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.JobType = string(sval)
+			}
+			// t.Param (string) (string)
+		case "Param":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.Param = string(sval)
+			}
+			// t.Description (string) (string)
+		case "Description":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.Description = string(sval)
+			}
+			// t.Interval (uint64) (uint64)
+		case "Interval":
+
+			{
+
+				maj, extra, err = cr.ReadHeader()
+				if err != nil {
+					return err
+				}
+				if maj != cbg.MajUnsignedInt {
+					return fmt.Errorf("wrong type for uint64 field")
+				}
+				t.Interval = uint64(extra)
+
+			}
+			// t.Status (string) (string)
+		case "Status":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.Status = string(sval)
+			}
+			// t.CreatedAt (int64) (int64)
+		case "CreatedAt":
+			{
+				maj, extra, err := cr.ReadHeader()
+				var extraI int64
+				if err != nil {
+					return err
+				}
+				switch maj {
+				case cbg.MajUnsignedInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 positive overflow")
+					}
+				case cbg.MajNegativeInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 negative oveflow")
+					}
+					extraI = -1 - extraI
+				default:
+					return fmt.Errorf("wrong type for int64 field: %d", maj)
+				}
+
+				t.CreatedAt = int64(extraI)
+			}
+			// t.LastRunAt (int64) (int64)
+		case "LastRunAt":
+			{
+				maj, extra, err := cr.ReadHeader()
+				var extraI int64
+				if err != nil {
+					return err
+				}
+				switch maj {
+				case cbg.MajUnsignedInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 positive overflow")
+					}
+				case cbg.MajNegativeInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 negative oveflow")
+					}
+					extraI = -1 - extraI
+				default:
+					return fmt.Errorf("wrong type for int64 field: %d", maj)
+				}
+
+				t.LastRunAt = int64(extraI)
+			}
+			// t.LastError (string) (string)
+		case "LastError":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.LastError = string(sval)
+			}
+			// t.Retries (uint64) (uint64)
+		case "Retries":
+
+			{
+
+				maj, extra, err = cr.ReadHeader()
+				if err != nil {
+					return err
+				}
+				if maj != cbg.MajUnsignedInt {
+					return fmt.Errorf("wrong type for uint64 field")
+				}
+				t.Retries = uint64(extra)
+
+			}
+
+		default:
+			// Field doesn't exist on this type, so ignore it
+			cbg.ScanForLinks(r, func(cid.Cid) {})
+		}
+	}
+
+	return nil
+}
+func (t *IndexJobKey) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+
+	cw := cbg.NewCborWriter(w)
+
+	if _, err := cw.Write([]byte{161}); err != nil {
+		return err
+	}
+
+	// t.JobId (string) (string)
+	if len("JobId") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"JobId\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("JobId"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("JobId")); err != nil {
+		return err
+	}
+
+	if len(t.JobId) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.JobId was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.JobId))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.JobId)); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *IndexJobKey) UnmarshalCBOR(r io.Reader) (err error) {
+	*t = IndexJobKey{}
+
+	cr := cbg.NewCborReader(r)
+
+	maj, extra, err := cr.ReadHeader()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+	}()
+
+	if maj != cbg.MajMap {
+		return fmt.Errorf("cbor input should be of type map")
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("IndexJobKey: map struct too large (%d)", extra)
+	}
+
+	var name string
+	n := extra
+
+	for i := uint64(0); i < n; i++ {
+
+		{
+			sval, err := cbg.ReadString(cr)
+			if err != nil {
+				return err
+			}
+
+			name = string(sval)
+		}
+
+		switch name {
+		// t.JobId (string) (string)
+		case "JobId":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.JobId = string(sval)
+			}
+
+		default:
+			// Field doesn't exist on this type, so ignore it
+			cbg.ScanForLinks(r, func(cid.Cid) {})
+		}
+	}
+
+	return nil
+}
+func (t *IndexJobIndex) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+
+	cw := cbg.NewCborWriter(w)
+
+	if _, err := cw.Write([]byte{161}); err != nil {
+		return err
+	}
+
+	// t.All ([]types.IndexJobKey) (slice)
+	if len("All") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"All\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("All"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("All")); err != nil {
+		return err
+	}
+
+	if len(t.All) > cbg.MaxLength {
+		return xerrors.Errorf("Slice value in field t.All was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajArray, uint64(len(t.All))); err != nil {
+		return err
+	}
+	for _, v := range t.All {
+		if err := v.MarshalCBOR(cw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *IndexJobIndex) UnmarshalCBOR(r io.Reader) (err error) {
+	*t = IndexJobIndex{}
+
+	cr := cbg.NewCborReader(r)
+
+	maj, extra, err := cr.ReadHeader()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+	}()
+
+	if maj != cbg.MajMap {
+		return fmt.Errorf("cbor input should be of type map")
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("IndexJobIndex: map struct too large (%d)", extra)
+	}
+
+	var name string
+	n := extra
+
+	for i := uint64(0); i < n; i++ {
+
+		{
+			sval, err := cbg.ReadString(cr)
+			if err != nil {
+				return err
+			}
+
+			name = string(sval)
+		}
+
+		switch name {
+		// t.All ([]types.IndexJobKey) (slice)
+		case "All":
+
+			maj, extra, err = cr.ReadHeader()
+			if err != nil {
+				return err
+			}
+
+			if extra > cbg.MaxLength {
+				return fmt.Errorf("t.All: array too large (%d)", extra)
+			}
+
+			if maj != cbg.MajArray {
+				return fmt.Errorf("expected cbor array")
+			}
+
+			if extra > 0 {
+				t.All = make([]IndexJobKey, extra)
+			}
+
+			for i := 0; i < int(extra); i++ {
+
+				var v IndexJobKey
+				if err := v.UnmarshalCBOR(cr); err != nil {
+					return err
+				}
+
+				t.All[i] = v
+			}
+
+		default:
+			// Field doesn't exist on this type, so ignore it
+			cbg.ScanForLinks(r, func(cid.Cid) {})
+		}
+	}
+
+	return nil
+}
+func (t *QueryProposal) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+
+	cw := cbg.NewCborWriter(w)
+
+	if _, err := cw.Write([]byte{168}); err != nil {
+		return err
+	}
+
+	// t.Owner (string) (string)
+	if len("Owner") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Owner\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Owner"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Owner")); err != nil {
+		return err
+	}
+
+	if len(t.Owner) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.Owner was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.Owner))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.Owner)); err != nil {
+		return err
+	}
+
+	// t.Keyword (string) (string)
+	if len("Keyword") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Keyword\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Keyword"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Keyword")); err != nil {
+		return err
+	}
+
+	if len(t.Keyword) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.Keyword was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.Keyword))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.Keyword)); err != nil {
+		return err
+	}
+
+	// t.GroupId (string) (string)
+	if len("GroupId") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"GroupId\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("GroupId"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("GroupId")); err != nil {
+		return err
+	}
+
+	if len(t.GroupId) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.GroupId was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.GroupId))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.GroupId)); err != nil {
+		return err
+	}
+
+	// t.KeywordType (uint64) (uint64)
+	if len("KeywordType") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"KeywordType\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("KeywordType"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("KeywordType")); err != nil {
+		return err
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.KeywordType)); err != nil {
+		return err
+	}
+
+	// t.LastValidHeight (uint64) (uint64)
+	if len("LastValidHeight") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"LastValidHeight\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("LastValidHeight"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("LastValidHeight")); err != nil {
+		return err
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.LastValidHeight)); err != nil {
+		return err
+	}
+
+	// t.Gateway (string) (string)
+	if len("Gateway") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Gateway\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Gateway"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Gateway")); err != nil {
+		return err
+	}
+
+	if len(t.Gateway) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.Gateway was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.Gateway))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.Gateway)); err != nil {
+		return err
+	}
+
+	// t.CommitId (string) (string)
+	if len("CommitId") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"CommitId\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("CommitId"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("CommitId")); err != nil {
+		return err
+	}
+
+	if len(t.CommitId) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.CommitId was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.CommitId))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.CommitId)); err != nil {
+		return err
+	}
+
+	// t.Version (string) (string)
+	if len("Version") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Version\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Version"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Version")); err != nil {
+		return err
+	}
+
+	if len(t.Version) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.Version was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.Version))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.Version)); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *QueryProposal) UnmarshalCBOR(r io.Reader) (err error) {
+	*t = QueryProposal{}
+
+	cr := cbg.NewCborReader(r)
+
+	maj, extra, err := cr.ReadHeader()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+	}()
+
+	if maj != cbg.MajMap {
+		return fmt.Errorf("cbor input should be of type map")
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("QueryProposal: map struct too large (%d)", extra)
+	}
+
+	var name string
+	n := extra
+
+	for i := uint64(0); i < n; i++ {
+
+		{
+			sval, err := cbg.ReadString(cr)
+			if err != nil {
+				return err
+			}
+
+			name = string(sval)
+		}
+
+		switch name {
+		// t.Owner (string) (string)
+		case "Owner":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.Owner = string(sval)
+			}
+			// t.Keyword (string) (string)
+		case "Keyword":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.Keyword = string(sval)
+			}
+			// t.GroupId (string) (string)
+		case "GroupId":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.GroupId = string(sval)
+			}
+			// t.KeywordType (uint64) (uint64)
+		case "KeywordType":
+
+			{
+
+				maj, extra, err = cr.ReadHeader()
+				if err != nil {
+					return err
+				}
+				if maj != cbg.MajUnsignedInt {
+					return fmt.Errorf("wrong type for uint64 field")
+				}
+				t.KeywordType = uint64(extra)
+
+			}
+			// t.LastValidHeight (uint64) (uint64)
+		case "LastValidHeight":
+
+			{
+
+				maj, extra, err = cr.ReadHeader()
+				if err != nil {
+					return err
+				}
+				if maj != cbg.MajUnsignedInt {
+					return fmt.Errorf("wrong type for uint64 field")
+				}
+				t.LastValidHeight = uint64(extra)
+
+			}
+			// t.Gateway (string) (string)
+		case "Gateway":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.Gateway = string(sval)
+			}
+			// t.CommitId (string) (string)
+		case "CommitId":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.CommitId = string(sval)
+			}
+			// t.Version (string) (string)
+		case "Version":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.Version = string(sval)
+			}
+
+		default:
+			// Field doesn't exist on this type, so ignore it
+			cbg.ScanForLinks(r, func(cid.Cid) {})
+		}
+	}
+
+	return nil
+}
+func (t *RelayProposal) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+
+	cw := cbg.NewCborWriter(w)
+
+	if _, err := cw.Write([]byte{164}); err != nil {
+		return err
+	}
+
+	// t.NodeAddress (string) (string)
+	if len("NodeAddress") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"NodeAddress\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("NodeAddress"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("NodeAddress")); err != nil {
+		return err
+	}
+
+	if len(t.NodeAddress) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.NodeAddress was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.NodeAddress))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.NodeAddress)); err != nil {
+		return err
+	}
+
+	// t.LocalPeerId (string) (string)
+	if len("LocalPeerId") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"LocalPeerId\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("LocalPeerId"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("LocalPeerId")); err != nil {
+		return err
+	}
+
+	if len(t.LocalPeerId) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.LocalPeerId was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.LocalPeerId))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.LocalPeerId)); err != nil {
+		return err
+	}
+
+	// t.RelayPeerIds (string) (string)
+	if len("RelayPeerIds") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"RelayPeerIds\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("RelayPeerIds"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("RelayPeerIds")); err != nil {
+		return err
+	}
+
+	if len(t.RelayPeerIds) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.RelayPeerIds was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.RelayPeerIds))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.RelayPeerIds)); err != nil {
+		return err
+	}
+
+	// t.TargetPeerInfo (string) (string)
+	if len("TargetPeerInfo") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"TargetPeerInfo\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("TargetPeerInfo"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("TargetPeerInfo")); err != nil {
+		return err
+	}
+
+	if len(t.TargetPeerInfo) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.TargetPeerInfo was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.TargetPeerInfo))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.TargetPeerInfo)); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *RelayProposal) UnmarshalCBOR(r io.Reader) (err error) {
+	*t = RelayProposal{}
+
+	cr := cbg.NewCborReader(r)
+
+	maj, extra, err := cr.ReadHeader()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+	}()
+
+	if maj != cbg.MajMap {
+		return fmt.Errorf("cbor input should be of type map")
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("RelayProposal: map struct too large (%d)", extra)
+	}
+
+	var name string
+	n := extra
+
+	for i := uint64(0); i < n; i++ {
+
+		{
+			sval, err := cbg.ReadString(cr)
+			if err != nil {
+				return err
+			}
+
+			name = string(sval)
+		}
+
+		switch name {
+		// t.NodeAddress (string) (string)
+		case "NodeAddress":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.NodeAddress = string(sval)
+			}
+			// t.LocalPeerId (string) (string)
+		case "LocalPeerId":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.LocalPeerId = string(sval)
+			}
+			// t.RelayPeerIds (string) (string)
+		case "RelayPeerIds":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.RelayPeerIds = string(sval)
+			}
+			// t.TargetPeerInfo (string) (string)
+		case "TargetPeerInfo":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.TargetPeerInfo = string(sval)
+			}
+
+		default:
+			// Field doesn't exist on this type, so ignore it
+			cbg.ScanForLinks(r, func(cid.Cid) {})
+		}
+	}
+
+	return nil
+}
+func (t *JwsSignature) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+
+	cw := cbg.NewCborWriter(w)
+
+	if _, err := cw.Write([]byte{162}); err != nil {
+		return err
+	}
+
+	// t.Protected (string) (string)
+	if len("Protected") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Protected\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Protected"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Protected")); err != nil {
+		return err
+	}
+
+	if len(t.Protected) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.Protected was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.Protected))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.Protected)); err != nil {
+		return err
+	}
+
+	// t.Signature (string) (string)
+	if len("Signature") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Signature\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Signature"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Signature")); err != nil {
+		return err
+	}
+
+	if len(t.Signature) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.Signature was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.Signature))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.Signature)); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *JwsSignature) UnmarshalCBOR(r io.Reader) (err error) {
+	*t = JwsSignature{}
+
+	cr := cbg.NewCborReader(r)
+
+	maj, extra, err := cr.ReadHeader()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+	}()
+
+	if maj != cbg.MajMap {
+		return fmt.Errorf("cbor input should be of type map")
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("JwsSignature: map struct too large (%d)", extra)
+	}
+
+	var name string
+	n := extra
+
+	for i := uint64(0); i < n; i++ {
+
+		{
+			sval, err := cbg.ReadString(cr)
+			if err != nil {
+				return err
+			}
+
+			name = string(sval)
+		}
+
+		switch name {
+		// t.Protected (string) (string)
+		case "Protected":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.Protected = string(sval)
+			}
+			// t.Signature (string) (string)
+		case "Signature":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.Signature = string(sval)
+			}
+
+		default:
+			// Field doesn't exist on this type, so ignore it
+			cbg.ScanForLinks(r, func(cid.Cid) {})
+		}
+	}
+
+	return nil
+}
+func (t *MetadataProposalCbor) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+
+	cw := cbg.NewCborWriter(w)
+
+	if _, err := cw.Write([]byte{162}); err != nil {
+		return err
+	}
+
+	// t.Proposal (types.QueryProposal) (struct)
+	if len("Proposal") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Proposal\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Proposal"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Proposal")); err != nil {
+		return err
+	}
+
+	if err := t.Proposal.MarshalCBOR(cw); err != nil {
+		return err
+	}
+
+	// t.JwsSignature (types.JwsSignature) (struct)
+	if len("JwsSignature") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"JwsSignature\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("JwsSignature"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("JwsSignature")); err != nil {
+		return err
+	}
+
+	if err := t.JwsSignature.MarshalCBOR(cw); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *MetadataProposalCbor) UnmarshalCBOR(r io.Reader) (err error) {
+	*t = MetadataProposalCbor{}
+
+	cr := cbg.NewCborReader(r)
+
+	maj, extra, err := cr.ReadHeader()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+	}()
+
+	if maj != cbg.MajMap {
+		return fmt.Errorf("cbor input should be of type map")
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("MetadataProposalCbor: map struct too large (%d)", extra)
+	}
+
+	var name string
+	n := extra
+
+	for i := uint64(0); i < n; i++ {
+
+		{
+			sval, err := cbg.ReadString(cr)
+			if err != nil {
+				return err
+			}
+
+			name = string(sval)
+		}
+
+		switch name {
+		// t.Proposal (types.QueryProposal) (struct)
+		case "Proposal":
+
+			{
+
+				if err := t.Proposal.UnmarshalCBOR(cr); err != nil {
+					return xerrors.Errorf("unmarshaling t.Proposal: %w", err)
+				}
+
+			}
+			// t.JwsSignature (types.JwsSignature) (struct)
+		case "JwsSignature":
+
+			{
+
+				if err := t.JwsSignature.UnmarshalCBOR(cr); err != nil {
+					return xerrors.Errorf("unmarshaling t.JwsSignature: %w", err)
+				}
+
+			}
+
+		default:
+			// Field doesn't exist on this type, so ignore it
+			cbg.ScanForLinks(r, func(cid.Cid) {})
+		}
+	}
+
+	return nil
+}
+func (t *RelayProposalCbor) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+
+	cw := cbg.NewCborWriter(w)
+
+	if _, err := cw.Write([]byte{162}); err != nil {
+		return err
+	}
+
+	// t.Proposal (types.RelayProposal) (struct)
+	if len("Proposal") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Proposal\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Proposal"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Proposal")); err != nil {
+		return err
+	}
+
+	if err := t.Proposal.MarshalCBOR(cw); err != nil {
+		return err
+	}
+
+	// t.Signature ([]uint8) (slice)
+	if len("Signature") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Signature\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Signature"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Signature")); err != nil {
+		return err
+	}
+
+	if len(t.Signature) > cbg.ByteArrayMaxLen {
+		return xerrors.Errorf("Byte array in field t.Signature was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajByteString, uint64(len(t.Signature))); err != nil {
+		return err
+	}
+
+	if _, err := cw.Write(t.Signature[:]); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *RelayProposalCbor) UnmarshalCBOR(r io.Reader) (err error) {
+	*t = RelayProposalCbor{}
+
+	cr := cbg.NewCborReader(r)
+
+	maj, extra, err := cr.ReadHeader()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+	}()
+
+	if maj != cbg.MajMap {
+		return fmt.Errorf("cbor input should be of type map")
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("RelayProposalCbor: map struct too large (%d)", extra)
+	}
+
+	var name string
+	n := extra
+
+	for i := uint64(0); i < n; i++ {
+
+		{
+			sval, err := cbg.ReadString(cr)
+			if err != nil {
+				return err
+			}
+
+			name = string(sval)
+		}
+
+		switch name {
+		// t.Proposal (types.RelayProposal) (struct)
+		case "Proposal":
+
+			{
+
+				if err := t.Proposal.UnmarshalCBOR(cr); err != nil {
+					return xerrors.Errorf("unmarshaling t.Proposal: %w", err)
+				}
+
+			}
+			// t.Signature ([]uint8) (slice)
+		case "Signature":
+
+			maj, extra, err = cr.ReadHeader()
+			if err != nil {
+				return err
+			}
+
+			if extra > cbg.ByteArrayMaxLen {
+				return fmt.Errorf("t.Signature: byte array too large (%d)", extra)
+			}
+			if maj != cbg.MajByteString {
+				return fmt.Errorf("expected byte array")
+			}
+
+			if extra > 0 {
+				t.Signature = make([]uint8, extra)
+			}
+
+			if _, err := io.ReadFull(cr, t.Signature[:]); err != nil {
+				return err
+			}
+
+		default:
+			// Field doesn't exist on this type, so ignore it
+			cbg.ScanForLinks(r, func(cid.Cid) {})
+		}
+	}
+
+	return nil
+}
+func (t *TraceContext) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+
+	cw := cbg.NewCborWriter(w)
+
+	if _, err := cw.Write([]byte{162}); err != nil {
+		return err
+	}
+
+	// t.TraceParent (string) (string)
+	if len("TraceParent") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"TraceParent\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("TraceParent"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("TraceParent")); err != nil {
+		return err
+	}
+
+	if len(t.TraceParent) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.TraceParent was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.TraceParent))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.TraceParent)); err != nil {
+		return err
+	}
+
+	// t.TraceState (string) (string)
+	if len("TraceState") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"TraceState\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("TraceState"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("TraceState")); err != nil {
+		return err
+	}
+
+	if len(t.TraceState) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.TraceState was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.TraceState))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.TraceState)); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *TraceContext) UnmarshalCBOR(r io.Reader) (err error) {
+	*t = TraceContext{}
+
+	cr := cbg.NewCborReader(r)
+
+	maj, extra, err := cr.ReadHeader()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+	}()
+
+	if maj != cbg.MajMap {
+		return fmt.Errorf("cbor input should be of type map")
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("TraceContext: map struct too large (%d)", extra)
+	}
+
+	var name string
+	n := extra
+
+	for i := uint64(0); i < n; i++ {
+
+		{
+			sval, err := cbg.ReadString(cr)
+			if err != nil {
+				return err
+			}
+
+			name = string(sval)
+		}
+
+		switch name {
+		// t.TraceParent (string) (string)
+		case "TraceParent":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.TraceParent = string(sval)
+			}
+			// t.TraceState (string) (string)
+		case "TraceState":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.TraceState = string(sval)
+			}
+
+		default:
+			// Field doesn't exist on this type, so ignore it
+			cbg.ScanForLinks(r, func(cid.Cid) {})
+		}
+	}
+
+	return nil
+}
+func (t *ShardAssignReq) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+
+	cw := cbg.NewCborWriter(w)
+
+	if _, err := cw.Write([]byte{170}); err != nil {
+		return err
+	}
+
+	// t.OrderId (uint64) (uint64)
+	if len("OrderId") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"OrderId\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("OrderId"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("OrderId")); err != nil {
+		return err
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.OrderId)); err != nil {
+		return err
+	}
+
+	// t.DataId (string) (string)
+	if len("DataId") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"DataId\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("DataId"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("DataId")); err != nil {
+		return err
+	}
+
+	if len(t.DataId) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.DataId was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.DataId))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.DataId)); err != nil {
+		return err
+	}
+
+	// t.Assignee (string) (string)
+	if len("Assignee") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Assignee\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Assignee"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Assignee")); err != nil {
+		return err
+	}
+
+	if len(t.Assignee) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.Assignee was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.Assignee))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.Assignee)); err != nil {
+		return err
+	}
+
+	// t.TxHash (string) (string)
+	if len("TxHash") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"TxHash\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("TxHash"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("TxHash")); err != nil {
+		return err
+	}
+
+	if len(t.TxHash) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.TxHash was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.TxHash))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.TxHash)); err != nil {
+		return err
+	}
+
+	// t.Height (int64) (int64)
+	if len("Height") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Height\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Height"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Height")); err != nil {
+		return err
+	}
+
+	if t.Height >= 0 {
+		if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.Height)); err != nil {
+			return err
+		}
+	} else {
+		if err := cw.WriteMajorTypeHeader(cbg.MajNegativeInt, uint64(-t.Height-1)); err != nil {
+			return err
+		}
+	}
+
+	// t.AssignTxType (types.AssignTxType) (string)
+	if len("AssignTxType") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"AssignTxType\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("AssignTxType"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("AssignTxType")); err != nil {
+		return err
+	}
+
+	if len(t.AssignTxType) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.AssignTxType was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.AssignTxType))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.AssignTxType)); err != nil {
+		return err
+	}
+
+	// t.TimeoutHeight (uint64) (uint64)
+	if len("TimeoutHeight") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"TimeoutHeight\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("TimeoutHeight"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("TimeoutHeight")); err != nil {
+		return err
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.TimeoutHeight)); err != nil {
+		return err
+	}
+
+	// t.ShardCid (string) (string)
+	if len("ShardCid") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"ShardCid\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("ShardCid"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("ShardCid")); err != nil {
+		return err
+	}
+
+	if len(t.ShardCid) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.ShardCid was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.ShardCid))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.ShardCid)); err != nil {
+		return err
+	}
+
+	// t.ShardSize (uint64) (uint64)
+	if len("ShardSize") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"ShardSize\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("ShardSize"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("ShardSize")); err != nil {
+		return err
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.ShardSize)); err != nil {
+		return err
+	}
+
+	// t.TraceContext (types.TraceContext) (struct)
+	if len("TraceContext") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"TraceContext\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("TraceContext"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("TraceContext")); err != nil {
+		return err
+	}
+
+	if err := t.TraceContext.MarshalCBOR(cw); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *ShardAssignReq) UnmarshalCBOR(r io.Reader) (err error) {
+	*t = ShardAssignReq{}
+
+	cr := cbg.NewCborReader(r)
+
+	maj, extra, err := cr.ReadHeader()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+	}()
+
+	if maj != cbg.MajMap {
+		return fmt.Errorf("cbor input should be of type map")
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("ShardAssignReq: map struct too large (%d)", extra)
+	}
+
+	var name string
+	n := extra
+
+	for i := uint64(0); i < n; i++ {
+
+		{
+			sval, err := cbg.ReadString(cr)
+			if err != nil {
+				return err
+			}
+
+			name = string(sval)
+		}
+
+		switch name {
+		// t.OrderId (uint64) (uint64)
+		case "OrderId":
+
+			{
+
+				maj, extra, err = cr.ReadHeader()
+				if err != nil {
+					return err
+				}
+				if maj != cbg.MajUnsignedInt {
+					return fmt.Errorf("wrong type for uint64 field")
+				}
+				t.OrderId = uint64(extra)
+
+			}
+			// t.DataId (string) (string)
+		case "DataId":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.DataId = string(sval)
+			}
+			// t.Assignee (string) (string)
+		case "Assignee":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.Assignee = string(sval)
+			}
+			// t.TxHash (string) (string)
+		case "TxHash":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.TxHash = string(sval)
+			}
+			// t.Height (int64) (int64)
+		case "Height":
+			{
+				maj, extra, err := cr.ReadHeader()
+				var extraI int64
+				if err != nil {
+					return err
+				}
+				switch maj {
+				case cbg.MajUnsignedInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 positive overflow")
+					}
+				case cbg.MajNegativeInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 negative oveflow")
+					}
+					extraI = -1 - extraI
+				default:
+					return fmt.Errorf("wrong type for int64 field: %d", maj)
+				}
+
+				t.Height = int64(extraI)
+			}
+			// t.AssignTxType (types.AssignTxType) (string)
+		case "AssignTxType":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.AssignTxType = AssignTxType(sval)
+			}
+			// t.TimeoutHeight (uint64) (uint64)
+		case "TimeoutHeight":
+
+			{
+
+				maj, extra, err = cr.ReadHeader()
+				if err != nil {
+					return err
+				}
+				if maj != cbg.MajUnsignedInt {
+					return fmt.Errorf("wrong type for uint64 field")
+				}
+				t.TimeoutHeight = uint64(extra)
+
+			}
+			// t.ShardCid (string) (string)
+		case "ShardCid":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.ShardCid = string(sval)
+			}
+			// t.ShardSize (uint64) (uint64)
+		case "ShardSize":
+
+			{
+
+				maj, extra, err = cr.ReadHeader()
+				if err != nil {
+					return err
+				}
+				if maj != cbg.MajUnsignedInt {
+					return fmt.Errorf("wrong type for uint64 field")
+				}
+				t.ShardSize = uint64(extra)
+
+			}
+			// t.TraceContext (types.TraceContext) (struct)
+		case "TraceContext":
+
+			{
+
+				if err := t.TraceContext.UnmarshalCBOR(cr); err != nil {
+					return xerrors.Errorf("unmarshaling t.TraceContext: %w", err)
+				}
+
+			}
+
+		default:
+			// Field doesn't exist on this type, so ignore it
+			cbg.ScanForLinks(r, func(cid.Cid) {})
+		}
+	}
+
+	return nil
+}
+func (t *ShardAssignResp) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+
+	cw := cbg.NewCborWriter(w)
+
+	if _, err := cw.Write([]byte{162}); err != nil {
+		return err
+	}
+
+	// t.Code (uint64) (uint64)
+	if len("Code") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Code\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Code"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Code")); err != nil {
+		return err
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.Code)); err != nil {
+		return err
+	}
+
+	// t.Message (string) (string)
+	if len("Message") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Message\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Message"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Message")); err != nil {
+		return err
+	}
+
+	if len(t.Message) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.Message was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.Message))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.Message)); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *ShardAssignResp) UnmarshalCBOR(r io.Reader) (err error) {
+	*t = ShardAssignResp{}
+
+	cr := cbg.NewCborReader(r)
+
+	maj, extra, err := cr.ReadHeader()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+	}()
+
+	if maj != cbg.MajMap {
+		return fmt.Errorf("cbor input should be of type map")
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("ShardAssignResp: map struct too large (%d)", extra)
+	}
+
+	var name string
+	n := extra
+
+	for i := uint64(0); i < n; i++ {
+
+		{
+			sval, err := cbg.ReadString(cr)
+			if err != nil {
+				return err
+			}
+
+			name = string(sval)
+		}
+
+		switch name {
+		// t.Code (uint64) (uint64)
+		case "Code":
+
+			{
+
+				maj, extra, err = cr.ReadHeader()
+				if err != nil {
+					return err
+				}
+				if maj != cbg.MajUnsignedInt {
+					return fmt.Errorf("wrong type for uint64 field")
+				}
+				t.Code = uint64(extra)
+
+			}
+			// t.Message (string) (string)
+		case "Message":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.Message = string(sval)
+			}
+
+		default:
+			// Field doesn't exist on this type, so ignore it
+			cbg.ScanForLinks(r, func(cid.Cid) {})
+		}
+	}
+
+	return nil
+}
+func (t *ShardCompleteReq) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+
+	cw := cbg.NewCborWriter(w)
+
+	if _, err := cw.Write([]byte{166}); err != nil {
+		return err
+	}
+
+	// t.OrderId (uint64) (uint64)
+	if len("OrderId") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"OrderId\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("OrderId"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("OrderId")); err != nil {
+		return err
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.OrderId)); err != nil {
+		return err
+	}
+
+	// t.DataId (string) (string)
+	if len("DataId") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"DataId\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("DataId"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("DataId")); err != nil {
+		return err
+	}
+
+	if len(t.DataId) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.DataId was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.DataId))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.DataId)); err != nil {
+		return err
+	}
+
+	// t.Cids ([]cid.Cid) (slice)
+	if len("Cids") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Cids\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Cids"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Cids")); err != nil {
+		return err
+	}
+
+	if len(t.Cids) > cbg.MaxLength {
+		return xerrors.Errorf("Slice value in field t.Cids was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajArray, uint64(len(t.Cids))); err != nil {
+		return err
+	}
+	for _, v := range t.Cids {
+		if err := cbg.WriteCid(w, v); err != nil {
+			return xerrors.Errorf("failed writing cid field t.Cids: %w", err)
+		}
+	}
+
+	// t.TxHash (string) (string)
+	if len("TxHash") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"TxHash\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("TxHash"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("TxHash")); err != nil {
+		return err
+	}
+
+	if len(t.TxHash) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.TxHash was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.TxHash))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.TxHash)); err != nil {
+		return err
+	}
+
+	// t.Height (int64) (int64)
+	if len("Height") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Height\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Height"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Height")); err != nil {
+		return err
+	}
+
+	if t.Height >= 0 {
+		if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.Height)); err != nil {
+			return err
+		}
+	} else {
+		if err := cw.WriteMajorTypeHeader(cbg.MajNegativeInt, uint64(-t.Height-1)); err != nil {
+			return err
+		}
+	}
+
+	// t.TraceContext (types.TraceContext) (struct)
+	if len("TraceContext") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"TraceContext\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("TraceContext"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("TraceContext")); err != nil {
+		return err
+	}
+
+	if err := t.TraceContext.MarshalCBOR(cw); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *ShardCompleteReq) UnmarshalCBOR(r io.Reader) (err error) {
+	*t = ShardCompleteReq{}
+
+	cr := cbg.NewCborReader(r)
+
+	maj, extra, err := cr.ReadHeader()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+	}()
+
+	if maj != cbg.MajMap {
+		return fmt.Errorf("cbor input should be of type map")
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("ShardCompleteReq: map struct too large (%d)", extra)
+	}
+
+	var name string
+	n := extra
+
+	for i := uint64(0); i < n; i++ {
+
+		{
+			sval, err := cbg.ReadString(cr)
+			if err != nil {
+				return err
+			}
+
+			name = string(sval)
+		}
+
+		switch name {
+		// t.OrderId (uint64) (uint64)
+		case "OrderId":
+
+			{
+
+				maj, extra, err = cr.ReadHeader()
+				if err != nil {
+					return err
+				}
+				if maj != cbg.MajUnsignedInt {
+					return fmt.Errorf("wrong type for uint64 field")
+				}
+				t.OrderId = uint64(extra)
+
+			}
+			// t.DataId (string) (string)
+		case "DataId":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.DataId = string(sval)
+			}
+			// t.Cids ([]cid.Cid) (slice)
+		case "Cids":
+
+			maj, extra, err = cr.ReadHeader()
+			if err != nil {
+				return err
+			}
+
+			if extra > cbg.MaxLength {
+				return fmt.Errorf("t.Cids: array too large (%d)", extra)
+			}
+
+			if maj != cbg.MajArray {
+				return fmt.Errorf("expected cbor array")
+			}
+
+			if extra > 0 {
+				t.Cids = make([]cid.Cid, extra)
+			}
+
+			for i := 0; i < int(extra); i++ {
+
+				c, err := cbg.ReadCid(cr)
+				if err != nil {
+					return xerrors.Errorf("reading cid field t.Cids failed: %w", err)
+				}
+				t.Cids[i] = c
+			}
+
+			// t.TxHash (string) (string)
+		case "TxHash":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.TxHash = string(sval)
+			}
+			// t.Height (int64) (int64)
+		case "Height":
+			{
+				maj, extra, err := cr.ReadHeader()
+				var extraI int64
+				if err != nil {
+					return err
+				}
+				switch maj {
+				case cbg.MajUnsignedInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 positive overflow")
+					}
+				case cbg.MajNegativeInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 negative oveflow")
+					}
+					extraI = -1 - extraI
+				default:
+					return fmt.Errorf("wrong type for int64 field: %d", maj)
+				}
+
+				t.Height = int64(extraI)
+			}
+			// t.TraceContext (types.TraceContext) (struct)
+		case "TraceContext":
+
+			{
+
+				if err := t.TraceContext.UnmarshalCBOR(cr); err != nil {
+					return xerrors.Errorf("unmarshaling t.TraceContext: %w", err)
+				}
+
+			}
+
+		default:
+			// Field doesn't exist on this type, so ignore it
+			cbg.ScanForLinks(r, func(cid.Cid) {})
+		}
+	}
+
+	return nil
+}
+func (t *ShardCompleteResp) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+
+	cw := cbg.NewCborWriter(w)
+
+	if _, err := cw.Write([]byte{163}); err != nil {
+		return err
+	}
+
+	// t.Code (uint64) (uint64)
+	if len("Code") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Code\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Code"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Code")); err != nil {
+		return err
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.Code)); err != nil {
+		return err
+	}
+
+	// t.Message (string) (string)
+	if len("Message") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Message\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Message"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Message")); err != nil {
+		return err
+	}
+
+	if len(t.Message) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.Message was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.Message))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.Message)); err != nil {
+		return err
+	}
+
+	// t.Recoverable (bool) (bool)
+	if len("Recoverable") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Recoverable\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Recoverable"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Recoverable")); err != nil {
+		return err
+	}
+
+	if err := cbg.WriteBool(w, t.Recoverable); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *ShardCompleteResp) UnmarshalCBOR(r io.Reader) (err error) {
+	*t = ShardCompleteResp{}
+
+	cr := cbg.NewCborReader(r)
+
+	maj, extra, err := cr.ReadHeader()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+	}()
+
+	if maj != cbg.MajMap {
+		return fmt.Errorf("cbor input should be of type map")
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("ShardCompleteResp: map struct too large (%d)", extra)
+	}
+
+	var name string
+	n := extra
+
+	for i := uint64(0); i < n; i++ {
+
+		{
+			sval, err := cbg.ReadString(cr)
+			if err != nil {
+				return err
+			}
+
+			name = string(sval)
+		}
+
+		switch name {
+		// t.Code (uint64) (uint64)
+		case "Code":
+
+			{
+
+				maj, extra, err = cr.ReadHeader()
+				if err != nil {
+					return err
+				}
+				if maj != cbg.MajUnsignedInt {
+					return fmt.Errorf("wrong type for uint64 field")
+				}
+				t.Code = uint64(extra)
+
+			}
+			// t.Message (string) (string)
+		case "Message":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.Message = string(sval)
+			}
+			// t.Recoverable (bool) (bool)
+		case "Recoverable":
+
+			maj, extra, err = cr.ReadHeader()
+			if err != nil {
+				return err
+			}
+			if maj != cbg.MajOther {
+				return fmt.Errorf("booleans must be major type 7")
+			}
+			switch extra {
+			case 20:
+				t.Recoverable = false
+			case 21:
+				t.Recoverable = true
+			default:
+				return fmt.Errorf("booleans are either major type 7, value 20 or 21 (got %d)", extra)
+			}
+
+		default:
+			// Field doesn't exist on this type, so ignore it
+			cbg.ScanForLinks(r, func(cid.Cid) {})
+		}
+	}
+
+	return nil
+}
+func (t *ShardLoadReq) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+
+	cw := cbg.NewCborWriter(w)
+
+	if _, err := cw.Write([]byte{169}); err != nil {
+		return err
+	}
+
+	// t.Owner (string) (string)
+	if len("Owner") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Owner\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Owner"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Owner")); err != nil {
+		return err
+	}
+
+	if len(t.Owner) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.Owner was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.Owner))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.Owner)); err != nil {
+		return err
+	}
+
+	// t.DataId (string) (string)
+	if len("DataId") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"DataId\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("DataId"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("DataId")); err != nil {
+		return err
+	}
+
+	if len(t.DataId) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.DataId was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.DataId))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.DataId)); err != nil {
+		return err
+	}
+
+	// t.OrderId (uint64) (uint64)
+	if len("OrderId") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"OrderId\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("OrderId"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("OrderId")); err != nil {
+		return err
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.OrderId)); err != nil {
+		return err
+	}
+
+	// t.Cid (cid.Cid) (struct)
+	if len("Cid") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Cid\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Cid"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Cid")); err != nil {
+		return err
+	}
+
+	if err := cbg.WriteCid(cw, t.Cid); err != nil {
+		return xerrors.Errorf("failed to write cid field t.Cid: %w", err)
+	}
+
+	// t.Proposal (types.MetadataProposalCbor) (struct)
+	if len("Proposal") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Proposal\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Proposal"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Proposal")); err != nil {
+		return err
+	}
+
+	if err := t.Proposal.MarshalCBOR(cw); err != nil {
+		return err
+	}
+
+	// t.RequestId (int64) (int64)
+	if len("RequestId") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"RequestId\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("RequestId"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("RequestId")); err != nil {
+		return err
+	}
+
+	if t.RequestId >= 0 {
+		if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.RequestId)); err != nil {
+			return err
+		}
+	} else {
+		if err := cw.WriteMajorTypeHeader(cbg.MajNegativeInt, uint64(-t.RequestId-1)); err != nil {
+			return err
+		}
+	}
+
+	// t.RelayProposal (types.RelayProposalCbor) (struct)
+	if len("RelayProposal") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"RelayProposal\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("RelayProposal"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("RelayProposal")); err != nil {
+		return err
+	}
+
+	if err := t.RelayProposal.MarshalCBOR(cw); err != nil {
+		return err
+	}
+
+	// t.Offset (uint64) (uint64)
+	if len("Offset") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Offset\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Offset"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Offset")); err != nil {
+		return err
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.Offset)); err != nil {
+		return err
+	}
+
+	// t.TraceContext (types.TraceContext) (struct)
+	if len("TraceContext") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"TraceContext\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("TraceContext"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("TraceContext")); err != nil {
+		return err
+	}
+
+	if err := t.TraceContext.MarshalCBOR(cw); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *ShardLoadReq) UnmarshalCBOR(r io.Reader) (err error) {
+	*t = ShardLoadReq{}
+
+	cr := cbg.NewCborReader(r)
+
+	maj, extra, err := cr.ReadHeader()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+	}()
+
+	if maj != cbg.MajMap {
+		return fmt.Errorf("cbor input should be of type map")
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("ShardLoadReq: map struct too large (%d)", extra)
+	}
+
+	var name string
+	n := extra
+
+	for i := uint64(0); i < n; i++ {
+
+		{
+			sval, err := cbg.ReadString(cr)
+			if err != nil {
+				return err
+			}
+
+			name = string(sval)
+		}
+
+		switch name {
+		// t.Owner (string) (string)
+		case "Owner":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.Owner = string(sval)
+			}
+			// t.DataId (string) (string)
+		case "DataId":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.DataId = string(sval)
+			}
+			// t.OrderId (uint64) (uint64)
+		case "OrderId":
+
+			{
+
+				maj, extra, err = cr.ReadHeader()
+				if err != nil {
+					return err
+				}
+				if maj != cbg.MajUnsignedInt {
+					return fmt.Errorf("wrong type for uint64 field")
+				}
+				t.OrderId = uint64(extra)
+
+			}
+			// t.Cid (cid.Cid) (struct)
+		case "Cid":
+
+			{
+
+				c, err := cbg.ReadCid(cr)
+				if err != nil {
+					return xerrors.Errorf("failed to read cid field t.Cid: %w", err)
+				}
+
+				t.Cid = c
+
+			}
+			// t.Proposal (types.MetadataProposalCbor) (struct)
+		case "Proposal":
+
+			{
+
+				if err := t.Proposal.UnmarshalCBOR(cr); err != nil {
+					return xerrors.Errorf("unmarshaling t.Proposal: %w", err)
+				}
+
+			}
+			// t.RequestId (int64) (int64)
+		case "RequestId":
+			{
+				maj, extra, err := cr.ReadHeader()
+				var extraI int64
+				if err != nil {
+					return err
+				}
+				switch maj {
+				case cbg.MajUnsignedInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 positive overflow")
+					}
+				case cbg.MajNegativeInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 negative oveflow")
+					}
+					extraI = -1 - extraI
+				default:
+					return fmt.Errorf("wrong type for int64 field: %d", maj)
+				}
+
+				t.RequestId = int64(extraI)
+			}
+			// t.RelayProposal (types.RelayProposalCbor) (struct)
+		case "RelayProposal":
+
+			{
+
+				if err := t.RelayProposal.UnmarshalCBOR(cr); err != nil {
+					return xerrors.Errorf("unmarshaling t.RelayProposal: %w", err)
+				}
+
+			}
+			// t.Offset (uint64) (uint64)
+		case "Offset":
+
+			{
+
+				maj, extra, err = cr.ReadHeader()
+				if err != nil {
+					return err
+				}
+				if maj != cbg.MajUnsignedInt {
+					return fmt.Errorf("wrong type for uint64 field")
+				}
+				t.Offset = uint64(extra)
+
+			}
+			// t.TraceContext (types.TraceContext) (struct)
+		case "TraceContext":
+
+			{
+
+				if err := t.TraceContext.UnmarshalCBOR(cr); err != nil {
+					return xerrors.Errorf("unmarshaling t.TraceContext: %w", err)
+				}
+
+			}
+
 		default:
 			// Field doesn't exist on this type, so ignore it
 			cbg.ScanForLinks(r, func(cid.Cid) {})
@@ -3709,7 +7696,7 @@ func (t *MetadataProposalCbor) UnmarshalCBOR(r io.Reader) (err error) {
 
 	return nil
 }
-func (t *RelayProposalCbor) MarshalCBOR(w io.Writer) error {
+func (t *ShardLoadResp) MarshalCBOR(w io.Writer) error {
 	if t == nil {
 		_, err := w.Write(cbg.CborNull)
 		return err
@@ -3717,54 +7704,153 @@ func (t *RelayProposalCbor) MarshalCBOR(w io.Writer) error {
 
 	cw := cbg.NewCborWriter(w)
 
-	if _, err := cw.Write([]byte{162}); err != nil {
+	if _, err := cw.Write([]byte{167}); err != nil {
 		return err
 	}
 
-	// t.Proposal (types.RelayProposal) (struct)
-	if len("Proposal") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"Proposal\" was too long")
+	// t.Code (uint64) (uint64)
+	if len("Code") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Code\" was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Proposal"))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Code"))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string("Proposal")); err != nil {
+	if _, err := io.WriteString(w, string("Code")); err != nil {
 		return err
 	}
 
-	if err := t.Proposal.MarshalCBOR(cw); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.Code)); err != nil {
 		return err
 	}
 
-	// t.Signature ([]uint8) (slice)
-	if len("Signature") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"Signature\" was too long")
+	// t.Message (string) (string)
+	if len("Message") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Message\" was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Signature"))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Message"))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string("Signature")); err != nil {
+	if _, err := io.WriteString(w, string("Message")); err != nil {
 		return err
 	}
 
-	if len(t.Signature) > cbg.ByteArrayMaxLen {
-		return xerrors.Errorf("Byte array in field t.Signature was too long")
+	if len(t.Message) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.Message was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajByteString, uint64(len(t.Signature))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.Message))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.Message)); err != nil {
 		return err
 	}
 
-	if _, err := cw.Write(t.Signature[:]); err != nil {
+	// t.OrderId (uint64) (uint64)
+	if len("OrderId") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"OrderId\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("OrderId"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("OrderId")); err != nil {
+		return err
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.OrderId)); err != nil {
+		return err
+	}
+
+	// t.Cid (cid.Cid) (struct)
+	if len("Cid") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Cid\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Cid"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Cid")); err != nil {
+		return err
+	}
+
+	if err := cbg.WriteCid(cw, t.Cid); err != nil {
+		return xerrors.Errorf("failed to write cid field t.Cid: %w", err)
+	}
+
+	// t.Content ([]uint8) (slice)
+	if len("Content") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Content\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Content"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Content")); err != nil {
+		return err
+	}
+
+	if len(t.Content) > cbg.ByteArrayMaxLen {
+		return xerrors.Errorf("Byte array in field t.Content was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajByteString, uint64(len(t.Content))); err != nil {
+		return err
+	}
+
+	if _, err := cw.Write(t.Content[:]); err != nil {
+		return err
+	}
+
+	// t.RequestId (int64) (int64)
+	if len("RequestId") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"RequestId\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("RequestId"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("RequestId")); err != nil {
+		return err
+	}
+
+	if t.RequestId >= 0 {
+		if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.RequestId)); err != nil {
+			return err
+		}
+	} else {
+		if err := cw.WriteMajorTypeHeader(cbg.MajNegativeInt, uint64(-t.RequestId-1)); err != nil {
+			return err
+		}
+	}
+
+	// t.ResponseId (int64) (int64)
+	if len("ResponseId") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"ResponseId\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("ResponseId"))); err != nil {
 		return err
 	}
+	if _, err := io.WriteString(w, string("ResponseId")); err != nil {
+		return err
+	}
+
+	if t.ResponseId >= 0 {
+		if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.ResponseId)); err != nil {
+			return err
+		}
+	} else {
+		if err := cw.WriteMajorTypeHeader(cbg.MajNegativeInt, uint64(-t.ResponseId-1)); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func (t *RelayProposalCbor) UnmarshalCBOR(r io.Reader) (err error) {
-	*t = RelayProposalCbor{}
+func (t *ShardLoadResp) UnmarshalCBOR(r io.Reader) (err error) {
+	*t = ShardLoadResp{}
 
 	cr := cbg.NewCborReader(r)
 
@@ -3783,7 +7869,7 @@ func (t *RelayProposalCbor) UnmarshalCBOR(r io.Reader) (err error) {
 	}
 
 	if extra > cbg.MaxLength {
-		return fmt.Errorf("RelayProposalCbor: map struct too large (%d)", extra)
+		return fmt.Errorf("ShardLoadResp: map struct too large (%d)", extra)
 	}
 
 	var name string
@@ -3801,18 +7887,62 @@ func (t *RelayProposalCbor) UnmarshalCBOR(r io.Reader) (err error) {
 		}
 
 		switch name {
-		// t.Proposal (types.RelayProposal) (struct)
-		case "Proposal":
+		// t.Code (uint64) (uint64)
+		case "Code":
 
 			{
 
-				if err := t.Proposal.UnmarshalCBOR(cr); err != nil {
-					return xerrors.Errorf("unmarshaling t.Proposal: %w", err)
+				maj, extra, err = cr.ReadHeader()
+				if err != nil {
+					return err
 				}
+				if maj != cbg.MajUnsignedInt {
+					return fmt.Errorf("wrong type for uint64 field")
+				}
+				t.Code = uint64(extra)
 
 			}
-			// t.Signature ([]uint8) (slice)
-		case "Signature":
+			// t.Message (string) (string)
+		case "Message":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.Message = string(sval)
+			}
+			// t.OrderId (uint64) (uint64)
+		case "OrderId":
+
+			{
+
+				maj, extra, err = cr.ReadHeader()
+				if err != nil {
+					return err
+				}
+				if maj != cbg.MajUnsignedInt {
+					return fmt.Errorf("wrong type for uint64 field")
+				}
+				t.OrderId = uint64(extra)
+
+			}
+			// t.Cid (cid.Cid) (struct)
+		case "Cid":
+
+			{
+
+				c, err := cbg.ReadCid(cr)
+				if err != nil {
+					return xerrors.Errorf("failed to read cid field t.Cid: %w", err)
+				}
+
+				t.Cid = c
+
+			}
+			// t.Content ([]uint8) (slice)
+		case "Content":
 
 			maj, extra, err = cr.ReadHeader()
 			if err != nil {
@@ -3820,19 +7950,71 @@ func (t *RelayProposalCbor) UnmarshalCBOR(r io.Reader) (err error) {
 			}
 
 			if extra > cbg.ByteArrayMaxLen {
-				return fmt.Errorf("t.Signature: byte array too large (%d)", extra)
+				return fmt.Errorf("t.Content: byte array too large (%d)", extra)
 			}
 			if maj != cbg.MajByteString {
 				return fmt.Errorf("expected byte array")
 			}
 
 			if extra > 0 {
-				t.Signature = make([]uint8, extra)
+				t.Content = make([]uint8, extra)
 			}
 
-			if _, err := io.ReadFull(cr, t.Signature[:]); err != nil {
+			if _, err := io.ReadFull(cr, t.Content[:]); err != nil {
 				return err
 			}
+			// t.RequestId (int64) (int64)
+		case "RequestId":
+			{
+				maj, extra, err := cr.ReadHeader()
+				var extraI int64
+				if err != nil {
+					return err
+				}
+				switch maj {
+				case cbg.MajUnsignedInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 positive overflow")
+					}
+				case cbg.MajNegativeInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 negative oveflow")
+					}
+					extraI = -1 - extraI
+				default:
+					return fmt.Errorf("wrong type for int64 field: %d", maj)
+				}
+
+				t.RequestId = int64(extraI)
+			}
+			// t.ResponseId (int64) (int64)
+		case "ResponseId":
+			{
+				maj, extra, err := cr.ReadHeader()
+				var extraI int64
+				if err != nil {
+					return err
+				}
+				switch maj {
+				case cbg.MajUnsignedInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 positive overflow")
+					}
+				case cbg.MajNegativeInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 negative oveflow")
+					}
+					extraI = -1 - extraI
+				default:
+					return fmt.Errorf("wrong type for int64 field: %d", maj)
+				}
+
+				t.ResponseId = int64(extraI)
+			}
 
 		default:
 			// Field doesn't exist on this type, so ignore it
@@ -3842,7 +8024,7 @@ func (t *RelayProposalCbor) UnmarshalCBOR(r io.Reader) (err error) {
 
 	return nil
 }
-func (t *ShardAssignReq) MarshalCBOR(w io.Writer) error {
+func (t *ShardMigrateReq) MarshalCBOR(w io.Writer) error {
 	if t == nil {
 		_, err := w.Write(cbg.CborNull)
 		return err
@@ -3850,7 +8032,30 @@ func (t *ShardAssignReq) MarshalCBOR(w io.Writer) error {
 
 	cw := cbg.NewCborWriter(w)
 
-	if _, err := cw.Write([]byte{167}); err != nil {
+	if _, err := cw.Write([]byte{168}); err != nil {
+		return err
+	}
+
+	// t.MigrateFrom (string) (string)
+	if len("MigrateFrom") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"MigrateFrom\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("MigrateFrom"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("MigrateFrom")); err != nil {
+		return err
+	}
+
+	if len(t.MigrateFrom) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.MigrateFrom was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.MigrateFrom))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.MigrateFrom)); err != nil {
 		return err
 	}
 
@@ -3893,29 +8098,6 @@ func (t *ShardAssignReq) MarshalCBOR(w io.Writer) error {
 		return err
 	}
 
-	// t.Assignee (string) (string)
-	if len("Assignee") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"Assignee\" was too long")
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Assignee"))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string("Assignee")); err != nil {
-		return err
-	}
-
-	if len(t.Assignee) > cbg.MaxLength {
-		return xerrors.Errorf("Value in field t.Assignee was too long")
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.Assignee))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string(t.Assignee)); err != nil {
-		return err
-	}
-
 	// t.TxHash (string) (string)
 	if len("TxHash") > cbg.MaxLength {
 		return xerrors.Errorf("Value in field \"TxHash\" was too long")
@@ -3939,72 +8121,96 @@ func (t *ShardAssignReq) MarshalCBOR(w io.Writer) error {
 		return err
 	}
 
-	// t.Height (int64) (int64)
-	if len("Height") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"Height\" was too long")
+	// t.TxHeight (int64) (int64)
+	if len("TxHeight") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"TxHeight\" was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Height"))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("TxHeight"))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string("Height")); err != nil {
+	if _, err := io.WriteString(w, string("TxHeight")); err != nil {
 		return err
 	}
 
-	if t.Height >= 0 {
-		if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.Height)); err != nil {
+	if t.TxHeight >= 0 {
+		if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.TxHeight)); err != nil {
 			return err
 		}
 	} else {
-		if err := cw.WriteMajorTypeHeader(cbg.MajNegativeInt, uint64(-t.Height-1)); err != nil {
+		if err := cw.WriteMajorTypeHeader(cbg.MajNegativeInt, uint64(-t.TxHeight-1)); err != nil {
 			return err
 		}
 	}
 
-	// t.AssignTxType (types.AssignTxType) (string)
-	if len("AssignTxType") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"AssignTxType\" was too long")
+	// t.Cid (string) (string)
+	if len("Cid") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Cid\" was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("AssignTxType"))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Cid"))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string("AssignTxType")); err != nil {
+	if _, err := io.WriteString(w, string("Cid")); err != nil {
 		return err
 	}
 
-	if len(t.AssignTxType) > cbg.MaxLength {
-		return xerrors.Errorf("Value in field t.AssignTxType was too long")
+	if len(t.Cid) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.Cid was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.AssignTxType))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.Cid))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string(t.AssignTxType)); err != nil {
+	if _, err := io.WriteString(w, string(t.Cid)); err != nil {
 		return err
 	}
 
-	// t.TimeoutHeight (uint64) (uint64)
-	if len("TimeoutHeight") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"TimeoutHeight\" was too long")
+	// t.Content ([]uint8) (slice)
+	if len("Content") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Content\" was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("TimeoutHeight"))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Content"))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string("TimeoutHeight")); err != nil {
+	if _, err := io.WriteString(w, string("Content")); err != nil {
 		return err
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.TimeoutHeight)); err != nil {
+	if len(t.Content) > cbg.ByteArrayMaxLen {
+		return xerrors.Errorf("Byte array in field t.Content was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajByteString, uint64(len(t.Content))); err != nil {
+		return err
+	}
+
+	if _, err := cw.Write(t.Content[:]); err != nil {
+		return err
+	}
+
+	// t.Size (uint64) (uint64)
+	if len("Size") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Size\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Size"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Size")); err != nil {
+		return err
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.Size)); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (t *ShardAssignReq) UnmarshalCBOR(r io.Reader) (err error) {
-	*t = ShardAssignReq{}
+func (t *ShardMigrateReq) UnmarshalCBOR(r io.Reader) (err error) {
+	*t = ShardMigrateReq{}
 
 	cr := cbg.NewCborReader(r)
 
@@ -4023,7 +8229,7 @@ func (t *ShardAssignReq) UnmarshalCBOR(r io.Reader) (err error) {
 	}
 
 	if extra > cbg.MaxLength {
-		return fmt.Errorf("ShardAssignReq: map struct too large (%d)", extra)
+		return fmt.Errorf("ShardMigrateReq: map struct too large (%d)", extra)
 	}
 
 	var name string
@@ -4040,8 +8246,19 @@ func (t *ShardAssignReq) UnmarshalCBOR(r io.Reader) (err error) {
 			name = string(sval)
 		}
 
-		switch name {
-		// t.OrderId (uint64) (uint64)
+		switch name {
+		// t.MigrateFrom (string) (string)
+		case "MigrateFrom":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.MigrateFrom = string(sval)
+			}
+			// t.OrderId (uint64) (uint64)
 		case "OrderId":
 
 			{
@@ -4067,17 +8284,6 @@ func (t *ShardAssignReq) UnmarshalCBOR(r io.Reader) (err error) {
 
 				t.DataId = string(sval)
 			}
-			// t.Assignee (string) (string)
-		case "Assignee":
-
-			{
-				sval, err := cbg.ReadString(cr)
-				if err != nil {
-					return err
-				}
-
-				t.Assignee = string(sval)
-			}
 			// t.TxHash (string) (string)
 		case "TxHash":
 
@@ -4089,8 +8295,8 @@ func (t *ShardAssignReq) UnmarshalCBOR(r io.Reader) (err error) {
 
 				t.TxHash = string(sval)
 			}
-			// t.Height (int64) (int64)
-		case "Height":
+			// t.TxHeight (int64) (int64)
+		case "TxHeight":
 			{
 				maj, extra, err := cr.ReadHeader()
 				var extraI int64
@@ -4113,10 +8319,10 @@ func (t *ShardAssignReq) UnmarshalCBOR(r io.Reader) (err error) {
 					return fmt.Errorf("wrong type for int64 field: %d", maj)
 				}
 
-				t.Height = int64(extraI)
+				t.TxHeight = int64(extraI)
 			}
-			// t.AssignTxType (types.AssignTxType) (string)
-		case "AssignTxType":
+			// t.Cid (string) (string)
+		case "Cid":
 
 			{
 				sval, err := cbg.ReadString(cr)
@@ -4124,10 +8330,32 @@ func (t *ShardAssignReq) UnmarshalCBOR(r io.Reader) (err error) {
 					return err
 				}
 
-				t.AssignTxType = AssignTxType(sval)
+				t.Cid = string(sval)
 			}
-			// t.TimeoutHeight (uint64) (uint64)
-		case "TimeoutHeight":
+			// t.Content ([]uint8) (slice)
+		case "Content":
+
+			maj, extra, err = cr.ReadHeader()
+			if err != nil {
+				return err
+			}
+
+			if extra > cbg.ByteArrayMaxLen {
+				return fmt.Errorf("t.Content: byte array too large (%d)", extra)
+			}
+			if maj != cbg.MajByteString {
+				return fmt.Errorf("expected byte array")
+			}
+
+			if extra > 0 {
+				t.Content = make([]uint8, extra)
+			}
+
+			if _, err := io.ReadFull(cr, t.Content[:]); err != nil {
+				return err
+			}
+			// t.Size (uint64) (uint64)
+		case "Size":
 
 			{
 
@@ -4138,7 +8366,7 @@ func (t *ShardAssignReq) UnmarshalCBOR(r io.Reader) (err error) {
 				if maj != cbg.MajUnsignedInt {
 					return fmt.Errorf("wrong type for uint64 field")
 				}
-				t.TimeoutHeight = uint64(extra)
+				t.Size = uint64(extra)
 
 			}
 
@@ -4150,7 +8378,7 @@ func (t *ShardAssignReq) UnmarshalCBOR(r io.Reader) (err error) {
 
 	return nil
 }
-func (t *ShardAssignResp) MarshalCBOR(w io.Writer) error {
+func (t *ShardMigrateResp) MarshalCBOR(w io.Writer) error {
 	if t == nil {
 		_, err := w.Write(cbg.CborNull)
 		return err
@@ -4158,7 +8386,7 @@ func (t *ShardAssignResp) MarshalCBOR(w io.Writer) error {
 
 	cw := cbg.NewCborWriter(w)
 
-	if _, err := cw.Write([]byte{162}); err != nil {
+	if _, err := cw.Write([]byte{164}); err != nil {
 		return err
 	}
 
@@ -4200,11 +8428,56 @@ func (t *ShardAssignResp) MarshalCBOR(w io.Writer) error {
 	if _, err := io.WriteString(w, string(t.Message)); err != nil {
 		return err
 	}
+
+	// t.CompleteHash (string) (string)
+	if len("CompleteHash") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"CompleteHash\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("CompleteHash"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("CompleteHash")); err != nil {
+		return err
+	}
+
+	if len(t.CompleteHash) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.CompleteHash was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.CompleteHash))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.CompleteHash)); err != nil {
+		return err
+	}
+
+	// t.CompleteHeight (int64) (int64)
+	if len("CompleteHeight") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"CompleteHeight\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("CompleteHeight"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("CompleteHeight")); err != nil {
+		return err
+	}
+
+	if t.CompleteHeight >= 0 {
+		if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.CompleteHeight)); err != nil {
+			return err
+		}
+	} else {
+		if err := cw.WriteMajorTypeHeader(cbg.MajNegativeInt, uint64(-t.CompleteHeight-1)); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func (t *ShardAssignResp) UnmarshalCBOR(r io.Reader) (err error) {
-	*t = ShardAssignResp{}
+func (t *ShardMigrateResp) UnmarshalCBOR(r io.Reader) (err error) {
+	*t = ShardMigrateResp{}
 
 	cr := cbg.NewCborReader(r)
 
@@ -4223,7 +8496,7 @@ func (t *ShardAssignResp) UnmarshalCBOR(r io.Reader) (err error) {
 	}
 
 	if extra > cbg.MaxLength {
-		return fmt.Errorf("ShardAssignResp: map struct too large (%d)", extra)
+		return fmt.Errorf("ShardMigrateResp: map struct too large (%d)", extra)
 	}
 
 	var name string
@@ -4267,6 +8540,43 @@ func (t *ShardAssignResp) UnmarshalCBOR(r io.Reader) (err error) {
 
 				t.Message = string(sval)
 			}
+			// t.CompleteHash (string) (string)
+		case "CompleteHash":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.CompleteHash = string(sval)
+			}
+			// t.CompleteHeight (int64) (int64)
+		case "CompleteHeight":
+			{
+				maj, extra, err := cr.ReadHeader()
+				var extraI int64
+				if err != nil {
+					return err
+				}
+				switch maj {
+				case cbg.MajUnsignedInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 positive overflow")
+					}
+				case cbg.MajNegativeInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 negative oveflow")
+					}
+					extraI = -1 - extraI
+				default:
+					return fmt.Errorf("wrong type for int64 field: %d", maj)
+				}
+
+				t.CompleteHeight = int64(extraI)
+			}
 
 		default:
 			// Field doesn't exist on this type, so ignore it
@@ -4276,7 +8586,7 @@ func (t *ShardAssignResp) UnmarshalCBOR(r io.Reader) (err error) {
 
 	return nil
 }
-func (t *ShardCompleteReq) MarshalCBOR(w io.Writer) error {
+func (t *ShardPingPong) MarshalCBOR(w io.Writer) error {
 	if t == nil {
 		_, err := w.Write(cbg.CborNull)
 		return err
@@ -4284,123 +8594,37 @@ func (t *ShardCompleteReq) MarshalCBOR(w io.Writer) error {
 
 	cw := cbg.NewCborWriter(w)
 
-	if _, err := cw.Write([]byte{165}); err != nil {
-		return err
-	}
-
-	// t.OrderId (uint64) (uint64)
-	if len("OrderId") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"OrderId\" was too long")
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("OrderId"))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string("OrderId")); err != nil {
-		return err
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.OrderId)); err != nil {
-		return err
-	}
-
-	// t.DataId (string) (string)
-	if len("DataId") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"DataId\" was too long")
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("DataId"))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string("DataId")); err != nil {
-		return err
-	}
-
-	if len(t.DataId) > cbg.MaxLength {
-		return xerrors.Errorf("Value in field t.DataId was too long")
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.DataId))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string(t.DataId)); err != nil {
-		return err
-	}
-
-	// t.Cids ([]cid.Cid) (slice)
-	if len("Cids") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"Cids\" was too long")
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Cids"))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string("Cids")); err != nil {
-		return err
-	}
-
-	if len(t.Cids) > cbg.MaxLength {
-		return xerrors.Errorf("Slice value in field t.Cids was too long")
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajArray, uint64(len(t.Cids))); err != nil {
-		return err
-	}
-	for _, v := range t.Cids {
-		if err := cbg.WriteCid(w, v); err != nil {
-			return xerrors.Errorf("failed writing cid field t.Cids: %w", err)
-		}
-	}
-
-	// t.TxHash (string) (string)
-	if len("TxHash") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"TxHash\" was too long")
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("TxHash"))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string("TxHash")); err != nil {
+	if _, err := cw.Write([]byte{161}); err != nil {
 		return err
 	}
 
-	if len(t.TxHash) > cbg.MaxLength {
-		return xerrors.Errorf("Value in field t.TxHash was too long")
+	// t.Local (string) (string)
+	if len("Local") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Local\" was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.TxHash))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Local"))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string(t.TxHash)); err != nil {
+	if _, err := io.WriteString(w, string("Local")); err != nil {
 		return err
 	}
 
-	// t.Height (int64) (int64)
-	if len("Height") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"Height\" was too long")
+	if len(t.Local) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.Local was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Height"))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.Local))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string("Height")); err != nil {
+	if _, err := io.WriteString(w, string(t.Local)); err != nil {
 		return err
 	}
-
-	if t.Height >= 0 {
-		if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.Height)); err != nil {
-			return err
-		}
-	} else {
-		if err := cw.WriteMajorTypeHeader(cbg.MajNegativeInt, uint64(-t.Height-1)); err != nil {
-			return err
-		}
-	}
 	return nil
 }
 
-func (t *ShardCompleteReq) UnmarshalCBOR(r io.Reader) (err error) {
-	*t = ShardCompleteReq{}
+func (t *ShardPingPong) UnmarshalCBOR(r io.Reader) (err error) {
+	*t = ShardPingPong{}
 
 	cr := cbg.NewCborReader(r)
 
@@ -4419,7 +8643,7 @@ func (t *ShardCompleteReq) UnmarshalCBOR(r io.Reader) (err error) {
 	}
 
 	if extra > cbg.MaxLength {
-		return fmt.Errorf("ShardCompleteReq: map struct too large (%d)", extra)
+		return fmt.Errorf("ShardPingPong: map struct too large (%d)", extra)
 	}
 
 	var name string
@@ -4437,63 +8661,8 @@ func (t *ShardCompleteReq) UnmarshalCBOR(r io.Reader) (err error) {
 		}
 
 		switch name {
-		// t.OrderId (uint64) (uint64)
-		case "OrderId":
-
-			{
-
-				maj, extra, err = cr.ReadHeader()
-				if err != nil {
-					return err
-				}
-				if maj != cbg.MajUnsignedInt {
-					return fmt.Errorf("wrong type for uint64 field")
-				}
-				t.OrderId = uint64(extra)
-
-			}
-			// t.DataId (string) (string)
-		case "DataId":
-
-			{
-				sval, err := cbg.ReadString(cr)
-				if err != nil {
-					return err
-				}
-
-				t.DataId = string(sval)
-			}
-			// t.Cids ([]cid.Cid) (slice)
-		case "Cids":
-
-			maj, extra, err = cr.ReadHeader()
-			if err != nil {
-				return err
-			}
-
-			if extra > cbg.MaxLength {
-				return fmt.Errorf("t.Cids: array too large (%d)", extra)
-			}
-
-			if maj != cbg.MajArray {
-				return fmt.Errorf("expected cbor array")
-			}
-
-			if extra > 0 {
-				t.Cids = make([]cid.Cid, extra)
-			}
-
-			for i := 0; i < int(extra); i++ {
-
-				c, err := cbg.ReadCid(cr)
-				if err != nil {
-					return xerrors.Errorf("reading cid field t.Cids failed: %w", err)
-				}
-				t.Cids[i] = c
-			}
-
-			// t.TxHash (string) (string)
-		case "TxHash":
+		// t.Local (string) (string)
+		case "Local":
 
 			{
 				sval, err := cbg.ReadString(cr)
@@ -4501,33 +8670,7 @@ func (t *ShardCompleteReq) UnmarshalCBOR(r io.Reader) (err error) {
 					return err
 				}
 
-				t.TxHash = string(sval)
-			}
-			// t.Height (int64) (int64)
-		case "Height":
-			{
-				maj, extra, err := cr.ReadHeader()
-				var extraI int64
-				if err != nil {
-					return err
-				}
-				switch maj {
-				case cbg.MajUnsignedInt:
-					extraI = int64(extra)
-					if extraI < 0 {
-						return fmt.Errorf("int64 positive overflow")
-					}
-				case cbg.MajNegativeInt:
-					extraI = int64(extra)
-					if extraI < 0 {
-						return fmt.Errorf("int64 negative oveflow")
-					}
-					extraI = -1 - extraI
-				default:
-					return fmt.Errorf("wrong type for int64 field: %d", maj)
-				}
-
-				t.Height = int64(extraI)
+				t.Local = string(sval)
 			}
 
 		default:
@@ -4538,7 +8681,7 @@ func (t *ShardCompleteReq) UnmarshalCBOR(r io.Reader) (err error) {
 
 	return nil
 }
-func (t *ShardCompleteResp) MarshalCBOR(w io.Writer) error {
+func (t *ShardChunk) MarshalCBOR(w io.Writer) error {
 	if t == nil {
 		_, err := w.Write(cbg.CborNull)
 		return err
@@ -4546,69 +8689,86 @@ func (t *ShardCompleteResp) MarshalCBOR(w io.Writer) error {
 
 	cw := cbg.NewCborWriter(w)
 
-	if _, err := cw.Write([]byte{163}); err != nil {
+	if _, err := cw.Write([]byte{164}); err != nil {
 		return err
 	}
 
-	// t.Code (uint64) (uint64)
-	if len("Code") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"Code\" was too long")
+	// t.Offset (uint64) (uint64)
+	if len("Offset") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Offset\" was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Code"))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Offset"))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string("Code")); err != nil {
+	if _, err := io.WriteString(w, string("Offset")); err != nil {
 		return err
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.Code)); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.Offset)); err != nil {
 		return err
 	}
 
-	// t.Message (string) (string)
-	if len("Message") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"Message\" was too long")
+	// t.Cid (cid.Cid) (struct)
+	if len("Cid") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Cid\" was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Message"))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Cid"))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string("Message")); err != nil {
+	if _, err := io.WriteString(w, string("Cid")); err != nil {
 		return err
 	}
 
-	if len(t.Message) > cbg.MaxLength {
-		return xerrors.Errorf("Value in field t.Message was too long")
+	if err := cbg.WriteCid(cw, t.Cid); err != nil {
+		return xerrors.Errorf("failed to write cid field t.Cid: %w", err)
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.Message))); err != nil {
+	// t.Content ([]uint8) (slice)
+	if len("Content") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Content\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Content"))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string(t.Message)); err != nil {
+	if _, err := io.WriteString(w, string("Content")); err != nil {
 		return err
 	}
 
-	// t.Recoverable (bool) (bool)
-	if len("Recoverable") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"Recoverable\" was too long")
+	if len(t.Content) > cbg.ByteArrayMaxLen {
+		return xerrors.Errorf("Byte array in field t.Content was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Recoverable"))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajByteString, uint64(len(t.Content))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string("Recoverable")); err != nil {
+
+	if _, err := cw.Write(t.Content[:]); err != nil {
 		return err
 	}
 
-	if err := cbg.WriteBool(w, t.Recoverable); err != nil {
+	// t.Last (bool) (bool)
+	if len("Last") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Last\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Last"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Last")); err != nil {
+		return err
+	}
+
+	if err := cbg.WriteBool(w, t.Last); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (t *ShardCompleteResp) UnmarshalCBOR(r io.Reader) (err error) {
-	*t = ShardCompleteResp{}
+func (t *ShardChunk) UnmarshalCBOR(r io.Reader) (err error) {
+	*t = ShardChunk{}
 
 	cr := cbg.NewCborReader(r)
 
@@ -4627,7 +8787,7 @@ func (t *ShardCompleteResp) UnmarshalCBOR(r io.Reader) (err error) {
 	}
 
 	if extra > cbg.MaxLength {
-		return fmt.Errorf("ShardCompleteResp: map struct too large (%d)", extra)
+		return fmt.Errorf("ShardChunk: map struct too large (%d)", extra)
 	}
 
 	var name string
@@ -4645,8 +8805,8 @@ func (t *ShardCompleteResp) UnmarshalCBOR(r io.Reader) (err error) {
 		}
 
 		switch name {
-		// t.Code (uint64) (uint64)
-		case "Code":
+		// t.Offset (uint64) (uint64)
+		case "Offset":
 
 			{
 
@@ -4657,22 +8817,46 @@ func (t *ShardCompleteResp) UnmarshalCBOR(r io.Reader) (err error) {
 				if maj != cbg.MajUnsignedInt {
 					return fmt.Errorf("wrong type for uint64 field")
 				}
-				t.Code = uint64(extra)
+				t.Offset = uint64(extra)
 
 			}
-			// t.Message (string) (string)
-		case "Message":
+			// t.Cid (cid.Cid) (struct)
+		case "Cid":
 
 			{
-				sval, err := cbg.ReadString(cr)
+
+				c, err := cbg.ReadCid(cr)
 				if err != nil {
-					return err
+					return xerrors.Errorf("failed to read cid field t.Cid: %w", err)
 				}
 
-				t.Message = string(sval)
+				t.Cid = c
+
 			}
-			// t.Recoverable (bool) (bool)
-		case "Recoverable":
+			// t.Content ([]uint8) (slice)
+		case "Content":
+
+			maj, extra, err = cr.ReadHeader()
+			if err != nil {
+				return err
+			}
+
+			if extra > cbg.ByteArrayMaxLen {
+				return fmt.Errorf("t.Content: byte array too large (%d)", extra)
+			}
+			if maj != cbg.MajByteString {
+				return fmt.Errorf("expected byte array")
+			}
+
+			if extra > 0 {
+				t.Content = make([]uint8, extra)
+			}
+
+			if _, err := io.ReadFull(cr, t.Content[:]); err != nil {
+				return err
+			}
+			// t.Last (bool) (bool)
+		case "Last":
 
 			maj, extra, err = cr.ReadHeader()
 			if err != nil {
@@ -4683,9 +8867,9 @@ func (t *ShardCompleteResp) UnmarshalCBOR(r io.Reader) (err error) {
 			}
 			switch extra {
 			case 20:
-				t.Recoverable = false
+				t.Last = false
 			case 21:
-				t.Recoverable = true
+				t.Last = true
 			default:
 				return fmt.Errorf("booleans are either major type 7, value 20 or 21 (got %d)", extra)
 			}
@@ -4698,7 +8882,7 @@ func (t *ShardCompleteResp) UnmarshalCBOR(r io.Reader) (err error) {
 
 	return nil
 }
-func (t *ShardLoadReq) MarshalCBOR(w io.Writer) error {
+func (t *ShardStreamAck) MarshalCBOR(w io.Writer) error {
 	if t == nil {
 		_, err := w.Write(cbg.CborNull)
 		return err
@@ -4706,146 +8890,70 @@ func (t *ShardLoadReq) MarshalCBOR(w io.Writer) error {
 
 	cw := cbg.NewCborWriter(w)
 
-	if _, err := cw.Write([]byte{167}); err != nil {
-		return err
-	}
-
-	// t.Owner (string) (string)
-	if len("Owner") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"Owner\" was too long")
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Owner"))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string("Owner")); err != nil {
-		return err
-	}
-
-	if len(t.Owner) > cbg.MaxLength {
-		return xerrors.Errorf("Value in field t.Owner was too long")
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.Owner))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string(t.Owner)); err != nil {
-		return err
-	}
-
-	// t.DataId (string) (string)
-	if len("DataId") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"DataId\" was too long")
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("DataId"))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string("DataId")); err != nil {
-		return err
-	}
-
-	if len(t.DataId) > cbg.MaxLength {
-		return xerrors.Errorf("Value in field t.DataId was too long")
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.DataId))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string(t.DataId)); err != nil {
-		return err
-	}
-
-	// t.OrderId (uint64) (uint64)
-	if len("OrderId") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"OrderId\" was too long")
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("OrderId"))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string("OrderId")); err != nil {
-		return err
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.OrderId)); err != nil {
+	if _, err := cw.Write([]byte{163}); err != nil {
 		return err
 	}
 
-	// t.Cid (cid.Cid) (struct)
-	if len("Cid") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"Cid\" was too long")
+	// t.Code (uint64) (uint64)
+	if len("Code") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Code\" was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Cid"))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Code"))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string("Cid")); err != nil {
+	if _, err := io.WriteString(w, string("Code")); err != nil {
 		return err
 	}
 
-	if err := cbg.WriteCid(cw, t.Cid); err != nil {
-		return xerrors.Errorf("failed to write cid field t.Cid: %w", err)
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.Code)); err != nil {
+		return err
 	}
 
-	// t.Proposal (types.MetadataProposalCbor) (struct)
-	if len("Proposal") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"Proposal\" was too long")
+	// t.Message (string) (string)
+	if len("Message") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Message\" was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Proposal"))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string("Proposal")); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Message"))); err != nil {
 		return err
 	}
-
-	if err := t.Proposal.MarshalCBOR(cw); err != nil {
+	if _, err := io.WriteString(w, string("Message")); err != nil {
 		return err
 	}
 
-	// t.RequestId (int64) (int64)
-	if len("RequestId") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"RequestId\" was too long")
+	if len(t.Message) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.Message was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("RequestId"))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.Message))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string("RequestId")); err != nil {
+	if _, err := io.WriteString(w, string(t.Message)); err != nil {
 		return err
 	}
 
-	if t.RequestId >= 0 {
-		if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.RequestId)); err != nil {
-			return err
-		}
-	} else {
-		if err := cw.WriteMajorTypeHeader(cbg.MajNegativeInt, uint64(-t.RequestId-1)); err != nil {
-			return err
-		}
-	}
-
-	// t.RelayProposal (types.RelayProposalCbor) (struct)
-	if len("RelayProposal") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"RelayProposal\" was too long")
+	// t.Offset (uint64) (uint64)
+	if len("Offset") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Offset\" was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("RelayProposal"))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Offset"))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string("RelayProposal")); err != nil {
+	if _, err := io.WriteString(w, string("Offset")); err != nil {
 		return err
 	}
 
-	if err := t.RelayProposal.MarshalCBOR(cw); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.Offset)); err != nil {
 		return err
 	}
+
 	return nil
 }
 
-func (t *ShardLoadReq) UnmarshalCBOR(r io.Reader) (err error) {
-	*t = ShardLoadReq{}
+func (t *ShardStreamAck) UnmarshalCBOR(r io.Reader) (err error) {
+	*t = ShardStreamAck{}
 
 	cr := cbg.NewCborReader(r)
 
@@ -4864,7 +8972,7 @@ func (t *ShardLoadReq) UnmarshalCBOR(r io.Reader) (err error) {
 	}
 
 	if extra > cbg.MaxLength {
-		return fmt.Errorf("ShardLoadReq: map struct too large (%d)", extra)
+		return fmt.Errorf("ShardStreamAck: map struct too large (%d)", extra)
 	}
 
 	var name string
@@ -4882,30 +8990,8 @@ func (t *ShardLoadReq) UnmarshalCBOR(r io.Reader) (err error) {
 		}
 
 		switch name {
-		// t.Owner (string) (string)
-		case "Owner":
-
-			{
-				sval, err := cbg.ReadString(cr)
-				if err != nil {
-					return err
-				}
-
-				t.Owner = string(sval)
-			}
-			// t.DataId (string) (string)
-		case "DataId":
-
-			{
-				sval, err := cbg.ReadString(cr)
-				if err != nil {
-					return err
-				}
-
-				t.DataId = string(sval)
-			}
-			// t.OrderId (uint64) (uint64)
-		case "OrderId":
+		// t.Code (uint64) (uint64)
+		case "Code":
 
 			{
 
@@ -4916,66 +9002,33 @@ func (t *ShardLoadReq) UnmarshalCBOR(r io.Reader) (err error) {
 				if maj != cbg.MajUnsignedInt {
 					return fmt.Errorf("wrong type for uint64 field")
 				}
-				t.OrderId = uint64(extra)
+				t.Code = uint64(extra)
 
 			}
-			// t.Cid (cid.Cid) (struct)
-		case "Cid":
+			// t.Message (string) (string)
+		case "Message":
 
 			{
-
-				c, err := cbg.ReadCid(cr)
+				sval, err := cbg.ReadString(cr)
 				if err != nil {
-					return xerrors.Errorf("failed to read cid field t.Cid: %w", err)
+					return err
 				}
 
-				t.Cid = c
-
+				t.Message = string(sval)
 			}
-			// t.Proposal (types.MetadataProposalCbor) (struct)
-		case "Proposal":
+			// t.Offset (uint64) (uint64)
+		case "Offset":
 
 			{
 
-				if err := t.Proposal.UnmarshalCBOR(cr); err != nil {
-					return xerrors.Errorf("unmarshaling t.Proposal: %w", err)
-				}
-
-			}
-			// t.RequestId (int64) (int64)
-		case "RequestId":
-			{
-				maj, extra, err := cr.ReadHeader()
-				var extraI int64
+				maj, extra, err = cr.ReadHeader()
 				if err != nil {
 					return err
 				}
-				switch maj {
-				case cbg.MajUnsignedInt:
-					extraI = int64(extra)
-					if extraI < 0 {
-						return fmt.Errorf("int64 positive overflow")
-					}
-				case cbg.MajNegativeInt:
-					extraI = int64(extra)
-					if extraI < 0 {
-						return fmt.Errorf("int64 negative oveflow")
-					}
-					extraI = -1 - extraI
-				default:
-					return fmt.Errorf("wrong type for int64 field: %d", maj)
-				}
-
-				t.RequestId = int64(extraI)
-			}
-			// t.RelayProposal (types.RelayProposalCbor) (struct)
-		case "RelayProposal":
-
-			{
-
-				if err := t.RelayProposal.UnmarshalCBOR(cr); err != nil {
-					return xerrors.Errorf("unmarshaling t.RelayProposal: %w", err)
+				if maj != cbg.MajUnsignedInt {
+					return fmt.Errorf("wrong type for uint64 field")
 				}
+				t.Offset = uint64(extra)
 
 			}
 
@@ -4987,7 +9040,7 @@ func (t *ShardLoadReq) UnmarshalCBOR(r io.Reader) (err error) {
 
 	return nil
 }
-func (t *ShardLoadResp) MarshalCBOR(w io.Writer) error {
+func (t *ShardChallengeReq) MarshalCBOR(w io.Writer) error {
 	if t == nil {
 		_, err := w.Write(cbg.CborNull)
 		return err
@@ -4995,46 +9048,30 @@ func (t *ShardLoadResp) MarshalCBOR(w io.Writer) error {
 
 	cw := cbg.NewCborWriter(w)
 
-	if _, err := cw.Write([]byte{167}); err != nil {
-		return err
-	}
-
-	// t.Code (uint64) (uint64)
-	if len("Code") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"Code\" was too long")
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Code"))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string("Code")); err != nil {
-		return err
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.Code)); err != nil {
+	if _, err := cw.Write([]byte{166}); err != nil {
 		return err
 	}
 
-	// t.Message (string) (string)
-	if len("Message") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"Message\" was too long")
+	// t.Fisherman (string) (string)
+	if len("Fisherman") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Fisherman\" was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Message"))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Fisherman"))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string("Message")); err != nil {
+	if _, err := io.WriteString(w, string("Fisherman")); err != nil {
 		return err
 	}
 
-	if len(t.Message) > cbg.MaxLength {
-		return xerrors.Errorf("Value in field t.Message was too long")
+	if len(t.Fisherman) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.Fisherman was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.Message))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.Fisherman))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string(t.Message)); err != nil {
+	if _, err := io.WriteString(w, string(t.Fisherman)); err != nil {
 		return err
 	}
 
@@ -5070,29 +9107,46 @@ func (t *ShardLoadResp) MarshalCBOR(w io.Writer) error {
 		return xerrors.Errorf("failed to write cid field t.Cid: %w", err)
 	}
 
-	// t.Content ([]uint8) (slice)
-	if len("Content") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"Content\" was too long")
+	// t.LeafSize (uint64) (uint64)
+	if len("LeafSize") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"LeafSize\" was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Content"))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("LeafSize"))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string("Content")); err != nil {
+	if _, err := io.WriteString(w, string("LeafSize")); err != nil {
 		return err
 	}
 
-	if len(t.Content) > cbg.ByteArrayMaxLen {
-		return xerrors.Errorf("Byte array in field t.Content was too long")
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.LeafSize)); err != nil {
+		return err
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajByteString, uint64(len(t.Content))); err != nil {
+	// t.Leaves ([]uint64) (slice)
+	if len("Leaves") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Leaves\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Leaves"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Leaves")); err != nil {
 		return err
 	}
 
-	if _, err := cw.Write(t.Content[:]); err != nil {
+	if len(t.Leaves) > cbg.MaxLength {
+		return xerrors.Errorf("Slice value in field t.Leaves was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajArray, uint64(len(t.Leaves))); err != nil {
 		return err
 	}
+	for _, v := range t.Leaves {
+		if err := cw.CborWriteHeader(cbg.MajUnsignedInt, uint64(v)); err != nil {
+			return err
+		}
+	}
 
 	// t.RequestId (int64) (int64)
 	if len("RequestId") > cbg.MaxLength {
@@ -5115,33 +9169,11 @@ func (t *ShardLoadResp) MarshalCBOR(w io.Writer) error {
 			return err
 		}
 	}
-
-	// t.ResponseId (int64) (int64)
-	if len("ResponseId") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"ResponseId\" was too long")
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("ResponseId"))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string("ResponseId")); err != nil {
-		return err
-	}
-
-	if t.ResponseId >= 0 {
-		if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.ResponseId)); err != nil {
-			return err
-		}
-	} else {
-		if err := cw.WriteMajorTypeHeader(cbg.MajNegativeInt, uint64(-t.ResponseId-1)); err != nil {
-			return err
-		}
-	}
 	return nil
 }
 
-func (t *ShardLoadResp) UnmarshalCBOR(r io.Reader) (err error) {
-	*t = ShardLoadResp{}
+func (t *ShardChallengeReq) UnmarshalCBOR(r io.Reader) (err error) {
+	*t = ShardChallengeReq{}
 
 	cr := cbg.NewCborReader(r)
 
@@ -5160,7 +9192,7 @@ func (t *ShardLoadResp) UnmarshalCBOR(r io.Reader) (err error) {
 	}
 
 	if extra > cbg.MaxLength {
-		return fmt.Errorf("ShardLoadResp: map struct too large (%d)", extra)
+		return fmt.Errorf("ShardChallengeReq: map struct too large (%d)", extra)
 	}
 
 	var name string
@@ -5178,23 +9210,8 @@ func (t *ShardLoadResp) UnmarshalCBOR(r io.Reader) (err error) {
 		}
 
 		switch name {
-		// t.Code (uint64) (uint64)
-		case "Code":
-
-			{
-
-				maj, extra, err = cr.ReadHeader()
-				if err != nil {
-					return err
-				}
-				if maj != cbg.MajUnsignedInt {
-					return fmt.Errorf("wrong type for uint64 field")
-				}
-				t.Code = uint64(extra)
-
-			}
-			// t.Message (string) (string)
-		case "Message":
+		// t.Fisherman (string) (string)
+		case "Fisherman":
 
 			{
 				sval, err := cbg.ReadString(cr)
@@ -5202,7 +9219,7 @@ func (t *ShardLoadResp) UnmarshalCBOR(r io.Reader) (err error) {
 					return err
 				}
 
-				t.Message = string(sval)
+				t.Fisherman = string(sval)
 			}
 			// t.OrderId (uint64) (uint64)
 		case "OrderId":
@@ -5232,28 +9249,55 @@ func (t *ShardLoadResp) UnmarshalCBOR(r io.Reader) (err error) {
 				t.Cid = c
 
 			}
-			// t.Content ([]uint8) (slice)
-		case "Content":
+			// t.LeafSize (uint64) (uint64)
+		case "LeafSize":
+
+			{
+
+				maj, extra, err = cr.ReadHeader()
+				if err != nil {
+					return err
+				}
+				if maj != cbg.MajUnsignedInt {
+					return fmt.Errorf("wrong type for uint64 field")
+				}
+				t.LeafSize = uint64(extra)
+
+			}
+			// t.Leaves ([]uint64) (slice)
+		case "Leaves":
 
 			maj, extra, err = cr.ReadHeader()
 			if err != nil {
 				return err
 			}
 
-			if extra > cbg.ByteArrayMaxLen {
-				return fmt.Errorf("t.Content: byte array too large (%d)", extra)
+			if extra > cbg.MaxLength {
+				return fmt.Errorf("t.Leaves: array too large (%d)", extra)
 			}
-			if maj != cbg.MajByteString {
-				return fmt.Errorf("expected byte array")
+
+			if maj != cbg.MajArray {
+				return fmt.Errorf("expected cbor array")
+			}
+
+			if extra > 0 {
+				t.Leaves = make([]uint64, extra)
 			}
 
-			if extra > 0 {
-				t.Content = make([]uint8, extra)
+			for i := 0; i < int(extra); i++ {
+
+				maj, val, err := cr.ReadHeader()
+				if err != nil {
+					return xerrors.Errorf("failed to read uint64 for t.Leaves slice: %w", err)
+				}
+
+				if maj != cbg.MajUnsignedInt {
+					return xerrors.Errorf("value read for array t.Leaves was not a uint, instead got %d", maj)
+				}
+
+				t.Leaves[i] = uint64(val)
 			}
 
-			if _, err := io.ReadFull(cr, t.Content[:]); err != nil {
-				return err
-			}
 			// t.RequestId (int64) (int64)
 		case "RequestId":
 			{
@@ -5280,32 +9324,6 @@ func (t *ShardLoadResp) UnmarshalCBOR(r io.Reader) (err error) {
 
 				t.RequestId = int64(extraI)
 			}
-			// t.ResponseId (int64) (int64)
-		case "ResponseId":
-			{
-				maj, extra, err := cr.ReadHeader()
-				var extraI int64
-				if err != nil {
-					return err
-				}
-				switch maj {
-				case cbg.MajUnsignedInt:
-					extraI = int64(extra)
-					if extraI < 0 {
-						return fmt.Errorf("int64 positive overflow")
-					}
-				case cbg.MajNegativeInt:
-					extraI = int64(extra)
-					if extraI < 0 {
-						return fmt.Errorf("int64 negative oveflow")
-					}
-					extraI = -1 - extraI
-				default:
-					return fmt.Errorf("wrong type for int64 field: %d", maj)
-				}
-
-				t.ResponseId = int64(extraI)
-			}
 
 		default:
 			// Field doesn't exist on this type, so ignore it
@@ -5315,7 +9333,7 @@ func (t *ShardLoadResp) UnmarshalCBOR(r io.Reader) (err error) {
 
 	return nil
 }
-func (t *ShardMigrateReq) MarshalCBOR(w io.Writer) error {
+func (t *ShardLeafProof) MarshalCBOR(w io.Writer) error {
 	if t == nil {
 		_, err := w.Write(cbg.CborNull)
 		return err
@@ -5323,168 +9341,87 @@ func (t *ShardMigrateReq) MarshalCBOR(w io.Writer) error {
 
 	cw := cbg.NewCborWriter(w)
 
-	if _, err := cw.Write([]byte{167}); err != nil {
-		return err
-	}
-
-	// t.MigrateFrom (string) (string)
-	if len("MigrateFrom") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"MigrateFrom\" was too long")
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("MigrateFrom"))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string("MigrateFrom")); err != nil {
-		return err
-	}
-
-	if len(t.MigrateFrom) > cbg.MaxLength {
-		return xerrors.Errorf("Value in field t.MigrateFrom was too long")
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.MigrateFrom))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string(t.MigrateFrom)); err != nil {
+	if _, err := cw.Write([]byte{163}); err != nil {
 		return err
 	}
 
-	// t.OrderId (uint64) (uint64)
-	if len("OrderId") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"OrderId\" was too long")
+	// t.Index (uint64) (uint64)
+	if len("Index") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Index\" was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("OrderId"))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Index"))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string("OrderId")); err != nil {
+	if _, err := io.WriteString(w, string("Index")); err != nil {
 		return err
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.OrderId)); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.Index)); err != nil {
 		return err
 	}
 
-	// t.DataId (string) (string)
-	if len("DataId") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"DataId\" was too long")
+	// t.Content ([]uint8) (slice)
+	if len("Content") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Content\" was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("DataId"))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Content"))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string("DataId")); err != nil {
+	if _, err := io.WriteString(w, string("Content")); err != nil {
 		return err
 	}
 
-	if len(t.DataId) > cbg.MaxLength {
-		return xerrors.Errorf("Value in field t.DataId was too long")
+	if len(t.Content) > cbg.ByteArrayMaxLen {
+		return xerrors.Errorf("Byte array in field t.Content was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.DataId))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string(t.DataId)); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajByteString, uint64(len(t.Content))); err != nil {
 		return err
 	}
 
-	// t.TxHash (string) (string)
-	if len("TxHash") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"TxHash\" was too long")
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("TxHash"))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string("TxHash")); err != nil {
+	if _, err := cw.Write(t.Content[:]); err != nil {
 		return err
 	}
 
-	if len(t.TxHash) > cbg.MaxLength {
-		return xerrors.Errorf("Value in field t.TxHash was too long")
+	// t.Path ([][]uint8) (slice)
+	if len("Path") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Path\" was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.TxHash))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Path"))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string(t.TxHash)); err != nil {
+	if _, err := io.WriteString(w, string("Path")); err != nil {
 		return err
 	}
 
-	// t.TxHeight (int64) (int64)
-	if len("TxHeight") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"TxHeight\" was too long")
+	if len(t.Path) > cbg.MaxLength {
+		return xerrors.Errorf("Slice value in field t.Path was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("TxHeight"))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string("TxHeight")); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajArray, uint64(len(t.Path))); err != nil {
 		return err
 	}
+	for _, v := range t.Path {
+		if len(v) > cbg.ByteArrayMaxLen {
+			return xerrors.Errorf("Byte array in field v was too long")
+		}
 
-	if t.TxHeight >= 0 {
-		if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.TxHeight)); err != nil {
+		if err := cw.WriteMajorTypeHeader(cbg.MajByteString, uint64(len(v))); err != nil {
 			return err
 		}
-	} else {
-		if err := cw.WriteMajorTypeHeader(cbg.MajNegativeInt, uint64(-t.TxHeight-1)); err != nil {
+
+		if _, err := cw.Write(v[:]); err != nil {
 			return err
 		}
 	}
-
-	// t.Cid (string) (string)
-	if len("Cid") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"Cid\" was too long")
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Cid"))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string("Cid")); err != nil {
-		return err
-	}
-
-	if len(t.Cid) > cbg.MaxLength {
-		return xerrors.Errorf("Value in field t.Cid was too long")
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.Cid))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string(t.Cid)); err != nil {
-		return err
-	}
-
-	// t.Content ([]uint8) (slice)
-	if len("Content") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"Content\" was too long")
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Content"))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string("Content")); err != nil {
-		return err
-	}
-
-	if len(t.Content) > cbg.ByteArrayMaxLen {
-		return xerrors.Errorf("Byte array in field t.Content was too long")
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajByteString, uint64(len(t.Content))); err != nil {
-		return err
-	}
-
-	if _, err := cw.Write(t.Content[:]); err != nil {
-		return err
-	}
 	return nil
 }
 
-func (t *ShardMigrateReq) UnmarshalCBOR(r io.Reader) (err error) {
-	*t = ShardMigrateReq{}
+func (t *ShardLeafProof) UnmarshalCBOR(r io.Reader) (err error) {
+	*t = ShardLeafProof{}
 
 	cr := cbg.NewCborReader(r)
 
@@ -5503,7 +9440,7 @@ func (t *ShardMigrateReq) UnmarshalCBOR(r io.Reader) (err error) {
 	}
 
 	if extra > cbg.MaxLength {
-		return fmt.Errorf("ShardMigrateReq: map struct too large (%d)", extra)
+		return fmt.Errorf("ShardLeafProof: map struct too large (%d)", extra)
 	}
 
 	var name string
@@ -5521,19 +9458,8 @@ func (t *ShardMigrateReq) UnmarshalCBOR(r io.Reader) (err error) {
 		}
 
 		switch name {
-		// t.MigrateFrom (string) (string)
-		case "MigrateFrom":
-
-			{
-				sval, err := cbg.ReadString(cr)
-				if err != nil {
-					return err
-				}
-
-				t.MigrateFrom = string(sval)
-			}
-			// t.OrderId (uint64) (uint64)
-		case "OrderId":
+		// t.Index (uint64) (uint64)
+		case "Index":
 
 			{
 
@@ -5544,89 +9470,77 @@ func (t *ShardMigrateReq) UnmarshalCBOR(r io.Reader) (err error) {
 				if maj != cbg.MajUnsignedInt {
 					return fmt.Errorf("wrong type for uint64 field")
 				}
-				t.OrderId = uint64(extra)
+				t.Index = uint64(extra)
 
 			}
-			// t.DataId (string) (string)
-		case "DataId":
-
-			{
-				sval, err := cbg.ReadString(cr)
-				if err != nil {
-					return err
-				}
+			// t.Content ([]uint8) (slice)
+		case "Content":
 
-				t.DataId = string(sval)
+			maj, extra, err = cr.ReadHeader()
+			if err != nil {
+				return err
 			}
-			// t.TxHash (string) (string)
-		case "TxHash":
-
-			{
-				sval, err := cbg.ReadString(cr)
-				if err != nil {
-					return err
-				}
 
-				t.TxHash = string(sval)
+			if extra > cbg.ByteArrayMaxLen {
+				return fmt.Errorf("t.Content: byte array too large (%d)", extra)
 			}
-			// t.TxHeight (int64) (int64)
-		case "TxHeight":
-			{
-				maj, extra, err := cr.ReadHeader()
-				var extraI int64
-				if err != nil {
-					return err
-				}
-				switch maj {
-				case cbg.MajUnsignedInt:
-					extraI = int64(extra)
-					if extraI < 0 {
-						return fmt.Errorf("int64 positive overflow")
-					}
-				case cbg.MajNegativeInt:
-					extraI = int64(extra)
-					if extraI < 0 {
-						return fmt.Errorf("int64 negative oveflow")
-					}
-					extraI = -1 - extraI
-				default:
-					return fmt.Errorf("wrong type for int64 field: %d", maj)
-				}
-
-				t.TxHeight = int64(extraI)
+			if maj != cbg.MajByteString {
+				return fmt.Errorf("expected byte array")
 			}
-			// t.Cid (string) (string)
-		case "Cid":
 
-			{
-				sval, err := cbg.ReadString(cr)
-				if err != nil {
-					return err
-				}
+			if extra > 0 {
+				t.Content = make([]uint8, extra)
+			}
 
-				t.Cid = string(sval)
+			if _, err := io.ReadFull(cr, t.Content[:]); err != nil {
+				return err
 			}
-			// t.Content ([]uint8) (slice)
-		case "Content":
+			// t.Path ([][]uint8) (slice)
+		case "Path":
 
 			maj, extra, err = cr.ReadHeader()
 			if err != nil {
 				return err
 			}
 
-			if extra > cbg.ByteArrayMaxLen {
-				return fmt.Errorf("t.Content: byte array too large (%d)", extra)
+			if extra > cbg.MaxLength {
+				return fmt.Errorf("t.Path: array too large (%d)", extra)
 			}
-			if maj != cbg.MajByteString {
-				return fmt.Errorf("expected byte array")
+
+			if maj != cbg.MajArray {
+				return fmt.Errorf("expected cbor array")
 			}
 
-			if extra > 0 {
-				t.Content = make([]uint8, extra)
-			}
+			if extra > 0 {
+				t.Path = make([][]uint8, extra)
+			}
+
+			for i := 0; i < int(extra); i++ {
+				{
+					var maj byte
+					var extra uint64
+					var err error
+
+					maj, extra, err = cr.ReadHeader()
+					if err != nil {
+						return err
+					}
+
+					if extra > cbg.ByteArrayMaxLen {
+						return fmt.Errorf("t.Path[i]: byte array too large (%d)", extra)
+					}
+					if maj != cbg.MajByteString {
+						return fmt.Errorf("expected byte array")
+					}
 
-			if _, err := io.ReadFull(cr, t.Content[:]); err != nil {
-				return err
+					if extra > 0 {
+						t.Path[i] = make([]uint8, extra)
+					}
+
+					if _, err := io.ReadFull(cr, t.Path[i][:]); err != nil {
+						return err
+					}
+				}
 			}
 
 		default:
@@ -5637,7 +9551,7 @@ func (t *ShardMigrateReq) UnmarshalCBOR(r io.Reader) (err error) {
 
 	return nil
 }
-func (t *ShardMigrateResp) MarshalCBOR(w io.Writer) error {
+func (t *ShardChallengeResp) MarshalCBOR(w io.Writer) error {
 	if t == nil {
 		_, err := w.Write(cbg.CborNull)
 		return err
@@ -5645,7 +9559,7 @@ func (t *ShardMigrateResp) MarshalCBOR(w io.Writer) error {
 
 	cw := cbg.NewCborWriter(w)
 
-	if _, err := cw.Write([]byte{164}); err != nil {
+	if _, err := cw.Write([]byte{167}); err != nil {
 		return err
 	}
 
@@ -5688,55 +9602,111 @@ func (t *ShardMigrateResp) MarshalCBOR(w io.Writer) error {
 		return err
 	}
 
-	// t.CompleteHash (string) (string)
-	if len("CompleteHash") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"CompleteHash\" was too long")
+	// t.Cid (cid.Cid) (struct)
+	if len("Cid") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Cid\" was too long")
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("CompleteHash"))); err != nil {
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Cid"))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string("CompleteHash")); err != nil {
+	if _, err := io.WriteString(w, string("Cid")); err != nil {
 		return err
 	}
 
-	if len(t.CompleteHash) > cbg.MaxLength {
-		return xerrors.Errorf("Value in field t.CompleteHash was too long")
+	if err := cbg.WriteCid(cw, t.Cid); err != nil {
+		return xerrors.Errorf("failed to write cid field t.Cid: %w", err)
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.CompleteHash))); err != nil {
+	// t.LeafCount (uint64) (uint64)
+	if len("LeafCount") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"LeafCount\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("LeafCount"))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string(t.CompleteHash)); err != nil {
+	if _, err := io.WriteString(w, string("LeafCount")); err != nil {
 		return err
 	}
 
-	// t.CompleteHeight (int64) (int64)
-	if len("CompleteHeight") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"CompleteHeight\" was too long")
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.LeafCount)); err != nil {
+		return err
 	}
 
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("CompleteHeight"))); err != nil {
+	// t.Proofs ([]types.ShardLeafProof) (slice)
+	if len("Proofs") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Proofs\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Proofs"))); err != nil {
 		return err
 	}
-	if _, err := io.WriteString(w, string("CompleteHeight")); err != nil {
+	if _, err := io.WriteString(w, string("Proofs")); err != nil {
 		return err
 	}
 
-	if t.CompleteHeight >= 0 {
-		if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.CompleteHeight)); err != nil {
+	if len(t.Proofs) > cbg.MaxLength {
+		return xerrors.Errorf("Slice value in field t.Proofs was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajArray, uint64(len(t.Proofs))); err != nil {
+		return err
+	}
+	for _, v := range t.Proofs {
+		if err := v.MarshalCBOR(cw); err != nil {
+			return err
+		}
+	}
+
+	// t.RequestId (int64) (int64)
+	if len("RequestId") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"RequestId\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("RequestId"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("RequestId")); err != nil {
+		return err
+	}
+
+	if t.RequestId >= 0 {
+		if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.RequestId)); err != nil {
 			return err
 		}
 	} else {
-		if err := cw.WriteMajorTypeHeader(cbg.MajNegativeInt, uint64(-t.CompleteHeight-1)); err != nil {
+		if err := cw.WriteMajorTypeHeader(cbg.MajNegativeInt, uint64(-t.RequestId-1)); err != nil {
+			return err
+		}
+	}
+
+	// t.ResponseId (int64) (int64)
+	if len("ResponseId") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"ResponseId\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("ResponseId"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("ResponseId")); err != nil {
+		return err
+	}
+
+	if t.ResponseId >= 0 {
+		if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.ResponseId)); err != nil {
+			return err
+		}
+	} else {
+		if err := cw.WriteMajorTypeHeader(cbg.MajNegativeInt, uint64(-t.ResponseId-1)); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func (t *ShardMigrateResp) UnmarshalCBOR(r io.Reader) (err error) {
-	*t = ShardMigrateResp{}
+func (t *ShardChallengeResp) UnmarshalCBOR(r io.Reader) (err error) {
+	*t = ShardChallengeResp{}
 
 	cr := cbg.NewCborReader(r)
 
@@ -5755,7 +9725,7 @@ func (t *ShardMigrateResp) UnmarshalCBOR(r io.Reader) (err error) {
 	}
 
 	if extra > cbg.MaxLength {
-		return fmt.Errorf("ShardMigrateResp: map struct too large (%d)", extra)
+		return fmt.Errorf("ShardChallengeResp: map struct too large (%d)", extra)
 	}
 
 	var name string
@@ -5799,19 +9769,66 @@ func (t *ShardMigrateResp) UnmarshalCBOR(r io.Reader) (err error) {
 
 				t.Message = string(sval)
 			}
-			// t.CompleteHash (string) (string)
-		case "CompleteHash":
+			// t.Cid (cid.Cid) (struct)
+		case "Cid":
 
 			{
-				sval, err := cbg.ReadString(cr)
+
+				c, err := cbg.ReadCid(cr)
+				if err != nil {
+					return xerrors.Errorf("failed to read cid field t.Cid: %w", err)
+				}
+
+				t.Cid = c
+
+			}
+			// t.LeafCount (uint64) (uint64)
+		case "LeafCount":
+
+			{
+
+				maj, extra, err = cr.ReadHeader()
 				if err != nil {
 					return err
 				}
+				if maj != cbg.MajUnsignedInt {
+					return fmt.Errorf("wrong type for uint64 field")
+				}
+				t.LeafCount = uint64(extra)
 
-				t.CompleteHash = string(sval)
 			}
-			// t.CompleteHeight (int64) (int64)
-		case "CompleteHeight":
+			// t.Proofs ([]types.ShardLeafProof) (slice)
+		case "Proofs":
+
+			maj, extra, err = cr.ReadHeader()
+			if err != nil {
+				return err
+			}
+
+			if extra > cbg.MaxLength {
+				return fmt.Errorf("t.Proofs: array too large (%d)", extra)
+			}
+
+			if maj != cbg.MajArray {
+				return fmt.Errorf("expected cbor array")
+			}
+
+			if extra > 0 {
+				t.Proofs = make([]ShardLeafProof, extra)
+			}
+
+			for i := 0; i < int(extra); i++ {
+
+				var v ShardLeafProof
+				if err := v.UnmarshalCBOR(cr); err != nil {
+					return err
+				}
+
+				t.Proofs[i] = v
+			}
+
+			// t.RequestId (int64) (int64)
+		case "RequestId":
 			{
 				maj, extra, err := cr.ReadHeader()
 				var extraI int64
@@ -5834,102 +9851,33 @@ func (t *ShardMigrateResp) UnmarshalCBOR(r io.Reader) (err error) {
 					return fmt.Errorf("wrong type for int64 field: %d", maj)
 				}
 
-				t.CompleteHeight = int64(extraI)
-			}
-
-		default:
-			// Field doesn't exist on this type, so ignore it
-			cbg.ScanForLinks(r, func(cid.Cid) {})
-		}
-	}
-
-	return nil
-}
-func (t *ShardPingPong) MarshalCBOR(w io.Writer) error {
-	if t == nil {
-		_, err := w.Write(cbg.CborNull)
-		return err
-	}
-
-	cw := cbg.NewCborWriter(w)
-
-	if _, err := cw.Write([]byte{161}); err != nil {
-		return err
-	}
-
-	// t.Local (string) (string)
-	if len("Local") > cbg.MaxLength {
-		return xerrors.Errorf("Value in field \"Local\" was too long")
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Local"))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string("Local")); err != nil {
-		return err
-	}
-
-	if len(t.Local) > cbg.MaxLength {
-		return xerrors.Errorf("Value in field t.Local was too long")
-	}
-
-	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.Local))); err != nil {
-		return err
-	}
-	if _, err := io.WriteString(w, string(t.Local)); err != nil {
-		return err
-	}
-	return nil
-}
-
-func (t *ShardPingPong) UnmarshalCBOR(r io.Reader) (err error) {
-	*t = ShardPingPong{}
-
-	cr := cbg.NewCborReader(r)
-
-	maj, extra, err := cr.ReadHeader()
-	if err != nil {
-		return err
-	}
-	defer func() {
-		if err == io.EOF {
-			err = io.ErrUnexpectedEOF
-		}
-	}()
-
-	if maj != cbg.MajMap {
-		return fmt.Errorf("cbor input should be of type map")
-	}
-
-	if extra > cbg.MaxLength {
-		return fmt.Errorf("ShardPingPong: map struct too large (%d)", extra)
-	}
-
-	var name string
-	n := extra
-
-	for i := uint64(0); i < n; i++ {
-
-		{
-			sval, err := cbg.ReadString(cr)
-			if err != nil {
-				return err
+				t.RequestId = int64(extraI)
 			}
-
-			name = string(sval)
-		}
-
-		switch name {
-		// t.Local (string) (string)
-		case "Local":
-
+			// t.ResponseId (int64) (int64)
+		case "ResponseId":
 			{
-				sval, err := cbg.ReadString(cr)
+				maj, extra, err := cr.ReadHeader()
+				var extraI int64
 				if err != nil {
 					return err
 				}
+				switch maj {
+				case cbg.MajUnsignedInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 positive overflow")
+					}
+				case cbg.MajNegativeInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 negative oveflow")
+					}
+					extraI = -1 - extraI
+				default:
+					return fmt.Errorf("wrong type for int64 field: %d", maj)
+				}
 
-				t.Local = string(sval)
+				t.ResponseId = int64(extraI)
 			}
 
 		default:
@@ -70,6 +70,9 @@ var (
 	ErrQueryPledgeFailed      = errors.Register(ModuleChain, 11032, "failed to query the pledge information")
 	ErrInvalidValidator       = errors.Register(ModuleChain, 11033, "invalid validator")
 	ErrQueryDidParamFailed    = errors.Register(ModuleChain, 11034, "failed to query the did param")
+
+	ErrSubscribeFailed   = errors.Register(ModuleChain, 11035, "failed to subscribe the chain events")
+	ErrUnsubscribeFailed = errors.Register(ModuleChain, 11036, "failed to unsubscribe the chain events")
 )
 
 var (
@@ -87,6 +90,9 @@ var (
 	ErrOpenDataStoreFailed    = errors.Register(ModuleClient, 12013, "failed to open the data store")
 	ErrInvalidParameters      = errors.Register(ModuleClient, 12014, "invalid parameters")
 	ErrCreateClientFailed     = errors.Register(ModuleClient, 12015, "failed to create client")
+	ErrInvalidArchive         = errors.Register(ModuleClient, 12016, "invalid model archive")
+	ErrEncryptFailed          = errors.Register(ModuleClient, 12017, "failed to encrypt the content")
+	ErrDecryptFailed          = errors.Register(ModuleClient, 12018, "failed to decrypt the content")
 )
 
 var (
@@ -107,6 +113,9 @@ var (
 	ErrUnSupportProtocol          = errors.Register(ModuleStore, 13012, "unsupported ipfs connection protocol")
 	ErrRemoveFailed               = errors.Register(ModuleStore, 13013, "remove data failed")
 	ErrDataMissing                = errors.Register(ModuleStore, 13014, "cannot found the data")
+	ErrOpenLocalBackendFailed     = errors.Register(ModuleStore, 13015, "failed to open local backend")
+	ErrOpenS3BackendFailed        = errors.Register(ModuleStore, 13016, "failed to open S3 backend")
+	ErrInsufficientSpace          = errors.Register(ModuleStore, 13017, "insufficient storage space")
 )
 
 var (
@@ -152,6 +161,16 @@ var (
 	ErrRetriesExceed      = errors.Register(ModuleModel, 14030, "shard retries too many times")
 	ErrInvalidProvider    = errors.Register(ModuleModel, 14031, "invalid provider")
 	ErrInvalidPeerInfo    = errors.Register(ModuleModel, 14032, "invalid peer info")
+
+	ErrErasureEncodeFailed = errors.Register(ModuleModel, 14033, "failed to erasure encode the content")
+	ErrErasureDecodeFailed = errors.Register(ModuleModel, 14034, "failed to erasure decode the shards")
+
+	ErrInvalidStorageProof = errors.Register(ModuleModel, 14035, "invalid proof of storage")
+
+	ErrInvalidOrderState = errors.Register(ModuleModel, 14036, "invalid order state")
+	ErrInvalidShardState = errors.Register(ModuleModel, 14037, "invalid shard state")
+
+	ErrAuditPending = errors.Register(ModuleModel, 14038, "audit is pending")
 )
 
 var (
@@ -167,6 +186,7 @@ var (
 	ErrSendRequestFailed          = errors.Register(ModuleNetwork, 15007, "failed to send the request")
 	ErrReadResponseFailed         = errors.Register(ModuleNetwork, 15008, "failed to read the response")
 	ErrFailuresResponsed          = errors.Register(ModuleNetwork, 15009, "received failed response")
+	ErrStartTracingFailed         = errors.Register(ModuleNetwork, 15010, "failed to start the trace exporter")
 )
 
 func Wrap(err0 error, err1 error) error {
@@ -0,0 +1,36 @@
+package types
+
+type GcKind string
+
+const (
+	// shard content staged by the gateway, <staging>/<owner>/<cid>-<dataId>
+	GcKindStagedShard GcKind = "staged-shard"
+	// chunks and content received by the transport, <staging>/<peer>/<cid>/
+	GcKindUpload GcKind = "upload"
+	// ReceivedFileInfo record without upload content
+	GcKindUploadRecord GcKind = "upload-record"
+	// partially received shard content of the storage module, <staging>/shard-parts|migrate/<orderId>-<cid>
+	GcKindShardPart GcKind = "shard-part"
+	// content cached for the http file server, <http-files>/<dataId>
+	GcKindCache GcKind = "cache"
+)
+
+/**
+ * a stale file or record found by the garbage collector.
+ */
+type GcEntry struct {
+	Kind    GcKind
+	Path    string
+	Size    int64
+	Reason  string
+	Removed bool
+	Error   string
+}
+
+type GcReport struct {
+	DryRun  bool
+	StartAt int64
+	Entries []GcEntry
+	// bytes of the removed entries, or of all entries in a dry run
+	Reclaimed int64
+}
@@ -14,6 +14,11 @@ const (
 	JobStatusKilled    = "Killed"
 )
 
+const (
+	IndexJobTypeMetadata = "metadata"
+	IndexJobTypeShard    = "shard"
+)
+
 type Job struct {
 	ID          string
 	Description string
@@ -19,6 +19,19 @@ const (
 	ShardPingPongProtocol = "/sao/shard/pingpong/1.0"
 	RpcProtocol           = "/sao/rpc/1.0"
 
+	ShardLoadStreamProtocol    = "/sao/shard/load/stream/1.0"
+	ShardStoreStreamProtocol   = "/sao/shard/store/stream/1.0"
+	ShardMigrateStreamProtocol = "/sao/shard/migrate/stream/1.0"
+	ShardChallengeProtocol     = "/sao/shard/challenge/1.0"
+
+	// shard content is transferred in chunks of this size in stream protocols
+	ShardChunkSize = 1024 * 1024
+
+	// bounds of a shard challenge accepted by storage nodes
+	ShardChallengeMinLeafSize = 4 * 1024
+	ShardChallengeMaxLeafSize = ShardChunkSize
+	ShardChallengeMaxLeaves   = 64
+
 	ErrorCodeInvalidRequest       = 1
 	ErrorCodeInvalidTx            = 2
 	ErrorCodeInternalErr          = 3
@@ -26,6 +39,9 @@ const (
 	ErrorCodeInvalidShardCid      = 5
 	ErrorCodeInvalidOrderProvider = 6
 	ErrorCodeInvalidShardAssignee = 7
+	ErrorCodeInsufficientSpace    = 8
+	// the request didn't reach the peer, connecting to it or opening the stream failed
+	ErrorCodeConnectFailed = 9
 
 	AssignTxTypeStore AssignTxType = "MsgStore"
 	AssignTxTypeReady AssignTxType = "MsgReady"
@@ -34,6 +50,26 @@ const (
 	FormatCbor string = "cbor"
 )
 
+/**
+ * response code of a failed transport request, connect failures are told apart as they are retried with backoff.
+ */
+func TransportErrorCode(err error) uint64 {
+	if ErrConnectFailed.Is(err) || ErrCreateStreamFailed.Is(err) {
+		return ErrorCodeConnectFailed
+	}
+	return ErrorCodeInternalErr
+}
+
+/**
+ * error of a failed response, the connect failures of the transport keep their error.
+ */
+func ResponseError(code uint64, message string) error {
+	if code == ErrorCodeConnectFailed {
+		return Wrapf(ErrConnectFailed, "%s", message)
+	}
+	return Wrapf(ErrFailuresResponsed, "%s", message)
+}
+
 type ShardStaging struct {
 	Basedir string
 }
@@ -47,6 +83,8 @@ type ShardLoadReq struct {
 	Proposal      MetadataProposalCbor
 	RequestId     int64
 	RelayProposal RelayProposalCbor
+	Offset        uint64 // start offset of the content in stream protocols
+	TraceContext  TraceContext
 }
 
 type ShardLoadResp struct {
@@ -67,6 +105,9 @@ type ShardAssignReq struct {
 	Height        int64
 	AssignTxType  AssignTxType
 	TimeoutHeight uint64
+	ShardCid      string // erasure coded shard cid, empty if the shard is a full replica
+	ShardSize     uint64 // erasure coded shard size, the chain only knows the order size
+	TraceContext  TraceContext
 }
 
 type ShardAssignResp struct {
@@ -75,11 +116,12 @@ type ShardAssignResp struct {
 }
 
 type ShardCompleteReq struct {
-	OrderId uint64
-	DataId  string
-	Cids    []cid.Cid
-	TxHash  string
-	Height  int64
+	OrderId      uint64
+	DataId       string
+	Cids         []cid.Cid
+	TxHash       string
+	Height       int64
+	TraceContext TraceContext
 }
 
 type ShardCompleteResp struct {
@@ -96,6 +138,7 @@ type ShardMigrateReq struct {
 	TxHeight    int64
 	Cid         string
 	Content     []byte
+	Size        uint64 // content size in stream protocols, Content is empty then
 }
 
 type ShardMigrateResp struct {
@@ -109,6 +152,51 @@ type ShardPingPong struct {
 	Local string
 }
 
+/**
+ * a piece of shard content in stream protocols, the stream ends with the Last chunk.
+ */
+type ShardChunk struct {
+	Offset  uint64
+	Cid     cid.Cid
+	Content []byte
+	Last    bool
+}
+
+/**
+ * acknowledgement of a stream request, tells the sender which offset to resume from.
+ */
+type ShardStreamAck struct {
+	Code    uint64
+	Message string
+	Offset  uint64
+}
+
+// ShardChallengeReq asks a storage node to prove it holds the shard with the given merkle leaves.
+type ShardChallengeReq struct {
+	Fisherman string
+	OrderId   uint64
+	Cid       cid.Cid
+	LeafSize  uint64
+	Leaves    []uint64
+	RequestId int64
+}
+
+type ShardLeafProof struct {
+	Index   uint64
+	Content []byte
+	Path    [][]byte // sibling hashes from the leaf up to the root
+}
+
+type ShardChallengeResp struct {
+	Code       uint64
+	Message    string
+	Cid        cid.Cid
+	LeafCount  uint64
+	Proofs     []ShardLeafProof
+	RequestId  int64
+	ResponseId int64
+}
+
 func (f *ShardMigrateReq) Unmarshal(r io.Reader, format string) error {
 	var err error
 	if format == FormatJson {
@@ -359,3 +447,107 @@ func (f *ShardPingPong) Marshal(w io.Writer, format string) error {
 	}
 	return err
 }
+
+func (f *ShardChunk) Unmarshal(r io.Reader, format string) error {
+	var err error
+	if format == FormatJson {
+		buf := &bytes.Buffer{}
+		buf.ReadFrom(r)
+		err = json.Unmarshal(buf.Bytes(), f)
+	} else {
+		err = f.UnmarshalCBOR(r)
+	}
+	return err
+}
+
+func (f *ShardChunk) Marshal(w io.Writer, format string) error {
+	var err error
+	if format == FormatJson {
+		bytes, err := json.Marshal(f)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(bytes)
+	} else {
+		err = f.MarshalCBOR(w)
+	}
+	return err
+}
+
+func (f *ShardStreamAck) Unmarshal(r io.Reader, format string) error {
+	var err error
+	if format == FormatJson {
+		buf := &bytes.Buffer{}
+		buf.ReadFrom(r)
+		err = json.Unmarshal(buf.Bytes(), f)
+	} else {
+		err = f.UnmarshalCBOR(r)
+	}
+	return err
+}
+
+func (f *ShardStreamAck) Marshal(w io.Writer, format string) error {
+	var err error
+	if format == FormatJson {
+		bytes, err := json.Marshal(f)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(bytes)
+	} else {
+		err = f.MarshalCBOR(w)
+	}
+	return err
+}
+
+func (f *ShardChallengeReq) Unmarshal(r io.Reader, format string) error {
+	var err error
+	if format == FormatJson {
+		buf := &bytes.Buffer{}
+		buf.ReadFrom(r)
+		err = json.Unmarshal(buf.Bytes(), f)
+	} else {
+		err = f.UnmarshalCBOR(r)
+	}
+	return err
+}
+
+func (f *ShardChallengeReq) Marshal(w io.Writer, format string) error {
+	var err error
+	if format == FormatJson {
+		bytes, err := json.Marshal(f)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(bytes)
+	} else {
+		err = f.MarshalCBOR(w)
+	}
+	return err
+}
+
+func (f *ShardChallengeResp) Unmarshal(r io.Reader, format string) error {
+	var err error
+	if format == FormatJson {
+		buf := &bytes.Buffer{}
+		buf.ReadFrom(r)
+		err = json.Unmarshal(buf.Bytes(), f)
+	} else {
+		err = f.UnmarshalCBOR(r)
+	}
+	return err
+}
+
+func (f *ShardChallengeResp) Marshal(w io.Writer, format string) error {
+	var err error
+	if format == FormatJson {
+		bytes, err := json.Marshal(f)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(bytes)
+	} else {
+		err = f.MarshalCBOR(w)
+	}
+	return err
+}
@@ -41,9 +41,10 @@ type OrderKey struct {
  */
 type OrderInfo struct {
 	// commit id
-	DataId string
-	Owner  string
-	Cid    cid.Cid
+	DataId  string
+	Owner   string
+	Cid     cid.Cid
+	GroupId string
 
 	// Staged
 	StagePath string
@@ -61,6 +62,13 @@ type OrderInfo struct {
 	Tries   uint64
 	RetryAt int64
 	LastErr string
+	// retry policy chosen by the last error, as class/kind
+	RetryPolicy string
+	// 1-based position in the scheduling queue when the status is queried, 0 if it's not queued
+	QueuePosition int64
+
+	// trace of the request that created the order
+	TraceContext TraceContext
 }
 
 type OrderState uint64
@@ -71,18 +79,54 @@ const (
 	OrderStateComplete
 	OrderStateTerminate
 	OrderStateExpired
+	OrderStateCancelled
 )
 
 var orderStateString = map[OrderState]string{
-	OrderStateStaged:   "Staged",
-	OrderStateReady:    "Ready",
-	OrderStateComplete: "Complete",
+	OrderStateStaged:    "Staged",
+	OrderStateReady:     "Ready",
+	OrderStateComplete:  "Complete",
+	OrderStateTerminate: "Terminate",
+	OrderStateExpired:   "Expired",
+	OrderStateCancelled: "Cancelled",
 }
 
 func (s OrderState) String() string {
 	return orderStateString[s]
 }
 
+const (
+	// the state of the order kept by the gateway
+	OrderEventOrder = "order"
+	// the state of a shard of the order kept by the gateway, Subject is the storage provider
+	OrderEventOrderShard = "order-shard"
+	// the state of a shard kept by the storage node, Subject is the shard cid
+	OrderEventShard = "shard"
+)
+
+/**
+ * a state transition of an order or of its shards, with the error that caused it if any.
+ */
+type OrderEvent struct {
+	OrderId uint64
+	Kind    string
+	Subject string
+	From    string
+	To      string
+	Tries   uint64
+	Message string
+	Time    int64
+}
+
+/**
+ * state transitions of an order on this node, oldest first.
+ */
+type OrderHistory struct {
+	DataId  string
+	OrderId uint64
+	Events  []OrderEvent
+}
+
 /**
  * shard state in order
  */
@@ -143,6 +187,11 @@ type ShardInfo struct {
 	State        ShardState
 	RetryAt      int64
 	LastErr      string
+	// retry policy chosen by the last error, as class/kind
+	RetryPolicy string
+
+	// trace of the shard assignment
+	TraceContext TraceContext
 }
 
 type ShardState uint64
@@ -169,6 +218,19 @@ func (s ShardState) String() string {
 	return shardStateString[s]
 }
 
+/**
+ * merkle commitment of a shard made by fishermen after verifying the whole content once,
+ * later audits only challenge a few leaves against it.
+ */
+type ShardCommitment struct {
+	Cid       string
+	Size      uint64
+	LeafSize  uint64
+	LeafCount uint64
+	Root      []byte
+	CreatedAt int64
+}
+
 type MigrateInfo struct {
 	DataId       string
 	OrderId      uint64
@@ -209,3 +271,55 @@ type MigrateKey struct {
 type MigrateIndex struct {
 	All []MigrateKey
 }
+
+type AuditRecord struct {
+	Provider string
+	DataId   string
+	OrderId  uint64
+	ShardId  uint64
+	Cid      string
+	Passed   bool
+	// the shard can't be audited yet, it's neither passed nor failed
+	Pending bool
+	Message string
+	// a fault of the shard reported by this node is open on chain after the audit
+	FaultOpen bool
+	AuditAt   int64
+}
+
+type AuditHistory struct {
+	Provider string
+	Records  []AuditRecord
+}
+
+type AuditKey struct {
+	Provider string
+}
+
+type AuditIndex struct {
+	All []AuditKey
+}
+
+type IndexJobInfo struct {
+	JobId   string
+	JobType string
+	// platform ids of the metadata job or addresses of the providers of the shard job
+	Param       string
+	Description string
+	// seconds between two runs, the job runs only once if it's 0
+	Interval  uint64
+	Status    string
+	CreatedAt int64
+	LastRunAt int64
+	LastError string
+	// failed runs in a row, the job is retried with exponential backoff
+	Retries uint64
+}
+
+type IndexJobKey struct {
+	JobId string
+}
+
+type IndexJobIndex struct {
+	All []IndexJobKey
+}
@@ -0,0 +1,38 @@
+package types
+
+const (
+	traceParentHeader = "traceparent"
+	traceStateHeader  = "tracestate"
+)
+
+/**
+ * W3C trace context carried by protocol requests and persisted tasks, so that spans of remote peers
+ * and of queued work join the trace that caused them.
+ */
+type TraceContext struct {
+	TraceParent string
+	TraceState  string
+}
+
+func (t *TraceContext) Get(key string) string {
+	switch key {
+	case traceParentHeader:
+		return t.TraceParent
+	case traceStateHeader:
+		return t.TraceState
+	}
+	return ""
+}
+
+func (t *TraceContext) Set(key string, value string) {
+	switch key {
+	case traceParentHeader:
+		t.TraceParent = value
+	case traceStateHeader:
+		t.TraceState = value
+	}
+}
+
+func (t *TraceContext) Keys() []string {
+	return []string{traceParentHeader, traceStateHeader}
+}
@@ -7,10 +7,18 @@ import (
 )
 
 const PEER_INFO_PREFIX = "peerInfo_"
-const FILE_INFO_PREFIX = "fileInfo_"
+const FILE_INFO_NAMESPACE = "fileInfo"
 
 const CHUNK_SIZE int = 32 * 1024 * 1024
 
+/**
+ * datastore key of the ReceivedFileInfo of the content uploaded by the peer, peers uploading
+ * the same content keep their own records under the namespace of the content.
+ */
+func FileInfoKey(peer string, cidStr string) string {
+	return FILE_INFO_NAMESPACE + "/" + cidStr + "/" + peer
+}
+
 type PeerInfo struct {
 	ID peer.ID
 	//Agent       string
@@ -44,6 +52,14 @@ type ReceivedFileInfo struct {
 	ChunkCids      []string
 }
 
+type UploadStatusResp struct {
+	Cid         string
+	TotalLength int
+	TotalChunks int
+	// cids of the received chunks, empty for the missing ones
+	ChunkCids []string
+}
+
 type RpcReq struct {
 	Method string
 	Params []string
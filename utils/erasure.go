@@ -0,0 +1,203 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"github.com/SaoNetwork/sao-node/types"
+
+	"github.com/klauspost/reedsolomon"
+)
+
+const (
+	ErasureShardMagic      = "SAOE"
+	ErasureShardVersion    = 1
+	ErasureShardHeaderSize = 16
+	// bytes of each shard decoded at a time by ErasureDecodeReaders
+	ErasureDecodeBlockSize = 64 * 1024
+)
+
+/**
+ * erasure shard header, prepended to every reed-solomon shard so that
+ * any k shards are enough to restore the original content.
+ */
+type ErasureShardHeader struct {
+	DataShards   uint8
+	ParityShards uint8
+	Index        uint8
+	Size         uint64
+}
+
+func (h ErasureShardHeader) TotalShards() int {
+	return int(h.DataShards) + int(h.ParityShards)
+}
+
+/**
+ * check if the content is a reed-solomon shard.
+ */
+func IsErasureShard(content []byte) bool {
+	if len(content) < ErasureShardHeaderSize {
+		return false
+	}
+	return bytes.Equal(content[:4], []byte(ErasureShardMagic)) && content[4] == ErasureShardVersion
+}
+
+func ParseErasureShardHeader(content []byte) (ErasureShardHeader, error) {
+	if !IsErasureShard(content) {
+		return ErasureShardHeader{}, types.Wrapf(types.ErrErasureDecodeFailed, "invalid erasure shard header")
+	}
+
+	h := ErasureShardHeader{
+		DataShards:   content[5],
+		ParityShards: content[6],
+		Index:        content[7],
+		Size:         binary.BigEndian.Uint64(content[8:ErasureShardHeaderSize]),
+	}
+	if h.DataShards == 0 || int(h.Index) >= h.TotalShards() {
+		return ErasureShardHeader{}, types.Wrapf(types.ErrErasureDecodeFailed, "invalid erasure shard header %v", h)
+	}
+	return h, nil
+}
+
+/**
+ * Split content into dataShards+parityShards reed-solomon shards, each prefixed with ErasureShardHeader.
+ */
+func ErasureEncode(content []byte, dataShards int, parityShards int) ([][]byte, error) {
+	if dataShards <= 0 || parityShards <= 0 || dataShards+parityShards > 256 {
+		return nil, types.Wrapf(types.ErrErasureEncodeFailed, "invalid shards number, data=%d parity=%d", dataShards, parityShards)
+	}
+	if len(content) == 0 {
+		return nil, types.Wrapf(types.ErrErasureEncodeFailed, "empty content")
+	}
+
+	enc, err := reedsolomon.New(dataShards, parityShards)
+	if err != nil {
+		return nil, types.Wrap(types.ErrErasureEncodeFailed, err)
+	}
+
+	shards, err := enc.Split(content)
+	if err != nil {
+		return nil, types.Wrap(types.ErrErasureEncodeFailed, err)
+	}
+	err = enc.Encode(shards)
+	if err != nil {
+		return nil, types.Wrap(types.ErrErasureEncodeFailed, err)
+	}
+
+	result := make([][]byte, len(shards))
+	for i, shard := range shards {
+		buf := make([]byte, ErasureShardHeaderSize, ErasureShardHeaderSize+len(shard))
+		copy(buf, ErasureShardMagic)
+		buf[4] = ErasureShardVersion
+		buf[5] = uint8(dataShards)
+		buf[6] = uint8(parityShards)
+		buf[7] = uint8(i)
+		binary.BigEndian.PutUint64(buf[8:ErasureShardHeaderSize], uint64(len(content)))
+		result[i] = append(buf, shard...)
+	}
+	return result, nil
+}
+
+/**
+ * Restore the original content from the readers of DataShards distinct reed-solomon shards. the shards are
+ * decoded block by block as they're read, so they never have to be held in memory. a reader has to end right
+ * after its shard, a reader failing at its end, e.g. on a cid mismatch of the shard, fails the decoding.
+ * the index of the reader failing the decoding is returned with the error, or -1 if no reader is to blame.
+ * the content size of the shard headers is untrusted, a header exceeding maxSize fails the decoding.
+ */
+func ErasureDecodeReaders(readers []io.Reader, maxSize uint64) ([]byte, int, error) {
+	if len(readers) == 0 {
+		return nil, -1, types.Wrapf(types.ErrErasureDecodeFailed, "no shards")
+	}
+
+	headers := make([]ErasureShardHeader, len(readers))
+	buf := make([]byte, ErasureShardHeaderSize)
+	for i, reader := range readers {
+		_, err := io.ReadFull(reader, buf)
+		if err != nil {
+			return nil, i, types.Wrap(types.ErrErasureDecodeFailed, err)
+		}
+		h, err := ParseErasureShardHeader(buf)
+		if err != nil {
+			return nil, i, err
+		}
+		if h.Size > maxSize {
+			return nil, i, types.Wrapf(types.ErrErasureDecodeFailed, "erasure shard content size %d exceeds %d", h.Size, maxSize)
+		}
+		if i > 0 && (h.DataShards != headers[0].DataShards || h.ParityShards != headers[0].ParityShards || h.Size != headers[0].Size) {
+			return nil, i, types.Wrapf(types.ErrErasureDecodeFailed, "inconsistent erasure shard header %v, expected %v", h, headers[0])
+		}
+		for j := 0; j < i; j++ {
+			if headers[j].Index == h.Index {
+				return nil, i, types.Wrapf(types.ErrErasureDecodeFailed, "duplicated erasure shard %d", h.Index)
+			}
+		}
+		headers[i] = h
+	}
+
+	first := headers[0]
+	dataShards := int(first.DataShards)
+	if len(readers) != dataShards {
+		return nil, -1, types.Wrapf(types.ErrErasureDecodeFailed, "need %d shards, got %d", dataShards, len(readers))
+	}
+	enc, err := reedsolomon.New(dataShards, int(first.ParityShards))
+	if err != nil {
+		return nil, -1, types.Wrap(types.ErrErasureDecodeFailed, err)
+	}
+
+	// data shards are joined in order, each decoded block goes right to its place in the content
+	perShard := (first.Size + uint64(dataShards) - 1) / uint64(dataShards)
+	content := make([]byte, first.Size)
+	bufs := make([][]byte, dataShards)
+	for i := range bufs {
+		bufs[i] = make([]byte, ErasureDecodeBlockSize)
+	}
+	blocks := make([][]byte, first.TotalShards())
+	for offset := uint64(0); offset < perShard; offset += ErasureDecodeBlockSize {
+		n := perShard - offset
+		if n > ErasureDecodeBlockSize {
+			n = ErasureDecodeBlockSize
+		}
+
+		for i := range blocks {
+			blocks[i] = nil
+		}
+		for i, reader := range readers {
+			block := bufs[i][:n]
+			_, err := io.ReadFull(reader, block)
+			if err != nil {
+				return nil, i, types.Wrap(types.ErrErasureDecodeFailed, err)
+			}
+			blocks[headers[i].Index] = block
+		}
+		err = enc.ReconstructData(blocks)
+		if err != nil {
+			return nil, -1, types.Wrap(types.ErrErasureDecodeFailed, err)
+		}
+
+		for i := 0; i < dataShards; i++ {
+			start := uint64(i)*perShard + offset
+			if start >= first.Size {
+				break
+			}
+			end := start + n
+			if end > first.Size {
+				end = first.Size
+			}
+			copy(content[start:end], blocks[i])
+		}
+	}
+
+	var extra [1]byte
+	for i, reader := range readers {
+		_, err := io.ReadFull(reader, extra[:])
+		if err == nil {
+			err = types.Wrapf(types.ErrErasureDecodeFailed, "erasure shard %d is longer than expected", headers[i].Index)
+		}
+		if err != io.EOF {
+			return nil, i, types.Wrap(types.ErrErasureDecodeFailed, err)
+		}
+	}
+	return content, -1, nil
+}
@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestErasureEncode(t *testing.T) {
+	content := bytes.Repeat([]byte("sao network erasure coding "), 1000)
+
+	shards, err := ErasureEncode(content, 3, 2)
+	require.NoError(t, err)
+	require.Len(t, shards, 5)
+	for i, shard := range shards {
+		require.True(t, IsErasureShard(shard))
+		header, err := ParseErasureShardHeader(shard)
+		require.NoError(t, err)
+		require.Equal(t, uint8(i), header.Index)
+		require.Equal(t, uint64(len(content)), header.Size)
+	}
+}
+
+func TestIsErasureShard(t *testing.T) {
+	require.False(t, IsErasureShard([]byte("abc")))
+	require.False(t, IsErasureShard(bytes.Repeat([]byte{0x01}, 64)))
+
+	_, err := ErasureEncode([]byte{}, 2, 1)
+	require.Error(t, err)
+	_, err = ErasureEncode([]byte("abc"), 0, 1)
+	require.Error(t, err)
+}
+
+func TestErasureDecodeReaders(t *testing.T) {
+	// shards span several decode blocks
+	content := bytes.Repeat([]byte("sao network erasure stream "), 3*ErasureDecodeBlockSize/10)
+	shards, err := ErasureEncode(content, 3, 2)
+	require.NoError(t, err)
+
+	readers := func(shards ...[]byte) []io.Reader {
+		result := make([]io.Reader, len(shards))
+		for i, shard := range shards {
+			result[i] = bytes.NewReader(shard)
+		}
+		return result
+	}
+
+	restored, bad, err := ErasureDecodeReaders(readers(shards[0], shards[1], shards[2]), uint64(len(content)))
+	require.NoError(t, err)
+	require.Equal(t, -1, bad)
+	require.Equal(t, content, restored)
+
+	restored, _, err = ErasureDecodeReaders(readers(shards[4], shards[1], shards[3]), uint64(len(content)))
+	require.NoError(t, err)
+	require.Equal(t, content, restored)
+
+	// the reader failing at the end of its shard is blamed
+	failing := readers(shards[4], shards[1], shards[3])
+	failing[1] = io.MultiReader(failing[1], iotestErrReader{})
+	_, bad, err = ErasureDecodeReaders(failing, uint64(len(content)))
+	require.Error(t, err)
+	require.Equal(t, 1, bad)
+
+	_, bad, err = ErasureDecodeReaders(readers(shards[0], shards[1], append(append([]byte{}, shards[2]...), 0)), uint64(len(content)))
+	require.Error(t, err)
+	require.Equal(t, 2, bad)
+
+	_, bad, err = ErasureDecodeReaders(readers(shards[0], shards[0], shards[2]), uint64(len(content)))
+	require.Error(t, err)
+	require.Equal(t, 1, bad)
+
+	_, _, err = ErasureDecodeReaders(readers(shards[0], shards[2]), uint64(len(content)))
+	require.Error(t, err)
+
+	// the content size of the headers is capped
+	_, bad, err = ErasureDecodeReaders(readers(shards[0], shards[1], shards[2]), uint64(len(content))-1)
+	require.Error(t, err)
+	require.Equal(t, 0, bad)
+}
+
+type iotestErrReader struct{}
+
+func (iotestErrReader) Read([]byte) (int, error) {
+	return 0, errors.New("cid mismatch")
+}
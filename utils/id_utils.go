@@ -1,6 +1,9 @@
 package utils
 
 import (
+	"crypto/sha256"
+	"hash"
+	"io"
 	"regexp"
 	"strings"
 
@@ -79,3 +82,36 @@ func CalculateCid(content []byte) (cid.Cid, error) {
 
 	return contentCid, nil
 }
+
+/**
+ * same as CalculateCid, but hash the content from reader without loading it into memory.
+ */
+func CalculateCidFromReader(reader io.Reader) (cid.Cid, uint64, error) {
+	h := NewCidHasher()
+	size, err := io.Copy(h, reader)
+	if err != nil {
+		return cid.Undef, 0, types.Wrap(types.ErrCalculateCidFailed, err)
+	}
+
+	c, err := h.Cid()
+	return c, uint64(size), err
+}
+
+/**
+ * CidHasher calculates the same cid as CalculateCid of the content written into it.
+ */
+type CidHasher struct {
+	hash.Hash
+}
+
+func NewCidHasher() *CidHasher {
+	return &CidHasher{sha256.New()}
+}
+
+func (h *CidHasher) Cid() (cid.Cid, error) {
+	mh, err := multihash.Encode(h.Sum(nil), multihash.SHA2_256)
+	if err != nil {
+		return cid.Undef, types.Wrap(types.ErrCalculateCidFailed, err)
+	}
+	return cid.NewCidV0(mh), nil
+}
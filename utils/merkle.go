@@ -0,0 +1,206 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+
+	"github.com/SaoNetwork/sao-node/types"
+)
+
+/**
+ * binary merkle tree over fixed size leaves of a shard content.
+ * leaf hash is sha256(0x00 || leaf), inner node hash is sha256(0x01 || left || right),
+ * the last node of a level without sibling is promoted to the upper level as is.
+ * the tree is built while the content is streamed, only the root and the paths of the kept leaves are held.
+ */
+type MerkleTree struct {
+	LeafSize uint64
+	Size     uint64
+	// contents of the leaves kept while building the tree
+	Leaves map[uint64][]byte
+
+	root      []byte
+	leafCount uint64
+	// sibling hashes of the kept leaves collected while building the tree
+	paths map[uint64][][]byte
+}
+
+func merkleLeafHash(leaf []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{0x00})
+	h.Write(leaf)
+	return h.Sum(nil)
+}
+
+func merkleNodeHash(left []byte, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{0x01})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+/**
+ * build the merkle tree of the content, contents and paths of leaves in keepLeaves are kept for proofs.
+ * only the pending left node of each level is held, so the memory doesn't grow with the content.
+ * empty content has a single empty leaf.
+ */
+func BuildMerkleTree(reader io.Reader, leafSize uint64, keepLeaves []uint64) (*MerkleTree, error) {
+	if leafSize == 0 {
+		return nil, types.Wrapf(types.ErrInvalidParameters, "leaf size should be positive")
+	}
+
+	tree := &MerkleTree{
+		LeafSize: leafSize,
+		Leaves:   make(map[uint64][]byte),
+		paths:    make(map[uint64][][]byte),
+	}
+	for _, index := range keepLeaves {
+		tree.paths[index] = nil
+	}
+
+	// pending[level] is the node waiting for its right sibling, counts[level] the number of nodes of the level so far
+	var pending [][]byte
+	var counts []uint64
+	var push func(level int, hash []byte)
+	push = func(level int, hash []byte) {
+		if level == len(pending) {
+			pending = append(pending, nil)
+			counts = append(counts, 0)
+		}
+		index := counts[level]
+		counts[level]++
+		if index%2 == 0 {
+			pending[level] = hash
+			return
+		}
+		left := pending[level]
+		pending[level] = nil
+		tree.addSiblings(level, index-1, left, hash)
+		push(level+1, merkleNodeHash(left, hash))
+	}
+
+	buf := make([]byte, leafSize)
+	for index := uint64(0); ; index++ {
+		n, err := io.ReadFull(reader, buf)
+		if err == io.EOF && index > 0 {
+			break
+		} else if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return nil, types.Wrap(types.ErrReadFileFailed, err)
+		}
+
+		if _, keep := tree.paths[index]; keep {
+			tree.Leaves[index] = append([]byte{}, buf[:n]...)
+		}
+		push(0, merkleLeafHash(buf[:n]))
+		tree.leafCount++
+		tree.Size += uint64(n)
+
+		if n < len(buf) {
+			break
+		}
+	}
+
+	// the last node of each level is either paired with the pending node or promoted as is
+	var last []byte
+	for level := range pending {
+		if pending[level] == nil {
+			continue
+		}
+		if last == nil {
+			last = pending[level]
+			continue
+		}
+		tree.addSiblings(level, counts[level]-1, pending[level], last)
+		last = merkleNodeHash(pending[level], last)
+	}
+	tree.root = last
+
+	for index := range tree.paths {
+		if index >= tree.leafCount {
+			delete(tree.paths, index)
+		}
+	}
+	return tree, nil
+}
+
+// the right node is the sibling of the kept leaves under the left node at the level, and vice versa
+func (t *MerkleTree) addSiblings(level int, leftIndex uint64, left []byte, right []byte) {
+	for index, path := range t.paths {
+		switch index >> level {
+		case leftIndex:
+			t.paths[index] = append(path, right)
+		case leftIndex + 1:
+			t.paths[index] = append(path, left)
+		}
+	}
+}
+
+func (t *MerkleTree) Root() []byte {
+	return t.root
+}
+
+func (t *MerkleTree) LeafCount() uint64 {
+	return t.leafCount
+}
+
+/**
+ * sibling hashes from the leaf up to the root, promoted levels have no sibling.
+ * only the paths of leaves kept while building the tree are known.
+ */
+func (t *MerkleTree) Proof(index uint64) ([][]byte, error) {
+	path, kept := t.paths[index]
+	if !kept {
+		return nil, types.Wrapf(types.ErrInvalidParameters, "leaf %d out of range %d or not kept", index, t.leafCount)
+	}
+	return path, nil
+}
+
+func VerifyMerkleProof(root []byte, leaf []byte, index uint64, leafCount uint64, path [][]byte) bool {
+	if index >= leafCount {
+		return false
+	}
+
+	hash := merkleLeafHash(leaf)
+	for width := leafCount; width > 1; width = (width + 1) / 2 {
+		sibling := index ^ 1
+		if sibling < width {
+			if len(path) == 0 {
+				return false
+			}
+			if index%2 == 0 {
+				hash = merkleNodeHash(hash, path[0])
+			} else {
+				hash = merkleNodeHash(path[0], hash)
+			}
+			path = path[1:]
+		}
+		index /= 2
+	}
+	return len(path) == 0 && bytes.Equal(hash, root)
+}
+
+/**
+ * answer a storage challenge with the requested leaves and their merkle paths.
+ */
+func BuildShardProofs(reader io.Reader, leafSize uint64, leaves []uint64) (uint64, []types.ShardLeafProof, error) {
+	tree, err := BuildMerkleTree(reader, leafSize, leaves)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var proofs []types.ShardLeafProof
+	for _, index := range leaves {
+		path, err := tree.Proof(index)
+		if err != nil {
+			return 0, nil, err
+		}
+		proofs = append(proofs, types.ShardLeafProof{
+			Index:   index,
+			Content: tree.Leaves[index],
+			Path:    path,
+		})
+	}
+	return tree.LeafCount(), proofs, nil
+}
@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerkleProofs(t *testing.T) {
+	leafSize := uint64(64)
+	// empty content, a single leaf, partial last leaf, odd and even leaf counts
+	for _, size := range []int{0, 10, 64, 100, 64 * 5, 64*7 + 3, 64 * 16} {
+		content := make([]byte, size)
+		for i := range content {
+			content[i] = byte(i) ^ byte(i/64)
+		}
+
+		tree, err := BuildMerkleTree(bytes.NewReader(content), leafSize, nil)
+		require.NoError(t, err)
+		require.Equal(t, uint64(size), tree.Size)
+		require.Equal(t, referenceMerkleRoot(content, leafSize), tree.Root())
+
+		var leaves []uint64
+		for i := uint64(0); i < tree.LeafCount(); i++ {
+			leaves = append(leaves, i)
+		}
+		leafCount, proofs, err := BuildShardProofs(bytes.NewReader(content), leafSize, leaves)
+		require.NoError(t, err)
+		require.Equal(t, tree.LeafCount(), leafCount)
+
+		for _, proof := range proofs {
+			require.True(t, VerifyMerkleProof(tree.Root(), proof.Content, proof.Index, leafCount, proof.Path), "size %d leaf %d", size, proof.Index)
+
+			// tampered content
+			tampered := append([]byte{0x01}, proof.Content...)
+			require.False(t, VerifyMerkleProof(tree.Root(), tampered, proof.Index, leafCount, proof.Path))
+
+			// wrong index
+			if leafCount > 1 {
+				require.False(t, VerifyMerkleProof(tree.Root(), proof.Content, (proof.Index+1)%leafCount, leafCount, proof.Path))
+			}
+		}
+	}
+
+	_, err := BuildMerkleTree(bytes.NewReader(nil), 0, nil)
+	require.Error(t, err)
+
+	tree, err := BuildMerkleTree(bytes.NewReader(make([]byte, 128)), leafSize, nil)
+	require.NoError(t, err)
+	_, err = tree.Proof(2)
+	require.Error(t, err)
+}
+
+// the merkle root built level by level with all the hashes in memory
+func referenceMerkleRoot(content []byte, leafSize uint64) []byte {
+	var level [][]byte
+	for offset := uint64(0); offset == 0 || offset < uint64(len(content)); offset += leafSize {
+		end := offset + leafSize
+		if end > uint64(len(content)) {
+			end = uint64(len(content))
+		}
+		level = append(level, merkleLeafHash(content[offset:end]))
+	}
+	for len(level) > 1 {
+		var upper [][]byte
+		for i := 0; i < len(level); i += 2 {
+			if i+1 < len(level) {
+				upper = append(upper, merkleNodeHash(level[i], level[i+1]))
+			} else {
+				upper = append(upper, level[i])
+			}
+		}
+		level = upper
+	}
+	return level[0]
+}
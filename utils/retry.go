@@ -0,0 +1,137 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/SaoNetwork/sao-node/types"
+
+	"github.com/cosmos/cosmos-sdk/types/errors"
+)
+
+const (
+	// wait Interval * Multiplier^(tries-1), capped at MaxInterval
+	RetryExponential = "exponential"
+	// retry at once
+	RetryImmediate = "immediate"
+	// give up after the first failure
+	RetryNone = "none"
+)
+
+const (
+	RetryClassDefault    = "default"
+	RetryClassChain      = "chain"
+	RetryClassConnect    = "connect"
+	RetryClassNetwork    = "network"
+	RetryClassInvalidCid = "invalid-cid"
+)
+
+type RetryPolicy struct {
+	Kind        string
+	MaxRetries  uint64
+	Interval    time.Duration
+	MaxInterval time.Duration
+	Multiplier  float64
+	// fraction of the interval randomly added or removed
+	Jitter float64
+}
+
+/**
+ * retry policies of failed orders and shards by the class of the error.
+ */
+type RetryPolicies struct {
+	Default    RetryPolicy
+	Chain      RetryPolicy
+	Connect    RetryPolicy
+	Network    RetryPolicy
+	InvalidCid RetryPolicy
+}
+
+func (rp RetryPolicies) Validate() error {
+	for class, p := range map[string]RetryPolicy{
+		RetryClassDefault:    rp.Default,
+		RetryClassChain:      rp.Chain,
+		RetryClassConnect:    rp.Connect,
+		RetryClassNetwork:    rp.Network,
+		RetryClassInvalidCid: rp.InvalidCid,
+	} {
+		switch p.Kind {
+		case RetryExponential:
+			if p.Interval <= 0 || p.Multiplier < 1 || p.Jitter < 0 || p.Jitter >= 1 {
+				return types.Wrapf(types.ErrInvalidParameters, "invalid %s retry policy: interval %v multiplier %v jitter %v",
+					class, p.Interval, p.Multiplier, p.Jitter)
+			}
+		case RetryImmediate, RetryNone:
+		default:
+			return types.Wrapf(types.ErrInvalidParameters, "invalid %s retry policy kind %s", class, p.Kind)
+		}
+	}
+	return nil
+}
+
+/**
+ * the class of an error deciding its retry policy, errors are classified by their module.
+ */
+func RetryClass(err error) string {
+	if types.ErrInvalidCid.Is(err) {
+		return RetryClassInvalidCid
+	}
+	// the peer may be down for a while, unlike a stream broken in the middle
+	if types.ErrConnectFailed.Is(err) || types.ErrCreateStreamFailed.Is(err) {
+		return RetryClassConnect
+	}
+	codespace, _, _ := errors.ABCIInfo(err, false)
+	switch codespace {
+	case types.ModuleChain:
+		return RetryClassChain
+	case types.ModuleNetwork:
+		return RetryClassNetwork
+	}
+	return RetryClassDefault
+}
+
+func (rp RetryPolicies) Policy(class string) RetryPolicy {
+	switch class {
+	case RetryClassChain:
+		return rp.Chain
+	case RetryClassConnect:
+		return rp.Connect
+	case RetryClassNetwork:
+		return rp.Network
+	case RetryClassInvalidCid:
+		return rp.InvalidCid
+	}
+	return rp.Default
+}
+
+/**
+ * decide the retry of a failed attempt, tries counts the attempts so far including the failed one, so the policy
+ * gives up once tries exceeds MaxRetries, after the first attempt and MaxRetries retries.
+ * returns the chosen policy as class/kind and the unix time of the next attempt, ok is false if it gives up.
+ */
+func (rp RetryPolicies) NextRetry(err error, tries uint64) (policy string, retryAt int64, ok bool) {
+	class := RetryClass(err)
+	p := rp.Policy(class)
+	policy = fmt.Sprintf("%s/%s", class, p.Kind)
+
+	if p.Kind == RetryNone || tries > p.MaxRetries {
+		return policy, 0, false
+	}
+	if p.Kind == RetryImmediate {
+		return policy, time.Now().Unix(), true
+	}
+	return policy, time.Now().Add(p.backoff(tries)).Unix(), true
+}
+
+func (p RetryPolicy) backoff(tries uint64) time.Duration {
+	interval := float64(p.Interval) * math.Pow(p.Multiplier, float64(tries)-1)
+	if p.MaxInterval > 0 && interval > float64(p.MaxInterval) {
+		interval = float64(p.MaxInterval)
+	}
+	if p.Jitter > 0 {
+		interval += interval * p.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(interval)
+}
@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/SaoNetwork/sao-node/types"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+)
+
+func TestRetryPolicies(t *testing.T) {
+	rp := RetryPolicies{
+		Default:    RetryPolicy{Kind: RetryExponential, MaxRetries: 3, Interval: time.Minute, MaxInterval: 5 * time.Minute, Multiplier: 3},
+		Chain:      RetryPolicy{Kind: RetryExponential, MaxRetries: 5, Interval: time.Hour, Multiplier: 2, Jitter: 0.5},
+		Connect:    RetryPolicy{Kind: RetryExponential, MaxRetries: 5, Interval: time.Second, MaxInterval: time.Minute, Multiplier: 2},
+		Network:    RetryPolicy{Kind: RetryImmediate, MaxRetries: 2},
+		InvalidCid: RetryPolicy{Kind: RetryNone},
+	}
+	require.NoError(t, rp.Validate())
+
+	require.Equal(t, RetryClassDefault, RetryClass(xerrors.New("unknown")))
+	require.Equal(t, RetryClassChain, RetryClass(types.Wrapf(types.ErrTxProcessFailed, "tx failed")))
+	require.Equal(t, RetryClassNetwork, RetryClass(types.Wrapf(types.ErrFailuresResponsed, "peer unreachable")))
+	require.Equal(t, RetryClassConnect, RetryClass(types.Wrapf(types.ErrConnectFailed, "dial backoff")))
+	require.Equal(t, RetryClassConnect, RetryClass(types.ResponseError(types.ErrorCodeConnectFailed, "protocols not supported")))
+	require.Equal(t, RetryClassNetwork, RetryClass(types.ResponseError(types.ErrorCodeInternalErr, "stream reset")))
+	require.Equal(t, RetryClassInvalidCid, RetryClass(types.Wrapf(types.ErrInvalidCid, "content cid mismatch")))
+
+	now := time.Now()
+	policy, retryAt, ok := rp.NextRetry(xerrors.New("unknown"), 2)
+	require.True(t, ok)
+	require.Equal(t, "default/exponential", policy)
+	require.InDelta(t, now.Add(3*time.Minute).Unix(), retryAt, 2)
+
+	// the interval is capped
+	_, retryAt, ok = rp.NextRetry(xerrors.New("unknown"), 3)
+	require.True(t, ok)
+	require.InDelta(t, now.Add(5*time.Minute).Unix(), retryAt, 2)
+
+	// the first attempt and MaxRetries retries
+	_, _, ok = rp.NextRetry(xerrors.New("unknown"), 4)
+	require.False(t, ok)
+
+	_, retryAt, ok = rp.NextRetry(types.Wrapf(types.ErrTxProcessFailed, "tx failed"), 1)
+	require.True(t, ok)
+	require.GreaterOrEqual(t, retryAt, now.Add(30*time.Minute).Unix())
+	require.LessOrEqual(t, retryAt, now.Add(90*time.Minute).Unix()+1)
+
+	policy, retryAt, ok = rp.NextRetry(types.Wrapf(types.ErrFailuresResponsed, "peer unreachable"), 1)
+	require.True(t, ok)
+	require.Equal(t, "network/immediate", policy)
+	require.InDelta(t, now.Unix(), retryAt, 2)
+
+	// connect failures back off and are capped
+	policy, retryAt, ok = rp.NextRetry(types.Wrapf(types.ErrCreateStreamFailed, "no addresses"), 5)
+	require.True(t, ok)
+	require.Equal(t, "connect/exponential", policy)
+	require.InDelta(t, now.Add(16*time.Second).Unix(), retryAt, 2)
+	_, _, ok = rp.NextRetry(types.Wrapf(types.ErrCreateStreamFailed, "no addresses"), 6)
+	require.False(t, ok)
+
+	policy, _, ok = rp.NextRetry(types.Wrapf(types.ErrInvalidCid, "content cid mismatch"), 1)
+	require.False(t, ok)
+	require.Equal(t, "invalid-cid/none", policy)
+
+	rp.Chain.Kind = "linear"
+	require.Error(t, rp.Validate())
+}
@@ -4,25 +4,49 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/SaoNetwork/sao-node/types"
 
 	"github.com/ipfs/go-cid"
 	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+	logging "github.com/ipfs/go-log/v2"
 )
 
+var log = logging.Logger("utils")
+
 const (
 	ORDER_INDEX_KEY        = "order-index"
 	ORDER_KEY              = "order-%s"
+	ORDER_ID_KEY           = "order-id-%d"
 	SHARD_INDEX_KEY        = "shard-index"
 	SHARD_KEY              = "order-%d-shard-%v"
 	MIGRATE_INDEX_KEY      = "migrate-index"
 	MIGRATE_KEY            = "migrate-dataid-%s-from-%s"
 	SHARD_EXPIRE_INDEX_KEY = "shard-expire"
 	SHARD_EXPIRE_KEY       = "shard-expire-%d"
+	SHARD_COMMITMENT_KEY   = "shard-commitment-%s"
+	AUDIT_INDEX_KEY        = "audit-index"
+	AUDIT_KEY              = "audit-%s"
+	EVENT_HEIGHT_KEY       = "event-height-%s"
+	ORDER_HISTORY_KEY      = "order-history-%s-%d"
+	INDEX_JOB_INDEX_KEY    = "index-job-index"
+	INDEX_JOB_KEY          = "index-job-%s"
+
+	// audit records kept for each provider, older ones are dropped
+	MAX_AUDIT_RECORDS = 1000
+	// state transitions kept for each order, older ones are dropped
+	MAX_ORDER_EVENTS = 500
 )
 
+// order histories are appended by the gateway and storage modules sharing the datastore
+var orderHistoryLk sync.Mutex
+
 // -----
 // shard cid
 // -----
@@ -196,6 +220,13 @@ func SaveOrder(ctx context.Context, ds datastore.Batching, order types.OrderInfo
 	if err != nil {
 		return err
 	}
+	var prev types.OrderInfo
+	if exists {
+		prev, err = GetOrder(ctx, ds, order.DataId)
+		if err != nil {
+			return err
+		}
+	}
 
 	buf := new(bytes.Buffer)
 	err = order.MarshalCBOR(buf)
@@ -213,9 +244,71 @@ func SaveOrder(ctx context.Context, ds datastore.Batching, order types.OrderInfo
 			return err
 		}
 	}
+	if order.OrderId > 0 && (!exists || prev.OrderId != order.OrderId) {
+		err = ds.Put(ctx, datastore.NewKey(fmt.Sprintf(ORDER_ID_KEY, order.OrderId)), []byte(order.DataId))
+		if err != nil {
+			return err
+		}
+	}
+	// the order is saved already, a failure of its history doesn't fail the save
+	if exists && prev.OrderId == 0 && order.OrderId > 0 {
+		err = moveStagedOrderEvents(ctx, ds, order.DataId, order.OrderId)
+		if err != nil {
+			log.Warnf("move staged history of order %s to %d error: %v", order.DataId, order.OrderId, err)
+		}
+	}
+	err = AppendOrderEvents(ctx, ds, order.DataId, order.OrderId, orderEvents(prev, order, exists)...)
+	if err != nil {
+		log.Warnf("append history of order %s error: %v", order.DataId, err)
+	}
 	return nil
 }
 
+/**
+ * transitions of the order and its shards from the previously saved state.
+ */
+func orderEvents(prev types.OrderInfo, order types.OrderInfo, exists bool) []types.OrderEvent {
+	now := time.Now().UnixMilli()
+	var events []types.OrderEvent
+	from := ""
+	if exists {
+		from = prev.State.String()
+	}
+	// a failed retry keeps the state, but the error is recorded
+	if !exists || prev.State != order.State || (order.LastErr != "" && order.LastErr != prev.LastErr) {
+		events = append(events, types.OrderEvent{
+			Kind:    types.OrderEventOrder,
+			From:    from,
+			To:      order.State.String(),
+			Tries:   order.Tries,
+			Message: order.LastErr,
+			Time:    now,
+		})
+	}
+
+	providers := make([]string, 0, len(order.Shards))
+	for provider := range order.Shards {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+	for _, provider := range providers {
+		shard := order.Shards[provider]
+		prevShard, ok := prev.Shards[provider]
+		if ok && prevShard.State == shard.State {
+			continue
+		}
+		events = append(events, types.OrderEvent{
+			Kind:    types.OrderEventOrderShard,
+			Subject: provider,
+			From:    string(prevShard.State),
+			To:      string(shard.State),
+			Tries:   order.Tries,
+			Time:    now,
+		})
+	}
+	return events
+}
+
 /**
  * Get order state from datastore.
  */
@@ -242,6 +335,28 @@ func GetOrder(ctx context.Context, ds datastore.Batching, id string) (types.Orde
 	return orderInfo, nil
 }
 
+/**
+ * Get order state of the chain order id from datastore, the order is empty if the order id is not saved here.
+ */
+func GetOrderByOrderId(ctx context.Context, ds datastore.Batching, orderId uint64) (types.OrderInfo, error) {
+	dataId, err := ds.Get(ctx, datastore.NewKey(fmt.Sprintf(ORDER_ID_KEY, orderId)))
+	if err == datastore.ErrNotFound {
+		return types.OrderInfo{}, nil
+	} else if err != nil {
+		return types.OrderInfo{}, err
+	}
+
+	order, err := GetOrder(ctx, ds, string(dataId))
+	if err != nil {
+		return types.OrderInfo{}, err
+	}
+	// the data model is updated with a new order
+	if order.OrderId != orderId {
+		return types.OrderInfo{}, nil
+	}
+	return order, nil
+}
+
 /**
  * update order index.
  */
@@ -434,6 +549,13 @@ func SaveShard(ctx context.Context, ds datastore.Batching, shard types.ShardInfo
 	if err != nil {
 		return err
 	}
+	var prev types.ShardInfo
+	if exists {
+		prev, err = GetShard(ctx, ds, shard.OrderId, shard.Cid)
+		if err != nil {
+			return err
+		}
+	}
 
 	buf := new(bytes.Buffer)
 	err = shard.MarshalCBOR(buf)
@@ -450,6 +572,26 @@ func SaveShard(ctx context.Context, ds datastore.Batching, shard types.ShardInfo
 			return err
 		}
 	}
+
+	if !exists || prev.State != shard.State || (shard.LastErr != "" && shard.LastErr != prev.LastErr) {
+		from := ""
+		if exists {
+			from = prev.State.String()
+		}
+		// the shard is saved already, a failure of its history doesn't fail the save
+		err = AppendOrderEvents(ctx, ds, shard.DataId, shard.OrderId, types.OrderEvent{
+			Kind:    types.OrderEventShard,
+			Subject: shard.Cid.String(),
+			From:    from,
+			To:      shard.State.String(),
+			Tries:   shard.Tries,
+			Message: shard.LastErr,
+			Time:    time.Now().UnixMilli(),
+		})
+		if err != nil {
+			log.Warnf("append history of shard %v of order %d error: %v", shard.Cid, shard.OrderId, err)
+		}
+	}
 	return nil
 }
 
@@ -545,15 +687,416 @@ func GetShardIndex(ctx context.Context, ds datastore.Batching) (types.ShardIndex
 	return index, err
 }
 
-const RetryIntervalCoeff time.Duration = 3
+// -----
+// shard commitment
+// -----
+func shardCommitmentDatastoreKey(cid string) datastore.Key {
+	return datastore.NewKey(fmt.Sprintf(SHARD_COMMITMENT_KEY, cid))
+}
+
+func SaveShardCommitment(ctx context.Context, ds datastore.Batching, commitment types.ShardCommitment) error {
+	key := shardCommitmentDatastoreKey(commitment.Cid)
+
+	buf := new(bytes.Buffer)
+	err := commitment.MarshalCBOR(buf)
+	if err != nil {
+		return err
+	}
+	return ds.Put(ctx, key, buf.Bytes())
+}
+
+func GetShardCommitment(ctx context.Context, ds datastore.Batching, cid string) (types.ShardCommitment, error) {
+	key := shardCommitmentDatastoreKey(cid)
+	exists, err := ds.Has(ctx, key)
+	if err != nil {
+		return types.ShardCommitment{}, err
+	}
+	if !exists {
+		return types.ShardCommitment{}, nil
+	}
+
+	bs, err := ds.Get(ctx, key)
+	if err != nil {
+		return types.ShardCommitment{}, err
+	}
+
+	var commitment types.ShardCommitment
+	err = commitment.UnmarshalCBOR(bytes.NewReader(bs))
+	if err != nil {
+		return types.ShardCommitment{}, err
+	}
+	return commitment, nil
+}
+
+// -----
+// audit
+// -----
+func auditDatastoreKey(provider string) datastore.Key {
+	return datastore.NewKey(fmt.Sprintf(AUDIT_KEY, provider))
+}
+
+func SaveAuditRecords(ctx context.Context, ds datastore.Batching, provider string, records []types.AuditRecord) error {
+	history, err := GetAuditHistory(ctx, ds, provider)
+	if err != nil {
+		return err
+	}
+	isNew := history.Provider == ""
+
+	history.Provider = provider
+	history.Records = append(history.Records, records...)
+	if len(history.Records) > MAX_AUDIT_RECORDS {
+		history.Records = history.Records[len(history.Records)-MAX_AUDIT_RECORDS:]
+	}
+
+	buf := new(bytes.Buffer)
+	err = history.MarshalCBOR(buf)
+	if err != nil {
+		return err
+	}
+	err = ds.Put(ctx, auditDatastoreKey(provider), buf.Bytes())
+	if err != nil {
+		return err
+	}
+	if isNew {
+		return UpdateAuditIndex(ctx, ds, provider)
+	}
+	return nil
+}
+
+func GetAuditHistory(ctx context.Context, ds datastore.Batching, provider string) (types.AuditHistory, error) {
+	key := auditDatastoreKey(provider)
+	exists, err := ds.Has(ctx, key)
+	if err != nil {
+		return types.AuditHistory{}, err
+	}
+	if !exists {
+		return types.AuditHistory{}, nil
+	}
+
+	bs, err := ds.Get(ctx, key)
+	if err != nil {
+		return types.AuditHistory{}, err
+	}
+
+	var history types.AuditHistory
+	err = history.UnmarshalCBOR(bytes.NewReader(bs))
+	if err != nil {
+		return types.AuditHistory{}, err
+	}
+	return history, nil
+}
+
+func UpdateAuditIndex(ctx context.Context, ds datastore.Batching, provider string) error {
+	index, err := GetAuditIndex(ctx, ds)
+	if err != nil {
+		return err
+	}
+	index.All = append(index.All, types.AuditKey{Provider: provider})
+
+	buf := new(bytes.Buffer)
+	err = index.MarshalCBOR(buf)
+	if err != nil {
+		return err
+	}
+	return ds.Put(ctx, datastore.NewKey(AUDIT_INDEX_KEY), buf.Bytes())
+}
+
+func GetAuditIndex(ctx context.Context, ds datastore.Batching) (types.AuditIndex, error) {
+	key := datastore.NewKey(AUDIT_INDEX_KEY)
+	exists, err := ds.Has(ctx, key)
+	if err != nil {
+		return types.AuditIndex{}, err
+	}
+	if !exists {
+		return types.AuditIndex{}, nil
+	}
+
+	data, err := ds.Get(ctx, key)
+	if err != nil {
+		return types.AuditIndex{}, err
+	}
+
+	var index types.AuditIndex
+	err = index.UnmarshalCBOR(bytes.NewReader(data))
+	return index, err
+}
+
+// -----
+// event height
+// -----
+func eventHeightDatastoreKey(name string) datastore.Key {
+	return datastore.NewKey(fmt.Sprintf(EVENT_HEIGHT_KEY, name))
+}
+
+/**
+ * save the height of the last chain event handled by the named subscription, it's resumed from there after restart.
+ */
+func SaveEventHeight(ctx context.Context, ds datastore.Batching, name string, height int64) error {
+	return ds.Put(ctx, eventHeightDatastoreKey(name), []byte(strconv.FormatInt(height, 10)))
+}
+
+/**
+ * get the height of the last chain event handled by the named subscription, 0 if nothing is handled yet.
+ */
+func GetEventHeight(ctx context.Context, ds datastore.Batching, name string) (int64, error) {
+	key := eventHeightDatastoreKey(name)
+	exists, err := ds.Has(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	if !exists {
+		return 0, nil
+	}
+
+	data, err := ds.Get(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(string(data), 10, 64)
+}
+
+// -----
+// order history
+// -----
+func orderHistoryDatastoreKey(dataId string, orderId uint64) datastore.Key {
+	return datastore.NewKey(fmt.Sprintf(ORDER_HISTORY_KEY, dataId, orderId))
+}
+
+/**
+ * append events to the history of an order, events of an order not yet submitted to chain are kept under order id 0.
+ */
+func AppendOrderEvents(ctx context.Context, ds datastore.Batching, dataId string, orderId uint64, events ...types.OrderEvent) error {
+	if len(events) == 0 || dataId == "" {
+		return nil
+	}
+
+	orderHistoryLk.Lock()
+	defer orderHistoryLk.Unlock()
+
+	history, err := GetOrderHistory(ctx, ds, dataId, orderId)
+	if err != nil {
+		return err
+	}
+	for i := range events {
+		events[i].OrderId = orderId
+	}
+	return putOrderHistory(ctx, ds, dataId, orderId, append(history.Events, events...))
+}
+
+/**
+ * move the events recorded while the order was staged to the order id it got on chain.
+ */
+func moveStagedOrderEvents(ctx context.Context, ds datastore.Batching, dataId string, orderId uint64) error {
+	orderHistoryLk.Lock()
+	defer orderHistoryLk.Unlock()
+
+	staged, err := GetOrderHistory(ctx, ds, dataId, 0)
+	if err != nil || len(staged.Events) == 0 {
+		return err
+	}
+	history, err := GetOrderHistory(ctx, ds, dataId, orderId)
+	if err != nil {
+		return err
+	}
+	for i := range staged.Events {
+		staged.Events[i].OrderId = orderId
+	}
+	err = putOrderHistory(ctx, ds, dataId, orderId, append(staged.Events, history.Events...))
+	if err != nil {
+		return err
+	}
+	return ds.Delete(ctx, orderHistoryDatastoreKey(dataId, 0))
+}
+
+func putOrderHistory(ctx context.Context, ds datastore.Batching, dataId string, orderId uint64, events []types.OrderEvent) error {
+	if len(events) > MAX_ORDER_EVENTS {
+		events = events[len(events)-MAX_ORDER_EVENTS:]
+	}
+	history := types.OrderHistory{
+		DataId:  dataId,
+		OrderId: orderId,
+		Events:  events,
+	}
+
+	buf := new(bytes.Buffer)
+	err := history.MarshalCBOR(buf)
+	if err != nil {
+		return err
+	}
+	return ds.Put(ctx, orderHistoryDatastoreKey(dataId, orderId), buf.Bytes())
+}
+
+func GetOrderHistory(ctx context.Context, ds datastore.Batching, dataId string, orderId uint64) (types.OrderHistory, error) {
+	key := orderHistoryDatastoreKey(dataId, orderId)
+	exists, err := ds.Has(ctx, key)
+	if err != nil {
+		return types.OrderHistory{}, err
+	}
+	if !exists {
+		return types.OrderHistory{}, nil
+	}
+
+	bs, err := ds.Get(ctx, key)
+	if err != nil {
+		return types.OrderHistory{}, err
+	}
+
+	var history types.OrderHistory
+	err = history.UnmarshalCBOR(bytes.NewReader(bs))
+	if err != nil {
+		return types.OrderHistory{}, err
+	}
+	return history, nil
+}
 
 /**
- * Get order retry timestamp.
+ * events of all orders of the data, oldest first.
  */
-func GetRetryAt(tries uint64) int64 {
-	retryInterval := time.Second
-	for i := uint64(0); i < tries; i++ {
-		retryInterval *= RetryIntervalCoeff
+func ListOrderEvents(ctx context.Context, ds datastore.Batching, dataId string) ([]types.OrderEvent, error) {
+	results, err := ds.Query(ctx, query.Query{})
+	if err != nil {
+		return nil, err
+	}
+	defer results.Close()
+
+	prefix := datastore.NewKey(fmt.Sprintf("order-history-%s-", dataId)).String()
+	var events []types.OrderEvent
+	for result := range results.Next() {
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if !strings.HasPrefix(result.Key, prefix) {
+			continue
+		}
+		// another data id sharing the prefix
+		if _, err := strconv.ParseUint(strings.TrimPrefix(result.Key, prefix), 10, 64); err != nil {
+			continue
+		}
+
+		var history types.OrderHistory
+		err = history.UnmarshalCBOR(bytes.NewReader(result.Value))
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, history.Events...)
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].Time != events[j].Time {
+			return events[i].Time < events[j].Time
+		}
+		return events[i].OrderId < events[j].OrderId
+	})
+	return events, nil
+}
+
+// -----
+// index job
+// -----
+func indexJobDatastoreKey(jobId string) datastore.Key {
+	return datastore.NewKey(fmt.Sprintf(INDEX_JOB_KEY, jobId))
+}
+
+func SaveIndexJob(ctx context.Context, ds datastore.Batching, job types.IndexJobInfo) error {
+	key := indexJobDatastoreKey(job.JobId)
+	exists, err := ds.Has(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	buf := new(bytes.Buffer)
+	err = job.MarshalCBOR(buf)
+	if err != nil {
+		return err
+	}
+	err = ds.Put(ctx, key, buf.Bytes())
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return UpdateIndexJobIndex(ctx, ds, job.JobId)
+	}
+	return nil
+}
+
+func DeleteIndexJob(ctx context.Context, ds datastore.Batching, jobId string) error {
+	err := ds.Delete(ctx, indexJobDatastoreKey(jobId))
+	if err != nil {
+		return err
+	}
+
+	index, err := GetIndexJobIndex(ctx, ds)
+	if err != nil {
+		return err
+	}
+	for i, k := range index.All {
+		if k.JobId == jobId {
+			index.All = append(index.All[:i], index.All[i+1:]...)
+			break
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	err = index.MarshalCBOR(buf)
+	if err != nil {
+		return err
+	}
+	return ds.Put(ctx, datastore.NewKey(INDEX_JOB_INDEX_KEY), buf.Bytes())
+}
+
+func GetIndexJob(ctx context.Context, ds datastore.Batching, jobId string) (types.IndexJobInfo, error) {
+	key := indexJobDatastoreKey(jobId)
+	exists, err := ds.Has(ctx, key)
+	if err != nil {
+		return types.IndexJobInfo{}, err
+	}
+	if !exists {
+		return types.IndexJobInfo{}, nil
 	}
-	return time.Now().Add(retryInterval).Unix()
+
+	bs, err := ds.Get(ctx, key)
+	if err != nil {
+		return types.IndexJobInfo{}, err
+	}
+
+	var job types.IndexJobInfo
+	err = job.UnmarshalCBOR(bytes.NewReader(bs))
+	if err != nil {
+		return types.IndexJobInfo{}, err
+	}
+	return job, nil
+}
+
+func UpdateIndexJobIndex(ctx context.Context, ds datastore.Batching, jobId string) error {
+	index, err := GetIndexJobIndex(ctx, ds)
+	if err != nil {
+		return err
+	}
+	index.All = append(index.All, types.IndexJobKey{JobId: jobId})
+
+	buf := new(bytes.Buffer)
+	err = index.MarshalCBOR(buf)
+	if err != nil {
+		return err
+	}
+	return ds.Put(ctx, datastore.NewKey(INDEX_JOB_INDEX_KEY), buf.Bytes())
+}
+
+func GetIndexJobIndex(ctx context.Context, ds datastore.Batching) (types.IndexJobIndex, error) {
+	key := datastore.NewKey(INDEX_JOB_INDEX_KEY)
+	exists, err := ds.Has(ctx, key)
+	if err != nil {
+		return types.IndexJobIndex{}, err
+	}
+	if !exists {
+		return types.IndexJobIndex{}, nil
+	}
+
+	data, err := ds.Get(ctx, key)
+	if err != nil {
+		return types.IndexJobIndex{}, err
+	}
+
+	var index types.IndexJobIndex
+	err = index.UnmarshalCBOR(bytes.NewReader(data))
+	return index, err
 }
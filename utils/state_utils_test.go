@@ -0,0 +1,178 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/SaoNetwork/sao-node/types"
+
+	"github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditHistory(t *testing.T) {
+	ctx := context.Background()
+	ds := dssync.MutexWrap(datastore.NewMapDatastore())
+
+	history, err := GetAuditHistory(ctx, ds, "provider1")
+	require.NoError(t, err)
+	require.Empty(t, history.Records)
+
+	for i := 0; i < MAX_AUDIT_RECORDS+10; i++ {
+		err = SaveAuditRecords(ctx, ds, "provider1", []types.AuditRecord{{Provider: "provider1", ShardId: uint64(i)}})
+		require.NoError(t, err)
+	}
+	err = SaveAuditRecords(ctx, ds, "provider2", []types.AuditRecord{{Provider: "provider2", Passed: true}})
+	require.NoError(t, err)
+
+	// older records are dropped
+	history, err = GetAuditHistory(ctx, ds, "provider1")
+	require.NoError(t, err)
+	require.Len(t, history.Records, MAX_AUDIT_RECORDS)
+	require.Equal(t, uint64(10), history.Records[0].ShardId)
+
+	index, err := GetAuditIndex(ctx, ds)
+	require.NoError(t, err)
+	require.Equal(t, []types.AuditKey{{Provider: "provider1"}, {Provider: "provider2"}}, index.All)
+}
+
+func TestOrderHistory(t *testing.T) {
+	ctx := context.Background()
+	ds := dssync.MutexWrap(datastore.NewMapDatastore())
+
+	contentCid, err := CalculateCid([]byte("order history"))
+	require.NoError(t, err)
+	order := types.OrderInfo{DataId: "data1", Cid: contentCid, State: types.OrderStateStaged}
+	require.NoError(t, SaveOrder(ctx, ds, order))
+
+	// the staged events move to the order id once the order is on chain
+	order.OrderId = 1
+	order.State = types.OrderStateReady
+	order.Shards = map[string]types.OrderShardInfo{"sp1": {State: types.ShardStateAssigned}}
+	require.NoError(t, SaveOrder(ctx, ds, order))
+
+	// saving the same state again is not a transition
+	require.NoError(t, SaveOrder(ctx, ds, order))
+
+	// a failed retry keeps the state but records the error
+	order.Tries = 1
+	order.LastErr = "assign failed"
+	order.Shards["sp1"] = types.OrderShardInfo{State: types.ShardStateError}
+	require.NoError(t, SaveOrder(ctx, ds, order))
+
+	order.Tries = 2
+	order.State = types.OrderStateTerminate
+	require.NoError(t, SaveOrder(ctx, ds, order))
+
+	shard := types.ShardInfo{DataId: "data1", OrderId: 1, Cid: contentCid, State: types.ShardStateValidated}
+	require.NoError(t, SaveShard(ctx, ds, shard))
+	shard.State = types.ShardStateStored
+	require.NoError(t, SaveShard(ctx, ds, shard))
+
+	// an update of the data is a new order with its own history
+	update := types.OrderInfo{DataId: "data1", OrderId: 2, Cid: contentCid, State: types.OrderStateReady}
+	require.NoError(t, SaveOrder(ctx, ds, update))
+
+	staged, err := GetOrderHistory(ctx, ds, "data1", 0)
+	require.NoError(t, err)
+	require.Empty(t, staged.Events)
+
+	history, err := GetOrderHistory(ctx, ds, "data1", 1)
+	require.NoError(t, err)
+	require.Equal(t, "data1", history.DataId)
+	require.Equal(t, uint64(1), history.OrderId)
+	for _, event := range history.Events {
+		require.Equal(t, uint64(1), event.OrderId)
+	}
+
+	var transitions []string
+	for _, event := range history.Events {
+		transitions = append(transitions, event.Kind+":"+event.Subject+":"+event.From+"->"+event.To)
+	}
+	require.Equal(t, []string{
+		"order::->Staged",
+		"order::Staged->Ready",
+		"order-shard:sp1:->assigned",
+		"order::Ready->Ready",
+		"order-shard:sp1:assigned->error",
+		"order::Ready->Terminate",
+		"shard:" + shard.Cid.String() + ":->validated",
+		"shard:" + shard.Cid.String() + ":validated->stored",
+	}, transitions)
+	require.Equal(t, "assign failed", history.Events[3].Message)
+	require.Equal(t, uint64(2), history.Events[5].Tries)
+
+	events, err := ListOrderEvents(ctx, ds, "data1")
+	require.NoError(t, err)
+	require.Len(t, events, len(history.Events)+1)
+	require.Equal(t, uint64(2), events[len(events)-1].OrderId)
+}
+
+// historyFailingDs fails every write of the order history.
+type historyFailingDs struct {
+	datastore.Batching
+}
+
+func (ds historyFailingDs) Put(ctx context.Context, key datastore.Key, value []byte) error {
+	if strings.HasPrefix(key.String(), "/order-history-") {
+		return errors.New("history is broken")
+	}
+	return ds.Batching.Put(ctx, key, value)
+}
+
+func TestSaveOrderHistoryFailure(t *testing.T) {
+	ctx := context.Background()
+	ds := historyFailingDs{dssync.MutexWrap(datastore.NewMapDatastore())}
+
+	contentCid, err := CalculateCid([]byte("order history failure"))
+	require.NoError(t, err)
+	order := types.OrderInfo{DataId: "data1", OrderId: 1, Cid: contentCid, State: types.OrderStateReady}
+	require.NoError(t, SaveOrder(ctx, ds, order))
+	shard := types.ShardInfo{DataId: "data1", OrderId: 1, Cid: contentCid, State: types.ShardStateValidated}
+	require.NoError(t, SaveShard(ctx, ds, shard))
+
+	// the states are saved without their history
+	saved, err := GetOrderByOrderId(ctx, ds, 1)
+	require.NoError(t, err)
+	require.Equal(t, types.OrderStateReady, saved.State)
+	savedShard, err := GetShard(ctx, ds, 1, contentCid)
+	require.NoError(t, err)
+	require.Equal(t, types.ShardStateValidated, savedShard.State)
+	history, err := GetOrderHistory(ctx, ds, "data1", 1)
+	require.NoError(t, err)
+	require.Empty(t, history.Events)
+}
+
+func TestGetOrderByOrderId(t *testing.T) {
+	ctx := context.Background()
+	ds := dssync.MutexWrap(datastore.NewMapDatastore())
+
+	contentCid, err := CalculateCid([]byte("order by order id"))
+	require.NoError(t, err)
+	order := types.OrderInfo{DataId: "data1", Cid: contentCid, State: types.OrderStateStaged}
+	require.NoError(t, SaveOrder(ctx, ds, order))
+	order.OrderId = 1
+	order.State = types.OrderStateReady
+	require.NoError(t, SaveOrder(ctx, ds, order))
+
+	found, err := GetOrderByOrderId(ctx, ds, 1)
+	require.NoError(t, err)
+	require.Equal(t, "data1", found.DataId)
+
+	found, err = GetOrderByOrderId(ctx, ds, 2)
+	require.NoError(t, err)
+	require.Empty(t, found.DataId)
+
+	// the data model is updated with a new order
+	order.OrderId = 2
+	require.NoError(t, SaveOrder(ctx, ds, order))
+	found, err = GetOrderByOrderId(ctx, ds, 1)
+	require.NoError(t, err)
+	require.Empty(t, found.DataId)
+	found, err = GetOrderByOrderId(ctx, ds, 2)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), found.OrderId)
+}